@@ -25,6 +25,8 @@ const (
 	queryParamGroupName     = "instance_scaling_group_name"
 	queryParamLimit         = "limit"
 	queryParamOffset        = "offset"
+	queryParamStartTime     = "start_time"
+	queryParamEndTime       = "end_time"
 )
 
 type ScalingGroupController struct {
@@ -178,6 +180,49 @@ func (c *ScalingGroupController) ListScalingGroup() {
 	response.Success(c.Ctx, http.StatusOK, list)
 }
 
+// ListScalingDecisions 按时间倒序分页查询伸缩组的伸缩决定记录
+func (c *ScalingGroupController) ListScalingDecisions() {
+	tLogger := logger.GetTraceLogger(c.Ctx).WithField(logger.Stage, "list_scaling_decisions")
+	projectId := c.GetString(urlParamProjectId)
+	if errCode := validator.ErrCodeForProjectId(projectId); errCode != nil {
+		response.Error(c.Ctx, http.StatusBadRequest, errors.NewErrorResp(*errCode))
+		tLogger.Error("project_id verification is failed,err: %s", errCode.Msg())
+		return
+	}
+	groupID := c.GetString(urlParamScalingGroupId)
+	start, err := parseQueryTime(c.GetString(queryParamStartTime))
+	if err != nil {
+		response.Error(c.Ctx, http.StatusBadRequest, errors.NewErrorResp(errors.QueryParamTimeError))
+		tLogger.Error("Query param %s is invalid, err: %v", queryParamStartTime, err)
+		return
+	}
+	end, err := parseQueryTime(c.GetString(queryParamEndTime))
+	if err != nil {
+		response.Error(c.Ctx, http.StatusBadRequest, errors.NewErrorResp(errors.QueryParamTimeError))
+		tLogger.Error("Query param %s is invalid, err: %v", queryParamEndTime, err)
+		return
+	}
+	limit, err := c.GetInt(queryParamLimit, common.MaxNumberOfParamLimit)
+	if err != nil || limit < 0 || limit > common.MaxNumberOfParamLimit {
+		response.Error(c.Ctx, http.StatusBadRequest, errors.NewErrorResp(errors.QueryParamLimitError))
+		tLogger.Error("The query param limit is invalid,err: %+v", err)
+		return
+	}
+	offset, err := c.GetInt(queryParamOffset, 0)
+	if err != nil || offset < 0 || offset > common.MaxNumberOfParamOffset {
+		response.Error(c.Ctx, http.StatusBadRequest, errors.NewErrorResp(errors.QueryParamOffsetError))
+		tLogger.Error("The query param offset is invalid,err: %+v", err)
+		return
+	}
+	tLogger.Info("Received scaling decisions query request for scaling group[%s]", groupID)
+	list, errResp := service.ListScalingDecisions(tLogger, projectId, groupID, start, end, limit, offset)
+	if errResp != nil {
+		response.Error(c.Ctx, errResp.HttpCode, errResp)
+		return
+	}
+	response.Success(c.Ctx, http.StatusOK, list)
+}
+
 // GetInstanceConfigOfScalingGroup get instance configuration of instance scaling group
 func (c *ScalingGroupController) GetInstanceConfigOfScalingGroup() {
 	tLogger := logger.GetTraceLogger(c.Ctx).WithField(logger.Stage, "get_instance_config")
@@ -6,6 +6,7 @@ package controller
 import (
 	"encoding/json"
 	"net/http"
+	"time"
 
 	"github.com/beego/beego/v2/server/web"
 
@@ -13,6 +14,7 @@ import (
 	"scase.io/application-auto-scaling-service/pkg/api/model"
 	"scase.io/application-auto-scaling-service/pkg/api/response"
 	"scase.io/application-auto-scaling-service/pkg/api/validator"
+	"scase.io/application-auto-scaling-service/pkg/common"
 	"scase.io/application-auto-scaling-service/pkg/service"
 	"scase.io/application-auto-scaling-service/pkg/utils/logger"
 )
@@ -102,3 +104,46 @@ func (c *ScalingPolicyController) UpdateScalingPolicy() {
 	response.Success(c.Ctx, http.StatusNoContent, nil)
 	return
 }
+
+// GetScalingForecast 查询预测策略的需求预测以及对应时间段的实际需求
+func (c *ScalingPolicyController) GetScalingForecast() {
+	tLogger := logger.GetTraceLogger(c.Ctx).WithField(logger.Stage, "get_scaling_forecast")
+	projectId := c.GetString(urlParamProjectId)
+	if errCode := validator.ErrCodeForProjectId(projectId); errCode != nil {
+		response.Error(c.Ctx, http.StatusBadRequest, errors.NewErrorResp(*errCode))
+		tLogger.Error("project_id verification is failed,err: %s", errCode.Msg())
+		return
+	}
+	policyId := c.GetString(urlParamScalingPolicyId)
+	start, err := parseQueryTime(c.GetString(queryParamStartTime))
+	if err != nil {
+		response.Error(c.Ctx, http.StatusBadRequest, errors.NewErrorResp(errors.QueryParamTimeError))
+		tLogger.Error("Query param %s is invalid, err: %v", queryParamStartTime, err)
+		return
+	}
+	end, err := parseQueryTime(c.GetString(queryParamEndTime))
+	if err != nil {
+		response.Error(c.Ctx, http.StatusBadRequest, errors.NewErrorResp(errors.QueryParamTimeError))
+		tLogger.Error("Query param %s is invalid, err: %v", queryParamEndTime, err)
+		return
+	}
+	tLogger.Info("Received forecast request for scaling policy[%s]", policyId)
+	forecast, errResp := service.GetScalingForecast(tLogger, projectId, policyId, start, end)
+	if errResp != nil {
+		response.Error(c.Ctx, errResp.HttpCode, errResp)
+		return
+	}
+	response.Success(c.Ctx, http.StatusOK, forecast)
+}
+
+// parseQueryTime 解析UTC时间的查询参数, 未指定时返回nil
+func parseQueryTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.ParseInLocation(common.TimeLayout, value, time.UTC)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
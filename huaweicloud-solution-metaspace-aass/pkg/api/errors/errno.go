@@ -36,11 +36,17 @@ const (
 	DisKSizeError           ErrCode = "SCASE.00030105"
 	GroupLockUpdateNumError ErrCode = "SCASE.00030106"
 	// 业务类型错误码-伸缩策略相关
-	TargetBasedPolicyExist  ErrCode = "SCASE.00030200"
-	ScalingPolicyNotFound   ErrCode = "SCASE.00030201"
-	PolicyDeleteError       ErrCode = "SCASE.00030202"
-	GroupLockDelPolicyError ErrCode = "SCASE.00030203"
-	ScalingGroupDeleting    ErrCode = "SCASE.00030204"
+	TargetBasedPolicyExist   ErrCode = "SCASE.00030200"
+	ScalingPolicyNotFound    ErrCode = "SCASE.00030201"
+	PolicyDeleteError        ErrCode = "SCASE.00030202"
+	GroupLockDelPolicyError  ErrCode = "SCASE.00030203"
+	ScalingGroupDeleting     ErrCode = "SCASE.00030204"
+	TargetConfigurationError ErrCode = "SCASE.00030205"
+	ScheduledConfigError     ErrCode = "SCASE.00030206"
+	StepConfigError          ErrCode = "SCASE.00030207"
+	PredictivePolicyExist    ErrCode = "SCASE.00030208"
+	NotPredictivePolicy      ErrCode = "SCASE.00030209"
+	TargetMetricExist        ErrCode = "SCASE.00030210"
 	// LTS 相关错误码
 	LtsHostGroupError    ErrCode = "SCASE.00040001"
 	LtsLogStreamError    ErrCode = "SCASE.00040002"
@@ -70,11 +76,21 @@ var errMsg = map[ErrCode]string{
 	DisKSizeError:           "The size of disk is invalid",
 	GroupLockUpdateNumError: "The instance num cannot be updated because the scaling group is locked. Please try again later",
 	// 业务类型错误信息-伸缩策略相关
-	TargetBasedPolicyExist:  "Only one TARGET_BASED policy can be configured for instance scaling group",
+	TargetBasedPolicyExist:  "The STEP policy cannot coexist with other TARGET_BASED or STEP policies in instance scaling group",
 	ScalingPolicyNotFound:   "The scaling policy is not found",
 	PolicyDeleteError:       "At least one scaling policy exists when the instance scaling group's enable_auto_scaling is true",
 	GroupLockDelPolicyError: "The policy cannot be deleted because the scaling group is locked. Please try again later",
 	ScalingGroupDeleting:    "The instance scaling group is being deleted. Cannot create scaling policy for it.",
+	TargetConfigurationError: "The custom_metric_name is required only for CUSTOM_METRIC, the target_value of " +
+		"percent and utilization metrics cannot be greater than 100, and the target_value of " +
+		"PERCENT_AVAILABLE_PROCESSES must be less than 100",
+	ScheduledConfigError: "The scheduled_configuration requires a valid recurrence or start_time, at least one of " +
+		"instance numbers, and min_instance_number ≤ desire_instance_number ≤ max_instance_number",
+	StepConfigError: "The steps must be sorted by bounds without overlapping, only the first step can omit lower_bound " +
+		"and only the last step can omit upper_bound, and custom_metric_name is required only for CUSTOM_METRIC",
+	PredictivePolicyExist: "Only one PREDICTIVE policy can be configured for instance scaling group",
+	NotPredictivePolicy:   "The forecast is only available for PREDICTIVE policy",
+	TargetMetricExist:     "The TARGET_BASED policy of the metric already exists in instance scaling group",
 	// LTS 相关错误码
 	LtsHostGroupError:    "LTS Host Group Error",
 	LtsLogStreamError:    "LTS Log Stream Error",
@@ -0,0 +1,36 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 自动伸缩决定记录结构定义
+package model
+
+// ScalingDecisionList 伸缩组的伸缩决定记录, 按判断时间倒序排列
+type ScalingDecisionList struct {
+	TotalCount       int64                 `json:"total_count"`
+	Count            int                   `json:"count"`
+	ScalingDecisions []*ScalingDecisionLog `json:"scaling_decisions"`
+}
+
+// ScalingDecisionLog 一次伸缩判断的输入、伸缩决定以及启动的伸缩任务或未启动的原因
+type ScalingDecisionLog struct {
+	ScalingGroupId  string             `json:"instance_scaling_group_id"`
+	ScalingPolicyId string             `json:"scaling_policy_id"`
+	PolicyType      string             `json:"policy_type"`
+	EvaluatedAt     string             `json:"evaluated_at"`
+	Metrics         map[string]float64 `json:"metrics,omitempty"`
+	// CurrentInstanceNumber 判断时的实例数, MinInstanceNumber和MaxInstanceNumber为判断时生效的上下限
+	CurrentInstanceNumber int32 `json:"current_instance_number"`
+	MinInstanceNumber     int32 `json:"min_instance_number"`
+	MaxInstanceNumber     int32 `json:"max_instance_number"`
+	InCoolDown            bool  `json:"in_cool_down"`
+	// Action ScalingOut扩容, ScalingIn缩容, NoScaling不伸缩
+	Action        string   `json:"action"`
+	CalculatedNum float64  `json:"calculated_num"`
+	AvailableNum  float64  `json:"available_num"`
+	ScalingNum    float64  `json:"scaling_num"`
+	Instances     []string `json:"instances,omitempty"`
+	// TaskId 启动的伸缩任务, 未启动时为0并由SkipReason说明原因
+	TaskId     int    `json:"task_id,omitempty"`
+	SkipReason string `json:"skip_reason,omitempty"`
+	Message    string `json:"message,omitempty"`
+	DryRun     bool   `json:"dry_run"`
+}
@@ -42,6 +42,8 @@ type UpdateScalingGroupReq struct {
 	EnableAutoScaling     *bool                  `json:"enable_auto_scaling,omitempty" validate:"omitempty"`
 	InstanceConfiguration *InstanceConfiguration `json:"instance_configuration,omitempty" validate:"omitempty"`
 	InstanceTags		  []InstanceTag			 `json:"instance_tags,omitempty" validate:"omitempty,min=0,max=10"`
+	// ImageId 替换伸缩配置的镜像, 之后扩容的实例从新镜像启动, 已有实例不受影响
+	ImageId               *string                `json:"image_id,omitempty" validate:"omitempty,uuid"`
 }
 
 type VmTemplate struct {
@@ -82,12 +84,28 @@ type RuntimeConfiguration struct {
 	ProcessConfigurations                 *[]ProcessConfiguration `json:"process_configurations,omitempty" validate:"omitempty,min=1,max=50,dive"`
 	ServerSessionActivationTimeoutSeconds *int32                  `json:"server_session_activation_timeout_seconds,omitempty" validate:"omitempty,gte=1,lte=600"`
 	MaxConcurrentServerSessionsPerProcess *int32                  `json:"max_concurrent_server_sessions_per_process,omitempty" validate:"omitempty,gte=1,lte=50"`
+	PlacementPolicy                       *PlacementPolicy        `json:"placement_policy,omitempty" validate:"omitempty"`
+}
+
+// PlacementPolicy 服务端会话在fleet内的进程放置策略, 由appgateway在分配进程时使用
+type PlacementPolicy struct {
+	Strategy                   string   `json:"strategy" validate:"required,oneof=PACK SPREAD PREFERRED"`
+	PreferredInstanceIds       []string `json:"preferred_instance_ids,omitempty" validate:"omitempty,max=20,dive,min=1,max=128"`
+	PreferredAvailabilityZones []string `json:"preferred_availability_zones,omitempty" validate:"omitempty,max=10,dive,min=1,max=64"`
 }
 
 type ProcessConfiguration struct {
-	LaunchPath           *string `json:"launch_path" validate:"required,min=0,max=1024"`
-	Parameters           *string `json:"parameters" validate:"required,min=0,max=1024"`
-	ConcurrentExecutions *int32  `json:"concurrent_executions" validate:"required,gte=1,lte=50"`
+	LaunchPath           *string         `json:"launch_path" validate:"required,min=0,max=1024"`
+	Parameters           *string         `json:"parameters" validate:"required,min=0,max=1024"`
+	ConcurrentExecutions *int32          `json:"concurrent_executions" validate:"required,gte=1,lte=50"`
+	ResourceLimits       *ResourceLimits `json:"resource_limits,omitempty" validate:"omitempty"`
+}
+
+// ResourceLimits 单个应用进程的资源限制, 由auxproxy在Linux上通过cgroup v2和rlimit实施, 由appgateway透传给auxproxy
+type ResourceLimits struct {
+	CpuQuotaMillicores int `json:"cpu_quota_millicores,omitempty" validate:"omitempty,gte=100,lte=64000"`
+	MemoryMaxMB        int `json:"memory_max_mb,omitempty" validate:"omitempty,gte=64,lte=262144"`
+	MaxOpenFiles       int `json:"max_open_files,omitempty" validate:"omitempty,gte=64,lte=1048576"`
 }
 
 type ScalingGroupDetail struct {
@@ -4,20 +4,99 @@
 package model
 
 type CreateScalingPolicyReq struct {
-	TargetConfiguration    *TargetConfiguration `json:"target_based_configuration,omitempty" validate:"required_if=Type TARGET_BASED"`
-	Name                   *string              `json:"name" validate:"required,min=1,max=1024"`
-	InstanceScalingGroupID *string              `json:"instance_scaling_group_id" validate:"required,uuid"`
-	Type                   *string              `json:"policy_type" validate:"required,oneof=TARGET_BASED"`
+	TargetConfiguration     *TargetConfiguration     `json:"target_based_configuration,omitempty" validate:"required_if=Type TARGET_BASED"`
+	ScheduledConfiguration  *ScheduledConfiguration  `json:"scheduled_configuration,omitempty" validate:"required_if=Type SCHEDULED"`
+	StepConfiguration       *StepConfiguration       `json:"step_configuration,omitempty" validate:"required_if=Type STEP"`
+	PredictiveConfiguration *PredictiveConfiguration `json:"predictive_configuration,omitempty" validate:"required_if=Type PREDICTIVE"`
+	Name                    *string                  `json:"name" validate:"required,min=1,max=1024"`
+	InstanceScalingGroupID  *string                  `json:"instance_scaling_group_id" validate:"required,uuid"`
+	Type                    *string                  `json:"policy_type" validate:"required,oneof=TARGET_BASED SCHEDULED STEP PREDICTIVE"`
+	// DryRun 只记录伸缩决定, 不启动伸缩任务, 默认为false
+	DryRun *bool `json:"dry_run,omitempty"`
 }
 
 type UpdateScalingPolicyReq struct {
-	TargetConfiguration *TargetConfiguration `json:"target_based_configuration,omitempty" validate:"omitempty"`
-	Name                *string              `json:"name,omitempty" validate:"omitempty,min=1,max=1024"`
+	TargetConfiguration     *TargetConfiguration     `json:"target_based_configuration,omitempty" validate:"omitempty"`
+	ScheduledConfiguration  *ScheduledConfiguration  `json:"scheduled_configuration,omitempty" validate:"omitempty"`
+	StepConfiguration       *StepConfiguration       `json:"step_configuration,omitempty" validate:"omitempty"`
+	PredictiveConfiguration *PredictiveConfiguration `json:"predictive_configuration,omitempty" validate:"omitempty"`
+	Name                    *string                  `json:"name,omitempty" validate:"omitempty,min=1,max=1024"`
+	DryRun                  *bool                    `json:"dry_run,omitempty"`
 }
 
 type TargetConfiguration struct {
-	MetricName  *string `json:"metric_name" validate:"required,oneof=PERCENT_AVAILABLE_SERVER_SESSIONS"`
-	TargetValue *int32  `json:"target_value" validate:"required,gte=1,lte=100"`
+	MetricName *string `json:"metric_name" validate:"required,oneof=PERCENT_AVAILABLE_SERVER_SESSIONS PERCENT_AVAILABLE_PROCESSES QUEUED_SERVER_SESSIONS CPU_UTILIZATION MEMORY_UTILIZATION CUSTOM_METRIC"`
+	// CustomMetricName 进程通过SDK上报的自定义指标名称, 仅CUSTOM_METRIC使用
+	CustomMetricName *string `json:"custom_metric_name,omitempty" validate:"omitempty,customMetricName"`
+	// TargetValue PERCENT_AVAILABLE_SERVER_SESSIONS和PERCENT_AVAILABLE_PROCESSES为可用的百分比,
+	// QUEUED_SERVER_SESSIONS为可容忍的排队ServerSession数, CPU_UTILIZATION和MEMORY_UTILIZATION为实例使用率的
+	// 平均百分比, CUSTOM_METRIC为伸缩组内进程指标的平均值
+	TargetValue *int32 `json:"target_value" validate:"required,gte=1"`
+}
+
+// ScheduledConfiguration 定时策略, 时间窗内由策略的实例数上下限代替伸缩组自身的上下限, 基于目标的策略在其范围内伸缩
+type ScheduledConfiguration struct {
+	// Recurrence 周期时间窗的cron表达式(分 时 日 月 周), 与StartTime二选一
+	Recurrence *string `json:"recurrence,omitempty" validate:"required_without=StartTime,excluded_with=StartTime,omitempty,max=128"`
+	// StartTime 单次时间窗的开始时间, 格式为2006-01-02T15:04:05
+	StartTime *string `json:"start_time,omitempty" validate:"required_without=Recurrence,omitempty,datetime=2006-01-02T15:04:05"`
+	// DurationMinutes 时间窗的长度, 时间窗结束后恢复伸缩组自身的上下限
+	DurationMinutes *int32 `json:"duration_minutes" validate:"required,gte=1,lte=43200"`
+	// TimeZone Recurrence和StartTime所在的时区, 如Asia/Shanghai, 默认为UTC
+	TimeZone             *string `json:"time_zone,omitempty" validate:"omitempty,timezone"`
+	MinInstanceNumber    *int32  `json:"min_instance_number,omitempty" validate:"omitempty,gte=0,instanceMaximumLimit"`
+	MaxInstanceNumber    *int32  `json:"max_instance_number,omitempty" validate:"omitempty,gte=0,instanceMaximumLimit"`
+	DesireInstanceNumber *int32  `json:"desire_instance_number,omitempty" validate:"omitempty,gte=0,instanceMaximumLimit"`
+}
+
+// StepConfiguration 步进策略, 指标连续EvaluationPeriods个监控周期落在同一伸缩方向的区间时, 按最近一个周期所在区间的步长伸缩
+type StepConfiguration struct {
+	MetricName       *string `json:"metric_name" validate:"required,oneof=PERCENT_AVAILABLE_SERVER_SESSIONS CUSTOM_METRIC"`
+	CustomMetricName *string `json:"custom_metric_name,omitempty" validate:"omitempty,customMetricName"`
+	// Steps 按指标值从小到大排列且互不重叠的区间, PERCENT_AVAILABLE_SERVER_SESSIONS的指标值为0~100的百分比
+	Steps []*StepAdjustment `json:"steps" validate:"required,min=1,max=10,dive,required"`
+	// EvaluationPeriods 指标需要连续落在同一伸缩方向区间的监控周期数, 默认为1
+	EvaluationPeriods *int32 `json:"evaluation_periods,omitempty" validate:"omitempty,gte=1,lte=10"`
+	// ScaleOutCoolDownTime 扩容后再次扩容的冷却时长(min), 默认为伸缩组的冷却时长
+	ScaleOutCoolDownTime *int32 `json:"scale_out_cool_down_time,omitempty" validate:"omitempty,gte=0,lte=30"`
+	// ScaleInCoolDownTime 扩容或缩容后再次缩容的冷却时长(min), 默认为伸缩组的冷却时长
+	ScaleInCoolDownTime *int32 `json:"scale_in_cool_down_time,omitempty" validate:"omitempty,gte=0,lte=30"`
+}
+
+// StepAdjustment 指标值落在[LowerBound, UpperBound)时的伸缩步长, 未指定的边界为无穷
+type StepAdjustment struct {
+	LowerBound *float64 `json:"lower_bound,omitempty"`
+	UpperBound *float64 `json:"upper_bound,omitempty"`
+	// AdjustmentType CHANGE_IN_CAPACITY为固定实例数, PERCENT_CHANGE_IN_CAPACITY为当前实例数的百分比(向上取整)
+	AdjustmentType *string `json:"adjustment_type" validate:"required,oneof=CHANGE_IN_CAPACITY PERCENT_CHANGE_IN_CAPACITY"`
+	// Adjustment 正数扩容, 负数缩容, 0不伸缩
+	Adjustment *int32 `json:"adjustment" validate:"required,gte=-1000,lte=1000"`
+}
+
+// PredictiveConfiguration 预测策略, 根据历史ServerSession需求预测未来ForecastMinutes内的需求, 提前扩容
+type PredictiveConfiguration struct {
+	// Mode FORECAST_ONLY只预测不伸缩, 用于对比预测与实际需求; FORECAST_AND_SCALE按预测提前扩容
+	Mode *string `json:"mode" validate:"required,oneof=FORECAST_ONLY FORECAST_AND_SCALE"`
+	// ForecastMinutes 预测时长, 应覆盖实例创建和应用包同步的时长
+	ForecastMinutes *int32 `json:"forecast_minutes" validate:"required,gte=5,lte=240"`
+	// SeasonDays 需求的周期(天), 1为按天, 7为按周, 默认为1
+	SeasonDays *int32 `json:"season_days,omitempty" validate:"omitempty,oneof=1 7"`
+	// TargetValue 预测需求下期望保留的可用会话百分比
+	TargetValue *int32 `json:"target_value" validate:"required,gte=0,lte=90"`
+}
+
+// ScalingForecast 预测策略的需求预测, 与同一时间段的实际需求对比
+type ScalingForecast struct {
+	ScalingPolicyId string           `json:"scaling_policy_id"`
+	Forecasts       []*ForecastPoint `json:"forecasts"`
+}
+
+// ForecastPoint 每个时间段的预测, 需求为时间段内已使用和排队的ServerSession数量的峰值
+type ForecastPoint struct {
+	Time                   string   `json:"time"`
+	ForecastDemand         float64  `json:"forecast_demand"`
+	ForecastInstanceNumber int32    `json:"forecast_instance_number"`
+	ActualDemand           *float64 `json:"actual_demand,omitempty"`
 }
 
 type CreateScalingPolicyResp struct {
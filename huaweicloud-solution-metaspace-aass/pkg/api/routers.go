@@ -17,6 +17,9 @@ func initRouters() {
 	web.Router("/v1/:project_id/instance-scaling-groups/:instance_scaling_group_id",
 		&controller.ScalingGroupController{},
 		"delete:DeleteScalingGroup;put:UpdateScalingGroup;get:GetScalingGroup")
+	web.Router("/v1/:project_id/instance-scaling-groups/:instance_scaling_group_id/scaling-decisions",
+		&controller.ScalingGroupController{},
+		"get:ListScalingDecisions")
 	web.Router("/v1/instance-scaling-groups/:instance_scaling_group_id/instance-configuration",
 		&controller.ScalingGroupController{},
 		"get:GetInstanceConfigOfScalingGroup")
@@ -26,6 +29,8 @@ func initRouters() {
 		"post:CreateScalingPolicy")
 	web.Router("/v1/:project_id/scaling-policies/:scaling_policy_id", &controller.ScalingPolicyController{},
 		"delete:DeleteScalingPolicy;put:UpdateScalingPolicy")
+	web.Router("/v1/:project_id/scaling-policies/:scaling_policy_id/forecasts", &controller.ScalingPolicyController{},
+		"get:GetScalingForecast")
 
 	// scaling instances routers
 	web.Router("/v1/:project_id/monitor-instances",
@@ -42,6 +42,9 @@ func Init() error {
 	if err := validate.RegisterValidation("supportedVolumeType", supportedVolumeTypeFun); err != nil {
 		return errors.Wrap(err, "register validation[supportedVolumeType] err")
 	}
+	if err := validate.RegisterValidation("customMetricName", customMetricNameFun); err != nil {
+		return errors.Wrap(err, "register validation[customMetricName] err")
+	}
 	if err := et.RegisterDefaultTranslations(validate, trans); err != nil {
 		return errors.Wrap(err, "register validator default translation err")
 	}
@@ -90,6 +93,16 @@ func flavorIdFun(f validator.FieldLevel) bool {
 	return flag
 }
 
+// customMetricNameFun 自定义指标名称与appgateway写入influxdb的字段名规则一致
+func customMetricNameFun(f validator.FieldLevel) bool {
+	value := f.Field().String()
+	flag, err := regexp.MatchString(`^[A-Za-z][A-Za-z0-9_]{0,63}$`, value)
+	if err != nil {
+		return false
+	}
+	return flag
+}
+
 func instanceMaximumLimitFun(f validator.FieldLevel) bool {
 	value := f.Field().Int()
 	if value > int64(setting.GetInstanceMaximumLimitPreGroup()) {
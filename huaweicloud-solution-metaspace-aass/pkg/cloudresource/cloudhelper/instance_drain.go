@@ -0,0 +1,296 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 实例排空: 缩容前通知实例内的auxproxy排空所有应用进程, 等待服务器会话结束后再移除实例
+package cloudhelper
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"scase.io/application-auto-scaling-service/pkg/cloudresource"
+	"scase.io/application-auto-scaling-service/pkg/setting"
+	"scase.io/application-auto-scaling-service/pkg/utils/hhmac"
+	"scase.io/application-auto-scaling-service/pkg/utils/logger"
+)
+
+const (
+	auxproxyCleanUpPath      = "/v1/cleanup"
+	auxproxyCleanUpStatePath = "/v1/cleanup-state"
+	cleanUpStateFinished     = "FINISHED"
+
+	drainRequestTimeout = 10 * time.Second
+	// drainMaxUnavailable auxproxy连续无法访问或返回5xx的最大次数, 超过后认为实例已失效, 不再等待排空
+	drainMaxUnavailable = 3
+)
+
+// drainPollInterval 查询实例排空状态的间隔, 测试时替换
+var drainPollInterval = 10 * time.Second
+
+// errInstanceUnavailable 实例内的auxproxy无法访问或不健康, 实例上不会再有服务器会话, 视为排空完成
+var errInstanceUnavailable = errors.New("auxproxy of instance is unavailable")
+
+var (
+	// drainHttpClient 使用配置的CA校验auxproxy证书, 首次使用时创建, 测试时替换
+	drainHttpClient    *http.Client
+	drainHttpClientMux sync.Mutex
+)
+
+func getDrainHttpClient() (*http.Client, error) {
+	drainHttpClientMux.Lock()
+	defer drainHttpClientMux.Unlock()
+	if drainHttpClient != nil {
+		return drainHttpClient, nil
+	}
+	// 创建失败时不缓存, 修正配置后重试的缩容任务可以继续
+	client, err := newDrainHttpClient(setting.GetAuxproxyCaFile(), setting.GetAuxproxyServerName())
+	if err != nil {
+		return nil, err
+	}
+	drainHttpClient = client
+	return client, nil
+}
+
+func newDrainHttpClient(caFile, serverName string) (*http.Client, error) {
+	if caFile == "" {
+		return nil, errors.New("ca file of auxproxy is not configured, can not verify auxproxy certificate")
+	}
+	ca, err := ioutil.ReadFile(caFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "read ca file of auxproxy err")
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, errors.New("no valid certificate in ca file of auxproxy")
+	}
+	return &http.Client{
+		Timeout: drainRequestTimeout,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				MinVersion: tls.VersionTLS12,
+				RootCAs:    pool,
+				ServerName: serverName,
+			},
+		},
+	}, nil
+}
+
+// newDrainRequest 创建访问auxproxy的请求, 配置了auxproxy秘钥时进行hmac签名
+func newDrainRequest(method, url string) (*http.Request, error) {
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if hhmac.HmacLocalSker == nil || !hhmac.HmacLocalSker.EnableAuth(hhmac.LocalKeyAuxProxy) {
+		return req, nil
+	}
+	ak, err := hhmac.HmacLocalSker.GetAk(hhmac.LocalKeyAuxProxy)
+	if err != nil {
+		return nil, err
+	}
+	sk, err := hhmac.HmacLocalSker.GetSk(hhmac.LocalKeyAuxProxy)
+	if err != nil {
+		return nil, err
+	}
+	if err = hhmac.RequestSignHmac(req, ak, sk); err != nil {
+		// 签名错误可能包含秘钥信息, 不输出具体err
+		return nil, errors.New("sign auxproxy request err")
+	}
+	return req, nil
+}
+
+func doDrainRequest(method, url string) (*http.Response, error) {
+	client, err := getDrainHttpClient()
+	if err != nil {
+		return nil, err
+	}
+	req, err := newDrainRequest(method, url)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil && !isCertificateError(err) {
+		return nil, errors.Wrap(errInstanceUnavailable, err.Error())
+	}
+	return resp, err
+}
+
+// isCertificateError auxproxy证书校验失败说明配置错误, 不能视为实例不可用
+func isCertificateError(err error) bool {
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostname x509.HostnameError
+	var invalid x509.CertificateInvalidError
+	return errors.As(err, &unknownAuthority) || errors.As(err, &hostname) || errors.As(err, &invalid)
+}
+
+// instanceCleanUpState auxproxy清理状态
+type instanceCleanUpState struct {
+	State     string               `json:"state"`
+	Processes []processDrainStatus `json:"processes"`
+}
+
+// processDrainStatus auxproxy上报的单个进程排空状态
+type processDrainStatus struct {
+	Pid                  int    `json:"pid"`
+	ProcessID            string `json:"process_id"`
+	Phase                string `json:"phase"`
+	Acknowledged         bool   `json:"acknowledged"`
+	ActiveServerSessions int    `json:"active_server_sessions"`
+}
+
+// DrainInstances 并发排空待缩容的实例，任一实例在超时时间内仍有服务器会话时返回错误，由缩容任务重试；
+// auxproxy无法访问或不健康的实例视为排空完成，未配置auxproxy CA时跳过排空，不阻塞缩容
+// 注意：唯一入口为ScaleInTask触发
+func DrainInstances(log *logger.FMLogger, projectId string, instanceIds []string) error {
+	if _, err := getDrainHttpClient(); err != nil {
+		log.Error("Can not verify auxproxy certificate, scale in instances[%s] without draining, err: %+v",
+			strings.Join(instanceIds, ","), err)
+		return nil
+	}
+	resCtrl, err := cloudresource.GetResourceController(projectId)
+	if err != nil {
+		return errors.Wrapf(err, "get resource controller of project[%s] err", projectId)
+	}
+	port := strconv.Itoa(setting.GetAuxproxyPort())
+	timeout := time.Duration(setting.GetInstanceDrainTimeoutMinutes()) * time.Minute
+
+	var wg sync.WaitGroup
+	var mux sync.Mutex
+	var failed []string
+	for _, instanceId := range instanceIds {
+		wg.Add(1)
+		go func(instanceId string) {
+			defer wg.Done()
+			if err := drainInstanceById(log, resCtrl, instanceId, port, timeout); err != nil {
+				log.Error("Drain instance[%s] err: %+v", instanceId, err)
+				mux.Lock()
+				failed = append(failed, instanceId)
+				mux.Unlock()
+			}
+		}(instanceId)
+	}
+	wg.Wait()
+
+	if len(failed) > 0 {
+		return errors.Errorf("drain of instances[%s] is not confirmed", strings.Join(failed, ","))
+	}
+	return nil
+}
+
+func drainInstanceById(log *logger.FMLogger, resCtrl *cloudresource.ResourceController, instanceId, port string,
+	timeout time.Duration) error {
+	ip, err := resCtrl.GetServerFixedIp(instanceId)
+	if err != nil {
+		return errors.Wrap(err, "get ip of instance err")
+	}
+	endpoint := fmt.Sprintf("https://%s", net.JoinHostPort(ip, port))
+	state, err := drainInstance(endpoint, timeout)
+	if errors.Is(err, errInstanceUnavailable) {
+		log.Warn("Instance[%s] is treated as drained, err: %+v", instanceId, err)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	log.Info("Instance[%s] drained, processes: %+v", instanceId, state.Processes)
+	return nil
+}
+
+// drainInstance 通知auxproxy开始清理并等待清理完成，清理重复触发时auxproxy返回202；
+// auxproxy连续drainMaxUnavailable次无法访问或返回5xx时返回errInstanceUnavailable
+func drainInstance(endpoint string, timeout time.Duration) (*instanceCleanUpState, error) {
+	if err := startInstanceCleanUp(endpoint); err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(timeout)
+	unavailable := 0
+	for {
+		state, err := showInstanceCleanUpState(endpoint)
+		if err == nil && state.State == cleanUpStateFinished {
+			return state, nil
+		}
+		if unavailable, err = countUnavailable(unavailable, err); unavailable >= drainMaxUnavailable {
+			return nil, err
+		}
+		if time.Now().After(deadline) {
+			if err != nil {
+				return nil, errors.Wrap(err, "wait instance clean up timeout")
+			}
+			return state, errors.Errorf("wait instance clean up timeout in state[%s]", state.State)
+		}
+		time.Sleep(drainPollInterval)
+	}
+}
+
+func startInstanceCleanUp(endpoint string) error {
+	unavailable := 0
+	for {
+		err := doStartInstanceCleanUp(endpoint)
+		if err == nil {
+			return nil
+		}
+		if unavailable, err = countUnavailable(unavailable, err); unavailable == 0 || unavailable >= drainMaxUnavailable {
+			return err
+		}
+		time.Sleep(drainPollInterval)
+	}
+}
+
+func doStartInstanceCleanUp(endpoint string) error {
+	resp, err := doDrainRequest(http.MethodPost, endpoint+auxproxyCleanUpPath)
+	if err != nil {
+		return errors.Wrap(err, "start instance clean up err")
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return errors.Wrapf(errInstanceUnavailable, "start instance clean up with status code %d", resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		return errors.Errorf("start instance clean up with unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// countUnavailable 累计auxproxy连续不可用的次数, 其他错误或访问成功时清零
+func countUnavailable(unavailable int, err error) (int, error) {
+	if errors.Is(err, errInstanceUnavailable) {
+		return unavailable + 1, err
+	}
+	return 0, err
+}
+
+func showInstanceCleanUpState(endpoint string) (*instanceCleanUpState, error) {
+	resp, err := doDrainRequest(http.MethodGet, endpoint+auxproxyCleanUpStatePath)
+	if err != nil {
+		return nil, errors.Wrap(err, "show instance clean up state err")
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "read instance clean up state err")
+	}
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return nil, errors.Wrapf(errInstanceUnavailable, "show instance clean up state with status code %d",
+			resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("show instance clean up state with unexpected status code %d", resp.StatusCode)
+	}
+	state := &instanceCleanUpState{}
+	if err = json.Unmarshal(body, state); err != nil {
+		return nil, errors.Wrap(err, "unmarshal instance clean up state err")
+	}
+	return state, nil
+}
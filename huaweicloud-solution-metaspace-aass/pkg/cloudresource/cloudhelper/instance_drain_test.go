@@ -0,0 +1,215 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 实例排空测试
+package cloudhelper
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"scase.io/application-auto-scaling-service/pkg/utils/hhmac"
+)
+
+func newFakeAuxproxy(startStatus int, finishedAfter int32) (*httptest.Server, *int32) {
+	var polls int32
+	mux := http.NewServeMux()
+	mux.HandleFunc(auxproxyCleanUpPath, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(startStatus)
+	})
+	mux.HandleFunc(auxproxyCleanUpStatePath, func(w http.ResponseWriter, r *http.Request) {
+		state := instanceCleanUpState{
+			State: "CLEANING",
+			Processes: []processDrainStatus{
+				{Pid: 3302, ProcessID: "app-process-1", Phase: "WAITING_SESSIONS", ActiveServerSessions: 1},
+			},
+		}
+		if atomic.AddInt32(&polls, 1) >= finishedAfter {
+			state.State = cleanUpStateFinished
+			state.Processes[0].Phase = "TERMINATED"
+			state.Processes[0].ActiveServerSessions = 0
+		}
+		_ = json.NewEncoder(w).Encode(state)
+	})
+	return httptest.NewTLSServer(mux), &polls
+}
+
+// useServerClient 使用信任测试服务证书的客户端
+func useServerClient(t *testing.T, srv *httptest.Server) {
+	oldClient := drainHttpClient
+	drainHttpClient = srv.Client()
+	t.Cleanup(func() { drainHttpClient = oldClient })
+}
+
+func TestDrainInstance(t *testing.T) {
+	oldInterval := drainPollInterval
+	drainPollInterval = time.Millisecond
+	defer func() { drainPollInterval = oldInterval }()
+
+	t.Run("wait until finished", func(t *testing.T) {
+		srv, polls := newFakeAuxproxy(http.StatusOK, 3)
+		defer srv.Close()
+		useServerClient(t, srv)
+
+		state, err := drainInstance(srv.URL, time.Minute)
+		assert.Nil(t, err)
+		assert.Equal(t, cleanUpStateFinished, state.State)
+		assert.Equal(t, "TERMINATED", state.Processes[0].Phase)
+		assert.Equal(t, int32(3), atomic.LoadInt32(polls))
+	})
+
+	t.Run("clean up already started", func(t *testing.T) {
+		srv, _ := newFakeAuxproxy(http.StatusAccepted, 1)
+		defer srv.Close()
+		useServerClient(t, srv)
+
+		state, err := drainInstance(srv.URL, time.Minute)
+		assert.Nil(t, err)
+		assert.Equal(t, cleanUpStateFinished, state.State)
+	})
+
+	t.Run("start clean up rejected", func(t *testing.T) {
+		srv, polls := newFakeAuxproxy(http.StatusForbidden, 1)
+		defer srv.Close()
+		useServerClient(t, srv)
+
+		_, err := drainInstance(srv.URL, time.Minute)
+		assert.NotNil(t, err)
+		assert.False(t, errors.Is(err, errInstanceUnavailable))
+		assert.Equal(t, int32(0), atomic.LoadInt32(polls))
+	})
+
+	t.Run("unhealthy auxproxy", func(t *testing.T) {
+		var starts int32
+		srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&starts, 1)
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer srv.Close()
+		useServerClient(t, srv)
+
+		_, err := drainInstance(srv.URL, time.Minute)
+		assert.True(t, errors.Is(err, errInstanceUnavailable))
+		assert.Equal(t, int32(drainMaxUnavailable), atomic.LoadInt32(&starts))
+	})
+
+	t.Run("unreachable auxproxy", func(t *testing.T) {
+		srv, _ := newFakeAuxproxy(http.StatusOK, 1)
+		useServerClient(t, srv)
+		srv.Close()
+
+		_, err := drainInstance(srv.URL, time.Minute)
+		assert.True(t, errors.Is(err, errInstanceUnavailable))
+	})
+
+	t.Run("auxproxy dies while cleaning", func(t *testing.T) {
+		var polls int32
+		srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == auxproxyCleanUpStatePath && atomic.AddInt32(&polls, 1) > 1 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(instanceCleanUpState{State: "CLEANING"})
+		}))
+		defer srv.Close()
+		useServerClient(t, srv)
+
+		_, err := drainInstance(srv.URL, time.Minute)
+		assert.True(t, errors.Is(err, errInstanceUnavailable))
+		assert.Equal(t, int32(1+drainMaxUnavailable), atomic.LoadInt32(&polls))
+	})
+
+	t.Run("untrusted certificate", func(t *testing.T) {
+		srv, _ := newFakeAuxproxy(http.StatusOK, 1)
+		defer srv.Close()
+		useServerClient(t, srv)
+		drainHttpClient = &http.Client{Timeout: drainRequestTimeout}
+
+		_, err := drainInstance(srv.URL, time.Minute)
+		assert.NotNil(t, err)
+		assert.False(t, errors.Is(err, errInstanceUnavailable))
+	})
+
+	t.Run("wait timeout", func(t *testing.T) {
+		srv, _ := newFakeAuxproxy(http.StatusOK, 1000)
+		defer srv.Close()
+		useServerClient(t, srv)
+
+		state, err := drainInstance(srv.URL, 10*time.Millisecond)
+		assert.NotNil(t, err)
+		assert.Equal(t, "CLEANING", state.State)
+	})
+}
+
+func TestDrainRequestSigned(t *testing.T) {
+	oldLocal, oldStore := hhmac.HmacLocalSker, hhmac.HmacStoreSker
+	defer func() { hhmac.HmacLocalSker, hhmac.HmacStoreSker = oldLocal, oldStore }()
+	local := hhmac.NewlocalKey()
+	local.Add(hhmac.LocalKeyAuxProxy, hhmac.LocalKeyEntry{Enable: true, AK: "aass", SK: []byte("secret")})
+	hhmac.HmacLocalSker = local
+	store := hhmac.NewStore()
+	store.Add("aass", hhmac.KeyEntry{SK: []byte("secret")})
+	hhmac.HmacStoreSker = store
+
+	var validated int32
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 与auxproxy开启认证时一样, 未签名或签名错误的请求返回403
+		if err := hhmac.ValidateHmac(r); err != nil {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		atomic.AddInt32(&validated, 1)
+		if r.URL.Path == auxproxyCleanUpStatePath {
+			_ = json.NewEncoder(w).Encode(instanceCleanUpState{State: cleanUpStateFinished})
+		}
+	}))
+	defer srv.Close()
+	useServerClient(t, srv)
+
+	_, err := drainInstance(srv.URL, time.Minute)
+	assert.Nil(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&validated))
+
+	local.Add(hhmac.LocalKeyAuxProxy, hhmac.LocalKeyEntry{Enable: true, AK: "aass", SK: []byte("wrong")})
+	_, err = drainInstance(srv.URL, time.Minute)
+	assert.NotNil(t, err)
+}
+
+func TestNewDrainHttpClient(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	dir := t.TempDir()
+
+	_, err := newDrainHttpClient("", "")
+	assert.NotNil(t, err)
+
+	invalidCa := filepath.Join(dir, "invalid.crt")
+	assert.Nil(t, ioutil.WriteFile(invalidCa, []byte("not a certificate"), 0600))
+	_, err = newDrainHttpClient(invalidCa, "")
+	assert.NotNil(t, err)
+
+	// 测试服务证书由自身签发, 作为CA时可以校验通过
+	ca := filepath.Join(dir, "ca.crt")
+	certPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	assert.Nil(t, ioutil.WriteFile(ca, certPem, 0600))
+	client, err := newDrainHttpClient(ca, "example.com")
+	assert.Nil(t, err)
+	resp, err := client.Get(srv.URL)
+	assert.Nil(t, err)
+	_ = resp.Body.Close()
+
+	// 证书中不包含的服务端名称校验失败
+	client, err = newDrainHttpClient(ca, "auxproxy.invalid")
+	assert.Nil(t, err)
+	_, err = client.Get(srv.URL)
+	assert.NotNil(t, err)
+}
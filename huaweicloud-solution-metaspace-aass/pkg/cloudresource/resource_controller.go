@@ -5,6 +5,7 @@ package cloudresource
 
 import (
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
@@ -44,6 +45,8 @@ const (
 	// ecs 虚机状态
 	ecsServerStatusShutoff = "SHUTOFF"
 	ecsServerStatusDeleted = "DELETED"
+	// ecs 虚机IP地址版本
+	ipVersion4 = "4"
 
 	// 批量移除as实例操作，单次最多操作50个实例
 	batchRemoveAsInstancesLimit = 50
@@ -202,6 +205,57 @@ func (c *ResourceController) DeleteAsScalingConfig(log *logger.FMLogger, configI
 	return nil
 }
 
+// ReplaceAsScalingConfigImage 以原伸缩配置为模板创建使用新镜像的伸缩配置, 并替换AS伸缩组的伸缩配置,
+// 返回新伸缩配置的Id, 原伸缩配置由调用方删除; 原伸缩配置已使用该镜像时直接返回原伸缩配置Id
+func (c *ResourceController) ReplaceAsScalingConfigImage(log *logger.FMLogger, fleetId, instanceScalingGroupId,
+	asGroupId, configId, imageId string) (string, error) {
+	showResp, err := c.asClient.ShowScalingConfig(&asmodel.ShowScalingConfigRequest{ScalingConfigurationId: configId})
+	if err != nil {
+		return "", errors.Wrapf(err, "as client show scaling config[%s] err", configId)
+	}
+	if showResp.ScalingConfiguration == nil || showResp.ScalingConfiguration.InstanceConfig == nil {
+		return "", errors.Errorf("instance config of as scaling config[%s] is empty", configId)
+	}
+	origin := showResp.ScalingConfiguration.InstanceConfig
+	if origin.ImageRef != nil && *origin.ImageRef == imageId {
+		return configId, nil
+	}
+
+	// 查询结果与创建参数的字段一致, 通过json转换保留规格、磁盘、EIP等配置
+	instanceConfig := &asmodel.InstanceConfig{}
+	if err = json.Unmarshal([]byte(utils.ToJson(origin)), instanceConfig); err != nil {
+		return "", errors.Wrapf(err, "convert instance config of as scaling config[%s] err", configId)
+	}
+	instanceConfig.InstanceId = nil
+	instanceConfig.ImageRef = &imageId
+	instanceConfig.UserData = genUserData(fleetId, instanceScalingGroupId)
+	createResp, err := c.asClient.CreateScalingConfig(&asmodel.CreateScalingConfigRequest{
+		Body: &asmodel.CreateScalingConfigOption{
+			ScalingConfigurationName: fmt.Sprintf("Fleet_%s_aass_config_%d", fleetId, time.Now().Unix()),
+			InstanceConfig:           instanceConfig,
+		}})
+	if err != nil {
+		return "", errors.Wrap(err, "as client create scaling config err")
+	}
+	newConfigId := *createResp.ScalingConfigurationId
+
+	_, err = c.asClient.UpdateScalingGroup(&asmodel.UpdateScalingGroupRequest{
+		ScalingGroupId: asGroupId,
+		Body: &asmodel.UpdateScalingGroupOption{
+			ScalingConfigurationId: &newConfigId,
+		},
+	})
+	if err != nil {
+		if delErr := c.DeleteAsScalingConfig(log, newConfigId); delErr != nil {
+			log.Warn("Delete unused as scaling config[%s] err: %+v", newConfigId, delErr)
+		}
+		return "", errors.Wrapf(err, "as client update scaling config of ScalingGroup[%s] err", asGroupId)
+	}
+	log.Info("Replace AsScalingConfig[%s] of ScalingGroup[%s] with [%s], image[%s]", configId,
+		instanceScalingGroupId, newConfigId, imageId)
+	return newConfigId, nil
+}
+
 // CreateAsScalingGroup 创建ScalingGroup
 func (c *ResourceController) CreateAsScalingGroup(log *logger.FMLogger, params CreatAsGroupParams,
 	groupId, resourceId string) (string, error) {
@@ -592,6 +646,23 @@ func (c *ResourceController) WaitVmShutoff(log *logger.FMLogger, serverId string
 	return nil
 }
 
+// GetServerFixedIp 查询云服务器的私有IP地址, 用于访问实例内的auxproxy
+func (c *ResourceController) GetServerFixedIp(serverId string) (string, error) {
+	resp, err := c.ecsClient.ShowServer(&ecsmodel.ShowServerRequest{ServerId: serverId})
+	if err != nil {
+		return "", errors.Wrapf(err, "ecs client show server[%s] err", serverId)
+	}
+	fixed := ecsmodel.GetServerAddressOSEXTIPStypeEnum().FIXED
+	for _, addresses := range resp.Server.Addresses {
+		for _, addr := range addresses {
+			if addr.OSEXTIPStype != nil && *addr.OSEXTIPStype == fixed && addr.Version == ipVersion4 {
+				return addr.Addr, nil
+			}
+		}
+	}
+	return "", errors.Errorf("no fixed ipv4 address of server[%s]", serverId)
+}
+
 // BatchStopServers 根据给定的云服务器ID列表，批量关闭云服务器，一次最多可以关闭1000台
 func (c *ResourceController) BatchStopServers(tLogger *logger.FMLogger, serverIds []string) error {
 	if len(serverIds) == 0 {
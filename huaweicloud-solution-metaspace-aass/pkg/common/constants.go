@@ -10,6 +10,23 @@ import (
 
 const (
 	PolicyTypeTargetBased = "TARGET_BASED"
+	PolicyTypeScheduled   = "SCHEDULED"
+	PolicyTypeStep        = "STEP"
+	PolicyTypePredictive  = "PREDICTIVE"
+
+	PredictiveModeForecastOnly     = "FORECAST_ONLY"
+	PredictiveModeForecastAndScale = "FORECAST_AND_SCALE"
+
+	AdjustmentTypeChangeInCapacity        = "CHANGE_IN_CAPACITY"
+	AdjustmentTypePercentChangeInCapacity = "PERCENT_CHANGE_IN_CAPACITY"
+
+	MetricNamePercentAvailableServerSessions = "PERCENT_AVAILABLE_SERVER_SESSIONS"
+	MetricNameCustomMetric                   = "CUSTOM_METRIC"
+	MetricNamePercentAvailableProcesses      = "PERCENT_AVAILABLE_PROCESSES"
+	MetricNameQueuedServerSessions           = "QUEUED_SERVER_SESSIONS"
+	MetricNameCPUUtilization                 = "CPU_UTILIZATION"
+	MetricNameMemoryUtilization              = "MEMORY_UTILIZATION"
+	MaxTargetValueOfPercentMetric            = 100
 
 	DiskTypeSYS  = "SYS"
 	DiskTypeDATA = "DATA"
@@ -178,6 +178,20 @@ func DeleteAsyncTask(taskType, taskKey string) error {
 	return nil
 }
 
+// GetLatestAsyncTaskId 查询资源对象最近一次创建的指定类型任务的Id, 包括已执行完成的任务
+func GetLatestAsyncTaskId(taskType, taskKey string) (int, error) {
+	var task AsyncTask
+	err := ormer.QueryTable(tableNameAsyncTask).
+		Filter(fieldNameTaskType, taskType).
+		Filter(fieldNameTaskKey, taskKey).
+		OrderBy("-"+fieldNameId).
+		One(&task, fieldNameId)
+	if err != nil {
+		return 0, errors.Wrapf(err, "get latest async task[%s:%s] from db err", taskType, taskKey)
+	}
+	return task.Id, nil
+}
+
 // GetAllAsyncTasks ...
 func GetAllAsyncTasks() ([]*AsyncTask, error) {
 	var tasks []*AsyncTask
@@ -32,9 +32,23 @@ const (
 	fieldNameTargetValue     = "target_value"
 	fleldNameInstanceTags	 = "instance_tags"
 
-	fieldNameStateIn    = "state__in"
-	fieldNameIdIn       = "id__in"
-	fieldNameUpdateAtLt = "update_at__lt"
+	fieldNameMetricName        = "metric_name"
+	fieldNameCustomMetricName  = "custom_metric_name"
+	fieldNameTriggeredAt       = "triggered_at"
+	fieldNameScaleOutTimestamp = "scale_out_timestamp"
+	fieldNameScaleInTimestamp  = "scale_in_timestamp"
+	fieldNameForecastTime      = "forecast_time"
+	fieldNameEvaluatedAt       = "evaluated_at"
+
+	fieldNameStateIn      = "state__in"
+	fieldNameIdIn         = "id__in"
+	fieldNamePolicyTypeIn = "policy_type__in"
+	fieldNameUpdateAtLt   = "update_at__lt"
+
+	fieldNameForecastTimeGte = "forecast_time__gte"
+	fieldNameForecastTimeLt  = "forecast_time__lt"
+	fieldNameEvaluatedAtGte  = "evaluated_at__gte"
+	fieldNameEvaluatedAtLt   = "evaluated_at__lt"
 
 	notDeletedFlag = "0"
 	deletedFlag    = "1"
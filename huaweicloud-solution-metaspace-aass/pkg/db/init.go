@@ -47,6 +47,8 @@ func Init() error {
 		new(DeletingVm),
 		new(AsyncTask),
 		new(MetricMonitorTask),
+		new(ScalingForecast),
+		new(ScalingDecisionLog),
 		new(LtsConfig),
 		new(LogTransfer),
 	)
@@ -17,12 +17,19 @@ const (
 )
 
 type MetricMonitorTask struct {
-	Id              string `orm:"column(id);size(128);pk"`
-	MetricName      string `orm:"column(metric_name);size(128)"`             // 指标名称
-	TargetValue     int32  `orm:"column(target_value);type(int);default(0)"` // 目标阈值
-	ScalingGroupID  string `orm:"column(scaling_group_id);size(128)"`
-	ScalingPolicyID string `orm:"column(scaling_policy_id);size(128)"`
-	WorkNodeId      string `orm:"column(work_node_id);size(128)"` // 任务执行节点
+	Id               string `orm:"column(id);size(128);pk"`
+	MetricName       string `orm:"column(metric_name);size(128)"`             // 指标名称
+	CustomMetricName string `orm:"column(custom_metric_name);size(128)"`      // 自定义指标名称
+	TargetValue      int32  `orm:"column(target_value);type(int);default(0)"` // 目标阈值
+	ScalingGroupID   string `orm:"column(scaling_group_id);size(128)"`
+	ScalingPolicyID  string `orm:"column(scaling_policy_id);size(128)"`
+	WorkNodeId       string `orm:"column(work_node_id);size(128)"` // 任务执行节点
+	PolicyType       string `orm:"column(policy_type);size(64);default(TARGET_BASED)"`
+	// 定时策略最近一次执行的时间窗开始时间, 预测策略最近一次预测的时间段开始时间(Unix秒)
+	TriggeredAt int64 `orm:"column(triggered_at);type(bigint);default(0)"`
+	// 步进策略最近一次扩容和缩容的时间(Unix纳秒), 用于分别计算扩容和缩容的冷却时间
+	ScaleOutTimestamp int64 `orm:"column(scale_out_timestamp);type(bigint);default(0)"`
+	ScaleInTimestamp  int64 `orm:"column(scale_in_timestamp);type(bigint);default(0)"`
 	TimeModel
 }
 
@@ -58,12 +65,14 @@ func DeleteMetricMonitorTask(id string) error {
 }
 
 // UpdateMetricMonitorTask ...
-func UpdateMetricMonitorTask(id string, value int32) error {
+func UpdateMetricMonitorTask(id, metricName, customMetricName string, value int32) error {
 	_, err := ormer.QueryTable(tableNameMetricMonitorTask).
 		Filter(fieldNameIsDeleted, notDeletedFlag).Filter(fieldNameId, id).
 		Update(orm.Params{
-			fieldNameTargetValue: value,
-			fieldNameUpdateAt:    time.Now().UTC()})
+			fieldNameMetricName:       metricName,
+			fieldNameCustomMetricName: customMetricName,
+			fieldNameTargetValue:      value,
+			fieldNameUpdateAt:         time.Now().UTC()})
 	if err != nil {
 		if errors.Is(err, orm.ErrNoRows) {
 			return nil
@@ -73,6 +82,42 @@ func UpdateMetricMonitorTask(id string, value int32) error {
 	return nil
 }
 
+// UpdateMetricMonitorTaskTriggeredAt 记录定时策略已执行的时间窗或预测策略已预测的时间段
+func UpdateMetricMonitorTaskTriggeredAt(id string, triggeredAt int64) error {
+	_, err := ormer.QueryTable(tableNameMetricMonitorTask).
+		Filter(fieldNameIsDeleted, notDeletedFlag).Filter(fieldNameId, id).
+		Update(orm.Params{
+			fieldNameTriggeredAt: triggeredAt,
+			fieldNameUpdateAt:    time.Now().UTC()})
+	if err != nil {
+		if errors.Is(err, orm.ErrNoRows) {
+			return nil
+		}
+		return errors.Wrapf(err, "update triggered_at of metric monitor task[%s] err", id)
+	}
+	return nil
+}
+
+// UpdateMetricMonitorTaskScaleTimestamp 记录步进策略的扩容或缩容时间
+func UpdateMetricMonitorTaskScaleTimestamp(id string, scaleOut bool) error {
+	field := fieldNameScaleInTimestamp
+	if scaleOut {
+		field = fieldNameScaleOutTimestamp
+	}
+	_, err := ormer.QueryTable(tableNameMetricMonitorTask).
+		Filter(fieldNameIsDeleted, notDeletedFlag).Filter(fieldNameId, id).
+		Update(orm.Params{
+			field:             time.Now().UnixNano(),
+			fieldNameUpdateAt: time.Now().UTC()})
+	if err != nil {
+		if errors.Is(err, orm.ErrNoRows) {
+			return nil
+		}
+		return errors.Wrapf(err, "update %s of metric monitor task[%s] err", field, id)
+	}
+	return nil
+}
+
 // GetMetricMonitorTaskById ...
 func GetMetricMonitorTaskById(id string) (*MetricMonitorTask, error) {
 	return getMetricMonitorTaskByFilters(Filters{
@@ -97,6 +142,31 @@ func GetMetricMonitorTasksByWorkNodeId(wnId string) ([]*MetricMonitorTask, error
 	})
 }
 
+// ListTargetBasedMetricMonitorTasksOfGroup 按策略Id排序查询伸缩组内基于目标策略的监控任务
+func ListTargetBasedMetricMonitorTasksOfGroup(groupId string) ([]*MetricMonitorTask, error) {
+	var tasks []*MetricMonitorTask
+	_, err := ormer.QueryTable(tableNameMetricMonitorTask).Filter(fieldNameIsDeleted, notDeletedFlag).
+		Filter(fieldNameScalingGroupId, groupId).Filter(fieldNamePolicyType, common.PolicyTypeTargetBased).
+		OrderBy(fieldNameScalingPolicyId).All(&tasks)
+	if err != nil {
+		return nil, errors.Wrapf(err, "list target based metric monitor tasks of group[%s] err", groupId)
+	}
+	return tasks, nil
+}
+
+// ListMetricBasedMetricMonitorTasksOfGroup 按策略Id排序查询伸缩组内基于目标策略及步进策略的监控任务
+func ListMetricBasedMetricMonitorTasksOfGroup(groupId string) ([]*MetricMonitorTask, error) {
+	var tasks []*MetricMonitorTask
+	_, err := ormer.QueryTable(tableNameMetricMonitorTask).Filter(fieldNameIsDeleted, notDeletedFlag).
+		Filter(fieldNameScalingGroupId, groupId).
+		Filter(fieldNamePolicyTypeIn, []string{common.PolicyTypeTargetBased, common.PolicyTypeStep}).
+		OrderBy(fieldNameScalingPolicyId).All(&tasks)
+	if err != nil {
+		return nil, errors.Wrapf(err, "list metric based metric monitor tasks of group[%s] err", groupId)
+	}
+	return tasks, nil
+}
+
 func getMetricMonitorTaskByFilters(f Filters) (*MetricMonitorTask, error) {
 	var task MetricMonitorTask
 	if err := f.Filter(tableNameMetricMonitorTask).RelatedSel().One(&task); err != nil {
@@ -0,0 +1,86 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 自动伸缩决定记录数据表定义
+package db
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	tableNameScalingDecisionLog = "scaling_decision_log"
+)
+
+// ScalingDecisionLog 监控任务每次伸缩判断的输入、伸缩决定以及结果, 用于事后分析伸缩原因
+type ScalingDecisionLog struct {
+	Id              int64  `orm:"column(id);auto;pk"`
+	ScalingGroupId  string `orm:"column(scaling_group_id);size(128)"`
+	ScalingPolicyId string `orm:"column(scaling_policy_id);size(128)"`
+	PolicyType      string `orm:"column(policy_type);size(64)"`
+	// 伸缩判断使用的指标名到指标值的json
+	Metrics               string `orm:"column(metrics);type(text)"`
+	CurrentInstanceNumber int32  `orm:"column(current_instance_number);type(int)"`
+	// 伸缩判断时生效的实例数上下限, 包括定时策略和预测策略的调整
+	MinInstanceNumber int32 `orm:"column(min_instance_number);type(int)"`
+	MaxInstanceNumber int32 `orm:"column(max_instance_number);type(int)"`
+	InCoolDown        bool  `orm:"column(in_cool_down)"`
+	// 伸缩决定, 参考model.ScalingDecision
+	Action        string  `orm:"column(action);size(64)"`
+	CalculatedNum float64 `orm:"column(calculated_num)"`
+	AvailableNum  float64 `orm:"column(available_num)"`
+	ScalingNum    float64 `orm:"column(scaling_num)"`
+	Instances     string  `orm:"column(instances);type(text)"`
+	// 启动的伸缩任务的Id, 未启动伸缩任务时为0并记录跳过原因
+	TaskId      int       `orm:"column(task_id)"`
+	SkipReason  string    `orm:"column(skip_reason);size(64)"`
+	Message     string    `orm:"column(message);size(1024)"`
+	DryRun      bool      `orm:"column(dry_run)"`
+	EvaluatedAt time.Time `orm:"column(evaluated_at);type(datetime)"`
+}
+
+// TableIndex 设置索引
+func (l *ScalingDecisionLog) TableIndex() [][]string {
+	return [][]string{
+		{"ScalingGroupId", "EvaluatedAt"},
+		{"EvaluatedAt"},
+	}
+}
+
+// AddScalingDecisionLog add ScalingDecisionLog
+func AddScalingDecisionLog(decisionLog *ScalingDecisionLog) error {
+	if decisionLog == nil {
+		return errors.New("func AddScalingDecisionLog has invalid args")
+	}
+	if _, err := ormer.Insert(decisionLog); err != nil {
+		return errors.Wrapf(err, "add scaling decision log of group[%s] err", decisionLog.ScalingGroupId)
+	}
+	return nil
+}
+
+// ListScalingDecisionLogs 按时间倒序查询伸缩组在[start, end)内的伸缩决定记录, 返回记录和总数
+func ListScalingDecisionLogs(groupId string, start, end time.Time, limit,
+	offset int) ([]*ScalingDecisionLog, int64, error) {
+	qs := ormer.QueryTable(tableNameScalingDecisionLog).Filter(fieldNameScalingGroupId, groupId).
+		Filter(fieldNameEvaluatedAtGte, start).Filter(fieldNameEvaluatedAtLt, end)
+	total, err := qs.Count()
+	if err != nil {
+		return nil, 0, errors.Wrapf(err, "count scaling decision logs of group[%s] err", groupId)
+	}
+	var list []*ScalingDecisionLog
+	_, err = qs.OrderBy("-"+fieldNameEvaluatedAt, "-"+fieldNameId).Limit(limit, offset).All(&list)
+	if err != nil {
+		return nil, 0, errors.Wrapf(err, "list scaling decision logs of group[%s] err", groupId)
+	}
+	return list, total, nil
+}
+
+// DeleteExpiredScalingDecisionLogs 删除早于before的伸缩决定记录, 包括已删除伸缩组的记录
+func DeleteExpiredScalingDecisionLogs(before time.Time) error {
+	_, err := ormer.QueryTable(tableNameScalingDecisionLog).Filter(fieldNameEvaluatedAtLt, before).Delete()
+	if err != nil {
+		return errors.Wrap(err, "delete expired scaling decision logs err")
+	}
+	return nil
+}
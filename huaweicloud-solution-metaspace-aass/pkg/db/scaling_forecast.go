@@ -0,0 +1,64 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 预测策略的需求预测数据表定义
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	tableNameScalingForecast = "scaling_forecast"
+)
+
+// ScalingForecast 预测策略对某个时间段的需求预测, 每次预测覆盖未来时间段之前的预测,
+// 时间段开始后保留的是最后一次预测的结果, 用于与实际需求对比
+type ScalingForecast struct {
+	Id              string    `orm:"column(id);size(192);pk"`
+	ScalingPolicyId string    `orm:"column(scaling_policy_id);size(128);index"`
+	ScalingGroupId  string    `orm:"column(scaling_group_id);size(128)"`
+	ForecastTime    int64     `orm:"column(forecast_time);type(bigint);index"` // 时间段的开始时间(Unix秒)
+	Demand          float64   `orm:"column(demand)"`                           // 预测的ServerSession需求
+	InstanceNumber  int32     `orm:"column(instance_number);type(int)"`        // 满足预测需求的实例数
+	UpdateAt        time.Time `orm:"column(update_at);type(datetime);auto_now"`
+}
+
+// ScalingForecastId 同一策略同一时间段的预测只保留一条
+func ScalingForecastId(policyId string, forecastTime int64) string {
+	return fmt.Sprintf("%s-%d", policyId, forecastTime)
+}
+
+// SaveScalingForecasts 写入预测, 已存在的时间段覆盖为最新的预测
+func SaveScalingForecasts(forecasts []*ScalingForecast) error {
+	for _, f := range forecasts {
+		f.Id = ScalingForecastId(f.ScalingPolicyId, f.ForecastTime)
+		if _, err := ormer.InsertOrUpdate(f); err != nil {
+			return errors.Wrapf(err, "save scaling forecast[%s] err", f.Id)
+		}
+	}
+	return nil
+}
+
+// ListScalingForecasts 按时间段顺序查询策略在[start, end)内的预测
+func ListScalingForecasts(policyId string, start, end int64) ([]*ScalingForecast, error) {
+	var list []*ScalingForecast
+	_, err := ormer.QueryTable(tableNameScalingForecast).Filter(fieldNameScalingPolicyId, policyId).
+		Filter(fieldNameForecastTimeGte, start).Filter(fieldNameForecastTimeLt, end).
+		OrderBy(fieldNameForecastTime).All(&list)
+	if err != nil {
+		return nil, errors.Wrapf(err, "list scaling forecasts of policy[%s] err", policyId)
+	}
+	return list, nil
+}
+
+// DeleteExpiredScalingForecasts 删除时间段早于before的预测, 包括已删除策略的预测
+func DeleteExpiredScalingForecasts(before int64) error {
+	_, err := ormer.QueryTable(tableNameScalingForecast).Filter(fieldNameForecastTimeLt, before).Delete()
+	if err != nil {
+		return errors.Wrap(err, "delete expired scaling forecasts err")
+	}
+	return nil
+}
@@ -21,8 +21,10 @@ type ScalingPolicy struct {
 	Id           string        `orm:"column(id);size(64);pk"`
 	Name         string        `orm:"column(name);size(64)"`
 	PolicyType   string        `orm:"column(policy_type);size(64)"`
-	PolicyConfig string        `orm:"column(policy_config);size(512)"`
+	PolicyConfig string        `orm:"column(policy_config);size(2048)"`
 	ProjectId    string        `orm:"column(project_id);size(64)"`
+	// DryRun 只记录伸缩决定, 不启动伸缩任务
+	DryRun bool `orm:"column(dry_run);default(false)"`
 	TimeModel
 }
 
@@ -39,6 +41,7 @@ func (p *ScalingPolicy) replace(newPolicy *ScalingPolicy) {
 	if newPolicy.PolicyConfig != "" {
 		p.PolicyConfig = newPolicy.PolicyConfig
 	}
+	p.DryRun = newPolicy.DryRun
 }
 
 // AddScalingPolicy add ScalingPolicy
@@ -103,10 +106,25 @@ func IsScalingPolicyExist(projectId, policyId string) bool {
 		Filter(fieldNameProjectId, projectId).Filter(fieldNameId, policyId).Exist()
 }
 
-// IsTargetBasedPolicyExistInScalingGroup check whether the target based policy exists in the ScalingGroup
-func IsTargetBasedPolicyExistInScalingGroup(projectId, groupId string) bool {
+// IsMetricBasedPolicyExistInScalingGroup check whether the target based or step policy exists in the ScalingGroup
+func IsMetricBasedPolicyExistInScalingGroup(projectId, groupId string) bool {
 	return ormer.QueryTable(tableNameScalingPolicy).Filter(fieldNameIsDeleted, notDeletedFlag).
-		Filter(fieldNameProjectId, projectId).Filter(fieldNamePolicyType, common.PolicyTypeTargetBased).
+		Filter(fieldNameProjectId, projectId).
+		Filter(fieldNamePolicyTypeIn, []string{common.PolicyTypeTargetBased, common.PolicyTypeStep}).
+		Filter(fieldNameScalingGroupId, groupId).Exist()
+}
+
+// IsStepPolicyExistInScalingGroup check whether the step policy exists in the ScalingGroup
+func IsStepPolicyExistInScalingGroup(projectId, groupId string) bool {
+	return ormer.QueryTable(tableNameScalingPolicy).Filter(fieldNameIsDeleted, notDeletedFlag).
+		Filter(fieldNameProjectId, projectId).Filter(fieldNamePolicyType, common.PolicyTypeStep).
+		Filter(fieldNameScalingGroupId, groupId).Exist()
+}
+
+// IsPredictivePolicyExistInScalingGroup check whether the predictive policy exists in the ScalingGroup
+func IsPredictivePolicyExistInScalingGroup(projectId, groupId string) bool {
+	return ormer.QueryTable(tableNameScalingPolicy).Filter(fieldNameIsDeleted, notDeletedFlag).
+		Filter(fieldNameProjectId, projectId).Filter(fieldNamePolicyType, common.PolicyTypePredictive).
 		Filter(fieldNameScalingGroupId, groupId).Exist()
 }
 
@@ -0,0 +1,141 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 自动伸缩决定记录
+package metricmonitor
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"scase.io/application-auto-scaling-service/pkg/common"
+	"scase.io/application-auto-scaling-service/pkg/db"
+	"scase.io/application-auto-scaling-service/pkg/metricmonitor/model"
+	"scase.io/application-auto-scaling-service/pkg/setting"
+	"scase.io/application-auto-scaling-service/pkg/utils"
+	"scase.io/application-auto-scaling-service/pkg/utils/logger"
+)
+
+// 未启动伸缩任务的原因
+const (
+	skipReasonInCoolDown                = "IN_COOL_DOWN"
+	skipReasonInstanceNumberQueryFailed = "INSTANCE_NUMBER_QUERY_FAILED"
+	skipReasonMetricQueryFailed         = "METRIC_QUERY_FAILED"
+	skipReasonNoMetricData              = "NO_METRIC_DATA"
+	skipReasonNoScalingNeeded           = "NO_SCALING_NEEDED"
+	skipReasonDryRun                    = "DRY_RUN"
+	skipReasonGroupNotStable            = "GROUP_NOT_STABLE"
+	skipReasonTaskStartFailed           = "TASK_START_FAILED"
+)
+
+const (
+	maxDecisionMessageLength = 1024
+	// decisionLogCleanPeriod 清理过期伸缩决定记录的周期
+	decisionLogCleanPeriod = "@every 1h"
+)
+
+// policyOfGroup 从伸缩组的策略中查找策略, 策略已删除时返回nil
+func policyOfGroup(group *db.ScalingGroup, policyId string) *db.ScalingPolicy {
+	for _, policy := range group.ScalingPolicies {
+		if policy.Id == policyId {
+			return policy
+		}
+	}
+	return nil
+}
+
+// newDecisionLog 构造一次伸缩判断的记录, 策略为dry run时只记录伸缩决定
+func newDecisionLog(group *db.ScalingGroup, task *db.MetricMonitorTask) *db.ScalingDecisionLog {
+	record := &db.ScalingDecisionLog{
+		ScalingGroupId:  group.Id,
+		ScalingPolicyId: task.ScalingPolicyID,
+		PolicyType:      task.PolicyType,
+		EvaluatedAt:     time.Now().UTC(),
+	}
+	if policy := policyOfGroup(group, task.ScalingPolicyID); policy != nil {
+		record.PolicyType = policy.PolicyType
+		record.DryRun = policy.DryRun
+	}
+	return record
+}
+
+// setInstanceNumbers 记录伸缩判断时的实例数以及生效的上下限
+func setInstanceNumbers(record *db.ScalingDecisionLog, curNum, minNum, maxNum int32) {
+	record.CurrentInstanceNumber = curNum
+	record.MinInstanceNumber = minNum
+	record.MaxInstanceNumber = maxNum
+}
+
+// setDecision 记录伸缩决定以及伸缩判断使用的指标
+func setDecision(record *db.ScalingDecisionLog, decision *model.ScalingDecision) {
+	record.Action = decision.Action
+	record.CalculatedNum = decision.CalculatedNum
+	record.AvailableNum = decision.AvailableNum
+	record.ScalingNum = decision.ScalingNum
+	if len(decision.Instances) > 0 {
+		record.Instances = utils.ToJson(decision.Instances)
+	}
+	if len(decision.Metrics) > 0 {
+		record.Metrics = utils.ToJson(decision.Metrics)
+	}
+	if decision.Reason != "" {
+		record.Message = decision.Reason
+	}
+}
+
+// skipDecision 记录未启动伸缩任务的原因, message为空时保留伸缩决定的依据
+func skipDecision(record *db.ScalingDecisionLog, reason, message string) {
+	record.SkipReason = reason
+	if message != "" {
+		record.Message = message
+	}
+}
+
+// saveDecisionLog 保存伸缩判断的记录, 保存失败不影响伸缩
+func saveDecisionLog(log *logger.FMLogger, record *db.ScalingDecisionLog) {
+	if len(record.Message) > maxDecisionMessageLength {
+		record.Message = record.Message[:maxDecisionMessageLength]
+	}
+	if err := db.AddScalingDecisionLog(record); err != nil {
+		log.Error("Save scaling decision log of group[%s] err: %+v", record.ScalingGroupId, err)
+	}
+}
+
+// startScalingTask 启动伸缩任务并记录任务Id或未启动的原因, dry run的策略不启动伸缩任务, 返回伸缩任务是否启动成功
+func startScalingTask(log *logger.FMLogger, group *db.ScalingGroup, record *db.ScalingDecisionLog, scaleOut bool,
+	start func() error) bool {
+	if record.DryRun {
+		log.Info("Policy[%s] is dry run, skip %s of ScalingGroup[%s]", record.ScalingPolicyId, record.Action,
+			group.Id)
+		skipDecision(record, skipReasonDryRun, "")
+		return false
+	}
+	err := start()
+	if !finishScaling(log, group.Id, err) {
+		reason := skipReasonTaskStartFailed
+		if errors.Is(err, common.ErrScalingGroupNotStable) {
+			reason = skipReasonGroupNotStable
+		}
+		skipDecision(record, reason, err.Error())
+		return false
+	}
+	taskType := db.TaskTypeScaleInScalingGroup
+	if scaleOut {
+		taskType = db.TaskTypeScaleOutScalingGroup
+	}
+	taskId, err := db.GetLatestAsyncTaskId(taskType, group.Id)
+	if err != nil {
+		log.Error("Get started %s task of ScalingGroup[%s] err: %+v", taskType, group.Id, err)
+		return true
+	}
+	record.TaskId = taskId
+	return true
+}
+
+// cleanExpiredDecisionLogs 删除超过保留时长的伸缩决定记录
+func cleanExpiredDecisionLogs() {
+	retention := time.Duration(setting.GetDecisionLogRetentionDays()) * 24 * time.Hour
+	if err := db.DeleteExpiredScalingDecisionLogs(time.Now().UTC().Add(-retention)); err != nil {
+		logger.R.Error("Delete expired scaling decision logs err: %+v", err)
+	}
+}
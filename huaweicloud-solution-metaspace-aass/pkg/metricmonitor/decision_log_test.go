@@ -0,0 +1,58 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 自动伸缩决定记录测试
+package metricmonitor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"scase.io/application-auto-scaling-service/pkg/common"
+	"scase.io/application-auto-scaling-service/pkg/db"
+	"scase.io/application-auto-scaling-service/pkg/metricmonitor/model"
+)
+
+func TestNewDecisionLog(t *testing.T) {
+	group := &db.ScalingGroup{Id: "group", ScalingPolicies: []*db.ScalingPolicy{
+		{Id: "step", PolicyType: common.PolicyTypeStep, DryRun: true},
+	}}
+	record := newDecisionLog(group, &db.MetricMonitorTask{ScalingPolicyID: "step"})
+	assert.Equal(t, "group", record.ScalingGroupId)
+	assert.Equal(t, "step", record.ScalingPolicyId)
+	assert.Equal(t, common.PolicyTypeStep, record.PolicyType)
+	assert.True(t, record.DryRun)
+	assert.False(t, record.EvaluatedAt.IsZero())
+
+	record = newDecisionLog(group, &db.MetricMonitorTask{ScalingPolicyID: "deleted",
+		PolicyType: common.PolicyTypeTargetBased})
+	assert.Equal(t, common.PolicyTypeTargetBased, record.PolicyType)
+	assert.False(t, record.DryRun)
+}
+
+func TestSetDecision(t *testing.T) {
+	record := &db.ScalingDecisionLog{}
+	setDecision(record, &model.ScalingDecision{
+		Action:        model.ScalingDecisionActionIn,
+		CalculatedNum: 3,
+		AvailableNum:  2,
+		ScalingNum:    2,
+		Instances:     []string{"vm-1", "vm-2"},
+		Metrics:       map[string]float64{common.MetricNamePercentAvailableServerSessions: 80},
+	})
+	assert.Equal(t, model.ScalingDecisionActionIn, record.Action)
+	assert.Equal(t, float64(2), record.ScalingNum)
+	assert.Equal(t, `["vm-1","vm-2"]`, record.Instances)
+	assert.Equal(t, `{"PERCENT_AVAILABLE_SERVER_SESSIONS":80}`, record.Metrics)
+
+	// 没有指标的伸缩决定不覆盖已记录的指标
+	setDecision(record, &model.ScalingDecision{Action: model.ScalingDecisionActionOut})
+	assert.Equal(t, `{"PERCENT_AVAILABLE_SERVER_SESSIONS":80}`, record.Metrics)
+
+	// 未启动伸缩任务的原因没有说明时保留伸缩决定的依据
+	setDecision(record, &model.ScalingDecision{Action: model.ScalingDecisionActionNone,
+		Reason: "desired instance numbers: CPU_UTILIZATION=4"})
+	skipDecision(record, skipReasonNoScalingNeeded, "")
+	assert.Equal(t, skipReasonNoScalingNeeded, record.SkipReason)
+	assert.Equal(t, "desired instance numbers: CPU_UTILIZATION=4", record.Message)
+}
@@ -20,11 +20,25 @@ const (
 	usedServerSession             = "sum(server_session_count)"
 	maxServerSession              = "sum(max_server_session_num)"
 	tagsOfGroupByInstance         = "scaling_group_id,instance_id"
+
+	// measurementServerSessionQueue appgateway上报的fleet排队深度
+	measurementServerSessionQueue = "server_session_queue"
+	// fieldPrefixCustomMetric appgateway写入进程自定义指标时的字段前缀
+	fieldPrefixCustomMetric = "custom_"
+	// measurementInstance auxproxy上报的实例CPU和内存使用率
+	measurementInstance = "instance"
+	tagOfProcess        = "id"
+
+	FieldCpuUtilization    = "cpu_utilization"
+	FieldMemoryUtilization = "memory_utilization"
 )
 
 const (
 	queryDuration     int64 = 10 * 1e9
 	pingTimeOutSecond       = 3 * time.Second
+
+	// customMetricQueryDuration auxproxy每10秒批量上报一次自定义指标, 查询窗口覆盖多次上报
+	customMetricQueryDuration int64 = 30 * 1e9
 )
 
 type Controller struct {
@@ -104,6 +118,177 @@ func (c *Controller) GetServerSessionMetricsOfScalingGroup(log *logger.FMLogger,
 	return nil, nil
 }
 
+// GetCustomMetricOfScalingGroup 获取伸缩组内进程自定义指标的平均值; COUNTER类指标由auxproxy换算为每秒速率后上报,
+// 平均值为单个进程的平均速率, 与GAUGE一样可以和目标值比较
+func (c *Controller) GetCustomMetricOfScalingGroup(log *logger.FMLogger, groupID,
+	metricName string) (*GroupCustomMetric, error) {
+	log.Info("influxDB query custom metric[%s] of ScalingGroup[%s]", metricName, groupID)
+	return c.getMeanOfScalingGroup(c.measurement, fieldPrefixCustomMetric+metricName, groupID)
+}
+
+// GetInstanceMetricOfScalingGroup 获取伸缩组内实例CPU或内存使用率的平均值
+func (c *Controller) GetInstanceMetricOfScalingGroup(log *logger.FMLogger, groupID,
+	field string) (*GroupCustomMetric, error) {
+	log.Info("influxDB query instance metric[%s] of ScalingGroup[%s]", field, groupID)
+	return c.getMeanOfScalingGroup(measurementInstance, field, groupID)
+}
+
+// getMeanOfScalingGroup 查询伸缩组内最近上报的指标平均值, 没有数据时返回nil
+func (c *Controller) getMeanOfScalingGroup(measurement, field, groupID string) (*GroupCustomMetric, error) {
+	command := fmt.Sprintf("SELECT mean(\"%s\") FROM %s WHERE scaling_group_id = '%s' "+
+		"AND time >= now()-%ds", field, measurement, groupID, customMetricQueryDuration/1e9)
+	q := influx.NewQuery(command, c.database, c.timePrecision)
+	resp, err := c.client.Query(q)
+	if err != nil {
+		return nil, err
+	} else if resp.Error() != nil {
+		return nil, resp.Error()
+	}
+	if resp.Results == nil || resp.Results[0].Series == nil || resp.Results[0].Series[0].Values == nil {
+		return nil, nil
+	}
+
+	timestamp, err := getInt64ForInfluxValue(resp.Results[0].Series[0].Values[0][0])
+	if err != nil {
+		return nil, err
+	}
+	value, err := getFloat64ForInfluxValue(resp.Results[0].Series[0].Values[0][1])
+	if err != nil {
+		return nil, err
+	}
+	return &GroupCustomMetric{
+		Value:     value,
+		StartTime: timestamp,
+		EndTime:   timestamp + customMetricQueryDuration,
+	}, nil
+}
+
+// GetProcessMetricsOfScalingGroup 按进程统计伸缩组内的进程数以及还能接受ServerSession的进程数
+func (c *Controller) GetProcessMetricsOfScalingGroup(log *logger.FMLogger,
+	groupID string) (*GroupProcessMetrics, error) {
+	command := fmt.Sprintf("SELECT last(server_session_count) AS USED,last(max_server_session_num) AS MAX "+
+		"FROM %s WHERE scaling_group_id = '%s' AND time >= now()-20s AND time < now()-10s GROUP BY %s",
+		c.measurement, groupID, tagOfProcess)
+	log.Info("influxDB query command of getting process metrics: [%s]", command)
+	q := influx.NewQuery(command, c.database, c.timePrecision)
+	resp, err := c.client.Query(q)
+	if err != nil {
+		return nil, err
+	} else if resp.Error() != nil {
+		return nil, resp.Error()
+	}
+	if resp.Results == nil || resp.Results[0].Series == nil {
+		return nil, nil
+	}
+
+	metrics := &GroupProcessMetrics{}
+	for _, series := range resp.Results[0].Series {
+		// 只上报了自定义指标的进程没有内置指标
+		if len(series.Values) == 0 || len(series.Values[0]) < 3 ||
+			series.Values[0][1] == nil || series.Values[0][2] == nil {
+			continue
+		}
+		timestamp, err := getInt64ForInfluxValue(series.Values[0][0])
+		if err != nil {
+			return nil, err
+		}
+		used, err := getInt64ForInfluxValue(series.Values[0][1])
+		if err != nil {
+			return nil, err
+		}
+		max, err := getInt64ForInfluxValue(series.Values[0][2])
+		if err != nil {
+			return nil, err
+		}
+		metrics.TotalNum++
+		if used < max {
+			metrics.AvailableNum++
+		}
+		if metrics.StartTime == 0 || timestamp < metrics.StartTime {
+			metrics.StartTime = timestamp
+		}
+	}
+	if metrics.TotalNum == 0 {
+		return nil, nil
+	}
+	metrics.EndTime = metrics.StartTime + queryDuration
+	return metrics, nil
+}
+
+// GetQueuedServerSessionsOfFleet 获取fleet中排队等待进程的ServerSession数量, 没有数据时为0
+func (c *Controller) GetQueuedServerSessionsOfFleet(log *logger.FMLogger, fleetID string) (int64, error) {
+	command := fmt.Sprintf("SELECT last(queue_depth) FROM %s WHERE fleet_id = '%s' AND time >= now()-20s",
+		measurementServerSessionQueue, fleetID)
+	log.Info("influxDB query command of getting queued server sessions: [%s]", command)
+	q := influx.NewQuery(command, c.database, c.timePrecision)
+	resp, err := c.client.Query(q)
+	if err != nil {
+		return 0, err
+	} else if resp.Error() != nil {
+		return 0, resp.Error()
+	}
+	if resp.Results == nil || resp.Results[0].Series == nil || resp.Results[0].Series[0].Values == nil {
+		return 0, nil
+	}
+	return getInt64ForInfluxValue(resp.Results[0].Series[0].Values[0][1])
+}
+
+// GetServerSessionDemandOfScalingGroup 按时间段统计[start, end)内伸缩组已使用和fleet排队的ServerSession数量的峰值,
+// 返回时间段开始时间(ns)到需求的映射, 没有数据的时间段不返回
+func (c *Controller) GetServerSessionDemandOfScalingGroup(log *logger.FMLogger, groupID, fleetID string,
+	start, end int64, bucket time.Duration) (map[int64]float64, error) {
+	command := fmt.Sprintf("SELECT max(USED) FROM (SELECT %s AS USED FROM %s WHERE scaling_group_id = '%s' "+
+		"AND time >= %dns AND time < %dns GROUP BY time(%ds)) WHERE time >= %dns AND time < %dns "+
+		"GROUP BY time(%ds) fill(none)", usedServerSession, c.measurement, groupID, start, end,
+		queryDuration/1e9, start, end, int64(bucket/time.Second))
+	log.Info("influxDB query command of getting server session demand: [%s]", command)
+	demand, err := c.queryBucketValues(command)
+	if err != nil {
+		return nil, err
+	}
+
+	command = fmt.Sprintf("SELECT max(queue_depth) FROM %s WHERE fleet_id = '%s' AND time >= %dns "+
+		"AND time < %dns GROUP BY time(%ds) fill(none)", measurementServerSessionQueue, fleetID, start, end,
+		int64(bucket/time.Second))
+	log.Info("influxDB query command of getting queued server session demand: [%s]", command)
+	queued, err := c.queryBucketValues(command)
+	if err != nil {
+		log.Error("it's failed to get queued server session demand of fleet[%s], err: %s", fleetID, err.Error())
+		return demand, nil
+	}
+	for t, v := range queued {
+		demand[t] += v
+	}
+	return demand, nil
+}
+
+// queryBucketValues 执行按时间分组的查询, 返回时间到第一个字段值的映射
+func (c *Controller) queryBucketValues(command string) (map[int64]float64, error) {
+	q := influx.NewQuery(command, c.database, c.timePrecision)
+	resp, err := c.client.Query(q)
+	if err != nil {
+		return nil, err
+	} else if resp.Error() != nil {
+		return nil, resp.Error()
+	}
+	values := make(map[int64]float64)
+	if resp.Results == nil || resp.Results[0].Series == nil {
+		return values, nil
+	}
+	for _, v := range resp.Results[0].Series[0].Values {
+		timestamp, err := getInt64ForInfluxValue(v[0])
+		if err != nil {
+			return nil, err
+		}
+		value, err := getFloat64ForInfluxValue(v[1])
+		if err != nil {
+			return nil, err
+		}
+		values[timestamp] = value
+	}
+	return values, nil
+}
+
 // GetTopUsedServerSessionOfInstance 获取伸缩组中UsedServerSession前N的实例id列表
 func (c *Controller) GetTopUsedServerSessionOfInstance(log *logger.FMLogger, groupId string,
 	n float64, start, end int64) []string {
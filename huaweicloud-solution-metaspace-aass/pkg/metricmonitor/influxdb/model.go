@@ -10,3 +10,17 @@ type GroupServerSessionMetrics struct {
 	StartTime        int64
 	EndTime          int64
 }
+
+type GroupCustomMetric struct {
+	Value     float64
+	StartTime int64
+	EndTime   int64
+}
+
+// GroupProcessMetrics 伸缩组内的进程数, AvailableNum为ServerSession数未达到上限的进程数
+type GroupProcessMetrics struct {
+	TotalNum     int64
+	AvailableNum int64
+	StartTime    int64
+	EndTime      int64
+}
@@ -0,0 +1,56 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 可用进程比策略
+package metric
+
+import (
+	"fmt"
+	"math"
+
+	"scase.io/application-auto-scaling-service/pkg/common"
+	"scase.io/application-auto-scaling-service/pkg/db"
+	"scase.io/application-auto-scaling-service/pkg/metricmonitor/influxdb"
+	"scase.io/application-auto-scaling-service/pkg/utils/logger"
+)
+
+const (
+	// 伸缩决定中记录的进程指标
+	metricKeyTotalProcesses     = "total_processes"
+	metricKeyAvailableProcesses = "available_processes"
+)
+
+// getDesiredNumByAvailableProcesses 使已满进程的占比回到100%-目标值, 没有已满进程时缩容一半
+func getDesiredNumByAvailableProcesses(curNum int32, metrics *influxdb.GroupProcessMetrics,
+	targetValue int32) float64 {
+	fullNum := metrics.TotalNum - metrics.AvailableNum
+	if fullNum <= 0 {
+		return float64(curNum) - math.Floor(float64(curNum)*scaleInPercent)
+	}
+	fullPercent := float64(fullNum) / float64(metrics.TotalNum)
+	return math.Ceil(twoDecimalPlaces(float64(curNum) * fullPercent / (1 - changePercentToFloat64(targetValue))))
+}
+
+// voteByAvailableProcesses 根据还能接受ServerSession的进程占比计算期望实例数
+func voteByAvailableProcesses(log *logger.FMLogger, influxCtr *influxdb.Controller,
+	group *db.ScalingGroup, curNum int32, policy *TargetPolicy) (*TargetVote, error) {
+	metrics, err := influxCtr.GetProcessMetricsOfScalingGroup(log, group.Id)
+	if err != nil {
+		return nil, fmt.Errorf("it's failed to get process metrics of ScalingGroup[%s],err: %s ",
+			group.Id, err.Error())
+	}
+	if metrics == nil {
+		return nil, nil
+	}
+	log.Info("ScalingGroup[%s] process metrics: %+v", group.Id, *metrics)
+	return &TargetVote{
+		DesiredNum: getDesiredNumByAvailableProcesses(curNum, metrics, policy.TargetValue),
+		StartTime:  metrics.StartTime,
+		EndTime:    metrics.EndTime,
+		Metrics: map[string]float64{
+			common.MetricNamePercentAvailableProcesses: twoDecimalPlaces(
+				float64(metrics.AvailableNum) / float64(metrics.TotalNum) * percentSign),
+			metricKeyTotalProcesses:     float64(metrics.TotalNum),
+			metricKeyAvailableProcesses: float64(metrics.AvailableNum),
+		},
+	}, nil
+}
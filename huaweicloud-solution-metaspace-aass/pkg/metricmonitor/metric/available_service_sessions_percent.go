@@ -7,9 +7,9 @@ import (
 	"fmt"
 	"math"
 
+	"scase.io/application-auto-scaling-service/pkg/common"
 	"scase.io/application-auto-scaling-service/pkg/db"
 	"scase.io/application-auto-scaling-service/pkg/metricmonitor/influxdb"
-	"scase.io/application-auto-scaling-service/pkg/metricmonitor/model"
 	"scase.io/application-auto-scaling-service/pkg/utils/logger"
 )
 
@@ -38,6 +38,23 @@ func newServerSessionsWithTargetValue(metrics *influxdb.GroupServerSessionMetric
 	}
 }
 
+// addQueuedServerSessions 排队的ServerSession视为已使用, 使伸缩组扩容以消化排队
+func addQueuedServerSessions(metrics *influxdb.GroupServerSessionMetrics,
+	queued int64) *influxdb.GroupServerSessionMetrics {
+	if queued <= 0 {
+		return metrics
+	}
+	if metrics == nil {
+		metrics = &influxdb.GroupServerSessionMetrics{}
+	}
+	metrics.UsedNum += queued
+	metrics.AvailablePercent = 0
+	if metrics.MaxNum > 0 {
+		metrics.AvailablePercent = float64(metrics.MaxNum-metrics.UsedNum) / float64(metrics.MaxNum)
+	}
+	return metrics
+}
+
 func (s *serverSessions) setMaxNumOfInstanceServerSessions(max int32) {
 	s.MaxNumOfInstance = int64(max)
 }
@@ -61,9 +78,9 @@ func (s *serverSessions) getScalingInNumber(curNum int32) float64 {
 		((1-s.TargetPercentOfGroup)*maxNum - usedNum) / ((1 - s.TargetPercentOfGroup) * maxNumOfInstance)))
 }
 
-// ScalingDecisionByAvailableServerSessionsPercentOfGroup 根据AvailableServerSessionsPercent进行伸缩判断
-func ScalingDecisionByAvailableServerSessionsPercentOfGroup(log *logger.FMLogger, influxCtr *influxdb.Controller,
-	group *db.ScalingGroup, curNum int32, targetValue int32) (*model.ScalingDecision, error) {
+// voteByAvailableServerSessions 根据AvailableServerSessionsPercent计算期望实例数
+func voteByAvailableServerSessions(log *logger.FMLogger, influxCtr *influxdb.Controller,
+	group *db.ScalingGroup, curNum int32, policy *TargetPolicy) (*TargetVote, error) {
 	conf, err := db.GetInstanceConfigurationById(group.InstanceConfiguration.Id)
 	if err != nil {
 		return nil, fmt.Errorf("it's failed to get InstanceConfiguration[%s] of ScalingGroup[%s] from db, err: %s",
@@ -74,31 +91,31 @@ func ScalingDecisionByAvailableServerSessionsPercentOfGroup(log *logger.FMLogger
 	if err != nil {
 		return nil, fmt.Errorf("it's failed to get metric of ScalingGroup[%s],err: %s ", group.Id, err.Error())
 	}
+	queued, err := influxCtr.GetQueuedServerSessionsOfFleet(log, group.FleetId)
+	if err != nil {
+		log.Error("it's failed to get queued server sessions of fleet[%s], err: %s", group.FleetId, err.Error())
+		queued = 0
+	}
+	groupMetrics = addQueuedServerSessions(groupMetrics, queued)
 	if groupMetrics == nil {
 		return nil, nil
 	}
-	metric := newServerSessionsWithTargetValue(groupMetrics, targetValue)
+	metric := newServerSessionsWithTargetValue(groupMetrics, policy.TargetValue)
 	metric.setMaxNumOfInstanceServerSessions(conf.MaxServerSession)
 	log.Info("ScalingGroup[%s] server session metrics: %+v", group.Id, *metric)
 
-	res := model.ScalingDecision{Action: model.ScalingDecisionActionNone}
+	vote := &TargetVote{DesiredNum: float64(curNum), StartTime: metric.StartTime, EndTime: metric.EndTime,
+		Metrics: map[string]float64{
+			common.MetricNamePercentAvailableServerSessions: twoDecimalPlaces(
+				metric.AvailablePercentOfGroup * percentSign),
+			metricKeyMaxServerSessions:    float64(metric.MaxNumOfGroup),
+			metricKeyUsedServerSessions:   float64(metric.UsedNumOfGroup),
+			metricKeyQueuedServerSessions: float64(queued),
+		}}
 	if metric.AvailablePercentOfGroup < metric.TargetPercentOfGroup {
-		res.CalculatedNum = metric.getScalingOutNumber()
-		res.AvailableNum = float64(group.MaxInstanceNumber - curNum)
-		res.ScalingNum = math.Min(res.CalculatedNum, res.AvailableNum)
-		if res.ScalingNum > 0 {
-			res.Action = model.ScalingDecisionActionOut
-		}
+		vote.DesiredNum += metric.getScalingOutNumber()
 	} else if metric.AvailablePercentOfGroup > metric.TargetPercentOfGroup {
-		res.CalculatedNum = metric.getScalingInNumber(curNum)
-		res.AvailableNum = float64(curNum - group.MinInstanceNumber)
-		res.ScalingNum = math.Min(res.CalculatedNum, res.AvailableNum)
-		if res.ScalingNum > 0 {
-			res.Action = model.ScalingDecisionActionIn
-			res.Instances = influxCtr.GetTopUsedServerSessionOfInstance(log, group.Id, res.ScalingNum,
-				metric.StartTime, metric.EndTime)
-		}
+		vote.DesiredNum -= metric.getScalingInNumber(curNum)
 	}
-	log.Info("ScalingGroup[%s] auto scaling decision: %+v", group.Id, res)
-	return &res, nil
+	return vote, nil
 }
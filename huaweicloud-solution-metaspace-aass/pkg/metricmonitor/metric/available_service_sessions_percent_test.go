@@ -0,0 +1,56 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 可用会话比策略测试
+package metric
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"scase.io/application-auto-scaling-service/pkg/metricmonitor/influxdb"
+)
+
+func TestAddQueuedServerSessions(t *testing.T) {
+	tests := []struct {
+		name        string
+		metrics     *influxdb.GroupServerSessionMetrics
+		queued      int64
+		wantNil     bool
+		wantUsed    int64
+		wantPercent float64
+	}{
+		{
+			name:    "no queue and no metrics",
+			metrics: nil,
+			queued:  0,
+			wantNil: true,
+		},
+		{
+			name:        "queue without any process",
+			metrics:     nil,
+			queued:      3,
+			wantUsed:    3,
+			wantPercent: 0,
+		},
+		{
+			name:        "queue counted as used",
+			metrics:     &influxdb.GroupServerSessionMetrics{AvailablePercent: 0.5, MaxNum: 10, UsedNum: 5},
+			queued:      3,
+			wantUsed:    8,
+			wantPercent: 0.2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := addQueuedServerSessions(tt.metrics, tt.queued)
+			if tt.wantNil {
+				assert.Nil(t, got)
+				return
+			}
+			assert.Equal(t, tt.wantUsed, got.UsedNum)
+			assert.InDelta(t, tt.wantPercent, got.AvailablePercent, 1e-9)
+		})
+	}
+}
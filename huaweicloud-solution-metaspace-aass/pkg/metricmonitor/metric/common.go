@@ -7,6 +7,11 @@ import "math"
 
 const (
 	percentSign = 100
+
+	// 伸缩决定中记录的ServerSession指标, 已使用的数量包含排队的数量
+	metricKeyMaxServerSessions    = "max_server_sessions"
+	metricKeyUsedServerSessions   = "used_server_sessions"
+	metricKeyQueuedServerSessions = "queued_server_sessions"
 )
 
 func changePercentToFloat64(percent int32) float64 {
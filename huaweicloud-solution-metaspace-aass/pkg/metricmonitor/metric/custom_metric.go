@@ -0,0 +1,38 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 自定义指标策略
+package metric
+
+import (
+	"fmt"
+	"math"
+
+	"scase.io/application-auto-scaling-service/pkg/db"
+	"scase.io/application-auto-scaling-service/pkg/metricmonitor/influxdb"
+	"scase.io/application-auto-scaling-service/pkg/utils/logger"
+)
+
+// getDesiredNumByMetricAverage 按指标平均值与目标值的比例计算期望实例数, 使指标平均值回到目标值
+func getDesiredNumByMetricAverage(curNum int32, value float64, targetValue int32) float64 {
+	return math.Ceil(twoDecimalPlaces(float64(curNum) * value / float64(targetValue)))
+}
+
+// voteByCustomMetric 根据进程上报的自定义指标计算期望实例数
+func voteByCustomMetric(log *logger.FMLogger, influxCtr *influxdb.Controller,
+	group *db.ScalingGroup, curNum int32, policy *TargetPolicy) (*TargetVote, error) {
+	groupMetric, err := influxCtr.GetCustomMetricOfScalingGroup(log, group.Id, policy.CustomMetricName)
+	if err != nil {
+		return nil, fmt.Errorf("it's failed to get custom metric[%s] of ScalingGroup[%s],err: %s ",
+			policy.CustomMetricName, group.Id, err.Error())
+	}
+	if groupMetric == nil {
+		return nil, nil
+	}
+	log.Info("ScalingGroup[%s] custom metric[%s]: %+v", group.Id, policy.CustomMetricName, *groupMetric)
+	return &TargetVote{
+		DesiredNum: getDesiredNumByMetricAverage(curNum, groupMetric.Value, policy.TargetValue),
+		Metrics:    map[string]float64{policy.CustomMetricName: groupMetric.Value},
+		StartTime:  groupMetric.StartTime,
+		EndTime:    groupMetric.EndTime,
+	}, nil
+}
@@ -0,0 +1,62 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 自定义指标策略测试
+package metric
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetDesiredNumByMetricAverage(t *testing.T) {
+	tests := []struct {
+		name        string
+		curNum      int32
+		value       float64
+		targetValue int32
+		want        float64
+	}{
+		{
+			name:        "metric equals target",
+			curNum:      4,
+			value:       50,
+			targetValue: 50,
+			want:        4,
+		},
+		{
+			name:        "metric above target scales out",
+			curNum:      4,
+			value:       75,
+			targetValue: 50,
+			want:        6,
+		},
+		{
+			name:        "metric below target scales in",
+			curNum:      4,
+			value:       20,
+			targetValue: 50,
+			want:        2,
+		},
+		{
+			name:        "partial instance rounds up",
+			curNum:      3,
+			value:       55,
+			targetValue: 50,
+			want:        4,
+		},
+		{
+			name:        "idle group",
+			curNum:      3,
+			value:       0,
+			targetValue: 50,
+			want:        0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, getDesiredNumByMetricAverage(tt.curNum, tt.value, tt.targetValue))
+		})
+	}
+}
@@ -0,0 +1,35 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 实例CPU和内存使用率策略
+package metric
+
+import (
+	"fmt"
+
+	"scase.io/application-auto-scaling-service/pkg/common"
+	"scase.io/application-auto-scaling-service/pkg/db"
+	"scase.io/application-auto-scaling-service/pkg/metricmonitor/influxdb"
+	"scase.io/application-auto-scaling-service/pkg/utils/logger"
+)
+
+// voteByInstanceUtilization 根据auxproxy上报的实例CPU或内存使用率平均值计算期望实例数
+func voteByInstanceUtilization(log *logger.FMLogger, influxCtr *influxdb.Controller,
+	group *db.ScalingGroup, curNum int32, policy *TargetPolicy) (*TargetVote, error) {
+	field := influxdb.FieldCpuUtilization
+	if policy.MetricName == common.MetricNameMemoryUtilization {
+		field = influxdb.FieldMemoryUtilization
+	}
+	groupMetric, err := influxCtr.GetInstanceMetricOfScalingGroup(log, group.Id, field)
+	if err != nil {
+		return nil, fmt.Errorf("it's failed to get instance metric[%s] of ScalingGroup[%s],err: %s ",
+			field, group.Id, err.Error())
+	}
+	if groupMetric == nil {
+		return nil, nil
+	}
+	log.Info("ScalingGroup[%s] instance metric[%s]: %+v", group.Id, field, *groupMetric)
+	return &TargetVote{
+		DesiredNum: getDesiredNumByMetricAverage(curNum, groupMetric.Value, policy.TargetValue),
+		Metrics:    map[string]float64{policy.MetricName: twoDecimalPlaces(groupMetric.Value)},
+	}, nil
+}
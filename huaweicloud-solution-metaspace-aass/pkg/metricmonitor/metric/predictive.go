@@ -0,0 +1,95 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 预测策略
+package metric
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// ForecastBucket 需求统计和预测的时间粒度
+	ForecastBucket = 5 * time.Minute
+	// trendWindow 估计趋势使用的最近时长
+	trendWindow = time.Hour
+)
+
+// DemandForecast 某个时间段的需求预测
+type DemandForecast struct {
+	Time   time.Time
+	Demand float64
+}
+
+// ForecastDemand 按季节性朴素法加趋势预测从now所在时间段开始的horizon个时间段的需求:
+// 1.预测值 = 上一周期同一时间段的需求 + 趋势, 趋势为最近trendWindow的平均需求与上一周期同期的平均需求之差;
+// 2.上一周期同一时间段没有数据时, 以最近一个有数据的时间段的需求代替;
+// 3.没有任何历史数据时不预测.
+// history为时间段开始时间(ns)到需求的映射
+func ForecastDemand(history map[int64]float64, now time.Time, season time.Duration,
+	horizon int) []*DemandForecast {
+	cur := now.Truncate(ForecastBucket)
+	last, ok := latestDemand(history, cur)
+	if !ok {
+		return nil
+	}
+	trend := demandTrend(history, cur, season)
+	forecasts := make([]*DemandForecast, 0, horizon)
+	for i := 0; i < horizon; i++ {
+		t := cur.Add(time.Duration(i) * ForecastBucket)
+		demand := last
+		if seasonal, ok := history[t.Add(-season).UnixNano()]; ok {
+			demand = seasonal + trend
+		}
+		forecasts = append(forecasts, &DemandForecast{Time: t, Demand: twoDecimalPlaces(math.Max(demand, 0))})
+	}
+	return forecasts
+}
+
+// ForecastHistoryStart 预测需要的历史需求的开始时间, 覆盖上一周期同期及估计趋势的时长
+func ForecastHistoryStart(now time.Time, season time.Duration) time.Time {
+	return now.Truncate(ForecastBucket).Add(-season - trendWindow)
+}
+
+// latestDemand 返回cur及之前最近一个有数据的时间段的需求
+func latestDemand(history map[int64]float64, cur time.Time) (float64, bool) {
+	var latest int64
+	found := false
+	for t := range history {
+		if t <= cur.UnixNano() && (!found || t > latest) {
+			latest, found = t, true
+		}
+	}
+	return history[latest], found
+}
+
+// demandTrend 只比较最近trendWindow内和上一周期同期都有数据的时间段, 没有可比较的时间段时趋势为0
+func demandTrend(history map[int64]float64, cur time.Time, season time.Duration) float64 {
+	var diff float64
+	var count int
+	for t := cur.Add(-trendWindow); t.Before(cur); t = t.Add(ForecastBucket) {
+		recent, ok := history[t.UnixNano()]
+		if !ok {
+			continue
+		}
+		previous, ok := history[t.Add(-season).UnixNano()]
+		if !ok {
+			continue
+		}
+		diff += recent - previous
+		count++
+	}
+	if count == 0 {
+		return 0
+	}
+	return diff / float64(count)
+}
+
+// InstanceNumberForDemand 满足需求且保留targetValue百分比可用会话所需的实例数
+func InstanceNumberForDemand(demand float64, maxServerSession, targetValue int32) int32 {
+	capacity := float64(maxServerSession) * (1 - changePercentToFloat64(targetValue))
+	if capacity <= 0 {
+		return 0
+	}
+	return int32(math.Ceil(twoDecimalPlaces(demand / capacity)))
+}
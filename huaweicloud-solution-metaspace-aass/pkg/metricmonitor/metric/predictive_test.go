@@ -0,0 +1,67 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 预测策略测试
+package metric
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const day = 24 * time.Hour
+
+func TestForecastDemand(t *testing.T) {
+	now := time.Date(2022, 10, 2, 12, 2, 0, 0, time.UTC)
+	cur := now.Truncate(ForecastBucket)
+	history := map[int64]float64{}
+	// 昨天同期需求从100开始每个时间段增加10, 今天最近一小时比昨天同期多20
+	for i := -12; i < 3; i++ {
+		t := cur.Add(time.Duration(i) * ForecastBucket)
+		history[t.Add(-day).UnixNano()] = float64(100 + 10*i)
+		if i < 0 {
+			history[t.UnixNano()] = float64(120 + 10*i)
+		}
+	}
+
+	forecasts := ForecastDemand(history, now, day, 4)
+	assert.Equal(t, 4, len(forecasts))
+	assert.True(t, cur.Equal(forecasts[0].Time))
+	assert.Equal(t, float64(120), forecasts[0].Demand)
+	assert.Equal(t, float64(130), forecasts[1].Demand)
+	assert.Equal(t, float64(140), forecasts[2].Demand)
+	// 昨天同期没有数据时使用最近一个时间段的需求
+	assert.Equal(t, float64(110), forecasts[3].Demand)
+}
+
+func TestForecastDemandWithoutSeason(t *testing.T) {
+	now := time.Date(2022, 10, 2, 12, 0, 0, 0, time.UTC)
+	history := map[int64]float64{
+		now.Add(-2 * ForecastBucket).UnixNano(): 30,
+		now.Add(-ForecastBucket).UnixNano():     50,
+	}
+	forecasts := ForecastDemand(history, now, 7*day, 2)
+	assert.Equal(t, float64(50), forecasts[0].Demand)
+	assert.Equal(t, float64(50), forecasts[1].Demand)
+
+	assert.Nil(t, ForecastDemand(map[int64]float64{}, now, day, 2))
+}
+
+func TestForecastDemandIsNotNegative(t *testing.T) {
+	now := time.Date(2022, 10, 2, 12, 0, 0, 0, time.UTC)
+	history := map[int64]float64{
+		now.Add(-ForecastBucket - day).UnixNano(): 100,
+		now.Add(-ForecastBucket).UnixNano():       10,
+		now.Add(-day).UnixNano():                  50,
+	}
+	forecasts := ForecastDemand(history, now, day, 1)
+	assert.Equal(t, float64(0), forecasts[0].Demand)
+}
+
+func TestInstanceNumberForDemand(t *testing.T) {
+	assert.Equal(t, int32(10), InstanceNumberForDemand(100, 10, 0))
+	assert.Equal(t, int32(13), InstanceNumberForDemand(100, 10, 20))
+	assert.Equal(t, int32(0), InstanceNumberForDemand(0, 10, 20))
+	assert.Equal(t, int32(0), InstanceNumberForDemand(100, 0, 20))
+}
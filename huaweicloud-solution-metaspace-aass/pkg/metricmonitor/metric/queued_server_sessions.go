@@ -0,0 +1,48 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 排队ServerSession数策略
+package metric
+
+import (
+	"fmt"
+	"math"
+
+	"scase.io/application-auto-scaling-service/pkg/common"
+	"scase.io/application-auto-scaling-service/pkg/db"
+	"scase.io/application-auto-scaling-service/pkg/metricmonitor/influxdb"
+	"scase.io/application-auto-scaling-service/pkg/utils/logger"
+)
+
+// getDesiredNumByQueuedServerSessions 排队数超过目标值时按实例可承载的ServerSession数扩容消化超出的部分,
+// 未超过时不要求伸缩, 返回false
+func getDesiredNumByQueuedServerSessions(curNum int32, queued int64, targetValue,
+	maxNumOfInstance int32) (float64, bool) {
+	if queued <= int64(targetValue) {
+		return 0, false
+	}
+	if maxNumOfInstance <= 0 {
+		maxNumOfInstance = 1
+	}
+	return float64(curNum) + math.Ceil(float64(queued-int64(targetValue))/float64(maxNumOfInstance)), true
+}
+
+// voteByQueuedServerSessions 根据fleet中排队的ServerSession数计算期望实例数, 排队数只决定是否需要扩容
+func voteByQueuedServerSessions(log *logger.FMLogger, influxCtr *influxdb.Controller,
+	group *db.ScalingGroup, curNum int32, policy *TargetPolicy) (*TargetVote, error) {
+	conf, err := db.GetInstanceConfigurationById(group.InstanceConfiguration.Id)
+	if err != nil {
+		return nil, fmt.Errorf("it's failed to get InstanceConfiguration[%s] of ScalingGroup[%s] from db, err: %s",
+			group.InstanceConfiguration.Id, group.Id, err.Error())
+	}
+	queued, err := influxCtr.GetQueuedServerSessionsOfFleet(log, group.FleetId)
+	if err != nil {
+		return nil, fmt.Errorf("it's failed to get queued server sessions of fleet[%s], err: %s",
+			group.FleetId, err.Error())
+	}
+	vote := &TargetVote{Metrics: map[string]float64{common.MetricNameQueuedServerSessions: float64(queued)}}
+	var ok bool
+	vote.DesiredNum, ok = getDesiredNumByQueuedServerSessions(curNum, queued, policy.TargetValue,
+		conf.MaxServerSession)
+	vote.Abstain = !ok
+	return vote, nil
+}
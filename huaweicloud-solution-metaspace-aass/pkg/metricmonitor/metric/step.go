@@ -0,0 +1,195 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 步进策略
+package metric
+
+import (
+	"fmt"
+	"math"
+	"sync"
+
+	apimodel "scase.io/application-auto-scaling-service/pkg/api/model"
+	"scase.io/application-auto-scaling-service/pkg/common"
+	"scase.io/application-auto-scaling-service/pkg/db"
+	"scase.io/application-auto-scaling-service/pkg/metricmonitor/influxdb"
+	"scase.io/application-auto-scaling-service/pkg/metricmonitor/model"
+	"scase.io/application-auto-scaling-service/pkg/utils/logger"
+)
+
+const defaultEvaluationPeriods = 1
+
+// StepEvaluator 记录指标连续落在同一伸缩方向区间的监控周期数, 避免指标抖动导致反复伸缩
+type StepEvaluator struct {
+	lock   sync.Mutex
+	action string
+	count  int32
+}
+
+// Evaluate 记录本周期的伸缩方向, 返回该方向是否已连续出现periods个周期
+func (e *StepEvaluator) Evaluate(action string, periods int32) bool {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if action != e.action {
+		e.action = action
+		e.count = 0
+	}
+	e.count++
+	return action != model.ScalingDecisionActionNone && e.count >= periods
+}
+
+// Reset 伸缩后重新计数
+func (e *StepEvaluator) Reset() {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.action = ""
+	e.count = 0
+}
+
+// matchStep 返回指标值所在区间的步长, 没有匹配的区间时返回nil
+func matchStep(steps []*apimodel.StepAdjustment, value float64) *apimodel.StepAdjustment {
+	for _, step := range steps {
+		if step.LowerBound != nil && value < *step.LowerBound {
+			continue
+		}
+		if step.UpperBound != nil && value >= *step.UpperBound {
+			continue
+		}
+		return step
+	}
+	return nil
+}
+
+// getStepScalingNum 计算步长对应的伸缩数量, 正数扩容, 负数缩容; 百分比按当前实例数向远离0的方向取整
+func getStepScalingNum(step *apimodel.StepAdjustment, curNum int32) float64 {
+	if step == nil {
+		return 0
+	}
+	if *step.AdjustmentType != common.AdjustmentTypePercentChangeInCapacity {
+		return float64(*step.Adjustment)
+	}
+	num := math.Ceil(twoDecimalPlaces(float64(curNum) * math.Abs(float64(*step.Adjustment)) / percentSign))
+	if *step.Adjustment < 0 {
+		return -num
+	}
+	return num
+}
+
+// IsValidSteps 区间按边界从小到大排列且互不重叠, 只有第一个区间可以不指定下限, 只有最后一个区间可以不指定上限
+func IsValidSteps(steps []*apimodel.StepAdjustment) bool {
+	for i, step := range steps {
+		if step.LowerBound == nil && i != 0 {
+			return false
+		}
+		if step.UpperBound == nil && i != len(steps)-1 {
+			return false
+		}
+		if step.LowerBound != nil && step.UpperBound != nil && *step.LowerBound >= *step.UpperBound {
+			return false
+		}
+		if i > 0 && *steps[i-1].UpperBound > *step.LowerBound {
+			return false
+		}
+	}
+	return true
+}
+
+type stepMetric struct {
+	value     float64
+	startTime int64
+	endTime   int64
+}
+
+// getStepMetricOfGroup 获取步进策略的指标值, 可用会话比转换为0~100的百分比
+func getStepMetricOfGroup(log *logger.FMLogger, influxCtr *influxdb.Controller, group *db.ScalingGroup,
+	conf *apimodel.StepConfiguration) (*stepMetric, error) {
+	if *conf.MetricName == common.MetricNameCustomMetric {
+		groupMetric, err := influxCtr.GetCustomMetricOfScalingGroup(log, group.Id, *conf.CustomMetricName)
+		if err != nil || groupMetric == nil {
+			return nil, err
+		}
+		return &stepMetric{value: groupMetric.Value, startTime: groupMetric.StartTime, endTime: groupMetric.EndTime}, nil
+	}
+
+	groupMetrics, err := influxCtr.GetServerSessionMetricsOfScalingGroup(log, group.Id)
+	if err != nil {
+		return nil, err
+	}
+	queued, err := influxCtr.GetQueuedServerSessionsOfFleet(log, group.FleetId)
+	if err != nil {
+		log.Error("it's failed to get queued server sessions of fleet[%s], err: %s", group.FleetId, err.Error())
+		queued = 0
+	}
+	groupMetrics = addQueuedServerSessions(groupMetrics, queued)
+	if groupMetrics == nil {
+		return nil, nil
+	}
+	return &stepMetric{value: twoDecimalPlaces(groupMetrics.AvailablePercent * percentSign),
+		startTime: groupMetrics.StartTime, endTime: groupMetrics.EndTime}, nil
+}
+
+// stepMetricKey 伸缩决定中记录的指标名, 自定义指标使用自定义指标名称
+func stepMetricKey(conf *apimodel.StepConfiguration) string {
+	if *conf.MetricName == common.MetricNameCustomMetric && conf.CustomMetricName != nil {
+		return *conf.CustomMetricName
+	}
+	return *conf.MetricName
+}
+
+// StepPolicy 与伸缩组内其他策略共同判断的步进策略
+type StepPolicy struct {
+	Policy    *TargetPolicy
+	Conf      *apimodel.StepConfiguration
+	Evaluator *StepEvaluator
+	// InCoolDown 伸缩方向是否处于策略的冷却期间
+	InCoolDown func(action string) bool
+}
+
+// voteByStep 查询步进策略的指标并计算期望实例数, 查询失败或没有数据时标记为不可用
+func voteByStep(log *logger.FMLogger, influxCtr *influxdb.Controller, group *db.ScalingGroup,
+	curNum int32, step *StepPolicy) *TargetVote {
+	groupMetric, err := getStepMetricOfGroup(log, influxCtr, group, step.Conf)
+	if err != nil {
+		err = fmt.Errorf("it's failed to get metric[%s] of ScalingGroup[%s],err: %s ",
+			*step.Conf.MetricName, group.Id, err.Error())
+		log.Error(err.Error())
+		return &TargetVote{Policy: step.Policy, Unavailable: true, Err: err}
+	}
+	if groupMetric == nil {
+		log.Warn(fmt.Sprintf("No %s data of ScalingGroup[%s] in influx", *step.Conf.MetricName, group.Id))
+		return &TargetVote{Policy: step.Policy, Unavailable: true}
+	}
+	v := stepVoteOfValue(step, curNum, groupMetric.value)
+	v.StartTime, v.EndTime = groupMetric.startTime, groupMetric.endTime
+	log.Info("ScalingGroup[%s] step metric[%s]: %.2f, %s", group.Id, *step.Conf.MetricName,
+		groupMetric.value, describeVote(v))
+	return v
+}
+
+// stepVoteOfValue 指标值连续落在同一伸缩方向的区间达到周期数时, 期望实例数为当前实例数加上步长;
+// 未达到周期数或处于冷却期间时不要求伸缩, 连续周期数继续累计
+func stepVoteOfValue(step *StepPolicy, curNum int32, value float64) *TargetVote {
+	scalingNum := getStepScalingNum(matchStep(step.Conf.Steps, value), curNum)
+	action := model.ScalingDecisionActionNone
+	if scalingNum > 0 {
+		action = model.ScalingDecisionActionOut
+	} else if scalingNum < 0 {
+		action = model.ScalingDecisionActionIn
+	}
+	periods := int32(defaultEvaluationPeriods)
+	if step.Conf.EvaluationPeriods != nil {
+		periods = *step.Conf.EvaluationPeriods
+	}
+
+	v := &TargetVote{Policy: step.Policy, Abstain: true,
+		Metrics: map[string]float64{stepMetricKey(step.Conf): value}}
+	if !step.Evaluator.Evaluate(action, periods) {
+		return v
+	}
+	if step.InCoolDown != nil && step.InCoolDown(action) {
+		v.InCoolDown = true
+		return v
+	}
+	v.Abstain = false
+	v.DesiredNum = float64(curNum) + scalingNum
+	return v
+}
@@ -0,0 +1,161 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 步进策略测试
+package metric
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	apimodel "scase.io/application-auto-scaling-service/pkg/api/model"
+	"scase.io/application-auto-scaling-service/pkg/common"
+	"scase.io/application-auto-scaling-service/pkg/metricmonitor/model"
+)
+
+func float64Ptr(f float64) *float64 {
+	return &f
+}
+
+func newStep(lower, upper *float64, adjustmentType string, adjustment int32) *apimodel.StepAdjustment {
+	return &apimodel.StepAdjustment{
+		LowerBound:     lower,
+		UpperBound:     upper,
+		AdjustmentType: &adjustmentType,
+		Adjustment:     &adjustment,
+	}
+}
+
+// 可用会话比低于10%扩容3台, 10%~20%扩容20%, 20%~60%不伸缩, 高于60%缩容1台
+func availablePercentSteps() []*apimodel.StepAdjustment {
+	return []*apimodel.StepAdjustment{
+		newStep(nil, float64Ptr(10), common.AdjustmentTypeChangeInCapacity, 3),
+		newStep(float64Ptr(10), float64Ptr(20), common.AdjustmentTypePercentChangeInCapacity, 20),
+		newStep(float64Ptr(20), float64Ptr(60), common.AdjustmentTypeChangeInCapacity, 0),
+		newStep(float64Ptr(60), nil, common.AdjustmentTypeChangeInCapacity, -1),
+	}
+}
+
+func TestGetStepScalingNum(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  float64
+		curNum int32
+		want   float64
+	}{
+		{
+			name:   "below the first upper bound",
+			value:  5,
+			curNum: 4,
+			want:   3,
+		},
+		{
+			name:   "percent rounds up",
+			value:  10,
+			curNum: 4,
+			want:   1,
+		},
+		{
+			name:   "zero adjustment",
+			value:  40,
+			curNum: 4,
+			want:   0,
+		},
+		{
+			name:   "scale in above the last lower bound",
+			value:  95,
+			curNum: 4,
+			want:   -1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, getStepScalingNum(matchStep(availablePercentSteps(), tt.value), tt.curNum))
+		})
+	}
+}
+
+func TestGetStepScalingNumOfPercentScaleIn(t *testing.T) {
+	step := newStep(nil, nil, common.AdjustmentTypePercentChangeInCapacity, -30)
+	assert.Equal(t, float64(-3), getStepScalingNum(step, 10))
+	assert.Equal(t, float64(0), getStepScalingNum(nil, 10))
+}
+
+func TestIsValidSteps(t *testing.T) {
+	assert.True(t, IsValidSteps(availablePercentSteps()))
+	assert.True(t, IsValidSteps([]*apimodel.StepAdjustment{
+		newStep(nil, nil, common.AdjustmentTypeChangeInCapacity, 1),
+	}))
+	assert.False(t, IsValidSteps([]*apimodel.StepAdjustment{
+		newStep(nil, float64Ptr(20), common.AdjustmentTypeChangeInCapacity, 1),
+		newStep(float64Ptr(10), nil, common.AdjustmentTypeChangeInCapacity, -1),
+	}), "overlapping steps")
+	assert.False(t, IsValidSteps([]*apimodel.StepAdjustment{
+		newStep(nil, nil, common.AdjustmentTypeChangeInCapacity, 1),
+		newStep(float64Ptr(10), nil, common.AdjustmentTypeChangeInCapacity, -1),
+	}), "unbounded step in the middle")
+	assert.False(t, IsValidSteps([]*apimodel.StepAdjustment{
+		newStep(float64Ptr(20), float64Ptr(10), common.AdjustmentTypeChangeInCapacity, 1),
+	}), "lower bound above upper bound")
+}
+
+func TestStepEvaluator(t *testing.T) {
+	e := &StepEvaluator{}
+	assert.False(t, e.Evaluate(model.ScalingDecisionActionOut, 3))
+	assert.False(t, e.Evaluate(model.ScalingDecisionActionOut, 3))
+	// 方向变化后重新计数
+	assert.False(t, e.Evaluate(model.ScalingDecisionActionIn, 3))
+	assert.False(t, e.Evaluate(model.ScalingDecisionActionOut, 3))
+	assert.False(t, e.Evaluate(model.ScalingDecisionActionOut, 3))
+	assert.True(t, e.Evaluate(model.ScalingDecisionActionOut, 3))
+	assert.True(t, e.Evaluate(model.ScalingDecisionActionOut, 3))
+
+	e.Reset()
+	assert.False(t, e.Evaluate(model.ScalingDecisionActionOut, 2))
+	assert.False(t, e.Evaluate(model.ScalingDecisionActionNone, 1))
+}
+
+func newTestStepPolicy(policyId string, periods int32, inCoolDown func(string) bool) *StepPolicy {
+	metricName := common.MetricNamePercentAvailableServerSessions
+	return &StepPolicy{
+		Policy: &TargetPolicy{PolicyId: policyId, MetricName: metricName},
+		Conf: &apimodel.StepConfiguration{MetricName: &metricName, Steps: availablePercentSteps(),
+			EvaluationPeriods: &periods},
+		Evaluator:  &StepEvaluator{},
+		InCoolDown: inCoolDown,
+	}
+}
+
+func TestStepVoteOfValue(t *testing.T) {
+	// 连续2个周期落在扩容区间后期望实例数为当前实例数加上步长
+	step := newTestStepPolicy("step", 2, nil)
+	v := stepVoteOfValue(step, 4, 5)
+	assert.True(t, v.Abstain)
+	assert.Equal(t, float64(5), v.Metrics[common.MetricNamePercentAvailableServerSessions])
+	v = stepVoteOfValue(step, 4, 5)
+	assert.False(t, v.Abstain)
+	assert.Equal(t, float64(7), v.DesiredNum)
+
+	// 缩容
+	step = newTestStepPolicy("step", 1, nil)
+	v = stepVoteOfValue(step, 4, 80)
+	assert.False(t, v.Abstain)
+	assert.Equal(t, float64(3), v.DesiredNum)
+
+	// 不伸缩的区间
+	v = stepVoteOfValue(step, 4, 30)
+	assert.True(t, v.Abstain)
+	assert.False(t, v.InCoolDown)
+
+	// 对应方向处于冷却期间时不要求伸缩, 另一方向不受影响
+	step = newTestStepPolicy("step", 1, func(action string) bool {
+		return action == model.ScalingDecisionActionIn
+	})
+	v = stepVoteOfValue(step, 4, 80)
+	assert.True(t, v.Abstain)
+	assert.True(t, v.InCoolDown)
+	v = stepVoteOfValue(step, 4, 5)
+	assert.False(t, v.Abstain)
+	assert.Equal(t, float64(7), v.DesiredNum)
+}
@@ -0,0 +1,227 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 基于目标策略的共同判断
+package metric
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"scase.io/application-auto-scaling-service/pkg/common"
+	"scase.io/application-auto-scaling-service/pkg/db"
+	"scase.io/application-auto-scaling-service/pkg/metricmonitor/influxdb"
+	"scase.io/application-auto-scaling-service/pkg/metricmonitor/model"
+	"scase.io/application-auto-scaling-service/pkg/utils/logger"
+)
+
+const (
+	// 指标没有记录查询时间段时, 按ServerSession指标的查询时间段选择缩容的实例
+	instanceQueryStartOffset = 20 * time.Second
+	instanceQueryEndOffset   = 10 * time.Second
+)
+
+// TargetPolicy 伸缩组内一个基于目标策略的指标和目标值
+type TargetPolicy struct {
+	PolicyId         string
+	MetricName       string
+	CustomMetricName string
+	TargetValue      int32
+	DryRun           bool
+}
+
+// TargetVote 一个基于目标策略对伸缩组实例数的期望
+type TargetVote struct {
+	Policy *TargetPolicy
+	// DesiredNum 使指标回到目标值的实例数
+	DesiredNum float64
+	// Abstain 指标不要求伸缩, 不影响其他策略的扩容或缩容
+	Abstain bool
+	// Unavailable 指标查询失败或没有数据, 不影响其他策略的扩容但阻止缩容
+	Unavailable bool
+	// InCoolDown 步进策略要求伸缩但对应方向处于冷却期间, 视为不要求伸缩
+	InCoolDown bool
+	Err        error
+	Metrics    map[string]float64
+	StartTime  int64
+	EndTime    int64
+}
+
+// CompositeDecision 伸缩组内所有基于目标策略共同的伸缩决定
+type CompositeDecision struct {
+	*model.ScalingDecision
+	// PolicyId 决定伸缩数量的策略, 没有策略要求伸缩时为空
+	PolicyId string
+	// DryRun 伸缩组内的策略都是dry run时只记录伸缩决定
+	DryRun bool
+	// InCoolDown 没有伸缩且有步进策略因冷却期间未要求伸缩
+	InCoolDown bool
+}
+
+type voteFunc func(log *logger.FMLogger, influxCtr *influxdb.Controller, group *db.ScalingGroup,
+	curNum int32, policy *TargetPolicy) (*TargetVote, error)
+
+var voteFuncs = map[string]voteFunc{
+	common.MetricNamePercentAvailableServerSessions: voteByAvailableServerSessions,
+	common.MetricNamePercentAvailableProcesses:      voteByAvailableProcesses,
+	common.MetricNameQueuedServerSessions:           voteByQueuedServerSessions,
+	common.MetricNameCPUUtilization:                 voteByInstanceUtilization,
+	common.MetricNameMemoryUtilization:              voteByInstanceUtilization,
+	common.MetricNameCustomMetric:                   voteByCustomMetric,
+}
+
+// TargetMetricKey 伸缩决定中记录的指标名, 自定义指标使用自定义指标名称, 同一伸缩组的基于目标策略不能重复
+func TargetMetricKey(metricName, customMetricName string) string {
+	if metricName == common.MetricNameCustomMetric && customMetricName != "" {
+		return customMetricName
+	}
+	return metricName
+}
+
+// evaluateTargetPolicy 计算一个基于目标策略的期望实例数, 查询失败或没有数据时标记为不可用
+func evaluateTargetPolicy(log *logger.FMLogger, influxCtr *influxdb.Controller, group *db.ScalingGroup,
+	curNum int32, policy *TargetPolicy) *TargetVote {
+	vote := func() (*TargetVote, error) {
+		f, ok := voteFuncs[policy.MetricName]
+		if !ok {
+			return nil, fmt.Errorf("metric[%s] of policy[%s] is not supported", policy.MetricName, policy.PolicyId)
+		}
+		return f(log, influxCtr, group, curNum, policy)
+	}
+	v, err := vote()
+	if err != nil {
+		log.Error(err.Error())
+		return &TargetVote{Policy: policy, Unavailable: true, Err: err}
+	}
+	if v == nil {
+		log.Warn(fmt.Sprintf("No %s data of ScalingGroup[%s] in influx", policy.MetricName, group.Id))
+		return &TargetVote{Policy: policy, Unavailable: true}
+	}
+	v.Policy = policy
+	return v
+}
+
+// combineTargetVotes 合并伸缩组内基于目标策略及步进策略的期望实例数, 每个周期只产生一个伸缩决定:
+// 1.任一策略要求扩容时扩容到最大的期望实例数；
+// 2.所有策略都要求缩容时缩容到最大的期望实例数, 不要求伸缩的策略视为同意, 指标不可用的策略阻止缩容；
+// 3.dry run的策略只记录期望实例数不参与判断, 全部策略都是dry run时按全部策略判断并只记录伸缩决定；
+// 4.步进策略未连续满足周期数或处于冷却期间时视为不要求伸缩。
+func combineTargetVotes(group *db.ScalingGroup, curNum int32, votes []*TargetVote) *CompositeDecision {
+	res := &CompositeDecision{ScalingDecision: &model.ScalingDecision{Action: model.ScalingDecisionActionNone,
+		Metrics: map[string]float64{}}}
+	voters := make([]*TargetVote, 0, len(votes))
+	reasons := make([]string, 0, len(votes))
+	for _, v := range votes {
+		for k, value := range v.Metrics {
+			res.Metrics[k] = value
+		}
+		reasons = append(reasons, describeVote(v))
+		if !v.Policy.DryRun {
+			voters = append(voters, v)
+		}
+	}
+	res.Reason = "desired instance numbers: " + strings.Join(reasons, ", ")
+	if len(voters) == 0 {
+		voters = votes
+		res.DryRun = true
+	}
+
+	var driver *TargetVote
+	blocked, coolDown := false, false
+	for _, v := range voters {
+		if v.Unavailable {
+			blocked = true
+			continue
+		}
+		coolDown = coolDown || v.InCoolDown
+		if v.Abstain {
+			continue
+		}
+		if driver == nil || v.DesiredNum > driver.DesiredNum {
+			driver = v
+		}
+	}
+	if driver == nil {
+		res.InCoolDown = coolDown
+		return res
+	}
+	if driver.DesiredNum > float64(curNum) {
+		res.CalculatedNum = driver.DesiredNum - float64(curNum)
+		res.AvailableNum = float64(group.MaxInstanceNumber - curNum)
+		res.ScalingNum = math.Min(res.CalculatedNum, res.AvailableNum)
+		if res.ScalingNum > 0 {
+			res.Action = model.ScalingDecisionActionOut
+			res.PolicyId = driver.Policy.PolicyId
+		}
+	} else if driver.DesiredNum < float64(curNum) && !blocked {
+		res.CalculatedNum = float64(curNum) - driver.DesiredNum
+		res.AvailableNum = float64(curNum - group.MinInstanceNumber)
+		res.ScalingNum = math.Min(res.CalculatedNum, res.AvailableNum)
+		if res.ScalingNum > 0 {
+			res.Action = model.ScalingDecisionActionIn
+			res.PolicyId = driver.Policy.PolicyId
+		}
+	}
+	res.InCoolDown = coolDown && res.Action == model.ScalingDecisionActionNone
+	return res
+}
+
+func describeVote(v *TargetVote) string {
+	desired := fmt.Sprintf("%g", v.DesiredNum)
+	if v.Unavailable {
+		desired = "unavailable"
+	} else if v.InCoolDown {
+		desired = "cool down"
+	} else if v.Abstain {
+		desired = "abstain"
+	}
+	if v.Policy.DryRun {
+		desired += "(dry run)"
+	}
+	return TargetMetricKey(v.Policy.MetricName, v.Policy.CustomMetricName) + "=" + desired
+}
+
+// ScalingDecisionByTargetPolicies 共同判断伸缩组内所有基于目标策略及步进策略, 所有策略的指标都不可用时返回查询错误或nil
+func ScalingDecisionByTargetPolicies(log *logger.FMLogger, influxCtr *influxdb.Controller,
+	group *db.ScalingGroup, curNum int32, policies []*TargetPolicy, steps []*StepPolicy) (*CompositeDecision, error) {
+	votes := make([]*TargetVote, 0, len(policies)+len(steps))
+	for _, policy := range policies {
+		votes = append(votes, evaluateTargetPolicy(log, influxCtr, group, curNum, policy))
+	}
+	for _, step := range steps {
+		votes = append(votes, voteByStep(log, influxCtr, group, curNum, step))
+	}
+
+	var err error
+	available := false
+	for _, v := range votes {
+		if !v.Unavailable {
+			available = true
+		} else if err == nil {
+			err = v.Err
+		}
+	}
+	if !available {
+		return nil, err
+	}
+
+	res := combineTargetVotes(group, curNum, votes)
+	if res.Action == model.ScalingDecisionActionIn {
+		start, end := instanceQueryWindow(votes)
+		res.Instances = influxCtr.GetTopUsedServerSessionOfInstance(log, group.Id, res.ScalingNum, start, end)
+	}
+	log.Info("ScalingGroup[%s] auto scaling decision: %+v", group.Id, *res.ScalingDecision)
+	return res, nil
+}
+
+// instanceQueryWindow 缩容时选择实例的查询时间段, 优先使用进程指标的查询时间段
+func instanceQueryWindow(votes []*TargetVote) (int64, int64) {
+	for _, v := range votes {
+		if v.StartTime > 0 && v.EndTime > v.StartTime {
+			return v.StartTime, v.EndTime
+		}
+	}
+	now := time.Now()
+	return now.Add(-instanceQueryStartOffset).UnixNano(), now.Add(-instanceQueryEndOffset).UnixNano()
+}
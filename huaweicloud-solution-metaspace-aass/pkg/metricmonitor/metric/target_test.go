@@ -0,0 +1,157 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 基于目标策略的共同判断测试
+package metric
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"scase.io/application-auto-scaling-service/pkg/common"
+	"scase.io/application-auto-scaling-service/pkg/db"
+	"scase.io/application-auto-scaling-service/pkg/metricmonitor/influxdb"
+	"scase.io/application-auto-scaling-service/pkg/metricmonitor/model"
+)
+
+func newTestVote(policyId, metricName string, desired float64) *TargetVote {
+	return &TargetVote{
+		Policy:     &TargetPolicy{PolicyId: policyId, MetricName: metricName},
+		DesiredNum: desired,
+		Metrics:    map[string]float64{metricName: desired},
+	}
+}
+
+func TestCombineTargetVotes(t *testing.T) {
+	group := &db.ScalingGroup{MinInstanceNumber: 1, MaxInstanceNumber: 8}
+
+	// 任一策略要求扩容时扩容到最大的期望实例数
+	res := combineTargetVotes(group, 4, []*TargetVote{
+		newTestVote("p1", common.MetricNamePercentAvailableServerSessions, 2),
+		newTestVote("p2", common.MetricNameCPUUtilization, 6),
+	})
+	assert.Equal(t, model.ScalingDecisionActionOut, res.Action)
+	assert.Equal(t, float64(2), res.ScalingNum)
+	assert.Equal(t, "p2", res.PolicyId)
+	assert.Len(t, res.Metrics, 2)
+	assert.Equal(t, "desired instance numbers: PERCENT_AVAILABLE_SERVER_SESSIONS=2, CPU_UTILIZATION=6",
+		res.Reason)
+
+	// 扩容数量受上限限制
+	res = combineTargetVotes(group, 4, []*TargetVote{newTestVote("p1", common.MetricNameCPUUtilization, 20)})
+	assert.Equal(t, float64(16), res.CalculatedNum)
+	assert.Equal(t, float64(4), res.ScalingNum)
+
+	// 所有策略都要求缩容时缩容到最大的期望实例数, 不要求伸缩的策略视为同意
+	queue := &TargetVote{Policy: &TargetPolicy{PolicyId: "p3", MetricName: common.MetricNameQueuedServerSessions},
+		Abstain: true}
+	res = combineTargetVotes(group, 4, []*TargetVote{
+		newTestVote("p1", common.MetricNamePercentAvailableServerSessions, 2),
+		newTestVote("p2", common.MetricNameCPUUtilization, 3),
+		queue,
+	})
+	assert.Equal(t, model.ScalingDecisionActionIn, res.Action)
+	assert.Equal(t, float64(1), res.ScalingNum)
+	assert.Equal(t, "p2", res.PolicyId)
+
+	// 有策略不同意时不缩容
+	res = combineTargetVotes(group, 4, []*TargetVote{
+		newTestVote("p1", common.MetricNamePercentAvailableServerSessions, 2),
+		newTestVote("p2", common.MetricNameCPUUtilization, 4),
+	})
+	assert.Equal(t, model.ScalingDecisionActionNone, res.Action)
+	assert.Empty(t, res.PolicyId)
+
+	// 指标不可用的策略阻止缩容, 但不影响扩容
+	unavailable := &TargetVote{Policy: &TargetPolicy{PolicyId: "p2", MetricName: common.MetricNameMemoryUtilization},
+		Unavailable: true}
+	res = combineTargetVotes(group, 4, []*TargetVote{
+		newTestVote("p1", common.MetricNamePercentAvailableServerSessions, 2), unavailable})
+	assert.Equal(t, model.ScalingDecisionActionNone, res.Action)
+	res = combineTargetVotes(group, 4, []*TargetVote{
+		newTestVote("p1", common.MetricNamePercentAvailableServerSessions, 5), unavailable})
+	assert.Equal(t, model.ScalingDecisionActionOut, res.Action)
+
+	// 所有策略都不要求伸缩
+	res = combineTargetVotes(group, 4, []*TargetVote{queue})
+	assert.Equal(t, model.ScalingDecisionActionNone, res.Action)
+}
+
+func TestCombineTargetVotesWithDryRun(t *testing.T) {
+	group := &db.ScalingGroup{MinInstanceNumber: 1, MaxInstanceNumber: 8}
+
+	// dry run的策略不参与判断
+	dryRun := newTestVote("p2", common.MetricNameCPUUtilization, 6)
+	dryRun.Policy.DryRun = true
+	res := combineTargetVotes(group, 4, []*TargetVote{
+		newTestVote("p1", common.MetricNamePercentAvailableServerSessions, 2), dryRun})
+	assert.Equal(t, model.ScalingDecisionActionIn, res.Action)
+	assert.Equal(t, "p1", res.PolicyId)
+	assert.False(t, res.DryRun)
+	assert.Contains(t, res.Reason, "CPU_UTILIZATION=6(dry run)")
+
+	// 全部策略都是dry run时按全部策略判断
+	res = combineTargetVotes(group, 4, []*TargetVote{dryRun})
+	assert.Equal(t, model.ScalingDecisionActionOut, res.Action)
+	assert.True(t, res.DryRun)
+}
+
+func TestGetDesiredNumByAvailableProcesses(t *testing.T) {
+	// 10个进程中8个已满, 目标可用20%, 期望已满进程占80%
+	assert.Equal(t, float64(4), getDesiredNumByAvailableProcesses(4,
+		&influxdb.GroupProcessMetrics{TotalNum: 10, AvailableNum: 2}, 20))
+	assert.Equal(t, float64(5), getDesiredNumByAvailableProcesses(4,
+		&influxdb.GroupProcessMetrics{TotalNum: 10, AvailableNum: 1}, 20))
+	assert.Equal(t, float64(2), getDesiredNumByAvailableProcesses(4,
+		&influxdb.GroupProcessMetrics{TotalNum: 10, AvailableNum: 6}, 20))
+	// 没有已满进程时缩容一半
+	assert.Equal(t, float64(3), getDesiredNumByAvailableProcesses(5,
+		&influxdb.GroupProcessMetrics{TotalNum: 10, AvailableNum: 10}, 20))
+}
+
+func TestGetDesiredNumByQueuedServerSessions(t *testing.T) {
+	_, ok := getDesiredNumByQueuedServerSessions(4, 5, 5, 10)
+	assert.False(t, ok)
+
+	desired, ok := getDesiredNumByQueuedServerSessions(4, 26, 5, 10)
+	assert.True(t, ok)
+	assert.Equal(t, float64(7), desired)
+
+	desired, ok = getDesiredNumByQueuedServerSessions(4, 8, 5, 0)
+	assert.True(t, ok)
+	assert.Equal(t, float64(7), desired)
+}
+
+func TestTargetMetricKey(t *testing.T) {
+	assert.Equal(t, common.MetricNameCPUUtilization, TargetMetricKey(common.MetricNameCPUUtilization, ""))
+	assert.Equal(t, "players", TargetMetricKey(common.MetricNameCustomMetric, "players"))
+}
+
+func TestCombineTargetVotesWithStep(t *testing.T) {
+	group := &db.ScalingGroup{MinInstanceNumber: 1, MaxInstanceNumber: 8}
+	stepIn := stepVoteOfValue(newTestStepPolicy("step", 1, nil), 4, 80)
+
+	// 步进策略要求缩容而基于目标策略要求扩容时只扩容
+	res := combineTargetVotes(group, 4, []*TargetVote{
+		newTestVote("p1", common.MetricNameCPUUtilization, 6), stepIn})
+	assert.Equal(t, model.ScalingDecisionActionOut, res.Action)
+	assert.Equal(t, "p1", res.PolicyId)
+
+	// 基于目标策略也要求缩容时由步进策略缩容
+	res = combineTargetVotes(group, 4, []*TargetVote{
+		newTestVote("p1", common.MetricNameCPUUtilization, 2), stepIn})
+	assert.Equal(t, model.ScalingDecisionActionIn, res.Action)
+	assert.Equal(t, float64(1), res.ScalingNum)
+	assert.Equal(t, "step", res.PolicyId)
+
+	// 步进策略处于冷却期间时视为不要求伸缩
+	coolDown := stepVoteOfValue(newTestStepPolicy("step", 1, func(string) bool { return true }), 4, 80)
+	res = combineTargetVotes(group, 4, []*TargetVote{coolDown})
+	assert.Equal(t, model.ScalingDecisionActionNone, res.Action)
+	assert.True(t, res.InCoolDown)
+	assert.Contains(t, res.Reason, "PERCENT_AVAILABLE_SERVER_SESSIONS=cool down")
+	res = combineTargetVotes(group, 4, []*TargetVote{
+		newTestVote("p1", common.MetricNameCPUUtilization, 6), coolDown})
+	assert.Equal(t, model.ScalingDecisionActionOut, res.Action)
+	assert.False(t, res.InCoolDown)
+}
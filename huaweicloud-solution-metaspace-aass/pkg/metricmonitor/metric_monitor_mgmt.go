@@ -6,13 +6,16 @@ package metricmonitor
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/beego/beego/v2/client/orm"
 	"github.com/pkg/errors"
 	"github.com/robfig/cron/v3"
 
+	"scase.io/application-auto-scaling-service/pkg/common"
 	"scase.io/application-auto-scaling-service/pkg/db"
 	"scase.io/application-auto-scaling-service/pkg/metricmonitor/influxdb"
+	"scase.io/application-auto-scaling-service/pkg/metricmonitor/metric"
 	"scase.io/application-auto-scaling-service/pkg/setting"
 	"scase.io/application-auto-scaling-service/pkg/utils/logger"
 )
@@ -46,20 +49,27 @@ type metricMonitorMgmt struct {
 	cronTab   *cron.Cron
 	period    string
 	taskMgmt  map[string]cron.EntryID
-	lock      sync.Mutex
+	// evaluators 步进策略任务的连续周期计数, 由共同判断伸缩组的任务使用
+	evaluators map[string]*metric.StepEvaluator
+	lock       sync.Mutex
 }
 
 func newMetricMonitorMgmt() (*metricMonitorMgmt, error) {
 	var err error
 	m := metricMonitorMgmt{
-		cronTab:  newWithSeconds(),
-		period:   getCronDuration(setting.GetMonitorDuration()),
-		taskMgmt: make(map[string]cron.EntryID),
+		cronTab:    newWithSeconds(),
+		period:     getCronDuration(setting.GetMonitorDuration()),
+		taskMgmt:   make(map[string]cron.EntryID),
+		evaluators: make(map[string]*metric.StepEvaluator),
 	}
 	m.metricCtr, err = influxdb.NewController()
 	if err != nil {
 		return nil, err
 	}
+	if _, err = m.cronTab.AddFunc(decisionLogCleanPeriod, cleanExpiredDecisionLogs); err != nil {
+		return nil, err
+	}
+	m.cronTab.Start()
 	return &m, nil
 }
 
@@ -73,14 +83,64 @@ func GetMgmt() *metricMonitorMgmt {
 }
 
 // NewTaskForPolicy ...
-func (m *metricMonitorMgmt) NewTaskForPolicy(groupId, policyId, metric string,
+func (m *metricMonitorMgmt) NewTaskForPolicy(groupId, policyId, metric, customMetric string,
 	value int32) (*db.MetricMonitorTask, error) {
+	task := &db.MetricMonitorTask{
+		Id:               policyId,
+		ScalingGroupID:   groupId,
+		ScalingPolicyID:  policyId,
+		MetricName:       metric,
+		CustomMetricName: customMetric,
+		TargetValue:      value,
+		PolicyType:       common.PolicyTypeTargetBased,
+	}
+	err := db.AddMetricMonitorTask(task)
+	if err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// NewTaskForStepPolicy 步进策略的区间配置保存在策略中
+func (m *metricMonitorMgmt) NewTaskForStepPolicy(groupId, policyId, metric,
+	customMetric string) (*db.MetricMonitorTask, error) {
+	task := &db.MetricMonitorTask{
+		Id:               policyId,
+		ScalingGroupID:   groupId,
+		ScalingPolicyID:  policyId,
+		MetricName:       metric,
+		CustomMetricName: customMetric,
+		PolicyType:       common.PolicyTypeStep,
+	}
+	err := db.AddMetricMonitorTask(task)
+	if err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// NewTaskForScheduledPolicy 定时策略的时间窗配置保存在策略中, 任务每个周期检查是否处于时间窗内
+func (m *metricMonitorMgmt) NewTaskForScheduledPolicy(groupId, policyId string) (*db.MetricMonitorTask, error) {
+	task := &db.MetricMonitorTask{
+		Id:              policyId,
+		ScalingGroupID:  groupId,
+		ScalingPolicyID: policyId,
+		PolicyType:      common.PolicyTypeScheduled,
+	}
+	err := db.AddMetricMonitorTask(task)
+	if err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// NewTaskForPredictivePolicy 预测策略的配置保存在策略中, 任务每个时间段预测一次需求
+func (m *metricMonitorMgmt) NewTaskForPredictivePolicy(groupId, policyId string) (*db.MetricMonitorTask, error) {
 	task := &db.MetricMonitorTask{
 		Id:              policyId,
 		ScalingGroupID:  groupId,
 		ScalingPolicyID: policyId,
-		MetricName:      metric,
-		TargetValue:     value,
+		PolicyType:      common.PolicyTypePredictive,
 	}
 	err := db.AddMetricMonitorTask(task)
 	if err != nil {
@@ -106,7 +166,15 @@ func (m *metricMonitorMgmt) AddTask(task *db.MetricMonitorTask) error {
 			return
 		}
 
-		targetBasedMonitorTask(log, m.metricCtr, mt)
+		switch mt.PolicyType {
+		case common.PolicyTypeScheduled:
+			scheduledMonitorTask(log, mt)
+			return
+		case common.PolicyTypePredictive:
+			predictiveMonitorTask(log, m.metricCtr, mt)
+			return
+		}
+		targetBasedMonitorTask(log, m.metricCtr, mt, m.stepEvaluatorOf)
 	})
 	if err != nil {
 		return err
@@ -131,9 +199,22 @@ func (m *metricMonitorMgmt) deleteTask(taskId string) {
 	entryId := m.taskMgmt[taskId]
 	m.cronTab.Remove(entryId)
 	delete(m.taskMgmt, taskId)
+	delete(m.evaluators, taskId)
 	m.lock.Unlock()
 }
 
+// stepEvaluatorOf 获取步进策略任务的连续周期计数, 共同判断的任务可能不在步进策略任务所在的节点, 按需创建
+func (m *metricMonitorMgmt) stepEvaluatorOf(taskId string) *metric.StepEvaluator {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	evaluator, ok := m.evaluators[taskId]
+	if !ok {
+		evaluator = &metric.StepEvaluator{}
+		m.evaluators[taskId] = evaluator
+	}
+	return evaluator
+}
+
 // DeleteTask ...
 func (m *metricMonitorMgmt) DeleteTask(taskId string) error {
 	if len(taskId) == 0 {
@@ -143,9 +224,24 @@ func (m *metricMonitorMgmt) DeleteTask(taskId string) error {
 }
 
 // UpdateTask ...
-func (m *metricMonitorMgmt) UpdateTask(taskId string, targetValue int32) error {
+func (m *metricMonitorMgmt) UpdateTask(taskId, metric, customMetric string, targetValue int32) error {
+	if len(taskId) == 0 {
+		return errors.New("task id cannot be empty")
+	}
+	return db.UpdateMetricMonitorTask(taskId, metric, customMetric, targetValue)
+}
+
+// ResetTriggeredTask 定时策略的时间窗修改后当前所处的时间窗重新执行, 预测策略的配置修改后重新预测
+func (m *metricMonitorMgmt) ResetTriggeredTask(taskId string) error {
 	if len(taskId) == 0 {
 		return errors.New("task id cannot be empty")
 	}
-	return db.UpdateMetricMonitorTask(taskId, targetValue)
+	return db.UpdateMetricMonitorTaskTriggeredAt(taskId, 0)
+}
+
+// DemandOfScalingGroup 查询伸缩组[start, end)内每个预测时间段的实际需求, 返回时间段开始时间(ns)到需求的映射
+func (m *metricMonitorMgmt) DemandOfScalingGroup(log *logger.FMLogger, group *db.ScalingGroup,
+	start, end time.Time) (map[int64]float64, error) {
+	return m.metricCtr.GetServerSessionDemandOfScalingGroup(log, group.Id, group.FleetId,
+		start.UnixNano(), end.UnixNano(), metric.ForecastBucket)
 }
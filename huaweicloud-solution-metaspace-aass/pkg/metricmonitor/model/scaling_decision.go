@@ -21,4 +21,8 @@ type ScalingDecision struct {
 	// ScalingNum：伸缩数量
 	ScalingNum float64
 	Instances  []string
+	// Metrics：伸缩判断使用的指标名到指标值的映射
+	Metrics map[string]float64
+	// Reason：多个策略共同判断时各策略的期望实例数
+	Reason string
 }
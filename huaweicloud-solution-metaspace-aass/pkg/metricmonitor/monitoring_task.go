@@ -19,65 +19,231 @@ import (
 	"scase.io/application-auto-scaling-service/pkg/utils/logger"
 )
 
-// targetBasedMonitorTask 基于目标的监控任务
+// targetBasedMonitorTask 基于目标策略及步进策略的监控任务
 // 1.当实例伸缩组不处于active或enableAutoScaling状态时，不执行自动伸缩；
-// 2.当实例伸缩组处于冷却期间时，不执行自动伸缩；
+// 2.伸缩组有基于目标策略且处于冷却期间时，不执行自动伸缩；
 // 3.当实例伸缩组无伸缩伸缩时，不执行伸缩决策；
-func targetBasedMonitorTask(log *logger.FMLogger, influxCtr *influxdb.Controller, task *db.MetricMonitorTask) {
-	group := getEnableScalingGroup(log, "", task.ScalingGroupID, task.ScalingPolicyID)
+// 4.处于定时策略的时间窗内时，在定时策略的实例数上下限内进行伸缩决策；
+// 5.存在预测策略时，实例数下限提高到预测未来需要的实例数，提前扩容；
+// 6.步进策略按策略的扩容和缩容冷却时长分别判断，冷却期间的步进策略视为不要求伸缩；
+// 7.每次伸缩判断的输入、伸缩决定以及启动的伸缩任务或未启动的原因都会保存，dry run的策略不启动伸缩任务；
+// 8.伸缩组的基于目标策略及步进策略只由策略Id最小的任务共同判断，每个周期只产生一个伸缩决定。
+func targetBasedMonitorTask(log *logger.FMLogger, influxCtr *influxdb.Controller, task *db.MetricMonitorTask,
+	evaluatorOf func(taskId string) *metric.StepEvaluator) {
+	tasks, ok := metricPolicyTasksOfGroup(log, task)
+	if !ok {
+		return
+	}
+	group := getStableScalingGroup(log, "", task.ScalingGroupID, task.ScalingPolicyID)
 	if group == nil {
 		return
 	}
-	vmGroup, err := db.GetVmScalingGroupById(group.ResourceId)
-	if err != nil {
+	record := newDecisionLog(group, task)
+	defer saveDecisionLog(log, record)
+	policies := targetPoliciesOfTasks(tasks)
+	setTargetPoliciesDryRun(group, policies, record)
+	curNum, ok := currentInstanceNum(log, group)
+	if !ok {
+		skipDecision(record, skipReasonInstanceNumberQueryFailed, "")
 		return
 	}
-	resCtrl, err := cloudresource.GetResourceController(group.ProjectId)
-	if err != nil {
-		log.Error(fmt.Sprintf("it's failed to get resourceController by project_id[%s] ", group.ProjectId))
+	// 定时策略的时间窗内以策略的上下限为准, 预测策略提高下限
+	minNum, maxNum, _ := boundsOfGroup(log, group, time.Now())
+	setInstanceNumbers(record, curNum, minNum, maxNum)
+	targets, steps := splitStepPolicies(log, group, tasks, policies, evaluatorOf)
+	if len(targets) > 0 && isInCoolDown(group) {
+		log.Info(fmt.Sprintf("ScalingGroup[%s] is in the cooling duration", group.Id))
+		record.InCoolDown = true
+		skipDecision(record, skipReasonInCoolDown, "")
 		return
 	}
-	curNum, err := resCtrl.GetAsGroupCurrentInstanceNum(vmGroup.AsGroupId)
-	if err != nil {
-		log.Error(fmt.Sprintf("it's failed to get AsScalingGroup[%s] server number of ScalingGroup[%s]",
-			vmGroup.AsGroupId, group.Id))
+
+	// 实例数超出上下限时先伸缩到边界
+	if scaled, _ := scaleTo(log, group, record, curNum, clampInstanceNum(curNum, minNum, maxNum)); scaled {
+		record.Message = "instance number is out of the bounds"
 		return
 	}
+	bounded := *group
+	bounded.MinInstanceNumber, bounded.MaxInstanceNumber = minNum, maxNum
 
-	decision, err := metric.ScalingDecisionByAvailableServerSessionsPercentOfGroup(log, influxCtr,
-		group, curNum, task.TargetValue)
+	decision, err := targetScalingDecision(log, influxCtr, &bounded, curNum, targets, steps, record)
 	if err != nil {
 		log.Error(err.Error())
+		skipDecision(record, skipReasonMetricQueryFailed, err.Error())
 		return
 	}
 	if decision == nil {
 		log.Warn(fmt.Sprintf("No monitoring data of ScalingGroup[%s] in influx", group.Id))
+		skipDecision(record, skipReasonNoMetricData, "")
+		return
+	}
+	setDecision(record, decision)
+	if record.InCoolDown {
+		skipDecision(record, skipReasonInCoolDown, "")
 		return
 	}
 	if decision.Action == model.ScalingDecisionActionNone {
+		skipDecision(record, skipReasonNoScalingNeeded, "")
 		return
 	}
 
+	var started bool
 	if decision.Action == model.ScalingDecisionActionIn {
-		err = taskservice.StartScaleInGroupTask(group.Id, decision.Instances)
+		started = startScalingTask(log, group, record, false, func() error {
+			return taskservice.StartScaleInGroupTask(group.Id, decision.Instances)
+		})
 	} else if decision.Action == model.ScalingDecisionActionOut {
-		err = taskservice.StartScaleOutGroupTask(group.Id, int32(decision.ScalingNum)+curNum)
+		started = startScalingTask(log, group, record, true, func() error {
+			return taskservice.StartScaleOutGroupTask(group.Id, int32(decision.ScalingNum)+curNum)
+		})
+	}
+	if started {
+		recordStepScaling(log, steps, decision.Action)
+	}
+}
+
+// metricPolicyTasksOfGroup 查询伸缩组内所有基于目标策略及步进策略的任务, 当前任务不是策略Id最小的任务时返回false,
+// 由该任务共同判断
+func metricPolicyTasksOfGroup(log *logger.FMLogger, task *db.MetricMonitorTask) ([]*db.MetricMonitorTask, bool) {
+	tasks, err := db.ListMetricBasedMetricMonitorTasksOfGroup(task.ScalingGroupID)
+	if err != nil {
+		log.Error("List metric based tasks of ScalingGroup[%s] err: %+v", task.ScalingGroupID, err)
+		return nil, false
+	}
+	if len(tasks) == 0 || tasks[0].ScalingPolicyID != task.ScalingPolicyID {
+		return nil, false
+	}
+	return tasks, true
+}
+
+// targetPoliciesOfTasks 每个任务对应一个策略, 步进策略的区间配置在拆分时读取
+func targetPoliciesOfTasks(tasks []*db.MetricMonitorTask) []*metric.TargetPolicy {
+	policies := make([]*metric.TargetPolicy, 0, len(tasks))
+	for _, t := range tasks {
+		policies = append(policies, &metric.TargetPolicy{
+			PolicyId:         t.ScalingPolicyID,
+			MetricName:       t.MetricName,
+			CustomMetricName: t.CustomMetricName,
+			TargetValue:      t.TargetValue,
+		})
+	}
+	return policies
+}
+
+// splitStepPolicies 拆分出步进策略, 区间配置读取失败的步进策略不参与判断
+func splitStepPolicies(log *logger.FMLogger, group *db.ScalingGroup, tasks []*db.MetricMonitorTask,
+	policies []*metric.TargetPolicy, evaluatorOf func(taskId string) *metric.StepEvaluator) (
+	[]*metric.TargetPolicy, []*stepPolicyTask) {
+	var targets []*metric.TargetPolicy
+	var steps []*stepPolicyTask
+	now := time.Now()
+	for i, t := range tasks {
+		if t.PolicyType != common.PolicyTypeStep {
+			targets = append(targets, policies[i])
+			continue
+		}
+		step, err := newStepPolicyTask(group, t, policies[i], evaluatorOf(t.Id), now)
+		if err != nil {
+			log.Error("Read step policy[%s] of ScalingGroup[%s] err: %+v", t.ScalingPolicyID, group.Id, err)
+			continue
+		}
+		steps = append(steps, step)
+	}
+	return targets, steps
+}
+
+// setTargetPoliciesDryRun 所有基于目标策略都是dry run时, 伸缩组的伸缩决定只记录不启动伸缩任务
+func setTargetPoliciesDryRun(group *db.ScalingGroup, policies []*metric.TargetPolicy,
+	record *db.ScalingDecisionLog) {
+	record.DryRun = true
+	for _, policy := range policies {
+		if p := policyOfGroup(group, policy.PolicyId); p != nil {
+			policy.DryRun = p.DryRun
+		}
+		record.DryRun = record.DryRun && policy.DryRun
 	}
+}
+
+// targetScalingDecision 共同判断伸缩组内所有基于目标策略及步进策略, 伸缩决定记录到决定伸缩数量的策略
+func targetScalingDecision(log *logger.FMLogger, influxCtr *influxdb.Controller, group *db.ScalingGroup,
+	curNum int32, policies []*metric.TargetPolicy, steps []*stepPolicyTask,
+	record *db.ScalingDecisionLog) (*model.ScalingDecision, error) {
+	stepPolicies := make([]*metric.StepPolicy, 0, len(steps))
+	for _, step := range steps {
+		stepPolicies = append(stepPolicies, step.StepPolicy)
+	}
+	decision, err := metric.ScalingDecisionByTargetPolicies(log, influxCtr, group, curNum, policies, stepPolicies)
+	if err != nil || decision == nil {
+		return nil, err
+	}
+	record.DryRun = decision.DryRun
+	record.InCoolDown = decision.InCoolDown
+	if decision.PolicyId != "" {
+		record.ScalingPolicyId = decision.PolicyId
+		if policy := policyOfGroup(group, decision.PolicyId); policy != nil {
+			record.PolicyType = policy.PolicyType
+		}
+	}
+	return decision.ScalingDecision, nil
+}
+
+// finishScaling 处理伸缩任务的启动结果, 启动成功后记录伸缩时间用于冷却, 返回伸缩任务是否启动成功
+func finishScaling(log *logger.FMLogger, groupId string, err error) bool {
 	if err != nil {
 		if errors.Is(err, common.ErrScalingGroupNotStable) {
 			log.Info("Scaling group is not stable, do nothing")
-			return
+			return false
 		}
-		log.Error("Start scaling task for group[%s] failed, err: %+v", group.Id, err)
-		return
+		log.Error("Start scaling task for group[%s] failed, err: %+v", groupId, err)
+		return false
 	}
-	if err = db.UpdateAutoScalingTimestamp(group.Id); err != nil {
-		log.Error("Update AutoScalingTimestamp of ScalingGroup[%s] is failed, err: %+v", err)
-		return
+	if err = db.UpdateAutoScalingTimestamp(groupId); err != nil {
+		log.Error("Update AutoScalingTimestamp of ScalingGroup[%s] is failed, err: %+v", groupId, err)
 	}
+	return true
 }
 
-func getEnableScalingGroup(log *logger.FMLogger, projectId, groupId, policyId string) *db.ScalingGroup {
+// currentInstanceNum 查询伸缩组当前的实例数
+func currentInstanceNum(log *logger.FMLogger, group *db.ScalingGroup) (int32, bool) {
+	vmGroup, err := db.GetVmScalingGroupById(group.ResourceId)
+	if err != nil {
+		return 0, false
+	}
+	resCtrl, err := cloudresource.GetResourceController(group.ProjectId)
+	if err != nil {
+		log.Error(fmt.Sprintf("it's failed to get resourceController by project_id[%s] ", group.ProjectId))
+		return 0, false
+	}
+	curNum, err := resCtrl.GetAsGroupCurrentInstanceNum(vmGroup.AsGroupId)
+	if err != nil {
+		log.Error(fmt.Sprintf("it's failed to get AsScalingGroup[%s] server number of ScalingGroup[%s]",
+			vmGroup.AsGroupId, group.Id))
+		return 0, false
+	}
+	return curNum, true
+}
+
+// scaleTo 将伸缩组的实例数伸缩到targetNum并记录伸缩决定, 返回是否需要伸缩以及伸缩任务是否启动成功
+func scaleTo(log *logger.FMLogger, group *db.ScalingGroup, record *db.ScalingDecisionLog,
+	curNum, targetNum int32) (bool, bool) {
+	if targetNum == curNum {
+		return false, false
+	}
+	if targetNum > curNum {
+		setDecision(record, &model.ScalingDecision{Action: model.ScalingDecisionActionOut,
+			CalculatedNum: float64(targetNum - curNum), ScalingNum: float64(targetNum - curNum)})
+		return true, startScalingTask(log, group, record, true, func() error {
+			return taskservice.StartScaleOutGroupTask(group.Id, targetNum)
+		})
+	}
+	setDecision(record, &model.ScalingDecision{Action: model.ScalingDecisionActionIn,
+		CalculatedNum: float64(curNum - targetNum), ScalingNum: float64(curNum - targetNum)})
+	return true, startScalingTask(log, group, record, false, func() error {
+		return taskservice.StartScaleInGroupTaskForRandomVms(group.Id, group.ProjectId, curNum-targetNum)
+	})
+}
+
+func getStableScalingGroup(log *logger.FMLogger, projectId, groupId, policyId string) *db.ScalingGroup {
 	group, err := db.GetScalingGroupById(projectId, groupId)
 	if err != nil {
 		log.Error(fmt.Sprintf("it's failed to query ScalingGroup[%s] of policy[%s] from db", groupId, policyId))
@@ -87,9 +253,9 @@ func getEnableScalingGroup(log *logger.FMLogger, projectId, groupId, policyId st
 		log.Info(fmt.Sprintf("ScalingGroup[%s] is not stable or enableAutoScaling", group.Id))
 		return nil
 	}
-	if time.Now().UnixNano()-group.AutoScalingTimestamp < group.CoolDownTime*int64(time.Minute) {
-		log.Info(fmt.Sprintf("ScalingGroup[%s] is in the cooling duration", group.Id))
-		return nil
-	}
 	return group
 }
+
+func isInCoolDown(group *db.ScalingGroup) bool {
+	return time.Now().UnixNano()-group.AutoScalingTimestamp < group.CoolDownTime*int64(time.Minute)
+}
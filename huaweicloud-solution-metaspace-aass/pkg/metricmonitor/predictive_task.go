@@ -0,0 +1,198 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 预测策略的监控任务
+package metricmonitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/pkg/errors"
+
+	apimodel "scase.io/application-auto-scaling-service/pkg/api/model"
+	"scase.io/application-auto-scaling-service/pkg/common"
+	"scase.io/application-auto-scaling-service/pkg/db"
+	"scase.io/application-auto-scaling-service/pkg/metricmonitor/influxdb"
+	"scase.io/application-auto-scaling-service/pkg/metricmonitor/metric"
+	"scase.io/application-auto-scaling-service/pkg/utils"
+	"scase.io/application-auto-scaling-service/pkg/utils/logger"
+)
+
+const (
+	defaultSeasonDays = 1
+	// forecastRetention 预测的保留时长, 用于与实际需求对比
+	forecastRetention = 7 * 24 * time.Hour
+	// metricKeyForecastInstanceNumber 伸缩决定中记录的预测需要的实例数
+	metricKeyForecastInstanceNumber = "forecast_instance_number"
+)
+
+// predictiveConfigurationOf 从伸缩组的策略中读取预测策略的配置
+func predictiveConfigurationOf(group *db.ScalingGroup, policyId string) (*apimodel.PredictiveConfiguration, error) {
+	for _, policy := range group.ScalingPolicies {
+		if policy.Id != policyId {
+			continue
+		}
+		conf := &apimodel.PredictiveConfiguration{}
+		if err := json.Unmarshal([]byte(policy.PolicyConfig), conf); err != nil {
+			return nil, errors.Wrapf(err, "unmarshal predictive configuration of policy[%s] err", policyId)
+		}
+		return conf, nil
+	}
+	return nil, errors.Errorf("predictive policy[%s] is not found in ScalingGroup[%s]", policyId, group.Id)
+}
+
+func forecastDuration(conf *apimodel.PredictiveConfiguration) time.Duration {
+	return time.Duration(*conf.ForecastMinutes) * time.Minute
+}
+
+func seasonOf(conf *apimodel.PredictiveConfiguration) time.Duration {
+	days := int32(defaultSeasonDays)
+	if conf.SeasonDays != nil {
+		days = *conf.SeasonDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// forecastInstanceNumber 返回forecasts中预测的最大实例数
+func forecastInstanceNumber(forecasts []*db.ScalingForecast) int32 {
+	var num int32
+	for _, f := range forecasts {
+		if f.InstanceNumber > num {
+			num = f.InstanceNumber
+		}
+	}
+	return num
+}
+
+// predictiveFloorOfGroup 返回FORECAST_AND_SCALE预测策略在t之后ForecastMinutes内需要的最大实例数, 没有预测时为0
+func predictiveFloorOfGroup(log *logger.FMLogger, group *db.ScalingGroup, t time.Time) int32 {
+	var floor int32
+	for _, policy := range group.ScalingPolicies {
+		if policy.PolicyType != common.PolicyTypePredictive {
+			continue
+		}
+		conf, err := predictiveConfigurationOf(group, policy.Id)
+		if err != nil || *conf.Mode != common.PredictiveModeForecastAndScale {
+			continue
+		}
+		forecasts, err := db.ListScalingForecasts(policy.Id, t.Truncate(metric.ForecastBucket).Unix(),
+			t.Add(forecastDuration(conf)).Unix())
+		if err != nil {
+			log.Error("List forecasts of policy[%s] err: %+v", policy.Id, err)
+			continue
+		}
+		if num := forecastInstanceNumber(forecasts); num > floor {
+			floor = num
+		}
+	}
+	return floor
+}
+
+// boundsOfGroup 返回t时刻伸缩组生效的实例数上下限以及生效的定时策略,
+// 下限在定时策略的基础上提高到预测需要的实例数, 但不超过上限
+func boundsOfGroup(log *logger.FMLogger, group *db.ScalingGroup, t time.Time) (int32, int32, *activeSchedule) {
+	minNum, maxNum, active := activeScheduleOfGroup(group, t)
+	if floor := predictiveFloorOfGroup(log, group, t); floor > minNum {
+		minNum = clampInstanceNum(floor, minNum, maxNum)
+	}
+	return minNum, maxNum, active
+}
+
+// forecastOfGroup 根据伸缩组的历史需求预测从now所在时间段开始ForecastMinutes内每个时间段的需求和实例数
+func forecastOfGroup(log *logger.FMLogger, influxCtr *influxdb.Controller, group *db.ScalingGroup,
+	policyId string, conf *apimodel.PredictiveConfiguration, now time.Time) ([]*db.ScalingForecast, error) {
+	season := seasonOf(conf)
+	history, err := influxCtr.GetServerSessionDemandOfScalingGroup(log, group.Id, group.FleetId,
+		metric.ForecastHistoryStart(now, season).UnixNano(), now.UnixNano(), metric.ForecastBucket)
+	if err != nil {
+		return nil, fmt.Errorf("it's failed to get server session demand of ScalingGroup[%s], err: %s",
+			group.Id, err.Error())
+	}
+	var maxServerSession int32
+	if group.InstanceConfiguration != nil {
+		maxServerSession = group.InstanceConfiguration.MaxServerSession
+	}
+	horizon := int(math.Ceil(forecastDuration(conf).Minutes() / metric.ForecastBucket.Minutes()))
+	var forecasts []*db.ScalingForecast
+	for _, point := range metric.ForecastDemand(history, now, season, horizon) {
+		forecasts = append(forecasts, &db.ScalingForecast{
+			ScalingPolicyId: policyId,
+			ScalingGroupId:  group.Id,
+			ForecastTime:    point.Time.Unix(),
+			Demand:          point.Demand,
+			InstanceNumber:  metric.InstanceNumberForDemand(point.Demand, maxServerSession, *conf.TargetValue),
+		})
+	}
+	return forecasts, nil
+}
+
+// predictiveMonitorTask 预测策略的监控任务
+// 1.每个时间段预测一次未来ForecastMinutes内的需求并保存，FORECAST_ONLY只预测不伸缩；
+// 2.伸缩组存在基于目标或步进策略时，预测需要的实例数作为其伸缩的下限，由对应的监控任务提前扩容；
+// 3.伸缩组不存在基于目标或步进策略时，伸缩到预测需要的实例数并限制在生效的上下限内，缩容受冷却时间限制；
+// 4.没有预测时，只保证实例数处于生效的上下限内；
+// 5.需要伸缩时保存伸缩决定，dry run的策略不启动伸缩任务。
+func predictiveMonitorTask(log *logger.FMLogger, influxCtr *influxdb.Controller, task *db.MetricMonitorTask) {
+	group, err := db.GetScalingGroupById("", task.ScalingGroupID)
+	if err != nil {
+		log.Error(fmt.Sprintf("it's failed to query ScalingGroup[%s] of policy[%s] from db",
+			task.ScalingGroupID, task.ScalingPolicyID))
+		return
+	}
+	conf, err := predictiveConfigurationOf(group, task.ScalingPolicyID)
+	if err != nil {
+		log.Error(err.Error())
+		return
+	}
+
+	now := time.Now()
+	if bucket := now.Truncate(metric.ForecastBucket).Unix(); bucket > task.TriggeredAt {
+		forecasts, err := forecastOfGroup(log, influxCtr, group, task.ScalingPolicyID, conf, now)
+		if err != nil {
+			log.Error(err.Error())
+			return
+		}
+		if err = db.SaveScalingForecasts(forecasts); err != nil {
+			log.Error("Save forecasts of policy[%s] err: %+v", task.ScalingPolicyID, err)
+			return
+		}
+		log.Info("ScalingGroup[%s] forecasts %d buckets, max instance number: %d", group.Id, len(forecasts),
+			forecastInstanceNumber(forecasts))
+		if err = db.UpdateMetricMonitorTaskTriggeredAt(task.Id, bucket); err != nil {
+			log.Error("Record forecast time of task[%s] err: %+v", task.Id, err)
+		}
+		if err = db.DeleteExpiredScalingForecasts(now.Add(-forecastRetention).Unix()); err != nil {
+			log.Error("Delete expired forecasts err: %+v", err)
+		}
+	}
+
+	if *conf.Mode != common.PredictiveModeForecastAndScale || hasMetricBasedPolicy(group) ||
+		group.State != db.ScalingGroupStateStable || group.EnableAutoScaling == false {
+		return
+	}
+	curNum, ok := currentInstanceNum(log, group)
+	if !ok {
+		return
+	}
+	minNum, maxNum, _ := activeScheduleOfGroup(group, now)
+	targetNum := clampInstanceNum(curNum, minNum, maxNum)
+	floor := predictiveFloorOfGroup(log, group, now)
+	if floor > 0 {
+		targetNum = clampInstanceNum(floor, minNum, maxNum)
+	}
+	if targetNum < curNum && isInCoolDown(group) {
+		return
+	}
+	record := newDecisionLog(group, task)
+	setInstanceNumbers(record, curNum, minNum, maxNum)
+	record.Metrics = utils.ToJson(map[string]float64{metricKeyForecastInstanceNumber: float64(floor)})
+	scaled, started := scaleTo(log, group, record, curNum, targetNum)
+	if scaled {
+		saveDecisionLog(log, record)
+	}
+	if started {
+		log.Info("ScalingGroup[%s] is scaled by forecast, instance number: %d -> %d", group.Id, curNum, targetNum)
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 预测策略监控任务测试
+package metricmonitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"scase.io/application-auto-scaling-service/pkg/common"
+	"scase.io/application-auto-scaling-service/pkg/db"
+)
+
+func TestPredictiveConfigurationOf(t *testing.T) {
+	group := &db.ScalingGroup{Id: "group", ScalingPolicies: []*db.ScalingPolicy{
+		{Id: "predictive", PolicyType: common.PolicyTypePredictive,
+			PolicyConfig: `{"mode":"FORECAST_AND_SCALE","forecast_minutes":30,"season_days":7,"target_value":20}`},
+	}}
+	conf, err := predictiveConfigurationOf(group, "predictive")
+	assert.Nil(t, err)
+	assert.Equal(t, common.PredictiveModeForecastAndScale, *conf.Mode)
+	assert.Equal(t, 30*time.Minute, forecastDuration(conf))
+	assert.Equal(t, 7*24*time.Hour, seasonOf(conf))
+
+	conf.SeasonDays = nil
+	assert.Equal(t, 24*time.Hour, seasonOf(conf))
+
+	_, err = predictiveConfigurationOf(group, "missing")
+	assert.NotNil(t, err)
+}
+
+func TestForecastInstanceNumber(t *testing.T) {
+	assert.Equal(t, int32(0), forecastInstanceNumber(nil))
+	assert.Equal(t, int32(8), forecastInstanceNumber([]*db.ScalingForecast{
+		{InstanceNumber: 3}, {InstanceNumber: 8}, {InstanceNumber: 5},
+	}))
+}
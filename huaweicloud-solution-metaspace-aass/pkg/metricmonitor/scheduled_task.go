@@ -0,0 +1,213 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 定时策略的时间窗与监控任务
+package metricmonitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/robfig/cron/v3"
+
+	"scase.io/application-auto-scaling-service/pkg/api/model"
+	"scase.io/application-auto-scaling-service/pkg/common"
+	"scase.io/application-auto-scaling-service/pkg/db"
+	"scase.io/application-auto-scaling-service/pkg/utils/logger"
+)
+
+const defaultTimeZone = "UTC"
+
+// ScheduleWindow 定时策略的时间窗, 每次开始后持续duration
+type ScheduleWindow struct {
+	schedule cron.Schedule
+	duration time.Duration
+}
+
+// onceSchedule 单次时间窗, 开始后不再触发
+type onceSchedule struct {
+	at time.Time
+}
+
+// Next 返回t之后的开始时间, 零值表示不再触发
+func (s onceSchedule) Next(t time.Time) time.Time {
+	if t.Before(s.at) {
+		return s.at
+	}
+	return time.Time{}
+}
+
+// ParseScheduleWindow 解析定时策略的时间窗, Recurrence和StartTime均按TimeZone解释
+func ParseScheduleWindow(conf *model.ScheduledConfiguration) (*ScheduleWindow, error) {
+	if conf.DurationMinutes == nil {
+		return nil, errors.New("duration_minutes is required")
+	}
+	tz := defaultTimeZone
+	if conf.TimeZone != nil {
+		tz = *conf.TimeZone
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, errors.Wrapf(err, "load time zone[%s] err", tz)
+	}
+
+	w := &ScheduleWindow{duration: time.Duration(*conf.DurationMinutes) * time.Minute}
+	if conf.Recurrence != nil {
+		// @every按任务启动时间计算, 无法确定时间窗的开始时间
+		if strings.HasPrefix(*conf.Recurrence, "@every") || strings.Contains(*conf.Recurrence, "TZ=") {
+			return nil, errors.Errorf("recurrence[%s] is not supported", *conf.Recurrence)
+		}
+		w.schedule, err = cron.ParseStandard(fmt.Sprintf("CRON_TZ=%s %s", tz, *conf.Recurrence))
+		if err != nil {
+			return nil, errors.Wrapf(err, "parse recurrence[%s] err", *conf.Recurrence)
+		}
+		return w, nil
+	}
+	if conf.StartTime == nil {
+		return nil, errors.New("recurrence or start_time is required")
+	}
+	at, err := time.ParseInLocation(common.TimeLayout, *conf.StartTime, loc)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse start_time[%s] err", *conf.StartTime)
+	}
+	w.schedule = onceSchedule{at: at}
+	return w, nil
+}
+
+// ActiveStart 返回t所处时间窗的开始时间, 时间窗重叠时取最近开始的一个, 不在时间窗内时返回零值
+func (w *ScheduleWindow) ActiveStart(t time.Time) time.Time {
+	var start time.Time
+	for next := w.schedule.Next(t.Add(-w.duration)); !next.IsZero() && !next.After(t); next = w.schedule.Next(next) {
+		start = next
+	}
+	return start
+}
+
+// activeSchedule 当前生效的定时策略
+type activeSchedule struct {
+	policyId string
+	start    time.Time
+	conf     *model.ScheduledConfiguration
+}
+
+// activeScheduleOfGroup 返回t时刻伸缩组生效的实例数上下限以及生效的定时策略:
+// 1.多个定时策略的时间窗重叠时, 以最近开始的为准;
+// 2.定时策略未指定的上限或下限沿用伸缩组自身的值, 两者冲突时以策略指定的一侧为准;
+// 3.不在任何时间窗内时, 为伸缩组自身的上下限.
+func activeScheduleOfGroup(group *db.ScalingGroup, t time.Time) (int32, int32, *activeSchedule) {
+	var active *activeSchedule
+	for _, policy := range group.ScalingPolicies {
+		if policy.PolicyType != common.PolicyTypeScheduled {
+			continue
+		}
+		conf := &model.ScheduledConfiguration{}
+		if err := json.Unmarshal([]byte(policy.PolicyConfig), conf); err != nil {
+			continue
+		}
+		w, err := ParseScheduleWindow(conf)
+		if err != nil {
+			continue
+		}
+		start := w.ActiveStart(t)
+		if start.IsZero() || (active != nil && !start.After(active.start)) {
+			continue
+		}
+		active = &activeSchedule{policyId: policy.Id, start: start, conf: conf}
+	}
+
+	minNum, maxNum := group.MinInstanceNumber, group.MaxInstanceNumber
+	if active == nil {
+		return minNum, maxNum, nil
+	}
+	if active.conf.MinInstanceNumber != nil {
+		minNum = *active.conf.MinInstanceNumber
+	}
+	if active.conf.MaxInstanceNumber != nil {
+		maxNum = *active.conf.MaxInstanceNumber
+	}
+	if minNum > maxNum {
+		if active.conf.MinInstanceNumber != nil {
+			maxNum = minNum
+		} else {
+			minNum = maxNum
+		}
+	}
+	return minNum, maxNum, active
+}
+
+func clampInstanceNum(num, minNum, maxNum int32) int32 {
+	if num < minNum {
+		return minNum
+	}
+	if num > maxNum {
+		return maxNum
+	}
+	return num
+}
+
+func hasMetricBasedPolicy(group *db.ScalingGroup) bool {
+	for _, policy := range group.ScalingPolicies {
+		if policy.PolicyType == common.PolicyTypeTargetBased || policy.PolicyType == common.PolicyTypeStep {
+			return true
+		}
+	}
+	return false
+}
+
+// scheduledMonitorTask 定时策略的监控任务
+// 1.当实例伸缩组不处于stable或enableAutoScaling状态时，不执行；
+// 2.进入策略新的时间窗时，伸缩到期望实例数(未指定时为当前实例数)并限制在时间窗的上下限内，不受冷却时间限制；
+// 3.伸缩组不存在基于目标或步进策略时，保证实例数处于生效的上下限内(包括预测策略提高的下限)，时间窗结束后恢复为伸缩组自身的上下限；
+// 4.伸缩组存在基于目标或步进策略时，上下限由对应的监控任务保证；
+// 5.需要伸缩时保存伸缩决定，dry run的策略不启动伸缩任务。
+func scheduledMonitorTask(log *logger.FMLogger, task *db.MetricMonitorTask) {
+	group, err := db.GetScalingGroupById("", task.ScalingGroupID)
+	if err != nil {
+		log.Error(fmt.Sprintf("it's failed to query ScalingGroup[%s] of policy[%s] from db",
+			task.ScalingGroupID, task.ScalingPolicyID))
+		return
+	}
+	if group.State != db.ScalingGroupStateStable || group.EnableAutoScaling == false {
+		return
+	}
+
+	minNum, maxNum, active := boundsOfGroup(log, group, time.Now())
+	triggered := active != nil && active.policyId == task.ScalingPolicyID && active.start.Unix() > task.TriggeredAt
+	if !triggered && (hasMetricBasedPolicy(group) || isInCoolDown(group)) {
+		return
+	}
+	curNum, ok := currentInstanceNum(log, group)
+	if !ok {
+		return
+	}
+
+	targetNum := clampInstanceNum(curNum, minNum, maxNum)
+	if triggered && active.conf.DesireInstanceNumber != nil {
+		targetNum = clampInstanceNum(*active.conf.DesireInstanceNumber, minNum, maxNum)
+	}
+	record := newDecisionLog(group, task)
+	setInstanceNumbers(record, curNum, minNum, maxNum)
+	record.InCoolDown = isInCoolDown(group)
+	record.Message = "instance number is out of the bounds"
+	if triggered {
+		record.Message = fmt.Sprintf("schedule window started at %s", active.start.Format(time.RFC3339))
+	}
+	scaled, started := scaleTo(log, group, record, curNum, targetNum)
+	if scaled {
+		saveDecisionLog(log, record)
+	}
+	// dry run时时间窗同样视为已执行, 避免每个周期重复记录
+	if scaled && !started && !record.DryRun {
+		return
+	}
+	if !triggered {
+		return
+	}
+	log.Info("Schedule window started at %s of group[%s] is applied, instance number: %d -> %d, bounds: [%d, %d]",
+		active.start.Format(time.RFC3339), group.Id, curNum, targetNum, minNum, maxNum)
+	if err = db.UpdateMetricMonitorTaskTriggeredAt(task.Id, active.start.Unix()); err != nil {
+		log.Error("Record triggered schedule window of task[%s] err: %+v", task.Id, err)
+	}
+}
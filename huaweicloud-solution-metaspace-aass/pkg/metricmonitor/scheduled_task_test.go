@@ -0,0 +1,154 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 定时策略测试
+package metricmonitor
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"scase.io/application-auto-scaling-service/pkg/api/model"
+	"scase.io/application-auto-scaling-service/pkg/common"
+	"scase.io/application-auto-scaling-service/pkg/db"
+)
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
+
+func TestScheduleWindowActiveStart(t *testing.T) {
+	shanghai, _ := time.LoadLocation("Asia/Shanghai")
+	tests := []struct {
+		name string
+		conf *model.ScheduledConfiguration
+		now  time.Time
+		want time.Time
+	}{
+		{
+			name: "recurrence in time zone",
+			conf: &model.ScheduledConfiguration{Recurrence: strPtr("0 18 * * *"),
+				DurationMinutes: int32Ptr(120), TimeZone: strPtr("Asia/Shanghai")},
+			now:  time.Date(2022, 10, 1, 11, 30, 0, 0, time.UTC),
+			want: time.Date(2022, 10, 1, 18, 0, 0, 0, shanghai),
+		},
+		{
+			name: "recurrence window is over",
+			conf: &model.ScheduledConfiguration{Recurrence: strPtr("0 18 * * *"),
+				DurationMinutes: int32Ptr(120), TimeZone: strPtr("Asia/Shanghai")},
+			now: time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "overlapping windows use the latest start",
+			conf: &model.ScheduledConfiguration{Recurrence: strPtr("0 * * * *"),
+				DurationMinutes: int32Ptr(150)},
+			now:  time.Date(2022, 10, 1, 12, 30, 0, 0, time.UTC),
+			want: time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "one-off window",
+			conf: &model.ScheduledConfiguration{StartTime: strPtr("2022-10-01T08:00:00"),
+				DurationMinutes: int32Ptr(60)},
+			now:  time.Date(2022, 10, 1, 8, 59, 0, 0, time.UTC),
+			want: time.Date(2022, 10, 1, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "one-off window not started",
+			conf: &model.ScheduledConfiguration{StartTime: strPtr("2022-10-01T08:00:00"),
+				DurationMinutes: int32Ptr(60)},
+			now: time.Date(2022, 10, 1, 7, 59, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, err := ParseScheduleWindow(tt.conf)
+			assert.Nil(t, err)
+			assert.True(t, tt.want.Equal(w.ActiveStart(tt.now)), "ActiveStart() = %v, want %v",
+				w.ActiveStart(tt.now), tt.want)
+		})
+	}
+}
+
+func TestParseScheduleWindowInvalid(t *testing.T) {
+	confs := []*model.ScheduledConfiguration{
+		{Recurrence: strPtr("0 18 * *"), DurationMinutes: int32Ptr(60)},
+		{Recurrence: strPtr("@every 1h"), DurationMinutes: int32Ptr(60)},
+		{Recurrence: strPtr("0 18 * * *"), DurationMinutes: int32Ptr(60), TimeZone: strPtr("Mars/Olympus")},
+		{StartTime: strPtr("2022-10-01 08:00"), DurationMinutes: int32Ptr(60)},
+		{DurationMinutes: int32Ptr(60)},
+	}
+	for _, conf := range confs {
+		_, err := ParseScheduleWindow(conf)
+		assert.NotNil(t, err)
+	}
+}
+
+func scheduledPolicy(id string, conf *model.ScheduledConfiguration) *db.ScalingPolicy {
+	bytes, _ := json.Marshal(conf)
+	return &db.ScalingPolicy{Id: id, PolicyType: common.PolicyTypeScheduled, PolicyConfig: string(bytes)}
+}
+
+func TestActiveScheduleOfGroup(t *testing.T) {
+	now := time.Date(2022, 10, 1, 10, 30, 0, 0, time.UTC)
+	daily := scheduledPolicy("daily", &model.ScheduledConfiguration{Recurrence: strPtr("0 10 * * *"),
+		DurationMinutes: int32Ptr(120), MinInstanceNumber: int32Ptr(5), MaxInstanceNumber: int32Ptr(20)})
+	launch := scheduledPolicy("launch", &model.ScheduledConfiguration{StartTime: strPtr("2022-10-01T10:15:00"),
+		DurationMinutes: int32Ptr(60), MinInstanceNumber: int32Ptr(30)})
+	night := scheduledPolicy("night", &model.ScheduledConfiguration{Recurrence: strPtr("0 22 * * *"),
+		DurationMinutes: int32Ptr(480), MaxInstanceNumber: int32Ptr(2)})
+	target := &db.ScalingPolicy{Id: "target", PolicyType: common.PolicyTypeTargetBased}
+
+	tests := []struct {
+		name     string
+		policies []*db.ScalingPolicy
+		wantMin  int32
+		wantMax  int32
+		wantId   string
+	}{
+		{
+			name:     "no active window uses group bounds",
+			policies: []*db.ScalingPolicy{night, target},
+			wantMin:  1,
+			wantMax:  10,
+		},
+		{
+			name:     "active window overrides group bounds",
+			policies: []*db.ScalingPolicy{daily, night, target},
+			wantMin:  5,
+			wantMax:  20,
+			wantId:   "daily",
+		},
+		{
+			name:     "latest window wins and floor raises ceiling",
+			policies: []*db.ScalingPolicy{daily, launch},
+			wantMin:  30,
+			wantMax:  30,
+			wantId:   "launch",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group := &db.ScalingGroup{MinInstanceNumber: 1, MaxInstanceNumber: 10, ScalingPolicies: tt.policies}
+			minNum, maxNum, active := activeScheduleOfGroup(group, now)
+			assert.Equal(t, tt.wantMin, minNum)
+			assert.Equal(t, tt.wantMax, maxNum)
+			if tt.wantId == "" {
+				assert.Nil(t, active)
+				return
+			}
+			assert.Equal(t, tt.wantId, active.policyId)
+		})
+	}
+}
+
+func TestClampInstanceNum(t *testing.T) {
+	assert.Equal(t, int32(5), clampInstanceNum(3, 5, 20))
+	assert.Equal(t, int32(20), clampInstanceNum(25, 5, 20))
+	assert.Equal(t, int32(8), clampInstanceNum(8, 5, 20))
+}
@@ -0,0 +1,93 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 步进策略的监控任务
+package metricmonitor
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+
+	apimodel "scase.io/application-auto-scaling-service/pkg/api/model"
+	"scase.io/application-auto-scaling-service/pkg/db"
+	"scase.io/application-auto-scaling-service/pkg/metricmonitor/metric"
+	"scase.io/application-auto-scaling-service/pkg/metricmonitor/model"
+	"scase.io/application-auto-scaling-service/pkg/utils/logger"
+)
+
+// stepConfigurationOf 从伸缩组的策略中读取步进策略的配置
+func stepConfigurationOf(group *db.ScalingGroup, policyId string) (*apimodel.StepConfiguration, error) {
+	for _, policy := range group.ScalingPolicies {
+		if policy.Id != policyId {
+			continue
+		}
+		conf := &apimodel.StepConfiguration{}
+		if err := json.Unmarshal([]byte(policy.PolicyConfig), conf); err != nil {
+			return nil, errors.Wrapf(err, "unmarshal step configuration of policy[%s] err", policyId)
+		}
+		return conf, nil
+	}
+	return nil, errors.Errorf("step policy[%s] is not found in ScalingGroup[%s]", policyId, group.Id)
+}
+
+// isInStepCoolDown 扩容只受上次扩容的冷却时长限制, 缩容在上次扩容或缩容后都需要等待缩容的冷却时长
+func isInStepCoolDown(group *db.ScalingGroup, conf *apimodel.StepConfiguration, task *db.MetricMonitorTask,
+	action string, now time.Time) bool {
+	switch action {
+	case model.ScalingDecisionActionOut:
+		coolDown := group.CoolDownTime
+		if conf.ScaleOutCoolDownTime != nil {
+			coolDown = int64(*conf.ScaleOutCoolDownTime)
+		}
+		return now.UnixNano()-task.ScaleOutTimestamp < coolDown*int64(time.Minute)
+	case model.ScalingDecisionActionIn:
+		coolDown := group.CoolDownTime
+		if conf.ScaleInCoolDownTime != nil {
+			coolDown = int64(*conf.ScaleInCoolDownTime)
+		}
+		last := task.ScaleInTimestamp
+		if task.ScaleOutTimestamp > last {
+			last = task.ScaleOutTimestamp
+		}
+		return now.UnixNano()-last < coolDown*int64(time.Minute)
+	}
+	return false
+}
+
+// stepPolicyTask 参与伸缩组共同判断的步进策略及其监控任务
+type stepPolicyTask struct {
+	*metric.StepPolicy
+	task *db.MetricMonitorTask
+}
+
+// newStepPolicyTask 读取步进策略的区间配置, 冷却期间按策略的扩容和缩容冷却时长分别判断
+func newStepPolicyTask(group *db.ScalingGroup, task *db.MetricMonitorTask, policy *metric.TargetPolicy,
+	evaluator *metric.StepEvaluator, now time.Time) (*stepPolicyTask, error) {
+	conf, err := stepConfigurationOf(group, task.ScalingPolicyID)
+	if err != nil {
+		return nil, err
+	}
+	return &stepPolicyTask{
+		StepPolicy: &metric.StepPolicy{
+			Policy:    policy,
+			Conf:      conf,
+			Evaluator: evaluator,
+			InCoolDown: func(action string) bool {
+				return isInStepCoolDown(group, conf, task, action, now)
+			},
+		},
+		task: task,
+	}, nil
+}
+
+// recordStepScaling 伸缩组伸缩后记录所有步进策略的伸缩时间用于冷却, 并重新累计连续周期数
+func recordStepScaling(log *logger.FMLogger, steps []*stepPolicyTask, action string) {
+	for _, step := range steps {
+		step.Evaluator.Reset()
+		if err := db.UpdateMetricMonitorTaskScaleTimestamp(step.task.Id,
+			action == model.ScalingDecisionActionOut); err != nil {
+			log.Error("Record scaling timestamp of task[%s] err: %+v", step.task.Id, err)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 步进策略监控任务测试
+package metricmonitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"scase.io/application-auto-scaling-service/pkg/api/model"
+	"scase.io/application-auto-scaling-service/pkg/common"
+	"scase.io/application-auto-scaling-service/pkg/db"
+	"scase.io/application-auto-scaling-service/pkg/metricmonitor/metric"
+	decision "scase.io/application-auto-scaling-service/pkg/metricmonitor/model"
+)
+
+func TestIsInStepCoolDown(t *testing.T) {
+	now := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	minutesAgo := func(m int) int64 {
+		return now.Add(-time.Duration(m) * time.Minute).UnixNano()
+	}
+	group := &db.ScalingGroup{CoolDownTime: 5}
+	conf := &model.StepConfiguration{ScaleOutCoolDownTime: int32Ptr(1), ScaleInCoolDownTime: int32Ptr(10)}
+
+	tests := []struct {
+		name   string
+		conf   *model.StepConfiguration
+		task   *db.MetricMonitorTask
+		action string
+		want   bool
+	}{
+		{
+			name:   "scale out after scale out cool down",
+			conf:   conf,
+			task:   &db.MetricMonitorTask{ScaleOutTimestamp: minutesAgo(2)},
+			action: decision.ScalingDecisionActionOut,
+			want:   false,
+		},
+		{
+			name:   "scale out is not blocked by scale in",
+			conf:   conf,
+			task:   &db.MetricMonitorTask{ScaleInTimestamp: minutesAgo(0)},
+			action: decision.ScalingDecisionActionOut,
+			want:   false,
+		},
+		{
+			name:   "scale in waits after scale out",
+			conf:   conf,
+			task:   &db.MetricMonitorTask{ScaleOutTimestamp: minutesAgo(2), ScaleInTimestamp: minutesAgo(30)},
+			action: decision.ScalingDecisionActionIn,
+			want:   true,
+		},
+		{
+			name:   "group cool down is the default",
+			conf:   &model.StepConfiguration{},
+			task:   &db.MetricMonitorTask{ScaleInTimestamp: minutesAgo(3)},
+			action: decision.ScalingDecisionActionIn,
+			want:   true,
+		},
+		{
+			name:   "no scaling",
+			conf:   conf,
+			task:   &db.MetricMonitorTask{ScaleOutTimestamp: minutesAgo(0)},
+			action: decision.ScalingDecisionActionNone,
+			want:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isInStepCoolDown(group, tt.conf, tt.task, tt.action, now))
+		})
+	}
+}
+
+func TestStepConfigurationOf(t *testing.T) {
+	group := &db.ScalingGroup{Id: "group", ScalingPolicies: []*db.ScalingPolicy{
+		{Id: "step", PolicyConfig: `{"metric_name":"CUSTOM_METRIC","custom_metric_name":"cpu",` +
+			`"steps":[{"upper_bound":50,"adjustment_type":"CHANGE_IN_CAPACITY","adjustment":-1}]}`},
+	}}
+	conf, err := stepConfigurationOf(group, "step")
+	assert.Nil(t, err)
+	assert.Equal(t, "cpu", *conf.CustomMetricName)
+	assert.Equal(t, float64(50), *conf.Steps[0].UpperBound)
+
+	_, err = stepConfigurationOf(group, "missing")
+	assert.NotNil(t, err)
+}
+
+func TestSplitStepPolicies(t *testing.T) {
+	group := &db.ScalingGroup{Id: "group", CoolDownTime: 5, ScalingPolicies: []*db.ScalingPolicy{
+		{Id: "step", PolicyConfig: `{"metric_name":"CUSTOM_METRIC","custom_metric_name":"cpu",` +
+			`"steps":[{"upper_bound":50,"adjustment_type":"CHANGE_IN_CAPACITY","adjustment":-1}],` +
+			`"scale_out_cool_down_time":1}`},
+	}}
+	tasks := []*db.MetricMonitorTask{
+		{Id: "step", ScalingPolicyID: "step", PolicyType: common.PolicyTypeStep,
+			ScaleInTimestamp: time.Now().UnixNano()},
+		{Id: "target", ScalingPolicyID: "target", PolicyType: common.PolicyTypeTargetBased},
+	}
+	evaluators := map[string]*metric.StepEvaluator{}
+	evaluatorOf := func(taskId string) *metric.StepEvaluator {
+		if evaluators[taskId] == nil {
+			evaluators[taskId] = &metric.StepEvaluator{}
+		}
+		return evaluators[taskId]
+	}
+
+	// 步进策略与基于目标策略由同一个任务共同判断
+	policies := targetPoliciesOfTasks(tasks)
+	targets, steps := splitStepPolicies(nil, group, tasks, policies, evaluatorOf)
+	assert.Equal(t, []*metric.TargetPolicy{policies[1]}, targets)
+	assert.Len(t, steps, 1)
+	assert.Equal(t, policies[0], steps[0].Policy)
+	assert.Equal(t, evaluators["step"], steps[0].Evaluator)
+	assert.Equal(t, "cpu", *steps[0].Conf.CustomMetricName)
+
+	// 按步进策略任务自身的伸缩时间判断冷却
+	assert.True(t, steps[0].InCoolDown(decision.ScalingDecisionActionIn))
+	assert.False(t, steps[0].InCoolDown(decision.ScalingDecisionActionOut))
+}
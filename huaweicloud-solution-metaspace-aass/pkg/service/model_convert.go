@@ -103,6 +103,9 @@ func updateInstanceConfiguration(reqIc model.InstanceConfiguration,
 			r.MaxConcurrentServerSessionsPerProcess = reqIc.RuntimeConfiguration.MaxConcurrentServerSessionsPerProcess
 			numServerSessionsPerProcess = *reqIc.RuntimeConfiguration.MaxConcurrentServerSessionsPerProcess
 		}
+		if reqIc.RuntimeConfiguration.PlacementPolicy != nil {
+			r.PlacementPolicy = reqIc.RuntimeConfiguration.PlacementPolicy
+		}
 		if reqIc.RuntimeConfiguration.ProcessConfigurations != nil {
 			r.ProcessConfigurations = reqIc.RuntimeConfiguration.ProcessConfigurations
 			for _, pc := range *reqIc.RuntimeConfiguration.ProcessConfigurations {
@@ -193,7 +196,16 @@ func getVmScalingGroup(id, asConfigId string) *db.VmScalingGroup {
 
 func convertCreateScalingPolicyReq(req model.CreateScalingPolicyReq, projectId,
 	policyId string) (*db.ScalingPolicy, error) {
-	confBytes, err := json.Marshal(req.TargetConfiguration)
+	var conf interface{} = req.TargetConfiguration
+	switch *req.Type {
+	case common.PolicyTypeScheduled:
+		conf = req.ScheduledConfiguration
+	case common.PolicyTypeStep:
+		conf = req.StepConfiguration
+	case common.PolicyTypePredictive:
+		conf = req.PredictiveConfiguration
+	}
+	confBytes, err := json.Marshal(conf)
 	if err != nil {
 		return nil, err
 	}
@@ -207,6 +219,9 @@ func convertCreateScalingPolicyReq(req model.CreateScalingPolicyReq, projectId,
 		PolicyType:   *req.Type,
 		ProjectId:    projectId,
 	}
+	if req.DryRun != nil {
+		policy.DryRun = *req.DryRun
+	}
 	return policy, nil
 }
 
@@ -214,8 +229,21 @@ func convertUpdateScalingPolicyReq(req model.UpdateScalingPolicyReq, policy *db.
 	if req.Name != nil {
 		policy.Name = *req.Name
 	}
-	if req.TargetConfiguration != nil {
-		bytes, err := json.Marshal(req.TargetConfiguration)
+	if req.DryRun != nil {
+		policy.DryRun = *req.DryRun
+	}
+	var conf interface{}
+	if policy.PolicyType == common.PolicyTypeScheduled && req.ScheduledConfiguration != nil {
+		conf = req.ScheduledConfiguration
+	} else if policy.PolicyType == common.PolicyTypeStep && req.StepConfiguration != nil {
+		conf = req.StepConfiguration
+	} else if policy.PolicyType == common.PolicyTypePredictive && req.PredictiveConfiguration != nil {
+		conf = req.PredictiveConfiguration
+	} else if policy.PolicyType == common.PolicyTypeTargetBased && req.TargetConfiguration != nil {
+		conf = req.TargetConfiguration
+	}
+	if conf != nil {
+		bytes, err := json.Marshal(conf)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,86 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 自动伸缩决定记录查询服务
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"scase.io/application-auto-scaling-service/pkg/api/errors"
+	"scase.io/application-auto-scaling-service/pkg/api/model"
+	"scase.io/application-auto-scaling-service/pkg/common"
+	"scase.io/application-auto-scaling-service/pkg/db"
+	"scase.io/application-auto-scaling-service/pkg/utils/logger"
+)
+
+const defaultDecisionQueryDuration = 24 * time.Hour
+
+// ListScalingDecisions 按时间倒序分页查询伸缩组在[start, end)内的伸缩决定记录, start默认为24小时前, end默认为当前时间
+func ListScalingDecisions(log *logger.FMLogger, projectId, groupId string, start, end *time.Time,
+	limit, offset int) (*model.ScalingDecisionList, *errors.ErrorResp) {
+	if exist := db.IsScalingGroupExist(projectId, groupId); !exist {
+		log.Error("The scaling group[%s] of project[%s] is not found", groupId, projectId)
+		return nil, errors.NewErrorRespWithHttpCode(errors.ScalingGroupNotFound, http.StatusNotFound)
+	}
+	now := time.Now().UTC()
+	if end == nil {
+		end = &now
+	}
+	if start == nil {
+		defaultStart := end.Add(-defaultDecisionQueryDuration)
+		start = &defaultStart
+	}
+	if !end.After(*start) {
+		log.Error("The scaling decision query range [%s, %s) is invalid", start, end)
+		return nil, errors.NewErrorRespWithHttpCode(errors.QueryParamTimeError, http.StatusBadRequest)
+	}
+	logs, total, err := db.ListScalingDecisionLogs(groupId, *start, *end, limit, offset)
+	if err != nil {
+		log.Error("List scaling decision logs of group[%s] err: %+v", groupId, err)
+		return nil, errors.NewErrorRespWithHttpCode(errors.ServerInternalError, http.StatusInternalServerError)
+	}
+	list := &model.ScalingDecisionList{
+		TotalCount:       total,
+		Count:            len(logs),
+		ScalingDecisions: make([]*model.ScalingDecisionLog, 0, len(logs)),
+	}
+	for _, l := range logs {
+		list.ScalingDecisions = append(list.ScalingDecisions, convertDaoScalingDecisionLog(log, l))
+	}
+	return list, nil
+}
+
+// convertDaoScalingDecisionLog 指标和实例列表解析失败时只记录日志, 不影响其他字段的返回
+func convertDaoScalingDecisionLog(log *logger.FMLogger, l *db.ScalingDecisionLog) *model.ScalingDecisionLog {
+	decision := &model.ScalingDecisionLog{
+		ScalingGroupId:        l.ScalingGroupId,
+		ScalingPolicyId:       l.ScalingPolicyId,
+		PolicyType:            l.PolicyType,
+		EvaluatedAt:           l.EvaluatedAt.UTC().Format(common.TimeLayout),
+		CurrentInstanceNumber: l.CurrentInstanceNumber,
+		MinInstanceNumber:     l.MinInstanceNumber,
+		MaxInstanceNumber:     l.MaxInstanceNumber,
+		InCoolDown:            l.InCoolDown,
+		Action:                l.Action,
+		CalculatedNum:         l.CalculatedNum,
+		AvailableNum:          l.AvailableNum,
+		ScalingNum:            l.ScalingNum,
+		TaskId:                l.TaskId,
+		SkipReason:            l.SkipReason,
+		Message:               l.Message,
+		DryRun:                l.DryRun,
+	}
+	if l.Metrics != "" {
+		if err := json.Unmarshal([]byte(l.Metrics), &decision.Metrics); err != nil {
+			log.Error("Unmarshal metrics of scaling decision log[%d] err: %+v", l.Id, err)
+		}
+	}
+	if l.Instances != "" {
+		if err := json.Unmarshal([]byte(l.Instances), &decision.Instances); err != nil {
+			log.Error("Unmarshal instances of scaling decision log[%d] err: %+v", l.Id, err)
+		}
+	}
+	return decision
+}
@@ -162,6 +162,11 @@ func UpdateInstanceScalingGroup(log *logger.FMLogger, projectId, groupId string,
 	if errC := UpdateScalingGroupTagsAndInstanceConfiguration(rc, req, group, log); err != nil {
 		return errC
 	}
+	if req.ImageId != nil {
+		if errResp := updateScalingGroupImage(rc, group, *req.ImageId, log); errResp != nil {
+			return errResp
+		}
+	}
 	if err = scalingJudgmentForUpdateScalingGroup(log, projectId, group); err != nil {
 		if pkgerrors.Is(err, common.ErrScalingGroupNotStable) {
 			log.Info("The instance num of group[%s] cannot be updated because group is locked", group.Id)
@@ -208,6 +213,33 @@ func UpdateScalingGroupTagsAndInstanceConfiguration(rc *cloudresource.ResourceCo
 	return nil
 }
 
+// updateScalingGroupImage 替换AS伸缩组的伸缩配置, 之后扩容的实例从新镜像启动, 原伸缩配置删除失败时仅记录日志
+func updateScalingGroupImage(rc *cloudresource.ResourceController, group *db.ScalingGroup, imageId string,
+	log *logger.FMLogger) *errors.ErrorResp {
+	vmGroup, err := db.GetVmScalingGroupById(group.ResourceId)
+	if err != nil {
+		log.Error("Read vm scaling group[%s] from db err: %+v", group.ResourceId, err)
+		return errors.NewErrorRespWithHttpCode(errors.ServerInternalError, http.StatusInternalServerError)
+	}
+	configId, err := rc.ReplaceAsScalingConfigImage(log, group.FleetId, group.Id, vmGroup.AsGroupId,
+		vmGroup.ScalingConfigId, imageId)
+	if err != nil {
+		log.Error("Replace image of scaling group[%s] with [%s] err: %+v", group.Id, imageId, err)
+		return errors.NewErrorRespWithHttpCode(errors.ServerInternalError, http.StatusInternalServerError)
+	}
+	if configId == vmGroup.ScalingConfigId {
+		return nil
+	}
+	if err = db.UpdateAsConfigIdOfVmScalingGroup(vmGroup.Id, configId); err != nil {
+		log.Error("Update as config id of vm scaling group[%s] to [%s] err: %+v", vmGroup.Id, configId, err)
+		return errors.NewErrorRespWithHttpCode(errors.ServerInternalError, http.StatusInternalServerError)
+	}
+	if err = rc.DeleteAsScalingConfig(log, vmGroup.ScalingConfigId); err != nil {
+		log.Warn("Delete replaced as scaling config[%s] err: %+v", vmGroup.ScalingConfigId, err)
+	}
+	return nil
+}
+
 // GetInstanceScalingGroup get instance scaling group detail
 func GetInstanceScalingGroup(log *logger.FMLogger, projectId, groupId string) (*model.ScalingGroupDetail, *errors.ErrorResp) {
 	if exist := db.IsScalingGroupExist(projectId, groupId); !exist {
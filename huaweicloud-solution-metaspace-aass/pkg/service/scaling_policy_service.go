@@ -4,7 +4,9 @@
 package service
 
 import (
+	"encoding/json"
 	"net/http"
+	"time"
 
 	"github.com/beego/beego/v2/client/orm"
 	"github.com/google/uuid"
@@ -16,10 +18,16 @@ import (
 	"scase.io/application-auto-scaling-service/pkg/common"
 	"scase.io/application-auto-scaling-service/pkg/db"
 	"scase.io/application-auto-scaling-service/pkg/metricmonitor"
+	"scase.io/application-auto-scaling-service/pkg/metricmonitor/metric"
 	"scase.io/application-auto-scaling-service/pkg/service/taskservice"
 	"scase.io/application-auto-scaling-service/pkg/utils/logger"
 )
 
+const (
+	defaultForecastQueryDuration = 24 * time.Hour
+	maxForecastQueryDuration     = 7 * 24 * time.Hour
+)
+
 // CreateScalingPolicy create scaling policy
 func CreateScalingPolicy(log *logger.FMLogger, projectId string,
 	req model.CreateScalingPolicyReq) (*model.CreateScalingPolicyResp, *errors.ErrorResp) {
@@ -36,9 +44,8 @@ func CreateScalingPolicy(log *logger.FMLogger, projectId string,
 		log.Error(errors.ScalingGroupDeleting.Msg())
 		return nil, errors.NewErrorRespWithHttpCode(errors.ScalingGroupDeleting, http.StatusBadRequest)
 	}
-	if db.IsTargetBasedPolicyExistInScalingGroup(projectId, *req.InstanceScalingGroupID) {
-		log.Error(errors.TargetBasedPolicyExist.Msg())
-		return nil, errors.NewErrorRespWithHttpCode(errors.TargetBasedPolicyExist, http.StatusBadRequest)
+	if errResp := checkPolicyConfiguration(log, projectId, req); errResp != nil {
+		return nil, errResp
 	}
 	policyId := uuid.NewString()
 	policy, err := convertCreateScalingPolicyReq(req, projectId, policyId)
@@ -47,8 +54,20 @@ func CreateScalingPolicy(log *logger.FMLogger, projectId string,
 		return nil, errors.NewErrorRespWithHttpCode(errors.ServerInternalError, http.StatusInternalServerError)
 	}
 
-	task, err := metricmonitor.GetMgmt().NewTaskForPolicy(policy.ScalingGroup.Id, policy.Id,
-		*req.TargetConfiguration.MetricName, *req.TargetConfiguration.TargetValue)
+	var task *db.MetricMonitorTask
+	switch *req.Type {
+	case common.PolicyTypeScheduled:
+		task, err = metricmonitor.GetMgmt().NewTaskForScheduledPolicy(policy.ScalingGroup.Id, policy.Id)
+	case common.PolicyTypePredictive:
+		task, err = metricmonitor.GetMgmt().NewTaskForPredictivePolicy(policy.ScalingGroup.Id, policy.Id)
+	case common.PolicyTypeStep:
+		task, err = metricmonitor.GetMgmt().NewTaskForStepPolicy(policy.ScalingGroup.Id, policy.Id,
+			*req.StepConfiguration.MetricName, customMetricNameOf(req.StepConfiguration.CustomMetricName))
+	default:
+		task, err = metricmonitor.GetMgmt().NewTaskForPolicy(policy.ScalingGroup.Id, policy.Id,
+			*req.TargetConfiguration.MetricName, customMetricNameOf(req.TargetConfiguration.CustomMetricName),
+			*req.TargetConfiguration.TargetValue)
+	}
 	if err != nil {
 		log.Error("Creat metric monitor task for policy[%s] err: %+v", policy.Id, err)
 		return nil, errors.NewErrorRespWithHttpCode(errors.ServerInternalError, http.StatusInternalServerError)
@@ -87,12 +106,10 @@ func DeleteScalingPolicy(log *logger.FMLogger, projectId, policyId string) *erro
 		log.Error(errors.PolicyDeleteError.Msg())
 		return errors.NewErrorRespWithHttpCode(errors.PolicyDeleteError, http.StatusBadRequest)
 	}
-	if policy.PolicyType == common.PolicyTypeTargetBased {
-		taskId := metricmonitor.GetMgmt().TaskIdForPolicy(policy.Id)
-		if err = metricmonitor.GetMgmt().DeleteTask(taskId); err != nil {
-			log.Error("Delete metric monitor task for policy[%s] err: %+v", policy.Id, err)
-			return errors.NewErrorRespWithHttpCode(errors.ServerInternalError, http.StatusInternalServerError)
-		}
+	taskId := metricmonitor.GetMgmt().TaskIdForPolicy(policy.Id)
+	if err = metricmonitor.GetMgmt().DeleteTask(taskId); err != nil {
+		log.Error("Delete metric monitor task for policy[%s] err: %+v", policy.Id, err)
+		return errors.NewErrorRespWithHttpCode(errors.ServerInternalError, http.StatusInternalServerError)
 	}
 	if err = scalingJudgmentForDeleteScalingPolicy(log, group); err != nil {
 		if pkgerrors.Is(err, common.ErrScalingGroupNotStable) {
@@ -121,6 +138,24 @@ func UpdateScalingPolicy(log *logger.FMLogger, projectId, policyId string,
 		log.Error("Read scaling policy[%s] of project[%s] from db err: %+v", policyId, projectId, err)
 		return errors.NewErrorRespWithHttpCode(errors.ServerInternalError, http.StatusInternalServerError)
 	}
+	if req.TargetConfiguration != nil && !isValidTargetConfiguration(req.TargetConfiguration) {
+		log.Error(errors.TargetConfigurationError.Msg())
+		return errors.NewErrorRespWithHttpCode(errors.TargetConfigurationError, http.StatusBadRequest)
+	}
+	if policy.PolicyType == common.PolicyTypeTargetBased && req.TargetConfiguration != nil {
+		if errResp := checkTargetMetricUnique(log, policy.ScalingGroup.Id, policy.Id,
+			req.TargetConfiguration); errResp != nil {
+			return errResp
+		}
+	}
+	if req.ScheduledConfiguration != nil && !isValidScheduledConfiguration(req.ScheduledConfiguration) {
+		log.Error(errors.ScheduledConfigError.Msg())
+		return errors.NewErrorRespWithHttpCode(errors.ScheduledConfigError, http.StatusBadRequest)
+	}
+	if req.StepConfiguration != nil && !isValidStepConfiguration(req.StepConfiguration) {
+		log.Error(errors.StepConfigError.Msg())
+		return errors.NewErrorRespWithHttpCode(errors.StepConfigError, http.StatusBadRequest)
+	}
 	err = convertUpdateScalingPolicyReq(req, policy)
 	if err != nil {
 		log.Error("Convert UpdateScalingPolicyReq of policy[%s] err: %+v", policyId, err)
@@ -128,11 +163,29 @@ func UpdateScalingPolicy(log *logger.FMLogger, projectId, policyId string,
 	}
 	if policy.PolicyType == common.PolicyTypeTargetBased && req.TargetConfiguration != nil {
 		taskId := metricmonitor.GetMgmt().TaskIdForPolicy(policy.Id)
-		if err = metricmonitor.GetMgmt().UpdateTask(taskId, *req.TargetConfiguration.TargetValue); err != nil {
+		if err = metricmonitor.GetMgmt().UpdateTask(taskId, *req.TargetConfiguration.MetricName,
+			customMetricNameOf(req.TargetConfiguration.CustomMetricName),
+			*req.TargetConfiguration.TargetValue); err != nil {
 			log.Error("Update metric monitor task for policy[%s] err: %+v", policy.Id, err)
 			return errors.NewErrorRespWithHttpCode(errors.ServerInternalError, http.StatusInternalServerError)
 		}
 	}
+	if policy.PolicyType == common.PolicyTypeStep && req.StepConfiguration != nil {
+		taskId := metricmonitor.GetMgmt().TaskIdForPolicy(policy.Id)
+		if err = metricmonitor.GetMgmt().UpdateTask(taskId, *req.StepConfiguration.MetricName,
+			customMetricNameOf(req.StepConfiguration.CustomMetricName), 0); err != nil {
+			log.Error("Update metric monitor task for policy[%s] err: %+v", policy.Id, err)
+			return errors.NewErrorRespWithHttpCode(errors.ServerInternalError, http.StatusInternalServerError)
+		}
+	}
+	if (policy.PolicyType == common.PolicyTypeScheduled && req.ScheduledConfiguration != nil) ||
+		(policy.PolicyType == common.PolicyTypePredictive && req.PredictiveConfiguration != nil) {
+		taskId := metricmonitor.GetMgmt().TaskIdForPolicy(policy.Id)
+		if err = metricmonitor.GetMgmt().ResetTriggeredTask(taskId); err != nil {
+			log.Error("Reset metric monitor task for policy[%s] err: %+v", policy.Id, err)
+			return errors.NewErrorRespWithHttpCode(errors.ServerInternalError, http.StatusInternalServerError)
+		}
+	}
 	if err := db.UpdateScalingPolicyById(policyId, policy); err != nil {
 		log.Error("Update ScalingPolicy from db err: %+v", err)
 		return errors.NewErrorRespWithHttpCode(errors.ServerInternalError, http.StatusInternalServerError)
@@ -140,6 +193,190 @@ func UpdateScalingPolicy(log *logger.FMLogger, projectId, policyId string,
 	return nil
 }
 
+// GetScalingForecast 查询预测策略在[start, end)内每个时间段的预测, 已经过去的时间段附带实际需求:
+// 1. start默认为24小时前, end默认为预测时长之后;
+// 2. 查询范围不能超过预测的保留时长.
+func GetScalingForecast(log *logger.FMLogger, projectId, policyId string,
+	start, end *time.Time) (*model.ScalingForecast, *errors.ErrorResp) {
+	policy, err := db.GetScalingPolicyById(projectId, policyId)
+	if err != nil {
+		if pkgerrors.Is(err, orm.ErrNoRows) {
+			log.Error("The scaling policy[%s] of project[%s] is not found", policyId, projectId)
+			return nil, errors.NewErrorRespWithHttpCode(errors.ScalingPolicyNotFound, http.StatusNotFound)
+		}
+		log.Error("Read scaling policy[%s] of project[%s] from db err: %+v", policyId, projectId, err)
+		return nil, errors.NewErrorRespWithHttpCode(errors.ServerInternalError, http.StatusInternalServerError)
+	}
+	if policy.PolicyType != common.PolicyTypePredictive {
+		log.Error(errors.NotPredictivePolicy.Msg())
+		return nil, errors.NewErrorRespWithHttpCode(errors.NotPredictivePolicy, http.StatusBadRequest)
+	}
+	conf := &model.PredictiveConfiguration{}
+	if err = json.Unmarshal([]byte(policy.PolicyConfig), conf); err != nil {
+		log.Error("Unmarshal predictive configuration of policy[%s] err: %+v", policyId, err)
+		return nil, errors.NewErrorRespWithHttpCode(errors.ServerInternalError, http.StatusInternalServerError)
+	}
+
+	now := time.Now()
+	if start == nil {
+		defaultStart := now.Add(-defaultForecastQueryDuration)
+		start = &defaultStart
+	}
+	if end == nil {
+		defaultEnd := now.Add(time.Duration(*conf.ForecastMinutes) * time.Minute)
+		end = &defaultEnd
+	}
+	if !end.After(*start) || end.Sub(*start) > maxForecastQueryDuration {
+		log.Error("The forecast query range [%s, %s) is invalid", start, end)
+		return nil, errors.NewErrorRespWithHttpCode(errors.QueryParamTimeError, http.StatusBadRequest)
+	}
+	forecasts, err := db.ListScalingForecasts(policyId, start.Unix(), end.Unix())
+	if err != nil {
+		log.Error("List forecasts of policy[%s] err: %+v", policyId, err)
+		return nil, errors.NewErrorRespWithHttpCode(errors.ServerInternalError, http.StatusInternalServerError)
+	}
+
+	// 实际需求查询失败时只返回预测
+	var actual map[int64]float64
+	if start.Before(now) {
+		actualEnd := *end
+		if actualEnd.After(now) {
+			actualEnd = now
+		}
+		actual, err = metricmonitor.GetMgmt().DemandOfScalingGroup(log, policy.ScalingGroup, *start, actualEnd)
+		if err != nil {
+			log.Error("Query actual demand of group[%s] err: %+v", policy.ScalingGroup.Id, err)
+		}
+	}
+	resp := &model.ScalingForecast{
+		ScalingPolicyId: policyId,
+		Forecasts:       make([]*model.ForecastPoint, 0, len(forecasts)),
+	}
+	for _, f := range forecasts {
+		t := time.Unix(f.ForecastTime, 0)
+		point := &model.ForecastPoint{
+			Time:                   t.UTC().Format(common.TimeLayout),
+			ForecastDemand:         f.Demand,
+			ForecastInstanceNumber: f.InstanceNumber,
+		}
+		if demand, ok := actual[t.UnixNano()]; ok {
+			point.ActualDemand = &demand
+		}
+		resp.Forecasts = append(resp.Forecasts, point)
+	}
+	return resp, nil
+}
+
+// checkPolicyConfiguration 每个伸缩组只能有一个预测策略, 步进策略不能与其他基于目标或步进策略共存,
+// 基于目标策略和定时策略可以有多个, 基于目标策略的指标不能重复
+func checkPolicyConfiguration(log *logger.FMLogger, projectId string,
+	req model.CreateScalingPolicyReq) *errors.ErrorResp {
+	if *req.Type == common.PolicyTypePredictive {
+		if db.IsPredictivePolicyExistInScalingGroup(projectId, *req.InstanceScalingGroupID) {
+			log.Error(errors.PredictivePolicyExist.Msg())
+			return errors.NewErrorRespWithHttpCode(errors.PredictivePolicyExist, http.StatusBadRequest)
+		}
+		return nil
+	}
+	if *req.Type == common.PolicyTypeScheduled {
+		if !isValidScheduledConfiguration(req.ScheduledConfiguration) {
+			log.Error(errors.ScheduledConfigError.Msg())
+			return errors.NewErrorRespWithHttpCode(errors.ScheduledConfigError, http.StatusBadRequest)
+		}
+		return nil
+	}
+	if *req.Type == common.PolicyTypeStep {
+		if db.IsMetricBasedPolicyExistInScalingGroup(projectId, *req.InstanceScalingGroupID) {
+			log.Error(errors.TargetBasedPolicyExist.Msg())
+			return errors.NewErrorRespWithHttpCode(errors.TargetBasedPolicyExist, http.StatusBadRequest)
+		}
+		if !isValidStepConfiguration(req.StepConfiguration) {
+			log.Error(errors.StepConfigError.Msg())
+			return errors.NewErrorRespWithHttpCode(errors.StepConfigError, http.StatusBadRequest)
+		}
+		return nil
+	}
+	if db.IsStepPolicyExistInScalingGroup(projectId, *req.InstanceScalingGroupID) {
+		log.Error(errors.TargetBasedPolicyExist.Msg())
+		return errors.NewErrorRespWithHttpCode(errors.TargetBasedPolicyExist, http.StatusBadRequest)
+	}
+	if !isValidTargetConfiguration(req.TargetConfiguration) {
+		log.Error(errors.TargetConfigurationError.Msg())
+		return errors.NewErrorRespWithHttpCode(errors.TargetConfigurationError, http.StatusBadRequest)
+	}
+	return checkTargetMetricUnique(log, *req.InstanceScalingGroupID, "", req.TargetConfiguration)
+}
+
+// checkTargetMetricUnique 伸缩组内基于目标策略的指标不能重复, 更新策略时排除策略自身
+func checkTargetMetricUnique(log *logger.FMLogger, groupId, policyId string,
+	conf *model.TargetConfiguration) *errors.ErrorResp {
+	tasks, err := db.ListTargetBasedMetricMonitorTasksOfGroup(groupId)
+	if err != nil {
+		log.Error("List target based tasks of scaling group[%s] err: %+v", groupId, err)
+		return errors.NewErrorRespWithHttpCode(errors.ServerInternalError, http.StatusInternalServerError)
+	}
+	key := metric.TargetMetricKey(*conf.MetricName, customMetricNameOf(conf.CustomMetricName))
+	for _, task := range tasks {
+		if task.ScalingPolicyID != policyId && metric.TargetMetricKey(task.MetricName, task.CustomMetricName) == key {
+			log.Error(errors.TargetMetricExist.Msg())
+			return errors.NewErrorRespWithHttpCode(errors.TargetMetricExist, http.StatusBadRequest)
+		}
+	}
+	return nil
+}
+
+// isValidStepConfiguration CUSTOM_METRIC必须指定自定义指标名称, 区间按边界排列且互不重叠
+func isValidStepConfiguration(conf *model.StepConfiguration) bool {
+	if (*conf.MetricName == common.MetricNameCustomMetric) != (conf.CustomMetricName != nil) {
+		return false
+	}
+	return metric.IsValidSteps(conf.Steps)
+}
+
+// isValidScheduledConfiguration 时间窗可以解析, 且至少指定一个实例数, 指定的实例数满足min ≤ desire ≤ max
+func isValidScheduledConfiguration(conf *model.ScheduledConfiguration) bool {
+	if _, err := metricmonitor.ParseScheduleWindow(conf); err != nil {
+		return false
+	}
+	if conf.MinInstanceNumber == nil && conf.MaxInstanceNumber == nil && conf.DesireInstanceNumber == nil {
+		return false
+	}
+	nums := []*int32{conf.MinInstanceNumber, conf.DesireInstanceNumber, conf.MaxInstanceNumber}
+	var last *int32
+	for _, num := range nums {
+		if num == nil {
+			continue
+		}
+		if last != nil && *num < *last {
+			return false
+		}
+		last = num
+	}
+	return true
+}
+
+// isValidTargetConfiguration CUSTOM_METRIC必须指定自定义指标名称, 百分比指标的目标值不能超过100,
+// 可用进程比为100%时无法按已满进程的占比计算实例数, 目标值必须小于100
+func isValidTargetConfiguration(conf *model.TargetConfiguration) bool {
+	switch *conf.MetricName {
+	case common.MetricNameCustomMetric:
+		return conf.CustomMetricName != nil
+	case common.MetricNameQueuedServerSessions:
+		return conf.CustomMetricName == nil
+	case common.MetricNamePercentAvailableProcesses:
+		return conf.CustomMetricName == nil && *conf.TargetValue < common.MaxTargetValueOfPercentMetric
+	default:
+		return conf.CustomMetricName == nil && *conf.TargetValue <= common.MaxTargetValueOfPercentMetric
+	}
+}
+
+func customMetricNameOf(name *string) string {
+	if name == nil {
+		return ""
+	}
+	return *name
+}
+
 // scalingJudgmentForDeleteScalingPolicy 删除策略后，判断是否触发弹性伸缩：
 // 1. 伸缩组的EnableAutoScaling为false, 伸缩组仍按DesireInstanceNumber进行弹性伸缩，不触发弹性伸缩；
 // 2. 伸缩组的EnableAutoScaling为true 且 伸缩组存在其他伸缩策略，伸缩组仍按伸缩策略进行弹性伸缩，不触发弹性伸缩；
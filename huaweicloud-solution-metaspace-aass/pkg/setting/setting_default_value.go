@@ -9,10 +9,16 @@ const (
 	defaultMonitorDuration     = "30s"
 	defaultEnterpriseProjectId = "0"
 
+	defaultDecisionLogRetentionDays = 7
+
 	defaultInstanceMaximumLimitPreGroup = 200
 	defaultSupportedVolumeTypes         = "SATA;SAS;SSD;GPSSD"
 	defaultBandwidthChargingMode        = "traffic"
 	defaultBandwidthMaximumLimit        = 300
+	defaultAuxproxyPort                 = 60001
+	defaultInstanceDrainTimeoutMinutes  = 70
+	defaultAuxproxyCaFile               = "./conf/security/auxproxy_ca.crt"
+	defaultAuxproxyServerName           = "auxproxy"
 
 	defaultTakeOverTaskIntervalSeconds  = 60
 	defaultHeartBeatTaskIntervalSeconds = 300
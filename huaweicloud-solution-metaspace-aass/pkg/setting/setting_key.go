@@ -84,6 +84,8 @@ const (
 	monitorDuration     = "default_configuration.monitor_duration"
 	enterpriseProjectId = "default_configuration.enterprise_project_id"
 
+	decisionLogRetentionDays = "default_configuration.decision_log_retention_days"
+
 	takeOverTaskIntervalSeconds  = "default_configuration.work_node.take_over_task_interval_seconds"
 	heartBeatTaskIntervalSeconds = "default_configuration.work_node.heart_beat_task_interval_seconds"
 	deadCheckTaskIntervalSeconds = "default_configuration.work_node.dead_check_task_interval_seconds"
@@ -93,6 +95,10 @@ const (
 	supportedVolumeTypes         = "default_configuration.scaling_group.supported_volume_types"
 	bandwidthChargingMode        = "default_configuration.scaling_group.bandwidth_charging_mode"
 	bandwidthMaximumLimit        = "default_configuration.scaling_group.bandwidth_maximum_limit"
+	auxproxyPort                 = "default_configuration.scaling_group.auxproxy_port"
+	instanceDrainTimeoutMinutes  = "default_configuration.scaling_group.instance_drain_timeout_minutes"
+	auxproxyCaFile               = "default_configuration.scaling_group.auxproxy_ca_file"
+	auxproxyServerName           = "default_configuration.scaling_group.auxproxy_server_name"
 )
 
 // GetWebHttpPort get web http port
@@ -160,3 +166,28 @@ func GetBandwidthChargingMode() string {
 func GetBandwidthMaximumLimit() int {
 	return Config.Get(bandwidthMaximumLimit).ToInt(defaultBandwidthMaximumLimit)
 }
+
+// GetAuxproxyPort get https port of auxproxy running in instances from service config
+func GetAuxproxyPort() int {
+	return Config.Get(auxproxyPort).ToInt(defaultAuxproxyPort)
+}
+
+// GetInstanceDrainTimeoutMinutes get max minutes to wait for instance draining before scale in
+func GetInstanceDrainTimeoutMinutes() int {
+	return Config.Get(instanceDrainTimeoutMinutes).ToInt(defaultInstanceDrainTimeoutMinutes)
+}
+
+// GetAuxproxyCaFile get ca file to verify certificate of auxproxy running in instances
+func GetAuxproxyCaFile() string {
+	return Config.Get(auxproxyCaFile).ToString(defaultAuxproxyCaFile)
+}
+
+// GetAuxproxyServerName get server name in certificate of auxproxy, empty means verifying the instance ip
+func GetAuxproxyServerName() string {
+	return Config.Get(auxproxyServerName).ToString(defaultAuxproxyServerName)
+}
+
+// GetDecisionLogRetentionDays get retention days of auto scaling decision logs
+func GetDecisionLogRetentionDays() int {
+	return Config.Get(decisionLogRetentionDays).ToInt(defaultDecisionLogRetentionDays)
+}
@@ -111,11 +111,9 @@ func (t *DelScalingGroupTask) delPolicyAndMonitorTask(log *logger.FMLogger, poli
 
 	ids := make([]string, 0, len(policies))
 	for _, policy := range policies {
-		if policy.PolicyType == common.PolicyTypeTargetBased {
-			taskId := t.monitor.TaskIdForPolicy(policy.Id)
-			if err := t.monitor.DeleteTask(taskId); err != nil {
-				return err
-			}
+		taskId := t.monitor.TaskIdForPolicy(policy.Id)
+		if err := t.monitor.DeleteTask(taskId); err != nil {
+			return err
 		}
 		if err := db.DeleteScalingPolicy(policy.ProjectId, policy.Id); err != nil {
 			return err
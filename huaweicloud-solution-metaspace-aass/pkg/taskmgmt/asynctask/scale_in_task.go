@@ -57,7 +57,12 @@ func (t *ScaleInTask) Run(log *logger.FMLogger) error {
 	asGroupId := vmGroup.AsGroupId
 	projectId := group.ProjectId
 
-	// 2. 缩容as伸缩组，从as伸缩组中移除实例，并将实例关机
+	// 2. 通知实例内的auxproxy排空应用进程，等待服务器会话结束；超时仍有服务器会话时不移除实例，任务稍后重试
+	if err = cloudhelper.DrainInstances(log, projectId, t.ScaleInInstanceIds); err != nil {
+		return err
+	}
+
+	// 3. 缩容as伸缩组，从as伸缩组中移除实例，并将实例关机
 	err = cloudhelper.ScaleInAsScalingGroupByInstances(log, asGroupId, projectId, t.ScaleInInstanceIds)
 	if err != nil {
 		return err
@@ -72,12 +77,12 @@ func (t *ScaleInTask) Run(log *logger.FMLogger) error {
 		return err
 	}
 
-	// 3. 关闭所有虚机
+	// 4. 关闭所有虚机
 	if err = resCtrl.BatchStopServers(log, t.ScaleInInstanceIds); err != nil {
 		return err
 	}
 
-	// 4.DB记录vm删除任务、启动vm删除异步任务
+	// 5.DB记录vm删除任务、启动vm删除异步任务
 	for _, instanceId := range t.ScaleInInstanceIds {
 		err = db.InsertDeleteVmAsyncTask(instanceId, asGroupId, projectId)
 		if err != nil {
@@ -98,6 +103,6 @@ func (t *ScaleInTask) Run(log *logger.FMLogger) error {
 		taskmgmt.GetTaskMgmt().AddTask(NewDelVmTask(instanceId, projectId))
 	}
 
-	// 5. db记录伸缩组缩容结束
+	// 6. db记录伸缩组缩容结束
 	return db.TxRecordGroupScaleInComplete(t.GroupId)
 }
@@ -19,6 +19,8 @@ var (
 
 const (
 	LocalKeyAGW = "agw"
+	// LocalKeyAuxProxy 访问实例内auxproxy时使用的秘钥
+	LocalKeyAuxProxy = "auxproxy"
 )
 
 // InitHMACKey 初始化 hmac 秘钥存储器
@@ -61,15 +63,18 @@ func newHmacLocalKey() (LocalSker, error) {
 	localKeyEntrys := setting.ClientHmacConf.Keys
 
 	// eg: 通过如下方式，加入访问远端服务的 hmac秘钥 信息
-	agwKey := localKeyEntrys[LocalKeyAGW]
-	if agwKey.Enable {
-		sk, err := security.GCM_Decrypt(string(agwKey.SKCypher), setting.GCMKey, setting.GCMNonce)
+	for _, receiver := range []string{LocalKeyAGW, LocalKeyAuxProxy} {
+		key := localKeyEntrys[receiver]
+		if !key.Enable {
+			continue
+		}
+		sk, err := security.GCM_Decrypt(string(key.SKCypher), setting.GCMKey, setting.GCMNonce)
 		if err != nil {
 			return nil, errors.New("decrypt err")
 		}
-		localSker.Add(LocalKeyAGW, LocalKeyEntry{
-			Enable: agwKey.Enable,
-			AK:     agwKey.AK,
+		localSker.Add(receiver, LocalKeyEntry{
+			Enable: key.Enable,
+			AK:     key.AK,
 			SK:     []byte(sk),
 		})
 	}
@@ -4,26 +4,29 @@
 package apis
 
 type AppProcess struct {
-	ID                                      string `json:"app_process_id"`
-	PID                                     int    `json:"pid"`
-	BizPID                                  int    `json:"biz_pid"`
-	InstanceID                              string `json:"instance_id"`
-	ScalingGroupID                          string `json:"scaling_group_id"`
-	FleetID                                 string `json:"fleet_id"`
-	PublicIP                                string `json:"ip_address"`
-	PrivateIP                               string `json:"private_ip"`
-	ClientPort                              int    `json:"port"`
-	GrpcPort                                int    `json:"grpc_port"`
-	AuxProxyPort                            int    `json:"aux_proxy_port"`
-	LogPath                                 string `json:"log_path"`
-	State                                   string `json:"state"`
-	ServerSessionCount                      int    `json:"server_session_count"`
-	MaxServerSessionNum                     int    `json:"max_server_session_num"`
-	NewServerSessionProtectionPolicy        string `json:"new_server_session_protection_policy"`
-	ServerSessionProtectionTimeLimitMinutes int    `json:"server_session_protection_time_limit_minutes"`
-	ServerSessionActivationTimeoutSeconds   int    `json:"server_session_activation_timeout_seconds"`
-	LaunchPath                              string `json:"launch_path"`
-	Parameters                              string `json:"parameters"`
+	ID                                      string            `json:"app_process_id"`
+	PID                                     int               `json:"pid"`
+	BizPID                                  int               `json:"biz_pid"`
+	InstanceID                              string            `json:"instance_id"`
+	AvailabilityZone                        string            `json:"availability_zone,omitempty"`
+	ScalingGroupID                          string            `json:"scaling_group_id"`
+	FleetID                                 string            `json:"fleet_id"`
+	PublicIP                                string            `json:"ip_address"`
+	PrivateIP                               string            `json:"private_ip"`
+	ClientPort                              int               `json:"port"`
+	GrpcPort                                int               `json:"grpc_port"`
+	AuxProxyPort                            int               `json:"aux_proxy_port"`
+	LogPath                                 string            `json:"log_path"`
+	State                                   string            `json:"state"`
+	StateReason                             string            `json:"state_reason,omitempty"`
+	ServerSessionCount                      int               `json:"server_session_count"`
+	MaxServerSessionNum                     int               `json:"max_server_session_num"`
+	NewServerSessionProtectionPolicy        string            `json:"new_server_session_protection_policy"`
+	ServerSessionProtectionTimeLimitMinutes int               `json:"server_session_protection_time_limit_minutes"`
+	ServerSessionActivationTimeoutSeconds   int               `json:"server_session_activation_timeout_seconds"`
+	LaunchPath                              string            `json:"launch_path"`
+	Parameters                              string            `json:"parameters"`
+	Labels                                  map[string]string `json:"labels,omitempty"`
 }
 
 type AppProcessList struct {
@@ -33,20 +36,22 @@ type AppProcessList struct {
 // Register app process regarding apis
 
 type RegisterAppProcessRequest struct {
-	PID                                     int    `json:"pid" validate:"required,gte=1,lte=32768"`
-	BizPID                                  int    `json:"biz_pid" validate:"required,gte=1,lte=32768"`
-	InstanceID                              string `json:"instance_id" validate:"required,min=1,max=128"`
-	ScalingGroupID                          string `json:"scaling_group_id" validate:"required,min=1,max=128"`
-	FleetID                                 string `json:"fleet_id" validate:"required,min=1,max=128"`
-	PublicIP                                string `json:"ip_address" validate:"required,ip4_addr"`
-	PrivateIP                               string `json:"private_ip" validate:"required,ip4_addr"`
-	AuxProxyPort                            int    `json:"aux_proxy_port" validate:"required,gte=1,lte=65535"`
-	MaxServerSessionNum                     int    `json:"max_server_session_num" validate:"required,gte=1,lte=50"`
-	NewServerSessionProtectionPolicy        string `json:"new_server_session_protection_policy" validate:"required,oneof=NO_PROTECTION FULL_PROTECTION TIME_LIMIT_PROTECTION"`
-	ServerSessionProtectionTimeLimitMinutes int    `json:"server_session_protection_time_limit_minutes" validate:"required,gte=5,lte=1440"`
-	ServerSessionActivationTimeoutSeconds   int    `json:"server_session_activation_timeout_seconds" validate:"required,gte=1,lte=600"`
-	LaunchPath                              string `json:"launch_path" validate:"required,min=1,max=262144"`
-	Parameters                              string `json:"parameters" validate:"omitempty,min=0,max=262144"`
+	PID                                     int               `json:"pid" validate:"required,gte=1,lte=32768"`
+	BizPID                                  int               `json:"biz_pid" validate:"required,gte=1,lte=32768"`
+	InstanceID                              string            `json:"instance_id" validate:"required,min=1,max=128"`
+	AvailabilityZone                        string            `json:"availability_zone,omitempty" validate:"omitempty,max=64"`
+	ScalingGroupID                          string            `json:"scaling_group_id" validate:"required,min=1,max=128"`
+	FleetID                                 string            `json:"fleet_id" validate:"required,min=1,max=128"`
+	PublicIP                                string            `json:"ip_address" validate:"required,ip4_addr"`
+	PrivateIP                               string            `json:"private_ip" validate:"required,ip4_addr"`
+	AuxProxyPort                            int               `json:"aux_proxy_port" validate:"required,gte=1,lte=65535"`
+	MaxServerSessionNum                     int               `json:"max_server_session_num" validate:"required,gte=1,lte=50"`
+	NewServerSessionProtectionPolicy        string            `json:"new_server_session_protection_policy" validate:"required,oneof=NO_PROTECTION FULL_PROTECTION TIME_LIMIT_PROTECTION"`
+	ServerSessionProtectionTimeLimitMinutes int               `json:"server_session_protection_time_limit_minutes" validate:"required,gte=5,lte=1440"`
+	ServerSessionActivationTimeoutSeconds   int               `json:"server_session_activation_timeout_seconds" validate:"required,gte=1,lte=600"`
+	LaunchPath                              string            `json:"launch_path" validate:"required,min=1,max=262144"`
+	Parameters                              string            `json:"parameters" validate:"omitempty,min=0,max=262144"`
+	Labels                                  map[string]string `json:"labels,omitempty" validate:"omitempty,max=16,dive,keys,min=1,max=64,endkeys,max=256"`
 }
 
 type RegisterAppProcessResponse struct {
@@ -59,7 +64,7 @@ type UpdateAppProcessRequest struct {
 	ClientPort int      `json:"port" validate:"required,gte=1,lte=65535"`
 	GrpcPort   int      `json:"grpc_port" validate:"required,gte=1,lte=65535"`
 	LogPath    []string `json:"log_path" validate:"omitempty,gte=0,lte=100"`
-	State      string   `json:"state" validate:"required,oneof=AVTIVATING ACTIVE TERMINATING TERMINATED ERROR"`
+	State      string   `json:"state" validate:"required,oneof=AVTIVATING ACTIVE TERMINATING TERMINATED ERROR CRASH_LOOP"`
 }
 
 type UpdateAppProcessResponse struct {
@@ -69,7 +74,8 @@ type UpdateAppProcessResponse struct {
 // Update app process state
 
 type UpdateAppProcessStateRequest struct {
-	State string `json:"state" validate:"oneof=AVTIVATING ACTIVE TERMINATING TERMINATED ERROR"`
+	State       string `json:"state" validate:"oneof=AVTIVATING ACTIVE TERMINATING TERMINATED ERROR CRASH_LOOP"`
+	StateReason string `json:"state_reason,omitempty" validate:"omitempty,max=255"`
 }
 
 type UpdateAppProcessStateResponse struct {
@@ -91,6 +97,17 @@ type ListAppProcessesResponse struct {
 	AppProcesses []AppProcess `json:"app_processes"`
 }
 
+// ReportAppProcessMetricsRequest 上报进程自定义指标的请求
+type ReportAppProcessMetricsRequest struct {
+	Metrics []AppProcessMetrics `json:"metrics" validate:"required,min=1,max=1000,dive"`
+}
+
+// AppProcessMetrics 进程在一个上报周期内的自定义指标, key为指标名称
+type AppProcessMetrics struct {
+	ProcessID string             `json:"process_id" validate:"required,max=64"`
+	Values    map[string]float64 `json:"values" validate:"required,min=1,max=100"`
+}
+
 type ProcessCount struct {
 	State string `json:"state"`
 	Count int    `json:"count"`
@@ -0,0 +1,51 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 实例应用包原地更新结构体定义
+package apis
+
+import "time"
+
+// UpdateInstanceBuildRequest 实例原地更新应用包请求
+type UpdateInstanceBuildRequest struct {
+	FleetID string `json:"fleet_id" validate:"required"`
+	BuildID string `json:"build_id" validate:"required"`
+	// SourceType 应用包来源, 为空时为OBS; S3和HTTPS来源从Url下载, LOCAL来源从实例上的Path复制
+	SourceType              string `json:"source_type" validate:"omitempty,oneof=OBS S3 HTTPS LOCAL"`
+	Bucket                  string `json:"bucket" validate:"required_without_all=Url Path"`
+	Object                  string `json:"object" validate:"required_without_all=Url Path"`
+	Url                     string `json:"url" validate:"omitempty,url"`
+	Path                    string `json:"path" validate:"omitempty,startswith=/"`
+	Region                  string `json:"region" validate:"required"`
+	Checksum                string `json:"checksum" validate:"required_if=SourceType HTTPS,omitempty,len=64,hexadecimal"`
+	ReadinessTimeoutSeconds int    `json:"readiness_timeout_seconds" validate:"gte=0,lte=3600"`
+}
+
+// AuxProxyBuildUpdateRequest 发送给auxproxy的原地更新请求
+type AuxProxyBuildUpdateRequest struct {
+	BuildID                 string `json:"build_id"`
+	SourceType              string `json:"source_type,omitempty"`
+	Bucket                  string `json:"bucket"`
+	Object                  string `json:"object"`
+	Url                     string `json:"url,omitempty"`
+	Path                    string `json:"path,omitempty"`
+	Region                  string `json:"region"`
+	Checksum                string `json:"checksum,omitempty"`
+	ReadinessTimeoutSeconds int    `json:"readiness_timeout_seconds"`
+}
+
+// BuildUpdateStatus auxproxy返回的原地更新进度
+type BuildUpdateStatus struct {
+	BuildID          string    `json:"build_id"`
+	State            string    `json:"state"`
+	Message          string    `json:"message,omitempty"`
+	TotalProcesses   int       `json:"total_processes"`
+	UpdatedProcesses int       `json:"updated_processes"`
+	StartedAt        time.Time `json:"started_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// InstanceBuildUpdateResponse 实例原地更新进度
+type InstanceBuildUpdateResponse struct {
+	InstanceID string `json:"instance_id"`
+	BuildUpdateStatus
+}
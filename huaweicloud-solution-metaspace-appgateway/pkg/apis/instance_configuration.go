@@ -13,12 +13,28 @@ type RuntimeConfiguration struct {
 	ServerSessionActivationTimeoutSeconds int                    `json:"server_session_activation_timeout_seconds"`
 	ProcessConfiguration                  []ProcessConfiguration `json:"process_configurations"`
 	MaxConcurrentServerSessionsPerProcess int                    `json:"max_concurrent_server_sessions_per_process"`
+	PlacementPolicy                       *PlacementPolicy       `json:"placement_policy,omitempty"`
+}
+
+// PlacementPolicy 服务端会话在fleet内的进程放置策略
+type PlacementPolicy struct {
+	Strategy                   string   `json:"strategy"`
+	PreferredInstanceIds       []string `json:"preferred_instance_ids,omitempty"`
+	PreferredAvailabilityZones []string `json:"preferred_availability_zones,omitempty"`
 }
 
 type ProcessConfiguration struct {
-	LaunchPath           string `json:"launch_path"`
-	Parameters           string `json:"parameters"`
-	ConcurrentExecutions int    `json:"concurrent_executions"`
+	LaunchPath           string          `json:"launch_path"`
+	Parameters           string          `json:"parameters"`
+	ConcurrentExecutions int             `json:"concurrent_executions"`
+	ResourceLimits       *ResourceLimits `json:"resource_limits,omitempty"`
+}
+
+// ResourceLimits 单个应用进程的资源限制, 由auxproxy在Linux上通过cgroup v2和rlimit实施
+type ResourceLimits struct {
+	CpuQuotaMillicores int `json:"cpu_quota_millicores,omitempty"`
+	MemoryMaxMB        int `json:"memory_max_mb,omitempty"`
+	MaxOpenFiles       int `json:"max_open_files,omitempty"`
 }
 
 // Show runtime configuration regarding apis
@@ -0,0 +1,12 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 实例资源指标结构体定义
+package apis
+
+// ReportInstanceMetricsRequest auxproxy上报实例在一个上报周期内的CPU和内存使用率, 单位为百分比
+type ReportInstanceMetricsRequest struct {
+	FleetID           string  `json:"fleet_id" validate:"required,max=64"`
+	ScalingGroupID    string  `json:"scaling_group_id" validate:"required,max=64"`
+	CpuUtilization    float64 `json:"cpu_utilization" validate:"gte=0,lte=100"`
+	MemoryUtilization float64 `json:"memory_utilization" validate:"gte=0,lte=100"`
+}
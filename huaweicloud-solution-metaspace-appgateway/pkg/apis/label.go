@@ -0,0 +1,56 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 进程标签及服务端会话标签选择器定义
+package apis
+
+import (
+	"encoding/json"
+
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/utils/log"
+)
+
+const (
+	LabelSelectorOpIn           = "In"
+	LabelSelectorOpNotIn        = "NotIn"
+	LabelSelectorOpExists       = "Exists"
+	LabelSelectorOpDoesNotExist = "DoesNotExist"
+)
+
+// LabelSelector 服务端会话的放置约束, 只有满足全部条件的进程才能承载该会话
+type LabelSelector struct {
+	MatchLabels      map[string]string          `json:"match_labels,omitempty" validate:"omitempty,max=16,dive,keys,min=1,max=64,endkeys,max=256"`
+	MatchExpressions []LabelSelectorRequirement `json:"match_expressions,omitempty" validate:"omitempty,max=16,dive"`
+}
+
+// LabelSelectorRequirement 标签选择条件, In及NotIn需要填写values
+type LabelSelectorRequirement struct {
+	Key      string   `json:"key" validate:"required,min=1,max=64"`
+	Operator string   `json:"operator" validate:"required,oneof=In NotIn Exists DoesNotExist"`
+	Values   []string `json:"values,omitempty" validate:"required_if=Operator In,required_if=Operator NotIn,max=16,dive,max=256"`
+}
+
+// ParseLabels 解析数据库中保存的进程标签
+func ParseLabels(labels string) map[string]string {
+	if labels == "" {
+		return nil
+	}
+	var m map[string]string
+	if err := json.Unmarshal([]byte(labels), &m); err != nil {
+		log.RunLogger.Errorf("[label] failed to unmarshal labels %s for %v", labels, err)
+		return nil
+	}
+	return m
+}
+
+// ParseLabelSelector 解析数据库中保存的标签选择器, 未设置时返回nil
+func ParseLabelSelector(selector string) *LabelSelector {
+	if selector == "" {
+		return nil
+	}
+	s := &LabelSelector{}
+	if err := json.Unmarshal([]byte(selector), s); err != nil {
+		log.RunLogger.Errorf("[label] failed to unmarshal label selector %s for %v", selector, err)
+		return nil
+	}
+	return s
+}
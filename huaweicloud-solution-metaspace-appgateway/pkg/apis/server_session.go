@@ -17,25 +17,27 @@ type KV struct {
 }
 
 type ServerSession struct {
-	ID                          string `json:"server_session_id"`
-	Name                        string `json:"name"`
-	CreatorID                   string `json:"creator_id"`
-	ProcessID                   string `json:"process_id"`
-	InstanceID                  string `json:"instance_id"`
-	FleetID                     string `json:"fleet_id"`
-	PID                         int    `json:"pid"`
-	State                       string `json:"state"`
-	StateReason                 string `json:"state_reason"`
-	SessionData                 string `json:"server_session_data"`
-	SessionProperties           []KV   `json:"server_session_properties"`
-	ClientSessionCount          int    `json:"client_session_count"`
-	PublicIP                    string `json:"ip_address"`
-	ClientPort                  int    `json:"port"`
-	MaxClientSessionNum         int    `json:"max_client_session_num"`
-	ClientSessionCreationPolicy string `json:"client_session_creation_policy"`
-	ProtectionPolicy            string `json:"server_session_protection_policy"`
-	ProtectionTimeLimitMinutes  int    `json:"server_session_protection_time_limit_minutes"`
-	ActivationTimeoutSeconds    int    `json:"server_session_activation_timeout_seconds"`
+	ID                          string         `json:"server_session_id"`
+	Name                        string         `json:"name"`
+	CreatorID                   string         `json:"creator_id"`
+	ProcessID                   string         `json:"process_id"`
+	InstanceID                  string         `json:"instance_id"`
+	FleetID                     string         `json:"fleet_id"`
+	PID                         int            `json:"pid"`
+	State                       string         `json:"state"`
+	StateReason                 string         `json:"state_reason"`
+	SessionData                 string         `json:"server_session_data"`
+	SessionProperties           []KV           `json:"server_session_properties"`
+	ClientSessionCount          int            `json:"client_session_count"`
+	PublicIP                    string         `json:"ip_address"`
+	ClientPort                  int            `json:"port"`
+	MaxClientSessionNum         int            `json:"max_client_session_num"`
+	ClientSessionCreationPolicy string         `json:"client_session_creation_policy"`
+	ProtectionPolicy            string         `json:"server_session_protection_policy"`
+	ProtectionTimeLimitMinutes  int            `json:"server_session_protection_time_limit_minutes"`
+	ActivationTimeoutSeconds    int            `json:"server_session_activation_timeout_seconds"`
+	PlacementTimeoutSeconds     int            `json:"placement_timeout_seconds"`
+	LabelSelector               *LabelSelector `json:"label_selector,omitempty"`
 }
 
 type ServerSessionList struct {
@@ -50,6 +52,10 @@ type CreateServerSessionRequest struct {
 	SessionProperties []KV   `json:"server_session_properties" validate:"omitempty,min=0,max=16"`
 	// 为了区分传入零值和没传值的情况，使用指针类型
 	MaxClientSessionNum *int `json:"max_client_session_num" validate:"required,gte=1,lte=1024"`
+	// 只有满足标签选择器的进程才能承载该会话
+	LabelSelector *LabelSelector `json:"label_selector,omitempty" validate:"omitempty"`
+	// 没有可用进程时排队等待的最长时间, 为0时不排队直接失败
+	PlacementTimeoutSeconds int `json:"placement_timeout_seconds" validate:"omitempty,gte=0,lte=600"`
 }
 
 type CreateServerSessionResponse struct {
@@ -154,5 +160,7 @@ func TransferSSFromModel2Api(ss *server_session.ServerSession) *ServerSession {
 		ProtectionPolicy:            ss.ProtectionPolicy,
 		ActivationTimeoutSeconds:    ss.ActivationTimeoutSeconds,
 		ProtectionTimeLimitMinutes:  ss.ProtectionTimeLimitMinutes,
+		PlacementTimeoutSeconds:     ss.PlacementTimeoutSeconds,
+		LabelSelector:               ParseLabelSelector(ss.LabelSelector),
 	}
 }
@@ -9,6 +9,7 @@ const (
 	AppProcessStateTerminating = "TERMINATING"
 	AppProcessStateTerminated  = "TERMINATED"
 	AppProcessStateError       = "ERROR"
+	AppProcessStateCrashLoop   = "CRASH_LOOP"
 )
 
 const AppProcessIDPrefix = "app-process-"
@@ -75,7 +75,8 @@ func CheckState(ctx *context.Context) (string, error) {
 	if state == "" || state == ServerSessionStateError ||
 		state == ServerSessionStateActive ||
 		state == ServerSessionStateActivating ||
-		state == ServerSessionStateTerminated {
+		state == ServerSessionStateTerminated ||
+		state == ServerSessionStateQueued {
 		return state, nil
 	}
 	return "", fmt.Errorf("invalid state, please check")
@@ -99,6 +100,7 @@ func CheckStateForAppProcess(ctx *context.Context) (string, error) {
 		state != AppProcessStateError &&
 		state != AppProcessStateTerminated &&
 		state != AppProcessStateTerminating &&
+		state != AppProcessStateCrashLoop &&
 		state != "" {
 		return "", fmt.Errorf("invalid state, please check")
 	}
@@ -112,7 +114,8 @@ func CheckStateforServerSession(ctx *context.Context) (string, error) {
 	} else if state != ServerSessionStateActive &&
 		state != ServerSessionStateTerminated &&
 		state != ServerSessionStateActivating &&
-		state != ServerSessionStateError {
+		state != ServerSessionStateError &&
+		state != ServerSessionStateQueued {
 		return "", fmt.Errorf("invalid state, please check")
 	}
 	if state == ServerSessionStateActivating {
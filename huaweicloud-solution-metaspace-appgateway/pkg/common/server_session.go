@@ -9,6 +9,8 @@ const (
 	ServerSessionStateTerminated = "TERMINATED"
 	ServerSessionStateError      = "ERROR"
 	ServerSessionStateCreating   = "CREATING"
+	// ServerSessionStateQueued 暂无可用进程, 在放置超时时间内排队等待扩容
+	ServerSessionStateQueued = "QUEUED"
 )
 
 const (
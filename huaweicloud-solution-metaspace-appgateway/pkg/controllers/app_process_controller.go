@@ -124,6 +124,37 @@ func (a *AppProcessControllerImpl) UpdateAppProcessState() {
 	Response(a.Ctx, http.StatusNoContent, nil)
 }
 
+// ReportAppProcessMetrics report custom metrics of app processes
+func (a *AppProcessControllerImpl) ReportAppProcessMetrics() {
+	tLogger := log.GetTraceLogger(a.Ctx)
+
+	var reportMetricsRequest apis.ReportAppProcessMetricsRequest
+	err := json.Unmarshal(a.Ctx.Input.RequestBody, &reportMetricsRequest)
+	if err != nil {
+		tLogger.Errorf("[app process controller] failed to unmarshal report app process metrics request body for %v", err)
+		Response(a.Ctx, http.StatusBadRequest, errors.NewReportAppProcessMetricsError(fmt.Sprintf(
+			"can not unmarshal request body for %v", err), http.StatusBadRequest))
+		return
+	}
+
+	if err := validator.Validate(&reportMetricsRequest); err != nil {
+		tLogger.Errorf("[app process controller] invalid request body for %v", err)
+		Response(a.Ctx, http.StatusBadRequest, errors.NewReportAppProcessMetricsError(err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	tLogger.Debugf("[app process controller] received metrics of %d app processes", len(reportMetricsRequest.Metrics))
+
+	errMsg := services.AppProcessService.ReportAppProcessMetricsService(&reportMetricsRequest, tLogger)
+	if errMsg != nil {
+		tLogger.Errorf("[app process controller] failed to report app process metrics")
+		Response(a.Ctx, errMsg.HttpCode, errMsg)
+		return
+	}
+
+	Response(a.Ctx, http.StatusNoContent, nil)
+}
+
 // ShowAppProcess show an app process
 func (a *AppProcessControllerImpl) ShowAppProcess() {
 	tLogger := log.GetTraceLogger(a.Ctx)
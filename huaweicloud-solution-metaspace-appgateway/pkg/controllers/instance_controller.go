@@ -4,12 +4,16 @@
 package controllers
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
 
 	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/utils/log"
 	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/apis"
 	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/common"
 	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/services"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/utils/errors"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/utils/validator"
 	"github.com/beego/beego/v2/server/web"
 )
 
@@ -39,4 +43,76 @@ func (i *InstanceControllerImpl) ListInstances() {
 	}
 	
 	Response(i.Ctx, http.StatusOK, resp)
-}
\ No newline at end of file
+}
+
+// StartInstanceBuildUpdate 在实例上原地更新应用包
+func (i *InstanceControllerImpl) StartInstanceBuildUpdate() {
+	tLogger := log.GetTraceLogger(i.Ctx)
+	instanceID := i.GetString(":instance_id")
+
+	var req apis.UpdateInstanceBuildRequest
+	if err := json.Unmarshal(i.Ctx.Input.RequestBody, &req); err != nil {
+		tLogger.Errorf("[instance controller] failed to unmarshal build update request body for %v", err)
+		Response(i.Ctx, http.StatusBadRequest, errors.NewStartInstanceBuildUpdateError(instanceID,
+			fmt.Sprintf("can not unmarshal request body for %v", err), http.StatusBadRequest))
+		return
+	}
+	if err := validator.Validate(&req); err != nil {
+		tLogger.Errorf("[instance controller] invalid build update request body for %v", err)
+		Response(i.Ctx, http.StatusBadRequest, errors.NewStartInstanceBuildUpdateError(instanceID, err.Error(),
+			http.StatusBadRequest))
+		return
+	}
+
+	tLogger.Infof("[instance controller] received a build update request %+v for instance %s", req, instanceID)
+	resp, errResp := services.StartInstanceBuildUpdate(instanceID, &req, tLogger)
+	if errResp != nil {
+		Response(i.Ctx, errResp.HttpCode, errResp)
+		return
+	}
+
+	Response(i.Ctx, http.StatusAccepted, resp)
+}
+
+// ShowInstanceBuildUpdate 查询实例的应用包原地更新进度
+func (i *InstanceControllerImpl) ShowInstanceBuildUpdate() {
+	tLogger := log.GetTraceLogger(i.Ctx)
+	instanceID := i.GetString(":instance_id")
+	fleetID := i.Ctx.Input.Query(common.FleetId)
+
+	resp, errResp := services.ShowInstanceBuildUpdate(fleetID, instanceID, tLogger)
+	if errResp != nil {
+		Response(i.Ctx, errResp.HttpCode, errResp)
+		return
+	}
+
+	Response(i.Ctx, http.StatusOK, resp)
+}
+
+// ReportInstanceMetrics 上报实例的CPU和内存使用率
+func (i *InstanceControllerImpl) ReportInstanceMetrics() {
+	tLogger := log.GetTraceLogger(i.Ctx)
+	instanceID := i.GetString(":instance_id")
+
+	var req apis.ReportInstanceMetricsRequest
+	if err := json.Unmarshal(i.Ctx.Input.RequestBody, &req); err != nil {
+		tLogger.Errorf("[instance controller] failed to unmarshal instance metrics request body for %v", err)
+		Response(i.Ctx, http.StatusBadRequest, errors.NewReportInstanceMetricsError(instanceID,
+			fmt.Sprintf("can not unmarshal request body for %v", err), http.StatusBadRequest))
+		return
+	}
+	if err := validator.Validate(&req); err != nil {
+		tLogger.Errorf("[instance controller] invalid instance metrics request body for %v", err)
+		Response(i.Ctx, http.StatusBadRequest, errors.NewReportInstanceMetricsError(instanceID, err.Error(),
+			http.StatusBadRequest))
+		return
+	}
+
+	tLogger.Debugf("[instance controller] received metrics %+v of instance %s", req, instanceID)
+	if errResp := services.ReportInstanceMetrics(instanceID, &req, tLogger); errResp != nil {
+		Response(i.Ctx, errResp.HttpCode, errResp)
+		return
+	}
+
+	Response(i.Ctx, http.StatusNoContent, nil)
+}
@@ -245,6 +245,24 @@ func (a *ServerSessionControllerImpl) ListMonitorServerSessions() {
 	Response(a.Ctx, http.StatusOK, resp)
 }
 
+// CancelServerSession 取消尚未分配到进程的服务端会话
+func (a *ServerSessionControllerImpl) CancelServerSession() {
+	tLogger := log.GetTraceLogger(a.Ctx)
+
+	sid := a.GetString(":server_session_id")
+
+	tLogger.Infof("[server session controller] received a cancel server session %s request", sid)
+	errResp := services.ServerSessionService.CancelServerSession(sid, tLogger)
+	if errResp != nil {
+		tLogger.Errorf("[server session controller] failed to cancel server session %s, err %s",
+			sid, errResp.ErrorMsg)
+		Response(a.Ctx, errResp.HttpCode, errResp)
+		return
+	}
+
+	Response(a.Ctx, http.StatusNoContent, nil)
+}
+
 // TerminateAllRelativeResources 终止与指定服务端会话相关的资源，包括客户端会话和自身
 func (a *ServerSessionControllerImpl) TerminateAllRelativeResources() {
 	tLogger := log.GetTraceLogger(a.Ctx)
@@ -20,6 +20,7 @@ func InitFilters() {
 	web.InsertFilter("/v1/app-processes/:process_id", web.BeforeStatic, AppProcessEntranceFilter)
 	web.InsertFilter("/v1/app-processes/:process_id/state", web.BeforeStatic, AppProcessEntranceFilter)
 	web.InsertFilter("/v1/appprocess-counts", web.BeforeStatic, AppProcessEntranceFilter)
+	web.InsertFilter("/v1/app-process-metrics", web.BeforeStatic, AppProcessEntranceFilter)
 
 	web.InsertFilter("/v1/server-sessions", web.BeforeStatic, ServerSessionEntranceFilter)
 	web.InsertFilter("/v1/server-sessions/:server_session_id", web.BeforeStatic, ServerSessionEntranceFilter)
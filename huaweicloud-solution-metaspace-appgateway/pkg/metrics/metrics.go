@@ -15,6 +15,16 @@ const (
 	TagNameScalingGroupID        = "scaling_group_id"
 	FieldNameServerSessionCount  = "server_session_count"
 	FieldNameMaxServerSessionNum = "max_server_session_num"
+
+	MeasurementNameServerSessionQueue = "server_session_queue"
+	FieldNameQueueDepth               = "queue_depth"
+
+	// FieldNamePrefixCustomMetric 进程自定义指标的字段前缀, 避免与内置指标重名
+	FieldNamePrefixCustomMetric = "custom_"
+
+	MeasurementNameInstance    = "instance"
+	FieldNameCpuUtilization    = "cpu_utilization"
+	FieldNameMemoryUtilization = "memory_utilization"
 )
 
 type Metric struct {
@@ -26,6 +36,30 @@ type Metric struct {
 	MaxServerSessionNum int
 }
 
+// CustomMetric 进程上报的自定义指标, key为指标名称
+type CustomMetric struct {
+	ID             string
+	InstanceID     string
+	ScalingGroupID string
+	FleetID        string
+	Values         map[string]float64
+}
+
+// InstanceMetric 实例的CPU和内存使用率, 单位为百分比
+type InstanceMetric struct {
+	InstanceID        string
+	ScalingGroupID    string
+	FleetID           string
+	CpuUtilization    float64
+	MemoryUtilization float64
+}
+
+// QueueMetric fleet下排队等待进程的server session数量
+type QueueMetric struct {
+	FleetID    string
+	QueueDepth int
+}
+
 func (m *MetricClient) writeMetrics(pts []*client.Point) error {
 	bps, err := client.NewBatchPoints(client.BatchPointsConfig{
 		Database: m.influxDBDatabase,
@@ -68,3 +102,80 @@ func (m *MetricClient) WriteMetrics(metrics []*Metric) error {
 	}
 	return m.writeMetrics(pts)
 }
+
+// WriteQueueMetrics 上报各fleet的server session排队数量
+func (m *MetricClient) WriteQueueMetrics(metrics []*QueueMetric) error {
+	if len(metrics) == 0 {
+		return nil
+	}
+	pts := make([]*client.Point, len(metrics))
+	for i, metric := range metrics {
+		pt, err := client.NewPoint(MeasurementNameServerSessionQueue,
+			map[string]string{
+				TagNameFleetID: metric.FleetID,
+			},
+			map[string]interface{}{
+				FieldNameQueueDepth: metric.QueueDepth,
+			},
+		)
+		if err != nil {
+			return err
+		}
+		pts[i] = pt
+	}
+	return m.writeMetrics(pts)
+}
+
+// WriteCustomMetrics 上报进程自定义指标, 与内置指标写入同一个measurement和tag
+func (m *MetricClient) WriteCustomMetrics(metrics []*CustomMetric) error {
+	if len(metrics) == 0 {
+		return nil
+	}
+	pts := make([]*client.Point, 0, len(metrics))
+	for _, metric := range metrics {
+		fields := make(map[string]interface{}, len(metric.Values))
+		for name, value := range metric.Values {
+			fields[FieldNamePrefixCustomMetric+name] = value
+		}
+		pt, err := client.NewPoint(MeasurementNameProcess,
+			map[string]string{
+				TagNameFleetID:        metric.FleetID,
+				TagNameScalingGroupID: metric.ScalingGroupID,
+				TagNameInstanceID:     metric.InstanceID,
+				TagNameProcessID:      metric.ID,
+			},
+			fields,
+		)
+		if err != nil {
+			return err
+		}
+		pts = append(pts, pt)
+	}
+	return m.writeMetrics(pts)
+}
+
+// WriteInstanceMetrics 上报实例的CPU和内存使用率
+func (m *MetricClient) WriteInstanceMetrics(metrics []*InstanceMetric) error {
+	if len(metrics) == 0 {
+		return nil
+	}
+	pts := make([]*client.Point, len(metrics))
+	for i, metric := range metrics {
+		pt, err := client.NewPoint(MeasurementNameInstance,
+			map[string]string{
+				TagNameFleetID:        metric.FleetID,
+				TagNameScalingGroupID: metric.ScalingGroupID,
+				TagNameInstanceID:     metric.InstanceID,
+			},
+			map[string]interface{}{
+				FieldNameCpuUtilization:    metric.CpuUtilization,
+				FieldNameMemoryUtilization: metric.MemoryUtilization,
+			},
+		)
+		if err != nil {
+			return err
+		}
+		pts[i] = pt
+	}
+	return m.writeMetrics(pts)
+}
@@ -6,6 +6,7 @@ package metrics
 import (
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	client "github.com/influxdata/influxdb1-client/v2"
@@ -15,6 +16,7 @@ import (
 	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/distributedlock"
 	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/models"
 	app_process "codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/models/appprocess"
+	server_session "codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/models/serversession"
 	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/utils/log"
 	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/utils/security"
 )
@@ -47,7 +49,12 @@ type MetricClient struct {
 	uploadDuration time.Duration
 }
 
-var metricClient *MetricClient
+var (
+	metricClient    *MetricClient
+	metricClientMux sync.RWMutex
+	// metricInitOnce 写入自定义指标和持锁上报内置指标共用一个客户端, 只连接一次
+	metricInitOnce sync.Once
+)
 
 func initInfluxDataBase(influxClient client.Client, database string) error {
 	// 查询所配置的InfluxDataBase是否存在
@@ -189,6 +196,8 @@ func Init() {
 		}()
 		return
 	}
+	// 所有实例都需要连接influxdb以写入进程上报的自定义指标, 只有持有锁的实例定时上报内置指标
+	go InitMetric()
 	m := distributedlock.NewDistributedLockController(common.LockMetric, common.LockBizCategory, w)
 	m.Work()
 }
@@ -209,8 +218,13 @@ func (w *influxdbMetricWorker) CompetitorHook() {
 	close(w.stopCh)
 }
 
-// InitMetric 初始化influxDB
+// InitMetric 初始化influxDB, 并发调用时等待第一次调用连接成功后返回
 func InitMetric() {
+	metricInitOnce.Do(connectInfluxDB)
+}
+
+// connectInfluxDB 每10秒重试一次, 直到连接influxDB成功
+func connectInfluxDB() {
 	ticker := time.Tick(time.Second * 10)
 	for t := range ticker {
 		err := InitMetricClient()
@@ -262,6 +276,8 @@ func InitMetricClient() error {
 		return err
 	}
 
+	metricClientMux.Lock()
+	defer metricClientMux.Unlock()
 	metricClient = &MetricClient{
 		influxDBAddr:     config.GlobalConfig.InfluxAddr,
 		influxDBDatabase: config.GlobalConfig.InfluxDBName,
@@ -276,6 +292,8 @@ func InitMetricClient() error {
 
 // GetMetricClient return metric client
 func GetMetricClient() *MetricClient {
+	metricClientMux.RLock()
+	defer metricClientMux.RUnlock()
 	return metricClient
 }
 
@@ -284,9 +302,33 @@ func (m *MetricClient) Work(stopCh chan struct{}) {
 	go m.work(stopCh)
 }
 
+// uploadQueueMetrics 上报排队深度, 有进程的fleet没有排队时上报0, 以便伸缩决策及时感知排队清空
+func (m *MetricClient) uploadQueueMetrics(apDB []*app_process.AppProcess,
+	serverSessionDao *server_session.ServerSessionDao) {
+	queued, err := serverSessionDao.GetQueuedServerSessionCountGroupByFleetID()
+	if err != nil {
+		log.RunLogger.Errorf("[metric client] failed to get queued server session count for %v", err)
+		return
+	}
+	for _, ap := range apDB {
+		if _, ok := queued[ap.FleetID]; !ok {
+			queued[ap.FleetID] = 0
+		}
+	}
+
+	metrics := make([]*QueueMetric, 0, len(queued))
+	for fleetID, depth := range queued {
+		metrics = append(metrics, &QueueMetric{FleetID: fleetID, QueueDepth: depth})
+	}
+	if err = m.WriteQueueMetrics(metrics); err != nil {
+		log.RunLogger.Errorf("[metric client] failed to write queue metric for %v", err)
+	}
+}
+
 func (m *MetricClient) work(stopCh chan struct{}) {
 	ticker := time.NewTicker(m.uploadDuration)
 	appProcessDao := app_process.NewAppProcessDao(models.MySqlOrm)
+	serverSessionDao := server_session.NewServerSessionDao(models.MySqlOrm)
 	for {
 		select {
 		case <-ticker.C:
@@ -314,6 +356,7 @@ func (m *MetricClient) work(stopCh chan struct{}) {
 				log.RunLogger.Errorf("[metric client] failed to write metric for %v", err)
 				continue
 			}
+			m.uploadQueueMetrics(apDB, serverSessionDao)
 		case <-stopCh:
 			ticker.Stop()
 			return
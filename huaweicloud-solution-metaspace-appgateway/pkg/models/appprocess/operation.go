@@ -45,7 +45,7 @@ func (a *AppProcessDao) UpdateAppProcess(ap *AppProcess) (*AppProcess, error) {
 }
 
 func (a *AppProcessDao) UpdateAppProcessStateAndUpdatedAt(ap *AppProcess) (*AppProcess, error) {
-	_, err := a.sqlSession.Update(ap, FieldNameState, FieldNameUpdatedAt)
+	_, err := a.sqlSession.Update(ap, FieldNameState, FieldNameStateReason, FieldNameUpdatedAt)
 	if err != nil {
 		log.RunLogger.Errorf("[app process data service] failed to update app process state %v for %v", ap.ID, err)
 		return nil, fmt.Errorf("failed to update app process for %v", err)
@@ -76,6 +76,19 @@ func (a *AppProcessDao) GetAppProcessByID(id string) (*AppProcess, error) {
 	return &ap, err
 }
 
+// GetAppProcessesByIDs get app processes by ids
+func (a *AppProcessDao) GetAppProcessesByIDs(ids []string) ([]*AppProcess, error) {
+	var aps []*AppProcess
+	if len(ids) == 0 {
+		return aps, nil
+	}
+
+	cond := orm.NewCondition()
+	cond = cond.And(FieldNameProcessID+"__in", ids)
+	_, err := a.sqlSession.QueryTable(&AppProcess{}).SetCond(cond).All(&aps)
+	return aps, err
+}
+
 // GetAppProcessByFleetIDAndInstanceID get app process by fleet id and instance id with sort
 func (a *AppProcessDao) GetAppProcessByFleetIDAndInstanceID(fleetID, instanceID, sort string,
 	offset, limit int) (*[]AppProcess, error) {
@@ -128,6 +141,17 @@ func (a *AppProcessDao) GetAllAvailableAppProcessByFleetID(fleetID string) ([]*A
 	return aps, err
 }
 
+// GetAllActiveAppProcessByFleetID get all active processes by fleet id, including processes without free server session
+func (a *AppProcessDao) GetAllActiveAppProcessByFleetID(fleetID string) ([]*AppProcess, error) {
+	var aps []*AppProcess
+	sqlStr := fmt.Sprintf("select * from %s where FLEET_ID=? AND STATE=?", TableNameAppProcess)
+	_, err := a.sqlSession.Raw(sqlStr, fleetID, common.AppProcessStateActive).QueryRows(&aps)
+	if err == orm.ErrNoRows {
+		log.RunLogger.Infof("there is no active app process")
+	}
+	return aps, err
+}
+
 // GetAvailableAppProcessByFleetID get available app processes by fleet id
 func (a *AppProcessDao) GetAvailableAppProcessByFleetID(fleetID string) (AppProcess, error) {
 	var ap AppProcess
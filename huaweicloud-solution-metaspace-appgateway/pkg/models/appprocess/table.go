@@ -21,6 +21,7 @@ const (
 	FieldNameCreatedAt           = "CREATED_AT"
 	FieldNameUpdatedAt           = "UPDATED_AT"
 	FieldNameState               = "STATE"
+	FieldNameStateReason         = "STATE_REASON"
 	FieldNameServerSessionCount  = "SERVER_SESSION_COUNT"
 	FieldNameMaxServerSessionNum = "MAX_SERVER_SESSION_NUM"
 )
@@ -31,6 +32,7 @@ type AppProcess struct {
 	PID                                     int       `orm:" column(PID)"`
 	BizPID                                  int       `orm:" column(BIZ_PID)"`
 	InstanceID                              string    `orm:" column(INSTANCE_ID); size(128)"`
+	AvailabilityZone                        string    `orm:" column(AVAILABILITY_ZONE); size(64); null"`
 	ScalingGroupID                          string    `orm:" column(SCALING_GROUP_ID); size(128)"`
 	FleetID                                 string    `orm:" column(FLEET_ID); size(128)"`
 	CreatedAt                               time.Time `orm:" column(CREATED_AT); type(datetime)"`
@@ -42,6 +44,7 @@ type AppProcess struct {
 	GrpcPort                                int       `orm:" column(GRPC_PORT); type(integer); null"`
 	LogPath                                 string    `orm:" column(LOG_PATH); null"`
 	State                                   string    `orm:" column(STATE); size(36); null"`
+	StateReason                             string    `orm:" column(STATE_REASON); size(255); null"`
 	ServerSessionCount                      int       `orm:" column(SERVER_SESSION_COUNT)"`
 	MaxServerSessionNum                     int       `orm:" column(MAX_SERVER_SESSION_NUM)"`
 	NewServerSessionProtectionPolicy        string    `orm:" column(NEW_SERVER_SESSION_PROTECTION_POLICY); size(36); null"`
@@ -49,6 +52,7 @@ type AppProcess struct {
 	ServerSessionProtectionTimeLimitMinutes int       `orm:" column(SERVER_SESSION_PROTECTION_TIME_LIMIT_MINUTES); type(integer); null"`
 	LaunchPath                              string    `orm:"column(LAUNCH_PATH); size(255)"`
 	Parameters                              string    `orm:"column(PARAMETERS); type(text); null"`
+	Labels                                  string    `orm:"column(LABELS); type(text); null"`
 	IsDelete                                int       `orm:" column(IS_DELETE); type(integer);default(0)"`
 }
 
@@ -86,6 +90,8 @@ func (a *AppProcess) Transfer2State(state string) error {
 		return a.stateTerminatedCheck(state)
 	case app_process.AppProcessStateError:
 		return a.stateErrorCheck(state)
+	case app_process.AppProcessStateCrashLoop:
+		return a.stateCrashLoopCheck(state)
 	default:
 
 	}
@@ -123,6 +129,17 @@ func (a *AppProcess) stateTerminatingCheck(state string) error {
 
 func (a *AppProcess) stateTerminatedCheck(state string) error {
 	// all sate can goto terminated
+	if a.State == app_process.AppProcessStateActivating || a.State == app_process.AppProcessStateActive ||
+		a.State == app_process.AppProcessStateTerminating || a.State == app_process.AppProcessStateError ||
+		a.State == app_process.AppProcessStateCrashLoop {
+		a.State = state
+		return nil
+	}
+	return fmt.Errorf("cannot transfer app process %s state from %s to %s", a.ID, a.State, state)
+}
+
+func (a *AppProcess) stateCrashLoopCheck(state string) error {
+	// 进程反复启动失败, 除终止态外都可以进入crash loop, 恢复后只能转为terminated
 	if a.State == app_process.AppProcessStateActivating || a.State == app_process.AppProcessStateActive ||
 		a.State == app_process.AppProcessStateTerminating || a.State == app_process.AppProcessStateError {
 		a.State = state
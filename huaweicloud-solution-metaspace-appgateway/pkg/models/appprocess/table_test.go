@@ -0,0 +1,46 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 应用进程状态机测试
+package appprocess
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/common"
+)
+
+func TestTransfer2StateCrashLoop(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    string
+		to      string
+		wantErr bool
+	}{
+		{name: "activating process crash loop", from: common.AppProcessStateActivating,
+			to: common.AppProcessStateCrashLoop},
+		{name: "active process crash loop", from: common.AppProcessStateActive,
+			to: common.AppProcessStateCrashLoop},
+		{name: "terminated process crash loop", from: common.AppProcessStateTerminated,
+			to: common.AppProcessStateCrashLoop, wantErr: true},
+		{name: "crash loop process recovered", from: common.AppProcessStateCrashLoop,
+			to: common.AppProcessStateTerminated},
+		{name: "crash loop process active", from: common.AppProcessStateCrashLoop,
+			to: common.AppProcessStateActive, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ap := &AppProcess{State: tt.from}
+			err := ap.Transfer2State(tt.to)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, tt.from, ap.State)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.to, ap.State)
+		})
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 通知操作
+package notification
+
+import (
+	"fmt"
+
+	"github.com/beego/beego/v2/client/orm"
+)
+
+type Dao struct {
+	sqlSession orm.Ormer
+}
+
+// NewNotificationDao 创建一个notification dao
+func NewNotificationDao(sqlSession orm.Ormer) *Dao {
+	return &Dao{sqlSession: sqlSession}
+}
+
+// Bump 递增指定通知的序号, 通知不存在时创建
+func (d *Dao) Bump(name string) error {
+	sqlStr := fmt.Sprintf("INSERT INTO %s (NAME, SEQ, UPDATED_AT) VALUES (?, 1, NOW()) "+
+		"ON DUPLICATE KEY UPDATE SEQ = SEQ + 1, UPDATED_AT = NOW()", TableNameNotification)
+	_, err := d.sqlSession.Raw(sqlStr, name).Exec()
+	return err
+}
+
+// GetSeq 获取指定通知的当前序号, 通知不存在时为0
+func (d *Dao) GetSeq(name string) (int64, error) {
+	var seq int64
+	sqlStr := fmt.Sprintf("SELECT SEQ FROM %s WHERE NAME=?", TableNameNotification)
+	err := d.sqlSession.Raw(sqlStr, name).QueryRow(&seq)
+	if err == orm.ErrNoRows {
+		return 0, nil
+	}
+	return seq, err
+}
@@ -0,0 +1,36 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 通知表, 用于多实例部署时跨实例唤醒后台任务
+package notification
+
+import (
+	"time"
+
+	"github.com/beego/beego/v2/client/orm"
+)
+
+const (
+	TableNameNotification = "NOTIFICATION"
+	FieldNameName         = "NAME"
+)
+
+type Notification struct {
+	IDInc     int32     `orm:" pk; auto; column(ID_INC); default(0);"`
+	UpdatedAt time.Time `orm:" column(UPDATED_AT); type(datetime);auto_now"`
+	Name      string    `orm:" column(NAME); size(255)"`
+	Seq       int64     `orm:" column(SEQ); default(0)"`
+}
+
+func init() {
+	orm.RegisterModel(new(Notification))
+}
+
+func (n *Notification) TableName() string {
+	return TableNameNotification
+}
+
+func (n *Notification) TableUnique() [][]string {
+	return [][]string{
+		{FieldNameName},
+	}
+}
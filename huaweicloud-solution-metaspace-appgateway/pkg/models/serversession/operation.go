@@ -76,6 +76,30 @@ func (s *ServerSessionDao) ListByState1(state,
 	return &sss, err
 }
 
+// ListPendingServerSessions 查找等待分配进程(CREATING及QUEUED)的server session列表
+func (s *ServerSessionDao) ListPendingServerSessions(sort string, offset, limit int) (*[]ServerSession, error) {
+	var sss []ServerSession
+
+	cond := orm.NewCondition()
+	cond = cond.Or("STATE", common.ServerSessionStateCreating).Or("STATE", common.ServerSessionStateQueued)
+
+	_, err := s.sqlSession.QueryTable(&ServerSession{}).SetCond(cond).OrderBy(sort).
+		Offset(offset).Limit(limit).All(&sss)
+	return &sss, err
+}
+
+// UpdatePendingState 仅当server session仍在等待分配时更新状态及原因, 返回受影响的行数
+func (s *ServerSessionDao) UpdatePendingState(ss *ServerSession) (int64, error) {
+	sqlStr := fmt.Sprintf("update %s set STATE=?, STATE_REASON=? where ID=? and STATE in (?, ?)",
+		TableNameServerSession)
+	rsl, err := s.sqlSession.Raw(sqlStr, ss.State, ss.StateReason, ss.ID,
+		common.ServerSessionStateCreating, common.ServerSessionStateQueued).Exec()
+	if err != nil {
+		return 0, fmt.Errorf("failed to update pending server session for %v", err)
+	}
+	return rsl.RowsAffected()
+}
+
 // ListByFleetIDAndInstanceIDAndProcessIDAndState 根据指定信息查找server session列表
 func (s *ServerSessionDao) ListByFleetIDAndInstanceIDAndProcessIDAndState(fleetID, instanceID, processID, state,
 	sort string, offset, limit int) (*[]ServerSession, error) {
@@ -133,6 +157,31 @@ func (s *ServerSessionDao) GetAllServerSessionCountGroupByFleetID() (map[string]
 	return serverSessionCountsByFleet, err
 }
 
+// GetQueuedServerSessionCountGroupByFleetID 按fleet统计排队中的server session数量
+func (s *ServerSessionDao) GetQueuedServerSessionCountGroupByFleetID() (map[string]int, error) {
+	queuedCountsByFleet := map[string]int{}
+
+	var rows []orm.Params
+	_, err := s.sqlSession.Raw("SELECT FLEET_ID, COUNT(*) as COUNT FROM SERVER_SESSION "+
+		"WHERE STATE=? GROUP BY FLEET_ID", common.ServerSessionStateQueued).Values(&rows)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range rows {
+		fleetID, _ := rows[i]["FLEET_ID"].(string)
+		countStr, _ := rows[i]["COUNT"].(string)
+
+		count, err := strconv.Atoi(countStr)
+		if err != nil {
+			return nil, err
+		}
+		queuedCountsByFleet[fleetID] = count
+	}
+
+	return queuedCountsByFleet, nil
+}
+
 // QueryActivatingServerSession 查询所有处于active状态的server session
 func (s *ServerSessionDao) QueryActivatingServerSession() ([]ServerSession, error) {
 	var sss []ServerSession
@@ -36,6 +36,7 @@ type ServerSession struct {
 	StateReason                 string    `orm:" column(STATE_REASON); size(255); null"`
 	SessionData                 string    `orm:" column(SESSION_DATA); type(text); null"`
 	SessionProperties           string    `orm:" column(SESSION_PROPERTIES); type(text); null"`
+	LabelSelector               string    `orm:" column(LABEL_SELECTOR); type(text); null"`
 	PublicIP                    string    `orm:" column(PUBLIC_IP); size(255); null"`
 	ClientPort                  int       `orm:" column(CLIENT_PORT); type(integer); null"`
 	MaxClientSessionNum         int       `orm:" column(MAX_CLIENT_SESSION_NUM)"`
@@ -43,6 +44,8 @@ type ServerSession struct {
 	ProtectionPolicy            string    `orm:" column(PROTECTION_POLICY); null"`
 	ProtectionTimeLimitMinutes  int       `orm:" column(PROTECTION_TIME_LIMIT_MINUTES); type(integer); null"`
 	ActivationTimeoutSeconds    int       `orm:" column(ACTIVATION_TIMEOUT_SECONDS); size(36); null"`
+	PlacementTimeoutSeconds     int       `orm:" column(PLACEMENT_TIMEOUT_SECONDS); type(integer); null"`
+	PlacedAt                    time.Time `orm:" column(PLACED_AT); type(datetime); null"`
 	TerminatedAT                time.Time `orm:" column(TERMINATED_AT); type(datetime); null"`
 	CreatedAt                   time.Time `orm:" column(CREATED_AT); type(datetime);auto_now_add"`
 	UpdatedAt                   time.Time `orm:" column(UPDATED_AT); type(datetime);auto_now"`
@@ -105,7 +108,8 @@ func (s *ServerSession) Transfer2State(state string, stateReason string) error {
 	}
 
 	if state == server_session.ServerSessionStateTerminated {
-		if s.State == server_session.ServerSessionStateActive {
+		// 进程可能在上报激活前就结束了server session
+		if s.State == server_session.ServerSessionStateActive || s.State == server_session.ServerSessionStateActivating {
 			s.State = state
 			s.StateReason = stateReason
 			return nil
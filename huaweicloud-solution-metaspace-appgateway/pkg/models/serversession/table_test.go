@@ -0,0 +1,39 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// server session状态机测试
+package serversession
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/common"
+)
+
+func TestTransfer2StateTerminated(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    string
+		wantErr bool
+	}{
+		{name: "terminate active server session", from: common.ServerSessionStateActive},
+		{name: "terminate activating server session", from: common.ServerSessionStateActivating},
+		{name: "terminate error server session", from: common.ServerSessionStateError, wantErr: true},
+		{name: "terminate queued server session", from: common.ServerSessionStateQueued, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ss := &ServerSession{State: tt.from}
+			err := ss.Transfer2State(common.ServerSessionStateTerminated, "process terminate the server session")
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, tt.from, ss.State)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, common.ServerSessionStateTerminated, ss.State)
+		})
+	}
+}
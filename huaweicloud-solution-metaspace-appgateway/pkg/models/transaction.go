@@ -4,6 +4,7 @@
 package models
 
 import (
+	"errors"
 	"fmt"
 	"sync"
 
@@ -16,11 +17,32 @@ import (
 	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/utils/log"
 )
 
+var (
+	// ErrNoAvailableProcess 进程已没有空闲的server session名额
+	ErrNoAvailableProcess = errors.New("there is no available process")
+	// ErrServerSessionNotPending server session已不处于等待分配状态(例如已被取消)
+	ErrServerSessionNotPending = errors.New("server session is not pending for dispatch")
+)
+
+// DispatchServerSession2Process 将server session分配到进程, 并占用进程的server session名额
 func DispatchServerSession2Process(ss *server_session.ServerSession, ap *app_process.AppProcess) error {
 	tx, err := MySqlOrm.Begin()
 	if err != nil {
 		return err
 	}
+	// 锁定server session, 防止与取消操作并发
+	var state string
+	sqlStr0 := fmt.Sprintf("select STATE from %s where ID=? for update", server_session.TableNameServerSession)
+	if err0 := tx.Raw(sqlStr0, ss.ID).QueryRow(&state); err0 != nil || (state != common.ServerSessionStateCreating &&
+		state != common.ServerSessionStateQueued) {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			log.RunLogger.Errorf("[transaction] sqlStr0 rollback failed for %v", rbErr)
+		}
+		if err0 != nil {
+			return err0
+		}
+		return ErrServerSessionNotPending
+	}
 	sqlStr := fmt.Sprintf("update %s set SERVER_SESSION_COUNT = SERVER_SESSION_COUNT + 1 "+
 		"where ID=? and SERVER_SESSION_COUNT < MAX_SERVER_SESSION_NUM", app_process.TableNameAppProcess)
 	rsl1, err1 := tx.Raw(sqlStr, ap.ID).Exec()
@@ -43,7 +65,7 @@ func DispatchServerSession2Process(ss *server_session.ServerSession, ap *app_pro
 			log.RunLogger.Errorf("[transaction] sqlStr0 rollback failed for %v", err)
 			return err
 		}
-		return fmt.Errorf("there is no available process")
+		return ErrNoAvailableProcess
 	}
 	_, err2 := tx.Update(ss)
 	if err2 != nil {
@@ -60,7 +82,8 @@ func DispatchServerSession2Process(ss *server_session.ServerSession, ap *app_pro
 
 }
 
-// UpdateServerSessionState 变更为Error或者Terminated的时候需要同步去释放Process的server session名额
+// UpdateServerSessionState 变更为Error或者Terminated的时候需要同步去释放Process的server session名额,
+// 并结束其上的client session; 只有状态真正发生变化时才释放名额, 避免并发终止时重复释放
 func UpdateServerSessionState(ss *server_session.ServerSession, tLogger *log.FMLogger) error {
 	if ss.State != common.ServerSessionStateError && ss.State != common.ServerSessionStateTerminated {
 		return nil
@@ -70,43 +93,55 @@ func UpdateServerSessionState(ss *server_session.ServerSession, tLogger *log.FML
 	if err != nil {
 		return err
 	}
+	rollback := func(cause error) error {
+		if err := tx.Rollback(); err != nil {
+			tLogger.Errorf("[transaction] rollback server session %s state update failed for %v", ss.ID, err)
+		}
+		return cause
+	}
+
+	// 修改server session, 并更状态, 已终止的不再处理
+	sqlStr1 := fmt.Sprintf("update %s set STATE=?,STATE_REASON=?,CLIENT_SESSION_COUNT=0 "+
+		"where ID=? and STATE not in (?, ?)", server_session.TableNameServerSession)
+	rsl1, err := tx.Raw(sqlStr1, ss.State, ss.StateReason, ss.ID,
+		common.ServerSessionStateError, common.ServerSessionStateTerminated).Exec()
+	if err != nil {
+		tLogger.Errorf("[transaction] failed to update server session %s state for %v", ss.ID, err)
+		return rollback(err)
+	}
+	if num, _ := rsl1.RowsAffected(); num == 0 {
+		tLogger.Infof("[transaction] server session %s has already been terminated, skip to release "+
+			"process %s", ss.ID, ss.ProcessID)
+		return rollback(nil)
+	}
 
 	// 这里加了行锁，所以并发不好，但是单个process的server session不会太多，所以可以先这样处理
 	// 修改process, 释放名额
-	sqlStr := fmt.Sprintf("update %s set SERVER_SESSION_COUNT = SERVER_SESSION_COUNT - 1 "+
+	sqlStr2 := fmt.Sprintf("update %s set SERVER_SESSION_COUNT = SERVER_SESSION_COUNT - 1 "+
 		"where ID= ? and SERVER_SESSION_COUNT > 0 ", app_process.TableNameAppProcess)
-	rsl1, err1 := tx.Raw(sqlStr, ss.ProcessID).Exec()
-	if err1 == nil {
-		num, _ := rsl1.RowsAffected()
-		if num == 0 {
-			tLogger.Infof("[transaction] reduce server session count for server session %s in process %s do not "+
-				"affected", ss.ID, ss.ProcessID)
-		}
+	rsl2, err := tx.Raw(sqlStr2, ss.ProcessID).Exec()
+	if err != nil {
+		tLogger.Errorf("[transaction] failed to release process %s for server session %s for %v",
+			ss.ProcessID, ss.ID, err)
+		return rollback(err)
 	}
-
-	// 修改server session, 并更状态
-	sqlStr2 := fmt.Sprintf("update %s set STATE=?,STATE_REASON=? where ID=?",
-		server_session.TableNameServerSession)
-	rsl2, err2 := tx.Raw(sqlStr2, ss.State, ss.StateReason, ss.ID).Exec()
-	if err2 == nil {
-		num, _ := rsl2.RowsAffected()
-		if num == 0 {
-			tLogger.Infof("[transaction] server session state update SQL2 %s: do not affected any row, "+
-				"state %s, reason %s, id %v, start to rollback", sqlStr2, ss.State, ss.StateReason, ss.ID)
-			// 如果修改无效，证明已经修改过了，也是需要回滚的
-			return tx.Rollback()
-		}
+	if num, _ := rsl2.RowsAffected(); num == 0 {
+		tLogger.Infof("[transaction] reduce server session count for server session %s in process %s do not "+
+			"affected", ss.ID, ss.ProcessID)
 	}
 
-	if err1 != nil || err2 != nil {
-		tLogger.Errorf("[transaction] err1: %v", err1)
-		tLogger.Errorf("[transaction] err2: %v", err2)
-		tLogger.Errorf("[transaction] start to rollback for server session %s state update", ss.ID)
-		return tx.Rollback()
-	} else {
-		return tx.Commit()
+	// 结束server session上的client session
+	sqlStr3 := fmt.Sprintf("update %s set STATE=? where SERVER_SESSION_ID=? and STATE in (?, ?)",
+		client_session.TableNameClientSession)
+	_, err = tx.Raw(sqlStr3, common.ClientSessionStateCompleted, ss.ID,
+		common.ClientSessionStateReserved, common.ClientSessionStateConnected).Exec()
+	if err != nil {
+		tLogger.Errorf("[transaction] failed to complete client sessions of server session %s for %v",
+			ss.ID, err)
+		return rollback(err)
 	}
 
+	return tx.Commit()
 }
 
 // CreateServerSessionAndUpdateProcess 使用事务的方式创建server session并更新process的server session计数
@@ -261,7 +296,8 @@ func TerminateAllResourcesForServerSession(ssID string, tLogger *log.FMLogger) e
 func TerminateOutOfDateServerSession(instance string) error {
 	// 先获取全部的超时server session，如果只剩下3秒就过期也设置为超时
 	sqlStr := fmt.Sprintf("select * FROM %s where WORK_NODE_ID=? and STATE=? and ACTIVATION_TIMEOUT_SECONDS "+
-		"< TIMESTAMPDIFF(SECOND, CREATED_AT, DATE_ADD(NOW(),INTERVAL 3 SECOND))", server_session.TableNameServerSession)
+		"< TIMESTAMPDIFF(SECOND, GREATEST(COALESCE(PLACED_AT, CREATED_AT), CREATED_AT), "+
+		"DATE_ADD(NOW(),INTERVAL 3 SECOND))", server_session.TableNameServerSession)
 	log.RunLogger.Infof("sqlStr: %v", sqlStr)
 	var sss []server_session.ServerSession
 	_, err := MySqlOrm.Raw(sqlStr, instance, common.ServerSessionStateActivating).QueryRows(&sss)
@@ -20,11 +20,21 @@ func InitRouters() {
 		controllers.AppProcessController, "put:UpdateAppProcessState")
 	web.Router("/v1/app-process-counts",
 		controllers.AppProcessController, "get:ShowAppProcessCounts")
+	web.Router("/v1/app-process-metrics",
+		controllers.AppProcessController, "post:ReportAppProcessMetrics")
 
 	// instance configuration routers
 	web.Router("/v1/instance-scaling-group/:instance_scaling_group_id/instance-configuration",
 		controllers.InstanceConfigurationController, "get:ShowInstanceConfiguration")
 
+	// instance build update routers
+	web.Router("/v1/instances/:instance_id/build-update",
+		controllers.InstanceController, "post:StartInstanceBuildUpdate;get:ShowInstanceBuildUpdate")
+
+	// instance metrics routers
+	web.Router("/v1/instances/:instance_id/metrics",
+		controllers.InstanceController, "post:ReportInstanceMetrics")
+
 	// server session routers
 	web.Router("/v1/server-sessions",
 		controllers.ServerSessionController, "post:CreateServerSession;get:ListServerSessions")
@@ -32,6 +42,8 @@ func InitRouters() {
 		controllers.ServerSessionController, "get:ShowServerSession;put:UpdateServerSession")
 	web.Router("/v1/server-sessions/:server_session_id/state",
 		controllers.ServerSessionController, "put:UpdateServerSessionState")
+	web.Router("/v1/server-sessions/:server_session_id/cancel",
+		controllers.ServerSessionController, "post:CancelServerSession")
 
 	// client session routers
 	web.Router("/v1/client-sessions/batch-create",
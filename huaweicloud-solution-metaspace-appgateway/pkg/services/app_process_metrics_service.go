@@ -0,0 +1,83 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 应用进程自定义指标服务
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/apis"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/metrics"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/models"
+	app_process "codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/models/appprocess"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/utils/errors"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/utils/log"
+)
+
+// customMetricNamePattern 自定义指标名称, 会作为influxdb字段名并被伸缩策略引用
+var customMetricNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{0,63}$`)
+
+// ReportAppProcessMetricsService 将进程上报的自定义指标写入influxdb, 标签取自数据库中的进程信息
+func (a *AppProcessServiceImpl) ReportAppProcessMetricsService(req *apis.ReportAppProcessMetricsRequest,
+	tLogger *log.FMLogger) *errors.ErrorResp {
+	ids := make([]string, 0, len(req.Metrics))
+	for _, m := range req.Metrics {
+		for name := range m.Values {
+			if !customMetricNamePattern.MatchString(name) {
+				return errors.NewReportAppProcessMetricsError(fmt.Sprintf("invalid metric name %s", name),
+					http.StatusBadRequest)
+			}
+		}
+		ids = append(ids, m.ProcessID)
+	}
+
+	metricClient := metrics.GetMetricClient()
+	if metricClient == nil {
+		tLogger.Errorf("[app process metrics service] metric client is not ready")
+		return errors.NewReportAppProcessMetricsError("metric client is not ready", http.StatusServiceUnavailable)
+	}
+
+	appProcessDao := app_process.NewAppProcessDao(models.MySqlOrm)
+	aps, err := appProcessDao.GetAppProcessesByIDs(ids)
+	if err != nil {
+		tLogger.Errorf("[app process metrics service] failed to get app processes %v for %v", ids, err)
+		return errors.NewReportAppProcessMetricsError(err.Error(), http.StatusInternalServerError)
+	}
+
+	customMetrics := buildCustomMetrics(aps, req.Metrics)
+	if len(customMetrics) < len(req.Metrics) {
+		tLogger.Warnf("[app process metrics service] %d of %d processes not found, drop their metrics",
+			len(req.Metrics)-len(customMetrics), len(req.Metrics))
+	}
+	if err = metricClient.WriteCustomMetrics(customMetrics); err != nil {
+		tLogger.Errorf("[app process metrics service] failed to write custom metrics for %v", err)
+		return errors.NewReportAppProcessMetricsError(err.Error(), http.StatusInternalServerError)
+	}
+	return nil
+}
+
+// buildCustomMetrics 为自定义指标补充fleet、伸缩组和实例标签, 忽略不存在的进程
+func buildCustomMetrics(aps []*app_process.AppProcess, reported []apis.AppProcessMetrics) []*metrics.CustomMetric {
+	apMap := make(map[string]*app_process.AppProcess, len(aps))
+	for _, ap := range aps {
+		apMap[ap.ID] = ap
+	}
+
+	customMetrics := make([]*metrics.CustomMetric, 0, len(reported))
+	for _, m := range reported {
+		ap, ok := apMap[m.ProcessID]
+		if !ok {
+			continue
+		}
+		customMetrics = append(customMetrics, &metrics.CustomMetric{
+			ID:             ap.ID,
+			InstanceID:     ap.InstanceID,
+			ScalingGroupID: ap.ScalingGroupID,
+			FleetID:        ap.FleetID,
+			Values:         m.Values,
+		})
+	}
+	return customMetrics
+}
@@ -0,0 +1,45 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 应用进程自定义指标服务测试
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/apis"
+	app_process "codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/models/appprocess"
+)
+
+func TestCustomMetricNamePattern(t *testing.T) {
+	valid := []string{"queue_length", "Players", "a", "tick_rate_60"}
+	invalid := []string{"", "1st", "_hidden", "queue-length", "queue length",
+		"a2345678901234567890123456789012345678901234567890123456789012345"}
+
+	for _, name := range valid {
+		assert.True(t, customMetricNamePattern.MatchString(name), name)
+	}
+	for _, name := range invalid {
+		assert.False(t, customMetricNamePattern.MatchString(name), name)
+	}
+}
+
+func TestBuildCustomMetrics(t *testing.T) {
+	aps := []*app_process.AppProcess{
+		{ID: "p1", InstanceID: "i1", ScalingGroupID: "sg1", FleetID: "f1"},
+	}
+	reported := []apis.AppProcessMetrics{
+		{ProcessID: "p1", Values: map[string]float64{"queue_length": 3}},
+		{ProcessID: "unknown", Values: map[string]float64{"queue_length": 5}},
+	}
+
+	got := buildCustomMetrics(aps, reported)
+
+	assert.Len(t, got, 1)
+	assert.Equal(t, "p1", got[0].ID)
+	assert.Equal(t, "i1", got[0].InstanceID)
+	assert.Equal(t, "sg1", got[0].ScalingGroupID)
+	assert.Equal(t, "f1", got[0].FleetID)
+	assert.Equal(t, 3.0, got[0].Values["queue_length"])
+}
@@ -30,12 +30,19 @@ func (a *AppProcessServiceImpl) CreateAppProcessService(req *apis.RegisterAppPro
 	tLogger *log.FMLogger) (*apis.RegisterAppProcessResponse, *errors.ErrorResp) {
 	appProcessDao := app_process.NewAppProcessDao(models.MySqlOrm)
 
+	labelsStr, err := json.Marshal(req.Labels)
+	if err != nil {
+		tLogger.Errorf("[app process service] marshal labels failed %v", err)
+		return nil, errors.NewCreateAppProcessError(err.Error(), http.StatusInternalServerError)
+	}
+
 	apDB := &app_process.AppProcess{
 		ID: fmt.Sprintf("%s%s", app_process_common.AppProcessIDPrefix, uuid.NewRandom().String()),
 
 		PID:                                     req.PID,
 		BizPID:                                  req.BizPID,
 		InstanceID:                              req.InstanceID,
+		AvailabilityZone:                        req.AvailabilityZone,
 		ScalingGroupID:                          req.ScalingGroupID,
 		FleetID:                                 req.FleetID,
 		CreatedAt:                               time.Now().UTC(),
@@ -50,9 +57,10 @@ func (a *AppProcessServiceImpl) CreateAppProcessService(req *apis.RegisterAppPro
 		ServerSessionProtectionTimeLimitMinutes: req.ServerSessionProtectionTimeLimitMinutes,
 		LaunchPath:                              req.LaunchPath,
 		Parameters:                              req.Parameters,
+		Labels:                                  string(labelsStr),
 	}
 
-	_, err := appProcessDao.CreateAppProcess(apDB)
+	_, err = appProcessDao.CreateAppProcess(apDB)
 	if err != nil {
 		return nil, errors.NewCreateAppProcessError(err.Error(), http.StatusInternalServerError)
 	}
@@ -62,6 +70,7 @@ func (a *AppProcessServiceImpl) CreateAppProcessService(req *apis.RegisterAppPro
 		PID:                                     apDB.PID,
 		BizPID:                                  apDB.BizPID,
 		InstanceID:                              apDB.InstanceID,
+		AvailabilityZone:                        apDB.AvailabilityZone,
 		ScalingGroupID:                          apDB.ScalingGroupID,
 		FleetID:                                 apDB.FleetID,
 		PublicIP:                                apDB.PublicIP,
@@ -75,6 +84,7 @@ func (a *AppProcessServiceImpl) CreateAppProcessService(req *apis.RegisterAppPro
 		ServerSessionActivationTimeoutSeconds:   apDB.ServerSessionActivationTimeoutSeconds,
 		LaunchPath:                              apDB.LaunchPath,
 		Parameters:                              apDB.Parameters,
+		Labels:                                  apis.ParseLabels(apDB.Labels),
 	}
 
 	resp := &apis.RegisterAppProcessResponse{AppProcess: ap}
@@ -135,12 +145,18 @@ func (a *AppProcessServiceImpl) UpdateAppProcessStateService(processID string, r
 	}
 
 	// update app process in db
+	stateChanged := apDB.State != req.State
 	err = apDB.Transfer2State(req.State)
 	if err != nil {
 		tLogger.Errorf("[app process data service] app process %v transfer state error %v", apDB.ID, err)
 		return nil, errors.NewUpdateAppProcessStateError(err.Error(), http.StatusBadRequest)
 	}
 
+	// 状态变化时记录原因, 如资源超限被终止
+	if stateChanged {
+		apDB.StateReason = req.StateReason
+	}
+
 	// 这里的updateat是必要的，即使state没有变化，这个update at是判定是否是僵尸进程的关键
 	apDB.UpdatedAt = time.Now().UTC()
 	_, err = appProcessDao.UpdateAppProcessStateAndUpdatedAt(apDB)
@@ -148,8 +164,13 @@ func (a *AppProcessServiceImpl) UpdateAppProcessStateService(processID string, r
 		return nil, errors.NewUpdateAppProcessStateError(err.Error(), http.StatusInternalServerError)
 	}
 
-	// 当app-process被修改为TERMINATED时，检查该进程上是否有AVTIVE状态的会话，若有则修改会话的状态
-	if req.State == app_process_common.AppProcessStateTerminated {
+	if stateChanged && req.State == app_process_common.AppProcessStateActive {
+		NotifyDispatchCapacityChanged()
+	}
+
+	// 当app-process被修改为TERMINATED或CRASH_LOOP时，检查该进程上是否有AVTIVE状态的会话，若有则修改会话的状态
+	if req.State == app_process_common.AppProcessStateTerminated ||
+		req.State == app_process_common.AppProcessStateCrashLoop {
 		err := TerminateServerSessionByAppProcessID(apDB.ID, apDB.MaxServerSessionNum, tLogger)
 		if err != nil {
 			return nil, err
@@ -324,6 +345,7 @@ func transApDB2Ap(apDB app_process.AppProcess) apis.AppProcess {
 		PID:                                     apDB.PID,
 		BizPID:                                  apDB.BizPID,
 		InstanceID:                              apDB.InstanceID,
+		AvailabilityZone:                        apDB.AvailabilityZone,
 		ScalingGroupID:                          apDB.ScalingGroupID,
 		FleetID:                                 apDB.FleetID,
 		PublicIP:                                apDB.PublicIP,
@@ -333,6 +355,7 @@ func transApDB2Ap(apDB app_process.AppProcess) apis.AppProcess {
 		AuxProxyPort:                            apDB.AuxProxyPort,
 		LogPath:                                 apDB.LogPath,
 		State:                                   apDB.State,
+		StateReason:                             apDB.StateReason,
 		ServerSessionCount:                      apDB.ServerSessionCount,
 		MaxServerSessionNum:                     apDB.MaxServerSessionNum,
 		NewServerSessionProtectionPolicy:        apDB.NewServerSessionProtectionPolicy,
@@ -340,5 +363,6 @@ func transApDB2Ap(apDB app_process.AppProcess) apis.AppProcess {
 		ServerSessionActivationTimeoutSeconds:   apDB.ServerSessionActivationTimeoutSeconds,
 		LaunchPath:                              apDB.LaunchPath,
 		Parameters:                              apDB.Parameters,
+		Labels:                                  apis.ParseLabels(apDB.Labels),
 	}
 }
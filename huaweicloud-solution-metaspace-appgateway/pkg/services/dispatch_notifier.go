@@ -0,0 +1,120 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 服务端会话分配唤醒通知
+package services
+
+import (
+	"sync/atomic"
+	"time"
+
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/config"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/models"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/models/notification"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/utils/log"
+)
+
+const (
+	dispatchNotificationName = "server-session-dispatch"
+	notificationPollInterval = 100 * time.Millisecond
+)
+
+// bumpDispatchNotification 通知其他实例上的分配器, 多实例部署时使用通知表
+var bumpDispatchNotification = func() error {
+	return notification.NewNotificationDao(models.MySqlOrm).Bump(dispatchNotificationName)
+}
+
+// getDispatchNotificationSeq 获取通知表中分配通知的序号
+var getDispatchNotificationSeq = func() (int64, error) {
+	return notification.NewNotificationDao(models.MySqlOrm).GetSeq(dispatchNotificationName)
+}
+
+// dispatchNotifier 唤醒分配器; 分配器在本进程时直接通过信道唤醒, 否则写通知表由持锁实例感知
+type dispatchNotifier struct {
+	wakeCh      chan struct{}
+	localActive int32
+	// pendingBumps 尚未写入通知表的通知数, 同一时刻只有一个协程写通知表
+	pendingBumps int32
+}
+
+var serverSessionDispatchNotifier = newDispatchNotifier()
+
+func newDispatchNotifier() *dispatchNotifier {
+	// 容量为1, 多次通知合并为一次唤醒
+	return &dispatchNotifier{wakeCh: make(chan struct{}, 1)}
+}
+
+// NotifyServerSessionCreated 有新的server session等待分配时唤醒分配器
+func NotifyServerSessionCreated() {
+	serverSessionDispatchNotifier.notify()
+}
+
+// NotifyDispatchCapacityChanged 进程变为可用或server session结束释放容量时唤醒分配器, 排队中的server session无需等待退避
+func NotifyDispatchCapacityChanged() {
+	serverSessionDispatchNotifier.notify()
+}
+
+func (n *dispatchNotifier) notify() {
+	if config.GlobalConfig.DeployModel == config.DeployModelSingleton || atomic.LoadInt32(&n.localActive) == 1 {
+		n.wake()
+		return
+	}
+	// 通知表只有一行, 已有协程在写时只计数, 由其合并为一次写入, 避免高并发创建时争抢行锁
+	if atomic.AddInt32(&n.pendingBumps, 1) == 1 {
+		go n.bumpLoop()
+	}
+}
+
+// bumpLoop 写通知表直到没有未写入的通知, 写入期间到达的通知合并为下一次写入
+func (n *dispatchNotifier) bumpLoop() {
+	for {
+		pending := atomic.LoadInt32(&n.pendingBumps)
+		if err := bumpDispatchNotification(); err != nil {
+			// 通知失败时由分配器的周期扫描兜底
+			log.RunLogger.Errorf("[dispatch notifier] failed to bump dispatch notification for %v", err)
+		}
+		if atomic.CompareAndSwapInt32(&n.pendingBumps, pending, 0) {
+			return
+		}
+	}
+}
+
+func (n *dispatchNotifier) wake() {
+	select {
+	case n.wakeCh <- struct{}{}:
+	default:
+	}
+}
+
+func (n *dispatchNotifier) setLocalActive(active bool) {
+	var v int32
+	if active {
+		v = 1
+	}
+	atomic.StoreInt32(&n.localActive, v)
+}
+
+// watchRemote 持锁实例轮询通知表, 序号变化时唤醒本地分配器
+func (n *dispatchNotifier) watchRemote(stopCh chan struct{}) {
+	lastSeq, err := getDispatchNotificationSeq()
+	if err != nil {
+		log.RunLogger.Errorf("[dispatch notifier] failed to get dispatch notification seq for %v", err)
+	}
+	ticker := time.NewTicker(notificationPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			seq, err := getDispatchNotificationSeq()
+			if err != nil {
+				log.RunLogger.Errorf("[dispatch notifier] failed to get dispatch notification seq for %v", err)
+				continue
+			}
+			if seq != lastSeq {
+				lastSeq = seq
+				n.wake()
+			}
+		}
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 实例应用包原地更新服务, 转发请求到实例上的auxproxy
+package services
+
+import (
+	"fmt"
+	"net/http"
+
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/apis"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/models"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/models/appprocess"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/utils/clients"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/utils/errors"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/utils/log"
+)
+
+// getAuxProxyAddr 实例上所有进程的auxproxy地址相同, 取最新注册的进程
+func getAuxProxyAddr(fleetID, instanceID string) (string, error) {
+	dao := appprocess.NewAppProcessDao(models.MySqlOrm)
+	aps, err := dao.GetAppProcessByFleetIDAndInstanceID(fleetID, instanceID,
+		"-"+appprocess.FieldNameCreatedAt, 0, 1)
+	if err != nil {
+		return "", err
+	}
+	if len(*aps) == 0 {
+		return "", nil
+	}
+	ap := (*aps)[0]
+	return fmt.Sprintf("%s:%d", ap.PublicIP, ap.AuxProxyPort), nil
+}
+
+// StartInstanceBuildUpdate 在实例上开始原地更新应用包
+func StartInstanceBuildUpdate(instanceID string, req *apis.UpdateInstanceBuildRequest,
+	tLogger *log.FMLogger) (*apis.InstanceBuildUpdateResponse, *errors.ErrorResp) {
+	addr, err := getAuxProxyAddr(req.FleetID, instanceID)
+	if err != nil {
+		tLogger.Errorf("[instance build service] failed to get auxproxy address of instance %s for %v",
+			instanceID, err)
+		return nil, errors.NewStartInstanceBuildUpdateError(instanceID, err.Error(), http.StatusInternalServerError)
+	}
+	if addr == "" {
+		return nil, errors.NewInstanceNotFoundError(instanceID, http.StatusNotFound)
+	}
+
+	code, status, err := clients.NewAuxProxyClient(addr).StartBuildUpdate(&apis.AuxProxyBuildUpdateRequest{
+		BuildID:                 req.BuildID,
+		SourceType:              req.SourceType,
+		Bucket:                  req.Bucket,
+		Object:                  req.Object,
+		Url:                     req.Url,
+		Path:                    req.Path,
+		Region:                  req.Region,
+		Checksum:                req.Checksum,
+		ReadinessTimeoutSeconds: req.ReadinessTimeoutSeconds,
+	})
+	if err != nil {
+		tLogger.Errorf("[instance build service] failed to start build update on instance %s for %v",
+			instanceID, err)
+		// auxproxy上已有其他应用包在更新时透传冲突
+		httpCode := http.StatusInternalServerError
+		if code == http.StatusConflict {
+			httpCode = http.StatusConflict
+		}
+		return nil, errors.NewStartInstanceBuildUpdateError(instanceID, err.Error(), httpCode)
+	}
+
+	tLogger.Infof("[instance build service] start build %s update on instance %s", req.BuildID, instanceID)
+	return &apis.InstanceBuildUpdateResponse{InstanceID: instanceID, BuildUpdateStatus: *status}, nil
+}
+
+// ShowInstanceBuildUpdate 查询实例的应用包原地更新进度
+func ShowInstanceBuildUpdate(fleetID, instanceID string,
+	tLogger *log.FMLogger) (*apis.InstanceBuildUpdateResponse, *errors.ErrorResp) {
+	addr, err := getAuxProxyAddr(fleetID, instanceID)
+	if err != nil {
+		tLogger.Errorf("[instance build service] failed to get auxproxy address of instance %s for %v",
+			instanceID, err)
+		return nil, errors.NewShowInstanceBuildUpdateError(instanceID, err.Error(), http.StatusInternalServerError)
+	}
+	if addr == "" {
+		return nil, errors.NewInstanceNotFoundError(instanceID, http.StatusNotFound)
+	}
+
+	status, err := clients.NewAuxProxyClient(addr).ShowBuildUpdate()
+	if err != nil {
+		tLogger.Errorf("[instance build service] failed to show build update on instance %s for %v",
+			instanceID, err)
+		return nil, errors.NewShowInstanceBuildUpdateError(instanceID, err.Error(), http.StatusInternalServerError)
+	}
+	return &apis.InstanceBuildUpdateResponse{InstanceID: instanceID, BuildUpdateStatus: *status}, nil
+}
@@ -0,0 +1,36 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 实例资源指标服务
+package services
+
+import (
+	"net/http"
+
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/apis"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/metrics"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/utils/errors"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/utils/log"
+)
+
+// ReportInstanceMetrics 将auxproxy上报的实例CPU和内存使用率写入influxdb, 供伸缩策略按伸缩组聚合
+func ReportInstanceMetrics(instanceID string, req *apis.ReportInstanceMetricsRequest,
+	tLogger *log.FMLogger) *errors.ErrorResp {
+	metricClient := metrics.GetMetricClient()
+	if metricClient == nil {
+		tLogger.Errorf("[instance metrics service] metric client is not ready")
+		return errors.NewReportInstanceMetricsError(instanceID, "metric client is not ready",
+			http.StatusServiceUnavailable)
+	}
+	err := metricClient.WriteInstanceMetrics([]*metrics.InstanceMetric{{
+		InstanceID:        instanceID,
+		ScalingGroupID:    req.ScalingGroupID,
+		FleetID:           req.FleetID,
+		CpuUtilization:    req.CpuUtilization,
+		MemoryUtilization: req.MemoryUtilization,
+	}})
+	if err != nil {
+		tLogger.Errorf("[instance metrics service] failed to write metrics of instance %s for %v", instanceID, err)
+		return errors.NewReportInstanceMetricsError(instanceID, err.Error(), http.StatusInternalServerError)
+	}
+	return nil
+}
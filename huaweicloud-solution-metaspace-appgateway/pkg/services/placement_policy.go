@@ -0,0 +1,101 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// fleet放置策略缓存
+package services
+
+import (
+	"sync"
+	"time"
+
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/apis"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/services/stragegy"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/utils/clients"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/utils/log"
+)
+
+const (
+	placementPolicyTTL = 60 * time.Second
+)
+
+// placementFetchTimeout 首次获取放置策略时最多等待的时间, 避免AASS响应慢时长时间占用fleet分配锁
+var placementFetchTimeout = 2 * time.Second
+
+// getInstanceConfiguration 从AASS获取伸缩组的实例配置
+var getInstanceConfiguration = func(sgID string) (*apis.InstanceConfiguration, error) {
+	return clients.AASSClient.GetInstanceConfiguration(sgID)
+}
+
+type placementItem struct {
+	placement stragegy.Placement
+	expireAt  time.Time
+}
+
+// placementPolicyCache 按fleet缓存放置策略, 避免每次分配都请求AASS;
+// 请求AASS在锁外进行, 同一fleet同时只有一个请求
+type placementPolicyCache struct {
+	items    map[string]placementItem
+	fetching map[string]chan struct{}
+	mu       sync.Mutex
+}
+
+func newPlacementPolicyCache() *placementPolicyCache {
+	return &placementPolicyCache{
+		items:    make(map[string]placementItem),
+		fetching: make(map[string]chan struct{}),
+	}
+}
+
+// Placement 获取fleet的放置策略; 缓存过期时沿用旧策略并在后台刷新,
+// 没有缓存时最多等待placementFetchTimeout, 超时或获取失败时使用PACK
+func (c *placementPolicyCache) Placement(fleetID string, scalingGroupID string) stragegy.Placement {
+	c.mu.Lock()
+	item, ok := c.items[fleetID]
+	if ok && time.Now().Before(item.expireAt) {
+		c.mu.Unlock()
+		return item.placement
+	}
+	done, fetching := c.fetching[fleetID]
+	if !fetching {
+		done = make(chan struct{})
+		c.fetching[fleetID] = done
+		go c.refresh(fleetID, scalingGroupID, done)
+	}
+	c.mu.Unlock()
+	if ok {
+		return item.placement
+	}
+
+	select {
+	case <-done:
+	case <-time.After(placementFetchTimeout):
+	}
+	c.mu.Lock()
+	item, ok = c.items[fleetID]
+	c.mu.Unlock()
+	if !ok {
+		return stragegy.NewPlacement(nil)
+	}
+	return item.placement
+}
+
+// refresh 从AASS获取fleet的放置策略并更新缓存; 获取失败时沿用上一次的策略, 没有时使用PACK
+func (c *placementPolicyCache) refresh(fleetID string, scalingGroupID string, done chan struct{}) {
+	defer close(done)
+	itc, err := getInstanceConfiguration(scalingGroupID)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.fetching, fleetID)
+	item, ok := c.items[fleetID]
+	if err != nil {
+		log.RunLogger.Errorf("[dispatch] failed to get instance configuration of scaling group %s "+
+			"for fleet %s: %v", scalingGroupID, fleetID, err)
+		if !ok {
+			item.placement = stragegy.NewPlacement(nil)
+		}
+	} else {
+		item.placement = stragegy.NewPlacement(itc.RuntimeConfiguration.PlacementPolicy)
+	}
+	item.expireAt = time.Now().Add(placementPolicyTTL)
+	c.items[fleetID] = item
+}
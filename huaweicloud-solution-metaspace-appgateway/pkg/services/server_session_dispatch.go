@@ -1,82 +1,192 @@
 package services
 
 import (
+	"fmt"
+	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/config"
 	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/apis"
 	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/common"
 	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/models"
+	app_process "codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/models/appprocess"
 	server_session "codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/models/serversession"
 	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/services/stragegy"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/utils/errors"
 	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/utils/log"
 )
 
 const (
 	roundThreshold = 1000
+	// dispatchRetryInterval 上一轮有server session分配成功且仍有等待分配的server session时的重试间隔,
+	// 一轮没有分配成功时间隔逐轮翻倍, 最长为dispatchScanInterval
+	dispatchRetryInterval = 1 * time.Second
+	// dispatchScanInterval 没有通知时的兜底扫描间隔
+	dispatchScanInterval = 5 * time.Second
 )
 
+// pendingServerSessionStore 分配使用的server session存储
+type pendingServerSessionStore interface {
+	ListPendingServerSessions(sort string, offset, limit int) (*[]server_session.ServerSession, error)
+	UpdatePendingState(ss *server_session.ServerSession) (int64, error)
+}
+
 type ServerSessionDispatcher struct {
 	dispatcher *stragegy.BatchDispatch
 	roundCount int32
 	mu         sync.Mutex
+
+	// store place activate 在Work中使用数据库和auxproxy初始化, 测试时替换为内存实现
+	store pendingServerSessionStore
+	// place 将server session分配给进程并置为ACTIVATING
+	place func(ss *server_session.ServerSession, ap *app_process.AppProcess) error
+	// activate 通知auxproxy激活已分配的server session
+	activate func(ap *app_process.AppProcess, ss *server_session.ServerSession)
 }
 
 func (d *ServerSessionDispatcher) Work(stopCh chan struct{}) {
-	d.dispatcher = stragegy.NewBatchDispatch()
+	d.dispatcher = stragegy.NewBatchDispatch(newPlacementPolicyCache().Placement, nil)
+	d.store = server_session.NewServerSessionDao(models.MySqlOrm)
+	d.place = models.DispatchServerSession2Process
+	d.activate = activateDispatchedServerSession
 	d.roundCount = 0
+	serverSessionDispatchNotifier.setLocalActive(true)
+	if config.GlobalConfig.DeployModel != config.DeployModelSingleton {
+		go serverSessionDispatchNotifier.watchRemote(stopCh)
+	}
 	go d.work(stopCh)
 }
 
 func (d *ServerSessionDispatcher) work(stopCh chan struct{}) {
+	runDispatchLoop(stopCh, serverSessionDispatchNotifier.wakeCh, d.executeOneDispatchRound)
+	serverSessionDispatchNotifier.setLocalActive(false)
+	d.dispatcher.Clear()
+}
+
+// runDispatchLoop 收到唤醒通知后立即执行一轮分配, 周期扫描只作为通知丢失时的兜底;
+// round返回是否仍有等待分配的server session以及本轮是否有server session分配成功,
+// 等待分配的server session无法放置时退避重试, 新建会话或容量变化的通知会立即唤醒
+func runDispatchLoop(stopCh chan struct{}, wakeCh <-chan struct{}, round func() (pending bool, placed bool)) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	backoff := dispatchRetryInterval
 	for {
 		select {
 		case <-stopCh:
-			d.dispatcher.Clear()
 			return
-		default:
-			d.executeOneDispatchRound()
+		case <-wakeCh:
+			backoff = dispatchRetryInterval
+		case <-timer.C:
 		}
+
+		interval := dispatchScanInterval
+		pending, placed := round()
+		switch {
+		case pending && placed:
+			backoff = dispatchRetryInterval
+			interval = dispatchRetryInterval
+		case pending:
+			interval = backoff
+			backoff = nextDispatchBackoff(backoff)
+		}
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(interval)
 	}
 }
 
-func (d *ServerSessionDispatcher) executeOneDispatchRound() {
-	// 获取所有server session
-	serverSessionDao := server_session.NewServerSessionDao(models.MySqlOrm)
-	// 先到先处理，按创建时间升序获取
-	sssDB, err := serverSessionDao.ListByState1(common.ServerSessionStateCreating, common.ASCSort, 0, 10000)
+// nextDispatchBackoff 退避间隔翻倍, 不超过兜底扫描间隔
+func nextDispatchBackoff(backoff time.Duration) time.Duration {
+	backoff *= 2
+	if backoff > dispatchScanInterval {
+		return dispatchScanInterval
+	}
+	return backoff
+}
+
+// executeOneDispatchRound 执行一轮分配, 返回本轮是否有等待分配的server session以及是否有server session分配成功
+func (d *ServerSessionDispatcher) executeOneDispatchRound() (bool, bool) {
+	// 先到先处理，按创建时间升序获取, 包含排队中的server session
+	sssDB, err := d.store.ListPendingServerSessions(common.ASCSort, 0, 10000)
 	if err != nil {
-		log.RunLogger.Errorf("[dispatch] failed to get server sessions with "+ " for %v", err)
-		return
+		log.RunLogger.Errorf("[dispatch] failed to get pending server sessions for %v", err)
+		return true, false
+	}
+
+	// 按fleet分组, 组内保持创建顺序
+	var fleetIDs []string
+	fleetSessions := make(map[string][]server_session.ServerSession)
+	for _, ssDB := range *sssDB {
+		if _, ok := fleetSessions[ssDB.FleetID]; !ok {
+			fleetIDs = append(fleetIDs, ssDB.FleetID)
+		}
+		fleetSessions[ssDB.FleetID] = append(fleetSessions[ssDB.FleetID], ssDB)
 	}
 
 	log.RunLogger.Debugf("[dispatch] start to dispatch %d server session", len(*sssDB))
+	var placed int32
 	wg := sync.WaitGroup{}
-	wg.Add(len(*sssDB))
-	for _, ssDB := range *sssDB {
-		go d.dispatchOneProcess(ssDB, serverSessionDao, &wg)
+	wg.Add(len(fleetIDs))
+	for _, fleetID := range fleetIDs {
+		go d.dispatchFleet(fleetSessions[fleetID], &placed, &wg)
 	}
 	wg.Wait()
 	d.monitor()
-	time.Sleep(1 * time.Second)
+	return len(*sssDB) > 0, atomic.LoadInt32(&placed) > 0
 }
 
-func (d *ServerSessionDispatcher) dispatchOneProcess(ssDB server_session.ServerSession, 
-	serverSessionDao *server_session.ServerSessionDao, wg *sync.WaitGroup) {
+// dispatchFleet 按先到先得的顺序为同一fleet的server session分配进程,
+// 相同标签选择器的server session在前者等待时不会被后来者抢占
+func (d *ServerSessionDispatcher) dispatchFleet(sssDB []server_session.ServerSession, placed *int32,
+	wg *sync.WaitGroup) {
 	defer wg.Done()
-	// 获取可用process
-	dispatchProcess, err := d.dispatcher.Pick(ssDB.FleetID)
-	if err != nil {
-		log.RunLogger.Errorf("[dispatch] get available process by fleet id "+
-			"%s for %s failed because %v", ssDB.FleetID, ssDB.ID, err)
-		ssDB.State = common.ServerSessionStateError
-		ssDB.StateReason = err.Error()
-		_, err := serverSessionDao.Update(&ssDB)
+	placeWg := sync.WaitGroup{}
+	blocked := make(map[string]bool)
+	for _, ssDB := range sssDB {
+		if ssDB.State == common.ServerSessionStateQueued && placementExpired(&ssDB, time.Now()) {
+			d.failPendingServerSession(&ssDB, placementTimeoutReason(&ssDB))
+			continue
+		}
+		if blocked[ssDB.LabelSelector] {
+			d.handleUnplaced(ssDB, &stragegy.NoAvailableProcessError{FleetID: ssDB.FleetID})
+			continue
+		}
+
+		// 获取可用process
+		dispatchProcess, err := d.dispatcher.Pick(ssDB.FleetID, apis.ParseLabelSelector(ssDB.LabelSelector))
 		if err != nil {
-			log.RunLogger.Errorf("[dispatch] failed to update error server session %s to db, for %v", ssDB.ID, err)
+			log.RunLogger.Errorf("[dispatch] get available process by fleet id "+
+				"%s for %s failed because %v", ssDB.FleetID, ssDB.ID, err)
+			switch err.(type) {
+			case *stragegy.NoAvailableProcessError:
+				blocked[ssDB.LabelSelector] = true
+				d.handleUnplaced(ssDB, err)
+			case *stragegy.NoMatchingProcessError:
+				e := errors.NewNoMatchingProcessError(ssDB.FleetID, http.StatusBadRequest)
+				d.failPendingServerSession(&ssDB, fmt.Sprintf("%s: %s", e.ErrorCode, e.ErrorMsg))
+			default:
+				d.failPendingServerSession(&ssDB, err.Error())
+			}
+			continue
 		}
-		return
+
+		placeWg.Add(1)
+		go d.dispatchOneProcess(ssDB, dispatchProcess, placed, &placeWg)
 	}
+	placeWg.Wait()
+}
+
+func (d *ServerSessionDispatcher) dispatchOneProcess(ssDB server_session.ServerSession,
+	dispatchProcess *stragegy.Process, placed *int32, wg *sync.WaitGroup) {
+	defer wg.Done()
+	// 入库失败的实例倾向剔除
+	defer d.dispatcher.FinishHandleDispatch(dispatchProcess)
 
 	log.RunLogger.Infof("[dispatch] success pick process %v for server session %s in fleetID %s",
 		dispatchProcess.AppProcess.ID, ssDB.ID, ssDB.FleetID)
@@ -88,37 +198,83 @@ func (d *ServerSessionDispatcher) dispatchOneProcess(ssDB server_session.ServerS
 	ssDB.ClientPort = dispatchProcess.AppProcess.ClientPort
 	ssDB.PID = dispatchProcess.AppProcess.PID
 	ssDB.State = common.ServerSessionStateActivating
+	ssDB.StateReason = ""
 	ssDB.ProtectionPolicy = dispatchProcess.AppProcess.NewServerSessionProtectionPolicy
 	ssDB.ProtectionTimeLimitMinutes = dispatchProcess.AppProcess.ServerSessionProtectionTimeLimitMinutes
 	ssDB.ActivationTimeoutSeconds = dispatchProcess.AppProcess.ServerSessionActivationTimeoutSeconds
+	ssDB.PlacedAt = time.Now()
 
 	// 执行事务
-	err = models.DispatchServerSession2Process(&ssDB, dispatchProcess.AppProcess)
+	err := d.place(&ssDB, dispatchProcess.AppProcess)
 	if err != nil {
 		log.RunLogger.Errorf("[dispatch] failed to dispatch server session %s to process %s in fleetID %s "+
 			"because %v", ssDB.ID, dispatchProcess.AppProcess.ID, ssDB.FleetID, err)
-		ssDB.State = common.ServerSessionStateError
-		ssDB.StateReason = err.Error()
-		_, err := serverSessionDao.Update(&ssDB)
-		if err != nil {
-			log.RunLogger.Errorf("[dispatch] failed to update error server session %s to db, for %v", ssDB.ID, err)
+		switch err {
+		case models.ErrServerSessionNotPending:
+			// 已被取消, 无需处理
+		case models.ErrNoAvailableProcess:
+			d.handleUnplaced(ssDB, err)
+		default:
+			d.failPendingServerSession(&ssDB, err.Error())
 		}
-		// 入库失败的实例倾向剔除
-		d.dispatcher.FinishHandleDispatch(dispatchProcess)
 		return
 	}
 
-	go func() {
-		ss := apis.TransferSSFromModel2Api(&ssDB)
-		err = ActivateServerSession(dispatchProcess.AppProcess, &ssDB, ss, log.RunLogger)
-		if err != nil {
-			log.RunLogger.Errorf("[dispatch] activate server session error %v", err)
-			return
-		}
-	}()
+	atomic.AddInt32(placed, 1)
+	go d.activate(dispatchProcess.AppProcess, &ssDB)
 	log.RunLogger.Infof("[dispatch] success dispatch server session %s to process %s in fleetID %s",
 		ssDB.ID, dispatchProcess.AppProcess.ID, ssDB.FleetID)
-	d.dispatcher.FinishHandleDispatch(dispatchProcess)
+}
+
+func activateDispatchedServerSession(ap *app_process.AppProcess, ssDB *server_session.ServerSession) {
+	ss := apis.TransferSSFromModel2Api(ssDB)
+	if err := ActivateServerSession(ap, ssDB, ss, log.RunLogger); err != nil {
+		log.RunLogger.Errorf("[dispatch] activate server session error %v", err)
+	}
+}
+
+// handleUnplaced 暂无可用进程时, 设置了放置超时时间的server session排队等待扩容, 否则直接失败
+func (d *ServerSessionDispatcher) handleUnplaced(ssDB server_session.ServerSession, cause error) {
+	if ssDB.PlacementTimeoutSeconds <= 0 {
+		d.failPendingServerSession(&ssDB, cause.Error())
+		return
+	}
+	if placementExpired(&ssDB, time.Now()) {
+		d.failPendingServerSession(&ssDB, placementTimeoutReason(&ssDB))
+		return
+	}
+	if ssDB.State == common.ServerSessionStateQueued {
+		return
+	}
+
+	ssDB.State = common.ServerSessionStateQueued
+	ssDB.StateReason = "waiting for an available process"
+	if _, err := d.store.UpdatePendingState(&ssDB); err != nil {
+		log.RunLogger.Errorf("[dispatch] failed to queue server session %s, for %v", ssDB.ID, err)
+		return
+	}
+	log.RunLogger.Infof("[dispatch] server session %s in fleet %s is queued for %d seconds",
+		ssDB.ID, ssDB.FleetID, ssDB.PlacementTimeoutSeconds)
+}
+
+// failPendingServerSession 将等待分配的server session置为ERROR, 已被取消的不受影响
+func (d *ServerSessionDispatcher) failPendingServerSession(ssDB *server_session.ServerSession, reason string) {
+	ssDB.State = common.ServerSessionStateError
+	ssDB.StateReason = reason
+	if _, err := d.store.UpdatePendingState(ssDB); err != nil {
+		log.RunLogger.Errorf("[dispatch] failed to update error server session %s to db, for %v", ssDB.ID, err)
+	}
+}
+
+// placementExpired 判断server session是否已超过放置超时时间
+func placementExpired(ssDB *server_session.ServerSession, now time.Time) bool {
+	return ssDB.PlacementTimeoutSeconds > 0 &&
+		now.Sub(ssDB.CreatedAt) >= time.Duration(ssDB.PlacementTimeoutSeconds)*time.Second
+}
+
+func placementTimeoutReason(ssDB *server_session.ServerSession) string {
+	e := errors.NewPlacementTimeoutError(ssDB.FleetID, ssDB.PlacementTimeoutSeconds, http.StatusConflict)
+	return fmt.Sprintf("%s: %s", e.ErrorCode, e.ErrorMsg)
 }
 
 func (d *ServerSessionDispatcher) monitor() {
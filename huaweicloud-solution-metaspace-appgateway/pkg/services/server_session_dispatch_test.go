@@ -0,0 +1,386 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 服务端会话分配测试
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/apis"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/common"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/models"
+	app_process "codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/models/appprocess"
+	server_session "codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/models/serversession"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/services/stragegy"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/utils/log"
+)
+
+func TestPlacementExpired(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		timeout  int
+		age      time.Duration
+		expected bool
+	}{
+		{
+			name:     "no placement timeout never expires",
+			timeout:  0,
+			age:      time.Hour,
+			expected: false,
+		},
+		{
+			name:     "within placement timeout",
+			timeout:  60,
+			age:      59 * time.Second,
+			expected: false,
+		},
+		{
+			name:     "placement timeout reached",
+			timeout:  60,
+			age:      60 * time.Second,
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ss := &server_session.ServerSession{PlacementTimeoutSeconds: tt.timeout, CreatedAt: now.Add(-tt.age)}
+			assert.Equal(t, tt.expected, placementExpired(ss, now))
+		})
+	}
+}
+
+func TestPlacementPolicyCacheSlowFetch(t *testing.T) {
+	oldLogger := log.RunLogger
+	log.RunLogger = log.NewNopLogger()
+	oldGet := getInstanceConfiguration
+	oldTimeout := placementFetchTimeout
+	defer func() {
+		log.RunLogger = oldLogger
+		getInstanceConfiguration = oldGet
+		placementFetchTimeout = oldTimeout
+	}()
+
+	release := make(chan struct{})
+	var calls int32
+	getInstanceConfiguration = func(string) (*apis.InstanceConfiguration, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return &apis.InstanceConfiguration{}, nil
+	}
+	placementFetchTimeout = 50 * time.Millisecond
+
+	c := newPlacementPolicyCache()
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NotNil(t, c.Placement("fleet", "sg"))
+		}()
+	}
+	wg.Wait()
+	// 慢请求不阻塞调用方, 同一fleet只请求一次
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// 其他fleet的请求不受影响
+	assert.NotNil(t, c.Placement("other", "sg"))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	close(release)
+	assert.Eventually(t, func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		_, ok := c.items["fleet"]
+		return ok && len(c.fetching) == 0
+	}, time.Second, 10*time.Millisecond)
+	assert.NotNil(t, c.Placement("fleet", "sg"))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestRunDispatchLoopWakeUp(t *testing.T) {
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	n := newDispatchNotifier()
+	n.setLocalActive(true)
+	rounds := make(chan struct{}, 10)
+	go runDispatchLoop(stopCh, n.wakeCh, func() (bool, bool) {
+		rounds <- struct{}{}
+		return false, false
+	})
+
+	// 启动时立即执行一轮
+	<-rounds
+	n.notify()
+	select {
+	case <-rounds:
+	case <-time.After(dispatchScanInterval / 2):
+		t.Fatal("dispatch round is not woken up by notification")
+	}
+}
+
+func TestNextDispatchBackoff(t *testing.T) {
+	backoff := dispatchRetryInterval
+	var got []time.Duration
+	for i := 0; i < 5; i++ {
+		backoff = nextDispatchBackoff(backoff)
+		got = append(got, backoff)
+	}
+	assert.Equal(t, []time.Duration{2 * time.Second, 4 * time.Second, dispatchScanInterval,
+		dispatchScanInterval, dispatchScanInterval}, got)
+}
+
+func TestRunDispatchLoopBackoff(t *testing.T) {
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	wakeCh := make(chan struct{}, 1)
+	rounds := make(chan time.Time, 10)
+	go runDispatchLoop(stopCh, wakeCh, func() (bool, bool) {
+		rounds <- time.Now()
+		// 始终有等待分配的server session但无法放置
+		return true, false
+	})
+
+	first := <-rounds
+	second := <-rounds
+	third := <-rounds
+	assert.GreaterOrEqual(t, int64(second.Sub(first)), int64(dispatchRetryInterval))
+	// 没有分配成功时重试间隔翻倍
+	assert.GreaterOrEqual(t, int64(third.Sub(second)), int64(2*dispatchRetryInterval))
+
+	// 容量变化的通知立即唤醒
+	wakeCh <- struct{}{}
+	select {
+	case <-rounds:
+	case <-time.After(dispatchRetryInterval):
+		t.Fatal("dispatch round is not woken up during backoff")
+	}
+}
+
+func TestDispatchNotifierRemote(t *testing.T) {
+	var seq int64
+	oldBump, oldGetSeq := bumpDispatchNotification, getDispatchNotificationSeq
+	defer func() {
+		bumpDispatchNotification, getDispatchNotificationSeq = oldBump, oldGetSeq
+	}()
+	bumpDispatchNotification = func() error {
+		atomic.AddInt64(&seq, 1)
+		return nil
+	}
+	getDispatchNotificationSeq = func() (int64, error) {
+		return atomic.LoadInt64(&seq), nil
+	}
+
+	// 分配器不在本实例时通过通知表唤醒
+	sender := newDispatchNotifier()
+	holder := newDispatchNotifier()
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go holder.watchRemote(stopCh)
+	time.Sleep(2 * notificationPollInterval)
+
+	sender.notify()
+	assert.Empty(t, sender.wakeCh)
+	select {
+	case <-holder.wakeCh:
+	case <-time.After(10 * notificationPollInterval):
+		t.Fatal("holder is not woken up by remote notification")
+	}
+}
+
+func TestDispatchNotifierCoalesceBumps(t *testing.T) {
+	var bumps int32
+	entered := make(chan struct{}, 10)
+	release := make(chan struct{})
+	oldBump := bumpDispatchNotification
+	defer func() { bumpDispatchNotification = oldBump }()
+	bumpDispatchNotification = func() error {
+		atomic.AddInt32(&bumps, 1)
+		entered <- struct{}{}
+		<-release
+		return nil
+	}
+
+	n := newDispatchNotifier()
+	n.notify()
+	<-entered
+	// 写通知表期间的通知合并为下一次写入
+	for i := 0; i < 10; i++ {
+		n.notify()
+	}
+	close(release)
+	<-entered
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&n.pendingBumps) == 0 },
+		time.Second, time.Millisecond)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&bumps))
+}
+
+// memServerSessionStore 内存中的server session存储, 分配成功时通知等待方
+type memServerSessionStore struct {
+	mu      sync.Mutex
+	pending []server_session.ServerSession
+	placed  map[string]chan struct{}
+}
+
+func newMemServerSessionStore() *memServerSessionStore {
+	return &memServerSessionStore{placed: make(map[string]chan struct{})}
+}
+
+func (s *memServerSessionStore) create(ss server_session.ServerSession) <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	done := make(chan struct{})
+	s.pending = append(s.pending, ss)
+	s.placed[ss.ID] = done
+	return done
+}
+
+func (s *memServerSessionStore) ListPendingServerSessions(sort string, offset,
+	limit int) (*[]server_session.ServerSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sssDB := append([]server_session.ServerSession{}, s.pending...)
+	return &sssDB, nil
+}
+
+func (s *memServerSessionStore) UpdatePendingState(ss *server_session.ServerSession) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.pending {
+		if s.pending[i].ID == ss.ID {
+			s.pending[i] = *ss
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func (s *memServerSessionStore) place(ss *server_session.ServerSession, ap *app_process.AppProcess) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.pending {
+		if s.pending[i].ID == ss.ID {
+			s.pending = append(s.pending[:i], s.pending[i+1:]...)
+			close(s.placed[ss.ID])
+			delete(s.placed, ss.ID)
+			return nil
+		}
+	}
+	return models.ErrServerSessionNotPending
+}
+
+func newBenchmarkDispatcher(store *memServerSessionStore) *ServerSessionDispatcher {
+	var processes []*app_process.AppProcess
+	for i := 0; i < 4; i++ {
+		processes = append(processes, &app_process.AppProcess{
+			ID:                  fmt.Sprintf("process-%d", i),
+			InstanceID:          fmt.Sprintf("instance-%d", i),
+			FleetID:             "fleet-1",
+			MaxServerSessionNum: math.MaxInt32,
+		})
+	}
+	return &ServerSessionDispatcher{
+		dispatcher: stragegy.NewBatchDispatch(nil, func(string) ([]*app_process.AppProcess, error) {
+			return processes, nil
+		}),
+		store:    store,
+		place:    store.place,
+		activate: func(*app_process.AppProcess, *server_session.ServerSession) {},
+	}
+}
+
+// benchmarkPlacementLatency 并发创建server session, 统计从创建到分配为ACTIVATING的延迟;
+// sender为创建server session的实例上的通知器, 与wakeCh不在同一实例时经通知表唤醒
+func benchmarkPlacementLatency(b *testing.B, sender *dispatchNotifier, wakeCh <-chan struct{}, stopCh chan struct{}) {
+	oldLogger := log.RunLogger
+	log.RunLogger = log.NewNopLogger()
+	defer func() { log.RunLogger = oldLogger }()
+
+	store := newMemServerSessionStore()
+	d := newBenchmarkDispatcher(store)
+	go runDispatchLoop(stopCh, wakeCh, d.executeOneDispatchRound)
+
+	var (
+		mu        sync.Mutex
+		latencies []time.Duration
+		seq       int64
+	)
+	b.SetParallelism(8)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		var local []time.Duration
+		for pb.Next() {
+			createdAt := time.Now()
+			placed := store.create(server_session.ServerSession{
+				ID:        fmt.Sprintf("server-session-%d", atomic.AddInt64(&seq, 1)),
+				FleetID:   "fleet-1",
+				State:     common.ServerSessionStateCreating,
+				CreatedAt: createdAt,
+			})
+			sender.notify()
+			<-placed
+			local = append(local, time.Since(createdAt))
+		}
+		mu.Lock()
+		latencies = append(latencies, local...)
+		mu.Unlock()
+	})
+	b.StopTimer()
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	b.ReportMetric(float64(latencies[len(latencies)/2].Nanoseconds()), "p50-ns")
+	b.ReportMetric(float64(latencies[len(latencies)*99/100].Nanoseconds()), "p99-ns")
+}
+
+// BenchmarkDispatchPlacementLatency 分配器在本实例时, 创建到ACTIVATING的延迟
+func BenchmarkDispatchPlacementLatency(b *testing.B) {
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	n := newDispatchNotifier()
+	n.setLocalActive(true)
+	benchmarkPlacementLatency(b, n, n.wakeCh, stopCh)
+}
+
+// BenchmarkDispatchPlacementLatencyRemote 分配器在其他实例时, 经通知表唤醒的创建到ACTIVATING的延迟,
+// 通知表的写入模拟同一行的行锁
+func BenchmarkDispatchPlacementLatencyRemote(b *testing.B) {
+	var (
+		rowLock sync.Mutex
+		seq     int64
+		bumps   int64
+	)
+	oldBump, oldGetSeq := bumpDispatchNotification, getDispatchNotificationSeq
+	defer func() {
+		bumpDispatchNotification, getDispatchNotificationSeq = oldBump, oldGetSeq
+	}()
+	bumpDispatchNotification = func() error {
+		rowLock.Lock()
+		defer rowLock.Unlock()
+		time.Sleep(time.Millisecond)
+		atomic.AddInt64(&bumps, 1)
+		atomic.AddInt64(&seq, 1)
+		return nil
+	}
+	getDispatchNotificationSeq = func() (int64, error) {
+		return atomic.LoadInt64(&seq), nil
+	}
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	sender := newDispatchNotifier()
+	holder := newDispatchNotifier()
+	holder.setLocalActive(true)
+	go holder.watchRemote(stopCh)
+	benchmarkPlacementLatency(b, sender, holder.wakeCh, stopCh)
+	b.ReportMetric(float64(atomic.LoadInt64(&bumps))/float64(b.N), "bumps/op")
+}
@@ -46,6 +46,15 @@ func (s *ServerSessionServiceImpl) CreateServerSession(req *apis.CreateServerSes
 	}
 
 	ssDB, ss := generateApiModelAndDbModel(req, propertiesStr)
+	if req.LabelSelector != nil {
+		selectorStr, err := json.Marshal(req.LabelSelector)
+		if err != nil {
+			tLogger.Errorf("[server session service] marshal label selector failed %v", err)
+			return nil, errors.NewCreateServerSessionError(err.Error(), http.StatusInternalServerError)
+		}
+		ssDB.LabelSelector = string(selectorStr)
+		ss.LabelSelector = req.LabelSelector
+	}
 
 	serverSessionDao := server_session.NewServerSessionDao(models.MySqlOrm)
 
@@ -54,6 +63,7 @@ func (s *ServerSessionServiceImpl) CreateServerSession(req *apis.CreateServerSes
 		tLogger.Errorf("[server session service] failed to insert server session to db error %v", err)
 		return nil, errors.NewCreateServerSessionError(err.Error(), http.StatusInternalServerError)
 	}
+	NotifyServerSessionCreated()
 
 	resp := &apis.CreateServerSessionResponse{ServerSession: ss}
 	return resp, nil
@@ -69,6 +79,7 @@ func generateApiModelAndDbModel(req *apis.CreateServerSessionRequest, properties
 		SessionData:                 req.SessionData,
 		SessionProperties:           string(propertiesStr),
 		MaxClientSessionNum:         *req.MaxClientSessionNum,
+		PlacementTimeoutSeconds:     req.PlacementTimeoutSeconds,
 		ClientSessionCreationPolicy: common.ClientSessionCreationPolicyAcceptAll,
 		WorkNodeID:                  config.GlobalConfig.InstanceName,
 	}
@@ -99,6 +110,7 @@ func generateApiModelAndDbModel(req *apis.CreateServerSessionRequest, properties
 		ProtectionPolicy:            ssDB.ProtectionPolicy,
 		ProtectionTimeLimitMinutes:  ssDB.ProtectionTimeLimitMinutes,
 		ActivationTimeoutSeconds:    ssDB.ActivationTimeoutSeconds,
+		PlacementTimeoutSeconds:     ssDB.PlacementTimeoutSeconds,
 	}
 	return ssDB, ss
 
@@ -149,6 +161,8 @@ func (s *ServerSessionServiceImpl) ShowServerSession(id string, tLogger *log.FML
 		ProtectionTimeLimitMinutes:  ssDB.ProtectionTimeLimitMinutes,
 		ActivationTimeoutSeconds:    ssDB.ActivationTimeoutSeconds,
 		ClientSessionCount:          ssDB.ClientSessionCount,
+		PlacementTimeoutSeconds:     ssDB.PlacementTimeoutSeconds,
+		LabelSelector:               apis.ParseLabelSelector(ssDB.LabelSelector),
 	}
 
 	resp := &apis.ShowServerSessionResponse{ServerSession: ss}
@@ -343,6 +357,7 @@ func (s *ServerSessionServiceImpl) UpdateServerSessionState(id string,
 		if err != nil {
 			return errors.NewUpdateServerSessionStateError(id, err.Error(), http.StatusInternalServerError)
 		}
+		NotifyDispatchCapacityChanged()
 	} else {
 		_, err = serverSessionDao.UpdateStateAndReason(ssDB)
 		if err != nil {
@@ -355,6 +370,42 @@ func (s *ServerSessionServiceImpl) UpdateServerSessionState(id string,
 	return nil
 }
 
+// CancelServerSession 取消尚未分配到进程(CREATING或QUEUED)的server session
+func (s *ServerSessionServiceImpl) CancelServerSession(id string, tLogger *log.FMLogger) *errors.ErrorResp {
+	serverSessionDao := server_session.NewServerSessionDao(models.MySqlOrm)
+
+	ssDB, err := serverSessionDao.GetOneByID(id)
+	if err != nil {
+		tLogger.Errorf("[server session service] failed to get server session with id %s in cancel for %v",
+			id, err)
+		if err == orm.ErrNoRows {
+			return errors.NewServerSessionNotFoundError(id, http.StatusNotFound)
+		}
+		return errors.NewCancelServerSessionError(id, err.Error(), http.StatusInternalServerError)
+	}
+
+	if ssDB.State != common.ServerSessionStateCreating && ssDB.State != common.ServerSessionStateQueued {
+		return errors.NewCancelServerSessionError(id, fmt.Sprintf("server session in state %s cannot be canceled",
+			ssDB.State), http.StatusConflict)
+	}
+
+	ssDB.State = common.ServerSessionStateTerminated
+	ssDB.StateReason = "canceled by user before placement"
+	num, err := serverSessionDao.UpdatePendingState(ssDB)
+	if err != nil {
+		tLogger.Errorf("[server session service] failed to cancel server session %s for %v", id, err)
+		return errors.NewCancelServerSessionError(id, err.Error(), http.StatusInternalServerError)
+	}
+	// 取消与分配并发, 分配已先完成
+	if num == 0 {
+		return errors.NewCancelServerSessionError(id, "server session has already been placed",
+			http.StatusConflict)
+	}
+
+	tLogger.Infof("[server session service] success cancel server session %s", id)
+	return nil
+}
+
 // TerminateAllRelativeResources 设置该ServerSession和所有相关的Client Session的状态为有效终止状态
 func (s *ServerSessionServiceImpl) TerminateAllRelativeResources(id string, tLogger *log.FMLogger) *errors.ErrorResp {
 	err := models.TerminateAllResourcesForServerSession(id, tLogger)
@@ -429,7 +480,12 @@ func ActivateServerSession(apDB *app_process.AppProcess, ssDB *server_session.Se
 	// 启动一个定时器（Process里面的ServerSessionActivationTimeoutSeconds）监控server session的状态变换，如果在规定事件内，
 	// 状态还是Activiting的话，就设置状态为ERROR，并不再接受状态变化
 	ssID := ssDB.ID // 避免ssDB整个逃逸
-	interval := ss.ActivationTimeoutSeconds - int(time.Now().Sub(ssDB.CreatedAt).Seconds())
+	// 排队的server session从分配到进程开始计算激活超时
+	activationStart := ssDB.CreatedAt
+	if ssDB.PlacedAt.After(activationStart) {
+		activationStart = ssDB.PlacedAt
+	}
+	interval := ss.ActivationTimeoutSeconds - int(time.Now().Sub(activationStart).Seconds())
 	if interval < 0 {
 		tLogger.Infof("[server session service] server session %v invalid activation interval, skip set activation timer", ssID)
 		return nil
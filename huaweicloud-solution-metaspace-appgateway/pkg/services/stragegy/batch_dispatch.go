@@ -1,6 +1,7 @@
 package stragegy
 
 import (
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/apis"
 	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/models"
 	app_process "codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/models/appprocess"
 	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/utils/log"
@@ -12,24 +13,59 @@ type Process struct {
 	//如果在处理中的process就不覆盖（分配出去还没入库，又被捞取回来，避免被重新分配出去，导致后者失败,map的获取是不定序的）；如果没在处理中的，可以覆盖（需要考虑server session termianted的释放问题）
 	HandlingCount int64 // 当前处理者数目
 	AppProcess    *app_process.AppProcess
+	Labels        map[string]string // 进程标签, 入缓存时解析
 }
 
+// NoAvailableProcessError fleet下暂时没有空闲的进程, 扩容后可能会有
+type NoAvailableProcessError struct {
+	FleetID string
+}
+
+// Error 实现error接口
+func (e *NoAvailableProcessError) Error() string {
+	return fmt.Sprintf("there is no available process for fleet %s", e.FleetID)
+}
+
+// PlacementResolver 获取fleet的放置策略, scalingGroupID取自fleet下的任一进程
+type PlacementResolver func(fleetID string, scalingGroupID string) Placement
+
+// ProcessLoader 获取fleet下所有可用状态的进程
+type ProcessLoader func(fleetID string) ([]*app_process.AppProcess, error)
+
 type BatchDispatch struct {
 	// fleetID -> process
 	fleetProcessesMap map[string]map[string]*Process
-	fleetLockMap      map[string]*sync.Mutex
-	mu                sync.Mutex
+	// fleetID -> instanceID -> 实例上的服务端会话总数
+	fleetInstanceLoads map[string]map[string]int
+	fleetLockMap       map[string]*sync.Mutex
+	placementOf        PlacementResolver
+	loadProcesses      ProcessLoader
+	mu                 sync.Mutex
 }
 
-func NewBatchDispatch() *BatchDispatch {
+// NewBatchDispatch 新建批量分配器, placementOf为空时所有fleet使用PACK放置策略, loadProcesses为空时从数据库获取进程
+func NewBatchDispatch(placementOf PlacementResolver, loadProcesses ProcessLoader) *BatchDispatch {
+	if placementOf == nil {
+		placementOf = func(string, string) Placement { return &PackPlacement{} }
+	}
+	if loadProcesses == nil {
+		loadProcesses = func(fleetID string) ([]*app_process.AppProcess, error) {
+			return app_process.NewAppProcessDao(models.MySqlOrm).GetAllActiveAppProcessByFleetID(fleetID)
+		}
+	}
 	return &BatchDispatch{
-		fleetLockMap:      make(map[string]*sync.Mutex),
-		fleetProcessesMap: make(map[string]map[string]*Process),
+		fleetLockMap:       make(map[string]*sync.Mutex),
+		fleetProcessesMap:  make(map[string]map[string]*Process),
+		fleetInstanceLoads: make(map[string]map[string]int),
+		placementOf:        placementOf,
+		loadProcesses:      loadProcesses,
 	}
 }
 
-func (b *BatchDispatch) Pick(fleetID string) (*Process, error) {
-	ap, err := b.pickup(fleetID)
+// Pick 选择一个满足标签选择器的可用进程, fleet下没有进程满足标签选择器时返回NoMatchingProcessError,
+// 暂无空闲进程时返回NoAvailableProcessError
+func (b *BatchDispatch) Pick(fleetID string, selector *apis.LabelSelector) (*Process, error) {
+	ap, err := b.pickup(fleetID, selector)
 	if err != nil {
 		return nil, err
 	}
@@ -43,15 +79,17 @@ func (b *BatchDispatch) Clear() {
 		return
 	}
 	b.fleetProcessesMap = make(map[string]map[string]*Process)
+	b.fleetInstanceLoads = make(map[string]map[string]int)
 	b.fleetLockMap = make(map[string]*sync.Mutex)
 }
 
-func (b *BatchDispatch) pickup(fleetID string) (*Process, error) {
+func (b *BatchDispatch) pickup(fleetID string, selector *apis.LabelSelector) (*Process, error) {
 	b.mu.Lock()
 	if b.fleetProcessesMap[fleetID] == nil {
 		if b.fleetProcessesMap[fleetID] == nil {
 			b.fleetLockMap[fleetID] = &sync.Mutex{}
 			b.fleetProcessesMap[fleetID] = make(map[string]*Process, 0)
+			b.fleetInstanceLoads[fleetID] = make(map[string]int)
 		}
 	}
 	b.mu.Unlock()
@@ -59,23 +97,33 @@ func (b *BatchDispatch) pickup(fleetID string) (*Process, error) {
 	b.fleetLockMap[fleetID].Lock()
 	defer b.fleetLockMap[fleetID].Unlock()
 
-	ap := b.fetchOneAvailableAppProcess(fleetID)
+	ap := b.fetchOneAvailableAppProcess(fleetID, selector)
 	if ap == nil {
 		log.RunLogger.Infof("[batch dispatch] fleet %s's process list is empty, start to fetch from db", fleetID)
-		appProcessDao := app_process.NewAppProcessDao(models.MySqlOrm)
-		processesDB, err := appProcessDao.GetAllAvailableAppProcessByFleetID(fleetID)
+		processesDB, err := b.loadProcesses(fleetID)
 		if err != nil {
-			log.RunLogger.Errorf("[batch dispatch] failed to fetch all available process by fleetID %s for %v",
+			log.RunLogger.Errorf("[batch dispatch] failed to fetch all active process by fleetID %s for %v",
 				fleetID, err)
 			return nil, err
 		}
 
+		// 实例负载包含已满的进程, 以数据库为主
+		loads := make(map[string]int)
+		for _, process := range processesDB {
+			loads[process.InstanceID] += process.ServerSessionCount
+		}
+		b.fleetInstanceLoads[fleetID] = loads
+
 		for _, process := range processesDB {
+			if process.ServerSessionCount >= process.MaxServerSessionNum {
+				continue
+			}
 			if _, ok := b.fleetProcessesMap[fleetID][process.ID]; !ok {
 				// 不存在的话，直接入
 				b.fleetProcessesMap[fleetID][process.ID] = &Process{
 					HandlingCount: 0,
 					AppProcess:    process,
+					Labels:        apis.ParseLabels(process.Labels),
 				}
 			} else {
 				// 如果没有已经在处理的程序,就以数据库的为主；如果有在处理的，就不覆盖，避免从数据库捞取回来已经分配出去但还是还没入库的
@@ -83,33 +131,46 @@ func (b *BatchDispatch) pickup(fleetID string) (*Process, error) {
 					b.fleetProcessesMap[fleetID][process.ID] = &Process{
 						HandlingCount: 0,
 						AppProcess:    process,
+						Labels:        apis.ParseLabels(process.Labels),
 					}
 				}
 			}
 		}
-		ap = b.fetchOneAvailableAppProcess(fleetID)
+		ap = b.fetchOneAvailableAppProcess(fleetID, selector)
+		// fleet下还没有进程时等待扩容, 而不是认为标签不匹配
+		if ap == nil && selector != nil && len(processesDB) > 0 && !anyMatchLabelSelector(processesDB, selector) {
+			return nil, &NoMatchingProcessError{FleetID: fleetID}
+		}
 	}
 
 	if ap == nil {
-		return nil, fmt.Errorf("there is no available process for fleet %s", fleetID)
+		return nil, &NoAvailableProcessError{FleetID: fleetID}
 	}
 	ap.AppProcess.ServerSessionCount += 1
 	ap.HandlingCount += 1
+	b.fleetInstanceLoads[fleetID][ap.AppProcess.InstanceID] += 1
 
 	return ap, nil
 }
 
 // 这里要保证这里的数目无论是process还是fleet的维度一定会比外寸的少，这样才不会实际存在但是说没有
-func (b *BatchDispatch) fetchOneAvailableAppProcess(fleetID string) *Process {
+// 按照fleet的放置策略在满足标签选择器的可用进程中选择, 选择结果与map的遍历顺序无关
+func (b *BatchDispatch) fetchOneAvailableAppProcess(fleetID string, selector *apis.LabelSelector) *Process {
 	if len(b.fleetProcessesMap[fleetID]) == 0 {
 		return nil
 	}
+	var candidates []*Process
 	for _, ap := range b.fleetProcessesMap[fleetID] {
-		if ap.AppProcess.ServerSessionCount < ap.AppProcess.MaxServerSessionNum {
-			return ap
+		if ap.AppProcess.ServerSessionCount < ap.AppProcess.MaxServerSessionNum &&
+			MatchLabelSelector(selector, ap.Labels) {
+			candidates = append(candidates, ap)
 		}
 	}
-	return nil
+	if len(candidates) == 0 {
+		return nil
+	}
+	placement := b.placementOf(fleetID, candidates[0].AppProcess.ScalingGroupID)
+	return placement.Select(candidates, b.fleetInstanceLoads[fleetID])
 }
 
 // 不区分最后是否入库成功来进行AppProcess.ServerSessionCount -= 1是因为想以数据库为主；及时把正确的数目刷回来
@@ -126,3 +187,13 @@ func (b *BatchDispatch) FinishHandleDispatch(ap *Process) {
 		delete(b.fleetProcessesMap[ap.AppProcess.FleetID], ap.AppProcess.ID)
 	}
 }
+
+// anyMatchLabelSelector 判断是否有进程满足标签选择器, 不区分进程是否已满
+func anyMatchLabelSelector(processes []*app_process.AppProcess, selector *apis.LabelSelector) bool {
+	for _, process := range processes {
+		if MatchLabelSelector(selector, apis.ParseLabels(process.Labels)) {
+			return true
+		}
+	}
+	return false
+}
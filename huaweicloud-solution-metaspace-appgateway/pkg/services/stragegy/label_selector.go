@@ -0,0 +1,63 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 服务端会话标签选择
+package stragegy
+
+import (
+	"fmt"
+
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/apis"
+)
+
+// NoMatchingProcessError fleet下没有任何ACTIVE进程满足服务端会话的标签选择器
+type NoMatchingProcessError struct {
+	FleetID string
+}
+
+// Error 实现error接口
+func (e *NoMatchingProcessError) Error() string {
+	return fmt.Sprintf("there is no process matches the label selector in fleet %s", e.FleetID)
+}
+
+// MatchLabelSelector 判断进程标签是否满足标签选择器, 选择器为空时总是满足
+func MatchLabelSelector(selector *apis.LabelSelector, labels map[string]string) bool {
+	if selector == nil {
+		return true
+	}
+	for k, v := range selector.MatchLabels {
+		if lv, ok := labels[k]; !ok || lv != v {
+			return false
+		}
+	}
+	for _, r := range selector.MatchExpressions {
+		if !matchRequirement(r, labels) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchRequirement(r apis.LabelSelectorRequirement, labels map[string]string) bool {
+	v, ok := labels[r.Key]
+	switch r.Operator {
+	case apis.LabelSelectorOpIn:
+		return ok && containsString(r.Values, v)
+	case apis.LabelSelectorOpNotIn:
+		return !ok || !containsString(r.Values, v)
+	case apis.LabelSelectorOpExists:
+		return ok
+	case apis.LabelSelectorOpDoesNotExist:
+		return !ok
+	default:
+		return false
+	}
+}
+
+func containsString(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
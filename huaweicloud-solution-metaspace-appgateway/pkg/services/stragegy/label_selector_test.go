@@ -0,0 +1,105 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 标签选择测试
+package stragegy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/apis"
+	app_process "codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/models/appprocess"
+)
+
+func TestMatchLabelSelector(t *testing.T) {
+	labels := map[string]string{"map": "dust", "mode": "ranked"}
+
+	tests := []struct {
+		name     string
+		selector *apis.LabelSelector
+		expected bool
+	}{
+		{
+			name:     "nil selector matches all",
+			selector: nil,
+			expected: true,
+		},
+		{
+			name:     "match labels",
+			selector: &apis.LabelSelector{MatchLabels: map[string]string{"map": "dust"}},
+			expected: true,
+		},
+		{
+			name:     "match labels with different value",
+			selector: &apis.LabelSelector{MatchLabels: map[string]string{"map": "nuke"}},
+			expected: false,
+		},
+		{
+			name: "in",
+			selector: &apis.LabelSelector{MatchExpressions: []apis.LabelSelectorRequirement{
+				{Key: "mode", Operator: apis.LabelSelectorOpIn, Values: []string{"casual", "ranked"}},
+			}},
+			expected: true,
+		},
+		{
+			name: "not in",
+			selector: &apis.LabelSelector{MatchExpressions: []apis.LabelSelectorRequirement{
+				{Key: "mode", Operator: apis.LabelSelectorOpNotIn, Values: []string{"ranked"}},
+			}},
+			expected: false,
+		},
+		{
+			name: "not in without label",
+			selector: &apis.LabelSelector{MatchExpressions: []apis.LabelSelectorRequirement{
+				{Key: "region", Operator: apis.LabelSelectorOpNotIn, Values: []string{"eu"}},
+			}},
+			expected: true,
+		},
+		{
+			name: "exists and does not exist",
+			selector: &apis.LabelSelector{MatchExpressions: []apis.LabelSelectorRequirement{
+				{Key: "map", Operator: apis.LabelSelectorOpExists},
+				{Key: "gpu", Operator: apis.LabelSelectorOpDoesNotExist},
+			}},
+			expected: true,
+		},
+		{
+			name: "all requirements must match",
+			selector: &apis.LabelSelector{
+				MatchLabels: map[string]string{"map": "dust"},
+				MatchExpressions: []apis.LabelSelectorRequirement{
+					{Key: "gpu", Operator: apis.LabelSelectorOpExists},
+				},
+			},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, MatchLabelSelector(tt.selector, labels))
+		})
+	}
+}
+
+func TestFetchOneAvailableAppProcessWithSelector(t *testing.T) {
+	b := NewBatchDispatch(nil, nil)
+	b.fleetProcessesMap["fleet"] = map[string]*Process{
+		"p1": {AppProcess: &app_process.AppProcess{ID: "p1", InstanceID: "i1", ServerSessionCount: 1,
+			MaxServerSessionNum: 2}, Labels: map[string]string{"map": "dust"}},
+		"p2": {AppProcess: &app_process.AppProcess{ID: "p2", InstanceID: "i2", ServerSessionCount: 0,
+			MaxServerSessionNum: 2}, Labels: map[string]string{"map": "nuke"}},
+		"p3": {AppProcess: &app_process.AppProcess{ID: "p3", InstanceID: "i3", ServerSessionCount: 2,
+			MaxServerSessionNum: 2}, Labels: map[string]string{"map": "inferno"}},
+	}
+
+	ap := b.fetchOneAvailableAppProcess("fleet",
+		&apis.LabelSelector{MatchLabels: map[string]string{"map": "nuke"}})
+	assert.Equal(t, "p2", ap.AppProcess.ID)
+
+	// 满足选择器的进程已满
+	ap = b.fetchOneAvailableAppProcess("fleet",
+		&apis.LabelSelector{MatchLabels: map[string]string{"map": "inferno"}})
+	assert.Nil(t, ap)
+}
@@ -0,0 +1,145 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 服务端会话放置策略
+package stragegy
+
+import (
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/apis"
+)
+
+const (
+	// PlacementStrategyPack 优先放置到最繁忙的实例, 使空闲实例尽快排空以便缩容
+	PlacementStrategyPack = "PACK"
+	// PlacementStrategySpread 优先放置到最空闲的实例
+	PlacementStrategySpread = "SPREAD"
+	// PlacementStrategyPreferred 优先放置到指定的实例或可用区, 其余按照PACK放置
+	PlacementStrategyPreferred = "PREFERRED"
+)
+
+// Placement 从fleet的候选进程中选择一个进程, instanceLoads为各实例上的服务端会话总数;
+// 候选进程均有空闲的服务端会话, 相同输入的选择结果是确定的
+type Placement interface {
+	Select(candidates []*Process, instanceLoads map[string]int) *Process
+}
+
+type placementBuilder func(policy *apis.PlacementPolicy) Placement
+
+var placementBuilders = map[string]placementBuilder{
+	PlacementStrategyPack: func(*apis.PlacementPolicy) Placement {
+		return &PackPlacement{}
+	},
+	PlacementStrategySpread: func(*apis.PlacementPolicy) Placement {
+		return &SpreadPlacement{}
+	},
+	PlacementStrategyPreferred: func(policy *apis.PlacementPolicy) Placement {
+		return NewPreferredPlacement(policy.PreferredInstanceIds, policy.PreferredAvailabilityZones)
+	},
+}
+
+// NewPlacement 根据fleet的放置策略配置新建放置策略, 未配置或策略未知时使用PACK
+func NewPlacement(policy *apis.PlacementPolicy) Placement {
+	if policy == nil {
+		return &PackPlacement{}
+	}
+	builder, ok := placementBuilders[policy.Strategy]
+	if !ok {
+		return &PackPlacement{}
+	}
+	return builder(policy)
+}
+
+// processLess 按照实例负载及进程负载比较两个进程, busier为true时负载高的在前; 负载相同时按照实例ID及进程ID排序
+func processLess(a *Process, b *Process, instanceLoads map[string]int, busier bool) bool {
+	la, lb := instanceLoads[a.AppProcess.InstanceID], instanceLoads[b.AppProcess.InstanceID]
+	if la != lb {
+		return (la > lb) == busier
+	}
+	if a.AppProcess.InstanceID != b.AppProcess.InstanceID {
+		return a.AppProcess.InstanceID < b.AppProcess.InstanceID
+	}
+	ca, cb := a.AppProcess.ServerSessionCount, b.AppProcess.ServerSessionCount
+	if ca != cb {
+		return (ca > cb) == busier
+	}
+	return a.AppProcess.ID < b.AppProcess.ID
+}
+
+func selectFirst(candidates []*Process, less func(a *Process, b *Process) bool) *Process {
+	var picked *Process
+	for _, p := range candidates {
+		if picked == nil || less(p, picked) {
+			picked = p
+		}
+	}
+	return picked
+}
+
+// PackPlacement 优先选择服务端会话最多的实例, 实例内优先选择最繁忙的进程
+type PackPlacement struct {
+}
+
+// Select 进行选择
+func (p *PackPlacement) Select(candidates []*Process, instanceLoads map[string]int) *Process {
+	return selectFirst(candidates, func(a *Process, b *Process) bool {
+		return processLess(a, b, instanceLoads, true)
+	})
+}
+
+// SpreadPlacement 优先选择服务端会话最少的实例, 实例内优先选择最空闲的进程
+type SpreadPlacement struct {
+}
+
+// Select 进行选择
+func (p *SpreadPlacement) Select(candidates []*Process, instanceLoads map[string]int) *Process {
+	return selectFirst(candidates, func(a *Process, b *Process) bool {
+		return processLess(a, b, instanceLoads, false)
+	})
+}
+
+// PreferredPlacement 依次优先选择指定的实例及可用区, 越靠前的优先级越高, 同一优先级内按照PACK选择
+type PreferredPlacement struct {
+	ranks map[string]int
+	zones map[string]int
+	// others 未指定的实例及可用区的优先级, 最低
+	others int
+}
+
+// NewPreferredPlacement 新建优先实例及可用区放置策略, 实例的优先级高于可用区
+func NewPreferredPlacement(instanceIds []string, zones []string) *PreferredPlacement {
+	p := &PreferredPlacement{
+		ranks:  make(map[string]int, len(instanceIds)),
+		zones:  make(map[string]int, len(zones)),
+		others: len(instanceIds) + len(zones),
+	}
+	for i, id := range instanceIds {
+		if _, ok := p.ranks[id]; !ok {
+			p.ranks[id] = i
+		}
+	}
+	for i, zone := range zones {
+		if _, ok := p.zones[zone]; !ok {
+			p.zones[zone] = len(instanceIds) + i
+		}
+	}
+	return p
+}
+
+func (p *PreferredPlacement) rank(ap *Process) int {
+	if r, ok := p.ranks[ap.AppProcess.InstanceID]; ok {
+		return r
+	}
+	if r, ok := p.zones[ap.AppProcess.AvailabilityZone]; ok {
+		return r
+	}
+	return p.others
+}
+
+// Select 进行选择
+func (p *PreferredPlacement) Select(candidates []*Process, instanceLoads map[string]int) *Process {
+	return selectFirst(candidates, func(a *Process, b *Process) bool {
+		if ra, rb := p.rank(a), p.rank(b); ra != rb {
+			return ra < rb
+		}
+		return processLess(a, b, instanceLoads, true)
+	})
+}
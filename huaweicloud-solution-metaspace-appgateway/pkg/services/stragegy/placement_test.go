@@ -0,0 +1,94 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 放置策略测试
+package stragegy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/apis"
+	app_process "codehub-g.huawei.com/videocloud/mediaprocesscenter/application-gateway/pkg/models/appprocess"
+)
+
+func newTestProcess(id string, instanceID string, az string, count int) *Process {
+	return &Process{AppProcess: &app_process.AppProcess{
+		ID:                  id,
+		InstanceID:          instanceID,
+		AvailabilityZone:    az,
+		ServerSessionCount:  count,
+		MaxServerSessionNum: 4,
+	}}
+}
+
+func TestPlacementSelect(t *testing.T) {
+	candidates := []*Process{
+		newTestProcess("p1", "i1", "az1", 1),
+		newTestProcess("p2", "i1", "az1", 2),
+		newTestProcess("p3", "i2", "az2", 0),
+		newTestProcess("p4", "i3", "az2", 1),
+		newTestProcess("p5", "i4", "az3", 0),
+	}
+	loads := map[string]int{"i1": 3, "i2": 3, "i3": 1, "i4": 0}
+
+	tests := []struct {
+		name     string
+		policy   *apis.PlacementPolicy
+		expected string
+	}{
+		{
+			name:     "default policy is pack",
+			policy:   nil,
+			expected: "p2",
+		},
+		{
+			name:     "unknown strategy falls back to pack",
+			policy:   &apis.PlacementPolicy{Strategy: "UNKNOWN"},
+			expected: "p2",
+		},
+		{
+			name:     "pack busiest instance and busiest process",
+			policy:   &apis.PlacementPolicy{Strategy: PlacementStrategyPack},
+			expected: "p2",
+		},
+		{
+			name:     "spread least loaded instance",
+			policy:   &apis.PlacementPolicy{Strategy: PlacementStrategySpread},
+			expected: "p5",
+		},
+		{
+			name: "preferred instance first",
+			policy: &apis.PlacementPolicy{Strategy: PlacementStrategyPreferred,
+				PreferredInstanceIds: []string{"i9", "i3"}, PreferredAvailabilityZones: []string{"az1"}},
+			expected: "p4",
+		},
+		{
+			name: "preferred availability zone packs within the zone",
+			policy: &apis.PlacementPolicy{Strategy: PlacementStrategyPreferred,
+				PreferredAvailabilityZones: []string{"az9", "az2"}},
+			expected: "p3",
+		},
+		{
+			name: "no preference matches falls back to pack",
+			policy: &apis.PlacementPolicy{Strategy: PlacementStrategyPreferred,
+				PreferredInstanceIds: []string{"i9"}},
+			expected: "p2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			placement := NewPlacement(tt.policy)
+			// 选择结果与候选进程的顺序无关
+			for i := 0; i < len(candidates); i++ {
+				rotated := append(append([]*Process{}, candidates[i:]...), candidates[:i]...)
+				assert.Equal(t, tt.expected, placement.Select(rotated, loads).AppProcess.ID)
+			}
+		})
+	}
+}
+
+func TestPlacementSelectEmpty(t *testing.T) {
+	assert.Nil(t, NewPlacement(nil).Select(nil, nil))
+}
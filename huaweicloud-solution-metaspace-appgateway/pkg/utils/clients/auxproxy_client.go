@@ -16,6 +16,7 @@ import (
 
 const (
 	ServerSessionV1Path = "/v1/server-sessions/start"
+	BuildUpdateV1Path   = "/v1/build-update"
 )
 
 type AuxProxyClient struct {
@@ -64,3 +65,58 @@ func (a *AuxProxyClient) StartServerSession(r *apis.ServerSession) error {
 	log.RunLogger.Infof("[auxproxy client] success to stat server session %v request", r.ID)
 	return nil
 }
+
+// StartBuildUpdate 发送应用包原地更新请求, 返回auxproxy的状态码和更新进度
+func (a *AuxProxyClient) StartBuildUpdate(r *apis.AuxProxyBuildUpdateRequest) (int, *apis.BuildUpdateStatus, error) {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	url := fmt.Sprintf("https://%s%s", a.AuxProxyAddr, BuildUpdateV1Path)
+	req, err := NewRequest("POST", url, map[string][]string{}, bytes.NewReader(data))
+	if err != nil {
+		return 0, nil, err
+	}
+
+	code, buf, _, err := DoRequest(a.Cli, req)
+	if err != nil {
+		log.RunLogger.Errorf("[auxproxy client] failed to send build update request for build %s, "+
+			"error %v", r.BuildID, err)
+		return 0, nil, err
+	}
+	if code != http.StatusAccepted {
+		return code, nil, fmt.Errorf("expected status code %d, get status code %d, body %s",
+			http.StatusAccepted, code, buf)
+	}
+
+	status := &apis.BuildUpdateStatus{}
+	if err = json.Unmarshal(buf, status); err != nil {
+		return code, nil, err
+	}
+	return code, status, nil
+}
+
+// ShowBuildUpdate 查询应用包原地更新进度
+func (a *AuxProxyClient) ShowBuildUpdate() (*apis.BuildUpdateStatus, error) {
+	url := fmt.Sprintf("https://%s%s", a.AuxProxyAddr, BuildUpdateV1Path)
+	req, err := NewRequest("GET", url, map[string][]string{}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	code, buf, _, err := DoRequest(a.Cli, req)
+	if err != nil {
+		log.RunLogger.Errorf("[auxproxy client] failed to send show build update request, error %v", err)
+		return nil, err
+	}
+	if code != http.StatusOK {
+		return nil, fmt.Errorf("expected status code %d, get status code %d", http.StatusOK, code)
+	}
+
+	status := &apis.BuildUpdateStatus{}
+	if err = json.Unmarshal(buf, status); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
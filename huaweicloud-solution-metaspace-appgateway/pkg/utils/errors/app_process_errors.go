@@ -35,6 +35,11 @@ func NewUpdateAppProcessStateError(message string, httpCode int) *ErrorResp {
 	return NewError("SCASE.00010105", fmt.Sprintf("Update app process state failed: %s.", message), httpCode)
 }
 
+// NewReportAppProcessMetricsError new report app process metrics error
+func NewReportAppProcessMetricsError(message string, httpCode int) *ErrorResp {
+	return NewError("SCASE.00010107", fmt.Sprintf("Report app process metrics failed: %s.", message), httpCode)
+}
+
 // NewDeleteAppProcessError new delete app process error
 func NewDeleteAppProcessError(message string, httpCode int) *ErrorResp {
 	return NewError("SCASE.00010106", fmt.Sprintf("Update app process state failed: %s.", message), httpCode)
@@ -0,0 +1,23 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 实例应用包原地更新异常定义
+package errors
+
+import "fmt"
+
+// NewStartInstanceBuildUpdateError new start instance build update error
+func NewStartInstanceBuildUpdateError(instanceID, message string, httpCode int) *ErrorResp {
+	return NewError("SCASE.00010500", fmt.Sprintf("Start build update on instance %s failed: %s.",
+		instanceID, message), httpCode)
+}
+
+// NewShowInstanceBuildUpdateError new show instance build update error
+func NewShowInstanceBuildUpdateError(instanceID, message string, httpCode int) *ErrorResp {
+	return NewError("SCASE.00010501", fmt.Sprintf("Show build update on instance %s failed: %s.",
+		instanceID, message), httpCode)
+}
+
+// NewInstanceNotFoundError new instance not found error
+func NewInstanceNotFoundError(instanceID string, httpCode int) *ErrorResp {
+	return NewError("SCASE.00010502", fmt.Sprintf("Instance %s not found.", instanceID), httpCode)
+}
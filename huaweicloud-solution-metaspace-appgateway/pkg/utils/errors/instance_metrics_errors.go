@@ -0,0 +1,12 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 实例资源指标异常定义
+package errors
+
+import "fmt"
+
+// NewReportInstanceMetricsError new report instance metrics error
+func NewReportInstanceMetricsError(instanceID, message string, httpCode int) *ErrorResp {
+	return NewError("SCASE.00010503", fmt.Sprintf("Report metrics of instance %s failed: %s.",
+		instanceID, message), httpCode)
+}
@@ -37,3 +37,21 @@ func NewUpdateServerSessionStateError(id, message string, httpCode int) *ErrorRe
 	return NewError("SCASE.00010205", fmt.Sprintf("Update server session %s state failed: %s",
 		id, message), httpCode)
 }
+
+// NewNoMatchingProcessError 生成一个没有满足标签选择器的进程的错误
+func NewNoMatchingProcessError(fleetID string, httpCode int) *ErrorResp {
+	return NewError("SCASE.00010206", fmt.Sprintf("No process in fleet %s matches the label selector.",
+		fleetID), httpCode)
+}
+
+// NewPlacementTimeoutError 生成一个排队超时仍未分配到进程的错误
+func NewPlacementTimeoutError(fleetID string, timeout int, httpCode int) *ErrorResp {
+	return NewError("SCASE.00010207", fmt.Sprintf("No available process in fleet %s within the placement "+
+		"timeout of %d seconds.", fleetID, timeout), httpCode)
+}
+
+// NewCancelServerSessionError 生成一个取消Server Session失败的错误
+func NewCancelServerSessionError(id, message string, httpCode int) *ErrorResp {
+	return NewError("SCASE.00010208", fmt.Sprintf("Cancel server session %s failed: %s",
+		id, message), httpCode)
+}
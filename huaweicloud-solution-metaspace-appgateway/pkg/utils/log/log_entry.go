@@ -19,6 +19,14 @@ type FMLogger struct {
 	ctx    context.Context
 }
 
+// NewNopLogger 不输出任何日志的logger, 用于测试
+func NewNopLogger() *FMLogger {
+	return &FMLogger{
+		logger: zap.NewNop().Sugar(),
+		ctx:    context.Background(),
+	}
+}
+
 // Debugf print debug message
 func (l *FMLogger) Debugf(format string, a ...interface{}) {
 	l.logger.Debugf(format, a...)
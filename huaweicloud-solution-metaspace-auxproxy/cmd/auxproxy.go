@@ -40,6 +40,20 @@ func init() {
 	flag.BoolVar(&config.Opts.EnableTest, "enable-test-mode", false, "test enable")
 	flag.StringVar(&config.Opts.GCMKey, "gcm-key", "", "gcm decode or encode key")
 	flag.StringVar(&config.Opts.GCMNonce, "gcm-nonce", "", "gcm decode or encode nonce")
+	flag.StringVar(&config.Opts.ProcessLogDir, "process-log-dir", config.ProcessLogDir,
+		"directory of app process stdout and stderr logs")
+	flag.IntVar(&config.Opts.ProcessLogMaxSizeMB, "process-log-max-size", 50,
+		"max size in megabytes of app process log before rotation")
+	flag.IntVar(&config.Opts.ProcessLogMaxBackups, "process-log-max-backups", 3,
+		"max number of rotated app process logs to retain")
+	flag.IntVar(&config.Opts.ProcessLogRetention, "process-log-retention", 60,
+		"minutes to keep stdout and stderr logs of an exited app process before deleting them")
+	flag.IntVar(&config.Opts.ProcessHealthCheckTimeout, "process-health-check-timeout", 5,
+		"timeout in seconds of a single app process health check call")
+	flag.IntVar(&config.Opts.ProcessHealthGracePeriod, "process-health-grace-period", 10,
+		"seconds without heartbeat on the health stream before an app process is considered unhealthy")
+	flag.IntVar(&config.Opts.ProcessDrainTimeout, "process-drain-timeout", 60,
+		"minutes to wait for server sessions to end when draining app processes before cleanup")
 }
 
 // ReturnErr return when err is not nil
@@ -51,6 +65,10 @@ func ReturnErr(err error) {
 }
 
 func main() {
+	// 资源限制包装进程: 施加限制后exec应用进程, 不执行auxproxy的初始化
+	if len(os.Args) > 1 && os.Args[1] == processmanager.LimitedExecArg {
+		processmanager.RunLimitedExec(os.Args[2:])
+	}
 	flag.Parse()
 
 	ReturnErr(log.InitLog())
@@ -69,6 +87,7 @@ func main() {
 
 	// 1. init process manager
 	processmanager.InitProcessManager()
+	buildmanager.RestoreActiveBuild()
 
 	// 2. init config manager
 	_, p, err := net.SplitHostPort(config.Opts.AuxProxyAddr)
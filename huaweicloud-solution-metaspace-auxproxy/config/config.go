@@ -48,6 +48,19 @@ type Options struct {
 	EnableBuild bool
 	EnableTest	bool
 
+	// 应用进程标准输出日志
+	ProcessLogDir        string
+	ProcessLogMaxSizeMB  int
+	ProcessLogMaxBackups int
+	ProcessLogRetention  int // 进程退出后标准输出日志的保留时间，留给日志转储采集，单位分钟
+
+	// 应用进程健康检查
+	ProcessHealthCheckTimeout int // 单次健康检查调用的超时时间，单位秒
+	ProcessHealthGracePeriod  int // 健康流超过该时间没有心跳即认为进程不健康，单位秒
+
+	// 应用进程排空超时时间，超时后强制结束剩余的服务器会话，单位分钟
+	ProcessDrainTimeout int
+
 	GCMKey				string
 	GCMNonce			string
 }
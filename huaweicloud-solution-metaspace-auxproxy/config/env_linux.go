@@ -16,4 +16,11 @@ const (
 	BuildPathPrefix    = "/local/app"
 	DownloadPathPrefix = "/local/download"
 	RunLoggerPath      = "/etc/auxproxy/log/run.log"
+	// 应用进程标准输出日志目录, 需在/local/app下才能被日志清理
+	ProcessLogDir = "/local/app/process-logs"
+	// 应用进程cgroup v2根目录, 每个设置了资源限制的进程在其下创建子cgroup
+	ProcessCgroupRoot = "/sys/fs/cgroup/auxproxy"
 )
+
+// ActiveBuildFilePath 原地更新后生效的应用包, auxproxy重启后从该文件恢复
+const ActiveBuildFilePath = "/local/download/active-build.json"
@@ -17,4 +17,9 @@ const (
 	BuildPathPrefix    = "C:/local/app"
 	DownloadPathPrefix = "C:/download"
 	RunLoggerPath      = "C:/Program Files/auxproxy/log/run.log"
+	// 应用进程标准输出日志目录
+	ProcessLogDir = "C:/local/app/process-logs"
 )
+
+// ActiveBuildFilePath 原地更新后生效的应用包, auxproxy重启后从该文件恢复
+const ActiveBuildFilePath = "C:/download/active-build.json"
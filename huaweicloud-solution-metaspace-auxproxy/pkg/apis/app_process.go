@@ -33,20 +33,22 @@ type AppProcessList struct {
 // Register app process regarding apis
 
 type RegisterAppProcessRequest struct {
-	PID                                     int    `json:"pid" validate:"required,gte=1,lte=32768"`
-	BizPID                                  int    `json:"biz_pid" validate:"required,gte=1,lte=32768"`
-	InstanceID                              string `json:"instance_id" validate:"required,min=1,max=128"`
-	ScalingGroupID                          string `json:"scaling_group_id" validate:"required,min=1,max=128"`
-	FleetID                                 string `json:"fleet_id" validate:"required,min=1,max=128"`
-	PublicIP                                string `json:"ip_address" validate:"required,ip4_addr"`
-	PrivateIP                               string `json:"private_ip" validate:"required,ip4_addr"`
-	AuxProxyPort                            int    `json:"aux_proxy_port" validate:"required,gte=1,lte=65535"`
-	MaxServerSessionNum                     int    `json:"max_server_session_num" validate:"required,gte=1,lte=50"`
-	NewServerSessionProtectionPolicy        string `json:"new_server_session_protection_policy" validate:"required,oneof=NO_PROTECTION FULL_PROTECTION TIME_LIMIT_PROTECTION"`
-	ServerSessionProtectionTimeLimitMinutes int    `json:"server_session_protection_time_limit_minutes" validate:"required,gte=5,lte=1440"`
-	ServerSessionActivationTimeoutSeconds   int    `json:"server_session_activation_timeout_seconds" validate:"required,gte=1,lte=600"`
-	LaunchPath                              string `json:"launch_path" validate:"required,gte=1,lte=2147483647"`
-	Parameters                              string `json:"parameters" validate:"omitempty,min=0,max=2147483647"`
+	PID                                     int               `json:"pid" validate:"required,gte=1,lte=32768"`
+	BizPID                                  int               `json:"biz_pid" validate:"required,gte=1,lte=32768"`
+	InstanceID                              string            `json:"instance_id" validate:"required,min=1,max=128"`
+	AvailabilityZone                        string            `json:"availability_zone,omitempty" validate:"omitempty,max=64"`
+	ScalingGroupID                          string            `json:"scaling_group_id" validate:"required,min=1,max=128"`
+	FleetID                                 string            `json:"fleet_id" validate:"required,min=1,max=128"`
+	PublicIP                                string            `json:"ip_address" validate:"required,ip4_addr"`
+	PrivateIP                               string            `json:"private_ip" validate:"required,ip4_addr"`
+	AuxProxyPort                            int               `json:"aux_proxy_port" validate:"required,gte=1,lte=65535"`
+	MaxServerSessionNum                     int               `json:"max_server_session_num" validate:"required,gte=1,lte=50"`
+	NewServerSessionProtectionPolicy        string            `json:"new_server_session_protection_policy" validate:"required,oneof=NO_PROTECTION FULL_PROTECTION TIME_LIMIT_PROTECTION"`
+	ServerSessionProtectionTimeLimitMinutes int               `json:"server_session_protection_time_limit_minutes" validate:"required,gte=5,lte=1440"`
+	ServerSessionActivationTimeoutSeconds   int               `json:"server_session_activation_timeout_seconds" validate:"required,gte=1,lte=600"`
+	LaunchPath                              string            `json:"launch_path" validate:"required,gte=1,lte=2147483647"`
+	Parameters                              string            `json:"parameters" validate:"omitempty,min=0,max=2147483647"`
+	Labels                                  map[string]string `json:"labels,omitempty"`
 }
 
 type RegisterAppProcessResponse struct {
@@ -59,7 +61,7 @@ type UpdateAppProcessRequest struct {
 	ClientPort int      `json:"port" validate:"required,gte=1,lte=65535"`
 	GrpcPort   int      `json:"grpc_port" validate:"required,gte=1,lte=65535"`
 	LogPath    []string `json:"log_path" validate:"gte=0,lte=100"`
-	State      string   `json:"state" validate:"required,oneof=AVTIVATING ACTIVE TERMINATING TERMINATED ERROR"`
+	State      string   `json:"state" validate:"required,oneof=AVTIVATING ACTIVE TERMINATING TERMINATED ERROR CRASH_LOOP"`
 }
 
 type UpdateAppProcessResponse struct {
@@ -69,13 +71,25 @@ type UpdateAppProcessResponse struct {
 // Update app process state
 
 type UpdateAppProcessStateRequest struct {
-	State string `json:"state" validate:"oneof=AVTIVATING ACTIVE TERMINATING TERMINATED ERROR"`
+	State       string `json:"state" validate:"oneof=AVTIVATING ACTIVE TERMINATING TERMINATED ERROR CRASH_LOOP"`
+	StateReason string `json:"state_reason,omitempty"`
 }
 
 type UpdateAppProcessStateResponse struct {
 	AppProcess AppProcess `json:"app_process"`
 }
 
+// Report app process metrics regarding apis
+
+type ReportAppProcessMetricsRequest struct {
+	Metrics []AppProcessMetrics `json:"metrics"`
+}
+
+type AppProcessMetrics struct {
+	ProcessID string             `json:"process_id"`
+	Values    map[string]float64 `json:"values"`
+}
+
 // Delete app process regarding apis
 
 // Show app process regarding apis
@@ -0,0 +1,31 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 应用包原地更新接口定义
+package apis
+
+import "time"
+
+// UpdateBuildRequest 原地更新应用包, 新的应用包下载到独立目录,
+// SourceType为空时从OBS下载, S3和HTTPS来源从Url下载, LOCAL来源从实例上的Path复制
+type UpdateBuildRequest struct {
+	BuildID                 string `json:"build_id"`
+	SourceType              string `json:"source_type"`
+	Bucket                  string `json:"bucket"`
+	Object                  string `json:"object"`
+	URL                     string `json:"url"`
+	Path                    string `json:"path"`
+	Region                  string `json:"region"`
+	Checksum                string `json:"checksum"`
+	ReadinessTimeoutSeconds int    `json:"readiness_timeout_seconds"`
+}
+
+// BuildUpdateStatus 应用包原地更新进度
+type BuildUpdateStatus struct {
+	BuildID          string    `json:"build_id"`
+	State            string    `json:"state"`
+	Message          string    `json:"message,omitempty"`
+	TotalProcesses   int       `json:"total_processes"`
+	UpdatedProcesses int       `json:"updated_processes"`
+	StartedAt        time.Time `json:"started_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
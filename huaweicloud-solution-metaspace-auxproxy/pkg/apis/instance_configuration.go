@@ -16,9 +16,17 @@ type RuntimeConfiguration struct {
 }
 
 type ProcessConfiguration struct {
-	LaunchPath           string `json:"launch_path"`
-	Parameters           string `json:"parameters"`
-	ConcurrentExecutions int    `json:"concurrent_executions"`
+	LaunchPath           string          `json:"launch_path"`
+	Parameters           string          `json:"parameters"`
+	ConcurrentExecutions int             `json:"concurrent_executions"`
+	ResourceLimits       *ResourceLimits `json:"resource_limits,omitempty"`
+}
+
+// ResourceLimits 单个应用进程的资源限制, 由auxproxy在Linux上通过cgroup v2和rlimit实施
+type ResourceLimits struct {
+	CpuQuotaMillicores int `json:"cpu_quota_millicores,omitempty"`
+	MemoryMaxMB        int `json:"memory_max_mb,omitempty"`
+	MaxOpenFiles       int `json:"max_open_files,omitempty"`
 }
 
 // Show runtime configuration regarding apis
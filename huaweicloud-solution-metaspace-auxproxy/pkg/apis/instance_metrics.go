@@ -0,0 +1,12 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 实例资源指标结构体定义
+package apis
+
+// ReportInstanceMetricsRequest 上报实例在一个上报周期内的CPU和内存使用率, 单位为百分比
+type ReportInstanceMetricsRequest struct {
+	FleetID           string  `json:"fleet_id"`
+	ScalingGroupID    string  `json:"scaling_group_id"`
+	CpuUtilization    float64 `json:"cpu_utilization"`
+	MemoryUtilization float64 `json:"memory_utilization"`
+}
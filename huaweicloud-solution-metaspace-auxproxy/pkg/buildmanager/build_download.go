@@ -0,0 +1,245 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 应用包分段续传下载: 按Range从断点继续下载, 失败后在重试预算内重试, 下载完成后校验SHA-256
+package buildmanager
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"huaweicloud.com/esdk-obs-go/obs/v3"
+)
+
+const (
+	// partFileSuffix 下载中的文件后缀, 下载完成后重命名, 进程重启后从该文件继续下载
+	partFileSuffix = ".part"
+	// signedURLExpireSeconds 下载使用的临时URL有效期, 每次重试重新签名
+	signedURLExpireSeconds = 3600
+)
+
+var (
+	// downloadRetryBudget 下载中断后的最大重试次数
+	downloadRetryBudget = 10
+	// downloadRetryInterval 重试间隔
+	downloadRetryInterval = 5 * time.Second
+	// progressReportInterval 下载进度上报间隔
+	progressReportInterval = 10 * time.Second
+
+	// downloadHttpClient 下载的是实例上直接执行的应用包, 必须校验服务端证书
+	downloadHttpClient = &http.Client{
+		Transport: &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			TLSHandshakeTimeout:   60 * time.Second,
+			ResponseHeaderTimeout: 60 * time.Second,
+			TLSClientConfig:       &tls.Config{MinVersion: tls.VersionTLS12},
+		},
+	}
+)
+
+// signBuildURL 生成应用包对象的临时下载URL, 测试时替换
+var signBuildURL = func(build *BuildInfo, ak, sk string) (string, error) {
+	client, err := obs.New(ak, sk, GetOBSEndpoint(build.Location))
+	if err != nil {
+		return "", err
+	}
+	defer client.Close()
+
+	output, err := client.CreateSignedUrl(&obs.CreateSignedUrlInput{
+		Method:  obs.HttpMethodGet,
+		Bucket:  build.Bucket,
+		Key:     build.ObjectKey,
+		Expires: signedURLExpireSeconds,
+	})
+	if err != nil {
+		return "", err
+	}
+	return output.SignedUrl, nil
+}
+
+// downloadStatusError 下载请求返回的非预期状态码
+type downloadStatusError struct {
+	code int
+}
+
+func (e *downloadStatusError) Error() string {
+	return fmt.Sprintf("unexpected status code %d", e.code)
+}
+
+// retryable 服务端错误和限流可以重试, 鉴权失败和对象不存在等错误直接返回
+func (e *downloadStatusError) retryable() bool {
+	return e.code >= http.StatusInternalServerError || e.code == http.StatusRequestTimeout ||
+		e.code == http.StatusTooManyRequests
+}
+
+// progressFunc 下载进度回调, total未知时为-1
+type progressFunc func(downloaded, total int64)
+
+type progressWriter struct {
+	w          io.Writer
+	downloaded int64
+	total      int64
+	report     progressFunc
+	lastReport time.Time
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.downloaded += int64(n)
+	if time.Since(p.lastReport) >= progressReportInterval {
+		p.lastReport = time.Now()
+		p.report(p.downloaded, p.total)
+	}
+	return n, err
+}
+
+// rangeDownload 下载url到file, 中断后从已下载的位置继续, 超出重试预算后返回最后一次的错误
+func rangeDownload(signURL func() (string, error), file string, report progressFunc) error {
+	var lastErr error
+	for attempt := 0; attempt <= downloadRetryBudget; attempt++ {
+		if attempt > 0 {
+			time.Sleep(downloadRetryInterval)
+		}
+		url, err := signURL()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		err = downloadRange(url, file+partFileSuffix, report)
+		if err == nil {
+			return os.Rename(file+partFileSuffix, file)
+		}
+		lastErr = err
+		if se, ok := err.(*downloadStatusError); ok && !se.retryable() {
+			return err
+		}
+	}
+
+	return fmt.Errorf("download failed after %d retries, last error: %v", downloadRetryBudget, lastErr)
+}
+
+// downloadRange 从part文件的末尾继续下载, 服务端不支持Range时从头下载
+func downloadRange(url string, part string, report progressFunc) error {
+	f, err := os.OpenFile(part, os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	offset, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	rsp, err := downloadHttpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+
+	var total int64 = -1
+	switch rsp.StatusCode {
+	case http.StatusPartialContent:
+		total = parseContentRangeTotal(rsp.Header.Get("Content-Range"))
+	case http.StatusOK:
+		if offset > 0 {
+			if err = f.Truncate(0); err != nil {
+				return err
+			}
+			if offset, err = f.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
+		}
+		if rsp.ContentLength >= 0 {
+			total = rsp.ContentLength
+		}
+	case http.StatusRequestedRangeNotSatisfiable:
+		// 上次已经下载完成但是没有来得及重命名
+		if parseContentRangeTotal(rsp.Header.Get("Content-Range")) == offset {
+			report(offset, offset)
+			return nil
+		}
+		if err = f.Truncate(0); err != nil {
+			return err
+		}
+		return fmt.Errorf("part file of %d bytes is larger than the object", offset)
+	default:
+		return &downloadStatusError{code: rsp.StatusCode}
+	}
+
+	pw := &progressWriter{w: f, downloaded: offset, total: total, report: report, lastReport: time.Now()}
+	report(offset, total)
+	if _, err = io.Copy(pw, rsp.Body); err != nil {
+		return err
+	}
+	if total >= 0 && pw.downloaded != total {
+		return fmt.Errorf("download incomplete, %d of %d bytes", pw.downloaded, total)
+	}
+	report(pw.downloaded, pw.downloaded)
+
+	return nil
+}
+
+// parseContentRangeTotal 解析"bytes 0-99/200"或者"bytes */200"中的总长度, 无法解析时返回-1
+func parseContentRangeTotal(contentRange string) int64 {
+	i := strings.LastIndex(contentRange, "/")
+	if i < 0 {
+		return -1
+	}
+	total, err := strconv.ParseInt(contentRange[i+1:], 10, 64)
+	if err != nil {
+		return -1
+	}
+	return total
+}
+
+// verifyChecksum 校验文件的SHA-256, 不一致时删除文件避免下次直接使用损坏的文件
+// 没有校验值时返回错误, 是否允许不校验由调用方决定
+func verifyChecksum(file string, expected string) error {
+	if expected == "" {
+		return errChecksumMissing
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	h := sha256.New()
+	_, err = io.Copy(h, f)
+	_ = f.Close()
+	if err != nil {
+		return err
+	}
+
+	actual := hex.EncodeToString(h.Sum(nil))
+	if !strings.EqualFold(actual, expected) {
+		_ = os.Remove(file)
+		return &checksumError{expected: expected, actual: actual}
+	}
+	return nil
+}
+
+// errChecksumMissing 应用包没有设置校验值
+var errChecksumMissing = fmt.Errorf("checksum is not set")
+
+// checksumError 应用包校验失败
+type checksumError struct {
+	expected string
+	actual   string
+}
+
+func (e *checksumError) Error() string {
+	return fmt.Sprintf("checksum mismatch, expected %s, actual %s", e.expected, e.actual)
+}
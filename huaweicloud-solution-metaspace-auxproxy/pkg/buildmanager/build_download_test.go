@@ -0,0 +1,142 @@
+package buildmanager
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// fakeOBS 模拟OBS的对象下载, 支持Range请求, 可以在指定的请求中途断开连接或者返回错误码
+type fakeOBS struct {
+	mux        sync.Mutex
+	content    []byte
+	ranges     []string
+	failCodes  []int
+	truncateAt int
+}
+
+func (o *fakeOBS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	o.mux.Lock()
+	o.ranges = append(o.ranges, r.Header.Get("Range"))
+	var code int
+	if len(o.failCodes) > 0 {
+		code, o.failCodes = o.failCodes[0], o.failCodes[1:]
+	}
+	truncateAt := o.truncateAt
+	o.truncateAt = 0
+	o.mux.Unlock()
+
+	if code != 0 {
+		w.WriteHeader(code)
+		return
+	}
+	if truncateAt > 0 {
+		// 声明完整长度但只写入一部分后断开, 模拟网络中断
+		w.Header().Set("Content-Length", strconv.Itoa(len(o.content)))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(o.content[:truncateAt])
+		return
+	}
+	http.ServeContent(w, r, "app.zip", time.Time{}, bytes.NewReader(o.content))
+}
+
+func checksumOf(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestRangeDownload(t *testing.T) {
+	originInterval := downloadRetryInterval
+	originBudget := downloadRetryBudget
+	downloadRetryInterval = 0
+	downloadRetryBudget = 3
+	defer func() {
+		downloadRetryInterval = originInterval
+		downloadRetryBudget = originBudget
+	}()
+
+	content := bytes.Repeat([]byte("metaspace build "), 4096)
+	noReport := func(int64, int64) {}
+
+	Convey("range download test", t, func() {
+		dir, err := ioutil.TempDir("", "build-download")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		file := filepath.Join(dir, "app.zip")
+
+		obs := &fakeOBS{content: content}
+		server := httptest.NewServer(obs)
+		defer server.Close()
+		signURL := func() (string, error) { return server.URL + "/bucket/app.zip", nil }
+
+		Convey("download the whole object and verify checksum", func() {
+			var downloaded, total int64
+			err := rangeDownload(signURL, file, func(d, t int64) { downloaded, total = d, t })
+			So(err, ShouldBeNil)
+			So(downloaded, ShouldEqual, len(content))
+			So(total, ShouldEqual, len(content))
+			So(verifyChecksum(file, checksumOf(content)), ShouldBeNil)
+			_, err = os.Stat(file + partFileSuffix)
+			So(os.IsNotExist(err), ShouldBeTrue)
+		})
+
+		Convey("resume from the part file left by the last run", func() {
+			So(ioutil.WriteFile(file+partFileSuffix, content[:1000], 0600), ShouldBeNil)
+			So(rangeDownload(signURL, file, noReport), ShouldBeNil)
+			So(obs.ranges, ShouldResemble, []string{"bytes=1000-"})
+			So(verifyChecksum(file, checksumOf(content)), ShouldBeNil)
+		})
+
+		Convey("retry after the connection is broken and continue from the breakpoint", func() {
+			obs.failCodes = []int{http.StatusServiceUnavailable}
+			So(rangeDownload(signURL, file, noReport), ShouldBeNil)
+			So(len(obs.ranges), ShouldEqual, 2)
+
+			obs.ranges = nil
+			So(os.Remove(file), ShouldBeNil)
+			obs.truncateAt = 2048
+			So(rangeDownload(signURL, file, noReport), ShouldBeNil)
+			So(obs.ranges, ShouldResemble, []string{"", "bytes=2048-"})
+			So(verifyChecksum(file, checksumOf(content)), ShouldBeNil)
+		})
+
+		Convey("give up when the retry budget is exhausted", func() {
+			obs.failCodes = []int{http.StatusInternalServerError, http.StatusInternalServerError,
+				http.StatusInternalServerError, http.StatusInternalServerError}
+			So(rangeDownload(signURL, file, noReport), ShouldNotBeNil)
+			So(len(obs.ranges), ShouldEqual, downloadRetryBudget+1)
+		})
+
+		Convey("do not retry when the object is forbidden", func() {
+			obs.failCodes = []int{http.StatusForbidden}
+			So(rangeDownload(signURL, file, noReport), ShouldNotBeNil)
+			So(len(obs.ranges), ShouldEqual, 1)
+		})
+
+		Convey("corrupt archive is detected and removed", func() {
+			So(rangeDownload(signURL, file, noReport), ShouldBeNil)
+			err := verifyChecksum(file, checksumOf([]byte("another build")))
+			So(err, ShouldNotBeNil)
+			_, ok := err.(*checksumError)
+			So(ok, ShouldBeTrue)
+			_, err = os.Stat(file)
+			So(os.IsNotExist(err), ShouldBeTrue)
+		})
+
+		Convey("missing checksum is not treated as verified", func() {
+			So(rangeDownload(signURL, file, noReport), ShouldBeNil)
+			So(verifyChecksum(file, ""), ShouldEqual, errChecksumMissing)
+		})
+	})
+}
@@ -7,15 +7,12 @@ import (
 	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/config"
 	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/configmanager"
 	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/utils/clients"
-	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/utils/downloader"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/utils/hhmac"
 	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/utils/log"
 	"fmt"
-	"huaweicloud.com/esdk-obs-go/obs/v3"
 	"io"
 	"net/http"
 	"os"
-	"path"
-	"time"
 )
 
 type BuildInfo struct {
@@ -27,6 +24,15 @@ type BuildInfo struct {
 	ObjectKey            string
 	GlobalServiceAddress string
 	Location             string
+	// Checksum 应用包的SHA-256校验值, 只有OBS和LOCAL来源允许为空, 为空时告警并跳过校验
+	Checksum        string
+	SourceType      string
+	URL             string
+	Path            string
+	FleetID         string
+	InstanceID      string
+	DownloadedBytes int64
+	TotalBytes      int64
 }
 
 type ReportRequest struct {
@@ -35,7 +41,12 @@ type ReportRequest struct {
 	Bucket  string `json:"bucket"`
 	Object  string `json:"object"`
 	Result  int    `json:"result"` // 0-成功，非0失败，在state描述原因
-	State   string `json:"state"`  // Complete/No Auth/NoBucket/Download failed...
+	State   string `json:"state"`  // Downloading/Complete/No Auth/NoBucket/Download failed...
+
+	FleetID         string `json:"fleet_id"`
+	InstanceID      string `json:"instance_id"`
+	DownloadedBytes int64  `json:"downloaded_bytes"`
+	TotalBytes      int64  `json:"total_bytes"`
 }
 
 const (
@@ -43,9 +54,11 @@ const (
 	BuildHandleFail    = 1
 
 	// 对应ReportRequest的State字段
-	Complete       = "Complete"
-	ReasonNoAuth   = "No Auth"
-	DownloadFailed = "Download failed"
+	Downloading      = "Downloading"
+	Complete         = "Complete"
+	ReasonNoAuth     = "No Auth"
+	DownloadFailed   = "Download failed"
+	ChecksumMismatch = "Checksum mismatch"
 
 	// MaxFileNum codeCheck要求检查待解压文件的数量，门限先设置的大一些
 	MaxFileNum = 1024 * 1024
@@ -73,6 +86,12 @@ func Init() error {
 		ObjectKey:            meta.Object,
 		GlobalServiceAddress: meta.GlobalServiceAddress,
 		Location:             meta.Region,
+		Checksum:             meta.Checksum,
+		SourceType:           meta.SourceType,
+		URL:                  meta.URL,
+		Path:                 meta.Path,
+		FleetID:              meta.FleetID,
+		InstanceID:           meta.InstanceID,
 	}
 
 	// 创建下载目录和应用目录
@@ -82,20 +101,14 @@ func Init() error {
 		return err
 	}
 
-	// 下载obs压缩包
+	// 下载obs压缩包并校验
 	err = DownloadBuild(build, meta.Ak, meta.Sk)
 	if err != nil {
 		log.RunLogger.Infof("[build manager] DownloadBuild failed, err:%v", err)
+		reportDownloadFailure(build, err)
 		return err
 	}
 
-	// 检查压缩包是否生成完成，未完成则再等待一会儿
-	zipReady := checkZipReady(build)
-	if !zipReady {
-		log.RunLogger.Infof("[build manager] checkZipReady failed, build:%v", build.BuildID)
-		return fmt.Errorf("check zip not ready")
-	}
-
 	// 解压应用包到运行路径下
 	err = UnzipBuild(build)
 	if err != nil {
@@ -112,27 +125,12 @@ func Init() error {
 	return nil
 }
 
-func checkZipReady(build *BuildInfo) bool {
-	// obs接口返回时可能zip文件还未完全完成，1s轮询等待一下文件生成，最长10s
-	timeout := time.After(time.Second * 10)
-	for {
-		select {
-		case <-timeout:
-			log.RunLogger.Infof("[build manager] checkZipReady failed, time out")
-			return false
-		default:
-			_, err := os.Stat(build.DownloadPath + "/" + build.FileName)
-			if err == nil {
-				log.RunLogger.Infof("[build manager] checkZipReady success")
-				return true
-			}
-		}
-		time.Sleep(time.Second * 1)
-	}
-}
-
 func sendBuildStateToFleetManager(build *BuildInfo, result int, state string) error {
-	cli := clients.NewHttpsClientWithoutCerts()
+	// fleetmanager 对内部上报接口强制 hmac 校验，未签名的请求会被拒绝
+	cli, err := clients.NewSignedHttpsClient(hhmac.LocalKeyFleetManager)
+	if err != nil {
+		return err
+	}
 	reqBody := &ReportRequest{
 		BuildID: build.BuildID,
 		Region:  build.Location,
@@ -140,6 +138,11 @@ func sendBuildStateToFleetManager(build *BuildInfo, result int, state string) er
 		Object:  build.ObjectKey,
 		Result:  result,
 		State:   state,
+
+		FleetID:         build.FleetID,
+		InstanceID:      build.InstanceID,
+		DownloadedBytes: build.DownloadedBytes,
+		TotalBytes:      build.TotalBytes,
 	}
 
 	req, err := clients.JSONEncodeRequest(http.MethodPost,
@@ -157,37 +160,56 @@ func sendBuildStateToFleetManager(build *BuildInfo, result int, state string) er
 	return nil
 }
 
+// DownloadBuild 从应用包来源分段续传下载应用包, 下载过程中周期性上报进度, 完成后校验SHA-256
 func DownloadBuild(build *BuildInfo, ak, sk string) error {
-	_, build.FileName = path.Split(build.ObjectKey)
-	d, err := downloader.NewDownloader(ak, sk, GetOBSEndpoint(build.Location), build.Bucket, build.ObjectKey, build.Location)
+	src, err := newBuildSource(build, ak, sk)
 	if err != nil {
+		log.RunLogger.Errorf("[build manager] DownloadBuild %v failed, err:%v", build.BuildID, err)
 		return err
 	}
+	build.FileName = src.fileName()
+	file := build.DownloadPath + "/" + build.FileName
+	// 已经下载并校验通过的应用包不再重复下载
+	if build.Checksum != "" && verifyChecksum(file, build.Checksum) == nil {
+		log.RunLogger.Infof("[build manager] DownloadBuild %v skipped, file is verified", build.BuildID)
+		return nil
+	}
 
-	input := &obs.DownloadFileInput{}
-	input.Bucket = build.Bucket
-	input.Key = build.ObjectKey
-	// 下载对象的本地文件全路径
-	input.DownloadFile = config.DownloadPathPrefix + "/" + build.BuildID + "/" + build.FileName
-	// 开启断点续传模式
-	input.EnableCheckpoint = true
-	// 指定分段大小为9MB
-	input.PartSize = 9 * 1024 * 1024
-	// 指定分段下载时的最大并发数
-	input.TaskNum = 5
-
-	log.RunLogger.Infof("[build manager] DownloadBuild %v start, build:%+v", build.BuildID, build)
-	output, err := d.ObsClient.DownloadFile(input)
+	log.RunLogger.Infof("[build manager] DownloadBuild %v start, source:%v", build.BuildID, build.SourceType)
+	err = src.fetch(file, func(downloaded, total int64) {
+		build.DownloadedBytes = downloaded
+		build.TotalBytes = total
+		if err := sendBuildStateToFleetManager(build, BuildHandleSuccess, Downloading); err != nil {
+			log.RunLogger.Infof("[build manager] report build %v progress failed, err:%v", build.BuildID, err)
+		}
+	})
 	if err != nil {
-		log.RunLogger.Errorf("[build manager] DownloadBuild %v failed, output:%v, err:%v",
-			build.BuildID, output, err)
+		log.RunLogger.Errorf("[build manager] DownloadBuild %v failed, err:%v", build.BuildID, err)
 		return err
 	}
-	log.RunLogger.Infof("[build manager] DownloadBuild %v success, requestID:%v",
-		build.BuildID, output.RequestId)
+	// 只有OBS和LOCAL来源允许不设置校验值, S3和HTTPS来源在newBuildSource中已经拒绝
+	if build.Checksum == "" {
+		log.RunLogger.Warnf("[build manager] build %v has no checksum, integrity of %s is NOT verified",
+			build.BuildID, file)
+	} else if err = verifyChecksum(file, build.Checksum); err != nil {
+		log.RunLogger.Errorf("[build manager] DownloadBuild %v failed, err:%v", build.BuildID, err)
+		return err
+	}
+	log.RunLogger.Infof("[build manager] DownloadBuild %v success, %d bytes", build.BuildID, build.DownloadedBytes)
 	return nil
 }
 
+// reportDownloadFailure 上报下载失败的原因
+func reportDownloadFailure(build *BuildInfo, cause error) {
+	state := DownloadFailed
+	if _, ok := cause.(*checksumError); ok {
+		state = ChecksumMismatch
+	}
+	if err := sendBuildStateToFleetManager(build, BuildHandleFail, state); err != nil {
+		log.RunLogger.Infof("[build manager] report build %v failure failed, err:%v", build.BuildID, err)
+	}
+}
+
 func CreateBuildDocument(build *BuildInfo) error {
 	var err error
 	// 创建下载目录
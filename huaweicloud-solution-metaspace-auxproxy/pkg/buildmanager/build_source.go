@@ -0,0 +1,128 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 应用包来源: OBS使用实例凭证签名下载, S3和HTTPS从下发的地址下载, LOCAL从实例上的路径(通常为NFS挂载目录)复制
+package buildmanager
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"time"
+)
+
+// 对应BuildInfo的SourceType字段, 为空时为OBS
+const (
+	SourceOBS   = "OBS"
+	SourceS3    = "S3"
+	SourceHTTPS = "HTTPS"
+	SourceLocal = "LOCAL"
+)
+
+// buildSource 应用包来源
+type buildSource interface {
+	// fileName 下载到本地的应用包文件名
+	fileName() string
+	// fetch 获取应用包到file, 中断后再次调用从断点继续
+	fetch(file string, report progressFunc) error
+}
+
+func newBuildSource(build *BuildInfo, ak, sk string) (buildSource, error) {
+	switch build.SourceType {
+	case "", SourceOBS:
+		return &obsSource{build: build, ak: ak, sk: sk}, nil
+	case SourceS3, SourceHTTPS:
+		if build.URL == "" {
+			return nil, fmt.Errorf("url of %s build %s is empty", build.SourceType, build.BuildID)
+		}
+		// 地址由租户填写, 只能依赖校验值保证应用包未被篡改
+		if build.Checksum == "" {
+			return nil, fmt.Errorf("checksum of %s build %s is required", build.SourceType, build.BuildID)
+		}
+		u, err := url.Parse(build.URL)
+		if err != nil {
+			return nil, err
+		}
+		return &urlSource{url: build.URL, name: path.Base(u.Path)}, nil
+	case SourceLocal:
+		if !path.IsAbs(build.Path) {
+			return nil, fmt.Errorf("path %s of local build %s is not absolute", build.Path, build.BuildID)
+		}
+		return &localSource{path: build.Path}, nil
+	default:
+		return nil, fmt.Errorf("unsupported source type %s of build %s", build.SourceType, build.BuildID)
+	}
+}
+
+// obsSource 每次重试使用实例凭证重新签名, 避免临时URL过期
+type obsSource struct {
+	build *BuildInfo
+	ak    string
+	sk    string
+}
+
+func (s *obsSource) fileName() string {
+	return path.Base(s.build.ObjectKey)
+}
+
+func (s *obsSource) fetch(file string, report progressFunc) error {
+	return rangeDownload(func() (string, error) {
+		return signBuildURL(s.build, s.ak, s.sk)
+	}, file, report)
+}
+
+// urlSource S3来源的URL由fleetmanager签名后下发
+type urlSource struct {
+	url  string
+	name string
+}
+
+func (s *urlSource) fileName() string {
+	return s.name
+}
+
+func (s *urlSource) fetch(file string, report progressFunc) error {
+	return rangeDownload(func() (string, error) {
+		return s.url, nil
+	}, file, report)
+}
+
+// localSource 复制实例上已有的应用包, NFS上的文件在复制过程中可能被替换, 复制完成后同样校验
+type localSource struct {
+	path string
+}
+
+func (s *localSource) fileName() string {
+	return path.Base(s.path)
+}
+
+func (s *localSource) fetch(file string, report progressFunc) error {
+	src, err := os.Open(s.path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	part := file + partFileSuffix
+	dst, err := os.OpenFile(part, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	pw := &progressWriter{w: dst, total: info.Size(), report: report, lastReport: time.Now()}
+	report(0, info.Size())
+	_, err = io.Copy(pw, src)
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	report(pw.downloaded, pw.downloaded)
+
+	return os.Rename(part, file)
+}
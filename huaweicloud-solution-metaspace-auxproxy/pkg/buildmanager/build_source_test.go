@@ -0,0 +1,90 @@
+package buildmanager
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestBuildSource(t *testing.T) {
+	content := bytes.Repeat([]byte("metaspace build "), 1024)
+	noReport := func(int64, int64) {}
+
+	Convey("build source test", t, func() {
+		dir, err := ioutil.TempDir("", "build-source")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		file := filepath.Join(dir, "app.zip")
+
+		Convey("obs source is the default", func() {
+			src, err := newBuildSource(&BuildInfo{BuildID: "b1", ObjectKey: "builds/app.zip"}, "ak", "sk")
+			So(err, ShouldBeNil)
+			_, ok := src.(*obsSource)
+			So(ok, ShouldBeTrue)
+			So(src.fileName(), ShouldEqual, "app.zip")
+		})
+
+		Convey("s3 source downloads from the signed url", func() {
+			obs := &fakeOBS{content: content}
+			server := httptest.NewServer(obs)
+			defer server.Close()
+
+			src, err := newBuildSource(&BuildInfo{BuildID: "b1", SourceType: SourceS3,
+				URL: server.URL + "/builds/app.zip?X-Amz-Signature=abc", Checksum: checksumOf(content)}, "", "")
+			So(err, ShouldBeNil)
+			So(src.fileName(), ShouldEqual, "app.zip")
+			So(src.fetch(file, noReport), ShouldBeNil)
+			So(verifyChecksum(file, checksumOf(content)), ShouldBeNil)
+		})
+
+		Convey("s3 source requires checksum", func() {
+			_, err := newBuildSource(&BuildInfo{BuildID: "b1", SourceType: SourceS3,
+				URL: "https://s3.example.com/builds/app.zip"}, "", "")
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("https source requires checksum", func() {
+			_, err := newBuildSource(&BuildInfo{BuildID: "b1", SourceType: SourceHTTPS,
+				URL: "https://example.com/app.zip"}, "", "")
+			So(err, ShouldNotBeNil)
+
+			_, err = newBuildSource(&BuildInfo{BuildID: "b1", SourceType: SourceHTTPS,
+				URL: "https://example.com/app.zip", Checksum: checksumOf(content)}, "", "")
+			So(err, ShouldBeNil)
+		})
+
+		Convey("local source copies the file on the shared mount", func() {
+			nfs := filepath.Join(dir, "nfs-app.zip")
+			So(ioutil.WriteFile(nfs, content, 0600), ShouldBeNil)
+
+			src, err := newBuildSource(&BuildInfo{BuildID: "b1", SourceType: SourceLocal, Path: nfs}, "", "")
+			So(err, ShouldBeNil)
+			So(src.fileName(), ShouldEqual, "nfs-app.zip")
+			var downloaded, total int64
+			So(src.fetch(file, func(d, t int64) { downloaded, total = d, t }), ShouldBeNil)
+			So(downloaded, ShouldEqual, len(content))
+			So(total, ShouldEqual, len(content))
+			So(verifyChecksum(file, checksumOf(content)), ShouldBeNil)
+		})
+
+		Convey("local source rejects relative path and missing file", func() {
+			_, err := newBuildSource(&BuildInfo{BuildID: "b1", SourceType: SourceLocal, Path: "app.zip"}, "", "")
+			So(err, ShouldNotBeNil)
+
+			src, err := newBuildSource(&BuildInfo{BuildID: "b1", SourceType: SourceLocal,
+				Path: filepath.Join(dir, "missing.zip")}, "", "")
+			So(err, ShouldBeNil)
+			So(src.fetch(file, noReport), ShouldNotBeNil)
+		})
+
+		Convey("unknown source type is rejected", func() {
+			_, err := newBuildSource(&BuildInfo{BuildID: "b1", SourceType: "FTP"}, "", "")
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,267 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 应用包原地更新: 新的应用包下载到独立目录, 逐个排空进程并从新目录重新拉起, 新进程未就绪时回滚到原目录
+package buildmanager
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/config"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/apis"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/common"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/configmanager"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/processmanager"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/utils/log"
+)
+
+const defaultReadinessTimeout = 300 * time.Second
+
+// activeBuild 持久化的生效应用包
+type activeBuild struct {
+	BuildID string `json:"build_id"`
+	Dir     string `json:"dir"`
+}
+
+type buildUpdater struct {
+	mux            sync.Mutex
+	currentBuildID string
+	status         apis.BuildUpdateStatus
+}
+
+var updater = &buildUpdater{
+	status: apis.BuildUpdateStatus{State: common.BuildUpdateStateIdle},
+}
+
+// 应用包准备和进程替换, 测试时替换
+var (
+	prepareBuild  = downloadAndUnzipBuild
+	listProcesses = func() []*processmanager.Process {
+		return processmanager.ProcessMgr.GetAllRunningProcesses()
+	}
+	replaceProcess = func(pro *processmanager.Process) {
+		processmanager.ProcessMgr.ReplaceProcess(pro)
+	}
+	waitProcessesReady = func(launchPath, parameters, buildDir string, want int, timeout time.Duration) error {
+		return processmanager.ProcessMgr.WaitProcessesReady(launchPath, parameters, buildDir, want, timeout)
+	}
+	resetRestartHistory = func(launchPath, parameters string) {
+		processmanager.ProcessMgr.ResetRestartHistory(launchPath, parameters)
+	}
+)
+
+// buildDirOf 更新的应用包与启动时的应用包目录并列存放
+func buildDirOf(buildID string) string {
+	return config.BuildPathPrefix + "-" + buildID
+}
+
+// RestoreActiveBuild auxproxy重启后恢复原地更新生效的应用包目录
+func RestoreActiveBuild() {
+	data, err := ioutil.ReadFile(config.ActiveBuildFilePath)
+	if err != nil {
+		return
+	}
+	build := &activeBuild{}
+	if err = json.Unmarshal(data, build); err != nil {
+		log.RunLogger.Errorf("[build manager] failed to unmarshal active build %s for %v", data, err)
+		return
+	}
+	if _, err = os.Stat(build.Dir); err != nil {
+		log.RunLogger.Errorf("[build manager] active build dir %s is invalid for %v", build.Dir, err)
+		return
+	}
+
+	updater.mux.Lock()
+	updater.currentBuildID = build.BuildID
+	updater.mux.Unlock()
+	processmanager.SetActiveBuildDir(build.Dir)
+	log.RunLogger.Infof("[build manager] restore active build %s in %s", build.BuildID, build.Dir)
+}
+
+func saveActiveBuild(buildID, dir string) error {
+	data, err := json.Marshal(&activeBuild{BuildID: buildID, Dir: dir})
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(config.ActiveBuildFilePath, data, 0600)
+}
+
+func isBuildUpdating(state string) bool {
+	return state == common.BuildUpdateStateDownloading || state == common.BuildUpdateStateRolling ||
+		state == common.BuildUpdateStateRollingBack
+}
+
+// StartBuildUpdate 开始原地更新应用包, 同一应用包的重复请求直接返回, 同时只允许一个更新
+func StartBuildUpdate(req *apis.UpdateBuildRequest) error {
+	updater.mux.Lock()
+	defer updater.mux.Unlock()
+
+	st := updater.status
+	if st.BuildID == req.BuildID && (isBuildUpdating(st.State) || st.State == common.BuildUpdateStateSucceeded) {
+		return nil
+	}
+	if isBuildUpdating(st.State) {
+		return fmt.Errorf("build %s is updating in state %s", st.BuildID, st.State)
+	}
+
+	now := time.Now()
+	updater.status = apis.BuildUpdateStatus{
+		BuildID:   req.BuildID,
+		State:     common.BuildUpdateStateDownloading,
+		StartedAt: now,
+		UpdatedAt: now,
+	}
+	if req.BuildID == updater.currentBuildID {
+		updater.status.State = common.BuildUpdateStateSucceeded
+		return nil
+	}
+	go updater.run(*req)
+	return nil
+}
+
+// GetBuildUpdateStatus 查询应用包原地更新进度
+func GetBuildUpdateStatus() apis.BuildUpdateStatus {
+	updater.mux.Lock()
+	defer updater.mux.Unlock()
+	return updater.status
+}
+
+func (u *buildUpdater) update(f func(st *apis.BuildUpdateStatus)) {
+	u.mux.Lock()
+	defer u.mux.Unlock()
+	f(&u.status)
+	u.status.UpdatedAt = time.Now()
+}
+
+func (u *buildUpdater) run(req apis.UpdateBuildRequest) {
+	newDir := buildDirOf(req.BuildID)
+	if err := prepareBuild(&req, newDir); err != nil {
+		log.RunLogger.Errorf("[build manager] failed to prepare build %s for %v", req.BuildID, err)
+		u.update(func(st *apis.BuildUpdateStatus) {
+			st.State = common.BuildUpdateStateFailed
+			st.Message = err.Error()
+		})
+		return
+	}
+
+	oldDir := processmanager.ActiveBuildDir()
+	pros := listProcesses()
+	u.update(func(st *apis.BuildUpdateStatus) {
+		st.State = common.BuildUpdateStateRolling
+		st.TotalProcesses = len(pros)
+	})
+	processmanager.SetActiveBuildDir(newDir)
+	log.RunLogger.Infof("[build manager] start to roll %d processes from %s to %s", len(pros), oldDir, newDir)
+
+	timeout := defaultReadinessTimeout
+	if req.ReadinessTimeoutSeconds > 0 {
+		timeout = time.Duration(req.ReadinessTimeoutSeconds) * time.Second
+	}
+	// 同一启动配置可能有多个进程, 逐个替换后等待新目录中该启动配置的就绪进程数递增
+	replaced := map[string]int{}
+	for _, pro := range pros {
+		replaceProcess(pro)
+		key := pro.LaunchPath + " " + pro.Parameters
+		replaced[key]++
+		if err := waitProcessesReady(pro.LaunchPath, pro.Parameters, newDir, replaced[key], timeout); err != nil {
+			log.RunLogger.Errorf("[build manager] new process of build %s is not ready for %v, roll back",
+				req.BuildID, err)
+			u.rollback(pros, oldDir, newDir, err)
+			return
+		}
+		u.update(func(st *apis.BuildUpdateStatus) {
+			st.UpdatedProcesses++
+		})
+	}
+
+	if err := saveActiveBuild(req.BuildID, newDir); err != nil {
+		log.RunLogger.Errorf("[build manager] failed to save active build %s for %v", req.BuildID, err)
+	}
+	// 启动时的应用包目录下还有进程日志, 只清理更新产生的旧目录
+	if oldDir != config.BuildPathPrefix && oldDir != newDir {
+		_ = os.RemoveAll(oldDir)
+	}
+	u.mux.Lock()
+	u.currentBuildID = req.BuildID
+	u.mux.Unlock()
+	u.update(func(st *apis.BuildUpdateStatus) {
+		st.State = common.BuildUpdateStateSucceeded
+		st.Message = ""
+	})
+	log.RunLogger.Infof("[build manager] success to update build to %s", req.BuildID)
+}
+
+// rollback 切回原应用包目录, 替换所有从新目录启动的进程
+func (u *buildUpdater) rollback(pros []*processmanager.Process, oldDir, newDir string, cause error) {
+	u.update(func(st *apis.BuildUpdateStatus) {
+		st.State = common.BuildUpdateStateRollingBack
+		st.Message = cause.Error()
+	})
+	processmanager.SetActiveBuildDir(oldDir)
+
+	// 新目录的进程失败会累积重启退避, 回滚后旧目录的进程需要立即拉起
+	for _, pro := range pros {
+		resetRestartHistory(pro.LaunchPath, pro.Parameters)
+	}
+	for _, pro := range listProcesses() {
+		if pro.BuildDir == newDir {
+			replaceProcess(pro)
+		}
+	}
+	_ = os.RemoveAll(newDir)
+
+	u.update(func(st *apis.BuildUpdateStatus) {
+		st.State = common.BuildUpdateStateRolledBack
+		st.Message = fmt.Sprintf("rolled back to %s for %v", oldDir, cause)
+	})
+	log.RunLogger.Infof("[build manager] build update is rolled back to %s", oldDir)
+}
+
+// downloadAndUnzipBuild 使用实例元数据中的凭证下载新的应用包并解压到独立目录
+func downloadAndUnzipBuild(req *apis.UpdateBuildRequest, dir string) error {
+	meta, err := configmanager.GetMetaDataConfig()
+	if err != nil {
+		return fmt.Errorf("failed to get meta data for %v", err)
+	}
+	build := &BuildInfo{
+		BuildID:              req.BuildID,
+		BuildPath:            dir,
+		DownloadPath:         config.DownloadPathPrefix + "/" + req.BuildID,
+		Bucket:               req.Bucket,
+		ObjectKey:            req.Object,
+		GlobalServiceAddress: meta.GlobalServiceAddress,
+		Location:             req.Region,
+		Checksum:             req.Checksum,
+		SourceType:           req.SourceType,
+		URL:                  req.URL,
+		Path:                 req.Path,
+		FleetID:              meta.FleetID,
+		InstanceID:           meta.InstanceID,
+	}
+	if err = os.MkdirAll(build.DownloadPath, os.ModePerm); err != nil {
+		return err
+	}
+	// 清理上次失败残留的文件
+	if err = os.RemoveAll(dir); err != nil {
+		return err
+	}
+	if err = os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+
+	if err = DownloadBuild(build, meta.Ak, meta.Sk); err != nil {
+		reportDownloadFailure(build, err)
+		return err
+	}
+	if err = UnzipBuild(build); err != nil {
+		return err
+	}
+	if err = sendBuildStateToFleetManager(build, BuildHandleSuccess, Complete); err != nil {
+		log.RunLogger.Infof("[build manager] report build %v complete failed, err:%v", build.BuildID, err)
+	}
+	return nil
+}
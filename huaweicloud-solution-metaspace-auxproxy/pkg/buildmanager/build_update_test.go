@@ -0,0 +1,39 @@
+package buildmanager
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/config"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/apis"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/common"
+)
+
+func TestStartBuildUpdate(t *testing.T) {
+	req := &apis.UpdateBuildRequest{BuildID: "build-2", Bucket: "bucket", Object: "app.zip", Region: "cn-north-4"}
+	defer func() {
+		updater.currentBuildID = ""
+		updater.status = apis.BuildUpdateStatus{State: common.BuildUpdateStateIdle}
+	}()
+
+	Convey("start build update test", t, func() {
+		So(buildDirOf("build-2"), ShouldEqual, config.BuildPathPrefix+"-build-2")
+
+		// 应用包已生效, 无需更新
+		updater.currentBuildID = "build-2"
+		So(StartBuildUpdate(req), ShouldBeNil)
+		So(GetBuildUpdateStatus().State, ShouldEqual, common.BuildUpdateStateSucceeded)
+
+		// 同一应用包正在更新时重复请求直接返回
+		updater.currentBuildID = "build-1"
+		updater.status = apis.BuildUpdateStatus{BuildID: "build-2", State: common.BuildUpdateStateRolling}
+		So(StartBuildUpdate(req), ShouldBeNil)
+		So(GetBuildUpdateStatus().State, ShouldEqual, common.BuildUpdateStateRolling)
+
+		// 其他应用包正在更新时拒绝
+		updater.status = apis.BuildUpdateStatus{BuildID: "build-3", State: common.BuildUpdateStateDownloading}
+		So(StartBuildUpdate(req), ShouldNotBeNil)
+		So(GetBuildUpdateStatus().BuildID, ShouldEqual, "build-3")
+	})
+}
@@ -9,6 +9,37 @@ const (
 	AppProcessStateTerminating = "TERMINATING"
 	AppProcessStateTerminated  = "TERMINATED"
 	AppProcessStateError       = "ERROR"
+	AppProcessStateCrashLoop   = "CRASH_LOOP"
+)
+
+// 进程因资源限制被终止的原因
+const (
+	AppProcessStateReasonOOMKilled = "OOM_KILLED"
+)
+
+// 进程自定义指标类型
+const (
+	MetricTypeGauge   = "GAUGE"
+	MetricTypeCounter = "COUNTER"
+)
+
+// 进程排空阶段, 依次为停止接收新的服务器会话、等待服务器会话结束、结束进程
+const (
+	DrainPhaseStopAccepting   = "STOP_ACCEPTING"
+	DrainPhaseWaitingSessions = "WAITING_SESSIONS"
+	DrainPhaseTerminating     = "TERMINATING"
+	DrainPhaseTerminated      = "TERMINATED"
+)
+
+// 应用包原地更新状态
+const (
+	BuildUpdateStateIdle        = "IDLE"
+	BuildUpdateStateDownloading = "DOWNLOADING"
+	BuildUpdateStateRolling     = "ROLLING"
+	BuildUpdateStateRollingBack = "ROLLING_BACK"
+	BuildUpdateStateSucceeded   = "SUCCEEDED"
+	BuildUpdateStateRolledBack  = "ROLLED_BACK"
+	BuildUpdateStateFailed      = "FAILED"
 )
 
 const AppProcessIDPrefix = "app-process-"
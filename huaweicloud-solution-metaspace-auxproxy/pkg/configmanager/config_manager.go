@@ -27,9 +27,11 @@ type Config struct {
 	FleetID        string
 	ScalingGroupID string
 	InstanceID     string
-	PublicIP       string
-	PrivateIP      string
-	InstanceConfig apis.InstanceConfiguration
+	// AvailabilityZone 实例所在可用区, 用于appgateway按可用区放置服务端会话
+	AvailabilityZone string
+	PublicIP         string
+	PrivateIP        string
+	InstanceConfig   apis.InstanceConfiguration
 }
 
 type ConfigManager struct {
@@ -52,6 +54,14 @@ type Meta struct {
 	GlobalServiceAddress string `json:"global_service_address"`
 	Ak                   string `json:"ak"`
 	Sk                   string `json:"sk"`
+	// Checksum 应用包的SHA-256校验值, 为空时不校验
+	Checksum string `json:"checksum"`
+	// SourceType 应用包来源, 为空时为OBS; S3和HTTPS来源从URL下载, LOCAL来源从Path复制
+	SourceType string `json:"source_type"`
+	URL        string `json:"url"`
+	Path       string `json:"path"`
+	// InstanceID 取自metadata的uuid
+	InstanceID string `json:"-"`
 	// 弹缩使用的metadata
 	FleetID        string `json:"fleet_id"`
 	GatewayAddress string `json:"gateway_address"`
@@ -59,6 +69,7 @@ type Meta struct {
 }
 
 type Metadata struct {
+	UUID         string `json:"uuid"`
 	InstanceType string `json:"instance_type"`
 	Meta         Meta   `json:"meta"`
 }
@@ -87,7 +98,7 @@ func InitConfigManager(interval time.Duration) (retErr error) {
 			sgID = config.Opts.ScalingGroupId
 		}
 
-		instanceID, publicIP, privateIP, err := parseParam()
+		instanceID, az, publicIP, privateIP, err := parseParam()
 		if err != nil {
 			retErr = err
 			return
@@ -99,12 +110,13 @@ func InitConfigManager(interval time.Duration) (retErr error) {
 			AuxProxyPort:  config.HttpsPort,
 			ConfigGetChan: make(chan bool),
 			Config: Config{
-				FleetID:        fleetID,
-				ScalingGroupID: sgID,
-				InstanceID:     instanceID,
-				PublicIP:       publicIP,
-				PrivateIP:      privateIP,
-				InstanceConfig: apis.InstanceConfiguration{},
+				FleetID:          fleetID,
+				ScalingGroupID:   sgID,
+				InstanceID:       instanceID,
+				AvailabilityZone: az,
+				PublicIP:         publicIP,
+				PrivateIP:        privateIP,
+				InstanceConfig:   apis.InstanceConfiguration{},
 			},
 			StopChan: make(chan int),
 		}
@@ -113,25 +125,25 @@ func InitConfigManager(interval time.Duration) (retErr error) {
 	return
 }
 
-func parseParam() (string, string, string, error) {
-	// get instance id
-	instanceID, err := getInstanceID()
+func parseParam() (string, string, string, string, error) {
+	// get instance id and availability zone
+	instanceID, az, err := getInstanceIDAndAZ()
 	if err != nil {
-		return "", "", "", fmt.Errorf("[config manager] failed to get instance id for %v", err)
+		return "", "", "", "", fmt.Errorf("[config manager] failed to get instance id for %v", err)
 	}
 
 	// get public ip
 	publicIP, err := getPublicIPOrPrivateIP(publicIPType)
 	if err != nil {
-		return "", "", "", fmt.Errorf("[config manager] failed to get public ip for %v", err)
+		return "", "", "", "", fmt.Errorf("[config manager] failed to get public ip for %v", err)
 	}
 
 	// get private ip
 	privateIP, err := getPublicIPOrPrivateIP(privateIPType)
 	if err != nil {
-		return "", "", "", fmt.Errorf("[config manager] failed to get private ip for %v", err)
+		return "", "", "", "", fmt.Errorf("[config manager] failed to get private ip for %v", err)
 	}
-	return instanceID, publicIP, privateIP, nil
+	return instanceID, az, publicIP, privateIP, nil
 }
 
 // Stop stops config work
@@ -262,35 +274,37 @@ func GetMetaDataConfig() (*Meta, error) {
 	}
 
 	meta := metadata.Meta
+	meta.InstanceID = metadata.UUID
 	log.RunLogger.Errorf("[config manager] GetMetaDataConfig success, meta:%+v", meta)
 	return &meta, nil
 }
 
-func getInstanceID() (string, error) {
+func getInstanceIDAndAZ() (string, string, error) {
 	cli := clients.NewHttpsClientWithoutCerts()
 
 	req, err := clients.NewRequest("GET",
 		fmt.Sprintf("%s/openstack/latest/meta_data.json", config.Opts.CloudPlatformAddr),
 		map[string][]string{}, nil)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 
 	code, buf, _, err := clients.DoRequest(cli, req)
 	if err != nil || code != http.StatusOK {
-		return "", fmt.Errorf("code %d or err %v", code, err)
+		return "", "", fmt.Errorf("code %d or err %v", code, err)
 	}
 
 	var instanceIDStruct struct {
-		InstanceID string `json:"uuid"`
+		InstanceID       string `json:"uuid"`
+		AvailabilityZone string `json:"availability_zone"`
 	}
 
 	err = json.Unmarshal(buf, &instanceIDStruct)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 
-	return instanceIDStruct.InstanceID, nil
+	return instanceIDStruct.InstanceID, instanceIDStruct.AvailabilityZone, nil
 }
 
 func getPublicIPOrPrivateIP(ipType string) (string, error) {
@@ -67,7 +67,8 @@ func (g *GrpcServer) ProcessReady(ctx context.Context, req *auxproxyservice.Proc
 		req.Pid, req.GrpcPort, req.ClientPort)
 
 	pid := int(req.Pid)
-	err := processmanager.ProcessMgr.RegisterProcess(pid, int(req.ClientPort), int(req.GrpcPort), req.LogPathsToUpload)
+	err := processmanager.ProcessMgr.RegisterProcess(pid, int(req.ClientPort), int(req.GrpcPort), req.LogPathsToUpload,
+		req.Labels)
 
 	if err != nil {
 		log.RunLogger.Errorf("[sdk server] failed to register process %d for %v", pid, err)
@@ -111,6 +112,40 @@ func (g *GrpcServer) ProcessEnding(ctx context.Context, req *auxproxyservice.Pro
 	return &auxproxyservice.AuxProxyResponse{}, nil
 }
 
+// ReportMetrics report custom metrics service, 指标在本地聚合后批量上报appgateway
+func (g *GrpcServer) ReportMetrics(ctx context.Context, req *auxproxyservice.ReportMetricsRequest) (
+	*auxproxyservice.AuxProxyResponse, error) {
+	pid := int(req.Pid)
+
+	samples := make([]processmanager.MetricSample, 0, len(req.Samples))
+	for _, s := range req.Samples {
+		samples = append(samples, processmanager.MetricSample{Name: s.Name, Type: s.Type, Value: s.Value})
+	}
+
+	if err := processmanager.ProcessMgr.RecordMetrics(pid, samples); err != nil {
+		log.RunLogger.Errorf("[sdk server] failed to record metrics of process %d for %v", pid, err)
+		return &auxproxyservice.AuxProxyResponse{Error: errors.NewReportMetricsError(err.Error())}, err
+	}
+
+	return &auxproxyservice.AuxProxyResponse{}, nil
+}
+
+// ReportDrainProgress report drain progress service, 进程收到OnProcessTerminate后调用
+func (g *GrpcServer) ReportDrainProgress(ctx context.Context, req *auxproxyservice.ReportDrainProgressRequest) (
+	*auxproxyservice.AuxProxyResponse, error) {
+	pid := int(req.Pid)
+	log.RunLogger.Infof("[sdk server] process %d reports drain progress, active server sessions %d, message %s",
+		pid, req.ActiveServerSessions, req.Message)
+
+	err := processmanager.ProcessMgr.ReportDrainProgress(pid, int(req.ActiveServerSessions), req.Message)
+	if err != nil {
+		log.RunLogger.Errorf("[sdk server] failed to record drain progress of process %d for %v", pid, err)
+		return &auxproxyservice.AuxProxyResponse{Error: errors.NewReportDrainProgressError(err.Error())}, err
+	}
+
+	return &auxproxyservice.AuxProxyResponse{}, nil
+}
+
 // ActivateServerSession activate game server session service
 func (g *GrpcServer) ActivateServerSession(ctx context.Context,
 	req *auxproxyservice.ActivateServerSessionRequest) (*auxproxyservice.AuxProxyResponse, error) {
@@ -138,8 +173,13 @@ func (g *GrpcServer) ActivateServerSession(ctx context.Context,
 // TerminateServerSession terminate game server session service
 func (g *GrpcServer) TerminateServerSession(ctx context.Context,
 	req *auxproxyservice.TerminateServerSessionRequest) (*auxproxyservice.AuxProxyResponse, error) {
+	if req.GetServerSessionId() == "" {
+		log.RunLogger.Errorf("[sdk server] receive a terminate server session request without server session id")
+		return &auxproxyservice.AuxProxyResponse{Error: errors.NewTerminateServerSessionError(
+			"server session id is empty")}, fmt.Errorf("server session id is empty")
+	}
 
-	// termintate server session to gateway
+	// termintate server session to gateway, gateway会同时结束client session并释放进程的server session名额
 	r := &apis.UpdateServerSessionStateRequest{
 		State:       common.ServerSessionStateTerminated,
 		StateReason: "process terminate the server session",
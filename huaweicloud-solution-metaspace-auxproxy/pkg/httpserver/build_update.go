@@ -0,0 +1,49 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 应用包原地更新
+package httpserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/beego/beego/v2/server/web/context"
+
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/apis"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/buildmanager"
+	errors2 "codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/utils/errors"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/utils/log"
+)
+
+// StartBuildUpdate 开始原地更新应用包, 异步执行, 通过ShowBuildUpdate查询进度
+func StartBuildUpdate(ctx *context.Context) {
+	var req apis.UpdateBuildRequest
+	if err := json.Unmarshal(ctx.Input.RequestBody, &req); err != nil {
+		log.RunLogger.Errorf("[http server] failed to unmarshal update build request for %v", err)
+		errResp := errors2.NewBuildUpdateError(fmt.Sprintf("failed to unmarshal update build request for %v", err),
+			http.StatusBadRequest)
+		Response(ctx, errResp.HttpCode, errResp)
+		return
+	}
+	if req.BuildID == "" || req.Bucket == "" || req.Object == "" || req.Region == "" {
+		errResp := errors2.NewBuildUpdateError("build_id, bucket, object and region are required",
+			http.StatusBadRequest)
+		Response(ctx, errResp.HttpCode, errResp)
+		return
+	}
+
+	if err := buildmanager.StartBuildUpdate(&req); err != nil {
+		log.RunLogger.Errorf("[http server] failed to start build update to %s for %v", req.BuildID, err)
+		errResp := errors2.NewBuildUpdateError(err.Error(), http.StatusConflict)
+		Response(ctx, errResp.HttpCode, errResp)
+		return
+	}
+	log.RunLogger.Infof("[http server] start to update build to %s", req.BuildID)
+	Response(ctx, http.StatusAccepted, buildmanager.GetBuildUpdateStatus())
+}
+
+// ShowBuildUpdate 查询应用包原地更新进度
+func ShowBuildUpdate(ctx *context.Context) {
+	Response(ctx, http.StatusOK, buildmanager.GetBuildUpdateStatus())
+}
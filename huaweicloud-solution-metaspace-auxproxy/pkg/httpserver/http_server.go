@@ -60,12 +60,20 @@ func InitHttpServer(addr string) {
 
 		// 认证校验
 		Server.httpServer.InsertFilter("/v1/server-sessions/start", web.BeforeStatic, BeforeFilter)
+		Server.httpServer.InsertFilter("/v1/build-update", web.BeforeStatic, BeforeFilter)
 
 		// register router
 		Server.httpServer.Post("/v1/server-sessions/start", StartServerSession)
 
 		Server.httpServer.Post("/v1/cleanup", StartCleanUp)
 		Server.httpServer.Get("/v1/cleanup-state", ShowCleanUpState)
+
+		// 应用包原地更新
+		Server.httpServer.Post("/v1/build-update", StartBuildUpdate)
+		Server.httpServer.Get("/v1/build-update", ShowBuildUpdate)
+
+		// 本机调试查询进程输出
+		Server.httpServer.Get("/v1/app-processes/:pid/output", ShowProcessOutput)
 	})
 }
 
@@ -0,0 +1,79 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 应用进程输出查询
+package httpserver
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/beego/beego/v2/server/web/context"
+
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/processmanager"
+	errors2 "codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/utils/errors"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/utils/log"
+)
+
+const (
+	defaultOutputLines = 100
+	maxOutputLines     = 10000
+)
+
+// ShowProcessOutput 查询应用进程标准输出或标准错误的最后若干行, 仅允许本机访问, 用于调试
+func ShowProcessOutput(ctx *context.Context) {
+	if !isLocalRequest(ctx) {
+		Response(ctx, http.StatusForbidden, errors2.NewShowProcessOutputError(
+			"process output can only be accessed locally", http.StatusForbidden))
+		return
+	}
+
+	pid, err := strconv.Atoi(ctx.Input.Param(":pid"))
+	if err != nil {
+		errResp := errors2.NewShowProcessOutputError("invalid pid", http.StatusBadRequest)
+		Response(ctx, errResp.HttpCode, errResp)
+		return
+	}
+	stream := ctx.Input.Query("stream")
+	if stream == "" {
+		stream = processmanager.ProcessOutputStdout
+	}
+	lines := defaultOutputLines
+	if q := ctx.Input.Query("lines"); q != "" {
+		lines, err = strconv.Atoi(q)
+		if err != nil || lines <= 0 || lines > maxOutputLines {
+			errResp := errors2.NewShowProcessOutputError(
+				fmt.Sprintf("lines should be between 1 and %d", maxOutputLines), http.StatusBadRequest)
+			Response(ctx, errResp.HttpCode, errResp)
+			return
+		}
+	}
+
+	buf, err := processmanager.ProcessMgr.TailProcessOutput(pid, stream, lines)
+	if err != nil {
+		log.RunLogger.Errorf("[http server] failed to tail %s of process %d for %v", stream, pid, err)
+		errResp := errors2.NewShowProcessOutputError(err.Error(), http.StatusInternalServerError)
+		if os.IsNotExist(err) {
+			errResp = errors2.NewShowProcessOutputError(
+				fmt.Sprintf("output of process %d not found", pid), http.StatusNotFound)
+		}
+		Response(ctx, errResp.HttpCode, errResp)
+		return
+	}
+
+	ctx.Output.Header("Content-Type", "text/plain; charset=utf-8")
+	ctx.Output.SetStatus(http.StatusOK)
+	_ = ctx.Output.Body(buf)
+}
+
+// isLocalRequest 使用连接的对端地址判断, 不信任X-Forwarded-For等请求头
+func isLocalRequest(ctx *context.Context) bool {
+	host, _, err := net.SplitHostPort(ctx.Request.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
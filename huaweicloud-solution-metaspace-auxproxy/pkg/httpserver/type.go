@@ -3,6 +3,9 @@
 // 进程清理响应状态
 package httpserver
 
+import "codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/processmanager"
+
 type ShowCleanUpStateResponse struct {
-	State string `json:"state"`
+	State     string                       `json:"state"`
+	Processes []processmanager.DrainStatus `json:"processes"`
 }
@@ -10,18 +10,20 @@ import (
 	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/utils/clients"
 )
 
-func registerProcess(pid int, bizPid int, launchPath string, parameters string) (*apis.RegisterAppProcessResponse, error) {
+func registerProcess(pid int, bizPid int, launchPath string, parameters string,
+	labels map[string]string) (*apis.RegisterAppProcessResponse, error) {
 	cfg := configmanager.ConfMgr.Config.InstanceConfig
 	// 1. register and update app process to gateway
 	registerReq := &apis.RegisterAppProcessRequest{
-		PID:            pid,
-		BizPID:         bizPid,
-		InstanceID:     configmanager.ConfMgr.Config.InstanceID,
-		ScalingGroupID: configmanager.ConfMgr.Config.ScalingGroupID,
-		FleetID:        configmanager.ConfMgr.Config.FleetID,
-		PublicIP:       configmanager.ConfMgr.Config.PublicIP,
-		PrivateIP:      configmanager.ConfMgr.Config.PrivateIP,
-		AuxProxyPort:   configmanager.ConfMgr.AuxProxyPort,
+		PID:              pid,
+		BizPID:           bizPid,
+		InstanceID:       configmanager.ConfMgr.Config.InstanceID,
+		AvailabilityZone: configmanager.ConfMgr.Config.AvailabilityZone,
+		ScalingGroupID:   configmanager.ConfMgr.Config.ScalingGroupID,
+		FleetID:          configmanager.ConfMgr.Config.FleetID,
+		PublicIP:         configmanager.ConfMgr.Config.PublicIP,
+		PrivateIP:        configmanager.ConfMgr.Config.PrivateIP,
+		AuxProxyPort:     configmanager.ConfMgr.AuxProxyPort,
 
 		MaxServerSessionNum:                     cfg.RuntimeConfiguration.MaxConcurrentServerSessionsPerProcess,
 		NewServerSessionProtectionPolicy:        cfg.ServerSessionProtectionPolicy,
@@ -29,6 +31,7 @@ func registerProcess(pid int, bizPid int, launchPath string, parameters string)
 		ServerSessionActivationTimeoutSeconds:   cfg.RuntimeConfiguration.ServerSessionActivationTimeoutSeconds,
 		LaunchPath:                              launchPath,
 		Parameters:                              parameters,
+		Labels:                                  labels,
 	}
 
 	return clients.GWClient.RegisterProcess(registerReq)
@@ -0,0 +1,42 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 应用包生效目录, 原地更新应用包时切换到新目录, 之后拉起的进程都从新目录启动
+package processmanager
+
+import (
+	"strings"
+	"sync"
+
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/config"
+)
+
+var (
+	buildDirMux    sync.RWMutex
+	activeBuildDir = config.BuildPathPrefix
+)
+
+// SetActiveBuildDir 切换应用包生效目录
+func SetActiveBuildDir(dir string) {
+	buildDirMux.Lock()
+	defer buildDirMux.Unlock()
+	activeBuildDir = dir
+}
+
+// ActiveBuildDir 当前生效的应用包目录
+func ActiveBuildDir() string {
+	buildDirMux.RLock()
+	defer buildDirMux.RUnlock()
+	return activeBuildDir
+}
+
+// resolveLaunchPath 启动配置中的启动路径位于config.BuildPathPrefix下, 替换为进程实际使用的应用包目录
+func resolveLaunchPath(launchPath, buildDir string) string {
+	if buildDir == "" || buildDir == config.BuildPathPrefix {
+		return launchPath
+	}
+	rest := strings.TrimPrefix(launchPath, config.BuildPathPrefix)
+	if rest == launchPath || (rest != "" && rest[0] != '/' && rest[0] != '\\') {
+		return launchPath
+	}
+	return buildDir + rest
+}
@@ -0,0 +1,47 @@
+package processmanager
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/config"
+)
+
+func TestResolveLaunchPath(t *testing.T) {
+	newDir := config.BuildPathPrefix + "-build-2"
+	Convey("resolve launch path test", t, func() {
+		// 启动时的应用包目录不替换
+		So(resolveLaunchPath(config.BuildPathPrefix+"/bin/server", config.BuildPathPrefix), ShouldEqual,
+			config.BuildPathPrefix+"/bin/server")
+		So(resolveLaunchPath(config.BuildPathPrefix+"/bin/server", ""), ShouldEqual,
+			config.BuildPathPrefix+"/bin/server")
+
+		// 应用包目录下的启动路径替换为新目录
+		So(resolveLaunchPath(config.BuildPathPrefix+"/bin/server", newDir), ShouldEqual, newDir+"/bin/server")
+
+		// 应用包目录之外的启动路径不替换
+		So(resolveLaunchPath("/usr/bin/server", newDir), ShouldEqual, "/usr/bin/server")
+		So(resolveLaunchPath(config.BuildPathPrefix+"2/server", newDir), ShouldEqual,
+			config.BuildPathPrefix+"2/server")
+	})
+}
+
+func TestRestartTrackerReset(t *testing.T) {
+	tracker := newRestartTracker()
+	now := time.Now()
+	Convey("restart tracker reset test", t, func() {
+		for i := 0; i < crashLoopThreshold; i++ {
+			tracker.recordExit("/local/app/server", "-p 1", 1, 0, now)
+		}
+		tracker.setCrashLoopProcessID("/local/app/server", "-p 1", "app-process-1")
+		So(tracker.isCrashLooping("/local/app/server", "-p 1"), ShouldBeTrue)
+		So(tracker.canLaunch("/local/app/server", "-p 1", now), ShouldBeFalse)
+
+		So(tracker.reset("/local/app/server", "-p 1"), ShouldEqual, "app-process-1")
+		So(tracker.isCrashLooping("/local/app/server", "-p 1"), ShouldBeFalse)
+		So(tracker.canLaunch("/local/app/server", "-p 1", now), ShouldBeTrue)
+		So(tracker.reset("/local/app/server", "-p 1"), ShouldEqual, "")
+	})
+}
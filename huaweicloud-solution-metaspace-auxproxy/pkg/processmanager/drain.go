@@ -0,0 +1,263 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 应用进程排空, 实例清理和应用包原地更新时排空进程, 并记录每个进程所处的阶段和进程通过SDK上报的排空进度
+package processmanager
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/config"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/apis"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/common"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/utils/clients"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/utils/log"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/utils/sdk/processservice"
+)
+
+const (
+	defaultDrainTimeout = 60 * time.Minute
+	drainPollInterval   = 30 * time.Second
+)
+
+// 访问appgateway的服务器会话接口, 测试时替换
+var (
+	listServerSessions = func(processID string) (*apis.ListServerSessionResponse, error) {
+		return clients.GWClient.ListServerSessions(processID)
+	}
+	terminateServerSession = func(id string) error {
+		return clients.GWClient.TerminateServerSessionAllRelativeResources(id)
+	}
+)
+
+func drainTimeout() time.Duration {
+	if config.Opts.ProcessDrainTimeout <= 0 {
+		return defaultDrainTimeout
+	}
+	return time.Duration(config.Opts.ProcessDrainTimeout) * time.Minute
+}
+
+// DrainStatus 单个进程的排空状态
+type DrainStatus struct {
+	Pid                  int       `json:"pid"`
+	ProcessID            string    `json:"process_id"`
+	Phase                string    `json:"phase"`
+	Acknowledged         bool      `json:"acknowledged"`
+	ActiveServerSessions int       `json:"active_server_sessions"`
+	Message              string    `json:"message,omitempty"`
+	StartedAt            time.Time `json:"started_at"`
+	UpdatedAt            time.Time `json:"updated_at"`
+}
+
+// drainTracker 进程退出并从ProcessManager移除后, 排空状态仍然保留用于查询
+type drainTracker struct {
+	mux    sync.RWMutex
+	drains map[int]*DrainStatus
+}
+
+func newDrainTracker() *drainTracker {
+	return &drainTracker{
+		drains: map[int]*DrainStatus{},
+	}
+}
+
+// StartDrain 进程进入排空的第一个阶段, 停止接收新的服务器会话
+func (p *ProcessManager) StartDrain(process *Process) {
+	process.Mux.RLock()
+	pid, id := process.Pid, process.Id
+	process.Mux.RUnlock()
+
+	now := time.Now()
+	p.drains.mux.Lock()
+	defer p.drains.mux.Unlock()
+	p.drains.drains[pid] = &DrainStatus{
+		Pid:       pid,
+		ProcessID: id,
+		Phase:     common.DrainPhaseStopAccepting,
+		StartedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// UpdateDrainPhase 更新进程的排空阶段
+func (p *ProcessManager) UpdateDrainPhase(pid int, phase string) {
+	p.drains.mux.Lock()
+	defer p.drains.mux.Unlock()
+
+	if d, ok := p.drains.drains[pid]; ok {
+		d.Phase = phase
+		d.UpdatedAt = time.Now()
+	}
+}
+
+// UpdateDrainSessions 记录appgateway上进程尚未结束的服务器会话数
+func (p *ProcessManager) UpdateDrainSessions(pid int, active int) {
+	p.drains.mux.Lock()
+	defer p.drains.mux.Unlock()
+
+	if d, ok := p.drains.drains[pid]; ok {
+		d.ActiveServerSessions = active
+		d.UpdatedAt = time.Now()
+	}
+}
+
+// ReportDrainProgress 记录进程通过SDK上报的排空进度, 首次上报即视为进程已确认排空
+func (p *ProcessManager) ReportDrainProgress(pid int, active int, message string) error {
+	p.drains.mux.Lock()
+	defer p.drains.mux.Unlock()
+
+	d, ok := p.drains.drains[pid]
+	if !ok {
+		// 进程的业务pid可能与启动的pid不同
+		p.ProcessMux.RLock()
+		process := p.GetProcess(pid)
+		p.ProcessMux.RUnlock()
+		if process != nil {
+			d, ok = p.drains.drains[process.Pid]
+		}
+	}
+	if !ok {
+		return fmt.Errorf("process %d is not draining", pid)
+	}
+	d.Acknowledged = true
+	d.ActiveServerSessions = active
+	d.Message = message
+	d.UpdatedAt = time.Now()
+	return nil
+}
+
+// DrainStatuses 按pid排序返回所有进程的排空状态
+func (p *ProcessManager) DrainStatuses() []DrainStatus {
+	p.drains.mux.RLock()
+	defer p.drains.mux.RUnlock()
+
+	res := make([]DrainStatus, 0, len(p.drains.drains))
+	for _, d := range p.drains.drains {
+		res = append(res, *d)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Pid < res[j].Pid
+	})
+	return res
+}
+
+// DrainProcess 排空进程: 停止接收新的服务器会话、等待服务器会话结束、结束进程
+func (p *ProcessManager) DrainProcess(pro *Process) {
+	timeout := drainTimeout()
+	startedAt := time.Now()
+
+	// 1. 停止接收新的服务器会话, 并通知进程在超时时间内结束
+	p.StartDrain(pro)
+	updateDrainProcessState(pro, common.AppProcessStateTerminating)
+	if pro.Client != nil {
+		_, err := pro.Client.OnProcessTerminate(context.Background(),
+			&processservice.ProcessTerminateRequest{TerminationTime: int64(timeout / time.Minute)})
+		if err != nil {
+			log.RunLogger.Errorf("[process manager] failed to invoke on process %d terminate to process "+
+				"for %v", pro.Pid, err)
+		} else {
+			log.RunLogger.Infof("[process manager] succeed to invoke onProcessTerminate to process %d", pro.Pid)
+		}
+	}
+
+	// 2. 等待服务器会话结束, 超时后强制结束剩余的服务器会话
+	p.UpdateDrainPhase(pro.Pid, common.DrainPhaseWaitingSessions)
+	p.waitServerSessions(pro, startedAt, startedAt.Add(timeout))
+
+	// 3. 结束进程
+	p.UpdateDrainPhase(pro.Pid, common.DrainPhaseTerminating)
+	updateDrainProcessState(pro, common.AppProcessStateTerminated)
+	p.UpdateDrainPhase(pro.Pid, common.DrainPhaseTerminated)
+	log.RunLogger.Infof("[process manager] success to drain process %d in %v", pro.Pid, time.Since(startedAt))
+}
+
+func updateDrainProcessState(pro *Process, s string) {
+	// 未注册的进程在appgateway中没有记录
+	if pro.Id == "" {
+		return
+	}
+	r := &apis.UpdateAppProcessStateRequest{State: s}
+	_, err := clients.GWClient.UpdateProcessState(pro.Id, r)
+	if err != nil {
+		log.RunLogger.Errorf("[process manager] failed to update process %d state to %s for %v", pro.Pid, s, err)
+		return
+	}
+	log.RunLogger.Infof("[process manager] succeed to update process %d state to %s", pro.Pid, s)
+}
+
+func (p *ProcessManager) waitServerSessions(pro *Process, startedAt, deadline time.Time) {
+	if pro.Id == "" {
+		return
+	}
+	for {
+		force := time.Now().After(deadline)
+		active, err := drainServerSessions(pro.Id, startedAt, time.Now(), force)
+		if err != nil {
+			log.RunLogger.Errorf("[process manager] failed to list server sessions of process %d for %v", pro.Pid, err)
+		} else {
+			p.UpdateDrainSessions(pro.Pid, active)
+			if active == 0 {
+				return
+			}
+		}
+		if force {
+			log.RunLogger.Errorf("[process manager] process %d still has server sessions after drain timeout", pro.Pid)
+			return
+		}
+		time.Sleep(drainPollInterval)
+	}
+}
+
+// drainServerSessions 结束不再受保护的服务器会话, 返回仍在运行的服务器会话数
+func drainServerSessions(processID string, startedAt, now time.Time, force bool) (int, error) {
+	res, err := listServerSessions(processID)
+	if err != nil {
+		return 0, err
+	}
+
+	expired, active := splitServerSessions(res.ServerSessions, startedAt, now, force)
+	for _, ss := range expired {
+		if err = terminateServerSession(ss.ID); err != nil {
+			log.RunLogger.Errorf("[process manager] failed to terminate server session %s for %v", ss.ID, err)
+			active++
+			continue
+		}
+		log.RunLogger.Infof("[process manager] success to terminate server session %s with protection policy %s",
+			ss.ID, ss.ProtectionPolicy)
+	}
+	return active, nil
+}
+
+// splitServerSessions 返回需要结束的服务器会话和仍受保护的服务器会话数, 已结束的服务器会话不计入
+func splitServerSessions(sessions []apis.ServerSession, startedAt, now time.Time,
+	force bool) ([]apis.ServerSession, int) {
+	var expired []apis.ServerSession
+	protected := 0
+	for i := range sessions {
+		ss := &sessions[i]
+		if ss.State == common.ServerSessionStateError || ss.State == common.ServerSessionStateTerminated {
+			continue
+		}
+		if !force && isServerSessionProtected(ss, startedAt, now) {
+			protected++
+			continue
+		}
+		expired = append(expired, *ss)
+	}
+	return expired, protected
+}
+
+// isServerSessionProtected 限时保护的服务器会话从排空开始计算保护时间, 完全保护的服务器会话一直等待到排空超时
+func isServerSessionProtected(ss *apis.ServerSession, startedAt, now time.Time) bool {
+	switch ss.ProtectionPolicy {
+	case common.ProtectionPolicyTimeLimitProtection:
+		return now.Before(startedAt.Add(time.Duration(ss.ProtectionTimeLimitMinutes) * time.Minute))
+	case common.ProtectionPolicyFullProtection:
+		return true
+	default:
+		return false
+	}
+}
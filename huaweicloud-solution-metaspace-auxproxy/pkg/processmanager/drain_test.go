@@ -0,0 +1,87 @@
+package processmanager
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/apis"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/common"
+)
+
+func TestProcessManagerDrainStatus(t *testing.T) {
+	mgr := &ProcessManager{drains: newDrainTracker()}
+	process := NewProcess("/local/app/fake-server.sh", "", 3302)
+	process.Id = "app-process-1"
+	process.BizPid = 3303
+	mgr.Processes = append(mgr.Processes, process)
+
+	Convey("drain status test", t, func() {
+		// 未开始排空的进程不能上报进度
+		So(mgr.ReportDrainProgress(3302, 1, "draining"), ShouldNotBeNil)
+
+		mgr.StartDrain(process)
+		statuses := mgr.DrainStatuses()
+		So(len(statuses), ShouldEqual, 1)
+		So(statuses[0].ProcessID, ShouldEqual, "app-process-1")
+		So(statuses[0].Phase, ShouldEqual, common.DrainPhaseStopAccepting)
+		So(statuses[0].Acknowledged, ShouldBeFalse)
+
+		// 进程通过业务pid上报进度
+		mgr.UpdateDrainPhase(3302, common.DrainPhaseWaitingSessions)
+		So(mgr.ReportDrainProgress(3303, 2, "waiting players to leave"), ShouldBeNil)
+		statuses = mgr.DrainStatuses()
+		So(statuses[0].Phase, ShouldEqual, common.DrainPhaseWaitingSessions)
+		So(statuses[0].Acknowledged, ShouldBeTrue)
+		So(statuses[0].ActiveServerSessions, ShouldEqual, 2)
+		So(statuses[0].Message, ShouldEqual, "waiting players to leave")
+
+		// 进程移除后仍然可以查询排空状态
+		mgr.Processes = nil
+		mgr.UpdateDrainSessions(3302, 0)
+		mgr.UpdateDrainPhase(3302, common.DrainPhaseTerminated)
+		statuses = mgr.DrainStatuses()
+		So(statuses[0].Phase, ShouldEqual, common.DrainPhaseTerminated)
+		So(statuses[0].ActiveServerSessions, ShouldEqual, 0)
+	})
+}
+
+func serverSessionIDs(sessions []apis.ServerSession) []string {
+	ids := make([]string, 0, len(sessions))
+	for _, ss := range sessions {
+		ids = append(ids, ss.ID)
+	}
+	return ids
+}
+
+func TestSplitServerSessions(t *testing.T) {
+	sessions := []apis.ServerSession{
+		{ID: "ss-terminated", State: common.ServerSessionStateTerminated,
+			ProtectionPolicy: common.ProtectionPolicyNoProtection},
+		{ID: "ss-no-protection", State: common.ServerSessionStateActive,
+			ProtectionPolicy: common.ProtectionPolicyNoProtection},
+		{ID: "ss-time-limit", State: common.ServerSessionStateActive,
+			ProtectionPolicy: common.ProtectionPolicyTimeLimitProtection, ProtectionTimeLimitMinutes: 10},
+		{ID: "ss-full", State: common.ServerSessionStateActive,
+			ProtectionPolicy: common.ProtectionPolicyFullProtection},
+	}
+	startedAt := time.Now()
+
+	Convey("split server sessions test", t, func() {
+		// 未受保护的服务器会话立即结束
+		expired, protected := splitServerSessions(sessions, startedAt, startedAt, false)
+		So(serverSessionIDs(expired), ShouldResemble, []string{"ss-no-protection"})
+		So(protected, ShouldEqual, 2)
+
+		// 限时保护到期后结束
+		expired, protected = splitServerSessions(sessions, startedAt, startedAt.Add(10*time.Minute), false)
+		So(serverSessionIDs(expired), ShouldResemble, []string{"ss-no-protection", "ss-time-limit"})
+		So(protected, ShouldEqual, 1)
+
+		// 排空超时后强制结束
+		expired, protected = splitServerSessions(sessions, startedAt, startedAt.Add(time.Hour), true)
+		So(serverSessionIDs(expired), ShouldResemble, []string{"ss-no-protection", "ss-time-limit", "ss-full"})
+		So(protected, ShouldEqual, 0)
+	})
+}
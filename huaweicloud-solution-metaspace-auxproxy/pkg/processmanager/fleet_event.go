@@ -0,0 +1,71 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 应用进程事件上报fleetmanager
+package processmanager
+
+import (
+	"fmt"
+	"net/http"
+
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/configmanager"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/utils/clients"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/utils/hhmac"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/utils/log"
+)
+
+const fleetEventCodeProcessCrashLoop = "PROCESS_CRASH_LOOP"
+
+// FleetEventRequest 上报fleet事件的请求
+type FleetEventRequest struct {
+	EventCode    string `json:"event_code"`
+	InstanceID   string `json:"instance_id"`
+	LaunchPath   string `json:"launch_path"`
+	Parameters   string `json:"parameters"`
+	ExitCode     int    `json:"exit_code"`
+	FailureCount int    `json:"failure_count"`
+	StderrTail   string `json:"stderr_tail"`
+}
+
+// sendFleetEvent 上报fleet事件到fleetmanager, 测试时可替换
+var sendFleetEvent = func(fleetID string, event *FleetEventRequest) error {
+	meta, err := configmanager.GetMetaDataConfig()
+	if err != nil {
+		return err
+	}
+
+	// fleetmanager 对内部上报接口强制 hmac 校验，未签名的请求会被拒绝
+	cli, err := clients.NewSignedHttpsClient(hhmac.LocalKeyFleetManager)
+	if err != nil {
+		return err
+	}
+	req, err := clients.JSONEncodeRequest(http.MethodPost,
+		fmt.Sprintf("%s/v1/fleets/%s/events", meta.GlobalServiceAddress, fleetID), event)
+	if err != nil {
+		return err
+	}
+
+	code, _, _, err := clients.DoRequest(cli, req)
+	if err != nil || code != http.StatusCreated {
+		return fmt.Errorf("code %d or err %v", code, err)
+	}
+	return nil
+}
+
+func reportCrashLoopEvent(pro *Process, exitCode, failureCount int, stderrTail string) {
+	event := &FleetEventRequest{
+		EventCode:    fleetEventCodeProcessCrashLoop,
+		InstanceID:   configmanager.ConfMgr.Config.InstanceID,
+		LaunchPath:   pro.LaunchPath,
+		Parameters:   pro.Parameters,
+		ExitCode:     exitCode,
+		FailureCount: failureCount,
+		StderrTail:   stderrTail,
+	}
+	if err := sendFleetEvent(configmanager.ConfMgr.Config.FleetID, event); err != nil {
+		log.RunLogger.Errorf("[process manager] failed to report crash loop event of \"%s %s\" "+
+			"to fleet manager for %v", pro.LaunchPath, pro.Parameters, err)
+		return
+	}
+	log.RunLogger.Infof("[process manager] succeed to report crash loop event of \"%s %s\" to fleet manager",
+		pro.LaunchPath, pro.Parameters)
+}
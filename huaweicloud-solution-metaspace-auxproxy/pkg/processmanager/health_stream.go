@@ -0,0 +1,257 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 应用进程健康流, 进程通过双向流主动推送心跳, 不支持健康流的进程继续使用OnHealthCheck轮询
+package processmanager
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/config"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/common"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/utils/log"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/utils/sdk/processservice"
+)
+
+const (
+	defaultHealthCheckTimeout = 5 * time.Second
+	defaultHealthGracePeriod  = 10 * time.Second
+	// 健康流探测间隔
+	healthStreamProbeInterval = 10 * time.Second
+	healthStreamRetryInterval = 5 * time.Second
+
+	// 心跳中的帧率、玩家数及数值类的自定义详情作为GAUGE指标随进程指标上报appgateway
+	healthMetricTickRate     = "health_tick_rate"
+	healthMetricPlayerCount  = "health_player_count"
+	healthDetailMetricPrefix = "health_"
+)
+
+// healthTimeoutCheckInterval 心跳超时的检查间隔, 与探测间隔无关, 保证宽限期过后及时发现进程不健康
+var healthTimeoutCheckInterval = time.Second
+
+// processHealth 进程通过健康流上报的健康信息
+type processHealth struct {
+	streaming     bool
+	healthy       bool
+	lastHeartbeat time.Time
+}
+
+func healthCheckTimeout() time.Duration {
+	if config.Opts.ProcessHealthCheckTimeout <= 0 {
+		return defaultHealthCheckTimeout
+	}
+	return time.Duration(config.Opts.ProcessHealthCheckTimeout) * time.Second
+}
+
+func healthGracePeriod() time.Duration {
+	if config.Opts.ProcessHealthGracePeriod <= 0 {
+		return defaultHealthGracePeriod
+	}
+	return time.Duration(config.Opts.ProcessHealthGracePeriod) * time.Second
+}
+
+// startHealthStream 进程注册或接管后建立健康流, 进程移除时停止
+func (p *ProcessManager) startHealthStream(process *Process) {
+	ctx, cancel := context.WithCancel(context.Background())
+	process.Mux.Lock()
+	if process.healthCancel != nil {
+		process.healthCancel()
+	}
+	process.healthCancel = cancel
+	process.Mux.Unlock()
+
+	go p.runHealthStream(ctx, process)
+}
+
+func stopHealthStream(process *Process) {
+	process.Mux.Lock()
+	defer process.Mux.Unlock()
+
+	if process.healthCancel != nil {
+		process.healthCancel()
+		process.healthCancel = nil
+	}
+}
+
+func (p *ProcessManager) runHealthStream(ctx context.Context, process *Process) {
+	for {
+		err := p.watchHealthStream(ctx, process)
+		if ctx.Err() != nil {
+			return
+		}
+		process.Mux.Lock()
+		process.health.streaming = false
+		process.Mux.Unlock()
+
+		if status.Code(err) == codes.Unimplemented {
+			log.RunLogger.Infof("[process manager] process %d does not support health stream, "+
+				"use health check polling instead", process.Pid)
+			return
+		}
+		log.RunLogger.Errorf("[process manager] health stream of process %d broken for %v, retry after %v",
+			process.Pid, err, healthStreamRetryInterval)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(healthStreamRetryInterval):
+		}
+	}
+}
+
+// watchHealthStream 接收进程心跳, 定时发送探测并检查心跳是否超时, 流断开时返回
+func (p *ProcessManager) watchHealthStream(ctx context.Context, process *Process) error {
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := process.Client.OnHealthStream(streamCtx)
+	if err != nil {
+		return err
+	}
+
+	heartbeats := make(chan *processservice.HealthHeartbeat)
+	errCh := make(chan error, 1)
+	go func() {
+		for {
+			hb, err := stream.Recv()
+			if err != nil {
+				errCh <- err
+				return
+			}
+			select {
+			case heartbeats <- hb:
+			case <-streamCtx.Done():
+				return
+			}
+		}
+	}()
+
+	// 发送失败时流已断开, 具体原因由Recv返回; 进程不读取探测时Send可能因流控阻塞, 超时后断开流
+	var sequence int64
+	probe := func() error {
+		sequence++
+		sent := make(chan error, 1)
+		go func(req *processservice.HealthStreamRequest) {
+			sent <- stream.Send(req)
+		}(&processservice.HealthStreamRequest{Sequence: sequence})
+
+		var err error
+		select {
+		case err = <-sent:
+		case <-time.After(healthCheckTimeout()):
+			cancel()
+			return status.Errorf(codes.DeadlineExceeded, "send health probe %d timeout", sequence)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if err == nil {
+			return nil
+		}
+		for {
+			select {
+			case err = <-errCh:
+				return err
+			case <-heartbeats:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+
+	if err = probe(); err != nil {
+		return err
+	}
+	probeTicker := time.NewTicker(healthStreamProbeInterval)
+	defer probeTicker.Stop()
+	checkTicker := time.NewTicker(healthTimeoutCheckInterval)
+	defer checkTicker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err = <-errCh:
+			return err
+		case hb := <-heartbeats:
+			p.onHeartbeat(process, hb)
+		case <-checkTicker.C:
+			p.checkHeartbeatTimeout(process)
+		case <-probeTicker.C:
+			if err = probe(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// onHeartbeat 记录心跳并将健康详情计入进程指标, 健康状态变化时立即上报appgateway
+func (p *ProcessManager) onHeartbeat(process *Process, hb *processservice.HealthHeartbeat) {
+	process.Mux.Lock()
+	changed := !process.health.streaming || process.health.healthy != hb.HealthStatus
+	process.health = processHealth{
+		streaming:     true,
+		healthy:       hb.HealthStatus,
+		lastHeartbeat: time.Now(),
+	}
+	id := process.Id
+	process.Mux.Unlock()
+
+	log.RunLogger.Debugf("[process manager] receive heartbeat of process %d, healthy %v, tick rate %d, "+
+		"player count %d, details %v", process.Pid, hb.HealthStatus, hb.TickRate, hb.PlayerCount, hb.Details)
+	if id != "" {
+		if err := p.metrics.add(id, healthMetricSamples(hb)); err != nil {
+			log.RunLogger.Warnf("[process manager] failed to record health metrics of process %d for %v",
+				process.Pid, err)
+		}
+	}
+	if changed {
+		p.reportHealth(process, hb.HealthStatus)
+	}
+}
+
+// healthMetricSamples 将心跳转换为指标采样, 值不是数值或名称不合法的自定义详情不上报
+func healthMetricSamples(hb *processservice.HealthHeartbeat) []MetricSample {
+	samples := []MetricSample{
+		{Name: healthMetricTickRate, Type: common.MetricTypeGauge, Value: float64(hb.TickRate)},
+		{Name: healthMetricPlayerCount, Type: common.MetricTypeGauge, Value: float64(hb.PlayerCount)},
+	}
+	for _, d := range hb.Details {
+		value, err := strconv.ParseFloat(d.Value, 64)
+		name := healthDetailMetricPrefix + d.Key
+		if err != nil || !customMetricNamePattern.MatchString(name) {
+			continue
+		}
+		samples = append(samples, MetricSample{Name: name, Type: common.MetricTypeGauge, Value: value})
+	}
+	return samples
+}
+
+// checkHeartbeatTimeout 超过宽限期没有收到心跳时立即将进程置为不健康
+func (p *ProcessManager) checkHeartbeatTimeout(process *Process) {
+	process.Mux.Lock()
+	timeout := process.health.streaming && process.health.healthy &&
+		time.Since(process.health.lastHeartbeat) > healthGracePeriod()
+	if timeout {
+		process.health.healthy = false
+	}
+	process.Mux.Unlock()
+
+	if timeout {
+		log.RunLogger.Errorf("[process manager] no heartbeat from process %d in %v", process.Pid, healthGracePeriod())
+		p.reportHealth(process, false)
+	}
+}
+
+// streamedHealth 进程已建立健康流时返回流上最近的健康状态
+func (process *Process) streamedHealth() (healthy bool, ok bool) {
+	process.Mux.RLock()
+	defer process.Mux.RUnlock()
+
+	if !process.health.streaming {
+		return false, false
+	}
+	return process.health.healthy && time.Since(process.health.lastHeartbeat) <= healthGracePeriod(), true
+}
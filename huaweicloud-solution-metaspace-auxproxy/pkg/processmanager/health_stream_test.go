@@ -0,0 +1,123 @@
+package processmanager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/config"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/apis"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/common"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/utils/sdk/processservice"
+)
+
+func TestStreamedHealth(t *testing.T) {
+	Convey("streamed health test", t, func() {
+		pro := NewProcess("/local/app/fake-server.sh", "", 3302)
+
+		// 未建立健康流时使用OnHealthCheck轮询
+		_, ok := pro.streamedHealth()
+		So(ok, ShouldBeFalse)
+
+		pro.health = processHealth{streaming: true, healthy: true, lastHeartbeat: time.Now()}
+		healthy, ok := pro.streamedHealth()
+		So(ok, ShouldBeTrue)
+		So(healthy, ShouldBeTrue)
+
+		// 超过宽限期没有心跳视为不健康
+		pro.health.lastHeartbeat = time.Now().Add(-healthGracePeriod() - time.Second)
+		healthy, ok = pro.streamedHealth()
+		So(ok, ShouldBeTrue)
+		So(healthy, ShouldBeFalse)
+
+		pro.health = processHealth{streaming: true, healthy: false, lastHeartbeat: time.Now()}
+		healthy, _ = pro.streamedHealth()
+		So(healthy, ShouldBeFalse)
+	})
+}
+
+func TestHealthMetricSamples(t *testing.T) {
+	Convey("health metric samples test", t, func() {
+		hb := &processservice.HealthHeartbeat{
+			HealthStatus: true,
+			TickRate:     30,
+			PlayerCount:  12,
+			Details: []*processservice.HealthDetail{
+				{Key: "room_count", Value: "4"},
+				{Key: "map", Value: "desert"},
+				{Key: "bad-key", Value: "1"},
+			},
+		}
+
+		// 只有数值类且名称合法的自定义详情作为指标上报
+		samples := healthMetricSamples(hb)
+		So(samples, ShouldResemble, []MetricSample{
+			{Name: healthMetricTickRate, Type: common.MetricTypeGauge, Value: 30},
+			{Name: healthMetricPlayerCount, Type: common.MetricTypeGauge, Value: 12},
+			{Name: "health_room_count", Type: common.MetricTypeGauge, Value: 4},
+		})
+
+		// 随进程指标聚合, 取最新的心跳
+		b := newMetricsBatcher()
+		So(b.add("p1", samples), ShouldBeNil)
+		hb.PlayerCount = 16
+		So(b.add("p1", healthMetricSamples(hb)), ShouldBeNil)
+		So(b.drain(time.Now()), ShouldResemble, []apis.AppProcessMetrics{{ProcessID: "p1", Values: map[string]float64{
+			healthMetricTickRate: 30, healthMetricPlayerCount: 16, "health_room_count": 4,
+		}}})
+	})
+}
+
+// blockedHealthClient 模拟不读取探测的进程, Send和Recv阻塞直到流被取消
+type blockedHealthClient struct {
+	processservice.ProcessGrpcSdkServiceClient
+}
+
+type blockedHealthStream struct {
+	grpc.ClientStream
+	ctx context.Context
+}
+
+func (c *blockedHealthClient) OnHealthStream(ctx context.Context,
+	_ ...grpc.CallOption) (processservice.ProcessGrpcSdkService_OnHealthStreamClient, error) {
+	return &blockedHealthStream{ctx: ctx}, nil
+}
+
+func (s *blockedHealthStream) Send(*processservice.HealthStreamRequest) error {
+	<-s.ctx.Done()
+	return s.ctx.Err()
+}
+
+func (s *blockedHealthStream) Recv() (*processservice.HealthHeartbeat, error) {
+	<-s.ctx.Done()
+	return nil, s.ctx.Err()
+}
+
+func TestWatchHealthStreamSendTimeout(t *testing.T) {
+	Convey("watch health stream send timeout test", t, func() {
+		origin := config.Opts.ProcessHealthCheckTimeout
+		defer func() { config.Opts.ProcessHealthCheckTimeout = origin }()
+		config.Opts.ProcessHealthCheckTimeout = 1
+
+		pro := NewProcess("/local/app/fake-server.sh", "", 3302)
+		pro.Client = &blockedHealthClient{}
+		p := &ProcessManager{}
+
+		// 探测发送阻塞时按超时断开流, 不会一直卡住
+		start := time.Now()
+		err := p.watchHealthStream(context.Background(), pro)
+		So(status.Code(err), ShouldEqual, codes.DeadlineExceeded)
+		So(time.Since(start), ShouldBeLessThan, 3*time.Second)
+
+		// 进程移除时立即退出
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err = p.watchHealthStream(ctx, pro)
+		So(err, ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,145 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 实例资源指标, 与进程指标同周期采样实例的CPU和内存使用率并上报appgateway
+package processmanager
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/apis"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/configmanager"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/utils/clients"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/utils/log"
+)
+
+const percent = 100
+
+// reportInstanceMetrics 上报appgateway, 测试时替换
+var reportInstanceMetrics = func(instanceID string, r *apis.ReportInstanceMetricsRequest) error {
+	return clients.GWClient.ReportInstanceMetrics(instanceID, r)
+}
+
+// cpuTimes 自开机以来的cpu时间累计值, 两次采样的差值用于计算使用率
+type cpuTimes struct {
+	idle  uint64
+	total uint64
+}
+
+// instanceMetricsSampler 保存上一次的cpu采样, 只在work协程中使用
+type instanceMetricsSampler struct {
+	last *cpuTimes
+}
+
+// parseCPUTimes 解析/proc/stat的cpu汇总行, idle包含iowait
+func parseCPUTimes(content string) (*cpuTimes, error) {
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 || fields[0] != "cpu" {
+			continue
+		}
+		times := &cpuTimes{}
+		for i, f := range fields[1:] {
+			v, err := strconv.ParseUint(f, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid cpu time %s for %v", f, err)
+			}
+			// guest和guest_nice已计入user和nice
+			if i < 8 {
+				times.total += v
+			}
+			if i == 3 || i == 4 {
+				times.idle += v
+			}
+		}
+		return times, nil
+	}
+	return nil, fmt.Errorf("cpu line is not found")
+}
+
+// cpuUtilization 两次采样之间非空闲时间的占比, 时间未增长时返回false
+func cpuUtilization(prev, cur *cpuTimes) (float64, bool) {
+	if prev == nil || cur.total <= prev.total || cur.idle < prev.idle {
+		return 0, false
+	}
+	total := float64(cur.total - prev.total)
+	idle := float64(cur.idle - prev.idle)
+	if idle > total {
+		return 0, false
+	}
+	return (total - idle) / total * percent, true
+}
+
+// parseMemoryUtilization 根据/proc/meminfo计算已使用内存的占比, 可回收的缓存视为可用
+func parseMemoryUtilization(content string) (float64, error) {
+	values := map[string]uint64{}
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		v, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		values[strings.TrimSuffix(fields[0], ":")] = v
+	}
+	total, ok := values["MemTotal"]
+	if !ok || total == 0 {
+		return 0, fmt.Errorf("MemTotal is not found")
+	}
+	available, ok := values["MemAvailable"]
+	if !ok {
+		available = values["MemFree"] + values["Buffers"] + values["Cached"]
+	}
+	if available > total {
+		available = total
+	}
+	return float64(total-available) / float64(total) * percent, nil
+}
+
+// sample 采样实例的CPU和内存使用率, 第一次采样只记录cpu基准值, 返回nil
+func (s *instanceMetricsSampler) sample() (*apis.ReportInstanceMetricsRequest, error) {
+	cur, err := readCPUTimes()
+	if err != nil {
+		return nil, err
+	}
+	prev := s.last
+	s.last = cur
+	cpu, ok := cpuUtilization(prev, cur)
+	if !ok {
+		return nil, nil
+	}
+	memory, err := readMemoryUtilization()
+	if err != nil {
+		return nil, err
+	}
+	return &apis.ReportInstanceMetricsRequest{
+		FleetID:           configmanager.ConfMgr.Config.FleetID,
+		ScalingGroupID:    configmanager.ConfMgr.Config.ScalingGroupID,
+		CpuUtilization:    cpu,
+		MemoryUtilization: memory,
+	}, nil
+}
+
+// flushInstanceMetrics 上报实例的CPU和内存使用率, 采样或上报失败时跳过本周期
+func (p *ProcessManager) flushInstanceMetrics() {
+	r, err := p.instanceMetrics.sample()
+	if err != nil {
+		log.RunLogger.Debugf("[process manager] failed to sample instance metrics for %v", err)
+		return
+	}
+	if r == nil {
+		return
+	}
+	instanceID := configmanager.ConfMgr.Config.InstanceID
+	if err = reportInstanceMetrics(instanceID, r); err != nil {
+		log.RunLogger.Errorf("[process manager] failed to report metrics of instance %s for %v", instanceID, err)
+		return
+	}
+	log.RunLogger.Debugf("[process manager] succeed to report metrics %+v of instance %s", *r, instanceID)
+}
@@ -0,0 +1,33 @@
+//go:build linux
+// +build linux
+
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 从procfs读取实例的CPU和内存使用情况
+package processmanager
+
+import (
+	"io/ioutil"
+)
+
+// procfs路径, 测试时替换
+var (
+	procStatPath    = "/proc/stat"
+	procMeminfoPath = "/proc/meminfo"
+)
+
+func readCPUTimes() (*cpuTimes, error) {
+	content, err := ioutil.ReadFile(procStatPath)
+	if err != nil {
+		return nil, err
+	}
+	return parseCPUTimes(string(content))
+}
+
+func readMemoryUtilization() (float64, error) {
+	content, err := ioutil.ReadFile(procMeminfoPath)
+	if err != nil {
+		return 0, err
+	}
+	return parseMemoryUtilization(string(content))
+}
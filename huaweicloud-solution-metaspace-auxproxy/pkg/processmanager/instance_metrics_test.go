@@ -0,0 +1,53 @@
+package processmanager
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestParseCPUTimes(t *testing.T) {
+	Convey("parse cpu times test", t, func() {
+		times, err := parseCPUTimes("cpu  100 0 50 800 50 0 0 0 10 0\ncpu0 50 0 25 400 25 0 0 0 5 0\n")
+		So(err, ShouldBeNil)
+		So(times.total, ShouldEqual, 1000)
+		So(times.idle, ShouldEqual, 850)
+
+		_, err = parseCPUTimes("cpu0 50 0 25 400 25\n")
+		So(err, ShouldNotBeNil)
+		_, err = parseCPUTimes("cpu  100 0 x 800 50\n")
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestCPUUtilization(t *testing.T) {
+	Convey("cpu utilization test", t, func() {
+		prev := &cpuTimes{idle: 800, total: 1000}
+
+		_, ok := cpuUtilization(nil, prev)
+		So(ok, ShouldBeFalse)
+		_, ok = cpuUtilization(prev, prev)
+		So(ok, ShouldBeFalse)
+
+		value, ok := cpuUtilization(prev, &cpuTimes{idle: 950, total: 1200})
+		So(ok, ShouldBeTrue)
+		So(value, ShouldAlmostEqual, 25)
+	})
+}
+
+func TestParseMemoryUtilization(t *testing.T) {
+	Convey("parse memory utilization test", t, func() {
+		value, err := parseMemoryUtilization("MemTotal:       1000 kB\nMemFree:         100 kB\n" +
+			"MemAvailable:    400 kB\nBuffers:          50 kB\nCached:          200 kB\n")
+		So(err, ShouldBeNil)
+		So(value, ShouldAlmostEqual, 60)
+
+		// 老内核没有MemAvailable时按空闲和缓存估算
+		value, err = parseMemoryUtilization("MemTotal: 1000 kB\nMemFree: 100 kB\nBuffers: 50 kB\nCached: 250 kB\n")
+		So(err, ShouldBeNil)
+		So(value, ShouldAlmostEqual, 60)
+
+		_, err = parseMemoryUtilization("MemFree: 100 kB\n")
+		So(err, ShouldNotBeNil)
+	})
+}
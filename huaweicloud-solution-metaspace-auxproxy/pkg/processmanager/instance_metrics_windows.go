@@ -0,0 +1,19 @@
+//go:build windows
+// +build windows
+
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// windows暂不支持采样实例资源指标
+package processmanager
+
+import (
+	"fmt"
+)
+
+func readCPUTimes() (*cpuTimes, error) {
+	return nil, fmt.Errorf("instance metrics are not supported on windows")
+}
+
+func readMemoryUtilization() (float64, error) {
+	return 0, fmt.Errorf("instance metrics are not supported on windows")
+}
@@ -0,0 +1,285 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 应用进程标准输出和标准错误日志
+package processmanager
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/config"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/utils/log"
+)
+
+const (
+	ProcessOutputStdout = "stdout"
+	ProcessOutputStderr = "stderr"
+
+	processLogRotateInterval = 10 * time.Second
+	// 读取日志尾部时最多读取的字节数
+	maxTailReadBytes = 1 << 20
+	stderrTailSize   = 4096
+)
+
+// openProcessLogs 为进程创建独立的日志目录, 进程直接写文件, auxproxy重启不影响进程输出
+func openProcessLogs(launchPath string) (string, *os.File, *os.File, error) {
+	if err := os.MkdirAll(config.Opts.ProcessLogDir, 0750); err != nil {
+		return "", nil, nil, err
+	}
+	dir, err := ioutil.TempDir(config.Opts.ProcessLogDir, filepath.Base(launchPath)+"-")
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	stdout, err := openLogFile(processLogFile(dir, ProcessOutputStdout))
+	if err != nil {
+		return "", nil, nil, err
+	}
+	stderr, err := openLogFile(processLogFile(dir, ProcessOutputStderr))
+	if err != nil {
+		_ = stdout.Close()
+		return "", nil, nil, err
+	}
+	return dir, stdout, stderr, nil
+}
+
+func openLogFile(name string) (*os.File, error) {
+	return os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+}
+
+func processLogFile(dir, stream string) string {
+	return filepath.Join(dir, stream+".log")
+}
+
+// processLogDirOf 从接管进程的日志路径中找出auxproxy创建的日志目录
+func processLogDirOf(logPath []string) string {
+	prefix := filepath.Clean(config.Opts.ProcessLogDir) + string(filepath.Separator)
+	for _, p := range logPath {
+		if config.Opts.ProcessLogDir != "" && strings.HasPrefix(filepath.Clean(p), prefix) {
+			return p
+		}
+	}
+	return ""
+}
+
+// rotateLogFile 日志超过大小限制时复制为备份后截断; 进程以追加方式写文件, 截断后无需重新打开
+func rotateLogFile(name string, maxSize int64, maxBackups int) error {
+	info, err := os.Stat(name)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if info.Size() < maxSize {
+		return nil
+	}
+
+	if maxBackups > 0 {
+		for i := maxBackups - 1; i > 0; i-- {
+			_ = os.Rename(fmt.Sprintf("%s.%d", name, i), fmt.Sprintf("%s.%d", name, i+1))
+		}
+		if err = copyFile(name, name+".1"); err != nil {
+			return err
+		}
+	}
+	return os.Truncate(name, 0)
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0640)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// uploadedLogPath 进程退出时立即清理的日志路径; auxproxy创建的标准输出日志目录保留到过期后再清理,
+// 以便转储采集进程退出前的输出
+func (pro *Process) uploadedLogPath() []string {
+	var paths []string
+	for _, p := range pro.LogPath {
+		if pro.outputLogDir != "" && filepath.Clean(p) == filepath.Clean(pro.outputLogDir) {
+			continue
+		}
+		paths = append(paths, p)
+	}
+	return paths
+}
+
+// manageProcessLogs 定时轮转和清理进程日志, 复制大文件较慢, 不阻塞进程的启动和健康检查
+func (p *ProcessManager) manageProcessLogs() {
+	ticker := time.NewTicker(processLogRotateInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stopCh:
+			return
+		case <-ticker.C:
+			p.rotateProcessLogs()
+			p.cleanExpiredProcessLogs()
+		}
+	}
+}
+
+// cleanExpiredProcessLogs 清理已退出进程的标准输出日志目录, 最后一次写入超过保留时间才删除
+func (p *ProcessManager) cleanExpiredProcessLogs() {
+	if config.Opts.ProcessLogDir == "" {
+		return
+	}
+	running := map[string]bool{}
+	for _, pro := range p.GetAllRunningProcesses() {
+		if pro.outputLogDir != "" {
+			running[filepath.Clean(pro.outputLogDir)] = true
+		}
+	}
+	dirs, err := expiredProcessLogDirs(config.Opts.ProcessLogDir, running,
+		time.Duration(config.Opts.ProcessLogRetention)*time.Minute)
+	if err != nil {
+		log.RunLogger.Errorf("[process manager] failed to read process log dir for %v", err)
+		return
+	}
+	for _, dir := range dirs {
+		if err = os.RemoveAll(dir); err != nil {
+			log.RunLogger.Errorf("[process manager] failed to remove expired process log %s for %v", dir, err)
+			continue
+		}
+		log.RunLogger.Infof("[process manager] remove expired process log %s", dir)
+	}
+}
+
+// expiredProcessLogDirs 返回不属于运行中进程且超过保留时间没有写入的日志目录
+func expiredProcessLogDirs(logDir string, running map[string]bool, retention time.Duration) ([]string, error) {
+	entries, err := ioutil.ReadDir(logDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var dirs []string
+	for _, entry := range entries {
+		dir := filepath.Join(logDir, entry.Name())
+		if !entry.IsDir() || running[filepath.Clean(dir)] {
+			continue
+		}
+		if time.Since(lastModified(dir, entry.ModTime())) >= retention {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs, nil
+}
+
+// lastModified 返回目录下日志文件最后一次写入的时间
+func lastModified(dir string, since time.Time) time.Time {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return since
+	}
+	for _, f := range files {
+		if f.ModTime().After(since) {
+			since = f.ModTime()
+		}
+	}
+	return since
+}
+
+// rotateProcessLogs 轮转所有进程的标准输出和标准错误日志
+func (p *ProcessManager) rotateProcessLogs() {
+	maxSize := int64(config.Opts.ProcessLogMaxSizeMB) << 20
+	for _, pro := range p.GetAllRunningProcesses() {
+		if pro.outputLogDir == "" {
+			continue
+		}
+		for _, stream := range []string{ProcessOutputStdout, ProcessOutputStderr} {
+			name := processLogFile(pro.outputLogDir, stream)
+			if err := rotateLogFile(name, maxSize, config.Opts.ProcessLogMaxBackups); err != nil {
+				log.RunLogger.Errorf("[process manager] failed to rotate process log %s for %v", name, err)
+			}
+		}
+	}
+}
+
+// readTail 读取文件最后n个字节
+func readTail(name string, n int64) ([]byte, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() < n {
+		n = info.Size()
+	}
+	buf := make([]byte, n)
+	read, err := f.ReadAt(buf, info.Size()-n)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	return buf[:read], nil
+}
+
+// tailLines 读取文件最后n行
+func tailLines(name string, n int) ([]byte, error) {
+	buf, err := readTail(name, maxTailReadBytes)
+	if err != nil {
+		return nil, err
+	}
+	lines := bytes.Split(bytes.TrimSuffix(buf, []byte("\n")), []byte("\n"))
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	if len(buf) == 0 {
+		return buf, nil
+	}
+	return append(bytes.Join(lines, []byte("\n")), '\n'), nil
+}
+
+// TailProcessOutput 查询进程标准输出或标准错误的最后n行
+func (p *ProcessManager) TailProcessOutput(pid int, stream string, n int) ([]byte, error) {
+	if stream != ProcessOutputStdout && stream != ProcessOutputStderr {
+		return nil, fmt.Errorf("invalid stream %s", stream)
+	}
+	p.ProcessMux.RLock()
+	pro := p.GetProcess(pid)
+	p.ProcessMux.RUnlock()
+	if pro == nil || pro.outputLogDir == "" {
+		return nil, os.ErrNotExist
+	}
+	return tailLines(processLogFile(pro.outputLogDir, stream), n)
+}
+
+// stderrTail 获取进程标准错误输出的尾部, 用于进程异常退出时上报
+func (pro *Process) stderrTail() string {
+	if pro.outputLogDir == "" {
+		return ""
+	}
+	buf, err := readTail(processLogFile(pro.outputLogDir, ProcessOutputStderr), stderrTailSize)
+	if err != nil {
+		log.RunLogger.Errorf("[process manager] failed to read stderr of process %d for %v", pro.Pid, err)
+		return ""
+	}
+	return string(buf)
+}
@@ -0,0 +1,107 @@
+package processmanager
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTailLines(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "stdout.log")
+
+	Convey("tail lines test", t, func() {
+		So(ioutil.WriteFile(name, []byte("line1\nline2\nline3\n"), 0640), ShouldBeNil)
+
+		buf, err := tailLines(name, 2)
+		So(err, ShouldBeNil)
+		So(string(buf), ShouldEqual, "line2\nline3\n")
+
+		buf, err = tailLines(name, 10)
+		So(err, ShouldBeNil)
+		So(string(buf), ShouldEqual, "line1\nline2\nline3\n")
+
+		buf, err = readTail(name, 6)
+		So(err, ShouldBeNil)
+		So(string(buf), ShouldEqual, "line3\n")
+	})
+}
+
+func TestRotateLogFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "stderr.log")
+
+	Convey("rotate log file test", t, func() {
+		// 模拟进程以追加方式持有文件
+		f, err := openLogFile(name)
+		So(err, ShouldBeNil)
+		defer f.Close()
+
+		for i := 1; i <= 3; i++ {
+			_, err = f.WriteString(strings.Repeat(fmt.Sprint(i), 10))
+			So(err, ShouldBeNil)
+			So(rotateLogFile(name, 10, 2), ShouldBeNil)
+		}
+
+		// 只保留两个备份, 截断后进程继续写入原文件开头
+		buf, _ := ioutil.ReadFile(name + ".1")
+		So(string(buf), ShouldEqual, strings.Repeat("3", 10))
+		buf, _ = ioutil.ReadFile(name + ".2")
+		So(string(buf), ShouldEqual, strings.Repeat("2", 10))
+		_, err = os.Stat(name + ".3")
+		So(os.IsNotExist(err), ShouldBeTrue)
+
+		_, err = f.WriteString("4")
+		So(err, ShouldBeNil)
+		buf, _ = ioutil.ReadFile(name)
+		So(string(buf), ShouldEqual, "4")
+
+		// 未超过大小时不轮转
+		So(rotateLogFile(name, 10, 2), ShouldBeNil)
+		buf, _ = ioutil.ReadFile(name + ".1")
+		So(string(buf), ShouldEqual, strings.Repeat("3", 10))
+	})
+}
+
+func TestExpiredProcessLogDirs(t *testing.T) {
+	logDir := t.TempDir()
+
+	Convey("expired process log dirs test", t, func() {
+		old := time.Now().Add(-2 * time.Hour)
+		exited := filepath.Join(logDir, "exited")
+		recent := filepath.Join(logDir, "recent")
+		running := filepath.Join(logDir, "running")
+		for _, dir := range []string{exited, recent, running} {
+			So(os.MkdirAll(dir, 0750), ShouldBeNil)
+			So(ioutil.WriteFile(processLogFile(dir, ProcessOutputStdout), []byte("out\n"), 0640), ShouldBeNil)
+			So(os.Chtimes(processLogFile(dir, ProcessOutputStdout), old, old), ShouldBeNil)
+			So(os.Chtimes(dir, old, old), ShouldBeNil)
+		}
+		// 退出前刚写入的输出还没有超过保留时间
+		So(ioutil.WriteFile(processLogFile(recent, ProcessOutputStderr), []byte("err\n"), 0640), ShouldBeNil)
+
+		dirs, err := expiredProcessLogDirs(logDir, map[string]bool{running: true}, time.Hour)
+		So(err, ShouldBeNil)
+		So(dirs, ShouldResemble, []string{exited})
+
+		dirs, err = expiredProcessLogDirs(filepath.Join(logDir, "absent"), nil, time.Hour)
+		So(err, ShouldBeNil)
+		So(dirs, ShouldBeEmpty)
+	})
+}
+
+func TestUploadedLogPath(t *testing.T) {
+	Convey("uploaded log path test", t, func() {
+		// 标准输出日志目录不随进程退出立即删除
+		pro := &Process{outputLogDir: "/local/app/process-logs/server-1",
+			LogPath: []string{"/local/app/game/logs", "/local/app/process-logs/server-1/"}}
+		So(pro.uploadedLogPath(), ShouldResemble, []string{"/local/app/game/logs"})
+
+		pro = &Process{LogPath: []string{"/local/app/game/logs"}}
+		So(pro.uploadedLogPath(), ShouldResemble, []string{"/local/app/game/logs"})
+	})
+}
@@ -53,6 +53,20 @@ type Process struct {
 	Status       string
 	LogPath      []string
 	Client       processservice.ProcessGrpcSdkServiceClient
+	// 进程启动时生效的应用包目录, 接管的进程为空
+	BuildDir string
+
+	// 自启动进程的启动时间和退出信息, 用于重启退避和crash loop检测
+	startedAt time.Time
+	exitedAt  time.Time
+	exitCode  int
+	// 进程标准输出和标准错误日志目录
+	outputLogDir string
+	// 进程资源限制所在的cgroup目录, 未限制cpu和内存时为空
+	cgroupDir string
+	// 健康流上报的健康信息
+	health       processHealth
+	healthCancel context.CancelFunc
 
 	// 记录server session是否启动过
 	ServerSessionStartedMap map[string]bool
@@ -87,6 +101,12 @@ type ProcessManager struct {
 
 	WaitChan chan int
 	stopCh   chan struct{}
+
+	restarts *restartTracker
+	metrics  *metricsBatcher
+	drains   *drainTracker
+
+	instanceMetrics *instanceMetricsSampler
 }
 
 // ProcessMgr process manager
@@ -103,6 +123,10 @@ func InitProcessManager() {
 			ProcessMux:            sync.RWMutex{},
 			Processes:             []*Process{},
 			stopCh:                make(chan struct{}, 0),
+			restarts:              newRestartTracker(),
+			metrics:               newMetricsBatcher(),
+			drains:                newDrainTracker(),
+			instanceMetrics:       &instanceMetricsSampler{},
 		}
 	})
 }
@@ -114,6 +138,7 @@ func (p *ProcessManager) TakeOverRunningProcess(process *Process) error {
 
 	process.isTakeOver = true
 	process.isRegistered = true
+	process.outputLogDir = processLogDirOf(process.LogPath)
 
 	p.Processes = append(p.Processes, process)
 	conn, err := grpc.Dial(fmt.Sprintf("%s:%d", "localhost", process.GrpcPort), grpc.WithInsecure())
@@ -124,6 +149,7 @@ func (p *ProcessManager) TakeOverRunningProcess(process *Process) error {
 
 	cli := processservice.NewProcessGrpcSdkServiceClient(conn)
 	process.Client = cli
+	p.startHealthStream(process)
 
 	log.RunLogger.Infof("[process manager] success take overprocess, the pid is %d and "+
 		"bizpid is %d", process.Pid, process.BizPid)
@@ -186,6 +212,17 @@ func (p *ProcessManager) GetAllRunningProcesses() []*Process {
 	return processCopy
 }
 
+// findResourceLimits 查找启动配置对应的资源限制
+func findResourceLimits(launchPath, parameters string) *apis.ResourceLimits {
+	pcs := configmanager.ConfMgr.Config.InstanceConfig.RuntimeConfiguration.ProcessConfiguration
+	for _, pc := range pcs {
+		if pc.LaunchPath == launchPath && pc.Parameters == parameters {
+			return pc.ResourceLimits
+		}
+	}
+	return nil
+}
+
 func (p *ProcessManager) consistProcessByConfiguration() {
 	log.RunLogger.Infof("[process manager] consist process, check existed processes")
 
@@ -235,7 +272,8 @@ func (p *ProcessManager) checkExistedProcess(launchPath, parameters string) (boo
 }
 
 // RegisterProcess 业务进程注册
-func (p *ProcessManager) RegisterProcess(pid int, clientPort, grpcPort int, logPath []string) error {
+func (p *ProcessManager) RegisterProcess(pid int, clientPort, grpcPort int, logPath []string,
+	labels map[string]string) error {
 	// 设置bizPid，并校验是否是合法
 	process := p.InitBizPid(pid)
 	if process == nil {
@@ -243,13 +281,18 @@ func (p *ProcessManager) RegisterProcess(pid int, clientPort, grpcPort int, logP
 	}
 
 	// 上报appgateway注册
-	res, err := registerProcess(process.Pid, process.BizPid, process.LaunchPath, process.Parameters)
+	res, err := registerProcess(process.Pid, process.BizPid, process.LaunchPath, process.Parameters, labels)
 	if err != nil {
 		log.RunLogger.Errorf("[process manager] failed to create process to gateway for %v", err)
 		return fmt.Errorf("failed to add process to gateway")
 	}
 	log.RunLogger.Infof("[process manager] succeed to register an app process to app gateway")
 
+	// auxproxy创建的标准输出日志目录随进程日志一起上报, 以便日志清理和转储
+	if process.outputLogDir != "" {
+		logPath = append(logPath, process.outputLogDir)
+	}
+
 	// 上报appgateway补充process信息
 	_, err = updateProcess(clientPort, grpcPort, logPath, res.AppProcess.ID)
 
@@ -267,6 +310,7 @@ func (p *ProcessManager) RegisterProcess(pid int, clientPort, grpcPort int, logP
 	cli := processservice.NewProcessGrpcSdkServiceClient(conn)
 	process.Client = cli
 	process.isRegistered = true
+	p.startHealthStream(process)
 
 	log.RunLogger.Infof("[process manager] success register process, the pid is %d and "+
 		"bizpid is %d", process.Pid, process.BizPid)
@@ -341,6 +385,7 @@ func (p *ProcessManager) RemoveProcess(pid int) {
 		log.RunLogger.Errorf("[process manager] remove not exist process %d", pid)
 		return
 	}
+	stopHealthStream(p.Processes[idx])
 	p.Processes = append(p.Processes[:idx], p.Processes[idx+1:]...)
 
 	log.RunLogger.Infof("[process manager] success remove process %d", pid)
@@ -352,6 +397,8 @@ func (p *ProcessManager) Work() {
 
 	go p.work()
 
+	go p.manageProcessLogs()
+
 	log.RunLogger.Infof("[process manager] succeed to start process manager")
 }
 
@@ -363,6 +410,7 @@ func (p *ProcessManager) Stop() {
 func (p *ProcessManager) work() {
 	processTicker := time.NewTicker(newProcessLaunchInterval)
 	healthCheckTicker := time.NewTicker(processHealthCheckInterval)
+	metricsFlushTicker := time.NewTicker(processMetricsFlushInterval)
 
 	// 立即执行一次
 	p.consistProcessByConfiguration()
@@ -373,6 +421,7 @@ func (p *ProcessManager) work() {
 		case <-p.stopCh:
 			processTicker.Stop()
 			healthCheckTicker.Stop()
+			metricsFlushTicker.Stop()
 			log.RunLogger.Infof("[process manager] stop the process manager")
 			return
 		case <-processTicker.C:
@@ -389,7 +438,7 @@ func (p *ProcessManager) work() {
 			p.ProcessMux.RUnlock()
 
 			wg := sync.WaitGroup{}
-			wg.Add(len(p.Processes))
+			wg.Add(len(processCopy))
 			for _, process := range processCopy {
 				// 自管理进程
 				go func(process *Process) {
@@ -399,6 +448,9 @@ func (p *ProcessManager) work() {
 				}(process)
 			}
 			wg.Wait()
+		case <-metricsFlushTicker.C:
+			p.flushMetrics()
+			p.flushInstanceMetrics()
 		}
 	}
 }
@@ -407,13 +459,19 @@ func (p *ProcessManager) launchProcess() {
 	// start new process
 	log.RunLogger.Infof("[process manager] luanch process")
 	var newToBeStartedPros []*BasicProcessInfo
+	now := time.Now()
 	p.ToBeStartedProcessMux.Lock()
 	for _, toBeStartedPro := range p.ToBeStartedProcess {
-		cmd := exec.Command(toBeStartedPro.LaunchPath, strings.Split(toBeStartedPro.Parameters, " ")...)
+		// 退避时间未到的启动配置留在队列中, 等待下一轮启动
+		if !p.restarts.canLaunch(toBeStartedPro.LaunchPath, toBeStartedPro.Parameters, now) {
+			log.RunLogger.Infof("[process manager] process \"%s %s\" is in restart backoff, skip launching",
+				toBeStartedPro.LaunchPath, toBeStartedPro.Parameters)
+			newToBeStartedPros = append(newToBeStartedPros, toBeStartedPro)
+			continue
+		}
 
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
-		err := cmd.Start()
+		limits := findResourceLimits(toBeStartedPro.LaunchPath, toBeStartedPro.Parameters)
+		cmd, pro, err := startProcess(toBeStartedPro.LaunchPath, toBeStartedPro.Parameters, limits)
 		if err != nil {
 			log.RunLogger.Errorf("[process manager] failed to exec command \"%s %s\" for %v",
 				toBeStartedPro.LaunchPath, toBeStartedPro.Parameters, err)
@@ -421,13 +479,13 @@ func (p *ProcessManager) launchProcess() {
 			continue
 		}
 
-		go wait(cmd, p)
-
+		// 先加入进程列表再等待退出, 避免进程立即退出时receiveWaitSig找不到进程
 		p.ProcessMux.Lock()
-		p.Processes = append(p.Processes, NewProcess(toBeStartedPro.LaunchPath,
-			toBeStartedPro.Parameters, cmd.Process.Pid))
+		p.Processes = append(p.Processes, pro)
 		p.ProcessMux.Unlock()
 
+		go wait(cmd, pro, p)
+
 		log.RunLogger.Infof("[process manager] start process \"%s %s\"",
 			toBeStartedPro.LaunchPath, toBeStartedPro.Parameters)
 	}
@@ -452,25 +510,145 @@ func (p *ProcessManager) receiveWaitSig() {
 					"do not need to do remove in receiveWaitSig", pid)
 				continue
 			}
-			r := &apis.UpdateAppProcessStateRequest{State: common.AppProcessStateTerminated}
-			_, err := clients.GWClient.UpdateProcessState(pro.Id, r)
-			if err != nil {
-				log.RunLogger.Errorf("[health checker] set process %v to terminated to gateway", p)
-			}
-			err = clean.DeleteLogFiles(pro.LogPath)
-			if err != nil {
-				log.RunLogger.Errorf("[health checker] failed to clean process %v logs for %v", p, err)
-			}
-			p.RemoveProcess(pro.Pid)
+			p.handleProcessExit(pro)
 		}
 	}
 }
 
-func wait(cmd *exec.Cmd, p *ProcessManager) {
+// handleProcessExit 记录进程退出并更新appgateway中的进程状态, 连续失败的启动配置进入crash loop
+func (p *ProcessManager) handleProcessExit(pro *Process) {
+	pro.Mux.RLock()
+	exitCode := pro.exitCode
+	uptime := pro.exitedAt.Sub(pro.startedAt)
+	pro.Mux.RUnlock()
+
+	reason := limitKillReason(pro)
+	releaseResourceLimits(pro)
+
+	res := p.restarts.recordExit(pro.LaunchPath, pro.Parameters, exitCode, uptime, time.Now())
+	if res.Failed {
+		log.RunLogger.Errorf("[process manager] process %d \"%s %s\" exited with code %d after %v, "+
+			"reason %q, %d failures in window, next launch after %v", pro.Pid, pro.LaunchPath, pro.Parameters,
+			exitCode, uptime, reason, res.FailureCount, res.Backoff)
+	}
+	if res.RecoveredProcessID != "" {
+		terminateCrashLoopProcess(res.RecoveredProcessID)
+	}
+
+	state := common.AppProcessStateTerminated
+	if res.CrashLooping {
+		state = p.markCrashLoop(pro)
+	}
+	if pro.Id != "" {
+		r := &apis.UpdateAppProcessStateRequest{State: state, StateReason: reason}
+		_, err := clients.GWClient.UpdateProcessState(pro.Id, r)
+		if err != nil {
+			log.RunLogger.Errorf("[health checker] set process %s to %s to gateway", pro.Id, state)
+		}
+	}
+	if res.EnteredCrashLoop {
+		reportCrashLoopEvent(pro, exitCode, res.FailureCount, pro.stderrTail())
+	}
+
+	err := clean.DeleteLogFiles(pro.uploadedLogPath())
+	if err != nil {
+		log.RunLogger.Errorf("[health checker] failed to clean process %v logs for %v", p, err)
+	}
+	p.RemoveProcess(pro.Pid)
+}
+
+// markCrashLoop 返回退出进程在appgateway中应置的状态, 每个启动配置只保留一个CRASH_LOOP进程
+func (p *ProcessManager) markCrashLoop(pro *Process) string {
+	if p.restarts.crashLoopProcessID(pro.LaunchPath, pro.Parameters) != "" {
+		return common.AppProcessStateTerminated
+	}
+	if pro.Id == "" {
+		// 进程未注册就已退出, 补充注册使appgateway可以呈现crash loop状态
+		res, err := registerProcess(pro.Pid, pro.Pid, pro.LaunchPath, pro.Parameters, nil)
+		if err != nil {
+			log.RunLogger.Errorf("[process manager] failed to register crash loop process %d to gateway "+
+				"for %v", pro.Pid, err)
+			return common.AppProcessStateTerminated
+		}
+		pro.Id = res.AppProcess.ID
+	}
+	p.restarts.setCrashLoopProcessID(pro.LaunchPath, pro.Parameters, pro.Id)
+	return common.AppProcessStateCrashLoop
+}
+
+// recoverIfStable 进程稳定运行后清空重启历史, 并将之前记录的CRASH_LOOP进程置为终止
+func (p *ProcessManager) recoverIfStable(process *Process) {
+	if process.startedAt.IsZero() {
+		return
+	}
+	id := p.restarts.resetIfStable(process.LaunchPath, process.Parameters, time.Since(process.startedAt))
+	if id != "" {
+		terminateCrashLoopProcess(id)
+	}
+}
+
+func terminateCrashLoopProcess(id string) {
+	r := &apis.UpdateAppProcessStateRequest{State: common.AppProcessStateTerminated}
+	if _, err := clients.GWClient.UpdateProcessState(id, r); err != nil {
+		log.RunLogger.Errorf("[process manager] failed to set crash loop process %s to terminated for %v", id, err)
+		return
+	}
+	log.RunLogger.Infof("[process manager] process %s recovered from crash loop", id)
+}
+
+// LimitedExecArg 以该参数启动auxproxy时作为资源限制包装进程, 见RunLimitedExec
+const LimitedExecArg = "--limited-exec"
+
+// limitedExecExitCode 包装进程无法施加资源限制时的退出码
+const limitedExecExitCode = 125
+
+// startProcess 从当前生效的应用包目录启动进程, 标准输出和标准错误写入进程独立的日志文件;
+// 设置了资源限制时通过包装进程启动, 限制无法施加时不启动应用进程
+func startProcess(launchPath, parameters string, limits *apis.ResourceLimits) (*exec.Cmd, *Process, error) {
+	dir, stdout, stderr, err := openProcessLogs(launchPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open process logs for %v", err)
+	}
+	// 子进程持有文件描述符, 父进程在启动后即可关闭
+	defer stdout.Close()
+	defer stderr.Close()
+
+	buildDir := ActiveBuildDir()
+	cgroupDir, name, args, err := wrapResourceLimits(resolveLaunchPath(launchPath, buildDir),
+		strings.Split(parameters, " "), limits)
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, nil, fmt.Errorf("failed to prepare resource limits %+v for %v", limits, err)
+	}
+	cmd := exec.Command(name, args...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err = cmd.Start(); err != nil {
+		_ = os.RemoveAll(dir)
+		removeProcessCgroup(cgroupDir)
+		return nil, nil, err
+	}
+
+	pro := NewProcess(launchPath, parameters, cmd.Process.Pid)
+	pro.startedAt = time.Now()
+	pro.BuildDir = buildDir
+	pro.outputLogDir = dir
+	pro.LogPath = []string{dir}
+	pro.cgroupDir = cgroupDir
+	return cmd, pro, nil
+}
+
+func wait(cmd *exec.Cmd, pro *Process, p *ProcessManager) {
 	err := cmd.Wait()
 	if err != nil {
 		log.RunLogger.Errorf("[process manager] cmd wait error for %v", err)
 	}
+
+	pro.Mux.Lock()
+	pro.exitCode = cmd.ProcessState.ExitCode()
+	pro.exitedAt = time.Now()
+	pro.Mux.Unlock()
+
 	p.WaitChan <- cmd.Process.Pid
 }
 
@@ -478,7 +656,14 @@ func (p *ProcessManager) healthCheck(process *Process) {
 	if !process.isRegistered {
 		return
 	}
-	ctx := context.Background()
+	// 已建立健康流的进程直接使用流上的心跳, 定时上报以刷新appgateway中的进程更新时间
+	if healthy, ok := process.streamedHealth(); ok {
+		p.reportHealth(process, healthy)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout())
+	defer cancel()
 	res, err := process.Client.OnHealthCheck(ctx, &processservice.HealthCheckRequest{})
 	if err != nil {
 		// 无响应，打印信息，直接跳过
@@ -495,7 +680,7 @@ func (p *ProcessManager) healthCheck(process *Process) {
 			if err != nil {
 				log.RunLogger.Errorf("[health checker] set process %v to terminated to gateway", p)
 			}
-			err = clean.DeleteLogFiles(process.LogPath)
+			err = clean.DeleteLogFiles(process.uploadedLogPath())
 			if err != nil {
 				log.RunLogger.Errorf("[health checker] failed to clean process %v logs for %v", p, err)
 			}
@@ -503,16 +688,24 @@ func (p *ProcessManager) healthCheck(process *Process) {
 		}
 		return
 	}
-	if res.HealthStatus {
-		process.Status = common.AppProcessStateActive
-	} else {
-		process.Status = common.AppProcessStateError
+	p.reportHealth(process, res.HealthStatus)
+}
+
+// reportHealth 根据健康状态更新appgateway中的进程状态
+func (p *ProcessManager) reportHealth(process *Process, healthy bool) {
+	state := common.AppProcessStateError
+	if healthy {
+		state = common.AppProcessStateActive
+		p.recoverIfStable(process)
 	}
+	process.Mux.Lock()
+	process.Status = state
+	process.Mux.Unlock()
 
 	r := &apis.UpdateAppProcessStateRequest{
-		State: process.Status,
+		State: state,
 	}
-	_, err = clients.GWClient.UpdateProcessState(process.Id, r)
+	_, err := clients.GWClient.UpdateProcessState(process.Id, r)
 	if err != nil {
 		log.RunLogger.Errorf("[process manager] failed to update process state for %v"+
 			" in health check", err)
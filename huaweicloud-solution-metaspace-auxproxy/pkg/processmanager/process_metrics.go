@@ -0,0 +1,154 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 应用进程自定义指标, 进程通过SDK上报的指标在本地聚合后批量上报appgateway
+package processmanager
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/apis"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/common"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/utils/clients"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/utils/log"
+)
+
+const (
+	processMetricsFlushInterval = 10 * time.Second
+	// 单个进程最多保留的指标个数, 与appgateway的限制一致
+	maxMetricsPerProcess = 100
+)
+
+// customMetricNamePattern 指标名称会作为influxdb字段名, 与appgateway的校验一致
+var customMetricNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{0,63}$`)
+
+// reportProcessMetrics 上报appgateway, 测试时替换
+var reportProcessMetrics = func(r *apis.ReportAppProcessMetricsRequest) error {
+	return clients.GWClient.ReportProcessMetrics(r)
+}
+
+// MetricSample 进程上报的单个指标采样
+type MetricSample struct {
+	Name  string
+	Type  string
+	Value float64
+}
+
+// metricsBatcher 按进程聚合指标, GAUGE取最新值, COUNTER累加增量并在上报时换算为每秒速率,
+// 使两类指标都可以按伸缩组取平均值
+type metricsBatcher struct {
+	mux      sync.Mutex
+	metrics  map[string]map[string]float64
+	counters map[string]map[string]bool
+	since    time.Time
+}
+
+func newMetricsBatcher() *metricsBatcher {
+	return &metricsBatcher{
+		metrics:  map[string]map[string]float64{},
+		counters: map[string]map[string]bool{},
+		since:    time.Now(),
+	}
+}
+
+func validateMetricSample(s MetricSample) error {
+	if !customMetricNamePattern.MatchString(s.Name) {
+		return fmt.Errorf("invalid metric name %s", s.Name)
+	}
+	if s.Type != common.MetricTypeGauge && s.Type != common.MetricTypeCounter {
+		return fmt.Errorf("invalid type %s of metric %s", s.Type, s.Name)
+	}
+	return nil
+}
+
+// add 校验全部采样通过后再聚合, 避免部分写入
+func (b *metricsBatcher) add(processID string, samples []MetricSample) error {
+	for _, s := range samples {
+		if err := validateMetricSample(s); err != nil {
+			return err
+		}
+	}
+
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	values, ok := b.metrics[processID]
+	if !ok {
+		values = map[string]float64{}
+		b.metrics[processID] = values
+	}
+	for _, s := range samples {
+		if _, exist := values[s.Name]; !exist && len(values) >= maxMetricsPerProcess {
+			return fmt.Errorf("process reports more than %d metrics", maxMetricsPerProcess)
+		}
+		if s.Type == common.MetricTypeCounter {
+			values[s.Name] += s.Value
+			if b.counters[processID] == nil {
+				b.counters[processID] = map[string]bool{}
+			}
+			b.counters[processID][s.Name] = true
+		} else {
+			values[s.Name] = s.Value
+		}
+	}
+	return nil
+}
+
+// drain 取出当前聚合的指标并清空, COUNTER除以距上次取出的秒数得到每秒速率, 之后从0重新累加
+func (b *metricsBatcher) drain(now time.Time) []apis.AppProcessMetrics {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	elapsed := now.Sub(b.since).Seconds()
+	if elapsed <= 0 {
+		elapsed = processMetricsFlushInterval.Seconds()
+	}
+	b.since = now
+	if len(b.metrics) == 0 {
+		return nil
+	}
+	res := make([]apis.AppProcessMetrics, 0, len(b.metrics))
+	for id, values := range b.metrics {
+		for name := range b.counters[id] {
+			values[name] /= elapsed
+		}
+		res = append(res, apis.AppProcessMetrics{ProcessID: id, Values: values})
+	}
+	b.metrics = map[string]map[string]float64{}
+	b.counters = map[string]map[string]bool{}
+	return res
+}
+
+// RecordMetrics 记录进程上报的指标, 只接受已注册进程的指标
+func (p *ProcessManager) RecordMetrics(pid int, samples []MetricSample) error {
+	p.ProcessMux.RLock()
+	process := p.GetProcess(pid)
+	p.ProcessMux.RUnlock()
+	if process == nil {
+		return fmt.Errorf("process %d does not exist", pid)
+	}
+
+	process.Mux.RLock()
+	id := process.Id
+	process.Mux.RUnlock()
+	if id == "" {
+		return fmt.Errorf("process %d is not registered", pid)
+	}
+	return p.metrics.add(id, samples)
+}
+
+// flushMetrics 批量上报聚合的指标, 上报失败的指标直接丢弃
+func (p *ProcessManager) flushMetrics() {
+	processMetrics := p.metrics.drain(time.Now())
+	if len(processMetrics) == 0 {
+		return
+	}
+	if err := reportProcessMetrics(&apis.ReportAppProcessMetricsRequest{Metrics: processMetrics}); err != nil {
+		log.RunLogger.Errorf("[process manager] failed to report metrics of %d processes for %v",
+			len(processMetrics), err)
+		return
+	}
+	log.RunLogger.Debugf("[process manager] succeed to report metrics of %d processes", len(processMetrics))
+}
@@ -0,0 +1,51 @@
+package processmanager
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/apis"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/common"
+)
+
+func TestMetricsBatcher(t *testing.T) {
+	Convey("metrics batcher test", t, func() {
+		b := newMetricsBatcher()
+		start := time.Now()
+		b.since = start
+
+		// GAUGE取最新值, COUNTER累加后换算为每秒速率
+		So(b.add("p1", []MetricSample{
+			{Name: "queue_length", Type: common.MetricTypeGauge, Value: 3},
+			{Name: "matches", Type: common.MetricTypeCounter, Value: 2},
+		}), ShouldBeNil)
+		So(b.add("p1", []MetricSample{
+			{Name: "queue_length", Type: common.MetricTypeGauge, Value: 5},
+			{Name: "matches", Type: common.MetricTypeCounter, Value: 4},
+		}), ShouldBeNil)
+		So(b.add("p2", []MetricSample{{Name: "queue_length", Type: common.MetricTypeGauge, Value: 1}}), ShouldBeNil)
+
+		// 非法采样整体拒绝
+		So(b.add("p1", []MetricSample{
+			{Name: "matches", Type: common.MetricTypeCounter, Value: 100},
+			{Name: "bad-name", Type: common.MetricTypeGauge, Value: 1},
+		}), ShouldNotBeNil)
+		So(b.add("p1", []MetricSample{{Name: "matches", Type: "HISTOGRAM", Value: 1}}), ShouldNotBeNil)
+
+		got := map[string]map[string]float64{}
+		for _, m := range b.drain(start.Add(2 * time.Second)) {
+			got[m.ProcessID] = m.Values
+		}
+		So(got["p1"]["queue_length"], ShouldEqual, 5)
+		So(got["p1"]["matches"], ShouldEqual, 3)
+		So(got["p2"]["queue_length"], ShouldEqual, 1)
+
+		// 上报后清空, COUNTER重新累加, 速率按距上次取出的时间计算
+		So(b.drain(start.Add(4*time.Second)), ShouldBeNil)
+		So(b.add("p1", []MetricSample{{Name: "matches", Type: common.MetricTypeCounter, Value: 10}}), ShouldBeNil)
+		So(b.drain(start.Add(14*time.Second)), ShouldResemble,
+			[]apis.AppProcessMetrics{{ProcessID: "p1", Values: map[string]float64{"matches": 1}}})
+	})
+}
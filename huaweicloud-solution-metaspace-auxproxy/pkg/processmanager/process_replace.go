@@ -0,0 +1,98 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 应用包原地更新时替换进程: 排空旧进程并等待其退出, 进程管理按照启动配置从生效的应用包目录重新拉起进程
+package processmanager
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/common"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/utils/log"
+)
+
+const (
+	processExitTimeout         = 60 * time.Second
+	processReplacePollInterval = 5 * time.Second
+)
+
+// killProcess 强制结束进程, 测试时替换
+var killProcess = func(pid int) error {
+	pro, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return pro.Kill()
+}
+
+// ReplaceProcess 排空进程并等待其退出, 排空后仍未退出的进程强制结束
+func (p *ProcessManager) ReplaceProcess(pro *Process) {
+	p.DrainProcess(pro)
+	if p.waitProcessExit(pro.Pid, processExitTimeout) {
+		return
+	}
+
+	log.RunLogger.Errorf("[process manager] process %d did not exit after drain, kill it", pro.Pid)
+	if err := killProcess(pro.Pid); err != nil {
+		log.RunLogger.Errorf("[process manager] failed to kill process %d for %v", pro.Pid, err)
+		return
+	}
+	p.waitProcessExit(pro.Pid, processExitTimeout)
+}
+
+func (p *ProcessManager) waitProcessExit(pid int, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for p.IsProcessExisted(pid) {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(processReplacePollInterval)
+	}
+	return true
+}
+
+// countReadyProcesses 统计从指定应用包目录启动、已注册且健康检查通过的进程数
+func (p *ProcessManager) countReadyProcesses(launchPath, parameters, buildDir string) int {
+	p.ProcessMux.RLock()
+	defer p.ProcessMux.RUnlock()
+
+	ready := 0
+	for _, pro := range p.Processes {
+		if pro.LaunchPath != launchPath || pro.Parameters != parameters || pro.BuildDir != buildDir {
+			continue
+		}
+		pro.Mux.RLock()
+		if pro.isRegistered && pro.Status == common.AppProcessStateActive {
+			ready++
+		}
+		pro.Mux.RUnlock()
+	}
+	return ready
+}
+
+// WaitProcessesReady 等待从指定应用包目录启动的进程就绪数达到want, 启动配置进入crash loop时立即失败
+func (p *ProcessManager) WaitProcessesReady(launchPath, parameters, buildDir string, want int,
+	timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		if p.countReadyProcesses(launchPath, parameters, buildDir) >= want {
+			return nil
+		}
+		if p.restarts.isCrashLooping(launchPath, parameters) {
+			return fmt.Errorf("process \"%s %s\" is crash looping in %s", launchPath, parameters, buildDir)
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("process \"%s %s\" in %s is not ready in %v", launchPath, parameters,
+				buildDir, timeout)
+		}
+		time.Sleep(processReplacePollInterval)
+	}
+}
+
+// ResetRestartHistory 清空启动配置的重启历史, 回滚应用包后旧目录的进程无需等待重启退避
+func (p *ProcessManager) ResetRestartHistory(launchPath, parameters string) {
+	if id := p.restarts.reset(launchPath, parameters); id != "" {
+		terminateCrashLoopProcess(id)
+	}
+}
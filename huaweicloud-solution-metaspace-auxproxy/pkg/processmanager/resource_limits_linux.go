@@ -0,0 +1,178 @@
+//go:build linux
+// +build linux
+
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 应用进程资源限制, 通过cgroup v2限制cpu和内存, 通过rlimit限制打开文件数, 均在应用进程exec之前生效
+package processmanager
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/config"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/apis"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/common"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/utils/log"
+)
+
+const (
+	cgroupCpuPeriod      = 100000
+	cgroupControllers    = "+cpu +memory"
+	cgroupEventOOMKill   = "oom_kill"
+	cgroupFilePermission = 0644
+)
+
+// processCgroupRoot 应用进程cgroup v2根目录, 测试时替换
+var processCgroupRoot = config.ProcessCgroupRoot
+
+// wrapResourceLimits 启动前创建进程的cgroup并写入cpu和内存限制, 返回cgroup目录以及通过包装进程启动应用进程的命令,
+// 包装进程在exec应用进程之前设置rlimit并加入cgroup, 应用进程及其子进程从启动起就受到限制
+func wrapResourceLimits(name string, args []string, limits *apis.ResourceLimits) (string, string, []string, error) {
+	if limits == nil || (limits.CpuQuotaMillicores <= 0 && limits.MemoryMaxMB <= 0 && limits.MaxOpenFiles <= 0) {
+		return "", name, args, nil
+	}
+	self, err := os.Executable()
+	if err != nil {
+		return "", "", nil, fmt.Errorf("failed to locate auxproxy executable for %v", err)
+	}
+
+	dir := ""
+	if limits.CpuQuotaMillicores > 0 || limits.MemoryMaxMB > 0 {
+		if dir, err = createProcessCgroup(limits); err != nil {
+			return "", "", nil, err
+		}
+	}
+	wrapped := append([]string{LimitedExecArg, dir, strconv.Itoa(limits.MaxOpenFiles), name}, args...)
+	return dir, self, wrapped, nil
+}
+
+// RunLimitedExec 包装进程入口, 参数为cgroup目录、打开文件数限制和应用进程命令;
+// exec成功后不会返回, 任一限制无法施加时以limitedExecExitCode退出, 应用进程不会被执行
+func RunLimitedExec(args []string) {
+	err := limitedExec(args)
+	fmt.Fprintf(os.Stderr, "auxproxy: failed to apply resource limits for %v\n", err)
+	os.Exit(limitedExecExitCode)
+}
+
+func limitedExec(args []string) error {
+	if len(args) < 3 {
+		return fmt.Errorf("invalid limited exec args %v", args)
+	}
+	dir := args[0]
+	maxOpenFiles, err := strconv.ParseUint(args[1], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid max open files %s", args[1])
+	}
+	path, err := exec.LookPath(args[2])
+	if err != nil {
+		return err
+	}
+
+	if maxOpenFiles > 0 {
+		rlimit := syscall.Rlimit{Cur: maxOpenFiles, Max: maxOpenFiles}
+		if err = syscall.Setrlimit(syscall.RLIMIT_NOFILE, &rlimit); err != nil {
+			return fmt.Errorf("failed to set max open files for %v", err)
+		}
+	}
+	// 写入0表示将当前进程加入cgroup, exec不改变进程号
+	if dir != "" {
+		if err = writeCgroupFile(dir, "cgroup.procs", "0"); err != nil {
+			return fmt.Errorf("failed to join cgroup %s for %v", dir, err)
+		}
+	}
+	return syscall.Exec(path, args[2:], os.Environ())
+}
+
+// createProcessCgroup 在根目录下创建进程独立的cgroup并写入cpu和内存限制, 失败时删除已创建的cgroup
+func createProcessCgroup(limits *apis.ResourceLimits) (string, error) {
+	root := processCgroupRoot
+	parent := filepath.Dir(root)
+	if _, err := os.Stat(filepath.Join(parent, "cgroup.controllers")); err != nil {
+		return "", fmt.Errorf("cgroup v2 is not available at %s", parent)
+	}
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return "", err
+	}
+	// 子cgroup的控制器需要在上层cgroup中逐级开启
+	if err := writeCgroupFile(parent, "cgroup.subtree_control", cgroupControllers); err != nil {
+		return "", err
+	}
+	if err := writeCgroupFile(root, "cgroup.subtree_control", cgroupControllers); err != nil {
+		return "", err
+	}
+
+	dir, err := ioutil.TempDir(root, "process-")
+	if err != nil {
+		return "", err
+	}
+	if err = writeCgroupLimits(dir, limits); err != nil {
+		removeProcessCgroup(dir)
+		return "", err
+	}
+	return dir, nil
+}
+
+func writeCgroupLimits(dir string, limits *apis.ResourceLimits) error {
+	if limits.CpuQuotaMillicores > 0 {
+		quota := limits.CpuQuotaMillicores * cgroupCpuPeriod / 1000
+		if err := writeCgroupFile(dir, "cpu.max", fmt.Sprintf("%d %d", quota, cgroupCpuPeriod)); err != nil {
+			return err
+		}
+	}
+	if limits.MemoryMaxMB > 0 {
+		if err := writeCgroupFile(dir, "memory.max", strconv.Itoa(limits.MemoryMaxMB<<20)); err != nil {
+			return err
+		}
+		// 禁止使用swap绕过内存限制, 未开启swap时没有该文件
+		_ = writeCgroupFile(dir, "memory.swap.max", "0")
+	}
+	return nil
+}
+
+func writeCgroupFile(dir, name, value string) error {
+	return ioutil.WriteFile(filepath.Join(dir, name), []byte(value), cgroupFilePermission)
+}
+
+// limitKillReason 根据cgroup事件判断进程是否因资源超限被终止
+func limitKillReason(pro *Process) string {
+	if pro.cgroupDir == "" {
+		return ""
+	}
+	f, err := os.Open(filepath.Join(pro.cgroupDir, "memory.events"))
+	if err != nil {
+		log.RunLogger.Errorf("[process manager] failed to read memory events of process %d for %v", pro.Pid, err)
+		return ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == cgroupEventOOMKill && fields[1] != "0" {
+			return common.AppProcessStateReasonOOMKilled
+		}
+	}
+	return ""
+}
+
+// releaseResourceLimits 进程退出后删除进程的cgroup
+func releaseResourceLimits(pro *Process) {
+	removeProcessCgroup(pro.cgroupDir)
+}
+
+func removeProcessCgroup(dir string) {
+	if dir == "" {
+		return
+	}
+	if err := os.Remove(dir); err != nil {
+		log.RunLogger.Errorf("[process manager] failed to remove cgroup %s for %v", dir, err)
+	}
+}
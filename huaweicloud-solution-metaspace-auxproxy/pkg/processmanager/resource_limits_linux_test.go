@@ -0,0 +1,94 @@
+//go:build linux
+// +build linux
+
+package processmanager
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/config"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/apis"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/common"
+)
+
+// TestMain 测试进程作为资源限制包装进程启动时直接执行包装逻辑
+func TestMain(m *testing.M) {
+	if len(os.Args) > 1 && os.Args[1] == LimitedExecArg {
+		RunLimitedExec(os.Args[2:])
+	}
+	os.Exit(m.Run())
+}
+
+func TestLimitKillReason(t *testing.T) {
+	dir := t.TempDir()
+	events := filepath.Join(dir, "memory.events")
+
+	Convey("limit kill reason test", t, func() {
+		So(limitKillReason(&Process{}), ShouldEqual, "")
+
+		pro := &Process{cgroupDir: dir}
+		So(ioutil.WriteFile(events, []byte("low 0\nhigh 0\nmax 3\noom 0\noom_kill 0\n"), 0644), ShouldBeNil)
+		So(limitKillReason(pro), ShouldEqual, "")
+
+		So(ioutil.WriteFile(events, []byte("low 0\nhigh 0\nmax 5\noom 1\noom_kill 1\n"), 0644), ShouldBeNil)
+		So(limitKillReason(pro), ShouldEqual, common.AppProcessStateReasonOOMKilled)
+	})
+}
+
+func TestStartProcessWithResourceLimits(t *testing.T) {
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "started")
+	script := filepath.Join(dir, "app.sh")
+	originLogDir, originCgroupRoot := config.Opts.ProcessLogDir, processCgroupRoot
+	config.Opts.ProcessLogDir = filepath.Join(dir, "logs")
+	defer func() { config.Opts.ProcessLogDir, processCgroupRoot = originLogDir, originCgroupRoot }()
+
+	Convey("start process with resource limits test", t, func() {
+		So(ioutil.WriteFile(script, []byte("#!/bin/sh\ntouch "+marker+"\nulimit -n\n"), 0755), ShouldBeNil)
+		defer os.Remove(marker)
+
+		Convey("max open files is applied before the process runs", func() {
+			cmd, pro, err := startProcess(script, "", &apis.ResourceLimits{MaxOpenFiles: 64})
+			So(err, ShouldBeNil)
+			So(cmd.Wait(), ShouldBeNil)
+			out, err := ioutil.ReadFile(processLogFile(pro.outputLogDir, ProcessOutputStdout))
+			So(err, ShouldBeNil)
+			So(strings.TrimSpace(string(out)), ShouldEqual, "64")
+		})
+
+		Convey("process is not started when the cgroup can not be created", func() {
+			// 临时目录的上层目录不是cgroup v2挂载点
+			processCgroupRoot = filepath.Join(dir, "cgroup", "auxproxy")
+			cmd, pro, err := startProcess(script, "", &apis.ResourceLimits{CpuQuotaMillicores: 500})
+			So(err, ShouldNotBeNil)
+			So(cmd, ShouldBeNil)
+			So(pro, ShouldBeNil)
+			_, err = os.Stat(marker)
+			So(os.IsNotExist(err), ShouldBeTrue)
+		})
+
+		Convey("wrapper exits without running the process when it can not join the cgroup", func() {
+			self, err := os.Executable()
+			So(err, ShouldBeNil)
+			cmd := exec.Command(self, LimitedExecArg, filepath.Join(dir, "missing-cgroup"), "0", script)
+			err = cmd.Run()
+			So(err, ShouldNotBeNil)
+			So(cmd.ProcessState.ExitCode(), ShouldEqual, limitedExecExitCode)
+			_, err = os.Stat(marker)
+			So(os.IsNotExist(err), ShouldBeTrue)
+		})
+
+		Convey("wrapper rejects invalid arguments", func() {
+			So(limitedExec([]string{"", "0"}), ShouldNotBeNil)
+			So(limitedExec([]string{"", "many", script}), ShouldNotBeNil)
+			So(limitedExec([]string{"", "0", filepath.Join(dir, "missing.sh")}), ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,37 @@
+//go:build windows
+// +build windows
+
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// windows暂不支持应用进程资源限制
+package processmanager
+
+import (
+	"fmt"
+	"os"
+
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/apis"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/utils/log"
+)
+
+func wrapResourceLimits(name string, args []string, limits *apis.ResourceLimits) (string, string, []string, error) {
+	if limits != nil {
+		log.RunLogger.Infof("[process manager] resource limits are not supported on windows, "+
+			"ignore limits of %s", name)
+	}
+	return "", name, args, nil
+}
+
+// RunLimitedExec windows不会以包装进程方式启动应用进程
+func RunLimitedExec(args []string) {
+	fmt.Fprintf(os.Stderr, "auxproxy: resource limits are not supported on windows\n")
+	os.Exit(limitedExecExitCode)
+}
+
+func limitKillReason(pro *Process) string {
+	return ""
+}
+
+func releaseResourceLimits(pro *Process) {}
+
+func removeProcessCgroup(dir string) {}
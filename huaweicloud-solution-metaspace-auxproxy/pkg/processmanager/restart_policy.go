@@ -0,0 +1,168 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 应用进程重启退避与crash loop检测
+package processmanager
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	restartBackoffBase = 10 * time.Second
+	restartBackoffMax  = 5 * time.Minute
+	// 时间窗口内失败次数达到阈值即认为进程处于crash loop
+	crashLoopThreshold = 5
+	crashLoopWindow    = 10 * time.Minute
+	// 运行超过该时长且正常退出的进程视为稳定运行, 清空重启历史
+	stableRunDuration = 60 * time.Second
+)
+
+// restartHistory 同一启动配置(启动路径+参数)的重启历史
+type restartHistory struct {
+	failures            []time.Time
+	consecutiveFailures int
+	nextLaunchAt        time.Time
+	crashLooping        bool
+	// 上报给appgateway处于CRASH_LOOP状态的进程id
+	crashLoopProcessID string
+}
+
+// exitResult 进程退出后的重启判定结果
+type exitResult struct {
+	Failed           bool
+	CrashLooping     bool
+	EnteredCrashLoop bool
+	FailureCount     int
+	Backoff          time.Duration
+	// 从crash loop恢复时需要置为TERMINATED的进程id
+	RecoveredProcessID string
+}
+
+type restartTracker struct {
+	mux       sync.Mutex
+	histories map[string]*restartHistory
+}
+
+func newRestartTracker() *restartTracker {
+	return &restartTracker{histories: make(map[string]*restartHistory)}
+}
+
+func restartKey(launchPath, parameters string) string {
+	return launchPath + " " + parameters
+}
+
+// canLaunch 判断启动配置是否已过退避时间
+func (t *restartTracker) canLaunch(launchPath, parameters string, now time.Time) bool {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	h, ok := t.histories[restartKey(launchPath, parameters)]
+	if !ok {
+		return true
+	}
+	return !now.Before(h.nextLaunchAt)
+}
+
+// recordExit 记录进程退出; 启动后很快退出或退出码非0均记为一次失败
+func (t *restartTracker) recordExit(launchPath, parameters string, exitCode int, uptime time.Duration,
+	now time.Time) exitResult {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	key := restartKey(launchPath, parameters)
+	h, ok := t.histories[key]
+	if !ok {
+		h = &restartHistory{}
+		t.histories[key] = h
+	}
+
+	if exitCode == 0 && uptime >= stableRunDuration {
+		delete(t.histories, key)
+		return exitResult{RecoveredProcessID: h.crashLoopProcessID}
+	}
+
+	// 丢弃时间窗口之外的失败记录
+	var failures []time.Time
+	for _, f := range h.failures {
+		if now.Sub(f) <= crashLoopWindow {
+			failures = append(failures, f)
+		}
+	}
+	h.failures = append(failures, now)
+	h.consecutiveFailures++
+
+	backoff := restartBackoffBase
+	for i := 1; i < h.consecutiveFailures && backoff < restartBackoffMax; i++ {
+		backoff *= 2
+	}
+	if backoff > restartBackoffMax {
+		backoff = restartBackoffMax
+	}
+	h.nextLaunchAt = now.Add(backoff)
+
+	res := exitResult{
+		Failed:       true,
+		FailureCount: len(h.failures),
+		Backoff:      backoff,
+	}
+	if !h.crashLooping && len(h.failures) >= crashLoopThreshold {
+		h.crashLooping = true
+		res.EnteredCrashLoop = true
+	}
+	res.CrashLooping = h.crashLooping
+	return res
+}
+
+// crashLoopProcessID 获取appgateway中处于CRASH_LOOP状态的进程id
+func (t *restartTracker) crashLoopProcessID(launchPath, parameters string) string {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	h, ok := t.histories[restartKey(launchPath, parameters)]
+	if !ok {
+		return ""
+	}
+	return h.crashLoopProcessID
+}
+
+// setCrashLoopProcessID 记录appgateway中处于CRASH_LOOP状态的进程id
+func (t *restartTracker) setCrashLoopProcessID(launchPath, parameters, id string) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	if h, ok := t.histories[restartKey(launchPath, parameters)]; ok {
+		h.crashLoopProcessID = id
+	}
+}
+
+// resetIfStable 进程已稳定运行时清空重启历史, 返回需要恢复的CRASH_LOOP进程id
+func (t *restartTracker) resetIfStable(launchPath, parameters string, uptime time.Duration) string {
+	if uptime < stableRunDuration {
+		return ""
+	}
+	return t.reset(launchPath, parameters)
+}
+
+// reset 清空启动配置的重启历史, 返回需要恢复的CRASH_LOOP进程id
+func (t *restartTracker) reset(launchPath, parameters string) string {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	key := restartKey(launchPath, parameters)
+	h, ok := t.histories[key]
+	if !ok {
+		return ""
+	}
+	delete(t.histories, key)
+	return h.crashLoopProcessID
+}
+
+// isCrashLooping 判断启动配置是否处于crash loop
+func (t *restartTracker) isCrashLooping(launchPath, parameters string) bool {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	h, ok := t.histories[restartKey(launchPath, parameters)]
+	return ok && h.crashLooping
+}
@@ -0,0 +1,79 @@
+package processmanager
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRestartTrackerBackoff(t *testing.T) {
+	tracker := newRestartTracker()
+	launchPath, parameters := "/local/app/fake-server.sh", "-port 8080"
+	now := time.Now()
+
+	Convey("backoff test", t, func() {
+		So(tracker.canLaunch(launchPath, parameters, now), ShouldBeTrue)
+
+		// 启动后立即退出, 退避时间逐次翻倍
+		res := tracker.recordExit(launchPath, parameters, 1, time.Second, now)
+		So(res.Failed, ShouldBeTrue)
+		So(res.Backoff, ShouldEqual, restartBackoffBase)
+		So(tracker.canLaunch(launchPath, parameters, now.Add(restartBackoffBase/2)), ShouldBeFalse)
+		So(tracker.canLaunch(launchPath, parameters, now.Add(restartBackoffBase)), ShouldBeTrue)
+
+		res = tracker.recordExit(launchPath, parameters, 1, time.Second, now)
+		So(res.Backoff, ShouldEqual, 2*restartBackoffBase)
+
+		// 其他启动配置不受影响
+		So(tracker.canLaunch(launchPath, "-port 8081", now), ShouldBeTrue)
+
+		// 稳定运行后正常退出, 清空历史
+		res = tracker.recordExit(launchPath, parameters, 0, stableRunDuration, now)
+		So(res.Failed, ShouldBeFalse)
+		So(tracker.canLaunch(launchPath, parameters, now), ShouldBeTrue)
+	})
+}
+
+func TestRestartTrackerCrashLoop(t *testing.T) {
+	tracker := newRestartTracker()
+	launchPath, parameters := "/local/app/fake-server.sh", ""
+	now := time.Now()
+
+	Convey("crash loop test", t, func() {
+		var res exitResult
+		for i := 0; i < crashLoopThreshold-1; i++ {
+			res = tracker.recordExit(launchPath, parameters, 0, time.Second, now.Add(time.Duration(i)*time.Second))
+			So(res.CrashLooping, ShouldBeFalse)
+		}
+
+		// 达到阈值, 进入crash loop且只上报一次
+		res = tracker.recordExit(launchPath, parameters, 139, time.Second, now.Add(time.Minute))
+		So(res.EnteredCrashLoop, ShouldBeTrue)
+		So(res.CrashLooping, ShouldBeTrue)
+		So(res.FailureCount, ShouldEqual, crashLoopThreshold)
+
+		res = tracker.recordExit(launchPath, parameters, 139, time.Second, now.Add(2*time.Minute))
+		So(res.EnteredCrashLoop, ShouldBeFalse)
+		So(res.CrashLooping, ShouldBeTrue)
+		So(res.Backoff, ShouldBeLessThanOrEqualTo, restartBackoffMax)
+
+		// 稳定运行后恢复, 返回需要终止的CRASH_LOOP进程
+		tracker.setCrashLoopProcessID(launchPath, parameters, "app-process-1")
+		So(tracker.crashLoopProcessID(launchPath, parameters), ShouldEqual, "app-process-1")
+		So(tracker.resetIfStable(launchPath, parameters, time.Second), ShouldEqual, "")
+		So(tracker.resetIfStable(launchPath, parameters, stableRunDuration), ShouldEqual, "app-process-1")
+		So(tracker.crashLoopProcessID(launchPath, parameters), ShouldEqual, "")
+	})
+
+	Convey("failures out of window test", t, func() {
+		tracker := newRestartTracker()
+		var res exitResult
+		for i := 0; i < crashLoopThreshold; i++ {
+			res = tracker.recordExit(launchPath, parameters, 1, time.Second,
+				now.Add(time.Duration(i)*crashLoopWindow))
+		}
+		So(res.FailureCount, ShouldEqual, 2)
+		So(res.CrashLooping, ShouldBeFalse)
+	})
+}
@@ -16,8 +16,11 @@ import (
 )
 
 const (
-	ProcessV1Path      = "/v1/app-processes"
-	ProcessStateV1Path = "state"
+	ProcessV1Path        = "/v1/app-processes"
+	ProcessStateV1Path   = "state"
+	ProcessMetricsV1Path = "/v1/app-process-metrics"
+	InstanceV1Path       = "/v1/instances"
+	InstanceMetricsPath  = "metrics"
 )
 
 type GatewayClient struct {
@@ -139,6 +142,68 @@ func (g *GatewayClient) UpdateProcessState(id string, r *apis.UpdateAppProcessSt
 	return nil, nil
 }
 
+// ReportProcessMetrics report custom metrics of processes
+func (g *GatewayClient) ReportProcessMetrics(r *apis.ReportAppProcessMetricsRequest) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		log.RunLogger.Errorf("[gateway client] failed to marshal report app process metrics request for %v", err)
+		return err
+	}
+
+	req, err := NewRequest("POST",
+		fmt.Sprintf("https://%s%s", g.GatewayAddr, ProcessMetricsV1Path),
+		map[string][]string{},
+		bytes.NewReader(data))
+	if err != nil {
+		log.RunLogger.Errorf("[gateway client] failed to create report app process metrics request for %v", err)
+		return err
+	}
+
+	code, _, _, err := DoRequest(g.Cli, req)
+	if err != nil {
+		log.RunLogger.Errorf("[gateway client] failed to do report app process metrics request, error %v", err)
+		return err
+	}
+	if code != http.StatusNoContent {
+		log.RunLogger.Errorf("[gateway client] failed to do report app process metrics request, "+
+			"status code is %d", code)
+		return fmt.Errorf("expected status code %d, get status code %d", http.StatusNoContent, code)
+	}
+
+	return nil
+}
+
+// ReportInstanceMetrics report cpu and memory utilization of the instance
+func (g *GatewayClient) ReportInstanceMetrics(instanceID string, r *apis.ReportInstanceMetricsRequest) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		log.RunLogger.Errorf("[gateway client] failed to marshal report instance metrics request for %v", err)
+		return err
+	}
+
+	req, err := NewRequest("POST",
+		fmt.Sprintf("https://%s%s/%s/%s", g.GatewayAddr, InstanceV1Path, instanceID, InstanceMetricsPath),
+		map[string][]string{},
+		bytes.NewReader(data))
+	if err != nil {
+		log.RunLogger.Errorf("[gateway client] failed to create report instance metrics request for %v", err)
+		return err
+	}
+
+	code, _, _, err := DoRequest(g.Cli, req)
+	if err != nil {
+		log.RunLogger.Errorf("[gateway client] failed to do report instance metrics request, error %v", err)
+		return err
+	}
+	if code != http.StatusNoContent {
+		log.RunLogger.Errorf("[gateway client] failed to do report instance metrics request, "+
+			"status code is %d", code)
+		return fmt.Errorf("expected status code %d, get status code %d", http.StatusNoContent, code)
+	}
+
+	return nil
+}
+
 func (g *GatewayClient) FetchConfiguration(sgID string) (*apis.InstanceConfiguration, error) {
 	req, err := NewRequest("GET",
 		fmt.Sprintf("https://%s/v1/instance-scaling-group/%s/instance-configuration", g.GatewayAddr, sgID),
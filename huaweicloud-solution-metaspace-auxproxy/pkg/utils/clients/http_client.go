@@ -7,6 +7,7 @@ import (
 	"crypto/tls"
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
@@ -69,6 +70,18 @@ func NewHttpsClient(localKey string) *Client {
 	return client
 }
 
+// NewSignedHttpsClient 访问强制hmac校验的接口时使用, 秘钥未配置时返回错误, 不发送会被拒绝的未签名请求
+func NewSignedHttpsClient(localKey string) (*Client, error) {
+	if hhmac.HmacLocalSker == nil || !hhmac.HmacLocalSker.EnableAuth(localKey) {
+		return nil, fmt.Errorf("hmac key of %s is not enabled in client hmac conf", localKey)
+	}
+	client := NewHttpsClient(localKey)
+	if client.ak == "" || len(client.sk) == 0 {
+		return nil, fmt.Errorf("hmac ak or sk of %s is empty", localKey)
+	}
+	return client, nil
+}
+
 // NewRequest new request
 func NewRequest(method string, path string, headers map[string][]string, body io.Reader) (*http.Request, error) {
 	req, err := http.NewRequest(method, path, body)
@@ -21,6 +21,22 @@ func NewProcessEndingError(message string) *auxproxyservice.Error {
 	}
 }
 
+// NewReportMetricsError 上报自定义指标的错误
+func NewReportMetricsError(message string) *auxproxyservice.Error {
+	return &auxproxyservice.Error{
+		ErrorCode: "SCASE.00020102",
+		ErrorMsg:  message,
+	}
+}
+
+// NewReportDrainProgressError 上报排空进度的错误
+func NewReportDrainProgressError(message string) *auxproxyservice.Error {
+	return &auxproxyservice.Error{
+		ErrorCode: "SCASE.00020103",
+		ErrorMsg:  message,
+	}
+}
+
 // NewActivateServerSessionError 上报server session激活成功的错误
 func NewActivateServerSessionError(message string) *auxproxyservice.Error {
 	return &auxproxyservice.Error{
@@ -50,3 +50,13 @@ func NewStartServerSessionError(message string, httpCode int) *ErrorResp {
 func NewAuthenticationError() *ErrorResp {
 	return NewError("SCASE.00020009", "authentication error", http.StatusForbidden)
 }
+
+// NewShowProcessOutputError 创建查询进程输出失败的错误
+func NewShowProcessOutputError(message string, httpCode int) *ErrorResp {
+	return NewError("SCASE.00020400", message, httpCode)
+}
+
+// NewBuildUpdateError 创建原地更新应用包失败的错误
+func NewBuildUpdateError(message string, httpCode int) *ErrorResp {
+	return NewError("SCASE.00020500", message, httpCode)
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/pkg/errors"
 
 	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/config"
+	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/utils/log"
 	"codehub-g.huawei.com/videocloud/mediaprocesscenter/auxproxy/pkg/utils/security"
 )
 
@@ -19,6 +20,8 @@ var (
 
 const (
 	LocalKeyAGW = "agw"
+	// LocalKeyFleetManager 访问fleetmanager内部接口（事件、构建状态上报）时使用的秘钥
+	LocalKeyFleetManager = "fleetmanager"
 )
 
 // InitHMACKey 初始化 hmac 秘钥存储器
@@ -61,18 +64,25 @@ func newHmacLocalKey() (LocalSker, error) {
 	localKeyEntrys := config.ClientHmacConf.Keys
 
 	// eg: 通过如下方式，加入访问远端服务的 hmac秘钥 信息
-	agwKey := localKeyEntrys[LocalKeyAGW]
-	if agwKey.Enable {
-		sk, err := security.GCM_Decrypt(string(agwKey.SKCypher), config.Opts.GCMKey, config.Opts.GCMNonce)
+	for _, receiver := range []string{LocalKeyAGW, LocalKeyFleetManager} {
+		key := localKeyEntrys[receiver]
+		if !key.Enable {
+			continue
+		}
+		sk, err := security.GCM_Decrypt(string(key.SKCypher), config.Opts.GCMKey, config.Opts.GCMNonce)
 		if err != nil {
 			return nil, errors.New("decrypt err")
 		}
-		localSker.Add(LocalKeyAGW, LocalKeyEntry{
-			Enable: agwKey.Enable,
-			AK:     agwKey.AK,
+		localSker.Add(receiver, LocalKeyEntry{
+			Enable: key.Enable,
+			AK:     key.AK,
 			SK:     []byte(sk),
 		})
 	}
+	if !localKeyEntrys[LocalKeyFleetManager].Enable {
+		log.RunLogger.Errorf("[hmac] hmac key of %s is not enabled, events and build states "+
+			"can not be reported to fleetmanager", LocalKeyFleetManager)
+	}
 
 	return localSker, nil
 }
@@ -34,6 +34,10 @@ type ScaseGrpcSdkServiceClient interface {
 	TerminateServerSession(ctx context.Context, in *TerminateServerSessionRequest, opts ...grpc.CallOption) (*AuxProxyResponse, error)
 	// 上报进程结束
 	ProcessEnding(ctx context.Context, in *ProcessEndingRequest, opts ...grpc.CallOption) (*AuxProxyResponse, error)
+	// 上报自定义指标
+	ReportMetrics(ctx context.Context, in *ReportMetricsRequest, opts ...grpc.CallOption) (*AuxProxyResponse, error)
+	// 上报排空进度
+	ReportDrainProgress(ctx context.Context, in *ReportDrainProgressRequest, opts ...grpc.CallOption) (*AuxProxyResponse, error)
 }
 
 type scaseGrpcSdkServiceClient struct {
@@ -116,6 +120,24 @@ func (c *scaseGrpcSdkServiceClient) ProcessEnding(ctx context.Context, in *Proce
 	return out, nil
 }
 
+func (c *scaseGrpcSdkServiceClient) ReportMetrics(ctx context.Context, in *ReportMetricsRequest, opts ...grpc.CallOption) (*AuxProxyResponse, error) {
+	out := new(AuxProxyResponse)
+	err := c.cc.Invoke(ctx, "/auxproxyService.ScaseGrpcSdkService/ReportMetrics", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *scaseGrpcSdkServiceClient) ReportDrainProgress(ctx context.Context, in *ReportDrainProgressRequest, opts ...grpc.CallOption) (*AuxProxyResponse, error) {
+	out := new(AuxProxyResponse)
+	err := c.cc.Invoke(ctx, "/auxproxyService.ScaseGrpcSdkService/ReportDrainProgress", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ScaseGrpcSdkServiceServer is the server API for ScaseGrpcSdkService service.
 // All implementations must embed UnimplementedScaseGrpcSdkServiceServer
 // for forward compatibility
@@ -136,6 +158,10 @@ type ScaseGrpcSdkServiceServer interface {
 	TerminateServerSession(context.Context, *TerminateServerSessionRequest) (*AuxProxyResponse, error)
 	// 上报进程结束
 	ProcessEnding(context.Context, *ProcessEndingRequest) (*AuxProxyResponse, error)
+	// 上报自定义指标
+	ReportMetrics(context.Context, *ReportMetricsRequest) (*AuxProxyResponse, error)
+	// 上报排空进度
+	ReportDrainProgress(context.Context, *ReportDrainProgressRequest) (*AuxProxyResponse, error)
 	mustEmbedUnimplementedScaseGrpcSdkServiceServer()
 }
 
@@ -167,6 +193,12 @@ func (UnimplementedScaseGrpcSdkServiceServer) TerminateServerSession(context.Con
 func (UnimplementedScaseGrpcSdkServiceServer) ProcessEnding(context.Context, *ProcessEndingRequest) (*AuxProxyResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ProcessEnding not implemented")
 }
+func (UnimplementedScaseGrpcSdkServiceServer) ReportMetrics(context.Context, *ReportMetricsRequest) (*AuxProxyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReportMetrics not implemented")
+}
+func (UnimplementedScaseGrpcSdkServiceServer) ReportDrainProgress(context.Context, *ReportDrainProgressRequest) (*AuxProxyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReportDrainProgress not implemented")
+}
 func (UnimplementedScaseGrpcSdkServiceServer) mustEmbedUnimplementedScaseGrpcSdkServiceServer() {}
 
 // UnsafeScaseGrpcSdkServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -324,6 +356,42 @@ func _ScaseGrpcSdkService_ProcessEnding_Handler(srv interface{}, ctx context.Con
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ScaseGrpcSdkService_ReportMetrics_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReportMetricsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ScaseGrpcSdkServiceServer).ReportMetrics(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/auxproxyService.ScaseGrpcSdkService/ReportMetrics",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ScaseGrpcSdkServiceServer).ReportMetrics(ctx, req.(*ReportMetricsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ScaseGrpcSdkService_ReportDrainProgress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReportDrainProgressRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ScaseGrpcSdkServiceServer).ReportDrainProgress(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/auxproxyService.ScaseGrpcSdkService/ReportDrainProgress",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ScaseGrpcSdkServiceServer).ReportDrainProgress(ctx, req.(*ReportDrainProgressRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ScaseGrpcSdkService_ServiceDesc is the grpc.ServiceDesc for ScaseGrpcSdkService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -363,6 +431,14 @@ var ScaseGrpcSdkService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ProcessEnding",
 			Handler:    _ScaseGrpcSdkService_ProcessEnding_Handler,
 		},
+		{
+			MethodName: "ReportMetrics",
+			Handler:    _ScaseGrpcSdkService_ReportMetrics_Handler,
+		},
+		{
+			MethodName: "ReportDrainProgress",
+			Handler:    _ScaseGrpcSdkService_ReportDrainProgress_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "auxproxy_grpc_service.proto",
@@ -20,6 +20,8 @@ const _ = grpc.SupportPackageIsVersion7
 type ProcessGrpcSdkServiceClient interface {
 	// 接收健康检查请求
 	OnHealthCheck(ctx context.Context, in *HealthCheckRequest, opts ...grpc.CallOption) (*HealthCheckResponse, error)
+	// 健康检查双向流，进程通过流主动推送心跳和健康详情
+	OnHealthStream(ctx context.Context, opts ...grpc.CallOption) (ProcessGrpcSdkService_OnHealthStreamClient, error)
 	// 接收游戏会话
 	OnStartServerSession(ctx context.Context, in *StartServerSessionRequest, opts ...grpc.CallOption) (*ProcessResponse, error)
 	// 结束游戏进程
@@ -43,6 +45,37 @@ func (c *processGrpcSdkServiceClient) OnHealthCheck(ctx context.Context, in *Hea
 	return out, nil
 }
 
+func (c *processGrpcSdkServiceClient) OnHealthStream(ctx context.Context, opts ...grpc.CallOption) (ProcessGrpcSdkService_OnHealthStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ProcessGrpcSdkService_ServiceDesc.Streams[0], "/processService.ProcessGrpcSdkService/OnHealthStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &processGrpcSdkServiceOnHealthStreamClient{stream}
+	return x, nil
+}
+
+type ProcessGrpcSdkService_OnHealthStreamClient interface {
+	Send(*HealthStreamRequest) error
+	Recv() (*HealthHeartbeat, error)
+	grpc.ClientStream
+}
+
+type processGrpcSdkServiceOnHealthStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *processGrpcSdkServiceOnHealthStreamClient) Send(m *HealthStreamRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *processGrpcSdkServiceOnHealthStreamClient) Recv() (*HealthHeartbeat, error) {
+	m := new(HealthHeartbeat)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *processGrpcSdkServiceClient) OnStartServerSession(ctx context.Context, in *StartServerSessionRequest, opts ...grpc.CallOption) (*ProcessResponse, error) {
 	out := new(ProcessResponse)
 	err := c.cc.Invoke(ctx, "/processService.ProcessGrpcSdkService/OnStartServerSession", in, out, opts...)
@@ -67,6 +100,8 @@ func (c *processGrpcSdkServiceClient) OnProcessTerminate(ctx context.Context, in
 type ProcessGrpcSdkServiceServer interface {
 	// 接收健康检查请求
 	OnHealthCheck(context.Context, *HealthCheckRequest) (*HealthCheckResponse, error)
+	// 健康检查双向流，进程通过流主动推送心跳和健康详情
+	OnHealthStream(ProcessGrpcSdkService_OnHealthStreamServer) error
 	// 接收游戏会话
 	OnStartServerSession(context.Context, *StartServerSessionRequest) (*ProcessResponse, error)
 	// 结束游戏进程
@@ -81,6 +116,9 @@ type UnimplementedProcessGrpcSdkServiceServer struct {
 func (UnimplementedProcessGrpcSdkServiceServer) OnHealthCheck(context.Context, *HealthCheckRequest) (*HealthCheckResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method OnHealthCheck not implemented")
 }
+func (UnimplementedProcessGrpcSdkServiceServer) OnHealthStream(ProcessGrpcSdkService_OnHealthStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method OnHealthStream not implemented")
+}
 func (UnimplementedProcessGrpcSdkServiceServer) OnStartServerSession(context.Context, *StartServerSessionRequest) (*ProcessResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method OnStartServerSession not implemented")
 }
@@ -118,6 +156,32 @@ func _ProcessGrpcSdkService_OnHealthCheck_Handler(srv interface{}, ctx context.C
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ProcessGrpcSdkService_OnHealthStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ProcessGrpcSdkServiceServer).OnHealthStream(&processGrpcSdkServiceOnHealthStreamServer{stream})
+}
+
+type ProcessGrpcSdkService_OnHealthStreamServer interface {
+	Send(*HealthHeartbeat) error
+	Recv() (*HealthStreamRequest, error)
+	grpc.ServerStream
+}
+
+type processGrpcSdkServiceOnHealthStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *processGrpcSdkServiceOnHealthStreamServer) Send(m *HealthHeartbeat) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *processGrpcSdkServiceOnHealthStreamServer) Recv() (*HealthStreamRequest, error) {
+	m := new(HealthStreamRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func _ProcessGrpcSdkService_OnStartServerSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(StartServerSessionRequest)
 	if err := dec(in); err != nil {
@@ -174,6 +238,13 @@ var ProcessGrpcSdkService_ServiceDesc = grpc.ServiceDesc{
 			Handler:    _ProcessGrpcSdkService_OnProcessTerminate_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "OnHealthStream",
+			Handler:       _ProcessGrpcSdkService_OnHealthStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
 	Metadata: "process_grpc_service.proto",
 }
@@ -28,3 +28,16 @@ func (c *UpdateController) Update() {
 	}
 	response.Success(c.Ctx, http.StatusNoContent, nil)
 }
+
+// Rollout: 启动Alias蓝绿切换
+func (c *UpdateController) Rollout() {
+	tLogger := log.GetTraceLogger(c.Ctx).WithField(logger.Stage, "rollout_alias")
+	s := service.NewAliasService(c.Ctx, tLogger)
+	rsp, e := s.Rollout()
+	if e != nil {
+		response.ServiceError(c.Ctx, e)
+		tLogger.WithField(logger.Error, e.Error()).Error("rollout alias error")
+		return
+	}
+	response.Success(c.Ctx, http.StatusAccepted, rsp)
+}
@@ -49,3 +49,29 @@ func (c *UpdateController) Update() {
 
 	response.Success(c.Ctx, http.StatusOK, rep)
 }
+
+// ReportState auxproxy上报实例的应用包下载状态, 内部接口
+func (c *UpdateController) ReportState() {
+	tLogger := log.GetTraceLogger(c.Ctx).WithField(logger.Stage, "report_build_state")
+	r := build.ReportStateRequest{}
+	if err := json.Unmarshal(c.Ctx.Input.RequestBody, &r); err != nil {
+		response.InputError(c.Ctx)
+		tLogger.WithField(logger.Error, err.Error()).Error("read request body error")
+		return
+	}
+
+	if err := validator.Validate(&r); err != nil {
+		response.ParamsError(c.Ctx, err)
+		tLogger.WithField(logger.Error, err.Error()).Error("parameters invalid")
+		return
+	}
+
+	s := service.NewBuildService(c.Ctx, tLogger)
+	if e := s.ReportState(&r); e != nil {
+		response.ServiceError(c.Ctx, e)
+		tLogger.WithField(logger.Error, e.Error()).Error("report build state error")
+		return
+	}
+
+	response.Success(c.Ctx, http.StatusOK, nil)
+}
@@ -47,3 +47,30 @@ func (c *CreateController) Create() {
 	}
 	response.Success(c.Ctx, http.StatusCreated, rsp)
 }
+
+// CreateFleetEvent: 接收auxproxy上报的fleet事件
+func (c *CreateController) CreateFleetEvent() {
+	tLogger := log.GetTraceLogger(c.Ctx).WithField(logger.Stage, "create_fleet_event")
+
+	r := &fleet.CreateEventRequest{}
+	if err := json.Unmarshal(c.Ctx.Input.RequestBody, r); err != nil {
+		response.InputError(c.Ctx)
+		tLogger.WithField(logger.Error, err.Error()).Error("read request body error")
+		return
+	}
+
+	if err := validator.Validate(r); err != nil {
+		response.ParamsError(c.Ctx, err)
+		tLogger.WithField(logger.Error, err.Error()).Error("parameters invalid")
+		return
+	}
+
+	s := service.NewEventService(c.Ctx, tLogger)
+	rsp, e := s.CreateFleetEvent(r)
+	if e != nil {
+		response.ServiceError(c.Ctx, e)
+		tLogger.WithField(logger.Error, e.Error()).Error("create fleet event error")
+		return
+	}
+	response.Success(c.Ctx, http.StatusCreated, rsp)
+}
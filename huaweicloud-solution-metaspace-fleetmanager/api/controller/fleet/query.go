@@ -180,6 +180,21 @@ func (c *QueryController) ListFleetEvents() {
 	response.Success(c.Ctx, http.StatusOK, list)
 }
 
+// ListBuildSyncStates: 查询Fleet下实例的应用包下载进度
+func (c *QueryController) ListBuildSyncStates() {
+	tLogger := log.GetTraceLogger(c.Ctx).WithField(logger.Stage, "list_build_sync_states")
+
+	s := service.NewFleetService(c.Ctx, tLogger)
+	list, e := s.ListBuildSyncStates()
+	if e != nil {
+		response.ServiceError(c.Ctx, e)
+		tLogger.WithField(logger.Error, e.Error()).Error("list build sync states error")
+		return
+	}
+
+	response.Success(c.Ctx, http.StatusOK, list)
+}
+
 // ShowInstanceCapacity: 查询Fleet的容量详情
 func (c *QueryController) ShowInstanceCapacity() {
 	tLogger := log.GetTraceLogger(c.Ctx).WithField(logger.Stage, "show_instance_capacity")
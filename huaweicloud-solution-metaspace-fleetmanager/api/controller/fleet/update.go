@@ -111,3 +111,16 @@ func (c *UpdateController) UpdateCapacity() {
 		response.Success(c.Ctx, http.StatusNoContent, nil)
 	}
 }
+
+// UpdateBuild: 原地更新Fleet的应用包
+func (c *UpdateController) UpdateBuild() {
+	tLogger := log.GetTraceLogger(c.Ctx).WithField(logger.Stage, "update_fleet_build")
+	s := service.NewFleetService(c.Ctx, tLogger)
+	rsp, e := s.UpdateBuild()
+	if e != nil {
+		response.ServiceError(c.Ctx, e)
+		tLogger.WithField(logger.Error, e.Error()).Error("update fleet build error")
+		return
+	}
+	response.Success(c.Ctx, http.StatusAccepted, rsp)
+}
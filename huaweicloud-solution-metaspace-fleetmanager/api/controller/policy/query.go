@@ -47,3 +47,35 @@ func (c *QueryController) List() {
 	}
 	response.TransPort(c.Ctx, code, rsp)
 }
+
+// Decisions: 查询fleet的伸缩决定记录
+func (c *QueryController) Decisions() {
+	tLogger := log.GetTraceLogger(c.Ctx).WithField(logger.Stage, "list_scaling_decisions")
+	offset, limit, err := c.queryCheck()
+	if err != nil {
+		response.ParamsError(c.Ctx, err)
+		return
+	}
+
+	s := service.NewPolicyService(c.Ctx, tLogger)
+	code, rsp, e := s.Decisions(offset, limit)
+	if e != nil {
+		response.ServiceError(c.Ctx, e)
+		tLogger.WithField(logger.Error, e.Error()).Error("list scaling decisions error")
+		return
+	}
+	response.TransPort(c.Ctx, code, rsp)
+}
+
+// Forecast: 查询预测策略的需求预测
+func (c *QueryController) Forecast() {
+	tLogger := log.GetTraceLogger(c.Ctx).WithField(logger.Stage, "query_scaling_forecast")
+	s := service.NewPolicyService(c.Ctx, tLogger)
+	code, rsp, e := s.Forecast()
+	if e != nil {
+		response.ServiceError(c.Ctx, e)
+		tLogger.WithField(logger.Error, e.Error()).Error("query scaling forecast error")
+		return
+	}
+	response.TransPort(c.Ctx, code, rsp)
+}
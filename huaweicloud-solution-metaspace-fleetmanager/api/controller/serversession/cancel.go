@@ -0,0 +1,35 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 服务端会话取消模块
+package serversession
+
+import (
+	"fleetmanager/api/common/log"
+	"fleetmanager/api/response"
+	service "fleetmanager/api/service/serversession"
+	"fleetmanager/logger"
+	"github.com/beego/beego/v2/server/web"
+	"net/http"
+)
+
+type CancelController struct {
+	web.Controller
+}
+
+// Cancel: 取消尚未分配到进程的服务器会话
+func (c *CancelController) Cancel() {
+	tLogger := log.GetTraceLogger(c.Ctx).WithField(logger.Stage, "cancel_server_session")
+	s := service.NewServerSessionService(c.Ctx, tLogger)
+	code, rsp, e := s.Cancel()
+	if e != nil {
+		response.ServiceError(c.Ctx, e)
+		tLogger.WithField(logger.Error, e.Error()).Error("server session error")
+		return
+	}
+
+	if code < http.StatusOK || code >= http.StatusBadRequest {
+		response.TransPort(c.Ctx, code, rsp)
+	} else {
+		response.Success(c.Ctx, http.StatusNoContent, nil)
+	}
+}
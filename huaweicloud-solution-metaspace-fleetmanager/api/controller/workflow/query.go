@@ -0,0 +1,63 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// workflow查询模块
+package workflow
+
+import (
+	"fleetmanager/api/common/log"
+	"fleetmanager/api/common/query"
+	"fleetmanager/api/response"
+	service "fleetmanager/api/service/workflow"
+	"fleetmanager/logger"
+	"net/http"
+
+	"github.com/beego/beego/v2/server/web"
+)
+
+type QueryController struct {
+	web.Controller
+}
+
+// queryCheck 校验分页参数
+func (c *QueryController) queryCheck() (int, int, error) {
+	offset, err := query.CheckOffset(c.Ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	limit, err := query.CheckLimit(c.Ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	return offset, limit, nil
+}
+
+// List: 查询Workflow列表
+func (c *QueryController) List() {
+	tLogger := log.GetTraceLogger(c.Ctx).WithField(logger.Stage, "list_workflows")
+	s := service.NewWorkflowService(c.Ctx, tLogger)
+	offset, limit, err := c.queryCheck()
+	if err != nil {
+		response.ParamsError(c.Ctx, err)
+		return
+	}
+	list, e := s.List(offset, limit)
+	if e != nil {
+		response.ServiceError(c.Ctx, e)
+		tLogger.WithField(logger.Error, e.Error()).Error("query workflow list from db error")
+		return
+	}
+	response.Success(c.Ctx, http.StatusOK, list)
+}
+
+// Show: 查询Workflow详情及任务进度
+func (c *QueryController) Show() {
+	tLogger := log.GetTraceLogger(c.Ctx).WithField(logger.Stage, "show_workflow")
+	s := service.NewWorkflowService(c.Ctx, tLogger)
+	rsp, e := s.Show()
+	if e != nil {
+		response.ServiceError(c.Ctx, e)
+		tLogger.WithField(logger.Error, e.Error()).Error("query workflow info from db error")
+		return
+	}
+	response.Success(c.Ctx, http.StatusOK, rsp)
+}
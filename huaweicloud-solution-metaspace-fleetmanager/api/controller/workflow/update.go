@@ -0,0 +1,54 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// workflow运维操作模块
+package workflow
+
+import (
+	"fleetmanager/api/common/log"
+	"fleetmanager/api/response"
+	service "fleetmanager/api/service/workflow"
+	"fleetmanager/logger"
+	"net/http"
+
+	"github.com/beego/beego/v2/server/web"
+)
+
+type UpdateController struct {
+	web.Controller
+}
+
+// Retry: 从失败的任务处恢复Workflow
+func (c *UpdateController) Retry() {
+	tLogger := log.GetTraceLogger(c.Ctx).WithField(logger.Stage, "retry_workflow")
+	s := service.NewWorkflowService(c.Ctx, tLogger)
+	if e := s.Retry(); e != nil {
+		response.ServiceError(c.Ctx, e)
+		tLogger.WithField(logger.Error, e.Error()).Error("retry workflow error")
+		return
+	}
+	response.Success(c.Ctx, http.StatusNoContent, nil)
+}
+
+// Skip: 跳过Workflow中允许忽略失败的任务
+func (c *UpdateController) Skip() {
+	tLogger := log.GetTraceLogger(c.Ctx).WithField(logger.Stage, "skip_workflow_task")
+	s := service.NewWorkflowService(c.Ctx, tLogger)
+	if e := s.Skip(); e != nil {
+		response.ServiceError(c.Ctx, e)
+		tLogger.WithField(logger.Error, e.Error()).Error("skip workflow task error")
+		return
+	}
+	response.Success(c.Ctx, http.StatusNoContent, nil)
+}
+
+// Cancel: 取消运行中的Workflow并回滚
+func (c *UpdateController) Cancel() {
+	tLogger := log.GetTraceLogger(c.Ctx).WithField(logger.Stage, "cancel_workflow")
+	s := service.NewWorkflowService(c.Ctx, tLogger)
+	if e := s.Cancel(); e != nil {
+		response.ServiceError(c.Ctx, e)
+		tLogger.WithField(logger.Error, e.Error()).Error("cancel workflow error")
+		return
+	}
+	response.Success(c.Ctx, http.StatusNoContent, nil)
+}
@@ -34,6 +34,10 @@ const (
 	ServerSessionNotFound              ErrCode = "SCASE.00001023"
 	MissingServerSessionId             ErrCode = "SCASE.00001024"
 	FleetExccedQuota                   ErrCode = "SCASE.00001025"
+	WorkflowNotFound                   ErrCode = "SCASE.00001026"
+	WorkflowStateNotSupportAction      ErrCode = "SCASE.00001027"
+	WorkflowTaskNotFound               ErrCode = "SCASE.00001028"
+	WorkflowTaskNotSupportSkip         ErrCode = "SCASE.00001029"
 	BuildIsInUseNotSupportDelete       ErrCode = "SCASE.00002001"
 	BuildNumExceedMaxSize              ErrCode = "SCASE.00002002"
 	BuildIsAlreadyExist                ErrCode = "SCASE.00002003"
@@ -53,6 +57,9 @@ const (
 	FleetNotActive                     ErrCode = "SCASE.00002017"
 	AliasIsDeactive                    ErrCode = "SCASE.00002018"
 	FleetUsedByAlias                   ErrCode = "SCASE.00002019"
+	AliasRolloutInProgress             ErrCode = "SCASE.00002020"
+	AliasFleetNotAssociated            ErrCode = "SCASE.00002021"
+	FleetWorkflowInProgress            ErrCode = "SCASE.00002022"
 	InvalidUserinfo                    ErrCode = "SCASE.00003001"
 	UserExist                          ErrCode = "SCASE.00003002"
 	UserCreateError                    ErrCode = "SCASE.00003003"
@@ -96,6 +103,10 @@ var errMsg = map[ErrCode]string{
 	ServerSessionNotFound:              "Server session id can not be found",
 	MissingServerSessionId:             "Invalid parameter value, ServerSessionId is needed",
 	FleetExccedQuota:                   "Fleets exceed quota limit",
+	WorkflowNotFound:                   "Workflow id can not be found",
+	WorkflowStateNotSupportAction:      "Workflow do not support the action in current state",
+	WorkflowTaskNotFound:               "Workflow task can not be found",
+	WorkflowTaskNotSupportSkip:         "Workflow task can not be skipped, ignore is not allowed on failure",
 	FleetStateNotSupportCreateAlias:    "Fleet do not support to create alias when state is not active",
 	AliasNotFound:                      "Alias id can not be found in db",
 	RoutingStrategyNotFound:            "RoutingStrategy not be found in db",
@@ -108,6 +119,9 @@ var errMsg = map[ErrCode]string{
 	FleetNotActive:                     "Fleet not active, not be allowed to use",
 	FleetUsedByAlias:                   "Fleet is used by alias, please check",
 	AliasIsDeactive:                    "This alias is deactive",
+	AliasRolloutInProgress:             "The alias already has a rollout in progress",
+	AliasFleetNotAssociated:            "The fleet is not associated with the alias",
+	FleetWorkflowInProgress:            "The fleet already has a workflow in progress",
 	InvalidUserinfo:                    "Invalid userinfo",
 	UserExist:                          "User is existed",
 	UserCreateError:                    "User create error",
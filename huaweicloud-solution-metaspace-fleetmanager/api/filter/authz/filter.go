@@ -6,6 +6,7 @@ package authz
 import (
 	"fleetmanager/api/common/log"
 	"fleetmanager/api/errors"
+	"fleetmanager/api/filter/entrance"
 	"fleetmanager/api/params"
 	"fleetmanager/api/response"
 	"fleetmanager/logger"
@@ -15,6 +16,10 @@ import (
 
 // Filter: authz过滤器
 func Filter(ctx *context.Context) {
+	// 内部接口已在入口过滤器完成hmac签名校验
+	if entrance.IsInternalRoute(ctx) {
+		return
+	}
 	tLogger := log.GetTraceLogger(ctx).WithField(logger.Stage, "token_validate")
 	projectId := ctx.Input.Param(params.ProjectId)
 	token := ctx.Input.Header(params.HeaderParameterToken)
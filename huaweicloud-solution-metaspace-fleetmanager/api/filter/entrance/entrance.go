@@ -12,10 +12,12 @@ import (
 	"fleetmanager/db/dao"
 	"fleetmanager/db/dbm"
 	"fleetmanager/logger"
+	"fleetmanager/security"
 	"fleetmanager/setting"
 	"fleetmanager/utils"
 	"fmt"
 	"net/http"
+	"regexp"
 	"time"
 
 	"github.com/beego/beego/v2/server/web/context"
@@ -27,8 +29,34 @@ var skipMap map[string]bool = map[string]bool{
 	"/v1/user/login": true,
 }
 
+// auxproxy调用的内部接口, 不使用会话认证, 改为校验auxproxy的hmac签名
+var internalRoutes = []*regexp.Regexp{
+	regexp.MustCompile(`^/v1/fleets/[^/]+/events$`),
+	regexp.MustCompile(`^/v1/build/[^/]+/state$`),
+}
+
 const lifttimeMinutes = 30
 
+// IsInternalRoute 判断是否是内部接口
+func IsInternalRoute(ctx *context.Context) bool {
+	for _, r := range internalRoutes {
+		if r.MatchString(ctx.Input.URL()) {
+			return true
+		}
+	}
+	return false
+}
+
+// 校验内部接口请求的hmac签名
+func checkInternalSignature(ctx *context.Context) error {
+	if err := security.ValidateHmac(ctx.Request, setting.AuxProxyHmacAK, setting.AuxProxyHmacSK); err != nil {
+		logger.R.Warn("internal request %s %s hmac validate failed: %v", ctx.Request.Method,
+			ctx.Input.URL(), err)
+		return fmt.Errorf("invalid internal request signature")
+	}
+	return nil
+}
+
 func generateRequestId() string {
 	u, _ := uuid.NewUUID()
 	uid := u.String()
@@ -110,7 +138,13 @@ func Filter(ctx *context.Context) {
 	traceLogger := logger.R.WithField(logger.RequestId, requestId)
 	URI := ctx.Input.Context.Request.RequestURI
 	_, match := skipMap[URI]
-	if !match {
+	if IsInternalRoute(ctx) {
+		// 内部接口只接受携带有效hmac签名的请求
+		if err := checkInternalSignature(ctx); err != nil {
+			response.Error(ctx, http.StatusUnauthorized, errors.NewErrorF(errors.Unauthorized, err.Error()))
+			return
+		}
+	} else if !match {
 		// 除了白名单以外的操作需要验证token
 		if err := checkSession(ctx); err != nil {
 			response.Error(ctx, http.StatusUnauthorized, errors.NewErrorF(errors.Unauthorized, err.Error()))
@@ -16,4 +16,5 @@ type Alias struct {
 	AssociatedFleets []AssociatedFleet `json:"associated_fleets"`
 	Type            string            `json:"type" validate:"required,oneof=ACTIVE DEACTIVE TERMINATED"`
 	Message          string            `json:"message,omitempty" validate:"omitempty,min=0,max=1024"`
+	RoutingStrategy  string            `json:"routing_strategy"`
 }
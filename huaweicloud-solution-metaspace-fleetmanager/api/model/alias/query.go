@@ -18,6 +18,7 @@ type ShowAliasResponse struct {
 	AssociatedFleets []AssociatedFleetRsp `json:"associated_fleets"`
 	Type            string                `json:"type"`
 	Message          string               `json:"message,omitempty" validate:"omitempty,min=0,max=1024"`
+	RoutingStrategy  string               `json:"routing_strategy"`
 }
 
 // AssociatedFleetRs: 别名关联fleet响应
@@ -0,0 +1,21 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// alias蓝绿切换结构体定义
+package alias
+
+// RolloutRequest 将alias的流量按照Steps逐步从OldFleetId切换到NewFleetId,
+// Steps为每一步新fleet占两者流量的比例, 必须递增且最后一步为1, 未填写的参数使用默认值
+type RolloutRequest struct {
+	OldFleetId          string    `json:"old_fleet_id" validate:"required,min=1,max=64"`
+	NewFleetId          string    `json:"new_fleet_id" validate:"required,min=1,max=64,nefield=OldFleetId"`
+	Steps               []float32 `json:"steps,omitempty" validate:"omitempty,min=1,max=20,dive,gt=0,lte=1"`
+	StepIntervalSeconds int       `json:"step_interval_seconds,omitempty" validate:"min=0,max=3600"`
+	MaxErrorRate        *float64  `json:"max_error_rate,omitempty" validate:"omitempty,min=0,max=1"`
+	MinReadyProcesses   int       `json:"min_ready_processes" validate:"min=0,max=100"`
+	ScaleDownOldFleet   bool      `json:"scale_down_old_fleet"`
+}
+
+// RolloutResponse 蓝绿切换返回响应结构
+type RolloutResponse struct {
+	WorkflowId string `json:"workflow_id"`
+}
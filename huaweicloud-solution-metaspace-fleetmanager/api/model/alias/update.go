@@ -9,4 +9,5 @@ type UpdateAliasRequest struct {
 	AssociatedFleets []AssociatedFleet 		`json:"associated_fleets,omitempty" validate:"omitempty,min=0,max=10,associatedFleetsNotDelicated,dive"`
 	Type            string                  `json:"type" validate:"required,oneof=ACTIVE DEACTIVE"`
 	Message         string                  `json:"message,omitempty" validate:"omitempty,min=0,max=1024"`
+	RoutingStrategy string                  `json:"routing_strategy,omitempty" validate:"omitempty,oneof=WEIGHTED STICKY_CREATOR CAPACITY_AWARE"`
 }
@@ -14,6 +14,7 @@ type Build struct {
 	CreationTime    string `json:"creation_time"`
 	Version         string `json:"version"`
 	OperatingSystem string `json:"operating_system"`
+	Checksum        string `json:"checksum,omitempty"`
 }
 
 type FullBuild struct {
@@ -32,4 +33,10 @@ type FullBuild struct {
 	OperatingSystem   string `json:"operating_system"`
 	Version           string `json:"version"`
 	Size              int64  `json:"size"`
+	Checksum          string `json:"checksum,omitempty"`
+	SourceType        string `json:"source_type,omitempty"`
+	SourceEndpoint    string `json:"source_endpoint,omitempty"`
+	SourceRegion      string `json:"source_region,omitempty"`
+	SourceUrl         string `json:"source_url,omitempty"`
+	SourcePath        string `json:"source_path,omitempty"`
 }
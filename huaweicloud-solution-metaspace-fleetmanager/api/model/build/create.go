@@ -37,8 +37,23 @@ type QueryRequest struct {
 // StorageLocation
 // @Description:
 type StorageLocation struct {
-	BucketName string `json:"bucket_name" validate:"required,min=1,max=100"`
-	BucketKey  string `json:"bucket_key" validate:"required,min=1,max=100"`
+	// SourceType 应用包来源, 为空时为OBS; S3为S3兼容存储, HTTPS为带校验值的下载地址, LOCAL为实例上的NFS挂载路径
+	SourceType string `json:"source_type,omitempty" validate:"omitempty,oneof=OBS S3 HTTPS LOCAL"`
+	// BucketName BucketKey OBS和S3来源必填
+	BucketName string `json:"bucket_name,omitempty" validate:"omitempty,min=1,max=100"`
+	BucketKey  string `json:"bucket_key,omitempty" validate:"omitempty,min=1,max=100"`
+	// Endpoint Region AccessKey SecretKey S3来源的https访问地址、签名使用的region和凭证, 凭证为空时匿名访问
+	// S3来源必须同时填写Checksum
+	Endpoint  string `json:"endpoint,omitempty" validate:"omitempty,url,startswith=https://,max=255"`
+	Region    string `json:"region,omitempty" validate:"omitempty,max=64"`
+	AccessKey string `json:"access_key,omitempty" validate:"omitempty,max=128"`
+	SecretKey string `json:"secret_key,omitempty" validate:"omitempty,max=128"`
+	// Url HTTPS来源的下载地址, 必须同时填写Checksum
+	Url string `json:"url,omitempty" validate:"omitempty,url,startswith=https://,max=1024"`
+	// Path LOCAL来源在实例上的应用包路径
+	Path string `json:"path,omitempty" validate:"omitempty,startswith=/,max=1024"`
+	// Checksum 应用包的SHA-256校验值, 实例下载应用包后校验
+	Checksum string `json:"checksum,omitempty" validate:"omitempty,len=64,hexadecimal"`
 }
 
 type CreateResponse struct {
@@ -49,4 +64,5 @@ type UploadResponse struct {
 	BucketName    string `json:"bucket_name" validate:"required,min=1,max=100"`
 	BucketKey     string `json:"bucket_key" validate:"required,min=1,max=100"`
 	StorageRegion string `json:"storage_region" validate:"required,min=1,max=100"`
+	Checksum      string `json:"checksum"`
 }
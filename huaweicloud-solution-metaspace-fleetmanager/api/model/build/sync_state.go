@@ -0,0 +1,18 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 应用包同步状态结构体定义
+package build
+
+// ReportStateRequest auxproxy上报的应用包下载状态, 下载过程中周期性上报进度
+type ReportStateRequest struct {
+	BuildId         string `json:"build_id"`
+	Region          string `json:"region"`
+	Bucket          string `json:"bucket"`
+	Object          string `json:"object"`
+	Result          int    `json:"result"`
+	State           string `json:"state" validate:"required,min=1,max=64"`
+	FleetId         string `json:"fleet_id" validate:"max=64"`
+	InstanceId      string `json:"instance_id" validate:"required,min=1,max=64"`
+	DownloadedBytes int64  `json:"downloaded_bytes" validate:"min=0"`
+	TotalBytes      int64  `json:"total_bytes"`
+}
@@ -9,15 +9,32 @@ type ResourceCreationLimitPolicy struct {
 }
 
 type ProcessConfiguration struct {
-	LaunchPath           string `json:"launch_path" validate:"startswith=/local/app/|startswith=c:/local/app/|startswith=C:/local/app/,min=0,max=1024"`
-	Parameters           string `json:"parameters" validate:"launchParameters"`
-	ConcurrentExecutions int    `json:"concurrent_executions" validate:"gte=1,lte=50"`
+	LaunchPath           string          `json:"launch_path" validate:"startswith=/local/app/|startswith=c:/local/app/|startswith=C:/local/app/,min=0,max=1024"`
+	Parameters           string          `json:"parameters" validate:"launchParameters"`
+	ConcurrentExecutions int             `json:"concurrent_executions" validate:"gte=1,lte=50"`
+	ResourceLimits       *ResourceLimits `json:"resource_limits,omitempty" validate:"omitempty"`
+}
+
+// ResourceLimits 单个应用进程的资源限制, 由auxproxy在Linux上通过cgroup v2和rlimit实施, 不填表示不限制
+type ResourceLimits struct {
+	CpuQuotaMillicores int `json:"cpu_quota_millicores,omitempty" validate:"omitempty,gte=100,lte=64000"`
+	MemoryMaxMB        int `json:"memory_max_mb,omitempty" validate:"omitempty,gte=64,lte=262144"`
+	MaxOpenFiles       int `json:"max_open_files,omitempty" validate:"omitempty,gte=64,lte=1048576"`
 }
 
 type RuntimeConfiguration struct {
 	ServerSessionActivationTimeoutSeconds int                    `json:"server_session_activation_timeout_seconds" validate:"gte=1,lte=600" default:"600"`
 	MaxConcurrentServerSessionsPerProcess int                    `json:"max_concurrent_server_sessions_per_process" validate:"gte=1,lte=50" default:"1"`
 	ProcessConfigurations                 []ProcessConfiguration `json:"process_configurations" validate:"required,dive,min=1,max=50"`
+	PlacementPolicy                       *PlacementPolicy       `json:"placement_policy,omitempty" validate:"omitempty"`
+}
+
+// PlacementPolicy 服务端会话在fleet内的进程放置策略: PACK优先放置到最繁忙的实例以便缩容, SPREAD优先放置到最空闲的实例,
+// PREFERRED优先放置到指定的实例或可用区, 其余按照PACK放置
+type PlacementPolicy struct {
+	Strategy                   string   `json:"strategy" validate:"required,oneof=PACK SPREAD PREFERRED"`
+	PreferredInstanceIds       []string `json:"preferred_instance_ids,omitempty" validate:"omitempty,max=20,dive,min=1,max=128"`
+	PreferredAvailabilityZones []string `json:"preferred_availability_zones,omitempty" validate:"omitempty,max=10,dive,min=1,max=64"`
 }
 
 type IpPermission struct {
@@ -36,6 +36,17 @@ type ListEventRsp struct {
 	Events []Event `json:"events"`
 }
 
+// CreateEventRequest auxproxy上报的fleet事件
+type CreateEventRequest struct {
+	EventCode    string `json:"event_code" validate:"oneof=PROCESS_CRASH_LOOP"`
+	InstanceId   string `json:"instance_id" validate:"omitempty,max=64"`
+	LaunchPath   string `json:"launch_path" validate:"min=1,max=1024"`
+	Parameters   string `json:"parameters" validate:"omitempty,max=1024"`
+	ExitCode     int    `json:"exit_code"`
+	FailureCount int    `json:"failure_count" validate:"gte=0"`
+	StderrTail   string `json:"stderr_tail" validate:"omitempty,max=4096"`
+}
+
 type InstanceCapacity struct {
 	Minimum int `json:"minimum"`
 	Maximum int `json:"maximum"`
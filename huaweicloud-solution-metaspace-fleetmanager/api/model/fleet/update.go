@@ -42,6 +42,7 @@ type UpdateRuntimeConfigurationRequest struct {
 	ServerSessionActivationTimeoutSeconds *int                         `json:"server_session_activation_timeout_seconds,omitempty" validate:"omitempty,gte=1,lte=600"`
 	MaxConcurrentServerSessionsPerProcess *int                         `json:"max_concurrent_server_sessions_per_process,omitempty" validate:"omitempty,gte=1,lte=50"`
 	ProcessConfigurations                 []UpdateProcessConfiguration `json:"process_configurations,omitempty" validate:"omitempty,dive,min=1,max=50"`
+	PlacementPolicy                       *PlacementPolicy             `json:"placement_policy,omitempty" validate:"omitempty"`
 }
 
 type UpdateFleetCapacityRequest struct {
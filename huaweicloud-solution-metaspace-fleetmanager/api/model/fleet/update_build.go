@@ -0,0 +1,65 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// fleet应用包原地更新结构体定义
+package fleet
+
+// UpdateBuildRequest 将fleet下所有实例原地更新为BuildId对应的应用包,
+// 每批最多MaxUnavailable个实例同时更新, 未填写的参数使用默认值
+type UpdateBuildRequest struct {
+	BuildId                 string `json:"build_id" validate:"required,min=1,max=64"`
+	MaxUnavailable          int    `json:"max_unavailable,omitempty" validate:"min=0,max=100"`
+	ReadinessTimeoutSeconds int    `json:"readiness_timeout_seconds,omitempty" validate:"min=0,max=3600"`
+}
+
+// UpdateBuildResponse 应用包原地更新返回响应结构
+type UpdateBuildResponse struct {
+	WorkflowId string `json:"workflow_id"`
+}
+
+// BuildUpdate 应用包原地更新工作流参数
+type BuildUpdate struct {
+	InstanceBuildUpdateRequest
+	MaxUnavailable int `json:"max_unavailable"`
+}
+
+// InstanceBuildUpdateRequest 发送给appgateway的实例原地更新请求
+type InstanceBuildUpdateRequest struct {
+	FleetId  string `json:"fleet_id"`
+	BuildId  string `json:"build_id"`
+	Bucket   string `json:"bucket"`
+	Object   string `json:"object"`
+	Region   string `json:"region"`
+	Checksum string `json:"checksum,omitempty"`
+	// SourceType 应用包来源, S3来源的Url在每批次下发前生成
+	SourceType              string `json:"source_type,omitempty"`
+	Url                     string `json:"url,omitempty"`
+	Path                    string `json:"path,omitempty"`
+	ReadinessTimeoutSeconds int    `json:"readiness_timeout_seconds"`
+}
+
+// InstanceBuildUpdateStatus appgateway返回的实例原地更新进度
+type InstanceBuildUpdateStatus struct {
+	InstanceId       string `json:"instance_id"`
+	BuildId          string `json:"build_id"`
+	State            string `json:"state"`
+	Message          string `json:"message,omitempty"`
+	TotalProcesses   int    `json:"total_processes"`
+	UpdatedProcesses int    `json:"updated_processes"`
+}
+
+// BuildSyncState 实例上应用包的下载进度
+type BuildSyncState struct {
+	InstanceId      string `json:"instance_id"`
+	BuildId         string `json:"build_id"`
+	Result          int    `json:"result"`
+	State           string `json:"state"`
+	DownloadedBytes int64  `json:"downloaded_bytes"`
+	TotalBytes      int64  `json:"total_bytes"`
+	UpdateTime      string `json:"update_time"`
+}
+
+// ListBuildSyncStateRsp fleet下实例的应用包下载进度列表
+type ListBuildSyncStateRsp struct {
+	Count      int              `json:"count"`
+	SyncStates []BuildSyncState `json:"sync_states"`
+}
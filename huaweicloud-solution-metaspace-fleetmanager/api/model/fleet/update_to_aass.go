@@ -13,6 +13,7 @@ type UpdateScalingGroupRequest struct {
 	InstanceConfiguration *UpdateInstanceConfiguration `json:"instance_configuration,omitempty"`
 	EnableAutoScaling     *bool                        `json:"enable_auto_scaling,omitempty"`
 	InstanceTags      	  *[]InstanceTag           	   `json:"instance_tags,omitempty"`
+	ImageId               *string                      `json:"image_id,omitempty"`
 }
 
 type UpdateInstanceConfiguration struct {
@@ -25,10 +26,12 @@ type UpdateRuntimeConfiguration struct {
 	ServerSessionActivationTimeoutSeconds *int                         `json:"server_session_activation_timeout_seconds,omitempty"`
 	MaxConcurrentServerSessionsPerProcess *int                         `json:"max_concurrent_server_sessions_per_process,omitempty"`
 	ProcessConfigurations                 []UpdateProcessConfiguration `json:"process_configurations,omitempty"`
+	PlacementPolicy                       *PlacementPolicy             `json:"placement_policy,omitempty"`
 }
 
 type UpdateProcessConfiguration struct {
-	LaunchPath           *string `json:"launch_path,omitempty" validate:"startswith=/local/app/|startswith=c:/local/app/|startswith=C:/local/app/,min=0,max=1024"`
-	Parameters           *string `json:"parameters,omitempty" validate:"min=0,max=1024"`
-	ConcurrentExecutions *int    `json:"concurrent_executions,omitempty" validate:"gte=1,lte=50"`
+	LaunchPath           *string         `json:"launch_path,omitempty" validate:"startswith=/local/app/|startswith=c:/local/app/|startswith=C:/local/app/,min=0,max=1024"`
+	Parameters           *string         `json:"parameters,omitempty" validate:"min=0,max=1024"`
+	ConcurrentExecutions *int            `json:"concurrent_executions,omitempty" validate:"gte=1,lte=50"`
+	ResourceLimits       *ResourceLimits `json:"resource_limits,omitempty" validate:"omitempty"`
 }
\ No newline at end of file
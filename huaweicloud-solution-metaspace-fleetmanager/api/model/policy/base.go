@@ -3,17 +3,74 @@
 // 弹性伸缩策略基本结构体定义
 package policy
 
+// TargetBasedConfiguration 基于目标策略, 同一fleet可以有多个不同指标的基于目标策略, 共同决定伸缩
 type TargetBasedConfiguration struct {
-	MetricName  string `json:"metric_name" validate:"required,oneof=PERCENT_AVAILABLE_SERVER_SESSIONS"`
-	TargetValue int    `json:"target_value" validate:"required,gte=1,lte=100"`
+	MetricName string `json:"metric_name" validate:"required,oneof=PERCENT_AVAILABLE_SERVER_SESSIONS PERCENT_AVAILABLE_PROCESSES QUEUED_SERVER_SESSIONS CPU_UTILIZATION MEMORY_UTILIZATION CUSTOM_METRIC"`
+	// CustomMetricName 进程通过SDK上报的自定义指标名称, 仅CUSTOM_METRIC使用
+	CustomMetricName string `json:"custom_metric_name,omitempty" validate:"required_if=MetricName CUSTOM_METRIC,omitempty,customMetricName"`
+	TargetValue      int    `json:"target_value" validate:"required,gte=1,targetValueOfMetric"`
+}
+
+// ScheduledConfiguration 定时策略, 时间窗内由策略的实例数上下限代替fleet自身的上下限, 基于目标的策略在其范围内伸缩
+type ScheduledConfiguration struct {
+	// Recurrence 周期时间窗的cron表达式(分 时 日 月 周), 与StartTime二选一
+	Recurrence string `json:"recurrence,omitempty" validate:"required_without=StartTime,excluded_with=StartTime,omitempty,max=128"`
+	// StartTime 单次时间窗的开始时间, 格式为2006-01-02T15:04:05
+	StartTime string `json:"start_time,omitempty" validate:"required_without=Recurrence,omitempty,datetime=2006-01-02T15:04:05"`
+	// DurationMinutes 时间窗的长度, 时间窗结束后恢复fleet自身的上下限
+	DurationMinutes int `json:"duration_minutes" validate:"required,gte=1,lte=43200"`
+	// TimeZone Recurrence和StartTime所在的时区, 如Asia/Shanghai, 默认为UTC
+	TimeZone             string `json:"time_zone,omitempty" validate:"omitempty,timezone"`
+	MinInstanceNumber    *int32 `json:"min_instance_number,omitempty" validate:"omitempty,gte=0"`
+	MaxInstanceNumber    *int32 `json:"max_instance_number,omitempty" validate:"omitempty,gte=0"`
+	DesireInstanceNumber *int32 `json:"desire_instance_number,omitempty" validate:"omitempty,gte=0"`
+}
+
+// StepConfiguration 步进策略, 指标连续EvaluationPeriods个监控周期落在同一伸缩方向的区间时, 按区间的步长伸缩
+type StepConfiguration struct {
+	MetricName       string `json:"metric_name" validate:"required,oneof=PERCENT_AVAILABLE_SERVER_SESSIONS CUSTOM_METRIC"`
+	CustomMetricName string `json:"custom_metric_name,omitempty" validate:"required_if=MetricName CUSTOM_METRIC,omitempty,customMetricName"`
+	// Steps 按指标值从小到大排列且互不重叠的区间, PERCENT_AVAILABLE_SERVER_SESSIONS的指标值为0~100的百分比
+	Steps []StepAdjustment `json:"steps" validate:"required,min=1,max=10,dive"`
+	// EvaluationPeriods 指标需要连续落在同一伸缩方向区间的监控周期数, 默认为1
+	EvaluationPeriods *int32 `json:"evaluation_periods,omitempty" validate:"omitempty,gte=1,lte=10"`
+	// ScaleOutCoolDownTime 扩容后再次扩容的冷却时长(min), 默认为fleet的冷却时长
+	ScaleOutCoolDownTime *int32 `json:"scale_out_cool_down_time,omitempty" validate:"omitempty,gte=0,lte=30"`
+	// ScaleInCoolDownTime 扩容或缩容后再次缩容的冷却时长(min), 默认为fleet的冷却时长
+	ScaleInCoolDownTime *int32 `json:"scale_in_cool_down_time,omitempty" validate:"omitempty,gte=0,lte=30"`
+}
+
+// StepAdjustment 指标值落在[LowerBound, UpperBound)时的伸缩步长, 未指定的边界为无穷
+type StepAdjustment struct {
+	LowerBound     *float64 `json:"lower_bound,omitempty"`
+	UpperBound     *float64 `json:"upper_bound,omitempty"`
+	AdjustmentType string   `json:"adjustment_type" validate:"required,oneof=CHANGE_IN_CAPACITY PERCENT_CHANGE_IN_CAPACITY"`
+	// Adjustment 正数扩容, 负数缩容, 0不伸缩
+	Adjustment *int32 `json:"adjustment" validate:"required,gte=-1000,lte=1000"`
+}
+
+// PredictiveConfiguration 预测策略, 根据历史ServerSession需求预测未来ForecastMinutes内的需求, 提前扩容
+type PredictiveConfiguration struct {
+	// Mode FORECAST_ONLY只预测不伸缩, 用于对比预测与实际需求; FORECAST_AND_SCALE按预测提前扩容
+	Mode string `json:"mode" validate:"required,oneof=FORECAST_ONLY FORECAST_AND_SCALE"`
+	// ForecastMinutes 预测时长, 应覆盖实例创建和应用包同步的时长
+	ForecastMinutes int `json:"forecast_minutes" validate:"required,gte=5,lte=240"`
+	// SeasonDays 需求的周期(天), 1为按天, 7为按周, 默认为1
+	SeasonDays int `json:"season_days,omitempty" validate:"omitempty,oneof=1 7"`
+	// TargetValue 预测需求下期望保留的可用会话百分比
+	TargetValue *int32 `json:"target_value" validate:"required,gte=0,lte=90"`
 }
 
 type ScalingPolicy struct {
-	Id                       string                   `json:"policy_id"`
-	Name                     string                   `json:"name"`
-	FleetId                  string                   `json:"fleet_id"`
-	PolicyType               string                   `json:"policy_type"`
-	ScalingTarget            string                   `json:"scaling_target"`
-	State                    string                   `json:"state"`
-	TargetBasedConfiguration TargetBasedConfiguration `json:"target_based_configuration"`
+	Id                       string                    `json:"policy_id"`
+	Name                     string                    `json:"name"`
+	FleetId                  string                    `json:"fleet_id"`
+	PolicyType               string                    `json:"policy_type"`
+	ScalingTarget            string                    `json:"scaling_target"`
+	State                    string                    `json:"state"`
+	TargetBasedConfiguration *TargetBasedConfiguration `json:"target_based_configuration,omitempty"`
+	ScheduledConfiguration   *ScheduledConfiguration   `json:"scheduled_configuration,omitempty"`
+	StepConfiguration        *StepConfiguration        `json:"step_configuration,omitempty"`
+	PredictiveConfiguration  *PredictiveConfiguration  `json:"predictive_configuration,omitempty"`
+	DryRun                   bool                      `json:"dry_run"`
 }
@@ -4,10 +4,15 @@
 package policy
 
 type CreateRequest struct {
-	Name                     string                   `json:"name" validate:"required,min=1,max=1024"`
-	PolicyType               string                   `json:"policy_type" validate:"required,oneof=TARGET_BASED"`
-	ScalingTarget            string                   `json:"scaling_target" validate:"required,oneof=INSTANCE"`
-	TargetBasedConfiguration TargetBasedConfiguration `json:"target_based_configuration" validate:"required,dive"`
+	Name                     string                    `json:"name" validate:"required,min=1,max=1024"`
+	PolicyType               string                    `json:"policy_type" validate:"required,oneof=TARGET_BASED SCHEDULED STEP PREDICTIVE"`
+	ScalingTarget            string                    `json:"scaling_target" validate:"required,oneof=INSTANCE"`
+	TargetBasedConfiguration *TargetBasedConfiguration `json:"target_based_configuration,omitempty" validate:"required_if=PolicyType TARGET_BASED"`
+	ScheduledConfiguration   *ScheduledConfiguration   `json:"scheduled_configuration,omitempty" validate:"required_if=PolicyType SCHEDULED"`
+	StepConfiguration        *StepConfiguration        `json:"step_configuration,omitempty" validate:"required_if=PolicyType STEP"`
+	PredictiveConfiguration  *PredictiveConfiguration  `json:"predictive_configuration,omitempty" validate:"required_if=PolicyType PREDICTIVE"`
+	// DryRun 只记录伸缩决定, 不执行伸缩
+	DryRun bool `json:"dry_run,omitempty"`
 }
 
 type CreateScalingPolicyResponse struct {
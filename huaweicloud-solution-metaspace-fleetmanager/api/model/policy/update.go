@@ -6,6 +6,10 @@ package policy
 type UpdateRequest struct {
 	Name                     *string                  `json:"name,omitempty" validate:"omitempty,min=1,max=1024"`
 	TargetBasedConfiguration *TargetBasedConfiguration `json:"target_based_configuration,omitempty" validate:"omitempty,dive"`
+	ScheduledConfiguration   *ScheduledConfiguration   `json:"scheduled_configuration,omitempty" validate:"omitempty"`
+	StepConfiguration        *StepConfiguration        `json:"step_configuration,omitempty" validate:"omitempty"`
+	PredictiveConfiguration  *PredictiveConfiguration  `json:"predictive_configuration,omitempty" validate:"omitempty"`
+	DryRun                   *bool                     `json:"dry_run,omitempty"`
 }
 
 // NewUpdateRequest: 新建更新策略请求
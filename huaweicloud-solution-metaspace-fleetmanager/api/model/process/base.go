@@ -26,6 +26,7 @@ type AppProcess struct {
 	AuxProxyPort                            int    `json:"aux_proxy_port"`
 	LogPath                                 string `json:"log_path"`
 	State                                   string `json:"state"`
+	StateReason                             string `json:"state_reason,omitempty"`
 	ServerSessionCount                      int    `json:"server_session_count"`
 	MaxServerSessionNum                     int    `json:"max_server_session_num"`
 	NewServerSessionProtectionPolicy        string `json:"new_server_session_protection_policy"`
@@ -8,20 +8,35 @@ type Property struct {
 	Value string `json:"value" validate:"required,min=1,max=96"`
 }
 
+// LabelSelector 服务端会话的标签选择器, 只有满足全部条件的进程才能承载该会话
+type LabelSelector struct {
+	MatchLabels      map[string]string          `json:"match_labels,omitempty" validate:"omitempty,max=16,dive,keys,min=1,max=64,endkeys,max=256"`
+	MatchExpressions []LabelSelectorRequirement `json:"match_expressions,omitempty" validate:"omitempty,max=16,dive"`
+}
+
+// LabelSelectorRequirement 标签选择条件, In及NotIn需要填写values
+type LabelSelectorRequirement struct {
+	Key      string   `json:"key" validate:"required,min=1,max=64"`
+	Operator string   `json:"operator" validate:"required,oneof=In NotIn Exists DoesNotExist"`
+	Values   []string `json:"values,omitempty" validate:"required_if=Operator In,required_if=Operator NotIn,max=16,dive,max=256"`
+}
+
 type ServerSession struct {
-	ServerSessionId                         string     `json:"server_session_id"`
-	Name                                    string     `json:"name"`
-	CreatorId                               string     `json:"creator_id"`
-	FleetId                                 string     `json:"fleet_id"`
-	Properties                              []Property `json:"server_session_properties"`
-	ServerSessionData                       string     `json:"server_session_data"`
-	CurrentClientSessionCount               int        `json:"current_client_session_count"`
-	MaxClientSessionCount                   int        `json:"max_client_session_count"`
-	State                                   string     `json:"state"`
-	StateReason                             string     `json:"state_reason"`
-	IpAddress                               string     `json:"ip_address"`
-	Port                                    int        `json:"port"`
-	ClientSessionCreationPolicy             string     `json:"client_session_creation_policy"`
-	ServerSessionProtectionPolicy           string     `json:"server_session_protection_policy"`
-	ServerSessionProtectionTimeLimitMinutes int        `json:"server_session_protection_time_limit_minutes"`
+	ServerSessionId                         string         `json:"server_session_id"`
+	Name                                    string         `json:"name"`
+	CreatorId                               string         `json:"creator_id"`
+	FleetId                                 string         `json:"fleet_id"`
+	Properties                              []Property     `json:"server_session_properties"`
+	ServerSessionData                       string         `json:"server_session_data"`
+	CurrentClientSessionCount               int            `json:"current_client_session_count"`
+	MaxClientSessionCount                   int            `json:"max_client_session_count"`
+	State                                   string         `json:"state"`
+	StateReason                             string         `json:"state_reason"`
+	IpAddress                               string         `json:"ip_address"`
+	Port                                    int            `json:"port"`
+	ClientSessionCreationPolicy             string         `json:"client_session_creation_policy"`
+	ServerSessionProtectionPolicy           string         `json:"server_session_protection_policy"`
+	ServerSessionProtectionTimeLimitMinutes int            `json:"server_session_protection_time_limit_minutes"`
+	LabelSelector                           *LabelSelector `json:"label_selector,omitempty"`
+	PlacementTimeoutSeconds                 int            `json:"placement_timeout_seconds"`
 }
@@ -4,14 +4,17 @@
 package serversession
 
 type CreateRequest struct {
-	FleetId                 string     `json:"fleet_id,omitempty" validate:"omitempty,min=0,max=64"`
-	CreatorId               string     `json:"creator_id" validate:"min=0,max=1024"`
-	AliasId                 string     `json:"alias_id,omitempty" validate:"omitempty,min=0,max=64"`
-	Name                    string     `json:"name" validate:"min=0,max=1024"`
-	MaxClientSessionCount   int        `json:"max_client_session_count" validate:"required,gte=1,lte=1024"`
-	IdempotencyToken        string     `json:"idempotency_token" validate:"min=0,max=48"`
-	ServerSessionData       string     `json:"server_session_data" validate:"min=0,max=4096"`
-	ServerSessionProperties []Property `json:"server_session_properties" validate:"omitempty,dive,min=0,max=16"`
+	FleetId                 string         `json:"fleet_id,omitempty" validate:"omitempty,min=0,max=64"`
+	CreatorId               string         `json:"creator_id" validate:"min=0,max=1024"`
+	AliasId                 string         `json:"alias_id,omitempty" validate:"omitempty,min=0,max=64"`
+	Name                    string         `json:"name" validate:"min=0,max=1024"`
+	MaxClientSessionCount   int            `json:"max_client_session_count" validate:"required,gte=1,lte=1024"`
+	IdempotencyToken        string         `json:"idempotency_token" validate:"min=0,max=48"`
+	ServerSessionData       string         `json:"server_session_data" validate:"min=0,max=4096"`
+	ServerSessionProperties []Property     `json:"server_session_properties" validate:"omitempty,dive,min=0,max=16"`
+	RegionPreferences       []string       `json:"region_preferences,omitempty" validate:"omitempty,max=10,dive,min=1,max=64"`
+	LabelSelector           *LabelSelector `json:"label_selector,omitempty" validate:"omitempty"`
+	PlacementTimeoutSeconds int            `json:"placement_timeout_seconds,omitempty" validate:"omitempty,gte=0,lte=600"`
 }
 
 type CreateServerSessionResponse struct {
@@ -19,13 +22,15 @@ type CreateServerSessionResponse struct {
 }
 
 type CreateRequestToAppGW struct {
-	FleetId                 string     `json:"fleet_id" validate:"required,min=1,max=64"`
-	CreatorId               string     `json:"creator_id" validate:"min=0,max=1024"`
-	Name                    string     `json:"name" validate:"min=0,max=1024"`
-	MaxClientSessionNum     int        `json:"max_client_session_num" validate:"required,gte=1,lte=1024"`
-	IdempotencyToken        string     `json:"idempotency_token" validate:"min=0,max=48"`
-	ServerSessionData       string     `json:"server_session_data" validate:"min=0,max=4096"`
-	ServerSessionProperties []Property `json:"server_session_properties" validate:"omitempty,dive,min=0,max=16"`
+	FleetId                 string         `json:"fleet_id" validate:"required,min=1,max=64"`
+	CreatorId               string         `json:"creator_id" validate:"min=0,max=1024"`
+	Name                    string         `json:"name" validate:"min=0,max=1024"`
+	MaxClientSessionNum     int            `json:"max_client_session_num" validate:"required,gte=1,lte=1024"`
+	IdempotencyToken        string         `json:"idempotency_token" validate:"min=0,max=48"`
+	ServerSessionData       string         `json:"server_session_data" validate:"min=0,max=4096"`
+	ServerSessionProperties []Property     `json:"server_session_properties" validate:"omitempty,dive,min=0,max=16"`
+	LabelSelector           *LabelSelector `json:"label_selector,omitempty"`
+	PlacementTimeoutSeconds int            `json:"placement_timeout_seconds,omitempty"`
 }
 
 type CreateServerSessionResponseFromAppGW struct {
@@ -33,21 +38,23 @@ type CreateServerSessionResponseFromAppGW struct {
 }
 
 type ServerSessionFromAppGW struct {
-	ServerSessionId                         string     `json:"server_session_id"`
-	Name                                    string     `json:"name"`
-	CreatorId                               string     `json:"creator_id"`
-	FleetId                                 string     `json:"fleet_id"`
-	Properties                              []Property `json:"server_session_properties"`
-	ServerSessionData                       string     `json:"server_session_data"`
-	CurrentClientSessionCount               int        `json:"client_session_count"`
-	MaxClientSessionCount                   int        `json:"max_client_session_num"`
-	State                                   string     `json:"state"`
-	StateReason                             string     `json:"state_reason"`
-	IpAddress                               string     `json:"ip_address"`
-	Port                                    int        `json:"port"`
-	ClientSessionCreationPolicy             string     `json:"client_session_creation_policy"`
-	ServerSessionProtectionPolicy           string     `json:"server_session_protection_policy"`
-	ServerSessionProtectionTimeLimitMinutes int        `json:"server_session_protection_time_limit_minutes"`
-	CreationTime                            string     `json:"creation_time"`
-	TerminationTime                         string     `json:"termination_time"`
+	ServerSessionId                         string         `json:"server_session_id"`
+	Name                                    string         `json:"name"`
+	CreatorId                               string         `json:"creator_id"`
+	FleetId                                 string         `json:"fleet_id"`
+	Properties                              []Property     `json:"server_session_properties"`
+	ServerSessionData                       string         `json:"server_session_data"`
+	CurrentClientSessionCount               int            `json:"client_session_count"`
+	MaxClientSessionCount                   int            `json:"max_client_session_num"`
+	State                                   string         `json:"state"`
+	StateReason                             string         `json:"state_reason"`
+	IpAddress                               string         `json:"ip_address"`
+	Port                                    int            `json:"port"`
+	ClientSessionCreationPolicy             string         `json:"client_session_creation_policy"`
+	ServerSessionProtectionPolicy           string         `json:"server_session_protection_policy"`
+	ServerSessionProtectionTimeLimitMinutes int            `json:"server_session_protection_time_limit_minutes"`
+	CreationTime                            string         `json:"creation_time"`
+	TerminationTime                         string         `json:"termination_time"`
+	LabelSelector                           *LabelSelector `json:"label_selector,omitempty"`
+	PlacementTimeoutSeconds                 int            `json:"placement_timeout_seconds"`
 }
@@ -0,0 +1,43 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// workflow查询结构体定义
+package workflow
+
+type List struct {
+	TotalCount int        `json:"total_count"`
+	Count      int        `json:"count"`
+	Workflows  []Workflow `json:"workflows"`
+}
+
+type Workflow struct {
+	WorkflowId     string `json:"workflow_id"`
+	Name           string `json:"name"`
+	ResourceId     string `json:"resource_id"`
+	State          string `json:"state"`
+	WorkNodeId     string `json:"work_node_id"`
+	CompletedTasks int    `json:"completed_tasks"`
+	TotalTasks     int    `json:"total_tasks"`
+	CreationTime   string `json:"creation_time"`
+	UpdateTime     string `json:"update_time"`
+	Tasks          []Task `json:"tasks"`
+}
+
+type Task struct {
+	Step               int      `json:"step"`
+	Name               string   `json:"name"`
+	Description        string   `json:"description"`
+	TaskType           string   `json:"task_type"`
+	DependsOn          []string `json:"depends_on"`
+	State              string   `json:"state"`
+	RetryTimes         int      `json:"retry_times"`
+	RollbackRetryTimes int      `json:"rollback_retry_times"`
+	LastError          string   `json:"last_error"`
+	Failed             bool     `json:"failed"`
+	Skipped            bool     `json:"skipped"`
+	Ignorable          bool     `json:"ignorable"`
+	UpdateTime         string   `json:"update_time"`
+}
+
+type ShowWorkflowResponse struct {
+	Workflow Workflow `json:"workflow"`
+}
@@ -0,0 +1,8 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// workflow运维操作结构体定义
+package workflow
+
+type SkipTaskRequest struct {
+	TaskName string `json:"task_name" validate:"required,min=1,max=128"`
+}
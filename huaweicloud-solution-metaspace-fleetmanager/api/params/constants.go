@@ -11,6 +11,7 @@ const (
 	AliasId               = ":alias_id"
 	ServerSessionId       = ":server_session_id"
 	ClientSessionId       = ":client_session_id"
+	WorkflowId            = ":workflow_id"
 	QueryRegionId         = "region_id"
 	QueryBucketKey        = "bucket_key"
 	QueryOffset           = "offset"
@@ -26,6 +27,8 @@ const (
 	QueryType             = "type"
 	QueryAccessConfigId   = "access_config_id"
 	QueryLogStreamId      = "log_stream_id"
+	QueryResourceId       = "resource_id"
+	QueryBuildId          = "build_id"
 )
 
 const (
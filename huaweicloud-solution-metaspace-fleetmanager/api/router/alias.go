@@ -16,4 +16,6 @@ func initAliasRouters() {
 	web.Router("/v1/:project_id/aliases/:alias_id", &alias.DeleteController{}, "delete:Delete")
 	web.Router("/v1/:project_id/aliases/:alias_id",
 		&alias.UpdateController{}, "put:Update")
+	web.Router("/v1/:project_id/aliases/:alias_id/rollout",
+		&alias.UpdateController{}, "post:Rollout")
 }
@@ -21,6 +21,10 @@ func initBuildRouters() {
 	web.Router("/v1/:project_id/builds/upload",
 		&build.UploadController{}, "post:Upload")
 
+	// auxproxy上报应用包下载状态, 内部接口
+	web.Router("/v1/build/:build_id/state",
+		&build.UpdateController{}, "post:ReportState")
+
 	// 获取上传文件授权信息
 	web.Router("/v1/:project_id/builds/uploadcredentials",
 		&build.QueryController{}, "get:GetUploadCredentials")
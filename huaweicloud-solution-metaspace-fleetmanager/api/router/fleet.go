@@ -20,6 +20,12 @@ func initFleetRouters() {
 	web.Router("/v1/:project_id/fleets/:fleet_id",
 		&fleet.UpdateController{}, "put:UpdateAttributes")
 
+	// fleet build update
+	web.Router("/v1/:project_id/fleets/:fleet_id/build-update",
+		&fleet.UpdateController{}, "post:UpdateBuild")
+	web.Router("/v1/:project_id/fleets/:fleet_id/build-sync-states",
+		&fleet.QueryController{}, "get:ListBuildSyncStates")
+
 	// fleet inbound permissions
 	web.Router("/v1/:project_id/fleets/:fleet_id/inbound-permissions",
 		&fleet.UpdateController{}, "put:UpdateInboundPermissions")
@@ -35,6 +41,9 @@ func initFleetRouters() {
 	// fleet event
 	web.Router("/v1/:project_id/fleets/:fleet_id/events",
 		&fleet.QueryController{}, "get:ListFleetEvents")
+	// auxproxy上报fleet事件, 内部接口
+	web.Router("/v1/fleets/:fleet_id/events",
+		&fleet.CreateController{}, "post:CreateFleetEvent")
 
 	// fleet capacity
 	web.Router("/v1/:project_id/fleets/:fleet_id/instance-capacity",
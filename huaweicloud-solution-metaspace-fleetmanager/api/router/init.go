@@ -14,4 +14,5 @@ func Init() {
 	initProcessRouters()
 	initAliasRouters()
 	initLtsRouter()
+	initWorkflowRouters()
 }
@@ -17,4 +17,8 @@ func initPolicyRouters() {
 		&policy.DeleteController{}, "delete:Delete")
 	web.Router("/v1/:project_id/fleets/:fleet_id/scaling-policies/:policy_id",
 		&policy.UpdateController{}, "put:Update")
+	web.Router("/v1/:project_id/fleets/:fleet_id/scaling-policies/:policy_id/forecasts",
+		&policy.QueryController{}, "get:Forecast")
+	web.Router("/v1/:project_id/fleets/:fleet_id/scaling-decisions",
+		&policy.QueryController{}, "get:Decisions")
 }
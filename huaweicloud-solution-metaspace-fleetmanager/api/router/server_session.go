@@ -17,4 +17,6 @@ func initServerSessionRouters() {
 		&serversession.QueryController{}, "get:Show")
 	web.Router("/v1/:project_id/server-sessions/:server_session_id",
 		&serversession.UpdateController{}, "put:Update")
+	web.Router("/v1/:project_id/server-sessions/:server_session_id/cancel",
+		&serversession.CancelController{}, "post:Cancel")
 }
@@ -0,0 +1,23 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// workflow api定义
+package router
+
+import (
+	"fleetmanager/api/controller/workflow"
+	"github.com/beego/beego/v2/server/web"
+)
+
+func initWorkflowRouters() {
+	web.Router("/v1/:project_id/workflows", &workflow.QueryController{}, "get:List")
+	web.Router("/v1/:project_id/workflows/:workflow_id",
+		&workflow.QueryController{}, "get:Show")
+
+	// workflow operator actions
+	web.Router("/v1/:project_id/workflows/:workflow_id/retry",
+		&workflow.UpdateController{}, "post:Retry")
+	web.Router("/v1/:project_id/workflows/:workflow_id/skip",
+		&workflow.UpdateController{}, "post:Skip")
+	web.Router("/v1/:project_id/workflows/:workflow_id/cancel",
+		&workflow.UpdateController{}, "post:Cancel")
+}
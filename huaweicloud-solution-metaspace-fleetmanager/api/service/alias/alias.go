@@ -49,6 +49,9 @@ func (s *Service) buildAlias() error {
 	if err != nil {
 		return fmt.Errorf("marshal associated fleets: %+v err: %+v", s.createRequest.AssociatedFleets, err)
 	}
+	if s.createRequest.RoutingStrategy == "" {
+		s.createRequest.RoutingStrategy = dao.AliasRoutingStrategyWeighted
+	}
 	a := &dao.Alias{
 		Id:           aliasId,
 		ProjectId:    s.Ctx.Input.Param(params.ProjectId),
@@ -59,6 +62,7 @@ func (s *Service) buildAlias() error {
 		AssociatedFleets: string(associatedFleetByte),
 		Type:         s.createRequest.Type,
 		Message:      s.createRequest.Message,
+		RoutingStrategy: s.createRequest.RoutingStrategy,
 	}
 	s.alias = a
 	return nil
@@ -87,13 +91,18 @@ func GenerateFleetByAssociateFleetWeight(afts string) (string, *errors.CodedErro
 	if err != nil {
 		return "", errors.NewErrorF(errors.ServerInternalError, err.Error())
 	}
-	if len(*associatedFleets) < 1 {
+	return pickByWeight(*associatedFleets)
+}
+
+// pickByWeight 按照权重随机选择fleet
+func pickByWeight(associatedFleets []alias.AssociatedFleet) (string, *errors.CodedError) {
+	if len(associatedFleets) < 1 {
 		return "", errors.NewError(errors.AliasNoAvailableFleet)
 	}
 	// 随机种子
 	rand.Seed(time.Now().UTC().UnixNano())
 	availableFleetsChoices := []weightedrand.Choice{}
-	for _, aft := range *associatedFleets {
+	for _, aft := range associatedFleets {
 		availableFleetsChoices = append(availableFleetsChoices, 
 			weightedrand.NewChoice(aft.FleetId, uint(aft.Weight*WeightPrecision)))
 	}
@@ -28,6 +28,7 @@ func buildAliasModel(a *dao.Alias) (*alias.Alias, error) {
 		AssociatedFleets: associatedFleet,
 		Type:    a.Type,
 		Message: a.Message,
+		RoutingStrategy: routingStrategyOf(a),
 	}
 	return &aliasModel, nil
 }
@@ -161,5 +161,6 @@ func GenerateAliasRsp(als dao.Alias, alsrsp []alias.AssociatedFleetRsp) alias.Sh
 		AssociatedFleets: alsrsp,
 		Type:             als.Type,
 		Message:          als.Message,
+		RoutingStrategy:  routingStrategyOf(&als),
 	}
 }
@@ -0,0 +1,144 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// alias蓝绿切换方法
+package alias
+
+import (
+	"encoding/json"
+	"fleetmanager/api/errors"
+	"fleetmanager/api/model/alias"
+	"fleetmanager/api/params"
+	"fleetmanager/api/validator"
+	"fleetmanager/db/dao"
+	"fleetmanager/logger"
+	"fleetmanager/workflow"
+	"fleetmanager/workflow/directer"
+	"fleetmanager/worknode"
+	"fmt"
+
+	"github.com/beego/beego/v2/client/orm"
+)
+
+const (
+	defaultRolloutStepIntervalSeconds = 60
+	defaultRolloutMaxErrorRate        = 0.05
+)
+
+var (
+	defaultRolloutSteps   = []float32{0.1, 0.5, 1}
+	runningWorkflowStates = []string{dao.WorkflowStateCreate, dao.WorkflowStateRunning,
+		dao.WorkflowStateRollbacking}
+)
+
+// Rollout 启动alias蓝绿切换工作流, 将流量逐步从旧fleet切换到新fleet
+func (s *Service) Rollout() (*alias.RolloutResponse, *errors.CodedError) {
+	if err := s.setAlias(); err != nil {
+		s.Logger.Error("get alias info db error: %v", err)
+		if err == orm.ErrNoRows {
+			return nil, errors.NewError(errors.AliasNotFound)
+		}
+		return nil, errors.NewError(errors.DBError)
+	}
+	req, e := s.buildRolloutReq()
+	if e != nil {
+		return nil, e
+	}
+	if e := s.checkRollout(req); e != nil {
+		return nil, e
+	}
+
+	parameter := map[string]interface{}{
+		directer.WfKeyAliasId:      s.alias.Id,
+		directer.WfKeyAliasRollout: req,
+		directer.WfKeyRegion:       s.Fleet.Region,
+		directer.WfKeyRequestId:    fmt.Sprintf("%s", s.Ctx.Input.GetData(logger.RequestId)),
+	}
+	wf, err := workflow.CreateWorkflow(
+		"./conf/workflow/alias_rollout_workflow.json",
+		parameter,
+		s.alias.Id,
+		s.Ctx.Input.Param(params.ProjectId),
+		s.Logger,
+		worknode.WorkNodeId)
+	if err != nil {
+		s.Logger.Error("create workflow in alias rollout error: %v", err)
+		return nil, errors.NewError(errors.ServerInternalError)
+	}
+	wf.Run()
+
+	return &alias.RolloutResponse{WorkflowId: wf.Id}, nil
+}
+
+// buildRolloutReq 请求参数校验, 并为未填写的参数设置默认值
+func (s *Service) buildRolloutReq() (*alias.RolloutRequest, *errors.CodedError) {
+	req := &alias.RolloutRequest{}
+	if err := json.Unmarshal(s.Ctx.Input.RequestBody, req); err != nil {
+		s.Logger.Error("unmarshal request body %v error: %v", s.Ctx.Input.RequestBody, err)
+		return nil, errors.NewErrorF(errors.InvalidParameterValue, " read request params error")
+	}
+	if err := validator.Validate(req); err != nil {
+		s.Logger.Error("request params invalid, reqBody:%s, err:%+v", s.Ctx.Input.RequestBody, err)
+		return nil, errors.NewErrorF(errors.InvalidParameterValue, err.Error())
+	}
+
+	if len(req.Steps) == 0 {
+		req.Steps = defaultRolloutSteps
+	}
+	if req.StepIntervalSeconds == 0 {
+		req.StepIntervalSeconds = defaultRolloutStepIntervalSeconds
+	}
+	if req.MaxErrorRate == nil {
+		rate := defaultRolloutMaxErrorRate
+		req.MaxErrorRate = &rate
+	}
+	for i := 1; i < len(req.Steps); i++ {
+		if req.Steps[i] <= req.Steps[i-1] {
+			return nil, errors.NewErrorF(errors.InvalidParameterValue, "steps must be increasing")
+		}
+	}
+	if req.Steps[len(req.Steps)-1] != 1 {
+		return nil, errors.NewErrorF(errors.InvalidParameterValue, "the last step must be 1")
+	}
+
+	return req, nil
+}
+
+// checkRollout 校验alias与新旧fleet的状态, 同一alias同时只允许一个蓝绿切换
+func (s *Service) checkRollout(req *alias.RolloutRequest) *errors.CodedError {
+	if s.alias.Type != dao.AliasTypeActive {
+		return errors.NewError(errors.AliasIsDeactive)
+	}
+	fleets := []alias.AssociatedFleet{}
+	if err := json.Unmarshal([]byte(s.alias.AssociatedFleets), &fleets); err != nil {
+		s.Logger.Error("unmarshal alias %s associated fleets error: %v", s.alias.Id, err)
+		return errors.NewError(errors.ServerInternalError)
+	}
+	associated := false
+	for _, af := range fleets {
+		if af.FleetId == req.OldFleetId {
+			associated = true
+			break
+		}
+	}
+	if !associated {
+		return errors.NewErrorF(errors.AliasFleetNotAssociated, fmt.Sprintf("fleet_id: %s", req.OldFleetId))
+	}
+
+	if e := s.SetFleetById(req.NewFleetId); e != nil {
+		return e
+	}
+	if s.Fleet.State != dao.FleetStateActive {
+		return errors.NewErrorF(errors.FleetNotActive, fmt.Sprintf("fleet_id: %s", req.NewFleetId))
+	}
+
+	n, err := dao.CountWorkflows(dao.Filters{"ResourceId": s.alias.Id, "State__in": runningWorkflowStates})
+	if err != nil {
+		s.Logger.Error("count workflows of alias %s error: %v", s.alias.Id, err)
+		return errors.NewError(errors.DBError)
+	}
+	if n > 0 {
+		return errors.NewError(errors.AliasRolloutInProgress)
+	}
+
+	return nil
+}
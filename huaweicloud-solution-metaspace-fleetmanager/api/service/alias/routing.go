@@ -0,0 +1,241 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// alias路由策略
+package alias
+
+import (
+	"encoding/json"
+	"fleetmanager/api/errors"
+	"fleetmanager/api/model/alias"
+	"fleetmanager/api/model/fleet"
+	"fleetmanager/api/params"
+	"fleetmanager/api/service/constants"
+	"fleetmanager/client"
+	"fleetmanager/db/dao"
+	"fleetmanager/logger"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"net/http"
+	"strings"
+)
+
+// RouteRequest 会话路由请求信息
+type RouteRequest struct {
+	CreatorId         string
+	RegionPreferences []string
+	RequestId         string
+	Logger            *logger.FMLogger
+}
+
+// routeCandidate 可供路由的fleet
+type routeCandidate struct {
+	FleetId string
+	Region  string
+	Weight  float32
+}
+
+// routingStrategy 从候选fleet中选择一个, 候选fleet的权重均大于0
+type routingStrategy func(candidates []routeCandidate, r *RouteRequest) (string, *errors.CodedError)
+
+var routingStrategies = map[string]routingStrategy{
+	dao.AliasRoutingStrategyWeighted:      pickWeighted,
+	dao.AliasRoutingStrategyStickyCreator: pickStickyCreator,
+	dao.AliasRoutingStrategyCapacityAware: pickCapacityAware,
+}
+
+func routingStrategyOf(a *dao.Alias) string {
+	if a.RoutingStrategy == "" {
+		return dao.AliasRoutingStrategyWeighted
+	}
+	return a.RoutingStrategy
+}
+
+// RouteFleet 根据alias的路由策略选择会话应该在哪个fleet下创建: 先按照客户端的区域偏好筛选fleet, 再由路由策略选择
+func RouteFleet(a *dao.Alias, r *RouteRequest) (string, *errors.CodedError) {
+	strategy, ok := routingStrategies[routingStrategyOf(a)]
+	if !ok {
+		return "", errors.NewErrorF(errors.RoutingStrategyNotFound, fmt.Sprintf(" strategy: %s", a.RoutingStrategy))
+	}
+	candidates, e := buildRouteCandidates(a.AssociatedFleets)
+	if e != nil {
+		return "", e
+	}
+	return strategy(preferRegions(candidates, r.RegionPreferences), r)
+}
+
+// getFleetRegions 查询fleet所在的区域
+var getFleetRegions = func(fleetIds []string) (map[string]string, error) {
+	fleets, err := dao.GetFleetStorage().List(dao.Filters{"Id__in": fleetIds}, 0, len(fleetIds))
+	if err != nil {
+		return nil, err
+	}
+	regions := make(map[string]string, len(fleets))
+	for _, f := range fleets {
+		regions[f.Id] = f.Region
+	}
+	return regions, nil
+}
+
+func buildRouteCandidates(afts string) ([]routeCandidate, *errors.CodedError) {
+	associatedFleets := []alias.AssociatedFleet{}
+	if err := json.Unmarshal([]byte(afts), &associatedFleets); err != nil {
+		return nil, errors.NewErrorF(errors.ServerInternalError, err.Error())
+	}
+	var fleetIds []string
+	for _, aft := range associatedFleets {
+		if uint(aft.Weight*WeightPrecision) > 0 {
+			fleetIds = append(fleetIds, aft.FleetId)
+		}
+	}
+	if len(fleetIds) == 0 {
+		return nil, errors.NewError(errors.AliasNoAvailableFleet)
+	}
+
+	regions, err := getFleetRegions(fleetIds)
+	if err != nil {
+		return nil, errors.NewError(errors.DBError)
+	}
+	var candidates []routeCandidate
+	for _, aft := range associatedFleets {
+		region, ok := regions[aft.FleetId]
+		if !ok || uint(aft.Weight*WeightPrecision) == 0 {
+			continue
+		}
+		candidates = append(candidates, routeCandidate{FleetId: aft.FleetId, Region: region, Weight: aft.Weight})
+	}
+	if len(candidates) == 0 {
+		return nil, errors.NewError(errors.AliasNoAvailableFleet)
+	}
+	return candidates, nil
+}
+
+// preferRegions 返回区域偏好列表中第一个有可用fleet的区域下的fleet, 都没有时返回全部fleet
+func preferRegions(candidates []routeCandidate, preferences []string) []routeCandidate {
+	for _, region := range preferences {
+		var matched []routeCandidate
+		for _, c := range candidates {
+			if c.Region == region {
+				matched = append(matched, c)
+			}
+		}
+		if len(matched) > 0 {
+			return matched
+		}
+	}
+	return candidates
+}
+
+func toAssociatedFleets(candidates []routeCandidate) []alias.AssociatedFleet {
+	fleets := make([]alias.AssociatedFleet, 0, len(candidates))
+	for _, c := range candidates {
+		fleets = append(fleets, alias.AssociatedFleet{FleetId: c.FleetId, Weight: c.Weight})
+	}
+	return fleets
+}
+
+// pickWeighted 按照权重随机选择fleet
+func pickWeighted(candidates []routeCandidate, _ *RouteRequest) (string, *errors.CodedError) {
+	return pickByWeight(toAssociatedFleets(candidates))
+}
+
+// pickStickyCreator 基于creator_id做加权最高随机权重哈希, 同一个creator在fleet权重不变时总是落在同一个fleet,
+// 灰度调整权重时只有需要迁移到新fleet的creator会改变路由结果; creator_id为空时按照权重随机选择
+func pickStickyCreator(candidates []routeCandidate, r *RouteRequest) (string, *errors.CodedError) {
+	if r.CreatorId == "" {
+		return pickWeighted(candidates, r)
+	}
+	picked := ""
+	best := math.Inf(-1)
+	for _, c := range candidates {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(r.CreatorId))
+		_, _ = h.Write([]byte{0})
+		_, _ = h.Write([]byte(c.FleetId))
+		// 将哈希值映射到(0,1)区间
+		u := (float64(mix64(h.Sum64())>>11) + 0.5) / float64(uint64(1)<<53)
+		score := -float64(c.Weight) / math.Log(u)
+		if score > best {
+			best = score
+			picked = c.FleetId
+		}
+	}
+	return picked, nil
+}
+
+// mix64 打散fnv哈希的高位, fnv对末尾字节的变化仅影响低位
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// listFleetCapacity 查询区域内fleet的进程及会话数, 没有ACTIVE进程的fleet不在返回结果中
+var listFleetCapacity = func(requestId string, region string, fleetIds []string) (
+	map[string]fleet.FleetResponseFromAPPGW, error) {
+	url := client.GetServiceEndpoint(client.ServiceNameAPPGW, region) + constants.APPGWMonitorFleetsUrl
+	req := client.NewRequest(client.ServiceNameAPPGW, url, http.MethodGet, nil)
+	req.SetQuery(params.QueryFleetId, strings.Join(fleetIds, ","))
+	req.SetHeader(map[string]string{
+		logger.RequestId: requestId,
+	})
+	code, rsp, err := req.DoRequest()
+	if err != nil {
+		return nil, err
+	}
+	if code != http.StatusOK {
+		return nil, fmt.Errorf("list fleets failed, code: %d, rsp: %s", code, rsp)
+	}
+
+	obj := &fleet.ListFleetsResponseFromAPPGW{}
+	if err := json.Unmarshal(rsp, obj); err != nil {
+		return nil, err
+	}
+	capacity := make(map[string]fleet.FleetResponseFromAPPGW, len(obj.Fleets))
+	for _, f := range obj.Fleets {
+		capacity[f.FleetID] = f
+	}
+	return capacity, nil
+}
+
+// pickCapacityAware 跳过没有可用进程的fleet后按照权重随机选择; 区域查询失败时保留该区域的fleet
+func pickCapacityAware(candidates []routeCandidate, r *RouteRequest) (string, *errors.CodedError) {
+	regionFleets := make(map[string][]string)
+	var regions []string
+	for _, c := range candidates {
+		if _, ok := regionFleets[c.Region]; !ok {
+			regions = append(regions, c.Region)
+		}
+		regionFleets[c.Region] = append(regionFleets[c.Region], c.FleetId)
+	}
+
+	available := make(map[string]bool, len(candidates))
+	for _, region := range regions {
+		capacity, err := listFleetCapacity(r.RequestId, region, regionFleets[region])
+		if err != nil {
+			r.Logger.Warn("list capacity of fleets %v in region %s error: %v", regionFleets[region], region, err)
+			for _, id := range regionFleets[region] {
+				available[id] = true
+			}
+			continue
+		}
+		for _, id := range regionFleets[region] {
+			f, ok := capacity[id]
+			available[id] = ok && f.ProcessCount > 0 && f.MaxServerSessionNum > f.ServerSessionCount
+		}
+	}
+
+	var filtered []routeCandidate
+	for _, c := range candidates {
+		if available[c.FleetId] {
+			filtered = append(filtered, c)
+		}
+	}
+	if len(filtered) == 0 {
+		return "", errors.NewErrorF(errors.AliasNoAvailableFleet, " no fleet has available processes")
+	}
+	return pickWeighted(filtered, r)
+}
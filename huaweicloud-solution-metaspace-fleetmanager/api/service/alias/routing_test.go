@@ -0,0 +1,170 @@
+package alias
+
+import (
+	"fleetmanager/api/errors"
+	"fleetmanager/api/model/fleet"
+	"fleetmanager/db/dao"
+	"fleetmanager/logger"
+	"fmt"
+	"testing"
+)
+
+func stubFleetRegions(regions map[string]string) func() {
+	origin := getFleetRegions
+	getFleetRegions = func(fleetIds []string) (map[string]string, error) {
+		return regions, nil
+	}
+	return func() { getFleetRegions = origin }
+}
+
+func TestRouteFleetStickyCreator(t *testing.T) {
+	defer stubFleetRegions(map[string]string{"old": "r1", "new": "r1"})()
+
+	canary := &dao.Alias{
+		RoutingStrategy:  dao.AliasRoutingStrategyStickyCreator,
+		AssociatedFleets: `[{"fleet_id":"old","weight":0.9},{"fleet_id":"new","weight":0.1}]`,
+	}
+	half := &dao.Alias{
+		RoutingStrategy:  dao.AliasRoutingStrategyStickyCreator,
+		AssociatedFleets: `[{"fleet_id":"old","weight":0.5},{"fleet_id":"new","weight":0.5}]`,
+	}
+
+	total := 10000
+	onNew := 0
+	for i := 0; i < total; i++ {
+		r := &RouteRequest{CreatorId: fmt.Sprintf("creator-%d", i)}
+		first, e := RouteFleet(canary, r)
+		if e != nil {
+			t.Fatalf("RouteFleet() error = %v", e)
+		}
+		for j := 0; j < 3; j++ {
+			if again, _ := RouteFleet(canary, r); again != first {
+				t.Fatalf("creator %s is routed to %s and %s", r.CreatorId, first, again)
+			}
+		}
+		if first != "new" {
+			continue
+		}
+		onNew++
+		// 灰度扩大时, 已经落在新fleet的creator不会被切回旧fleet
+		if next, _ := RouteFleet(half, r); next != "new" {
+			t.Errorf("creator %s moves back to %s after the new fleet weight grows", r.CreatorId, next)
+		}
+	}
+	if ratio := float64(onNew) / float64(total); ratio < 0.08 || ratio > 0.12 {
+		t.Errorf("ratio of creators on new fleet = %.3f, want about 0.1", ratio)
+	}
+}
+
+func TestRouteFleetCapacityAware(t *testing.T) {
+	defer stubFleetRegions(map[string]string{"full": "r1", "idle": "r1", "remote": "r2"})()
+	origin := listFleetCapacity
+	defer func() { listFleetCapacity = origin }()
+
+	a := &dao.Alias{
+		RoutingStrategy:  dao.AliasRoutingStrategyCapacityAware,
+		AssociatedFleets: `[{"fleet_id":"full","weight":0.8},{"fleet_id":"idle","weight":0.1},{"fleet_id":"remote","weight":0.1}]`,
+	}
+	tests := []struct {
+		name        string
+		capacity    map[string]map[string]fleet.FleetResponseFromAPPGW
+		failRegions map[string]bool
+		expected    map[string]bool
+		expectedErr errors.ErrCode
+	}{
+		{
+			name: "skip fleets without available processes",
+			capacity: map[string]map[string]fleet.FleetResponseFromAPPGW{
+				"r1": {
+					"full": {FleetID: "full", ProcessCount: 2, ServerSessionCount: 4, MaxServerSessionNum: 4},
+					"idle": {FleetID: "idle", ProcessCount: 1, ServerSessionCount: 0, MaxServerSessionNum: 2},
+				},
+			},
+			expected: map[string]bool{"idle": true},
+		},
+		{
+			name:        "keep fleets whose capacity is unknown",
+			capacity:    map[string]map[string]fleet.FleetResponseFromAPPGW{},
+			failRegions: map[string]bool{"r2": true},
+			expected:    map[string]bool{"remote": true},
+		},
+		{
+			name:        "no fleet has available processes",
+			capacity:    map[string]map[string]fleet.FleetResponseFromAPPGW{},
+			expectedErr: errors.AliasNoAvailableFleet,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listFleetCapacity = func(requestId string, region string, fleetIds []string) (
+				map[string]fleet.FleetResponseFromAPPGW, error) {
+				if tt.failRegions[region] {
+					return nil, fmt.Errorf("appgateway unavailable")
+				}
+				return tt.capacity[region], nil
+			}
+			for i := 0; i < 50; i++ {
+				fleetId, e := RouteFleet(a, &RouteRequest{Logger: logger.NewDebugLogger()})
+				if tt.expectedErr != "" {
+					if e == nil || e.Code() != tt.expectedErr {
+						t.Fatalf("RouteFleet() error = %v, want %s", e, tt.expectedErr)
+					}
+					return
+				}
+				if e != nil || !tt.expected[fleetId] {
+					t.Fatalf("RouteFleet() = %s, %v, want one of %v", fleetId, e, tt.expected)
+				}
+			}
+		})
+	}
+}
+
+func TestRouteFleetRegionPreference(t *testing.T) {
+	defer stubFleetRegions(map[string]string{"east": "r-east", "west": "r-west"})()
+
+	a := &dao.Alias{
+		AssociatedFleets: `[{"fleet_id":"east","weight":0.5},{"fleet_id":"west","weight":0.5},` +
+			`{"fleet_id":"deleted","weight":0.5},{"fleet_id":"drained","weight":0}]`,
+	}
+	tests := []struct {
+		name        string
+		preferences []string
+		expected    map[string]bool
+	}{
+		{
+			name:        "first preferred region with fleets wins",
+			preferences: []string{"r-north", "r-west", "r-east"},
+			expected:    map[string]bool{"west": true},
+		},
+		{
+			name:        "fall back to all fleets when no region matches",
+			preferences: []string{"r-north"},
+			expected:    map[string]bool{"east": true, "west": true},
+		},
+		{
+			name:     "no preference",
+			expected: map[string]bool{"east": true, "west": true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 50; i++ {
+				fleetId, e := RouteFleet(a, &RouteRequest{RegionPreferences: tt.preferences})
+				if e != nil || !tt.expected[fleetId] {
+					t.Fatalf("RouteFleet() = %s, %v, want one of %v", fleetId, e, tt.expected)
+				}
+			}
+		})
+	}
+}
+
+func TestRouteFleetUnknownStrategy(t *testing.T) {
+	defer stubFleetRegions(map[string]string{"f": "r1"})()
+
+	a := &dao.Alias{RoutingStrategy: "UNKNOWN", AssociatedFleets: `[{"fleet_id":"f","weight":1}]`}
+	if _, e := RouteFleet(a, &RouteRequest{}); e == nil || e.Code() != errors.RoutingStrategyNotFound {
+		t.Errorf("RouteFleet() error = %v, want %s", e, errors.RoutingStrategyNotFound)
+	}
+}
@@ -99,6 +99,9 @@ func (s *Service) updateToDb() *errors.CodedError {
 	if s.updateReq.Message != "" {
 		s.alias.Message = s.updateReq.Message
 	}
+	if s.updateReq.RoutingStrategy != "" {
+		s.alias.RoutingStrategy = s.updateReq.RoutingStrategy
+	}
 	if s.updateReq.AssociatedFleets != nil {
 		associatedFleetsByte, err := json.Marshal(s.updateReq.AssociatedFleets)
 		if err != nil {
@@ -107,7 +110,8 @@ func (s *Service) updateToDb() *errors.CodedError {
 		s.alias.AssociatedFleets = string(associatedFleetsByte)
 	}
 	s.alias.UpdateTime = time.Now().UTC()
-	if err := dao.GetAliasStorage().Update(s.alias, "Name", "Description", "UpdateTime", "AssociatedFleets", "Type", "Message"); err != nil {
+	if err := dao.GetAliasStorage().Update(s.alias, "Name", "Description", "UpdateTime", "AssociatedFleets", "Type", "Message",
+		"RoutingStrategy"); err != nil {
 		s.Logger.Error("update alias data to error aliasId:%s, err:%+v", s.alias.Id, err)
 		return errors.NewError(errors.DBError)
 	}
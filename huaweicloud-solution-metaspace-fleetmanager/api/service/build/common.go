@@ -24,6 +24,7 @@ func buildBuildModel(bd *dao.Build) build.Build {
 		Version:         bd.Version,
 		Size:            bd.Size,
 		OperatingSystem: bd.OperatingSystem,
+		Checksum:        bd.Checksum,
 	}
 
 	return b
@@ -60,6 +61,12 @@ func buildFullBuild(bd *dao.Build) build.FullBuild {
 		StorageBucketName: bd.StorageBucketName,
 		StorageKey:        bd.StorageKey,
 		StorageRegion:     bd.StorageRegion,
+		Checksum:          bd.Checksum,
+		SourceType:        bd.SourceType,
+		SourceEndpoint:    bd.SourceEndpoint,
+		SourceRegion:      bd.SourceRegion,
+		SourceUrl:         bd.SourceUrl,
+		SourcePath:        bd.SourcePath,
 	}
 	return b
 }
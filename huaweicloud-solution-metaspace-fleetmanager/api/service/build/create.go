@@ -51,14 +51,8 @@ func (s *Service) Create(ctx *context.Context, r build.CreateRequest) (*build.Bu
 		return nil, e
 	}
 
-	// check build bucket
-	e := s.CheckBucket(r.StorageLocation.BucketName, projectId, regionStr)
-	if e != nil {
-		return nil, e
-	}
-
-	fileSize, e := s.CheckSize(r.StorageLocation.BucketName, r.StorageLocation.BucketKey,
-		projectId, regionStr)
+	// check build source
+	fileSize, e := s.checkStorageLocation(r.StorageLocation, projectId, regionStr)
 	if e != nil {
 		return nil, e
 	}
@@ -67,10 +61,16 @@ func (s *Service) Create(ctx *context.Context, r build.CreateRequest) (*build.Bu
 	if err != nil {
 		return nil, errors.NewErrorF(errors.ServerInternalError, "insert build error")
 	}
-	bd := buildBuildModel(b)
-
 	s.Build = b
 
+	// 本地来源的应用包只在实例上可见, 不构建镜像, 只能用于fleet原地更新
+	if b.SourceType == constants.BuildSourceLocal {
+		e := s.updateStateReady()
+		bd := buildBuildModel(b)
+		return &bd, e
+	}
+	bd := buildBuildModel(b)
+
 	if e := s.startCreateBuildImageWorkflow(regionStr, r.OperatingSystem, vpcId, subnetId, enterpriseProjectId); e != nil {
 		if e := s.updateStateError(); e != nil {
 			s.Logger.Error("update build state to error failed: %v", e)
@@ -290,6 +290,15 @@ func (s *Service) updateStateError() *errors.CodedError {
 	return nil
 }
 
+// 无需构建镜像的应用包直接更新至可用
+func (s *Service) updateStateReady() *errors.CodedError {
+	s.Build.State = constants.BuildStateReady
+	if _, err := dbm.Ormer.Update(s.Build, "State"); err != nil {
+		return s.ErrorMsg(errors.BuildUpdateFailed, "update build state to ready failed", err)
+	}
+	return nil
+}
+
 func (s *Service) startCreateBuildImageWorkflow(regionStr string, imageRef string,
 	vpcId string, subnetId string, enterpriseProjectId string) (e *errors.CodedError) {
 
@@ -37,7 +37,7 @@ func (s *Service) Delete(ctx *context.Context) *errors.CodedError {
 	}
 
 	// if build created by IMS-image directly, just delete the record from db
-	if tmpBuild.StorageBucketName != "" && tmpBuild.StorageKey != "" {
+	if tmpBuild.SourceType != "" || (tmpBuild.StorageBucketName != "" && tmpBuild.StorageKey != "") {
 
 		// Delete build in IMS
 		err = s.DeleteBuildInIMS(buildId, projectId)
@@ -0,0 +1,54 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 应用包来源校验方法
+package build
+
+import (
+	"fleetmanager/api/errors"
+	"fleetmanager/api/model/build"
+	"fleetmanager/api/service/constants"
+	"fleetmanager/client"
+)
+
+// checkStorageLocation 校验各来源的必填参数并返回应用包大小, 无法提前获取大小的来源返回0
+// S3和HTTPS来源的地址由租户填写, NewBuildSource会拒绝解析到内部网络的地址
+func (s *Service) checkStorageLocation(loc build.StorageLocation, projectId string,
+	region string) (int64, *errors.CodedError) {
+	switch loc.SourceType {
+	case "", constants.BuildSourceOBS:
+		if loc.BucketName == "" || loc.BucketKey == "" {
+			return 0, errors.NewErrorF(errors.InvalidParameterValue,
+				" bucket_name and bucket_key are required for OBS build")
+		}
+		if e := s.CheckBucket(loc.BucketName, projectId, region); e != nil {
+			return 0, e
+		}
+		return s.CheckSize(loc.BucketName, loc.BucketKey, projectId, region)
+	case constants.BuildSourceS3:
+		if loc.Endpoint == "" || loc.BucketName == "" || loc.BucketKey == "" || loc.Checksum == "" {
+			return 0, errors.NewErrorF(errors.InvalidParameterValue,
+				" endpoint, bucket_name, bucket_key and checksum are required for S3 build")
+		}
+	case constants.BuildSourceHTTPS:
+		if loc.Url == "" || loc.Checksum == "" {
+			return 0, errors.NewErrorF(errors.InvalidParameterValue,
+				" url and checksum are required for HTTPS build")
+		}
+	case constants.BuildSourceLocal:
+		if loc.Path == "" {
+			return 0, errors.NewErrorF(errors.InvalidParameterValue, " path is required for LOCAL build")
+		}
+		return 0, nil
+	}
+
+	src, err := client.NewBuildSource(loc, nil)
+	if err != nil {
+		return 0, s.ErrorMsg(errors.InvalidParameterValue, "Create build source failed!", err)
+	}
+	defer src.Close()
+	size, err := src.Size()
+	if err != nil {
+		return 0, s.ErrorMsg(errors.InvalidParameterValue, "Check build size failed!", err)
+	}
+	return size, nil
+}
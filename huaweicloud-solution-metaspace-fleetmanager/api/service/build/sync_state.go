@@ -0,0 +1,32 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 应用包同步状态方法
+package build
+
+import (
+	"fleetmanager/api/errors"
+	"fleetmanager/api/model/build"
+	"fleetmanager/api/params"
+	"fleetmanager/db/dao"
+	"time"
+)
+
+// ReportState 记录auxproxy上报的实例应用包下载状态
+func (s *Service) ReportState(r *build.ReportStateRequest) *errors.CodedError {
+	st := &dao.BuildSyncState{
+		FleetId:         r.FleetId,
+		InstanceId:      r.InstanceId,
+		BuildId:         s.Ctx.Input.Param(params.BuildId),
+		Result:          r.Result,
+		State:           r.State,
+		DownloadedBytes: r.DownloadedBytes,
+		TotalBytes:      r.TotalBytes,
+		UpdateTime:      time.Now().UTC(),
+	}
+	if err := dao.GetBuildSyncStateStorage().Upsert(st); err != nil {
+		s.Logger.Error("save build %s sync state of instance %s error: %v", st.BuildId, st.InstanceId, err)
+		return errors.NewError(errors.DBError)
+	}
+
+	return nil
+}
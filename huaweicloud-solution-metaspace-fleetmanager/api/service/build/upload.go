@@ -4,6 +4,8 @@
 package build
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fleetmanager/api/errors"
 	"fleetmanager/api/model/build"
 	"fleetmanager/api/params"
@@ -47,6 +49,8 @@ func (s *Service) Upload(ctx *context.Context, file multipart.File, information
 	b.BucketName = bucketName
 	b.StorageRegion = region
 	b.BucketKey = fileName + tmpId + "." + fileExt
+	sum := sha256.Sum256(data)
+	b.Checksum = hex.EncodeToString(sum[:])
 
 	// check build bucket
 	if e := s.CheckBucket(bucketName, projectId, region); e != nil {
@@ -16,12 +16,27 @@ const (
 	BuildDeleted          = "DELETED"
 )
 
+// Build source
+const (
+	BuildSourceOBS   = "OBS"
+	BuildSourceS3    = "S3"
+	BuildSourceHTTPS = "HTTPS"
+	BuildSourceLocal = "LOCAL"
+)
+
 // Fleet
 const (
 	InstanceTypeVm   = "VM"
 	DefaultNameSpace = "Default"
 )
 
+// Placement
+const (
+	PlacementStrategyPack      = "PACK"
+	PlacementStrategySpread    = "SPREAD"
+	PlacementStrategyPreferred = "PREFERRED"
+)
+
 const (
 	UpdateScalingGroupUrlPattern   = "/v1/%s/instance-scaling-groups/%s"
 	CreateScalingGroupUrlPattern   = "/v1/%s/instance-scaling-groups"
@@ -30,6 +45,8 @@ const (
 	ShowScalingGroupUrlPattern     = "/v1/%s/instance-scaling-groups/%s"
 	CreateScalingPolicyUrl         = "/v1/%s/scaling-policies"
 	ScalingPolicyUrlPattern        = "/v1/%s/scaling-policies/%s"
+	ScalingForecastUrlPattern      = "/v1/%s/scaling-policies/%s/forecasts"
+	ScalingDecisionUrlPattern      = "/v1/%s/instance-scaling-groups/%s/scaling-decisions"
 	ScalingGroupLtsAccessConfig    = "/v1/%s/lts-access-config"
 	ScalingGroupListAccessConfig   = "/v1/%s/list-lts-access-config"
 	ScalingGroupLtsLogGroup        = "/v1/%s/lts-log-group"
@@ -38,6 +55,7 @@ const (
 	ScalingGroupListLogTransfer    = "/v1/%s/list-lts-transfer"
 	ServerSessionsUrl              = "/v1/server-sessions"
 	ServerSessionUrlPattern        = "/v1/server-sessions/%s"
+	ServerSessionCancelUrlPattern  = "/v1/server-sessions/%s/cancel"
 	ClientSessionsUrl              = "/v1/client-sessions"
 	BatchCreateClientSessionUrl    = "/v1/client-sessions/batch-create"
 	ClientSessionUrlPattern        = "/v1/client-sessions/%s"
@@ -52,6 +70,7 @@ const (
 	APPGWMonitorAppProcessesUrl    = "/v1/monitor-app-processes"
 	APPGWMonitorServerSessionsUrl  = "/v1/monitor-server-sessions"
 	AASSMonitorInstancesUrlPattern = "/v1/%s/monitor-instances"
+	InstanceBuildUpdateUrlPattern  = "/v1/instances/%s/build-update"
 )
 
 const (
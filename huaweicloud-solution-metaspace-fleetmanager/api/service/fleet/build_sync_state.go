@@ -0,0 +1,50 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// fleet实例应用包同步状态查询
+package fleet
+
+import (
+	"fleetmanager/api/errors"
+	"fleetmanager/api/model/fleet"
+	"fleetmanager/api/params"
+	"fleetmanager/api/service/constants"
+	"fleetmanager/db/dao"
+
+	"github.com/beego/beego/v2/client/orm"
+)
+
+// ListBuildSyncStates 查询fleet下各实例的应用包下载进度, 可以按build_id过滤
+func (s *Service) ListBuildSyncStates() (*fleet.ListBuildSyncStateRsp, *errors.CodedError) {
+	if err := s.setFleet(); err != nil {
+		if err == orm.ErrNoRows {
+			return nil, errors.NewError(errors.FleetNotFound)
+		}
+		s.logger.Error("get fleet info db error: %v", err)
+		return nil, errors.NewError(errors.DBError)
+	}
+
+	filter := dao.Filters{"FleetId": s.fleet.Id}
+	if buildId := s.ctx.Input.Query(params.QueryBuildId); buildId != "" {
+		filter["BuildId"] = buildId
+	}
+	states, err := dao.GetBuildSyncStateStorage().List(filter, 0, -1)
+	if err != nil {
+		s.logger.Error("list build sync states of fleet %s error: %v", s.fleet.Id, err)
+		return nil, errors.NewError(errors.DBError)
+	}
+
+	rsp := &fleet.ListBuildSyncStateRsp{Count: len(states), SyncStates: []fleet.BuildSyncState{}}
+	for _, st := range states {
+		rsp.SyncStates = append(rsp.SyncStates, fleet.BuildSyncState{
+			InstanceId:      st.InstanceId,
+			BuildId:         st.BuildId,
+			Result:          st.Result,
+			State:           st.State,
+			DownloadedBytes: st.DownloadedBytes,
+			TotalBytes:      st.TotalBytes,
+			UpdateTime:      st.UpdateTime.Format(constants.TimeFormatLayout),
+		})
+	}
+
+	return rsp, nil
+}
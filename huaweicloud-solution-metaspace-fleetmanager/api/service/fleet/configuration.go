@@ -49,6 +49,12 @@ func (s *ConfigService) buildFleetRuntimeConfiguration(fd *dao.RuntimeConfigurat
 		MaxConcurrentServerSessionsPerProcess: fd.MaxConcurrentServerSessionsPerProcess,
 		ProcessConfigurations:                 processConfiguration,
 	}
+	if fd.PlacementPolicy != "" {
+		f.PlacementPolicy = &fleet.PlacementPolicy{}
+		if err := utils.ToObject([]byte(fd.PlacementPolicy), f.PlacementPolicy); err != nil {
+			f.PlacementPolicy = nil
+		}
+	}
 
 	return f
 }
@@ -67,6 +73,7 @@ func (s *ConfigService) updateRuntimeConfigurationToAASS() (code int, rsp []byte
 				ServerSessionActivationTimeoutSeconds: s.updateReq.ServerSessionActivationTimeoutSeconds,
 				MaxConcurrentServerSessionsPerProcess: s.updateReq.MaxConcurrentServerSessionsPerProcess,
 				ProcessConfigurations:                 s.updateReq.ProcessConfigurations,
+				PlacementPolicy:                       s.updateReq.PlacementPolicy,
 			},
 		}
 	}
@@ -103,8 +110,12 @@ func (s *ConfigService) updateToDb(fleet *dao.Fleet) *errors.CodedError {
 		conf.MaxConcurrentServerSessionsPerProcess = *s.updateReq.MaxConcurrentServerSessionsPerProcess
 	}
 
+	if s.updateReq.PlacementPolicy != nil {
+		conf.PlacementPolicy = utils.ToJson(s.updateReq.PlacementPolicy)
+	}
+
 	if err = dao.GetRuntimeConfigurationStorage().Update(conf, "ServerSessionActivationTimeoutSeconds",
-		"MaxConcurrentServerSessionsPerProcess", "ProcessConfigurations"); err != nil {
+		"MaxConcurrentServerSessionsPerProcess", "ProcessConfigurations", "PlacementPolicy"); err != nil {
 		return errors.NewError(errors.DBError)
 	}
 
@@ -142,6 +153,9 @@ func (s *ConfigService) UpdateRuntimeConfiguration(r *fleet.UpdateRuntimeConfigu
 		return 0, nil,
 			errors.NewErrorF(errors.InvalidParameterValue, " fleet state is not active, cloud not update")
 	}
+	if e := checkPlacementPolicy(r.PlacementPolicy); e != nil {
+		return 0, nil, e
+	}
 
 	code, rsp, e = s.updateRuntimeConfigurationToAASS()
 	// 如果调用接口失败了
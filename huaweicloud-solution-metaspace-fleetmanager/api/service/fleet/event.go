@@ -9,9 +9,17 @@ import (
 	"fleetmanager/api/params"
 	"fleetmanager/db/dao"
 	"fleetmanager/logger"
+	"fmt"
+	"time"
+
+	"github.com/beego/beego/v2/client/orm"
 	"github.com/beego/beego/v2/server/web/context"
+	"github.com/google/uuid"
 )
 
+// 与fleet_event表message字段长度一致
+const maxEventMessageLength = 1600
+
 type EventService struct {
 	ctx    *context.Context
 	logger *logger.FMLogger
@@ -44,3 +52,45 @@ func (s *EventService) ListFleetEvents() (fleet.ListEventRsp, *errors.CodedError
 
 	return list, nil
 }
+
+// CreateFleetEvent 记录auxproxy上报的Fleet事件
+func (s *EventService) CreateFleetEvent(r *fleet.CreateEventRequest) (fleet.Event, *errors.CodedError) {
+	fleetId := s.ctx.Input.Param(params.FleetId)
+	if _, err := dao.GetFleetStorage().Get(dao.Filters{"Id": fleetId}); err != nil {
+		if err == orm.ErrNoRows {
+			return fleet.Event{}, errors.NewError(errors.FleetNotFound)
+		}
+		return fleet.Event{}, errors.NewError(errors.DBError)
+	}
+
+	u, _ := uuid.NewUUID()
+	event := &dao.FleetEvent{
+		Id:        u.String(),
+		FleetId:   fleetId,
+		EventCode: r.EventCode,
+		EventTime: time.Now().UTC(),
+		Message:   buildCrashLoopMessage(r),
+	}
+	if err := dao.GetFleetEventStorage().Insert(event); err != nil {
+		s.logger.Error("insert crash loop event of fleet %s error: %v", fleetId, err)
+		return fleet.Event{}, errors.NewError(errors.DBError)
+	}
+
+	return buildFleetEvent(event), nil
+}
+
+// buildCrashLoopMessage 生成crash loop事件描述, 超长时截掉stderr尾部之前的内容
+func buildCrashLoopMessage(r *fleet.CreateEventRequest) string {
+	header := fmt.Sprintf("process \"%s %s\" on instance %s is crash looping, %d failures, "+
+		"last exit code %d, stderr tail: ", r.LaunchPath, r.Parameters, r.InstanceId, r.FailureCount, r.ExitCode)
+	headerRunes := []rune(header)
+	if len(headerRunes) >= maxEventMessageLength {
+		return string(headerRunes[:maxEventMessageLength])
+	}
+
+	tail := []rune(r.StderrTail)
+	if budget := maxEventMessageLength - len(headerRunes); len(tail) > budget {
+		tail = tail[len(tail)-budget:]
+	}
+	return header + string(tail)
+}
@@ -123,6 +123,19 @@ func (s *Service) checkRuntimeConfiguration() *errors.CodedError {
 		return errors.NewError(errors.ProcessNumExceedMaxSize)
 	}
 
+	return checkPlacementPolicy(s.createRequest.RuntimeConfiguration.PlacementPolicy)
+}
+
+// checkPlacementPolicy PREFERRED策略至少需要指定一个优先的实例或可用区
+func checkPlacementPolicy(p *fleet.PlacementPolicy) *errors.CodedError {
+	if p == nil || p.Strategy != constants.PlacementStrategyPreferred {
+		return nil
+	}
+	if len(p.PreferredInstanceIds) == 0 && len(p.PreferredAvailabilityZones) == 0 {
+		return errors.NewErrorF(errors.InvalidParameterValue,
+			" preferred placement requires preferred instances or availability zones")
+	}
+
 	return nil
 }
 
@@ -169,6 +182,11 @@ func (s *Service) checkBuildState() *errors.CodedError {
 		s.logger.Error("build %v not available in create fleet", s.createRequest.BuildId)
 		return errors.NewError(errors.BuildIsNotAvailable)
 	}
+	// 本地来源的应用包没有镜像, 只能用于原地更新
+	if b.ImageId == "" {
+		s.logger.Error("build %v has no image, only supports build update of fleet", s.createRequest.BuildId)
+		return errors.NewError(errors.BuildIsNotAvailable)
+	}
 
 	s.build = b
 	return nil
@@ -241,6 +259,9 @@ func (s *Service) buildRuntimeConfiguration() {
 		MaxConcurrentServerSessionsPerProcess: s.createRequest.RuntimeConfiguration.
 			MaxConcurrentServerSessionsPerProcess,
 	}
+	if s.createRequest.RuntimeConfiguration.PlacementPolicy != nil {
+		s.runtimeConfiguration.PlacementPolicy = utils.ToJson(s.createRequest.RuntimeConfiguration.PlacementPolicy)
+	}
 }
 
 func (s *Service) updateStateError() *errors.CodedError {
@@ -0,0 +1,119 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// fleet应用包原地更新方法
+package fleet
+
+import (
+	"encoding/json"
+	"fleetmanager/api/errors"
+	"fleetmanager/api/model/fleet"
+	"fleetmanager/api/params"
+	"fleetmanager/api/service/constants"
+	"fleetmanager/api/validator"
+	"fleetmanager/db/dao"
+	"fleetmanager/logger"
+	"fleetmanager/workflow"
+	"fleetmanager/workflow/directer"
+	"fleetmanager/worknode"
+	"fmt"
+
+	"github.com/beego/beego/v2/client/orm"
+)
+
+const defaultBuildUpdateMaxUnavailable = 1
+
+var runningWorkflowStates = []string{dao.WorkflowStateCreate, dao.WorkflowStateRunning,
+	dao.WorkflowStateRollbacking}
+
+// UpdateBuild 启动应用包原地更新工作流, 按批次将fleet下的实例更新为新的应用包
+func (s *Service) UpdateBuild() (*fleet.UpdateBuildResponse, *errors.CodedError) {
+	if err := s.setFleet(); err != nil {
+		if err == orm.ErrNoRows {
+			return nil, errors.NewError(errors.FleetNotFound)
+		}
+		s.logger.Error("get fleet info db error: %v", err)
+		return nil, errors.NewError(errors.DBError)
+	}
+	req := &fleet.UpdateBuildRequest{}
+	if err := json.Unmarshal(s.ctx.Input.RequestBody, req); err != nil {
+		s.logger.Error("unmarshal request body %v error: %v", s.ctx.Input.RequestBody, err)
+		return nil, errors.NewErrorF(errors.InvalidParameterValue, " read request params error")
+	}
+	if err := validator.Validate(req); err != nil {
+		s.logger.Error("request params invalid, reqBody:%s, err:%+v", s.ctx.Input.RequestBody, err)
+		return nil, errors.NewErrorF(errors.InvalidParameterValue, err.Error())
+	}
+	if req.MaxUnavailable == 0 {
+		req.MaxUnavailable = defaultBuildUpdateMaxUnavailable
+	}
+	if e := s.checkUpdateBuild(req); e != nil {
+		return nil, e
+	}
+
+	update := &fleet.BuildUpdate{
+		InstanceBuildUpdateRequest: fleet.InstanceBuildUpdateRequest{
+			FleetId:                 s.fleet.Id,
+			BuildId:                 s.build.Id,
+			Bucket:                  s.build.StorageBucketName,
+			Object:                  s.build.StorageKey,
+			Region:                  s.build.StorageRegion,
+			Checksum:                s.build.Checksum,
+			SourceType:              s.build.SourceType,
+			Url:                     s.build.SourceUrl,
+			Path:                    s.build.SourcePath,
+			ReadinessTimeoutSeconds: req.ReadinessTimeoutSeconds,
+		},
+		MaxUnavailable: req.MaxUnavailable,
+	}
+	parameter := map[string]interface{}{
+		directer.WfKeyBuildUpdate: update,
+		directer.WfKeyRegion:      s.fleet.Region,
+		directer.WfKeyRequestId:   fmt.Sprintf("%s", s.ctx.Input.GetData(logger.RequestId)),
+	}
+	wf, err := workflow.CreateWorkflow(
+		"./conf/workflow/update_fleet_build_workflow.json",
+		parameter,
+		s.fleet.Id,
+		s.fleet.ProjectId,
+		s.logger,
+		worknode.WorkNodeId)
+	if err != nil {
+		s.logger.Error("create workflow in update fleet build error: %v", err)
+		return nil, errors.NewError(errors.ServerInternalError)
+	}
+	wf.Run()
+
+	return &fleet.UpdateBuildResponse{WorkflowId: wf.Id}, nil
+}
+
+// checkUpdateBuild 校验fleet与应用包的状态, 同一fleet同时只允许一个工作流
+func (s *Service) checkUpdateBuild(req *fleet.UpdateBuildRequest) *errors.CodedError {
+	if s.fleet.State != dao.FleetStateActive {
+		return errors.NewErrorF(errors.FleetNotActive, fmt.Sprintf("fleet_id: %s", s.fleet.Id))
+	}
+
+	b, err := dao.GetBuildById(req.BuildId, s.ctx.Input.Param(params.ProjectId))
+	if err != nil {
+		s.logger.Error("build %v in update fleet build error: %v", req.BuildId, err)
+		if err == orm.ErrNoRows {
+			return errors.NewError(errors.BuildNotExists)
+		}
+		return errors.NewError(errors.DBError)
+	}
+	if b.State != constants.BuildStateReady {
+		s.logger.Error("build %v not available in update fleet build", req.BuildId)
+		return errors.NewError(errors.BuildIsNotAvailable)
+	}
+	s.build = b
+
+	n, err := dao.CountWorkflows(dao.Filters{"ResourceId": s.fleet.Id, "State__in": runningWorkflowStates})
+	if err != nil {
+		s.logger.Error("count workflows of fleet %s error: %v", s.fleet.Id, err)
+		return errors.NewError(errors.DBError)
+	}
+	if n > 0 {
+		return errors.NewError(errors.FleetWorkflowInProgress)
+	}
+
+	return nil
+}
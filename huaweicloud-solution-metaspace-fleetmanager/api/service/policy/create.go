@@ -23,9 +23,24 @@ func (s *Service) allowCreate() *errors.CodedError {
 		return errors.NewError(errors.DBError)
 	}
 
+	// 步进策略与其他按指标伸缩的策略同时存在时伸缩决策会互相冲突, 基于目标策略由AASS共同判断, 但指标不能重复
+	isMetricBased := func(policyType string) bool {
+		return policyType == dao.TargetBasedPolicy || policyType == dao.StepPolicy
+	}
 	for _, p := range policies {
-		if p.PolicyType == dao.TargetBasedPolicy && s.createReq.PolicyType == dao.TargetBasedPolicy {
-			s.Logger.Error("one fleet can have only one target based policy")
+		if isMetricBased(p.PolicyType) && isMetricBased(s.createReq.PolicyType) &&
+			(p.PolicyType == dao.StepPolicy || s.createReq.PolicyType == dao.StepPolicy) {
+			s.Logger.Error("step policy can not coexist with other target based or step policy in one fleet")
+			return errors.NewError(errors.DuplicatePolicy)
+		}
+		if p.PolicyType == dao.TargetBasedPolicy && s.createReq.PolicyType == dao.TargetBasedPolicy &&
+			s.isSameTargetMetric(&p) {
+			s.Logger.Error("one fleet can have only one target based policy of metric %s",
+				s.createReq.TargetBasedConfiguration.MetricName)
+			return errors.NewError(errors.DuplicatePolicy)
+		}
+		if p.PolicyType == dao.PredictivePolicy && s.createReq.PolicyType == dao.PredictivePolicy {
+			s.Logger.Error("one fleet can have only one predictive policy")
 			return errors.NewError(errors.DuplicatePolicy)
 		}
 	}
@@ -33,6 +48,17 @@ func (s *Service) allowCreate() *errors.CodedError {
 	return nil
 }
 
+// isSameTargetMetric 自定义指标按自定义指标名称区分
+func (s *Service) isSameTargetMetric(p *dao.ScalingPolicy) bool {
+	var conf policy.TargetBasedConfiguration
+	if err := json.Unmarshal([]byte(p.TargetBasedConfiguration), &conf); err != nil {
+		s.Logger.Error("unmarshal target based configuration of policy %s error: %v", p.Id, err)
+		return false
+	}
+	req := s.createReq.TargetBasedConfiguration
+	return req != nil && conf.MetricName == req.MetricName && conf.CustomMetricName == req.CustomMetricName
+}
+
 func (s *Service) makeCreateReq() (*policy.CreateRequestToAASS, string, error) {
 	group, err := dao.GetScalingGroupStorage().GetOne(dao.Filters{"FleetId": s.Fleet.Id})
 	if err != nil {
@@ -72,14 +98,26 @@ func (s *Service) forwardCreateToAASS() (code int, rsp []byte, err error) {
 func (s *Service) insertDb(rsp *policy.CreateResponseFromAASS, resProjectId string) (*dao.ScalingPolicy,
 	*errors.CodedError) {
 	p := &dao.ScalingPolicy{
-		Id:                       rsp.ScalingPolicyId,
-		Name:                     s.createReq.Name,
-		FleetId:                  s.Fleet.Id,
-		PolicyType:               s.createReq.PolicyType,
-		State:                    dao.PolicyStateActive,
-		ScalingTarget:            s.createReq.ScalingTarget,
-		TargetBasedConfiguration: utils.ToJson(s.createReq.TargetBasedConfiguration),
-		ResourceProjectId:        resProjectId,
+		Id:                rsp.ScalingPolicyId,
+		Name:              s.createReq.Name,
+		FleetId:           s.Fleet.Id,
+		PolicyType:        s.createReq.PolicyType,
+		State:             dao.PolicyStateActive,
+		ScalingTarget:     s.createReq.ScalingTarget,
+		ResourceProjectId: resProjectId,
+		DryRun:            s.createReq.DryRun,
+	}
+	if s.createReq.TargetBasedConfiguration != nil {
+		p.TargetBasedConfiguration = utils.ToJson(s.createReq.TargetBasedConfiguration)
+	}
+	if s.createReq.ScheduledConfiguration != nil {
+		p.ScheduledConfiguration = utils.ToJson(s.createReq.ScheduledConfiguration)
+	}
+	if s.createReq.StepConfiguration != nil {
+		p.StepConfiguration = utils.ToJson(s.createReq.StepConfiguration)
+	}
+	if s.createReq.PredictiveConfiguration != nil {
+		p.PredictiveConfiguration = utils.ToJson(s.createReq.PredictiveConfiguration)
 	}
 
 	if err := dao.GetScalingPolicyStorage().Insert(p); err != nil {
@@ -136,6 +174,10 @@ func (s *Service) Create(r *policy.CreateRequest) (code int, rsp []byte, e *erro
 		ScalingTarget:            policyDao.ScalingTarget,
 		State:                    policyDao.State,
 		TargetBasedConfiguration: s.createReq.TargetBasedConfiguration,
+		ScheduledConfiguration:   s.createReq.ScheduledConfiguration,
+		StepConfiguration:        s.createReq.StepConfiguration,
+		PredictiveConfiguration:  s.createReq.PredictiveConfiguration,
+		DryRun:                   policyDao.DryRun,
 	}
 	rsp, err = json.Marshal(newRsp)
 	if err != nil {
@@ -7,8 +7,13 @@ import (
 	"encoding/json"
 	"fleetmanager/api/errors"
 	"fleetmanager/api/model/policy"
+	"fleetmanager/api/params"
+	"fleetmanager/api/service/base"
+	"fleetmanager/api/service/constants"
 	"fleetmanager/db/dao"
+	"fmt"
 	"net/http"
+	"strconv"
 )
 
 func (s *Service) transPolicy(pd dao.ScalingPolicy) policy.ScalingPolicy {
@@ -19,8 +24,23 @@ func (s *Service) transPolicy(pd dao.ScalingPolicy) policy.ScalingPolicy {
 		PolicyType:    pd.PolicyType,
 		ScalingTarget: pd.ScalingTarget,
 		State:         pd.State,
+		DryRun:        pd.DryRun,
+	}
+	var err error
+	switch pd.PolicyType {
+	case dao.ScheduledPolicy:
+		p.ScheduledConfiguration = &policy.ScheduledConfiguration{}
+		err = json.Unmarshal([]byte(pd.ScheduledConfiguration), p.ScheduledConfiguration)
+	case dao.StepPolicy:
+		p.StepConfiguration = &policy.StepConfiguration{}
+		err = json.Unmarshal([]byte(pd.StepConfiguration), p.StepConfiguration)
+	case dao.PredictivePolicy:
+		p.PredictiveConfiguration = &policy.PredictiveConfiguration{}
+		err = json.Unmarshal([]byte(pd.PredictiveConfiguration), p.PredictiveConfiguration)
+	default:
+		p.TargetBasedConfiguration = &policy.TargetBasedConfiguration{}
+		err = json.Unmarshal([]byte(pd.TargetBasedConfiguration), p.TargetBasedConfiguration)
 	}
-	err := json.Unmarshal([]byte(pd.TargetBasedConfiguration), &p.TargetBasedConfiguration)
 	if err != nil {
 		s.Logger.Warn("trans policy error: %v", err)
 	}
@@ -86,3 +106,43 @@ func (s *Service) List(offset int, limit int) (code int, rsp []byte, e *errors.C
 	}
 	return http.StatusOK, rsp, nil
 }
+
+// Forecast 查询预测策略的需求预测以及对应时间段的实际需求, 查询时间透传到aass
+func (s *Service) Forecast() (code int, rsp []byte, e *errors.CodedError) {
+	if err := s.SetFleet(); err != nil {
+		return 0, nil, err
+	}
+	if err := s.SetPolicyById(s.Ctx.Input.Param(params.PolicyId)); err != nil {
+		return 0, nil, err
+	}
+	if s.scalingPolicy.FleetId != s.Fleet.Id {
+		s.Logger.Error("policy %s does not belong to fleet %s", s.scalingPolicy.Id, s.Fleet.Id)
+		return 0, nil, errors.NewError(errors.PolicyNotFound)
+	}
+
+	queryParams := base.GetQueryParams(s.Ctx, []string{params.StartTime, params.EndTime})
+	code, rsp, err := base.ForwardToAASS(s.Ctx, s.Fleet.Region, fmt.Sprintf(constants.ScalingForecastUrlPattern,
+		s.scalingPolicy.ResourceProjectId, s.scalingPolicy.Id), queryParams)
+	s.Logger.Info("query forecast from aass, code: %d, error: %+v", code, err)
+	return s.ForwardRspCheck(code, rsp, err)
+}
+
+// Decisions 分页查询fleet弹性伸缩组的伸缩决定记录, 查询时间透传到aass
+func (s *Service) Decisions(offset int, limit int) (code int, rsp []byte, e *errors.CodedError) {
+	if err := s.SetFleet(); err != nil {
+		return 0, nil, err
+	}
+	group, err := dao.GetScalingGroupStorage().GetOne(dao.Filters{"FleetId": s.Fleet.Id})
+	if err != nil {
+		s.Logger.Error("get scaling group of fleet %s db error: %v", s.Fleet.Id, err)
+		return 0, nil, errors.NewError(errors.DBError)
+	}
+
+	queryParams := base.GetQueryParams(s.Ctx, []string{params.StartTime, params.EndTime})
+	queryParams[params.QueryOffset] = strconv.Itoa(offset * limit)
+	queryParams[params.QueryLimit] = strconv.Itoa(limit)
+	code, rsp, err = base.ForwardToAASS(s.Ctx, s.Fleet.Region, fmt.Sprintf(constants.ScalingDecisionUrlPattern,
+		group.ResourceProjectId, group.Id), queryParams)
+	s.Logger.Info("query scaling decisions from aass, code: %d, error: %+v", code, err)
+	return s.ForwardRspCheck(code, rsp, err)
+}
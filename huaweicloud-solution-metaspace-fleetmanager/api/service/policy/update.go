@@ -36,13 +36,26 @@ func (s *Service) updateToDb(p *dao.ScalingPolicy) *errors.CodedError {
 	if s.updateReq.TargetBasedConfiguration != nil {
 		p.TargetBasedConfiguration = utils.ToJson(*s.updateReq.TargetBasedConfiguration)
 	}
+	if s.updateReq.ScheduledConfiguration != nil {
+		p.ScheduledConfiguration = utils.ToJson(*s.updateReq.ScheduledConfiguration)
+	}
+	if s.updateReq.StepConfiguration != nil {
+		p.StepConfiguration = utils.ToJson(*s.updateReq.StepConfiguration)
+	}
+	if s.updateReq.PredictiveConfiguration != nil {
+		p.PredictiveConfiguration = utils.ToJson(*s.updateReq.PredictiveConfiguration)
+	}
 
 	if s.updateReq.Name != nil {
 		p.Name = *s.updateReq.Name
 	}
+	if s.updateReq.DryRun != nil {
+		p.DryRun = *s.updateReq.DryRun
+	}
 
 	s.Logger.Info("update policy db p:%+v", p)
-	err := dao.GetScalingPolicyStorage().Update(p, "Name", "TargetBasedConfiguration")
+	err := dao.GetScalingPolicyStorage().Update(p, "Name", "TargetBasedConfiguration", "ScheduledConfiguration",
+		"StepConfiguration", "PredictiveConfiguration", "DryRun")
 	if err != nil {
 		s.Logger.Error("update scaling policy in db error: %v", err)
 		return errors.NewError(errors.DBError)
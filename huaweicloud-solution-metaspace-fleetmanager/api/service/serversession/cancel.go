@@ -0,0 +1,38 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 服务端会话取消服务
+package serversession
+
+import (
+	"fleetmanager/api/errors"
+	"fleetmanager/api/params"
+	"fleetmanager/api/service/constants"
+	"fleetmanager/client"
+	"fleetmanager/logger"
+	"fmt"
+	"net/http"
+)
+
+func (s *Service) forwardCancelToAPPGW(region string) (code int, rsp []byte, err error) {
+	sessionId := s.Ctx.Input.Param(params.ServerSessionId)
+	url := client.GetServiceEndpoint(client.ServiceNameAPPGW, region) +
+		fmt.Sprintf(constants.ServerSessionCancelUrlPattern, sessionId)
+	req := client.NewRequest(client.ServiceNameAPPGW, url, http.MethodPost, nil)
+	req.SetHeader(map[string]string{
+		logger.RequestId: fmt.Sprintf("%s", s.Ctx.Input.GetData(logger.RequestId)),
+	})
+	return req.DoRequest()
+}
+
+// Cancel 取消排队中或尚未分配到进程的服务端会话
+func (s *Service) Cancel() (code int, rsp []byte, e *errors.CodedError) {
+	if err := s.SetFleetByServerSessionId(s.Ctx.Input.Param(params.ServerSessionId)); err != nil {
+		s.Logger.Error("get fleet in cancel server session error, serverSessionId:%s",
+			s.Ctx.Input.Param(params.ServerSessionId))
+		return 0, nil, err
+	}
+
+	code, rsp, newErr := s.forwardCancelToAPPGW(s.Fleet.Region)
+	s.Logger.Info("forward cancel server session to app gateway, code:%d, rsp:%s, err:%v", code, rsp, newErr)
+	return s.ForwardRspCheck(code, rsp, newErr)
+}
@@ -29,6 +29,8 @@ func (s *Service) forwardCreateToAPPGW(region string) (code int, rsp []byte, err
 		IdempotencyToken:        s.createReq.IdempotencyToken,
 		ServerSessionData:       s.createReq.ServerSessionData,
 		ServerSessionProperties: s.createReq.ServerSessionProperties,
+		LabelSelector:           s.createReq.LabelSelector,
+		PlacementTimeoutSeconds: s.createReq.PlacementTimeoutSeconds,
 	}
 	body, err := json.Marshal(createReq)
 	if err != nil {
@@ -123,7 +125,12 @@ func (s *Service) createByAliasId(r *serversession.CreateRequest) (e *errors.Cod
 			" message: %s", s.alias.Message))
 	}
 
-	fleetId, err := AliasService.GenerateFleetByAssociateFleetWeight(s.alias.AssociatedFleets)
+	fleetId, err := AliasService.RouteFleet(s.alias, &AliasService.RouteRequest{
+		CreatorId:         r.CreatorId,
+		RegionPreferences: r.RegionPreferences,
+		RequestId:         fmt.Sprintf("%s", s.Ctx.Input.GetData(logger.RequestId)),
+		Logger:            s.Logger,
+	})
 	if err != nil {
 		return err
 	}
@@ -146,5 +146,7 @@ func generateServerSessions(serverSessionAppGW *serversession.ServerSessionFromA
 		ClientSessionCreationPolicy:             serverSessionAppGW.ClientSessionCreationPolicy,
 		ServerSessionProtectionPolicy:           serverSessionAppGW.ServerSessionProtectionPolicy,
 		ServerSessionProtectionTimeLimitMinutes: serverSessionAppGW.ServerSessionProtectionTimeLimitMinutes,
+		LabelSelector:                           serverSessionAppGW.LabelSelector,
+		PlacementTimeoutSeconds:                 serverSessionAppGW.PlacementTimeoutSeconds,
 	}
 }
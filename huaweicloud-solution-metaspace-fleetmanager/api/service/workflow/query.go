@@ -0,0 +1,67 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// workflow查询方法
+package workflow
+
+import (
+	"fleetmanager/api/errors"
+	"fleetmanager/api/model/workflow"
+	"fleetmanager/api/params"
+	"fleetmanager/db/dao"
+)
+
+// List 查询工作流列表
+func (s *Service) List(offset int, limit int) (workflow.List, *errors.CodedError) {
+	var list workflow.List
+	filter := s.workflowFilter()
+	total, err := dao.CountWorkflows(filter)
+	if err != nil {
+		s.logger.Error("count workflows from db error: %v", err)
+		return list, errors.NewError(errors.DBError)
+	}
+	list.TotalCount = int(total)
+	list.Workflows = []workflow.Workflow{}
+	if total == 0 {
+		return list, nil
+	}
+
+	wfs, err := dao.ListWorkflows(filter, offset*limit, limit)
+	if err != nil {
+		s.logger.Error("list workflows from db error: %v", err)
+		return list, errors.NewError(errors.DBError)
+	}
+	for i := range wfs {
+		m, progress, err := parseWorkflow(&wfs[i])
+		if err != nil {
+			s.logger.Error("parse workflow %s error: %v", wfs[i].Id, err)
+			return list, errors.NewError(errors.ServerInternalError)
+		}
+		list.Workflows = append(list.Workflows, buildWorkflowModel(&wfs[i], m, progress))
+	}
+	list.Count = len(list.Workflows)
+
+	return list, nil
+}
+
+// Show 查询工作流详情
+func (s *Service) Show() (*workflow.ShowWorkflowResponse, *errors.CodedError) {
+	if e := s.setWorkflow(); e != nil {
+		return nil, e
+	}
+
+	return &workflow.ShowWorkflowResponse{
+		Workflow: buildWorkflowModel(s.workflow, s.meta, s.progress),
+	}, nil
+}
+
+// workflowFilter 过滤条件查询
+func (s *Service) workflowFilter() dao.Filters {
+	filter := dao.Filters{"ProjectId": s.ctx.Input.Param(params.ProjectId)}
+	if resourceId := s.ctx.Input.Query(params.QueryResourceId); resourceId != "" {
+		filter["ResourceId"] = resourceId
+	}
+	if state := s.ctx.Input.Query(params.QueryState); state != "" {
+		filter["State"] = state
+	}
+	return filter
+}
@@ -0,0 +1,123 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// workflow运维操作方法
+package workflow
+
+import (
+	"encoding/json"
+	"fleetmanager/api/errors"
+	"fleetmanager/api/model/workflow"
+	"fleetmanager/api/validator"
+	"fleetmanager/db/dao"
+	wf "fleetmanager/workflow"
+	"fleetmanager/worknode"
+)
+
+var (
+	runningStates  = []string{dao.WorkflowStateCreate, dao.WorkflowStateRunning, dao.WorkflowStateRollbacking}
+	resumingStates = []string{dao.WorkflowStateError, dao.WorkflowStateRollbacked}
+)
+
+// Retry 从失败的任务处恢复执行已终止的工作流
+func (s *Service) Retry() *errors.CodedError {
+	if e := s.setWorkflow(); e != nil {
+		return e
+	}
+	if !inStates(s.workflow.State, resumingStates) {
+		return errors.NewError(errors.WorkflowStateNotSupportAction)
+	}
+
+	return s.resume("")
+}
+
+// Skip 跳过允许忽略失败的任务: 运行中的工作流由执行节点异步跳过还未执行的任务,
+// 已终止的工作流跳过失败或未执行的任务后恢复执行
+func (s *Service) Skip() *errors.CodedError {
+	req := &workflow.SkipTaskRequest{}
+	if err := json.Unmarshal(s.ctx.Input.RequestBody, req); err != nil {
+		s.logger.Error("unmarshal request body %v error: %v", s.ctx.Input.RequestBody, err)
+		return errors.NewErrorF(errors.InvalidParameterValue, " read request params error")
+	}
+	if err := validator.Validate(req); err != nil {
+		s.logger.Error("request params invalid, reqBody:%s, err:%+v", s.ctx.Input.RequestBody, err)
+		return errors.NewErrorF(errors.InvalidParameterValue, err.Error())
+	}
+
+	if e := s.setWorkflow(); e != nil {
+		return e
+	}
+	step := 0
+	for i, tm := range s.meta.Tasks {
+		if tm.Name == req.TaskName {
+			step = i + 1
+			break
+		}
+	}
+	if step == 0 {
+		return errors.NewError(errors.WorkflowTaskNotFound)
+	}
+	tm := s.meta.Tasks[step-1]
+	if !tm.ExecuteFailure.Ignore {
+		return errors.NewError(errors.WorkflowTaskNotSupportSkip)
+	}
+
+	if inStates(s.workflow.State, resumingStates) {
+		if !s.taskProgress(step).Skippable() {
+			return errors.NewError(errors.WorkflowTaskNotSupportSkip)
+		}
+		return s.resume(req.TaskName)
+	}
+
+	if !inStates(s.workflow.State, runningStates) {
+		return errors.NewError(errors.WorkflowStateNotSupportAction)
+	}
+	// 运行中的工作流只能跳过还未执行的任务
+	if st := s.taskState(step); st != "" && st != wf.TaskStatePending {
+		return errors.NewError(errors.WorkflowTaskNotSupportSkip)
+	}
+
+	return s.setAction(dao.WorkflowActionSkip, req.TaskName, runningStates)
+}
+
+// Cancel 取消运行中的工作流, 已执行的任务将按照依赖关系逆序回滚
+func (s *Service) Cancel() *errors.CodedError {
+	if e := s.setWorkflow(); e != nil {
+		return e
+	}
+
+	return s.setAction(dao.WorkflowActionCancel, "",
+		[]string{dao.WorkflowStateCreate, dao.WorkflowStateRunning})
+}
+
+func (s *Service) setAction(action string, task string, states []string) *errors.CodedError {
+	updated, err := dao.SetWorkflowAction(s.workflow.Id, states, action, task)
+	if err != nil {
+		s.logger.Error("set workflow %s action %s db error: %v", s.workflow.Id, action, err)
+		return errors.NewError(errors.DBError)
+	}
+	if updated != 1 {
+		return errors.NewError(errors.WorkflowStateNotSupportAction)
+	}
+
+	s.logger.Info("set workflow %s action %s, task %s", s.workflow.Id, action, task)
+	return nil
+}
+
+func (s *Service) resume(skipTask string) *errors.CodedError {
+	if err := wf.ResumeWorkflow(s.workflow.Id, skipTask, worknode.WorkNodeId); err != nil {
+		s.logger.Error("resume workflow %s error: %v", s.workflow.Id, err)
+		return errors.NewErrorF(errors.WorkflowStateNotSupportAction, err.Error())
+	}
+
+	s.logger.Info("resume workflow %s, skip task %s", s.workflow.Id, skipTask)
+	return nil
+}
+
+func inStates(state string, states []string) bool {
+	for _, st := range states {
+		if st == state {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// workflow服务方法
+package workflow
+
+import (
+	"encoding/json"
+	"fleetmanager/api/errors"
+	"fleetmanager/api/model/workflow"
+	"fleetmanager/api/params"
+	"fleetmanager/api/service/constants"
+	"fleetmanager/db/dao"
+	"fleetmanager/logger"
+	wf "fleetmanager/workflow"
+	"fleetmanager/workflow/meta"
+
+	"github.com/beego/beego/v2/client/orm"
+	"github.com/beego/beego/v2/server/web/context"
+)
+
+type Service struct {
+	ctx      *context.Context
+	logger   *logger.FMLogger
+	workflow *dao.Workflow
+	meta     meta.WorkflowMeta
+	progress []wf.TaskProgress
+}
+
+// NewWorkflowService 新建工作流管理服务
+func NewWorkflowService(ctx *context.Context, logger *logger.FMLogger) *Service {
+	s := &Service{
+		ctx:    ctx,
+		logger: logger,
+	}
+	return s
+}
+
+// setWorkflow 查询并解析当前请求的工作流
+func (s *Service) setWorkflow() *errors.CodedError {
+	filter := dao.Filters{
+		"Id":        s.ctx.Input.Param(params.WorkflowId),
+		"ProjectId": s.ctx.Input.Param(params.ProjectId),
+	}
+	w, err := dao.GetWorkflow(filter)
+	if err != nil {
+		if err == orm.ErrNoRows {
+			return errors.NewError(errors.WorkflowNotFound)
+		}
+		s.logger.Error("get workflow db error: %v", err)
+		return errors.NewError(errors.DBError)
+	}
+
+	m, progress, err := parseWorkflow(w)
+	if err != nil {
+		s.logger.Error("parse workflow %s error: %v", w.Id, err)
+		return errors.NewError(errors.ServerInternalError)
+	}
+	s.workflow = w
+	s.meta = m
+	s.progress = progress
+	return nil
+}
+
+func parseWorkflow(w *dao.Workflow) (meta.WorkflowMeta, []wf.TaskProgress, error) {
+	m := meta.WorkflowMeta{}
+	if err := json.Unmarshal([]byte(w.Meta), &m); err != nil {
+		return m, nil, err
+	}
+	progress, err := wf.ParseTaskProgress(w.TaskStates)
+	if err != nil {
+		return m, nil, err
+	}
+	return m, progress, nil
+}
+
+// taskState 获取任务当前记录的状态
+func (s *Service) taskState(step int) string {
+	return s.taskProgress(step).State
+}
+
+// taskProgress 获取任务当前记录的进度, 没有记录时返回空进度
+func (s *Service) taskProgress(step int) wf.TaskProgress {
+	for _, p := range s.progress {
+		if p.Step == step {
+			return p
+		}
+	}
+	return wf.TaskProgress{Step: step}
+}
+
+func buildWorkflowModel(w *dao.Workflow, m meta.WorkflowMeta, progress []wf.TaskProgress) workflow.Workflow {
+	rsp := workflow.Workflow{
+		WorkflowId:   w.Id,
+		Name:         m.Name,
+		ResourceId:   w.ResourceId,
+		State:        w.State,
+		WorkNodeId:   w.WorkNodeId,
+		TotalTasks:   len(m.Tasks),
+		CreationTime: w.CreationTime.Format(constants.TimeFormatLayout),
+		UpdateTime:   w.UpdateTime.Format(constants.TimeFormatLayout),
+		Tasks:        []workflow.Task{},
+	}
+
+	steps := make(map[int]wf.TaskProgress, len(progress))
+	for _, p := range progress {
+		steps[p.Step] = p
+	}
+	for i, tm := range m.Tasks {
+		task := workflow.Task{
+			Step:        i + 1,
+			Name:        tm.Name,
+			Description: tm.Description,
+			TaskType:    tm.TaskType,
+			DependsOn:   tm.DependsOn,
+			Ignorable:   tm.ExecuteFailure.Ignore,
+		}
+		if p, ok := steps[i+1]; ok {
+			task.State = p.State
+			task.RetryTimes = p.RetryTimes
+			task.RollbackRetryTimes = p.RollbackRetryTimes
+			task.LastError = p.LastError
+			task.Failed = p.Failed
+			task.Skipped = p.Skipped
+			if !p.UpdateTime.IsZero() {
+				task.UpdateTime = p.UpdateTime.Format(constants.TimeFormatLayout)
+			}
+		}
+		if task.DependsOn == nil {
+			task.DependsOn = []string{}
+		}
+		if task.State == wf.TaskStateCompleted {
+			rsp.CompletedTasks++
+		}
+		rsp.Tasks = append(rsp.Tasks, task)
+	}
+
+	return rsp
+}
@@ -17,6 +17,18 @@ import (
 	zt "github.com/go-playground/validator/v10/translations/zh"
 )
 
+const (
+	maxPercentTargetValue = 100
+	// percentAvailableProcessesMetric 可用进程比为100%时无法计算实例数, 目标值必须小于100
+	percentAvailableProcessesMetric = "PERCENT_AVAILABLE_PROCESSES"
+)
+
+// maxTargetValueFreeMetrics 目标值为进程指标平均值或排队数的指标, 不受百分比上限限制
+var maxTargetValueFreeMetrics = map[string]bool{
+	"CUSTOM_METRIC":          true,
+	"QUEUED_SERVER_SESSIONS": true,
+}
+
 var (
 	uni      *ut.UniversalTranslator
 	trans    ut.Translator
@@ -59,6 +71,12 @@ func Init() error {
 	if err := validate.RegisterValidation("checkPrefix", checkPrefix); err != nil {
 		return err
 	}
+	if err := validate.RegisterValidation("customMetricName", checkCustomMetricName); err != nil {
+		return err
+	}
+	if err := validate.RegisterValidation("targetValueOfMetric", checkTargetValueOfMetric); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -167,6 +185,28 @@ func checkPrefix(f validator.FieldLevel) bool {
 	return flag
 }
 
+// checkCustomMetricName 自定义指标名称会作为influxdb字段名
+func checkCustomMetricName(f validator.FieldLevel) bool {
+	name := f.Field().String()
+	flag, err := regexp.MatchString(`^[A-Za-z][A-Za-z0-9_]{0,63}$`, name)
+	if err != nil {
+		return false
+	}
+	return flag
+}
+
+// checkTargetValueOfMetric 百分比指标的目标值不能超过100, 自定义指标和排队数不限制上限
+func checkTargetValueOfMetric(f validator.FieldLevel) bool {
+	metricName := f.Parent().FieldByName("MetricName")
+	if metricName.IsValid() && maxTargetValueFreeMetrics[metricName.String()] {
+		return true
+	}
+	if metricName.IsValid() && metricName.String() == percentAvailableProcessesMetric {
+		return f.Field().Int() < maxPercentTargetValue
+	}
+	return f.Field().Int() <= maxPercentTargetValue
+}
+
 func checkLaunchParameters(f validator.FieldLevel) bool {
 	path := f.Field().String()
 	if path == "" {
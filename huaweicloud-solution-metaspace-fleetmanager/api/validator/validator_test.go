@@ -63,3 +63,33 @@ func TestValidateWithValidationError(t *testing.T) {
 
 	t.Errorf("expected invalid validate on validate(%v), got pass", mo)
 }
+
+type MockTargetConfiguration struct {
+	MetricName       string `json:"metric_name"`
+	CustomMetricName string `json:"custom_metric_name" validate:"required_if=MetricName CUSTOM_METRIC,omitempty,customMetricName"`
+	TargetValue      int    `json:"target_value" validate:"required,gte=1,targetValueOfMetric"`
+}
+
+func TestValidateTargetConfiguration(t *testing.T) {
+	setupTestCase(t)
+	cases := []struct {
+		conf  MockTargetConfiguration
+		valid bool
+	}{
+		{MockTargetConfiguration{MetricName: "PERCENT_AVAILABLE_SERVER_SESSIONS", TargetValue: 100}, true},
+		{MockTargetConfiguration{MetricName: "PERCENT_AVAILABLE_SERVER_SESSIONS", TargetValue: 101}, false},
+		{MockTargetConfiguration{MetricName: "CUSTOM_METRIC", CustomMetricName: "queue_length", TargetValue: 500}, true},
+		{MockTargetConfiguration{MetricName: "CUSTOM_METRIC", TargetValue: 50}, false},
+		{MockTargetConfiguration{MetricName: "CUSTOM_METRIC", CustomMetricName: "queue-length", TargetValue: 50}, false},
+		{MockTargetConfiguration{MetricName: "QUEUED_SERVER_SESSIONS", TargetValue: 500}, true},
+		{MockTargetConfiguration{MetricName: "CPU_UTILIZATION", TargetValue: 101}, false},
+		{MockTargetConfiguration{MetricName: "PERCENT_AVAILABLE_PROCESSES", TargetValue: 99}, true},
+		{MockTargetConfiguration{MetricName: "PERCENT_AVAILABLE_PROCESSES", TargetValue: 100}, false},
+	}
+	for _, c := range cases {
+		err := Validate(&c.conf)
+		if (err == nil) != c.valid {
+			t.Errorf("expected valid %v on %+v, got %v", c.valid, c.conf, err)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 应用包来源: OBS、S3兼容存储、带校验值的HTTPS地址以及实例上的本地(NFS)路径
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"fleetmanager/api/model/build"
+	"fleetmanager/api/service/constants"
+	"fmt"
+	"github.com/huaweicloud/huaweicloud-sdk-go-obs/obs"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// BuildSource 应用包来源, 用于创建应用包时校验以及生成实例的下载地址
+type BuildSource interface {
+	// Size 查询应用包大小, 无法提前获取时返回0
+	Size() (int64, error)
+	// DownloadUrl 生成有效期为expires秒的下载地址
+	DownloadUrl(expires int) (string, error)
+	Close()
+}
+
+const defaultS3Region = "us-east-1"
+
+const maxBuildSourceRedirects = 3
+
+var (
+	// buildSourceTransport S3和HTTPS来源由租户填写地址, 连接时校验实际连接的地址, 防止DNS重绑定到内部网络
+	buildSourceTransport = &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: 10 * time.Second,
+			Control: checkDialAddress,
+		}).DialContext,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: 30 * time.Second,
+		TLSClientConfig:       &tls.Config{MinVersion: tls.VersionTLS12},
+	}
+	buildSourceHttpClient = &http.Client{
+		Timeout:       30 * time.Second,
+		Transport:     buildSourceTransport,
+		CheckRedirect: checkSourceRedirect,
+	}
+	// resolveHost 解析地址, 测试时替换
+	resolveHost = net.DefaultResolver.LookupIPAddr
+)
+
+// internalNets 私有网段以及云内服务使用的共享地址网段
+var internalNets = parseCIDRs("0.0.0.0/8", "10.0.0.0/8", "100.64.0.0/10", "172.16.0.0/12", "192.168.0.0/16",
+	"fc00::/7")
+
+func parseCIDRs(cidrs ...string) []*net.IPNet {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		nets = append(nets, n)
+	}
+	return nets
+}
+
+// isForbiddenIP 回环、链路本地、私有、未指定和组播地址不允许作为应用包来源, 测试时替换
+var isForbiddenIP = func(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return true
+	}
+	for _, n := range internalNets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkSourceUrl 校验租户填写的应用包来源地址: 只允许https, 且解析后的地址都不能是内部地址
+func checkSourceUrl(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid build source url")
+	}
+	if u.Scheme != "https" || u.Hostname() == "" || u.User != nil {
+		return fmt.Errorf("build source url must be https with a host and without user info")
+	}
+	addrs, err := resolveHost(context.Background(), u.Hostname())
+	if err != nil {
+		return fmt.Errorf("resolve build source host %s failed: %v", u.Hostname(), err)
+	}
+	if len(addrs) == 0 {
+		return fmt.Errorf("build source host %s has no address", u.Hostname())
+	}
+	for _, addr := range addrs {
+		if isForbiddenIP(addr.IP) {
+			return fmt.Errorf("build source host %s resolves to internal address", u.Hostname())
+		}
+	}
+	return nil
+}
+
+// checkDialAddress 建立连接前校验实际连接的地址
+func checkDialAddress(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || isForbiddenIP(ip) {
+		return fmt.Errorf("connection to internal address %s is forbidden", host)
+	}
+	return nil
+}
+
+// checkSourceRedirect 重定向的地址同样需要校验
+func checkSourceRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxBuildSourceRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxBuildSourceRedirects)
+	}
+	return checkSourceUrl(req.URL.String())
+}
+
+// NewBuildSource 根据来源类型创建应用包来源, OBS来源使用obsClient获取的客户端
+func NewBuildSource(loc build.StorageLocation, obsClient func() (*obs.ObsClient, error)) (BuildSource, error) {
+	switch loc.SourceType {
+	case "", constants.BuildSourceOBS:
+		c, err := obsClient()
+		if err != nil {
+			return nil, err
+		}
+		return &objectSource{client: c, bucket: loc.BucketName, key: loc.BucketKey}, nil
+	case constants.BuildSourceS3:
+		if err := checkSourceUrl(loc.Endpoint); err != nil {
+			return nil, err
+		}
+		c, err := newS3Client(loc)
+		if err != nil {
+			return nil, err
+		}
+		return &objectSource{client: c, bucket: loc.BucketName, key: loc.BucketKey}, nil
+	case constants.BuildSourceHTTPS:
+		if err := checkSourceUrl(loc.Url); err != nil {
+			return nil, err
+		}
+		return &httpsSource{url: loc.Url}, nil
+	case constants.BuildSourceLocal:
+		return &localSource{path: loc.Path}, nil
+	default:
+		return nil, fmt.Errorf("unsupported build source type %s", loc.SourceType)
+	}
+}
+
+// newS3Client S3兼容存储使用V4签名和路径风格的地址, 凭证为空时匿名访问
+// 使用buildSourceTransport连接, 校验服务端证书和实际连接的地址
+func newS3Client(loc build.StorageLocation) (*obs.ObsClient, error) {
+	region := loc.Region
+	if region == "" {
+		region = defaultS3Region
+	}
+	return obs.New(loc.AccessKey, loc.SecretKey, loc.Endpoint, obs.WithSignature(obs.SignatureV4),
+		obs.WithPathStyle(true), obs.WithRegion(region), obs.WithHttpTransport(buildSourceTransport))
+}
+
+// objectSource OBS和S3兼容存储中的对象
+type objectSource struct {
+	client *obs.ObsClient
+	bucket string
+	key    string
+}
+
+func (s *objectSource) Size() (int64, error) {
+	return CheckObjectSizeInOBS(s.client, s.bucket, s.key)
+}
+
+func (s *objectSource) DownloadUrl(expires int) (string, error) {
+	input := &obs.CreateSignedUrlInput{}
+	input.Method = obs.HttpMethodGet
+	input.Expires = expires
+	input.Bucket = s.bucket
+	input.Key = s.key
+	output, err := s.client.CreateSignedUrl(input)
+	if err != nil {
+		return "", err
+	}
+	return output.SignedUrl, nil
+}
+
+func (s *objectSource) Close() {
+	s.client.Close()
+}
+
+// httpsSource 使用固定地址下载, 由应用包的校验值保证完整性
+type httpsSource struct {
+	url string
+}
+
+// Size 服务端不返回Content-Length时大小未知
+func (s *httpsSource) Size() (int64, error) {
+	rsp, err := buildSourceHttpClient.Head(s.url)
+	if err != nil {
+		return 0, err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("head %s return code %d", s.url, rsp.StatusCode)
+	}
+	if rsp.ContentLength < 0 {
+		return 0, nil
+	}
+	return rsp.ContentLength, nil
+}
+
+func (s *httpsSource) DownloadUrl(int) (string, error) {
+	return s.url, nil
+}
+
+func (s *httpsSource) Close() {}
+
+// localSource 实例上已经存在的应用包, 通常为NFS挂载目录下的文件, 只有实例自身可以访问
+type localSource struct {
+	path string
+}
+
+func (s *localSource) Size() (int64, error) {
+	return 0, nil
+}
+
+func (s *localSource) DownloadUrl(int) (string, error) {
+	return "", fmt.Errorf("local build %s can not be downloaded by url", s.path)
+}
+
+func (s *localSource) Close() {}
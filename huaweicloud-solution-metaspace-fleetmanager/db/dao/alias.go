@@ -13,6 +13,10 @@ const (
 	AliasTypeActive     = "ACTIVE"
 	AliasTypeDeactive   = "DEACTIVE"
 	AliasTypeTerminated = "TERMINATED"
+
+	AliasRoutingStrategyWeighted      = "WEIGHTED"
+	AliasRoutingStrategyStickyCreator = "STICKY_CREATOR"
+	AliasRoutingStrategyCapacityAware = "CAPACITY_AWARE"
 )
 type Alias struct {
 	Id               string    `orm:"column(id);size(64);pk" json:"id"`
@@ -24,6 +28,7 @@ type Alias struct {
 	AssociatedFleets string    `orm:"column(associated_fleets);size(2048)" json:"associated_fleets"`
 	Type             string    `orm:"column(type);size(16)" json:"type"`
 	Message          string    `orm:"column(message);size(1024)" json:"message"`
+	RoutingStrategy  string    `orm:"column(routing_strategy);size(32);default(WEIGHTED)" json:"routing_strategy"`
 }
 type aliasStorage struct{}
 
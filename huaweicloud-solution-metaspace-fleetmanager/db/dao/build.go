@@ -8,6 +8,8 @@ import (
 	"fleetmanager/api/params"
 	"fleetmanager/api/service/constants"
 	"fleetmanager/db/dbm"
+	"fleetmanager/security"
+	"fleetmanager/setting"
 	"github.com/beego/beego/v2/client/orm"
 	"github.com/google/uuid"
 	"strconv"
@@ -34,6 +36,15 @@ type Build struct {
 	OperatingSystem   string    `orm:"column(operating_system);size(50)" json:"operating_system"`
 	Version           string    `orm:"column(version);size(50)" json:"version"`
 	Size              int64     `orm:"column(size);size(32)" json:"size"`
+	Checksum          string    `orm:"column(checksum);size(64);null" json:"checksum"`
+	SourceType        string    `orm:"column(source_type);size(16);null" json:"source_type"`
+	SourceEndpoint    string    `orm:"column(source_endpoint);size(255);null" json:"source_endpoint"`
+	SourceRegion      string    `orm:"column(source_region);size(64);null" json:"source_region"`
+	SourceUrl         string    `orm:"column(source_url);size(1024);null" json:"source_url"`
+	SourcePath        string    `orm:"column(source_path);size(1024);null" json:"source_path"`
+	SourceAccessKey   string    `orm:"column(source_access_key);size(128);null" json:"-"`
+	// SourceSecretKey 使用AES-GCM加密存储
+	SourceSecretKey string `orm:"column(source_secret_key);size(512);null" json:"-"`
 }
 
 // @Title GetBuildById
@@ -74,6 +85,17 @@ func CheckBuildByName(name string, version string, projectId string) bool {
 // @Return *build.Build
 // @Return error
 func InsertBuild(r build.CreateRequest, region string, project string, size int64) (*Build, error) {
+	loc := r.StorageLocation
+	if loc.SourceType == "" {
+		loc.SourceType = constants.BuildSourceOBS
+	}
+	secretKey := ""
+	if loc.SecretKey != "" {
+		var err error
+		if secretKey, err = security.GCM_Encrypt(loc.SecretKey, setting.GCMKey, setting.GCMNonce); err != nil {
+			return nil, err
+		}
+	}
 
 	to, err := dbm.Ormer.Begin()
 	if err != nil {
@@ -89,14 +111,22 @@ func InsertBuild(r build.CreateRequest, region string, project string, size int6
 		Description:       r.Description,
 		State:             constants.BuildStateInitialized,
 		Version:           r.Version,
-		StorageBucketName: r.StorageLocation.BucketName,
-		StorageKey:        r.StorageLocation.BucketKey,
+		StorageBucketName: loc.BucketName,
+		StorageKey:        loc.BucketKey,
 		ImageRegion:       r.Region,
 		CreationTime:      time.Now().UTC(),
 		UpdateTime:        time.Now().UTC(),
 		StorageRegion:     region,
 		OperatingSystem:   r.OperatingSystem,
 		Size:              size,
+		Checksum:          strings.ToLower(loc.Checksum),
+		SourceType:        loc.SourceType,
+		SourceEndpoint:    loc.Endpoint,
+		SourceRegion:      loc.Region,
+		SourceUrl:         loc.Url,
+		SourcePath:        loc.Path,
+		SourceAccessKey:   loc.AccessKey,
+		SourceSecretKey:   secretKey,
 	}
 
 	_, err = to.Insert(bd)
@@ -114,6 +144,33 @@ func InsertBuild(r build.CreateRequest, region string, project string, size int6
 	return bd, nil
 }
 
+// GetBuildStorageLocation 还原应用包的来源信息, 解密S3来源的凭证, 历史数据的来源为OBS
+func GetBuildStorageLocation(b *Build) (build.StorageLocation, error) {
+	loc := build.StorageLocation{
+		SourceType: b.SourceType,
+		BucketName: b.StorageBucketName,
+		BucketKey:  b.StorageKey,
+		Endpoint:   b.SourceEndpoint,
+		Region:     b.SourceRegion,
+		AccessKey:  b.SourceAccessKey,
+		Url:        b.SourceUrl,
+		Path:       b.SourcePath,
+		Checksum:   b.Checksum,
+	}
+	if loc.SourceType == "" {
+		loc.SourceType = constants.BuildSourceOBS
+	}
+	if b.SourceSecretKey != "" {
+		sk, err := security.GCM_Decrypt(b.SourceSecretKey, setting.GCMKey, setting.GCMNonce)
+		if err != nil {
+			return loc, err
+		}
+		loc.SecretKey = sk
+	}
+
+	return loc, nil
+}
+
 func InsertBuildByImage(r build.CreateByImageRequest, region string, project string, osVersion string) (*Build, error) {
 	to, err := dbm.Ormer.Begin()
 	if err != nil {
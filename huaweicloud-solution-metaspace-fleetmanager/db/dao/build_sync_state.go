@@ -0,0 +1,68 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 实例应用包同步状态数据表定义
+package dao
+
+import (
+	"fleetmanager/db/dbm"
+	"time"
+
+	"github.com/beego/beego/v2/client/orm"
+	"github.com/google/uuid"
+)
+
+// BuildSyncState 实例上应用包的下载进度, 同一实例的同一应用包只保留最新的状态
+type BuildSyncState struct {
+	Id              string    `orm:"column(id);size(64);pk"`
+	FleetId         string    `orm:"column(fleet_id);size(64)"`
+	InstanceId      string    `orm:"column(instance_id);size(64)"`
+	BuildId         string    `orm:"column(build_id);size(64)"`
+	Result          int       `orm:"column(result)"`
+	State           string    `orm:"column(state);size(64)"`
+	DownloadedBytes int64     `orm:"column(downloaded_bytes)"`
+	TotalBytes      int64     `orm:"column(total_bytes)"`
+	UpdateTime      time.Time `orm:"column(update_time);type(datetime)"`
+}
+
+type buildSyncStateStorage struct{}
+
+var bss = buildSyncStateStorage{}
+
+// GetBuildSyncStateStorage 获取应用包同步状态存储
+func GetBuildSyncStateStorage() *buildSyncStateStorage {
+	return &bss
+}
+
+// Upsert 插入或者更新实例的应用包同步状态
+func (s *buildSyncStateStorage) Upsert(st *BuildSyncState) error {
+	var exist BuildSyncState
+	err := Filters{"InstanceId": st.InstanceId, "BuildId": st.BuildId}.Filter(BuildSyncStateTable).One(&exist)
+	if err == orm.ErrNoRows {
+		u, _ := uuid.NewUUID()
+		st.Id = u.String()
+		_, err = dbm.Ormer.Insert(st)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	st.Id = exist.Id
+	_, err = dbm.Ormer.Update(st)
+	return err
+}
+
+// List 获取应用包同步状态列表
+func (s *buildSyncStateStorage) List(f Filters, offset int, limit int) ([]BuildSyncState, error) {
+	var states []BuildSyncState
+	_, err := f.Filter(BuildSyncStateTable).OrderBy("-update_time").Offset(offset).Limit(limit).All(&states)
+
+	return states, err
+}
+
+// Count 获取应用包同步状态计数
+func (s *buildSyncStateStorage) Count(f Filters) (int64, error) {
+	count, err := f.Filter(BuildSyncStateTable).Count()
+
+	return count, err
+}
@@ -77,6 +77,17 @@ func (s *fleetStorage) Update(f *Fleet, cols ...string) error {
 	return err
 }
 
+// RecoverFleetState 将ERROR状态的fleet恢复为指定状态, 返回更新的行数
+func RecoverFleetState(id string, state string) (int64, error) {
+	return dbm.Ormer.QueryTable(FleetTable).
+		Filter("Id", id).
+		Filter("State", FleetStateError).
+		Update(orm.Params{
+			"State":      state,
+			"UpdateTime": time.Now().UTC(),
+		})
+}
+
 // Get 获取Fleet详情
 func (s *fleetStorage) Get(f Filters) (*Fleet, error) {
 	var fleet Fleet
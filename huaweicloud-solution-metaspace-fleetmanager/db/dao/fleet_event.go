@@ -8,6 +8,11 @@ import (
 	"time"
 )
 
+const (
+	FleetEventCodeWorkflowTaskTimeout = "WORKFLOW_TASK_TIMEOUT"
+	FleetEventCodeProcessCrashLoop    = "PROCESS_CRASH_LOOP"
+)
+
 // FleetEvent TODO:fleetId作为外键关联fleet表
 type FleetEvent struct {
 	Id              string    `orm:"column(id);size(64);pk"`
@@ -28,4 +28,5 @@ func Init() {
 	orm.RegisterModel(new(Alias))
 	orm.RegisterModel(new(User))
 	orm.RegisterModel(new(UserResConf))
+	orm.RegisterModel(new(BuildSyncState))
 }
@@ -11,6 +11,7 @@ type RuntimeConfiguration struct {
 	ServerSessionActivationTimeoutSeconds int    `orm:"column(server_session_activation_timeout_seconds);type(int);default(120)" json:"server_session_activation_timeout_seconds"`
 	MaxConcurrentServerSessionsPerProcess int    `orm:"column(max_concurrent_server_sessions_per_process);type(int);default(1)" json:"max_concurrent_server_sessions_per_process"`
 	ProcessConfigurations                 string `orm:"column(process_configurations);type(text)" json:"process_configurations"`
+	PlacementPolicy                       string `orm:"column(placement_policy);type(text);null" json:"placement_policy"`
 }
 
 type runtimeConfigurationStorage struct{}
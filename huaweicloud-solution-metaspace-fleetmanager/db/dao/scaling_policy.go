@@ -9,6 +9,9 @@ import (
 
 const (
 	TargetBasedPolicy = "TARGET_BASED"
+	ScheduledPolicy   = "SCHEDULED"
+	StepPolicy        = "STEP"
+	PredictivePolicy  = "PREDICTIVE"
 	RuleBasedPolicy   = "RULE_BASED"
 )
 
@@ -27,7 +30,11 @@ type ScalingPolicy struct {
 	State                    string `orm:"column(state);size(32)"`
 	TargetBasedConfiguration string `orm:"column(target_based_configuration);type(text);null"`
 	RuleBasedConfiguration   string `orm:"column(rule_based_configuration);type(text);null"`
+	ScheduledConfiguration   string `orm:"column(scheduled_configuration);type(text);null"`
+	StepConfiguration        string `orm:"column(step_configuration);type(text);null"`
+	PredictiveConfiguration  string `orm:"column(predictive_configuration);type(text);null"`
 	ResourceProjectId        string `orm:"column(resource_project_id);size(64)" json:"resource_project_id"`
+	DryRun                   bool   `orm:"column(dry_run);default(false)"`
 }
 
 type scalingPolicyStorage struct{}
@@ -25,4 +25,5 @@ const (
 	AliasTable                = "alias"
 	UserTable                 = "user"
 	UserResConfTable          = "user_res_conf"
+	BuildSyncStateTable       = "build_sync_state"
 )
@@ -93,6 +93,15 @@ func QueryDeadWorkNode(deadTime string) ([]WorkNode, error) {
 	return wns, err
 }
 
+// WorkNodeAlive 判断节点是否仍在运行, 节点停止心跳超过MaxDeadMinites后会被置为ERROR状态
+func WorkNodeAlive(workNodeId string) (bool, error) {
+	count, err := dbm.Ormer.QueryTable(WorkNodeTable).
+		Filter("Id", workNodeId).
+		Filter("State__in", []string{WorkNodeStateRunning, WorkNodeStateTakingOver}).
+		Count()
+	return count > 0, err
+}
+
 // HeartBeat 节点心跳
 func HeartBeat(workNodeId string) error {
 	_, err := dbm.Ormer.QueryTable(WorkNodeTable).
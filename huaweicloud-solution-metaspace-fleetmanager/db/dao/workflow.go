@@ -5,7 +5,9 @@ package dao
 
 import (
 	"fleetmanager/db/dbm"
+	"fmt"
 	"github.com/beego/beego/v2/client/orm"
+	"reflect"
 	"time"
 )
 
@@ -18,16 +20,35 @@ const (
 	WorkflowStateFinished    = "FINISHED"
 )
 
+const (
+	WorkflowActionCancel = "CANCEL"
+	WorkflowActionSkip   = "SKIP"
+)
+
+// WorkflowLease 工作流租约时长, 执行工作流的WorkNode需要在租约到期前续约, 租约过期后可被其他WorkNode接管
+var WorkflowLease = 30 * time.Second
+
+// ErrWorkflowLeaseLost 工作流每次被接管时FencingToken递增, 更新时携带的token与数据库不一致说明已被其他WorkNode接管
+var ErrWorkflowLeaseLost = fmt.Errorf("workflow lease is lost")
+
+// activeWorkflowStates 需要WorkNode持有租约执行的工作流状态
+var activeWorkflowStates = []string{WorkflowStateCreate, WorkflowStateRunning, WorkflowStateRollbacking}
+
 type Workflow struct {
-	Id           string    `orm:"column(id);size(64);pk" json:"id"`
-	ProjectId    string    `orm:"column(project_id);size(64)" json:"project_id"`
-	State        string    `orm:"column(state);size(32)" json:"state"`
-	ResourceId   string    `orm:"column(resource_id);size(64)" json:"resource_id"`
-	Meta         string    `orm:"column(meta);type(text)" json:"meta"`
-	Parameter    string    `orm:"column(parameter);type(text)" json:"parameter"`
-	WorkNodeId   string    `orm:"column(work_node_id);size(64)" json:"work_node_id"`
-	CreationTime time.Time `orm:"column(creation_time);type(datetime);auto_now_add" json:"creation_time"`
-	UpdateTime   time.Time `orm:"column(update_time);type(datetime);auto_now" json:"update_time"`
+	Id              string    `orm:"column(id);size(64);pk" json:"id"`
+	ProjectId       string    `orm:"column(project_id);size(64)" json:"project_id"`
+	State           string    `orm:"column(state);size(32)" json:"state"`
+	ResourceId      string    `orm:"column(resource_id);size(64)" json:"resource_id"`
+	Meta            string    `orm:"column(meta);type(text)" json:"meta"`
+	Parameter       string    `orm:"column(parameter);type(text)" json:"parameter"`
+	WorkNodeId      string    `orm:"column(work_node_id);size(64)" json:"work_node_id"`
+	TaskStates      string    `orm:"column(task_states);type(text)" json:"task_states"`
+	Action          string    `orm:"column(action);size(32)" json:"action"`
+	ActionTask      string    `orm:"column(action_task);size(128)" json:"action_task"`
+	FencingToken    int64     `orm:"column(fencing_token);default(0)" json:"fencing_token"`
+	LeaseExpireTime time.Time `orm:"column(lease_expire_time);type(datetime);null" json:"lease_expire_time"`
+	CreationTime    time.Time `orm:"column(creation_time);type(datetime);auto_now_add" json:"creation_time"`
+	UpdateTime      time.Time `orm:"column(update_time);type(datetime);auto_now" json:"update_time"`
 }
 
 // InsertWorkflow 插入工作流
@@ -54,21 +75,142 @@ func GetAllWorkflows(f Filters) ([]Workflow, error) {
 	return wfs, err
 }
 
-// UpdateWorkflow 更新工作流
+// ListWorkflows 分页获取工作流列表, 按创建时间倒序
+func ListWorkflows(f Filters, offset int, limit int) ([]Workflow, error) {
+	var wfs []Workflow
+	_, err := f.Filter(WorkflowTable).OrderBy("-CreationTime").Offset(offset).Limit(limit).All(&wfs)
+	return wfs, err
+}
+
+// CountWorkflows 获取工作流计数
+func CountWorkflows(f Filters) (int64, error) {
+	return f.Filter(WorkflowTable).Count()
+}
+
+// UpdateWorkflow 更新工作流, 仅当数据库中的fencing token与f一致时更新, 否则返回ErrWorkflowLeaseLost
 func UpdateWorkflow(f *Workflow, cols ...string) error {
-	_, err := dbm.Ormer.Update(f, cols...)
-	return err
+	params := orm.Params{}
+	v := reflect.ValueOf(f).Elem()
+	for _, col := range cols {
+		field := v.FieldByName(col)
+		if !field.IsValid() {
+			return fmt.Errorf("workflow has no field %s", col)
+		}
+		params[col] = field.Interface()
+	}
+
+	updated, err := dbm.Ormer.QueryTable(WorkflowTable).
+		Filter("Id", f.Id).
+		Filter("FencingToken", f.FencingToken).
+		Update(params)
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return checkFencingToken(f.Id, f.FencingToken)
+	}
+
+	return nil
 }
 
-// TakeOverWorkFlow 接管工作流
-func TakeOverWorkFlow(wfIds []string, takeOverId string) error {
-	if len(wfIds) == 0 {
-		return nil
+// checkFencingToken 更新行数为0时, 可能是数据未发生变化, 也可能是token已失效, 需要查询确认
+func checkFencingToken(id string, token int64) error {
+	w, err := GetWorkflow(Filters{"Id": id})
+	if err != nil {
+		return err
 	}
-	_, err := dbm.Ormer.QueryTable(WorkflowTable).
-		Filter("Id__in", wfIds).
+	if w.FencingToken != token {
+		return ErrWorkflowLeaseLost
+	}
+
+	return nil
+}
+
+// leaseExpired 租约已过期或从未设置租约
+func leaseExpired(now time.Time) *orm.Condition {
+	return orm.NewCondition().
+		Or("LeaseExpireTime__lt", now).
+		Or("LeaseExpireTime__isnull", true)
+}
+
+// RenewWorkflowLease 执行工作流的WorkNode续约
+func RenewWorkflowLease(id string, token int64) error {
+	updated, err := dbm.Ormer.QueryTable(WorkflowTable).
+		Filter("Id", id).
+		Filter("FencingToken", token).
 		Update(orm.Params{
-			"WorkNodeId": takeOverId,
+			"LeaseExpireTime": time.Now().UTC().Add(WorkflowLease),
+		})
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return checkFencingToken(id, token)
+	}
+
+	return nil
+}
+
+// ListExpiredWorkflows 获取租约已过期的未完成工作流
+func ListExpiredWorkflows(now time.Time) ([]Workflow, error) {
+	var wfs []Workflow
+	cond := orm.NewCondition().And("State__in", activeWorkflowStates).AndCond(leaseExpired(now))
+	_, err := dbm.Ormer.QueryTable(WorkflowTable).SetCond(cond).All(&wfs)
+	return wfs, err
+}
+
+// ClaimWorkflow 接管租约已过期的工作流, 原子性操作, 成功后fencing token加1
+func ClaimWorkflow(id string, token int64, workNodeId string, now time.Time) (int64, error) {
+	cond := orm.NewCondition().
+		And("Id", id).
+		And("FencingToken", token).
+		And("State__in", activeWorkflowStates).
+		AndCond(leaseExpired(now))
+	return dbm.Ormer.QueryTable(WorkflowTable).
+		SetCond(cond).
+		Update(orm.Params{
+			"WorkNodeId":      workNodeId,
+			"FencingToken":    token + 1,
+			"LeaseExpireTime": now.Add(WorkflowLease),
+			"UpdateTime":      now,
+		})
+}
+
+// SetWorkflowAction 为处于指定状态的工作流设置待执行的运维操作, 由执行该工作流的WorkNode消费
+func SetWorkflowAction(id string, states []string, action string, task string) (int64, error) {
+	return dbm.Ormer.QueryTable(WorkflowTable).
+		Filter("Id", id).
+		Filter("State__in", states).
+		Update(orm.Params{
+			"Action":     action,
+			"ActionTask": task,
+		})
+}
+
+// ClearWorkflowAction 清理已消费的运维操作
+func ClearWorkflowAction(id string, action string) (int64, error) {
+	return dbm.Ormer.QueryTable(WorkflowTable).
+		Filter("Id", id).
+		Filter("Action", action).
+		Update(orm.Params{
+			"Action":     "",
+			"ActionTask": "",
+		})
+}
+
+// ResumeWorkflow 将处于终止状态的工作流重新置为运行状态, 并由指定WorkNode接管, 成功后fencing token加1
+func ResumeWorkflow(id string, states []string, workNodeId string, token int64) (int64, error) {
+	return dbm.Ormer.QueryTable(WorkflowTable).
+		Filter("Id", id).
+		Filter("State__in", states).
+		Filter("FencingToken", token).
+		Update(orm.Params{
+			"State":           WorkflowStateRunning,
+			"WorkNodeId":      workNodeId,
+			"FencingToken":    token + 1,
+			"LeaseExpireTime": time.Now().UTC().Add(WorkflowLease),
+			"Action":          "",
+			"ActionTask":      "",
+			"UpdateTime":      time.Now().UTC(),
 		})
-	return err
 }
@@ -0,0 +1,270 @@
+package dao
+
+import (
+	"fleetmanager/db/dbm"
+	"fleetmanager/db/dbm/dbmtest"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	Init()
+	cleanup, err := dbmtest.InitSqlite()
+	if err != nil {
+		fmt.Printf("init sqlite error: %v\n", err)
+		os.Exit(1)
+	}
+	code := m.Run()
+	cleanup()
+	os.Exit(code)
+}
+
+func resetTables(t *testing.T) {
+	if err := dbmtest.ClearTables(WorkflowTable, WorkNodeTable); err != nil {
+		t.Fatalf("ClearTables() error = %v", err)
+	}
+}
+
+func insertTestWorkflow(t *testing.T, w *Workflow) {
+	if w.State == "" {
+		w.State = WorkflowStateRunning
+	}
+	if err := InsertWorkflow(w); err != nil {
+		t.Fatalf("InsertWorkflow() error = %v", err)
+	}
+}
+
+// insertLegacyWorkflow 模拟升级前创建的工作流: 没有fencing token和租约
+func insertLegacyWorkflow(t *testing.T, id string, workNodeId string) {
+	insertTestWorkflow(t, &Workflow{Id: id, WorkNodeId: workNodeId})
+	if _, err := dbm.Ormer.Raw("UPDATE workflow SET fencing_token = 0, lease_expire_time = NULL WHERE id = ?",
+		id).Exec(); err != nil {
+		t.Fatalf("update legacy workflow error = %v", err)
+	}
+}
+
+func getTestWorkflow(t *testing.T, id string) *Workflow {
+	w, err := GetWorkflow(Filters{"Id": id})
+	if err != nil {
+		t.Fatalf("GetWorkflow(%s) error = %v", id, err)
+	}
+	return w
+}
+
+func TestListExpiredWorkflows(t *testing.T) {
+	resetTables(t)
+	now := time.Now().UTC()
+	insertTestWorkflow(t, &Workflow{Id: "list-alive", FencingToken: 1, LeaseExpireTime: now.Add(time.Minute)})
+	insertTestWorkflow(t, &Workflow{Id: "list-expired", FencingToken: 1, LeaseExpireTime: now.Add(-time.Minute)})
+	insertTestWorkflow(t, &Workflow{Id: "list-finished", State: WorkflowStateFinished, FencingToken: 1,
+		LeaseExpireTime: now.Add(-time.Minute)})
+	insertLegacyWorkflow(t, "list-legacy", "node-legacy")
+
+	wfs, err := ListExpiredWorkflows(now)
+	if err != nil {
+		t.Fatalf("ListExpiredWorkflows() error = %v", err)
+	}
+	got := map[string]Workflow{}
+	for _, w := range wfs {
+		got[w.Id] = w
+	}
+	for _, id := range []string{"list-alive", "list-finished"} {
+		if _, ok := got[id]; ok {
+			t.Errorf("workflow %s should not be listed as expired", id)
+		}
+	}
+	if _, ok := got["list-expired"]; !ok {
+		t.Errorf("workflow list-expired is not listed as expired")
+	}
+	legacy, ok := got["list-legacy"]
+	if !ok {
+		t.Fatalf("workflow list-legacy is not listed as expired")
+	}
+	if legacy.FencingToken != 0 || !legacy.LeaseExpireTime.IsZero() {
+		t.Errorf("legacy workflow token = %d, lease = %v, want 0 and zero time",
+			legacy.FencingToken, legacy.LeaseExpireTime)
+	}
+}
+
+func TestClaimWorkflow(t *testing.T) {
+	resetTables(t)
+	now := time.Now().UTC()
+	insertTestWorkflow(t, &Workflow{Id: "claim-alive", WorkNodeId: "node-0", FencingToken: 1,
+		LeaseExpireTime: now.Add(time.Minute)})
+	insertTestWorkflow(t, &Workflow{Id: "claim-expired", WorkNodeId: "node-0", FencingToken: 1,
+		LeaseExpireTime: now.Add(-time.Second)})
+
+	tests := []struct {
+		name            string
+		id              string
+		token           int64
+		expectedUpdated int64
+		expectedOwner   string
+		expectedToken   int64
+	}{
+		{
+			name:          "lease is not expired",
+			id:            "claim-alive",
+			token:         1,
+			expectedOwner: "node-0",
+			expectedToken: 1,
+		},
+		{
+			name:          "stale fencing token",
+			id:            "claim-expired",
+			token:         0,
+			expectedOwner: "node-0",
+			expectedToken: 1,
+		},
+		{
+			name:            "lease is expired",
+			id:              "claim-expired",
+			token:           1,
+			expectedUpdated: 1,
+			expectedOwner:   "node-1",
+			expectedToken:   2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated, err := ClaimWorkflow(tt.id, tt.token, "node-1", now)
+			if err != nil {
+				t.Fatalf("ClaimWorkflow() error = %v", err)
+			}
+			if updated != tt.expectedUpdated {
+				t.Errorf("ClaimWorkflow() updated = %d, want %d", updated, tt.expectedUpdated)
+			}
+			w := getTestWorkflow(t, tt.id)
+			if w.WorkNodeId != tt.expectedOwner || w.FencingToken != tt.expectedToken {
+				t.Errorf("workflow owner = %s, token = %d, want %s, %d",
+					w.WorkNodeId, w.FencingToken, tt.expectedOwner, tt.expectedToken)
+			}
+		})
+	}
+
+	w := getTestWorkflow(t, "claim-expired")
+	if !w.LeaseExpireTime.After(now) {
+		t.Errorf("lease expire time = %v, want renewed after %v", w.LeaseExpireTime, now)
+	}
+}
+
+func TestClaimWorkflowConcurrently(t *testing.T) {
+	resetTables(t)
+	now := time.Now().UTC()
+	insertTestWorkflow(t, &Workflow{Id: "claim-concurrently", WorkNodeId: "node-0", FencingToken: 1,
+		LeaseExpireTime: now.Add(-time.Second)})
+	insertLegacyWorkflow(t, "claim-concurrently-legacy", "node-0")
+
+	for _, id := range []string{"claim-concurrently", "claim-concurrently-legacy"} {
+		token := getTestWorkflow(t, id).FencingToken
+		var claimed int64
+		var lock sync.Mutex
+		wg := sync.WaitGroup{}
+		for i := 1; i <= 5; i++ {
+			wg.Add(1)
+			go func(node string) {
+				defer wg.Done()
+				updated, err := ClaimWorkflow(id, token, node, now)
+				if err != nil {
+					t.Errorf("ClaimWorkflow() error = %v", err)
+					return
+				}
+				lock.Lock()
+				claimed += updated
+				lock.Unlock()
+			}(fmt.Sprintf("node-%d", i))
+		}
+		wg.Wait()
+
+		if claimed != 1 {
+			t.Errorf("workflow %s is claimed %d times, want exactly once", id, claimed)
+		}
+		if w := getTestWorkflow(t, id); w.FencingToken != token+1 || w.WorkNodeId == "node-0" {
+			t.Errorf("workflow %s owner = %s, token = %d, want a new owner with token %d",
+				id, w.WorkNodeId, w.FencingToken, token+1)
+		}
+	}
+}
+
+func TestRenewWorkflowLease(t *testing.T) {
+	resetTables(t)
+	expire := time.Now().UTC().Add(time.Second)
+	insertTestWorkflow(t, &Workflow{Id: "renew", FencingToken: 2, LeaseExpireTime: expire})
+
+	if err := RenewWorkflowLease("renew", 1); err != ErrWorkflowLeaseLost {
+		t.Errorf("RenewWorkflowLease() with stale token error = %v, want %v", err, ErrWorkflowLeaseLost)
+	}
+	if w := getTestWorkflow(t, "renew"); w.LeaseExpireTime.After(expire.Add(time.Second)) {
+		t.Errorf("lease is renewed with a stale token: %v", w.LeaseExpireTime)
+	}
+
+	if err := RenewWorkflowLease("renew", 2); err != nil {
+		t.Fatalf("RenewWorkflowLease() error = %v", err)
+	}
+	if w := getTestWorkflow(t, "renew"); !w.LeaseExpireTime.After(expire.Add(WorkflowLease / 2)) {
+		t.Errorf("lease expire time = %v, want renewed", w.LeaseExpireTime)
+	}
+}
+
+func TestUpdateWorkflow(t *testing.T) {
+	resetTables(t)
+	insertTestWorkflow(t, &Workflow{Id: "update", FencingToken: 2, TaskStates: "[]"})
+
+	stale := &Workflow{Id: "update", FencingToken: 1, State: WorkflowStateFinished}
+	if err := UpdateWorkflow(stale, "State"); err != ErrWorkflowLeaseLost {
+		t.Errorf("UpdateWorkflow() with stale token error = %v, want %v", err, ErrWorkflowLeaseLost)
+	}
+	if w := getTestWorkflow(t, "update"); w.State != WorkflowStateRunning {
+		t.Errorf("workflow state = %s, want not updated by stale token", w.State)
+	}
+
+	current := &Workflow{Id: "update", FencingToken: 2, State: WorkflowStateFinished, TaskStates: "[]"}
+	if err := UpdateWorkflow(current, "State", "TaskStates"); err != nil {
+		t.Fatalf("UpdateWorkflow() error = %v", err)
+	}
+	// 数据未发生变化时更新行数可能为0, 不能误判为租约丢失
+	if err := UpdateWorkflow(current, "TaskStates"); err != nil {
+		t.Errorf("UpdateWorkflow() without change error = %v", err)
+	}
+	if w := getTestWorkflow(t, "update"); w.State != WorkflowStateFinished {
+		t.Errorf("workflow state = %s, want %s", w.State, WorkflowStateFinished)
+	}
+	if err := UpdateWorkflow(&Workflow{Id: "update"}, "Unknown"); err == nil {
+		t.Errorf("UpdateWorkflow() with unknown field expected error, got nil")
+	}
+}
+
+func TestWorkNodeAlive(t *testing.T) {
+	resetTables(t)
+	for id, state := range map[string]string{
+		"alive-running":    WorkNodeStateRunning,
+		"alive-takingover": WorkNodeStateTakingOver,
+		"alive-error":      WorkNodeStateError,
+		"alive-terminated": WorkNodeStateTerminated,
+	} {
+		if err := InsertWorkNode(&WorkNode{Id: id, State: state}); err != nil {
+			t.Fatalf("InsertWorkNode() error = %v", err)
+		}
+	}
+
+	tests := map[string]bool{
+		"alive-running":    true,
+		"alive-takingover": true,
+		"alive-error":      false,
+		"alive-terminated": false,
+		"alive-unknown":    false,
+	}
+	for id, expected := range tests {
+		alive, err := WorkNodeAlive(id)
+		if err != nil {
+			t.Fatalf("WorkNodeAlive(%s) error = %v", id, err)
+		}
+		if alive != expected {
+			t.Errorf("WorkNodeAlive(%s) = %v, want %v", id, alive, expected)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 单元测试使用的sqlite数据库
+package dbmtest
+
+import (
+	"fleetmanager/db/dbm"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/beego/beego/v2/client/orm"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// InitSqlite 在临时目录创建sqlite数据库并建表, 替换dbm.Ormer, 返回清理函数
+// 需要在注册数据表(dao.Init)之后调用, 每个测试进程只能调用一次
+func InitSqlite() (func(), error) {
+	dir, err := os.MkdirTemp("", "fleetmanager-db")
+	if err != nil {
+		return nil, err
+	}
+	cleanup := func() { _ = os.RemoveAll(dir) }
+
+	// 多个WorkNode并发更新时等待写锁, 而不是直接返回database is locked
+	ds := fmt.Sprintf("file:%s?_busy_timeout=5000&_journal_mode=WAL", filepath.Join(dir, "fleetmanager.db"))
+	if err = orm.RegisterDriver("sqlite3", orm.DRSqlite); err != nil {
+		cleanup()
+		return nil, err
+	}
+	if err = orm.RegisterDataBase("default", "sqlite3", ds); err != nil {
+		cleanup()
+		return nil, err
+	}
+	if err = orm.RunSyncdb("default", false, false); err != nil {
+		cleanup()
+		return nil, err
+	}
+	dbm.Ormer = orm.NewOrm()
+
+	return cleanup, nil
+}
+
+// ClearTables 清空数据表, 保证测试可以重复执行
+func ClearTables(tables ...string) error {
+	for _, table := range tables {
+		if _, err := dbm.Ormer.Raw("DELETE FROM " + table).Exec(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
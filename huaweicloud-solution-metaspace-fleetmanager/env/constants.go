@@ -51,6 +51,8 @@ const (
 	AppGatewayEnableHmac                = "APPGATEWAY_ENABLE_HMAC"
 	AppGatewayHmacAK                    = "APPGATEWAY_HMAC_AK"
 	AppGatewayHmacSK                    = "APPGATEWAY_HMAC_SK"
+	AuxProxyHmacAK                      = "AUXPROXY_HMAC_AK"
+	AuxProxyHmacSK                      = "AUXPROXY_HMAC_SK"
 	FleetDiskSize                       = "FLEET_DISK_SIZE"
 	FleetVolumeType                     = "FLEET_VOLUME_TYPE"
 	FleetDiskType                       = "FLEET_DISK_TYPE"
@@ -74,3 +74,17 @@ func (mr *MockDirecterMockRecorder) Process(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Process", reflect.TypeOf((*MockDirecter)(nil).Process), ctx)
 }
+
+// SaveContext mocks base method.
+func (m *MockDirecter) SaveContext() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveContext")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveContext indicates an expected call of SaveContext.
+func (mr *MockDirecterMockRecorder) SaveContext() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveContext", reflect.TypeOf((*MockDirecter)(nil).SaveContext))
+}
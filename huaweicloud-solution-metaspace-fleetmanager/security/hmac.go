@@ -20,9 +20,11 @@ import (
 )
 
 const (
-	timestampFormat = "Mon, 02 Jan 2006 15:04:05 -0700"
-	prefix          = "SCASE-HMAC.V1"
+	timestampFormat  = "Mon, 02 Jan 2006 15:04:05 -0700"
+	prefix           = "SCASE-HMAC.V1"
 	lengthAuthString = 2
+	// 签名时间与服务端时间允许的最大偏差
+	hmacValidPeriod = 600 * time.Second
 )
 
 // RequestSignHmac 发起请求前进行hmac加密操作，会将加密信息加入req的header
@@ -49,6 +51,43 @@ func RequestSignHmac(req *http.Request, ak []byte, sk []byte) error {
 	return nil
 }
 
+// ValidateHmac 校验请求的hmac签名, 请求未签名、ak不匹配、摘要不一致或签名过期时返回错误
+func ValidateHmac(req *http.Request, ak []byte, sk []byte) error {
+	if req == nil {
+		return errors.New("hmac validate param req is nil")
+	}
+	if len(ak) == 0 || len(sk) == 0 {
+		return errors.New("hmac credential is not configured")
+	}
+
+	h := Hmac{Timestamp: req.Header.Get("Date")}
+	reqAK, digest, err := h.decodeAuthorization(req.Header.Get("Authorization"))
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal([]byte(reqAK), ak) {
+		return errors.New("hmac ak not matched")
+	}
+
+	tm, err := time.Parse(timestampFormat, h.Timestamp)
+	if err != nil {
+		return errors.New("hmac timestamp format error")
+	}
+	diff := time.Since(tm)
+	if diff > hmacValidPeriod || diff < -hmacValidPeriod {
+		return errors.Errorf("hmac timestamp diverse more than %v", hmacValidPeriod)
+	}
+
+	toSign, err := genStrToSign(req, h.Timestamp)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal([]byte(hmacSign(toSign, sk)), []byte(digest)) {
+		return errors.New("hmac signature not matched")
+	}
+	return nil
+}
+
 func genStrToSign(req *http.Request, timestamp string) (string, error) {
 	var body []byte
 	var err error
@@ -0,0 +1,61 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// hmac签名校验测试模块
+package security
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func newSignedRequest(t *testing.T, ak, sk []byte) *http.Request {
+	req, err := http.NewRequest(http.MethodPost, "https://fleetmanager/v1/fleets/f1/events",
+		bytes.NewBufferString(`{"event_code":"PROCESS_CRASH_LOOP"}`))
+	if err != nil {
+		t.Fatalf("new request err: %v", err)
+	}
+	if err = RequestSignHmac(req, ak, sk); err != nil {
+		t.Fatalf("sign request err: %v", err)
+	}
+	return req
+}
+
+func TestValidateHmac(t *testing.T) {
+	ak := []byte("auxproxy")
+	sk := []byte("secret")
+
+	if err := ValidateHmac(newSignedRequest(t, ak, sk), ak, sk); err != nil {
+		t.Errorf("valid request rejected: %v", err)
+	}
+
+	unsigned, _ := http.NewRequest(http.MethodPost, "https://fleetmanager/v1/fleets/f1/events", nil)
+	if err := ValidateHmac(unsigned, ak, sk); err == nil {
+		t.Errorf("unsigned request accepted")
+	}
+
+	if err := ValidateHmac(newSignedRequest(t, ak, []byte("other")), ak, sk); err == nil {
+		t.Errorf("request signed with wrong sk accepted")
+	}
+
+	if err := ValidateHmac(newSignedRequest(t, []byte("other"), sk), ak, sk); err == nil {
+		t.Errorf("request signed with wrong ak accepted")
+	}
+
+	tampered := newSignedRequest(t, ak, sk)
+	tampered.Body = http.NoBody
+	if err := ValidateHmac(tampered, ak, sk); err == nil {
+		t.Errorf("request with tampered body accepted")
+	}
+
+	expired := newSignedRequest(t, ak, sk)
+	expired.Header.Set("Date", time.Now().Add(-2*hmacValidPeriod).UTC().Format(timestampFormat))
+	if err := ValidateHmac(expired, ak, sk); err == nil {
+		t.Errorf("expired request accepted")
+	}
+
+	if err := ValidateHmac(newSignedRequest(t, ak, sk), nil, nil); err == nil {
+		t.Errorf("request accepted without configured credential")
+	}
+}
@@ -114,6 +114,8 @@ var (
 	AppGatewayEnableHmac                bool
 	AppGatewayHmacAK                    []byte
 	AppGatewayHmacSK                    []byte
+	AuxProxyHmacAK                      []byte
+	AuxProxyHmacSK                      []byte
 	FleetDiskSize                       int
 	FleetVolumeType                     string
 	FleetDiskType                       string
@@ -247,6 +249,27 @@ func loadAASSHmacConfig() error {
 	return nil
 }
 
+// auxproxy调用内部接口时必须携带hmac签名, 因此该秘钥为必选配置
+func loadAuxProxyHmacConfig() error {
+	auxproxyAKStr := getEnvString(env.AuxProxyHmacAK, "")
+	auxproxySKStr := getEnvString(env.AuxProxyHmacSK, "")
+	if auxproxyAKStr == "" || auxproxySKStr == "" {
+		return fmt.Errorf("missing auxproxy hmac credential config %s, %s",
+			env.AuxProxyHmacAK,
+			env.AuxProxyHmacSK)
+	}
+
+	auxproxySKDec, err := decodeSensitiveInfo(auxproxySKStr, CryptModeGCM)
+	if err != nil {
+		return fmt.Errorf("decrypt error, invalid auxproxy hmac sk")
+	}
+
+	AuxProxyHmacAK = []byte(auxproxyAKStr)
+	AuxProxyHmacSK = []byte(auxproxySKDec)
+
+	return nil
+}
+
 func loadServiceCredential() error {
 	serviceAKStr := getEnvString(env.ServiceAK, "")
 	serviceSKStr := getEnvString(env.ServiceSK, "")
@@ -283,6 +306,10 @@ func loadServiceCredential() error {
 		}
 	}
 
+	if err := loadAuxProxyHmacConfig(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -9,7 +9,6 @@ import (
 	"fleetmanager/workflow/directer"
 	"fleetmanager/workflow/meta"
 	"fmt"
-	"time"
 )
 
 type BaseTask struct {
@@ -98,6 +97,12 @@ func (t *BaseTask) getRetryDelay(d directer.WorkflowDirection) int {
 	return 0
 }
 
+// SetRetryTimes 设置任务已重试的次数, 用于超时后重建的任务实例继承重试进度
+func (t *BaseTask) SetRetryTimes(retryTimes int, rollbackRetryTimes int) {
+	t.retryTimes = retryTimes
+	t.rollbackRetryTimes = rollbackRetryTimes
+}
+
 func (t *BaseTask) stepLog(ctx *directer.ExecuteContext) {
 	success := 1
 	if ctx.Err != nil {
@@ -106,7 +111,7 @@ func (t *BaseTask) stepLog(ctx *directer.ExecuteContext) {
 	t.Logger.WithFields(map[string]interface{}{
 		logger.WorkflowDirection: ctx.Direction,
 		logger.TaskRetryTimes:    t.retryTimes,
-		logger.TaskRetryDelay:    t.getRetryDelay(ctx.Direction),
+		logger.TaskRetryDelay:    ctx.RetryDelay,
 		logger.Success:           success,
 		logger.Error:             fmt.Sprintf("%v", ctx.Err),
 	}).Info("task step log")
@@ -114,7 +119,6 @@ func (t *BaseTask) stepLog(ctx *directer.ExecuteContext) {
 
 // ExecNext 执行下一个任务
 func (t *BaseTask) ExecNext(output interface{}, err error) {
-	retryDelay := 0
 	ctx := &directer.ExecuteContext{
 		FromOutput: output,
 		Err:        err,
@@ -127,7 +131,7 @@ func (t *BaseTask) ExecNext(output interface{}, err error) {
 	if err != nil {
 		if t.needRetry(ctx.Direction) {
 			ctx.Next = t.Step
-			retryDelay = t.getRetryDelay(ctx.Direction)
+			ctx.RetryDelay = t.getRetryDelay(ctx.Direction)
 		} else {
 			// err不为空，且重试达到最大次数，此时判断该任务是否允许跳过，若允许跳过，执行下个任务，否则执行回滚
 			if t.meta.ExecuteFailure.Ignore {
@@ -141,20 +145,14 @@ func (t *BaseTask) ExecNext(output interface{}, err error) {
 		ctx.Next = t.Next
 	}
 
-	// 记录一下任务的运行日志
+	// 记录一下任务的运行日志, 重试场景的延迟由工作流在重新调度任务时处理
 	t.stepLog(ctx)
 
-	// 任务重试场景支持延迟重试
-	if retryDelay > 0 {
-		time.Sleep(time.Duration(retryDelay) * time.Second)
-	}
-
 	t.Directer.Process(ctx)
 }
 
 // RollbackPrev 回滚任务
 func (t *BaseTask) RollbackPrev(output interface{}, err error) {
-	retryDelay := 0
 	ctx := &directer.ExecuteContext{
 		FromOutput: output,
 		Err:        err,
@@ -167,7 +165,7 @@ func (t *BaseTask) RollbackPrev(output interface{}, err error) {
 	if err != nil {
 		if t.needRetry(ctx.Direction) {
 			ctx.Next = t.Step
-			retryDelay = t.getRetryDelay(ctx.Direction)
+			ctx.RetryDelay = t.getRetryDelay(ctx.Direction)
 		} else {
 			// err不为空，且重试达到最大次数，此时判断该任务是否允许跳过，若允许跳过，执行下个回滚流程，否则结束工作流
 			if t.meta.RollbackFailure.Ignore {
@@ -180,14 +178,9 @@ func (t *BaseTask) RollbackPrev(output interface{}, err error) {
 		ctx.Next = t.Prev
 	}
 
-	// 记录一下任务的运行日志
+	// 记录一下任务的运行日志, 重试场景的延迟由工作流在重新调度任务时处理
 	t.stepLog(ctx)
 
-	// 任务重试场景支持延迟重试
-	if retryDelay > 0 {
-		time.Sleep(time.Duration(retryDelay) * time.Second)
-	}
-
 	t.Directer.Process(ctx)
 }
 
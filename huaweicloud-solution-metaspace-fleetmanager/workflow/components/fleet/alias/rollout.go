@@ -0,0 +1,181 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// alias蓝绿切换公共方法
+package alias
+
+import (
+	"encoding/json"
+	"fleetmanager/api/model/alias"
+	"fleetmanager/api/params"
+	"fleetmanager/api/service/constants"
+	"fleetmanager/client"
+	"fleetmanager/client/model"
+	"fleetmanager/db/dao"
+	"fleetmanager/logger"
+	"fleetmanager/workflow/directer"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+)
+
+const (
+	// weightPrecision fleet的权重最多支持三位小数
+	weightPrecision = 1000
+
+	serverSessionStateError = "ERROR"
+	processStateActive      = "ACTIVE"
+)
+
+func getRollout(ctx *directer.WorkflowContext) (*alias.RolloutRequest, error) {
+	r := &alias.RolloutRequest{}
+	if err := json.Unmarshal(ctx.Get(directer.WfKeyAliasRollout).ToJson("{}"), r); err != nil {
+		return nil, err
+	}
+	if r.OldFleetId == "" || r.NewFleetId == "" {
+		return nil, fmt.Errorf("alias rollout parameter is invalid")
+	}
+
+	return r, nil
+}
+
+func parseAssociatedFleets(afs string) ([]alias.AssociatedFleet, error) {
+	fleets := []alias.AssociatedFleet{}
+	if err := json.Unmarshal([]byte(afs), &fleets); err != nil {
+		return nil, fmt.Errorf("parse associated fleets %s error: %v", afs, err)
+	}
+
+	return fleets, nil
+}
+
+// shiftWeights 按照新fleet占两者流量的比例重新计算新旧fleet的权重, 两者权重之和以及其他fleet的权重保持不变
+func shiftWeights(original []alias.AssociatedFleet, oldFleetId string, newFleetId string,
+	ratio float32) []alias.AssociatedFleet {
+	var total float32
+	for _, af := range original {
+		if af.FleetId == oldFleetId || af.FleetId == newFleetId {
+			total += af.Weight
+		}
+	}
+	newWeight := float32(math.Round(float64(total*ratio*weightPrecision))) / weightPrecision
+	oldWeight := float32(math.Round(float64((total-newWeight)*weightPrecision))) / weightPrecision
+
+	fleets := make([]alias.AssociatedFleet, 0, len(original)+1)
+	found := false
+	for _, af := range original {
+		switch af.FleetId {
+		case oldFleetId:
+			af.Weight = oldWeight
+		case newFleetId:
+			af.Weight = newWeight
+			found = true
+		}
+		fleets = append(fleets, af)
+	}
+	if !found {
+		fleets = append(fleets, alias.AssociatedFleet{FleetId: newFleetId, Weight: newWeight})
+	}
+
+	return fleets
+}
+
+var getAlias = func(aliasId string) (*dao.Alias, error) {
+	return dao.GetAliasStorage().Get(dao.Filters{"Id": aliasId})
+}
+
+var updateAliasFleets = func(a *dao.Alias, fleets []alias.AssociatedFleet) error {
+	b, err := json.Marshal(fleets)
+	if err != nil {
+		return err
+	}
+	a.AssociatedFleets = string(b)
+	a.UpdateTime = time.Now().UTC()
+	return dao.GetAliasStorage().Update(a, "AssociatedFleets", "UpdateTime")
+}
+
+// countReadyProcesses 查询fleet下处于ACTIVE状态的进程数, 最多统计一页
+var countReadyProcesses = func(requestId string, region string, fleetId string) (int, error) {
+	url := client.GetServiceEndpoint(client.ServiceNameAPPGW, region) + constants.ProcessesUrl
+	req := client.NewRequest(client.ServiceNameAPPGW, url, http.MethodGet, nil)
+	req.SetQuery(params.QueryFleetId, fleetId)
+	req.SetQuery(params.QueryState, processStateActive)
+	req.SetQuery(params.QueryOffset, params.DefaultOffset)
+	req.SetQuery(params.QueryLimit, params.DefaultLimit)
+	req.SetQuery(params.QuerySort, params.DefaultSort)
+	req.SetHeader(map[string]string{
+		logger.RequestId: requestId,
+	})
+	code, rsp, err := req.DoRequest()
+	if err != nil {
+		return 0, err
+	}
+	if code != http.StatusOK {
+		return 0, fmt.Errorf("list processes failed, code: %d, rsp: %s", code, rsp)
+	}
+
+	obj := &model.ListProcessResponse{}
+	if err := json.Unmarshal(rsp, obj); err != nil {
+		return 0, err
+	}
+	return obj.Count, nil
+}
+
+// serverSessionErrorRate 统计fleet最近创建的一页服务端会话中处于ERROR状态的比例, 没有会话时返回0
+var serverSessionErrorRate = func(requestId string, region string, fleetId string) (float64, error) {
+	url := client.GetServiceEndpoint(client.ServiceNameAPPGW, region) + constants.ServerSessionsUrl
+	req := client.NewRequest(client.ServiceNameAPPGW, url, http.MethodGet, nil)
+	req.SetQuery(params.QueryFleetId, fleetId)
+	req.SetQuery(params.QueryOffset, params.DefaultOffset)
+	req.SetQuery(params.QueryLimit, params.DefaultLimit)
+	req.SetQuery(params.QuerySort, params.DefaultSort)
+	req.SetHeader(map[string]string{
+		logger.RequestId: requestId,
+	})
+	code, rsp, err := req.DoRequest()
+	if err != nil {
+		return 0, err
+	}
+	if code != http.StatusOK {
+		return 0, fmt.Errorf("list server sessions failed, code: %d, rsp: %s", code, rsp)
+	}
+
+	obj := &model.ListServerSessionResponse{}
+	if err := json.Unmarshal(rsp, obj); err != nil {
+		return 0, err
+	}
+	if len(obj.ServerSessions) == 0 {
+		return 0, nil
+	}
+	failed := 0
+	for _, ss := range obj.ServerSessions {
+		if ss.State == serverSessionStateError {
+			failed++
+		}
+	}
+	return float64(failed) / float64(len(obj.ServerSessions)), nil
+}
+
+// checkHealth 检查新fleet的健康门限: 就绪进程数不低于MinReadyProcesses, 服务端会话错误率不高于MaxErrorRate
+func checkHealth(requestId string, region string, r *alias.RolloutRequest) error {
+	ready, err := countReadyProcesses(requestId, region, r.NewFleetId)
+	if err != nil {
+		return err
+	}
+	if ready < r.MinReadyProcesses {
+		return fmt.Errorf("fleet %s has %d ready processes, less than %d", r.NewFleetId, ready, r.MinReadyProcesses)
+	}
+
+	if r.MaxErrorRate == nil {
+		return nil
+	}
+	rate, err := serverSessionErrorRate(requestId, region, r.NewFleetId)
+	if err != nil {
+		return err
+	}
+	if rate > *r.MaxErrorRate {
+		return fmt.Errorf("server session error rate of fleet %s is %.3f, more than %.3f",
+			r.NewFleetId, rate, *r.MaxErrorRate)
+	}
+
+	return nil
+}
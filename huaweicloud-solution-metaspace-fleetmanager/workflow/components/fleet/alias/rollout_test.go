@@ -0,0 +1,199 @@
+package alias
+
+import (
+	"encoding/json"
+	"fleetmanager/api/model/alias"
+	"fleetmanager/config"
+	"fleetmanager/db/dao"
+	"fleetmanager/logger"
+	mockdirecter "fleetmanager/mocks/workflow/directer"
+	"fleetmanager/workflow/components"
+	"fleetmanager/workflow/directer"
+	"reflect"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+)
+
+func TestShiftWeights(t *testing.T) {
+	tests := []struct {
+		name     string
+		original []alias.AssociatedFleet
+		ratio    float32
+		expected []alias.AssociatedFleet
+	}{
+		{
+			name:     "new fleet not associated",
+			original: []alias.AssociatedFleet{{FleetId: "old", Weight: 1}},
+			ratio:    0.1,
+			expected: []alias.AssociatedFleet{{FleetId: "old", Weight: 0.9}, {FleetId: "new", Weight: 0.1}},
+		},
+		{
+			name: "other fleets keep their weights",
+			original: []alias.AssociatedFleet{{FleetId: "other", Weight: 0.4}, {FleetId: "old", Weight: 0.5},
+				{FleetId: "new", Weight: 0.1}},
+			ratio: 0.5,
+			expected: []alias.AssociatedFleet{{FleetId: "other", Weight: 0.4}, {FleetId: "old", Weight: 0.3},
+				{FleetId: "new", Weight: 0.3}},
+		},
+		{
+			name:     "all traffic to new fleet",
+			original: []alias.AssociatedFleet{{FleetId: "old", Weight: 0.7}},
+			ratio:    1,
+			expected: []alias.AssociatedFleet{{FleetId: "old", Weight: 0}, {FleetId: "new", Weight: 0.7}},
+		},
+		{
+			name:     "weights are rounded to three decimals",
+			original: []alias.AssociatedFleet{{FleetId: "old", Weight: 1}},
+			ratio:    0.3333,
+			expected: []alias.AssociatedFleet{{FleetId: "old", Weight: 0.667}, {FleetId: "new", Weight: 0.333}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := shiftWeights(tt.original, "old", "new", tt.ratio)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("shiftWeights() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestShiftAliasWeight(t *testing.T) {
+	originGetAlias := getAlias
+	originUpdateAliasFleets := updateAliasFleets
+	originCountReadyProcesses := countReadyProcesses
+	originServerSessionErrorRate := serverSessionErrorRate
+	defer func() {
+		getAlias = originGetAlias
+		updateAliasFleets = originUpdateAliasFleets
+		countReadyProcesses = originCountReadyProcesses
+		serverSessionErrorRate = originServerSessionErrorRate
+	}()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	original := `[{"fleet_id":"old","weight":1}]`
+	maxErrorRate := 0.05
+	tests := []struct {
+		name          string
+		rollback      bool
+		savedStep     int
+		readyProcs    int
+		errorRates    []float64
+		expectedErr   bool
+		expectedSteps []string
+		expectedSaved int
+	}{
+		{
+			name:        "all steps pass health gate",
+			readyProcs:  2,
+			errorRates:  []float64{0, 0.01, 0.05},
+			expectedErr: false,
+			expectedSteps: []string{
+				`[{"fleet_id":"old","weight":0.9},{"fleet_id":"new","weight":0.1}]`,
+				`[{"fleet_id":"old","weight":0.5},{"fleet_id":"new","weight":0.5}]`,
+				`[{"fleet_id":"old","weight":0},{"fleet_id":"new","weight":1}]`,
+			},
+			expectedSaved: 3,
+		},
+		{
+			name:        "resume from saved step",
+			savedStep:   2,
+			readyProcs:  2,
+			errorRates:  []float64{0},
+			expectedErr: false,
+			expectedSteps: []string{
+				`[{"fleet_id":"old","weight":0},{"fleet_id":"new","weight":1}]`,
+			},
+			expectedSaved: 3,
+		},
+		{
+			name:        "error rate exceeds threshold at second step",
+			readyProcs:  2,
+			errorRates:  []float64{0, 0.2},
+			expectedErr: true,
+			expectedSteps: []string{
+				`[{"fleet_id":"old","weight":0.9},{"fleet_id":"new","weight":0.1}]`,
+				`[{"fleet_id":"old","weight":0.5},{"fleet_id":"new","weight":0.5}]`,
+			},
+			expectedSaved: 1,
+		},
+		{
+			name:        "not enough ready processes",
+			readyProcs:  0,
+			expectedErr: true,
+			expectedSteps: []string{
+				`[{"fleet_id":"old","weight":0.9},{"fleet_id":"new","weight":0.1}]`,
+			},
+		},
+		{
+			name:          "rollback restores original weights",
+			rollback:      true,
+			savedStep:     2,
+			expectedErr:   false,
+			expectedSteps: []string{original},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &dao.Alias{Id: "alias", Type: dao.AliasTypeActive, AssociatedFleets: original}
+			var steps []string
+			getAlias = func(string) (*dao.Alias, error) { return a, nil }
+			updateAliasFleets = func(a *dao.Alias, fleets []alias.AssociatedFleet) error {
+				b, _ := json.Marshal(fleets)
+				steps = append(steps, string(b))
+				return nil
+			}
+			countReadyProcesses = func(string, string, string) (int, error) { return tt.readyProcs, nil }
+			checked := 0
+			serverSessionErrorRate = func(string, string, string) (float64, error) {
+				checked++
+				return tt.errorRates[checked-1], nil
+			}
+
+			mockDirecter := mockdirecter.NewMockDirecter(mockCtrl)
+			context := &directer.WorkflowContext{Config: config.NewConfig(map[string]interface{}{
+				directer.WfKeyAliasId: "alias",
+				directer.WfKeyAliasRollout: &alias.RolloutRequest{
+					OldFleetId:        "old",
+					NewFleetId:        "new",
+					Steps:             []float32{0.1, 0.5, 1},
+					MaxErrorRate:      &maxErrorRate,
+					MinReadyProcesses: 1,
+				},
+				directer.WfKeyAliasOriginalFleets: original,
+				directer.WfKeyAliasRolloutStep:    tt.savedStep,
+			})}
+			mockDirecter.EXPECT().GetContext().Return(context).AnyTimes()
+			mockDirecter.EXPECT().SaveContext().Return(nil).AnyTimes()
+			var result *directer.ExecuteContext
+			mockDirecter.EXPECT().Process(gomock.Any()).Do(func(ctx *directer.ExecuteContext) { result = ctx })
+
+			task := ShiftAliasWeightTask{
+				components.BaseTask{
+					Logger:   logger.NewDebugLogger(),
+					Directer: mockDirecter,
+				},
+			}
+			if tt.rollback {
+				_, _ = task.Rollback(nil)
+			} else {
+				_, _ = task.Execute(nil)
+			}
+
+			if (result.Err != nil) != tt.expectedErr {
+				t.Errorf("task error = %v, expectedErr %v", result.Err, tt.expectedErr)
+			}
+			if !reflect.DeepEqual(steps, tt.expectedSteps) {
+				t.Errorf("weights = %v, want %v", steps, tt.expectedSteps)
+			}
+			if saved := context.Get(directer.WfKeyAliasRolloutStep).ToInt(-1); saved != tt.expectedSaved {
+				t.Errorf("saved rollout step = %d, want %d", saved, tt.expectedSaved)
+			}
+		})
+	}
+}
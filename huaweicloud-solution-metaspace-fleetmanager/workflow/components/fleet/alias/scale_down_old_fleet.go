@@ -0,0 +1,94 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 缩容旧fleet
+package alias
+
+import (
+	"encoding/json"
+	"fleetmanager/api/model/fleet"
+	"fleetmanager/api/service/constants"
+	"fleetmanager/client"
+	"fleetmanager/db/dao"
+	"fleetmanager/logger"
+	"fleetmanager/workflow/components"
+	"fleetmanager/workflow/directer"
+	"fleetmanager/workflow/meta"
+	"fmt"
+	"net/http"
+)
+
+type ScaleDownOldFleetTask struct {
+	components.BaseTask
+}
+
+// Execute 流量切换完成后, 按需将旧fleet的最小及期望实例数缩容到0
+func (t *ScaleDownOldFleetTask) Execute(*directer.ExecuteContext) (output interface{}, err error) {
+	defer func() { t.ExecNext(output, err) }()
+
+	ctx := t.Directer.GetContext()
+	r, err := getRollout(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !r.ScaleDownOldFleet {
+		return nil, nil
+	}
+
+	f, err := dao.GetFleetStorage().Get(dao.Filters{"Id": r.OldFleetId})
+	if err != nil {
+		return nil, err
+	}
+	group, err := dao.GetScalingGroupStorage().GetOne(dao.Filters{"FleetId": f.Id})
+	if err != nil {
+		return nil, err
+	}
+	if err = scaleDownScalingGroup(ctx.Get(directer.WfKeyRequestId).ToString(""), f.Region, group); err != nil {
+		return nil, err
+	}
+
+	f.Minimum = 0
+	f.Desired = 0
+	if err = dao.GetFleetStorage().Update(f, "Minimum", "Desired"); err != nil {
+		return nil, err
+	}
+	t.Logger.Info("fleet %s is scaled down to zero after alias rollout", f.Id)
+
+	return nil, nil
+}
+
+var scaleDownScalingGroup = func(requestId string, region string, group *dao.ScalingGroup) error {
+	zero := 0
+	updateReq := fleet.UpdateScalingGroupRequest{
+		MinInstanceNumber:    &zero,
+		DesireInstanceNumber: &zero,
+	}
+	b, err := json.Marshal(updateReq)
+	if err != nil {
+		return err
+	}
+
+	url := client.GetServiceEndpoint(client.ServiceNameAASS, region) +
+		fmt.Sprintf(constants.UpdateScalingGroupUrlPattern, group.ResourceProjectId, group.Id)
+	req := client.NewRequest(client.ServiceNameAASS, url, http.MethodPut, b)
+	req.SetHeader(map[string]string{
+		logger.RequestId: requestId,
+	})
+	code, rsp, err := req.DoRequest()
+	if err != nil {
+		return err
+	}
+	if code < http.StatusOK || code >= http.StatusBadRequest {
+		return fmt.Errorf("update scaling group %s failed, code: %d, rsp: %s", group.Id, code, rsp)
+	}
+
+	return nil
+}
+
+// NewScaleDownOldFleetTask 新建缩容旧fleet任务
+func NewScaleDownOldFleetTask(meta meta.TaskMeta, directer directer.Directer, step int) components.Task {
+	t := &ScaleDownOldFleetTask{
+		components.NewBaseTask(meta, directer, step),
+	}
+
+	return t
+}
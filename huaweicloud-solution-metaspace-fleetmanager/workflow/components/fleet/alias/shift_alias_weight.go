@@ -0,0 +1,101 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 逐步切换alias流量
+package alias
+
+import (
+	"fleetmanager/workflow/components"
+	"fleetmanager/workflow/directer"
+	"fleetmanager/workflow/meta"
+	"fmt"
+	"time"
+)
+
+type ShiftAliasWeightTask struct {
+	components.BaseTask
+}
+
+// saveRolloutStep 记录已经通过健康检查的步数并立即持久化, 工作流被接管后从下一步继续
+func (t *ShiftAliasWeightTask) saveRolloutStep(step int) error {
+	t.Directer.GetContext().SetInt(directer.WfKeyAliasRolloutStep, step)
+	return t.Directer.SaveContext()
+}
+
+// Execute 按照步长逐步将流量切换到新fleet, 每一步等待StepIntervalSeconds后检查健康门限, 不满足时任务失败并触发回滚
+func (t *ShiftAliasWeightTask) Execute(*directer.ExecuteContext) (output interface{}, err error) {
+	defer func() { t.ExecNext(output, err) }()
+
+	ctx := t.Directer.GetContext()
+	r, err := getRollout(ctx)
+	if err != nil {
+		return nil, err
+	}
+	original, err := parseAssociatedFleets(ctx.Get(directer.WfKeyAliasOriginalFleets).ToString(""))
+	if err != nil {
+		return nil, err
+	}
+	aliasId := ctx.Get(directer.WfKeyAliasId).ToString("")
+	region := ctx.Get(directer.WfKeyRegion).ToString("")
+	requestId := ctx.Get(directer.WfKeyRequestId).ToString("")
+
+	completed := ctx.Get(directer.WfKeyAliasRolloutStep).ToInt(0)
+	if completed > 0 {
+		t.Logger.Info("alias %s rollout resumes from step %d/%d", aliasId, completed+1, len(r.Steps))
+	}
+	for i := completed; i < len(r.Steps); i++ {
+		ratio := r.Steps[i]
+		a, err := getAlias(aliasId)
+		if err != nil {
+			return nil, err
+		}
+		if err = updateAliasFleets(a, shiftWeights(original, r.OldFleetId, r.NewFleetId, ratio)); err != nil {
+			return nil, err
+		}
+		t.Logger.Info("alias %s rollout step %d/%d: %.3f of traffic to fleet %s",
+			aliasId, i+1, len(r.Steps), ratio, r.NewFleetId)
+
+		time.Sleep(time.Duration(r.StepIntervalSeconds) * time.Second)
+		if err = checkHealth(requestId, region, r); err != nil {
+			return nil, fmt.Errorf("health gate failed at rollout step %d: %v", i+1, err)
+		}
+		if err = t.saveRolloutStep(i + 1); err != nil {
+			return nil, err
+		}
+	}
+
+	return nil, nil
+}
+
+// Rollback 恢复切换前的fleet权重
+func (t *ShiftAliasWeightTask) Rollback(*directer.ExecuteContext) (output interface{}, err error) {
+	defer func() { t.RollbackPrev(output, err) }()
+
+	ctx := t.Directer.GetContext()
+	original, err := parseAssociatedFleets(ctx.Get(directer.WfKeyAliasOriginalFleets).ToString(""))
+	if err != nil {
+		return nil, err
+	}
+	aliasId := ctx.Get(directer.WfKeyAliasId).ToString("")
+	a, err := getAlias(aliasId)
+	if err != nil {
+		return nil, err
+	}
+	if err = updateAliasFleets(a, original); err != nil {
+		return nil, err
+	}
+	if err = t.saveRolloutStep(0); err != nil {
+		return nil, err
+	}
+	t.Logger.Info("alias %s rollout is rolled back to %s", aliasId, a.AssociatedFleets)
+
+	return nil, nil
+}
+
+// NewShiftAliasWeightTask 新建alias流量切换任务
+func NewShiftAliasWeightTask(meta meta.TaskMeta, directer directer.Directer, step int) components.Task {
+	t := &ShiftAliasWeightTask{
+		components.NewBaseTask(meta, directer, step),
+	}
+
+	return t
+}
@@ -0,0 +1,51 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 开始alias蓝绿切换
+package alias
+
+import (
+	"fleetmanager/db/dao"
+	"fleetmanager/workflow/components"
+	"fleetmanager/workflow/directer"
+	"fleetmanager/workflow/meta"
+	"fmt"
+)
+
+type StartAliasRolloutTask struct {
+	components.BaseTask
+}
+
+// Execute 校验alias状态并记录切换前的fleet权重, 用于失败后回滚
+func (t *StartAliasRolloutTask) Execute(*directer.ExecuteContext) (output interface{}, err error) {
+	defer func() { t.ExecNext(output, err) }()
+
+	ctx := t.Directer.GetContext()
+	aliasId := ctx.Get(directer.WfKeyAliasId).ToString("")
+	a, err := getAlias(aliasId)
+	if err != nil {
+		return nil, err
+	}
+	if a.Type != dao.AliasTypeActive {
+		return nil, fmt.Errorf("alias %s is %s, only active alias supports rollout", aliasId, a.Type)
+	}
+
+	// 工作流被接管重新执行时, 保留第一次记录的原始权重
+	if ctx.Get(directer.WfKeyAliasOriginalFleets).ToString("") != "" {
+		return nil, nil
+	}
+	if _, err = parseAssociatedFleets(a.AssociatedFleets); err != nil {
+		return nil, err
+	}
+	ctx.SetString(directer.WfKeyAliasOriginalFleets, a.AssociatedFleets)
+
+	return nil, nil
+}
+
+// NewStartAliasRolloutTask 新建开始alias蓝绿切换任务
+func NewStartAliasRolloutTask(meta meta.TaskMeta, directer directer.Directer, step int) components.Task {
+	t := &StartAliasRolloutTask{
+		components.NewBaseTask(meta, directer, step),
+	}
+
+	return t
+}
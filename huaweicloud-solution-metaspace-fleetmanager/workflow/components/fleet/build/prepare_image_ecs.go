@@ -2,7 +2,9 @@ package build
 
 import (
 	"encoding/base64"
+	"fleetmanager/api/service/constants"
 	"fleetmanager/client"
+	"fleetmanager/db/dao"
 	"fleetmanager/setting"
 	"fleetmanager/workflow/components"
 	"fleetmanager/workflow/directer"
@@ -10,9 +12,14 @@ import (
 	"github.com/huaweicloud/huaweicloud-sdk-go-obs/obs"
 	ecs "github.com/huaweicloud/huaweicloud-sdk-go-v3/services/ecs/v2"
 	ims "github.com/huaweicloud/huaweicloud-sdk-go-v3/services/ims/v2"
+	"net/url"
+	"path"
 	"strings"
 )
 
+// appUrlExpires 临时ECS下载应用包地址的有效期
+const appUrlExpires = 3600
+
 type PrePareImageEcsTask struct {
 	components.BaseTask
 }
@@ -26,8 +33,8 @@ type TmpEcs struct {
 	vpcId           string
 	subnetId        string
 	auxUrl          string
-	appBucket       string
-	appKey          string
+	appUrl          string
+	appFile         string
 	scriptUrl       string
 	tmpResourceName string
 	eipId           string
@@ -38,8 +45,6 @@ func (t *PrePareImageEcsTask) Execute(ctx *directer.ExecuteContext) (output inte
 	defer func() { t.ExecNext(output, err) }()
 	tmpEcs := TmpEcs{}
 	tmpEcs.regionId = t.Directer.GetContext().Get(directer.WfKeyBuildRegion).ToString("")
-	tmpEcs.appBucket = t.Directer.GetContext().Get(directer.WfKeyBuildBucket).ToString("")
-	tmpEcs.appKey = t.Directer.GetContext().Get(directer.WfKeyBuildKey).ToString("")
 	tmpEcs.auxUrl = setting.AuxProxyPath
 	tmpEcs.scriptUrl = setting.ScriptPath
 	tmpEcs.profileRegionId = setting.ProfileStorageRegion
@@ -57,6 +62,8 @@ func (t *PrePareImageEcsTask) Execute(ctx *directer.ExecuteContext) (output inte
 	resourceDomainId := t.Directer.GetContext().Get(directer.WfKeyResourceDomainId).ToString("")
 	buildName := t.Directer.GetContext().Get(directer.WfKeyBuildName).ToString("")
 	ltsAgency := t.Directer.GetContext().Get(directer.WfKeyIamAgencyName).ToString("")
+	buildId := t.Directer.GetContext().Get(directer.WfKeyBuildUuid).ToString("")
+	projectId := t.Directer.GetContext().Get(directer.WfKeyOriginProjectId).ToString("")
 
 	// 获取创建ECS所需信息
 	ecsClient, err := client.GetAgencyEcsClient(regionId, resourceProjectId, agencyName, resourceDomainId)
@@ -64,14 +71,20 @@ func (t *PrePareImageEcsTask) Execute(ctx *directer.ExecuteContext) (output inte
 		return nil, err
 	}
 	if ecsId == "" {
-		resourceObsClient, err := client.GetAgencyObsClient(regionId, agencyName, resourceDomainId)
+		b, err := dao.GetBuildById(buildId, projectId)
+		if err != nil {
+			return nil, err
+		}
+		tmpEcs.appUrl, tmpEcs.appFile, err = GetAppUrl(b, func() (*obs.ObsClient, error) {
+			return client.GetAgencyObsClient(regionId, agencyName, resourceDomainId)
+		})
 		if err != nil {
 			return nil, err
 		}
 
 		originObsClient, err := client.GetOriginObsClient(tmpEcs.profileRegionId)
 
-		tmpEcs.userData, err = CreateUserData(originObsClient, resourceObsClient, &tmpEcs, buildName)
+		tmpEcs.userData, err = CreateUserData(originObsClient, &tmpEcs, buildName)
 		if err != nil {
 			return nil, err
 		}
@@ -147,18 +160,37 @@ func GetSingleUrl(obsClient *obs.ObsClient, url string) (string, error) {
 	return tmpUrl, nil
 }
 
-// CreateUserData 构建注入数据，用于镜像环境构建
-func CreateUserData(originObsClient *obs.ObsClient, resourceObsClient *obs.ObsClient,
-	ecs *TmpEcs, buildName string) (string, error) {
-
-	tmpScriptUrl, err := GetSingleUrl(originObsClient, ecs.scriptUrl)
+// GetAppUrl 根据应用包来源生成临时ECS下载应用包的地址和文件名
+func GetAppUrl(b *dao.Build, obsClient func() (*obs.ObsClient, error)) (string, string, error) {
+	loc, err := dao.GetBuildStorageLocation(b)
 	if err != nil {
-		return "", nil
+		return "", "", err
+	}
+	src, err := client.NewBuildSource(loc, obsClient)
+	if err != nil {
+		return "", "", err
 	}
+	defer src.Close()
+	appUrl, err := src.DownloadUrl(appUrlExpires)
+	if err != nil {
+		return "", "", err
+	}
+
+	appFile := path.Base(loc.BucketKey)
+	if loc.SourceType == constants.BuildSourceHTTPS {
+		u, err := url.Parse(loc.Url)
+		if err != nil {
+			return "", "", err
+		}
+		appFile = path.Base(u.Path)
+	}
+	return appUrl, appFile, nil
+}
 
-	appUrl := ecs.appBucket + "/" + ecs.appKey
-	appFile := strings.Split(appUrl, "/")
-	tmpAppUrl, err := GetSingleUrl(resourceObsClient, appUrl)
+// CreateUserData 构建注入数据，用于镜像环境构建
+func CreateUserData(originObsClient *obs.ObsClient, ecs *TmpEcs, buildName string) (string, error) {
+
+	tmpScriptUrl, err := GetSingleUrl(originObsClient, ecs.scriptUrl)
 	if err != nil {
 		return "", nil
 	}
@@ -179,8 +211,8 @@ func CreateUserData(originObsClient *obs.ObsClient, resourceObsClient *obs.ObsCl
 		"\ncd /tmp" +
 		"\nwget '" + tmpScriptUrl + "' -O " + fileName +
 		"\nbash /tmp/" + fileName + " '" + ecs.regionId + "' '" +
-		tmpAppUrl + "' '" + tmpAuxUrl + "' '" + ltsIp + "' '" +
-		appFile[len(appFile)-1] + "' '" + auxFile[len(auxFile)-1] + "' '" + buildName +
+		ecs.appUrl + "' '" + tmpAuxUrl + "' '" + ltsIp + "' '" +
+		ecs.appFile + "' '" + auxFile[len(auxFile)-1] + "' '" + buildName +
 		"'\nrm -rf /tmp/" + fileName
 	byteData := []byte(userData)
 	baseData := base64.StdEncoding.EncodeToString(byteData)
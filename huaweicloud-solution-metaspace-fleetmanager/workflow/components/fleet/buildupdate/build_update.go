@@ -0,0 +1,313 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// fleet应用包原地更新公共方法
+package buildupdate
+
+import (
+	"encoding/json"
+	"fleetmanager/api/model/fleet"
+	"fleetmanager/api/params"
+	"fleetmanager/api/service/constants"
+	"fleetmanager/client"
+	"fleetmanager/db/dao"
+	"fleetmanager/logger"
+	"fleetmanager/workflow/directer"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	buildUpdateStateSucceeded  = "SUCCEEDED"
+	buildUpdateStateRolledBack = "ROLLED_BACK"
+	buildUpdateStateFailed     = "FAILED"
+	buildUpdateStateIdle       = "IDLE"
+)
+
+var (
+	// buildUpdatePollInterval 查询实例更新进度的间隔
+	buildUpdatePollInterval = 10 * time.Second
+	// instanceUpdateTimeout 单个实例的更新超时时间, 实例上的进程逐个排空并等待就绪
+	instanceUpdateTimeout = 2 * time.Hour
+)
+
+func getBuildUpdate(ctx *directer.WorkflowContext, key string) (*fleet.BuildUpdate, error) {
+	u := &fleet.BuildUpdate{}
+	if err := json.Unmarshal(ctx.Get(key).ToJson("{}"), u); err != nil {
+		return nil, err
+	}
+	if u.FleetId == "" || u.BuildId == "" {
+		return nil, fmt.Errorf("build update parameter is invalid")
+	}
+
+	return u, nil
+}
+
+func getInstances(ctx *directer.WorkflowContext, key string) ([]string, error) {
+	ids := []string{}
+	if err := json.Unmarshal(ctx.Get(key).ToJson("[]"), &ids); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func setInstances(ctx *directer.WorkflowContext, key string, ids []string) error {
+	b, err := json.Marshal(ids)
+	if err != nil {
+		return err
+	}
+	ctx.SetJson(key, string(b))
+
+	return nil
+}
+
+// saveUpdatedInstances 记录已经更新的实例并立即持久化, 工作流被接管后从记录处继续, 不重复更新
+func saveUpdatedInstances(d directer.Directer, ids []string) error {
+	if err := setInstances(d.GetContext(), directer.WfKeyBuildUpdatedInstances, ids); err != nil {
+		return err
+	}
+	return d.SaveContext()
+}
+
+// splitBatches 跳过已经完成更新的实例, 剩余实例按照batchSize分批
+func splitBatches(instances []string, completed []string, batchSize int) [][]string {
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+	done := map[string]bool{}
+	for _, id := range completed {
+		done[id] = true
+	}
+
+	var batches [][]string
+	var batch []string
+	for _, id := range instances {
+		if done[id] {
+			continue
+		}
+		batch = append(batch, id)
+		if len(batch) == batchSize {
+			batches = append(batches, batch)
+			batch = nil
+		}
+	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+
+	return batches
+}
+
+// rollInstances 按批次更新实例, 每一批全部更新成功后才开始下一批; 任一实例更新失败时等待本批其余实例结束,
+// 记录更新成功的实例后停止
+func rollInstances(log *logger.FMLogger, requestId string, region string, u *fleet.BuildUpdate,
+	batches [][]string, onDone func(instanceId string) error) error {
+	for i, batch := range batches {
+		log.Info("build %s update batch %d/%d: %v", u.BuildId, i+1, len(batches), batch)
+		r := u.InstanceBuildUpdateRequest
+		if r.SourceType == constants.BuildSourceS3 {
+			url, err := signBuildSourceUrl(u.FleetId, u.BuildId)
+			if err != nil {
+				return fmt.Errorf("sign download url of build %s error: %v", u.BuildId, err)
+			}
+			r.Url = url
+		}
+		var errs []string
+		var started []string
+		for _, id := range batch {
+			if err := startInstanceBuildUpdate(requestId, region, id, &r); err != nil {
+				errs = append(errs, fmt.Sprintf("start build update on instance %s error: %v", id, err))
+				continue
+			}
+			started = append(started, id)
+		}
+		for _, id := range started {
+			if err := waitInstanceBuildUpdate(log, requestId, region, id, u); err != nil {
+				errs = append(errs, err.Error())
+				continue
+			}
+			if err := onDone(id); err != nil {
+				return err
+			}
+		}
+		if len(errs) > 0 {
+			return fmt.Errorf("%s", strings.Join(errs, "; "))
+		}
+	}
+	return nil
+}
+
+// waitInstanceBuildUpdate 等待实例更新完成, 实例回滚或者失败时返回错误
+func waitInstanceBuildUpdate(log *logger.FMLogger, requestId string, region string, instanceId string,
+	u *fleet.BuildUpdate) error {
+	deadline := time.Now().Add(instanceUpdateTimeout)
+	for time.Now().Before(deadline) {
+		st, err := showInstanceBuildUpdate(requestId, region, u.FleetId, instanceId)
+		if err != nil {
+			// 网络抖动不影响实例上的更新, 继续查询直到超时
+			log.Warn("show build update on instance %s error: %v", instanceId, err)
+		} else if st.BuildId != u.BuildId || st.State == buildUpdateStateIdle {
+			return fmt.Errorf("build update of instance %s is lost, current build %s state %s",
+				instanceId, st.BuildId, st.State)
+		} else {
+			switch st.State {
+			case buildUpdateStateSucceeded:
+				log.Info("instance %s is updated to build %s", instanceId, u.BuildId)
+				return nil
+			case buildUpdateStateRolledBack, buildUpdateStateFailed:
+				return fmt.Errorf("build update of instance %s is %s: %s", instanceId, st.State, st.Message)
+			}
+		}
+		time.Sleep(buildUpdatePollInterval)
+	}
+
+	return fmt.Errorf("build update of instance %s timeout", instanceId)
+}
+
+// listFleetInstances 查询fleet下有ACTIVE进程的实例, 同一实例只保留一个
+var listFleetInstances = func(requestId string, region string, fleetId string) ([]string, error) {
+	url := client.GetServiceEndpoint(client.ServiceNameAPPGW, region) + constants.APPGWMonitorInstancesUrl
+	req := client.NewRequest(client.ServiceNameAPPGW, url, http.MethodGet, nil)
+	req.SetQuery(params.QueryFleetId, fleetId)
+	req.SetHeader(map[string]string{
+		logger.RequestId: requestId,
+	})
+	code, rsp, err := req.DoRequest()
+	if err != nil {
+		return nil, err
+	}
+	if code != http.StatusOK {
+		return nil, fmt.Errorf("list instances failed, code: %d, rsp: %s", code, rsp)
+	}
+
+	obj := &fleet.ListInstancesFromAppGW{}
+	if err := json.Unmarshal(rsp, obj); err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	ids := []string{}
+	for _, ins := range obj.Instances {
+		if ins.InstanceId == "" || seen[ins.InstanceId] {
+			continue
+		}
+		seen[ins.InstanceId] = true
+		ids = append(ids, ins.InstanceId)
+	}
+	return ids, nil
+}
+
+var startInstanceBuildUpdate = func(requestId string, region string, instanceId string,
+	r *fleet.InstanceBuildUpdateRequest) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	url := client.GetServiceEndpoint(client.ServiceNameAPPGW, region) +
+		fmt.Sprintf(constants.InstanceBuildUpdateUrlPattern, instanceId)
+	req := client.NewRequest(client.ServiceNameAPPGW, url, http.MethodPost, b)
+	req.SetHeader(map[string]string{
+		logger.RequestId: requestId,
+	})
+	code, rsp, err := req.DoRequest()
+	if err != nil {
+		return err
+	}
+	if code != http.StatusAccepted {
+		return fmt.Errorf("code: %d, rsp: %s", code, rsp)
+	}
+
+	return nil
+}
+
+var showInstanceBuildUpdate = func(requestId string, region string, fleetId string,
+	instanceId string) (*fleet.InstanceBuildUpdateStatus, error) {
+	url := client.GetServiceEndpoint(client.ServiceNameAPPGW, region) +
+		fmt.Sprintf(constants.InstanceBuildUpdateUrlPattern, instanceId)
+	req := client.NewRequest(client.ServiceNameAPPGW, url, http.MethodGet, nil)
+	req.SetQuery(params.QueryFleetId, fleetId)
+	req.SetHeader(map[string]string{
+		logger.RequestId: requestId,
+	})
+	code, rsp, err := req.DoRequest()
+	if err != nil {
+		return nil, err
+	}
+	if code != http.StatusOK {
+		return nil, fmt.Errorf("show build update failed, code: %d, rsp: %s", code, rsp)
+	}
+
+	obj := &fleet.InstanceBuildUpdateStatus{}
+	if err := json.Unmarshal(rsp, obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+var getFleet = func(fleetId string) (*dao.Fleet, error) {
+	return dao.GetFleetStorage().Get(dao.Filters{"Id": fleetId})
+}
+
+var updateFleetBuild = func(f *dao.Fleet, buildId string) error {
+	f.BuildId = buildId
+	return dao.GetFleetStorage().Update(f, "BuildId")
+}
+
+var getBuild = func(buildId string, projectId string) (*dao.Build, error) {
+	return dao.GetBuildById(buildId, projectId)
+}
+
+// getBuildImage 获取应用包在fleet所在区域的镜像, 不存在时返回空
+var getBuildImage = func(buildId string, region string, projectId string) (string, error) {
+	return dao.GetBuildImage(buildId, region, projectId)
+}
+
+// updateScalingGroupImage 替换fleet伸缩组的镜像, 之后扩容的实例从新镜像启动
+var updateScalingGroupImage = func(requestId string, f *dao.Fleet, imageId string) error {
+	group, err := dao.GetScalingGroupStorage().GetOne(dao.Filters{"FleetId": f.Id})
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(fleet.UpdateScalingGroupRequest{ImageId: &imageId})
+	if err != nil {
+		return err
+	}
+	url := client.GetServiceEndpoint(client.ServiceNameAASS, f.Region) +
+		fmt.Sprintf(constants.UpdateScalingGroupUrlPattern, group.ResourceProjectId, group.Id)
+	req := client.NewRequest(client.ServiceNameAASS, url, http.MethodPut, b)
+	req.SetHeader(map[string]string{
+		logger.RequestId: requestId,
+	})
+	code, rsp, err := req.DoRequest()
+	if err != nil {
+		return err
+	}
+	if code < http.StatusOK || code >= http.StatusBadRequest {
+		return fmt.Errorf("update image of scaling group %s failed, code: %d, rsp: %s", group.Id, code, rsp)
+	}
+	return nil
+}
+
+// signBuildSourceUrl S3来源的应用包每批次重新生成临时下载地址, 凭证不写入工作流参数
+var signBuildSourceUrl = func(fleetId string, buildId string) (string, error) {
+	f, err := getFleet(fleetId)
+	if err != nil {
+		return "", err
+	}
+	b, err := getBuild(buildId, f.ProjectId)
+	if err != nil {
+		return "", err
+	}
+	loc, err := dao.GetBuildStorageLocation(b)
+	if err != nil {
+		return "", err
+	}
+	src, err := client.NewBuildSource(loc, nil)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+	return src.DownloadUrl(int(instanceUpdateTimeout.Seconds()))
+}
@@ -0,0 +1,114 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 完成fleet应用包原地更新
+package buildupdate
+
+import (
+	"fleetmanager/workflow/components"
+	"fleetmanager/workflow/directer"
+	"fleetmanager/workflow/meta"
+	"fmt"
+)
+
+type BuildUpdateFinishTask struct {
+	components.BaseTask
+}
+
+// Execute 伸缩组切换到新应用包的镜像, 之后扩容的实例从新镜像启动; 切换前扩容的实例不在开始时记录的列表中,
+// 重新查询实例并更新剩余的实例, 最后记录fleet当前的应用包
+func (t *BuildUpdateFinishTask) Execute(*directer.ExecuteContext) (output interface{}, err error) {
+	defer func() { t.ExecNext(output, err) }()
+
+	ctx := t.Directer.GetContext()
+	requestId := ctx.Get(directer.WfKeyRequestId).ToString("")
+	region := ctx.Get(directer.WfKeyRegion).ToString("")
+	u, err := getBuildUpdate(ctx, directer.WfKeyBuildUpdate)
+	if err != nil {
+		return nil, err
+	}
+	f, err := getFleet(u.FleetId)
+	if err != nil {
+		return nil, err
+	}
+	image, err := getBuildImage(u.BuildId, f.Region, f.ProjectId)
+	if err != nil {
+		return nil, err
+	}
+	if image == "" {
+		return nil, fmt.Errorf("build %s has no image in region %s", u.BuildId, f.Region)
+	}
+	if err = updateScalingGroupImage(requestId, f, image); err != nil {
+		return nil, err
+	}
+	t.Logger.Info("scaling group of fleet %s is switched to image %s", f.Id, image)
+
+	ids, err := listFleetInstances(requestId, region, u.FleetId)
+	if err != nil {
+		return nil, err
+	}
+	completed, err := getInstances(ctx, directer.WfKeyBuildUpdatedInstances)
+	if err != nil {
+		return nil, err
+	}
+	batches := splitBatches(ids, completed, u.MaxUnavailable)
+	if len(batches) > 0 {
+		t.Logger.Info("fleet %s has %d batches of new instances to update", f.Id, len(batches))
+	}
+	err = rollInstances(t.Logger, requestId, region, u, batches, func(instanceId string) error {
+		completed = append(completed, instanceId)
+		return saveUpdatedInstances(t.Directer, completed)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = updateFleetBuild(f, u.BuildId); err != nil {
+		return nil, err
+	}
+	t.Logger.Info("fleet %s is updated to build %s", f.Id, u.BuildId)
+
+	return nil, nil
+}
+
+// Rollback 伸缩组切换回原应用包的镜像, 已经更新的实例由分批更新任务回滚
+func (t *BuildUpdateFinishTask) Rollback(*directer.ExecuteContext) (output interface{}, err error) {
+	defer func() { t.RollbackPrev(output, err) }()
+
+	ctx := t.Directer.GetContext()
+	if ctx.Get(directer.WfKeyBuildUpdateOriginal).ToString("") == "" {
+		t.Logger.Warn("original build is not recorded, skip restoring image of scaling group")
+		return nil, nil
+	}
+	original, err := getBuildUpdate(ctx, directer.WfKeyBuildUpdateOriginal)
+	if err != nil {
+		return nil, err
+	}
+	f, err := getFleet(original.FleetId)
+	if err != nil {
+		return nil, err
+	}
+	image, err := getBuildImage(original.BuildId, f.Region, f.ProjectId)
+	if err != nil {
+		return nil, err
+	}
+	if image == "" {
+		t.Logger.Warn("original build %s has no image in region %s, skip restoring image of scaling group",
+			original.BuildId, f.Region)
+		return nil, nil
+	}
+	if err = updateScalingGroupImage(ctx.Get(directer.WfKeyRequestId).ToString(""), f, image); err != nil {
+		return nil, err
+	}
+	t.Logger.Info("scaling group of fleet %s is restored to image %s", f.Id, image)
+
+	return nil, nil
+}
+
+// NewBuildUpdateFinishTask 新建完成应用包原地更新任务
+func NewBuildUpdateFinishTask(meta meta.TaskMeta, directer directer.Directer, step int) components.Task {
+	t := &BuildUpdateFinishTask{
+		components.NewBaseTask(meta, directer, step),
+	}
+
+	return t
+}
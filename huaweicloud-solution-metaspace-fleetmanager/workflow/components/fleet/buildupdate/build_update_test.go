@@ -0,0 +1,307 @@
+package buildupdate
+
+import (
+	"fleetmanager/api/model/fleet"
+	"fleetmanager/config"
+	"fleetmanager/db/dao"
+	"fleetmanager/logger"
+	mockdirecter "fleetmanager/mocks/workflow/directer"
+	"fleetmanager/workflow/components"
+	"fleetmanager/workflow/directer"
+	"reflect"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+)
+
+func TestSplitBatches(t *testing.T) {
+	tests := []struct {
+		name      string
+		instances []string
+		completed []string
+		batchSize int
+		expected  [][]string
+	}{
+		{
+			name:      "one instance per batch",
+			instances: []string{"i1", "i2", "i3"},
+			batchSize: 1,
+			expected:  [][]string{{"i1"}, {"i2"}, {"i3"}},
+		},
+		{
+			name:      "last batch is smaller",
+			instances: []string{"i1", "i2", "i3"},
+			batchSize: 2,
+			expected:  [][]string{{"i1", "i2"}, {"i3"}},
+		},
+		{
+			name:      "completed instances are skipped",
+			instances: []string{"i1", "i2", "i3", "i4"},
+			completed: []string{"i1", "i3"},
+			batchSize: 2,
+			expected:  [][]string{{"i2", "i4"}},
+		},
+		{
+			name:      "invalid batch size falls back to one",
+			instances: []string{"i1", "i2"},
+			batchSize: 0,
+			expected:  [][]string{{"i1"}, {"i2"}},
+		},
+		{
+			name:      "all instances completed",
+			instances: []string{"i1"},
+			completed: []string{"i1"},
+			batchSize: 1,
+			expected:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitBatches(tt.instances, tt.completed, tt.batchSize)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("splitBatches() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRollingUpdateBuild(t *testing.T) {
+	originStart := startInstanceBuildUpdate
+	originShow := showInstanceBuildUpdate
+	originInterval := buildUpdatePollInterval
+	defer func() {
+		startInstanceBuildUpdate = originStart
+		showInstanceBuildUpdate = originShow
+		buildUpdatePollInterval = originInterval
+	}()
+	buildUpdatePollInterval = 0
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	tests := []struct {
+		name            string
+		completed       string
+		results         map[string]string
+		expectedErr     bool
+		expectedStarted []string
+		expectedDone    string
+	}{
+		{
+			name:            "all instances updated",
+			results:         map[string]string{"i1": "SUCCEEDED", "i2": "SUCCEEDED", "i3": "SUCCEEDED"},
+			expectedErr:     false,
+			expectedStarted: []string{"i1", "i2", "i3"},
+			expectedDone:    `["i1","i2","i3"]`,
+		},
+		{
+			name:            "resume skips completed instances",
+			completed:       `["i1","i2"]`,
+			results:         map[string]string{"i3": "SUCCEEDED"},
+			expectedErr:     false,
+			expectedStarted: []string{"i3"},
+			expectedDone:    `["i1","i2","i3"]`,
+		},
+		{
+			name:            "rolled back instance stops the update",
+			results:         map[string]string{"i1": "SUCCEEDED", "i2": "ROLLED_BACK"},
+			expectedErr:     true,
+			expectedStarted: []string{"i1", "i2"},
+			expectedDone:    `["i1"]`,
+		},
+		{
+			name:            "batch finishes before stopping on failure",
+			results:         map[string]string{"i1": "FAILED", "i2": "SUCCEEDED", "i3": "SUCCEEDED"},
+			expectedErr:     true,
+			expectedStarted: []string{"i1", "i2"},
+			expectedDone:    `["i2"]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var started []string
+			startInstanceBuildUpdate = func(_ string, _ string, instanceId string,
+				_ *fleet.InstanceBuildUpdateRequest) error {
+				started = append(started, instanceId)
+				return nil
+			}
+			showInstanceBuildUpdate = func(_ string, _ string, _ string,
+				instanceId string) (*fleet.InstanceBuildUpdateStatus, error) {
+				return &fleet.InstanceBuildUpdateStatus{InstanceId: instanceId, BuildId: "build-new",
+					State: tt.results[instanceId]}, nil
+			}
+
+			context := &directer.WorkflowContext{Config: config.NewConfig(map[string]interface{}{
+				directer.WfKeyBuildUpdate: &fleet.BuildUpdate{
+					InstanceBuildUpdateRequest: fleet.InstanceBuildUpdateRequest{FleetId: "fleet",
+						BuildId: "build-new"},
+					MaxUnavailable: 2,
+				},
+				directer.WfKeyBuildUpdateInstances: []string{"i1", "i2", "i3"},
+			})}
+			if tt.completed != "" {
+				context.SetJson(directer.WfKeyBuildUpdatedInstances, tt.completed)
+			}
+			mockDirecter := mockdirecter.NewMockDirecter(mockCtrl)
+			mockDirecter.EXPECT().GetContext().Return(context).AnyTimes()
+			var saved []string
+			mockDirecter.EXPECT().SaveContext().DoAndReturn(func() error {
+				saved = append(saved, string(context.Get(directer.WfKeyBuildUpdatedInstances).ToJson("[]")))
+				return nil
+			}).AnyTimes()
+			var result *directer.ExecuteContext
+			mockDirecter.EXPECT().Process(gomock.Any()).Do(func(ctx *directer.ExecuteContext) { result = ctx })
+
+			task := RollingUpdateBuildTask{
+				components.BaseTask{
+					Logger:   logger.NewDebugLogger(),
+					Directer: mockDirecter,
+				},
+			}
+			_, _ = task.Execute(nil)
+
+			if (result.Err != nil) != tt.expectedErr {
+				t.Errorf("task error = %v, expectedErr %v", result.Err, tt.expectedErr)
+			}
+			if !reflect.DeepEqual(started, tt.expectedStarted) {
+				t.Errorf("started instances = %v, want %v", started, tt.expectedStarted)
+			}
+			if done := string(context.Get(directer.WfKeyBuildUpdatedInstances).ToJson("[]")); done != tt.expectedDone {
+				t.Errorf("updated instances = %s, want %s", done, tt.expectedDone)
+			}
+			if len(saved) == 0 || saved[len(saved)-1] != tt.expectedDone {
+				t.Errorf("saved updated instances = %v, want last %s", saved, tt.expectedDone)
+			}
+		})
+	}
+}
+
+func TestBuildUpdateFinish(t *testing.T) {
+	originStart := startInstanceBuildUpdate
+	originShow := showInstanceBuildUpdate
+	originInterval := buildUpdatePollInterval
+	originList := listFleetInstances
+	originGetFleet := getFleet
+	originUpdateFleet := updateFleetBuild
+	originImage := getBuildImage
+	originUpdateImage := updateScalingGroupImage
+	defer func() {
+		startInstanceBuildUpdate = originStart
+		showInstanceBuildUpdate = originShow
+		buildUpdatePollInterval = originInterval
+		listFleetInstances = originList
+		getFleet = originGetFleet
+		updateFleetBuild = originUpdateFleet
+		getBuildImage = originImage
+		updateScalingGroupImage = originUpdateImage
+	}()
+	buildUpdatePollInterval = 0
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	tests := []struct {
+		name            string
+		image           string
+		results         map[string]string
+		expectedErr     bool
+		expectedImage   string
+		expectedStarted []string
+		expectedBuild   string
+	}{
+		{
+			name:            "new instances are updated before finishing",
+			image:           "image-new",
+			results:         map[string]string{"i4": "SUCCEEDED"},
+			expectedErr:     false,
+			expectedImage:   "image-new",
+			expectedStarted: []string{"i4"},
+			expectedBuild:   "build-new",
+		},
+		{
+			name:            "build without image",
+			expectedErr:     true,
+			expectedStarted: nil,
+		},
+		{
+			name:            "failed new instance keeps fleet build",
+			image:           "image-new",
+			results:         map[string]string{"i4": "FAILED"},
+			expectedErr:     true,
+			expectedImage:   "image-new",
+			expectedStarted: []string{"i4"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var started []string
+			var switched, build string
+			startInstanceBuildUpdate = func(_ string, _ string, instanceId string,
+				_ *fleet.InstanceBuildUpdateRequest) error {
+				started = append(started, instanceId)
+				return nil
+			}
+			showInstanceBuildUpdate = func(_ string, _ string, _ string,
+				instanceId string) (*fleet.InstanceBuildUpdateStatus, error) {
+				return &fleet.InstanceBuildUpdateStatus{InstanceId: instanceId, BuildId: "build-new",
+					State: tt.results[instanceId]}, nil
+			}
+			listFleetInstances = func(_ string, _ string, _ string) ([]string, error) {
+				return []string{"i1", "i2", "i3", "i4"}, nil
+			}
+			getFleet = func(fleetId string) (*dao.Fleet, error) {
+				return &dao.Fleet{Id: fleetId, BuildId: "build-old", Region: "region"}, nil
+			}
+			updateFleetBuild = func(_ *dao.Fleet, buildId string) error {
+				build = buildId
+				return nil
+			}
+			getBuildImage = func(_ string, _ string, _ string) (string, error) {
+				return tt.image, nil
+			}
+			updateScalingGroupImage = func(_ string, _ *dao.Fleet, imageId string) error {
+				switched = imageId
+				return nil
+			}
+
+			context := &directer.WorkflowContext{Config: config.NewConfig(map[string]interface{}{
+				directer.WfKeyBuildUpdate: &fleet.BuildUpdate{
+					InstanceBuildUpdateRequest: fleet.InstanceBuildUpdateRequest{FleetId: "fleet",
+						BuildId: "build-new"},
+					MaxUnavailable: 2,
+				},
+				directer.WfKeyBuildUpdateInstances: []string{"i1", "i2", "i3"},
+			})}
+			context.SetJson(directer.WfKeyBuildUpdatedInstances, `["i1","i2","i3"]`)
+			mockDirecter := mockdirecter.NewMockDirecter(mockCtrl)
+			mockDirecter.EXPECT().GetContext().Return(context).AnyTimes()
+			mockDirecter.EXPECT().SaveContext().Return(nil).AnyTimes()
+			var result *directer.ExecuteContext
+			mockDirecter.EXPECT().Process(gomock.Any()).Do(func(ctx *directer.ExecuteContext) { result = ctx })
+
+			task := BuildUpdateFinishTask{
+				components.BaseTask{
+					Logger:   logger.NewDebugLogger(),
+					Directer: mockDirecter,
+				},
+			}
+			_, _ = task.Execute(nil)
+
+			if (result.Err != nil) != tt.expectedErr {
+				t.Errorf("task error = %v, expectedErr %v", result.Err, tt.expectedErr)
+			}
+			if switched != tt.expectedImage {
+				t.Errorf("scaling group image = %s, want %s", switched, tt.expectedImage)
+			}
+			if !reflect.DeepEqual(started, tt.expectedStarted) {
+				t.Errorf("started instances = %v, want %v", started, tt.expectedStarted)
+			}
+			if build != tt.expectedBuild {
+				t.Errorf("fleet build = %s, want %s", build, tt.expectedBuild)
+			}
+		})
+	}
+}
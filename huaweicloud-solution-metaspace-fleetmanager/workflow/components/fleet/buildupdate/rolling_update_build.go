@@ -0,0 +1,93 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 分批原地更新实例的应用包
+package buildupdate
+
+import (
+	"fleetmanager/workflow/components"
+	"fleetmanager/workflow/directer"
+	"fleetmanager/workflow/meta"
+)
+
+type RollingUpdateBuildTask struct {
+	components.BaseTask
+}
+
+// Execute 每批最多MaxUnavailable个实例同时更新, 每个实例完成后持久化到工作流参数, 工作流被接管后跳过
+func (t *RollingUpdateBuildTask) Execute(*directer.ExecuteContext) (output interface{}, err error) {
+	defer func() { t.ExecNext(output, err) }()
+
+	ctx := t.Directer.GetContext()
+	u, err := getBuildUpdate(ctx, directer.WfKeyBuildUpdate)
+	if err != nil {
+		return nil, err
+	}
+	instances, err := getInstances(ctx, directer.WfKeyBuildUpdateInstances)
+	if err != nil {
+		return nil, err
+	}
+	completed, err := getInstances(ctx, directer.WfKeyBuildUpdatedInstances)
+	if err != nil {
+		return nil, err
+	}
+
+	batches := splitBatches(instances, completed, u.MaxUnavailable)
+	err = rollInstances(t.Logger, ctx.Get(directer.WfKeyRequestId).ToString(""),
+		ctx.Get(directer.WfKeyRegion).ToString(""), u, batches, func(instanceId string) error {
+			completed = append(completed, instanceId)
+			return saveUpdatedInstances(t.Directer, completed)
+		})
+
+	return nil, err
+}
+
+// Rollback 将已经更新的实例更新回原来的应用包
+func (t *RollingUpdateBuildTask) Rollback(*directer.ExecuteContext) (output interface{}, err error) {
+	defer func() { t.RollbackPrev(output, err) }()
+
+	ctx := t.Directer.GetContext()
+	if ctx.Get(directer.WfKeyBuildUpdateOriginal).ToString("") == "" {
+		t.Logger.Warn("original build is not recorded, skip rolling back updated instances")
+		return nil, nil
+	}
+	original, err := getBuildUpdate(ctx, directer.WfKeyBuildUpdateOriginal)
+	if err != nil {
+		return nil, err
+	}
+	completed, err := getInstances(ctx, directer.WfKeyBuildUpdatedInstances)
+	if err != nil {
+		return nil, err
+	}
+
+	remaining := append([]string{}, completed...)
+	err = rollInstances(t.Logger, ctx.Get(directer.WfKeyRequestId).ToString(""),
+		ctx.Get(directer.WfKeyRegion).ToString(""), original, splitBatches(completed, nil, original.MaxUnavailable),
+		func(instanceId string) error {
+			remaining = removeInstance(remaining, instanceId)
+			return saveUpdatedInstances(t.Directer, remaining)
+		})
+	if err == nil {
+		t.Logger.Info("%d instances are rolled back to build %s", len(completed), original.BuildId)
+	}
+
+	return nil, err
+}
+
+func removeInstance(ids []string, instanceId string) []string {
+	left := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id != instanceId {
+			left = append(left, id)
+		}
+	}
+	return left
+}
+
+// NewRollingUpdateBuildTask 新建分批原地更新实例任务
+func NewRollingUpdateBuildTask(meta meta.TaskMeta, directer directer.Directer, step int) components.Task {
+	t := &RollingUpdateBuildTask{
+		components.NewBaseTask(meta, directer, step),
+	}
+
+	return t
+}
@@ -0,0 +1,99 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 开始fleet应用包原地更新
+package buildupdate
+
+import (
+	"encoding/json"
+	"fleetmanager/api/model/fleet"
+	"fleetmanager/db/dao"
+	"fleetmanager/workflow/components"
+	"fleetmanager/workflow/directer"
+	"fleetmanager/workflow/meta"
+	"fmt"
+)
+
+type StartBuildUpdateTask struct {
+	components.BaseTask
+}
+
+// Execute 校验fleet状态, 记录待更新的实例以及更新前的应用包, 用于失败后回滚
+func (t *StartBuildUpdateTask) Execute(*directer.ExecuteContext) (output interface{}, err error) {
+	defer func() { t.ExecNext(output, err) }()
+
+	ctx := t.Directer.GetContext()
+	u, err := getBuildUpdate(ctx, directer.WfKeyBuildUpdate)
+	if err != nil {
+		return nil, err
+	}
+	f, err := getFleet(u.FleetId)
+	if err != nil {
+		return nil, err
+	}
+	if f.State != dao.FleetStateActive {
+		return nil, fmt.Errorf("fleet %s is %s, only active fleet supports build update", f.Id, f.State)
+	}
+	// 更新完成后伸缩组切换到新应用包的镜像, 镜像不存在时不允许更新
+	image, err := getBuildImage(u.BuildId, f.Region, f.ProjectId)
+	if err != nil {
+		return nil, err
+	}
+	if image == "" {
+		return nil, fmt.Errorf("build %s has no image in region %s", u.BuildId, f.Region)
+	}
+
+	// 工作流被接管重新执行时, 保留第一次记录的实例列表
+	if ctx.Get(directer.WfKeyBuildUpdateInstances).ToString("") != "" {
+		return nil, nil
+	}
+	if err = t.recordOriginalBuild(ctx, f, u); err != nil {
+		return nil, err
+	}
+	ids, err := listFleetInstances(ctx.Get(directer.WfKeyRequestId).ToString(""),
+		ctx.Get(directer.WfKeyRegion).ToString(""), u.FleetId)
+	if err != nil {
+		return nil, err
+	}
+	if err = setInstances(ctx, directer.WfKeyBuildUpdateInstances, ids); err != nil {
+		return nil, err
+	}
+	t.Logger.Info("fleet %s has %d instances to update to build %s", u.FleetId, len(ids), u.BuildId)
+
+	return nil, nil
+}
+
+// recordOriginalBuild 更新前的应用包已经被删除时不记录, 失败后不回滚已更新的实例
+func (t *StartBuildUpdateTask) recordOriginalBuild(ctx *directer.WorkflowContext, f *dao.Fleet,
+	u *fleet.BuildUpdate) error {
+	b, err := getBuild(f.BuildId, f.ProjectId)
+	if err != nil {
+		t.Logger.Warn("original build %s of fleet %s is not available: %v", f.BuildId, f.Id, err)
+		return nil
+	}
+
+	original := *u
+	original.BuildId = b.Id
+	original.Bucket = b.StorageBucketName
+	original.Object = b.StorageKey
+	original.Region = b.StorageRegion
+	original.Checksum = b.Checksum
+	original.SourceType = b.SourceType
+	original.Url = b.SourceUrl
+	original.Path = b.SourcePath
+	data, err := json.Marshal(&original)
+	if err != nil {
+		return err
+	}
+	ctx.SetJson(directer.WfKeyBuildUpdateOriginal, string(data))
+
+	return nil
+}
+
+// NewStartBuildUpdateTask 新建开始应用包原地更新任务
+func NewStartBuildUpdateTask(meta meta.TaskMeta, directer directer.Directer, step int) components.Task {
+	t := &StartBuildUpdateTask{
+		components.NewBaseTask(meta, directer, step),
+	}
+
+	return t
+}
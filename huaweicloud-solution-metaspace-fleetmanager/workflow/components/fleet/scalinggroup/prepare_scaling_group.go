@@ -124,6 +124,13 @@ func (t *PrepareScalingGroupTask) makeRuntimeConfiguration() (fleet.RuntimeConfi
 		return conf, err
 	}
 
+	if pp := t.Directer.GetContext().Get(directer.WfKeyPlacementPolicy).ToString(""); pp != "" {
+		conf.PlacementPolicy = &fleet.PlacementPolicy{}
+		if err = json.Unmarshal([]byte(pp), conf.PlacementPolicy); err != nil {
+			return conf, err
+		}
+	}
+
 	return conf, nil
 }
 
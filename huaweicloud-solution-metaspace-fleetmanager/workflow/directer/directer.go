@@ -11,4 +11,6 @@ type Directer interface {
 	Process(ctx *ExecuteContext)
 	GetLogger() *logger.FMLogger
 	GetContext() *WorkflowContext
+	// SaveContext 持久化当前上下文, 长时间执行的任务记录阶段性进度, 工作流被接管后从该进度继续
+	SaveContext() error
 }
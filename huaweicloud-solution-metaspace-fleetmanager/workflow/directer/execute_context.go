@@ -20,6 +20,10 @@ type ExecuteContext struct {
 	Err        error
 	Direction  WorkflowDirection
 	RetryTimes int
+	// RetryDelay 任务重试前需要等待的时间(秒)
+	RetryDelay int
+	// Attempt 产生该事件的任务实例代次, 任务超时后旧实例产生的事件将被忽略
+	Attempt int
 }
 
 // Ended ctx结束
@@ -33,6 +33,7 @@ const (
 	WfKeyProcessConfiguration = "runtime_configuration.process_configurations"
 	WfKeyActivationTimeout    = "runtime_configuration.server_session_activation_timeout_seconds"
 	WfKeyConcurrentPerProcess = "runtime_configuration.max_concurrent_server_sessions_per_process"
+	WfKeyPlacementPolicy      = "runtime_configuration.placement_policy"
 	WfKeyInboundPermissions   = "inbound_permissions"
 
 	WfKeyBuild             = "build"
@@ -68,4 +69,14 @@ const (
 	WfDnsConfig              = "dns_config"
 	WfKeyRetryTimes          = "retry_times"
 	WfKeyRequestId           = "request_id"
+
+	WfKeyAliasId             = "alias_id"
+	WfKeyAliasRollout        = "alias_rollout"
+	WfKeyAliasOriginalFleets = "alias_original_fleets"
+	WfKeyAliasRolloutStep    = "alias_rollout_step"
+
+	WfKeyBuildUpdate           = "build_update"
+	WfKeyBuildUpdateOriginal   = "build_update_original"
+	WfKeyBuildUpdateInstances  = "build_update_instances"
+	WfKeyBuildUpdatedInstances = "build_updated_instances"
 )
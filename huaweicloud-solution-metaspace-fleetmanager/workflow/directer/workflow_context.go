@@ -28,6 +28,14 @@ func (ctx *WorkflowContext) syncParameter() {
 	ctx.Parameter = parameter
 }
 
+// GetParameter 获取当前参数快照
+func (ctx *WorkflowContext) GetParameter() []byte {
+	ctx.lock.RLock()
+	defer ctx.lock.RUnlock()
+
+	return ctx.Parameter
+}
+
 // SetString 设置string配置
 func (ctx *WorkflowContext) SetString(k string, v string) {
 	if err := ctx.Set(k, v); err != nil {
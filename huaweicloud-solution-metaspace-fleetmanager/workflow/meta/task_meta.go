@@ -3,7 +3,10 @@
 // task_meta
 package meta
 
-import "math"
+import (
+	"math"
+	"math/rand"
+)
 
 const (
 	LogicFixed    = "fixed"
@@ -11,9 +14,11 @@ const (
 )
 
 type RetryPolicy struct {
-	Repeat       int    `json:"repeat"`
-	Logic        string `json:"logic"`
-	DelaySeconds int    `json:"delay_seconds"`
+	Repeat          int     `json:"repeat"`
+	Logic           string  `json:"logic"`
+	DelaySeconds    int     `json:"delay_seconds"`
+	MaxDelaySeconds int     `json:"max_delay_seconds,omitempty"`
+	Jitter          float64 `json:"jitter,omitempty"`
 }
 
 type OnFailure struct {
@@ -27,16 +32,32 @@ type TaskMeta struct {
 	TaskType        string    `json:"task_type"`
 	ExecuteFailure  OnFailure `json:"execute_failure"`
 	RollbackFailure OnFailure `json:"rollback_failure"`
+	DependsOn       []string  `json:"depends_on,omitempty"`
+	TimeoutSeconds  int       `json:"timeout_seconds,omitempty"`
 }
 
-// GetRetryDelay 获取重试时间
+// GetRetryDelay 获取第retryTimes次(从1开始)重试的等待时间, 指数退避时等待时间为DelaySeconds*2^(retryTimes-1),
+// 若配置了MaxDelaySeconds则不超过该值, 若配置了Jitter则在[delay*(1-Jitter), delay]范围内随机取值
 func (p *RetryPolicy) GetRetryDelay(retryTimes int) int {
+	var delay float64
 	switch p.Logic {
 	case LogicFixed:
-		return p.DelaySeconds
+		delay = float64(p.DelaySeconds)
 	case LogicExponent:
-		return int(math.Pow(float64(p.DelaySeconds), float64(retryTimes)))
+		if retryTimes < 1 {
+			retryTimes = 1
+		}
+		delay = float64(p.DelaySeconds) * math.Pow(2, float64(retryTimes-1))
 	default:
 		return 0
 	}
+
+	if p.MaxDelaySeconds > 0 && delay > float64(p.MaxDelaySeconds) {
+		delay = float64(p.MaxDelaySeconds)
+	}
+	if p.Jitter > 0 {
+		delay -= delay * math.Min(p.Jitter, 1) * rand.Float64()
+	}
+
+	return int(math.Round(delay))
 }
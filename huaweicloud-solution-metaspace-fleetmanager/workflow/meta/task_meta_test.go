@@ -0,0 +1,67 @@
+package meta
+
+import "testing"
+
+func TestGetRetryDelay(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      RetryPolicy
+		retryTimes  int
+		expectedMin int
+		expectedMax int
+	}{
+		{
+			name:        "fixed",
+			policy:      RetryPolicy{Logic: LogicFixed, DelaySeconds: 5},
+			retryTimes:  3,
+			expectedMin: 5,
+			expectedMax: 5,
+		},
+		{
+			name:        "exponent first retry",
+			policy:      RetryPolicy{Logic: LogicExponent, DelaySeconds: 5},
+			retryTimes:  1,
+			expectedMin: 5,
+			expectedMax: 5,
+		},
+		{
+			name:        "exponent backoff",
+			policy:      RetryPolicy{Logic: LogicExponent, DelaySeconds: 5},
+			retryTimes:  4,
+			expectedMin: 40,
+			expectedMax: 40,
+		},
+		{
+			name:        "exponent capped by max delay",
+			policy:      RetryPolicy{Logic: LogicExponent, DelaySeconds: 5, MaxDelaySeconds: 60},
+			retryTimes:  10,
+			expectedMin: 60,
+			expectedMax: 60,
+		},
+		{
+			name:        "jitter",
+			policy:      RetryPolicy{Logic: LogicExponent, DelaySeconds: 5, MaxDelaySeconds: 60, Jitter: 0.5},
+			retryTimes:  10,
+			expectedMin: 30,
+			expectedMax: 60,
+		},
+		{
+			name:        "unknown logic",
+			policy:      RetryPolicy{Logic: "unknown", DelaySeconds: 5},
+			retryTimes:  1,
+			expectedMin: 0,
+			expectedMax: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				delay := tt.policy.GetRetryDelay(tt.retryTimes)
+				if delay < tt.expectedMin || delay > tt.expectedMax {
+					t.Fatalf("GetRetryDelay() = %d, want [%d, %d]", delay, tt.expectedMin, tt.expectedMax)
+				}
+			}
+		})
+	}
+}
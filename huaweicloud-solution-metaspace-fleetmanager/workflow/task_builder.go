@@ -5,7 +5,9 @@ package workflow
 
 import (
 	"fleetmanager/workflow/components"
+	"fleetmanager/workflow/components/fleet/alias"
 	"fleetmanager/workflow/components/fleet/build"
+	"fleetmanager/workflow/components/fleet/buildupdate"
 	"fleetmanager/workflow/components/fleet/eip"
 	"fleetmanager/workflow/components/fleet/process"
 	"fleetmanager/workflow/components/fleet/resdomain"
@@ -45,6 +47,12 @@ const (
 	CreateBuildImage          = "CREATE_BUILD_IMAGE"
 	BuildFinish               = "BUILD_FINISH"
 	StartBuildImage           = "START_BUILD_IMAGE"
+	StartAliasRollout         = "START_ALIAS_ROLLOUT"
+	ShiftAliasWeight          = "SHIFT_ALIAS_WEIGHT"
+	ScaleDownOldFleet         = "SCALE_DOWN_OLD_FLEET"
+	StartBuildUpdate          = "START_BUILD_UPDATE"
+	RollingUpdateBuild        = "ROLLING_UPDATE_BUILD"
+	BuildUpdateFinish         = "BUILD_UPDATE_FINISH"
 )
 
 type workflowCreater func(meta.TaskMeta, directer.Directer, int) components.Task
@@ -76,6 +84,12 @@ func newComponent(meta meta.TaskMeta, directer directer.Directer, step int) (com
 		PrepareImageECS:           build.NewPrePareImageEcsTask,
 		DeleteImageECS:            build.NewDeleteImageEcsTask,
 		CreateBuildImage:          build.NewCreateBuildImageTask,
+		StartAliasRollout:         alias.NewStartAliasRolloutTask,
+		ShiftAliasWeight:          alias.NewShiftAliasWeightTask,
+		ScaleDownOldFleet:         alias.NewScaleDownOldFleetTask,
+		StartBuildUpdate:          buildupdate.NewStartBuildUpdateTask,
+		RollingUpdateBuild:        buildupdate.NewRollingUpdateBuildTask,
+		BuildUpdateFinish:         buildupdate.NewBuildUpdateFinishTask,
 	}
 
 	if creater, ok := workflowCreaters[meta.TaskType]; ok {
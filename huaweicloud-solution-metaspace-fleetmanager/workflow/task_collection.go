@@ -33,6 +33,18 @@ func (tc *taskCollection) getTask(step int) (components.Task, error) {
 	return t, nil
 }
 
+func (tc *taskCollection) setTask(step int, t components.Task) error {
+	tc.aLock.Lock()
+	defer tc.aLock.Unlock()
+
+	if step < 1 || step > len(tc.All) {
+		return fmt.Errorf("task step over range")
+	}
+	tc.All[step-1] = t
+
+	return nil
+}
+
 func newTaskCollection() *taskCollection {
 	return &taskCollection{
 	}
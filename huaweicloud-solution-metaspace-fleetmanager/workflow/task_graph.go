@@ -0,0 +1,205 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// task graph
+package workflow
+
+import (
+	"fleetmanager/workflow/meta"
+	"fmt"
+	"sort"
+)
+
+const (
+	TaskStatePending     = "PENDING"
+	TaskStateExecuting   = "EXECUTING"
+	TaskStateCompleted   = "COMPLETED"
+	TaskStateFailed      = "FAILED"
+	TaskStateRollbacking = "ROLLBACKING"
+	TaskStateRollbacked  = "ROLLBACKED"
+)
+
+// taskGraph 任务依赖关系图, 节点为任务步骤(从1开始), 仅在工作流主循环中访问
+type taskGraph struct {
+	deps       map[int][]int
+	dependents map[int][]int
+	states     map[int]string
+	order      []int
+}
+
+// newTaskGraph 根据任务元数据构建依赖关系图, 若所有任务均未声明depends_on, 则按照声明顺序串行执行
+func newTaskGraph(tasks []meta.TaskMeta) (*taskGraph, error) {
+	g := &taskGraph{
+		deps:       make(map[int][]int, len(tasks)),
+		dependents: make(map[int][]int, len(tasks)),
+		states:     make(map[int]string, len(tasks)),
+	}
+
+	declared := false
+	for _, tm := range tasks {
+		if len(tm.DependsOn) > 0 {
+			declared = true
+			break
+		}
+	}
+
+	steps := make(map[string]int, len(tasks))
+	for i, tm := range tasks {
+		step := i + 1
+		g.states[step] = TaskStatePending
+		if !declared {
+			continue
+		}
+		if _, ok := steps[tm.Name]; ok {
+			return nil, fmt.Errorf("task name %s is duplicated", tm.Name)
+		}
+		steps[tm.Name] = step
+	}
+
+	for i, tm := range tasks {
+		step := i + 1
+		if !declared {
+			if step > 1 {
+				g.link(step-1, step)
+			}
+			continue
+		}
+		for _, name := range tm.DependsOn {
+			dep, ok := steps[name]
+			if !ok {
+				return nil, fmt.Errorf("task %s depends on unknown task %s", tm.Name, name)
+			}
+			if dep == step {
+				return nil, fmt.Errorf("task %s depends on itself", tm.Name)
+			}
+			g.link(dep, step)
+		}
+	}
+
+	if err := g.sort(); err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
+
+func (g *taskGraph) link(from int, to int) {
+	for _, d := range g.deps[to] {
+		if d == from {
+			return
+		}
+	}
+	g.deps[to] = append(g.deps[to], from)
+	g.dependents[from] = append(g.dependents[from], to)
+}
+
+// sort 拓扑排序, 同时检测环
+func (g *taskGraph) sort() error {
+	inDegree := make(map[int]int, len(g.states))
+	var ready []int
+	for step := range g.states {
+		inDegree[step] = len(g.deps[step])
+		if inDegree[step] == 0 {
+			ready = append(ready, step)
+		}
+	}
+
+	g.order = make([]int, 0, len(g.states))
+	for len(ready) > 0 {
+		sort.Ints(ready)
+		step := ready[0]
+		ready = ready[1:]
+		g.order = append(g.order, step)
+		for _, next := range g.dependents[step] {
+			inDegree[next]--
+			if inDegree[next] == 0 {
+				ready = append(ready, next)
+			}
+		}
+	}
+
+	if len(g.order) != len(g.states) {
+		return fmt.Errorf("task dependencies contain a cycle")
+	}
+
+	return nil
+}
+
+func (g *taskGraph) state(step int) string {
+	return g.states[step]
+}
+
+func (g *taskGraph) setState(step int, state string) {
+	g.states[step] = state
+}
+
+// roots 获取无依赖的入口任务
+func (g *taskGraph) roots() []int {
+	var steps []int
+	for _, step := range g.order {
+		if len(g.deps[step]) == 0 {
+			steps = append(steps, step)
+		}
+	}
+
+	return steps
+}
+
+// executable 获取依赖均已完成且尚未执行的任务
+func (g *taskGraph) executable() []int {
+	var steps []int
+	for _, step := range g.order {
+		if g.states[step] != TaskStatePending {
+			continue
+		}
+		ready := true
+		for _, dep := range g.deps[step] {
+			if g.states[dep] != TaskStateCompleted {
+				ready = false
+				break
+			}
+		}
+		if ready {
+			steps = append(steps, step)
+		}
+	}
+
+	return steps
+}
+
+// rollbackable 获取可以回滚的任务: 任务已执行(成功或失败), 且其已执行的后继任务均已回滚完成
+func (g *taskGraph) rollbackable() []int {
+	var steps []int
+	for i := len(g.order) - 1; i >= 0; i-- {
+		step := g.order[i]
+		if g.states[step] != TaskStateCompleted && g.states[step] != TaskStateFailed {
+			continue
+		}
+		ready := true
+		for _, next := range g.dependents[step] {
+			if g.states[next] != TaskStatePending && g.states[next] != TaskStateRollbacked {
+				ready = false
+				break
+			}
+		}
+		if ready {
+			steps = append(steps, step)
+		}
+	}
+
+	return steps
+}
+
+func (g *taskGraph) count(state string) int {
+	n := 0
+	for _, s := range g.states {
+		if s == state {
+			n++
+		}
+	}
+
+	return n
+}
+
+func (g *taskGraph) allCompleted() bool {
+	return g.count(TaskStateCompleted) == len(g.states)
+}
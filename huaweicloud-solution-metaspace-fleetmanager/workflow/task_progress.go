@@ -0,0 +1,265 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 任务执行进度
+package workflow
+
+import (
+	"encoding/json"
+	"fleetmanager/db/dao"
+	"fleetmanager/workflow/directer"
+	"fmt"
+	"time"
+)
+
+type TaskProgress struct {
+	Step               int       `json:"step"`
+	Name               string    `json:"name"`
+	TaskType           string    `json:"task_type"`
+	State              string    `json:"state"`
+	RetryTimes         int       `json:"retry_times"`
+	RollbackRetryTimes int       `json:"rollback_retry_times"`
+	LastError          string    `json:"last_error"`
+	Failed             bool      `json:"failed"`
+	Skipped            bool      `json:"skipped"`
+	UpdateTime         time.Time `json:"update_time"`
+}
+
+// ParseTaskProgress 解析数据库中记录的任务进度
+func ParseTaskProgress(taskStates string) ([]TaskProgress, error) {
+	var progress []TaskProgress
+	if taskStates == "" {
+		return progress, nil
+	}
+	if err := json.Unmarshal([]byte(taskStates), &progress); err != nil {
+		return nil, err
+	}
+
+	return progress, nil
+}
+
+// Skippable 只有执行失败(包括失败后已回滚)或者还未执行的任务可以跳过, 执行中或回滚中的任务结果无法撤销
+func (p TaskProgress) Skippable() bool {
+	switch p.State {
+	case "", TaskStatePending, TaskStateFailed:
+		return true
+	case TaskStateRollbacked:
+		return p.Failed
+	default:
+		return false
+	}
+}
+
+func (wf *Workflow) initProgress() {
+	wf.progress = make([]TaskProgress, len(wf.Meta.Tasks))
+	for i, tm := range wf.Meta.Tasks {
+		wf.progress[i] = TaskProgress{
+			Step:     i + 1,
+			Name:     tm.Name,
+			TaskType: tm.TaskType,
+		}
+	}
+}
+
+// recordProgress 根据任务事件更新任务的重试次数与错误信息
+func (wf *Workflow) recordProgress(ctx *directer.ExecuteContext) {
+	if ctx.From < 1 || ctx.From > len(wf.progress) {
+		return
+	}
+	p := &wf.progress[ctx.From-1]
+	p.UpdateTime = time.Now().UTC()
+	if ctx.Err == nil {
+		return
+	}
+	p.LastError = ctx.Err.Error()
+	if ctx.Next != ctx.From {
+		return
+	}
+	if wf.graph.state(ctx.From) == TaskStateRollbacking {
+		p.RollbackRetryTimes++
+	} else if ctx.Direction == directer.PositiveDirection {
+		// 重试次数耗尽后任务以反向事件通知工作流回滚, 不计入重试次数
+		p.RetryTimes++
+	}
+}
+
+func (wf *Workflow) taskStates() (string, error) {
+	for i := range wf.progress {
+		wf.progress[i].State = wf.graph.state(i + 1)
+	}
+	b, err := json.Marshal(wf.progress)
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+// restoreTaskStates 恢复数据库中记录的任务进度, restore根据记录的任务状态返回任务在依赖图中的初始状态
+func (wf *Workflow) restoreTaskStates(restore func(state string) string) error {
+	progress, err := ParseTaskProgress(wf.dbInfo.TaskStates)
+	if err != nil {
+		return err
+	}
+	for _, p := range progress {
+		if p.Step < 1 || p.Step > len(wf.progress) || wf.progress[p.Step-1].Name != p.Name {
+			continue
+		}
+		wf.progress[p.Step-1] = p
+		wf.graph.setState(p.Step, restore(p.State))
+	}
+
+	return nil
+}
+
+// restoreProgress 恢复数据库中记录的任务进度, 已完成的任务不再执行, 其余任务(包括已回滚的任务)重新执行
+func (wf *Workflow) restoreProgress(skipTask string) error {
+	err := wf.restoreTaskStates(func(state string) string {
+		if state == TaskStateCompleted {
+			return TaskStateCompleted
+		}
+		return TaskStatePending
+	})
+	if err != nil {
+		return err
+	}
+
+	if skipTask == "" {
+		return nil
+	}
+	for i := range wf.progress {
+		if wf.progress[i].Name != skipTask {
+			continue
+		}
+		if !wf.Meta.Tasks[i].ExecuteFailure.Ignore || !wf.progress[i].Skippable() {
+			return fmt.Errorf("task %s in state %s can not be skipped", skipTask, wf.progress[i].State)
+		}
+		wf.progress[i].Skipped = true
+		wf.graph.setState(i+1, TaskStateCompleted)
+		return nil
+	}
+
+	return fmt.Errorf("task %s not found", skipTask)
+}
+
+// restoreClaimedProgress 恢复租约过期后被接管的工作流进度:
+// 正向执行中的工作流, 已完成的任务不再执行, 未完成的任务重新执行;
+// 回滚中的工作流继续回滚, 已回滚的任务不再回滚, 执行或回滚到一半的任务重新回滚
+func (wf *Workflow) restoreClaimedProgress() error {
+	if wf.dbInfo.State != dao.WorkflowStateRollbacking {
+		return wf.restoreProgress("")
+	}
+
+	wf.rollback = true
+	return wf.restoreTaskStates(func(state string) string {
+		switch state {
+		case TaskStateCompleted, TaskStateRollbacked:
+			return state
+		case TaskStateFailed, TaskStateExecuting, TaskStateRollbacking:
+			return TaskStateFailed
+		default:
+			return TaskStatePending
+		}
+	})
+}
+
+// LoadClaimedWorkflow 加载已被当前WorkNode接管的工作流, 并从数据库记录的任务进度继续执行
+func LoadClaimedWorkflow(id string) (*Workflow, error) {
+	wf, err := LoadWorkflow(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := wf.restoreClaimedProgress(); err != nil {
+		return nil, err
+	}
+
+	return wf, nil
+}
+
+// checkAction 检查并执行运维人员下发的操作
+func (wf *Workflow) checkAction() (exitWorkflow bool) {
+	w, err := dao.GetWorkflow(dao.Filters{"Id": wf.Id})
+	if err != nil || w.Action == "" {
+		return false
+	}
+	if updated, err := dao.ClearWorkflowAction(wf.Id, w.Action); err != nil || updated != 1 {
+		return false
+	}
+
+	wf.logger.Info("receive workflow action %s, task %s", w.Action, w.ActionTask)
+	switch w.Action {
+	case dao.WorkflowActionCancel:
+		wf.setRollbackFlag(fmt.Errorf("workflow canceled by operator"))
+	case dao.WorkflowActionSkip:
+		wf.skip(w.ActionTask)
+	default:
+		return false
+	}
+
+	return wf.schedule(nil)
+}
+
+// skip 跳过还未执行的任务, 执行中或回滚中的任务不能跳过, 以免任务在后台继续修改资源
+func (wf *Workflow) skip(name string) {
+	for i, tm := range wf.Meta.Tasks {
+		if tm.Name != name {
+			continue
+		}
+		step := i + 1
+		if wf.rollback || !tm.ExecuteFailure.Ignore || wf.graph.state(step) != TaskStatePending {
+			wf.logger.Warn("task %s in state %s can not be skipped", name, wf.graph.state(step))
+			return
+		}
+		wf.graph.setState(step, TaskStateCompleted)
+		wf.progress[i].Skipped = true
+		wf.progress[i].UpdateTime = time.Now().UTC()
+		return
+	}
+}
+
+// restoreFleetState 工作流失败时fleet被置为ERROR, 恢复执行前将fleet恢复为工作流开始时的状态
+func (wf *Workflow) restoreFleetState() error {
+	f := &dao.Fleet{}
+	if err := json.Unmarshal(wf.Context.Get(directer.WfKeyFleet).ToJson("{}"), f); err != nil {
+		return err
+	}
+	if f.Id == "" || f.State == "" || f.State == dao.FleetStateError {
+		return nil
+	}
+	updated, err := dao.RecoverFleetState(f.Id, f.State)
+	if err != nil {
+		return err
+	}
+	if updated > 0 {
+		wf.logger.Info("fleet %s is recovered from %s to %s", f.Id, dao.FleetStateError, f.State)
+	}
+	return nil
+}
+
+// ResumeWorkflow 从失败的任务处恢复已终止的工作流, skipTask不为空时跳过该任务
+func ResumeWorkflow(id string, skipTask string, worknodeId string) error {
+	wf, err := LoadWorkflow(id)
+	if err != nil {
+		return err
+	}
+	if err := wf.restoreProgress(skipTask); err != nil {
+		return err
+	}
+
+	updated, err := dao.ResumeWorkflow(id, []string{dao.WorkflowStateError, dao.WorkflowStateRollbacked},
+		worknodeId, wf.dbInfo.FencingToken)
+	if err != nil {
+		return err
+	}
+	if updated != 1 {
+		return fmt.Errorf("workflow %s is not in a resumable state", id)
+	}
+	wf.worknodeId = worknodeId
+	wf.dbInfo.WorkNodeId = worknodeId
+	wf.dbInfo.FencingToken++
+	if err := wf.restoreFleetState(); err != nil {
+		wf.logger.Error("recover state of fleet %s error: %v", wf.dbInfo.ResourceId, err)
+	}
+	wf.Run()
+
+	return nil
+}
@@ -0,0 +1,150 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 任务超时控制
+package workflow
+
+import (
+	"fleetmanager/db/dao"
+	"fleetmanager/logger"
+	"fleetmanager/workflow/components"
+	"fleetmanager/workflow/directer"
+	"fleetmanager/workflow/meta"
+	"fmt"
+	"github.com/google/uuid"
+	"time"
+)
+
+// taskBuilder 根据任务元数据构建任务实例
+type taskBuilder func(tm meta.TaskMeta, d directer.Directer, step int) (components.Task, error)
+
+// retryableTask 超时后重建的任务实例需要继承重试进度, 并复用任务自身的失败处理策略
+type retryableTask interface {
+	components.Task
+	SetRetryTimes(retryTimes int, rollbackRetryTimes int)
+	ExecNext(output interface{}, err error)
+	RollbackPrev(output interface{}, err error)
+}
+
+// attemptDirecter 为任务实例产生的事件标记代次, 工作流据此忽略已超时实例的事件
+type attemptDirecter struct {
+	*Workflow
+	attempt int
+}
+
+// Process 标记事件代次后交由工作流处理
+func (d *attemptDirecter) Process(ctx *directer.ExecuteContext) {
+	ctx.Attempt = d.attempt
+	d.Workflow.Process(ctx)
+}
+
+type taskTimeout struct {
+	step    int
+	attempt int
+}
+
+// startTimer 为任务的本次执行启动超时定时器, delay为任务开始执行前的重试等待时间
+func (wf *Workflow) startTimer(step int, delay int) {
+	wf.stopTimer(step)
+	timeout := wf.Meta.Tasks[step-1].TimeoutSeconds
+	if timeout <= 0 {
+		return
+	}
+
+	event := taskTimeout{step: step, attempt: wf.attempts[step]}
+	wf.timers[step] = time.AfterFunc(time.Duration(delay+timeout)*time.Second, func() {
+		wf.timeoutCh <- event
+	})
+}
+
+func (wf *Workflow) stopTimer(step int) {
+	if timer, ok := wf.timers[step]; ok {
+		timer.Stop()
+		delete(wf.timers, step)
+	}
+}
+
+func (wf *Workflow) stopTimers() {
+	for step := range wf.timers {
+		wf.stopTimer(step)
+	}
+}
+
+// onTaskTimeout 任务超时后重建任务实例, 并以超时错误按照任务的失败策略进行重试、跳过或回滚
+func (wf *Workflow) onTaskTimeout(event taskTimeout) {
+	if event.attempt != wf.attempts[event.step] {
+		return
+	}
+	state := wf.graph.state(event.step)
+	if state != TaskStateExecuting && state != TaskStateRollbacking {
+		return
+	}
+	delete(wf.timers, event.step)
+
+	tm := wf.Meta.Tasks[event.step-1]
+	err := fmt.Errorf("task %s timed out after %d seconds", tm.Name, tm.TimeoutSeconds)
+	wf.recordTimeout(event.step, err)
+
+	t, e := wf.renewTask(event.step)
+	if e != nil {
+		wf.logger.Error("renew timeout task %s error: %v", tm.Name, e)
+		return
+	}
+	go func() {
+		if state == TaskStateRollbacking {
+			t.RollbackPrev(nil, err)
+			return
+		}
+		t.ExecNext(nil, err)
+	}()
+}
+
+// renewTask 重建任务实例, 旧实例仍可能在运行, 其后续产生的事件将被忽略
+func (wf *Workflow) renewTask(step int) (retryableTask, error) {
+	attempt := wf.attempts[step] + 1
+	t, err := wf.builder(wf.Meta.Tasks[step-1], &attemptDirecter{Workflow: wf, attempt: attempt}, step)
+	if err != nil {
+		return nil, err
+	}
+	rt, ok := t.(retryableTask)
+	if !ok {
+		return nil, fmt.Errorf("task step %d can not be renewed", step)
+	}
+
+	p := wf.progress[step-1]
+	rt.SetRetryTimes(p.RetryTimes, p.RollbackRetryTimes)
+	wf.linkTask(rt, step)
+	if err = wf.Tasks.setTask(step, rt); err != nil {
+		return nil, err
+	}
+	wf.attempts[step] = attempt
+
+	return rt, nil
+}
+
+// recordTimeout 记录任务超时原因, 工作流资源为fleet时同时写入fleet事件
+func (wf *Workflow) recordTimeout(step int, err error) {
+	wf.progress[step-1].LastError = err.Error()
+	wf.progress[step-1].UpdateTime = time.Now().UTC()
+	wf.logger.WithFields(map[string]interface{}{
+		logger.Stage: "workflow_task_timeout",
+		logger.Error: err.Error(),
+	}).Error("task timeout")
+
+	if wf.dbInfo == nil || wf.dbInfo.ResourceId == "" {
+		return
+	}
+	if _, e := dao.GetFleetStorage().Get(dao.Filters{"Id": wf.dbInfo.ResourceId}); e != nil {
+		return
+	}
+	u, _ := uuid.NewUUID()
+	event := &dao.FleetEvent{
+		Id:        u.String(),
+		FleetId:   wf.dbInfo.ResourceId,
+		EventCode: dao.FleetEventCodeWorkflowTaskTimeout,
+		EventTime: time.Now().UTC(),
+		Message:   fmt.Sprintf("workflow %s: %v", wf.Id, err),
+	}
+	if e := dao.GetFleetEventStorage().Insert(event); e != nil {
+		wf.logger.Warn("insert task timeout event of fleet %s error: %v", wf.dbInfo.ResourceId, e)
+	}
+}
@@ -26,8 +26,14 @@ type Workflow struct {
 	logger     *logger.FMLogger
 	dbInfo     *dao.Workflow
 	execCh     chan *directer.ExecuteContext
+	timeoutCh  chan taskTimeout
 	Context    *directer.WorkflowContext
 	Tasks      *taskCollection
+	graph      *taskGraph
+	progress   []TaskProgress
+	builder    taskBuilder
+	attempts   map[int]int
+	timers     map[int]*time.Timer
 	Id         string
 	Meta       meta.WorkflowMeta
 	Parameter  string
@@ -35,6 +41,7 @@ type Workflow struct {
 	resourceId string
 	err        error
 	rollback   bool
+	aborted    bool
 	projectId  string
 	worknodeId string
 }
@@ -54,6 +61,17 @@ func (wf *Workflow) GetContext() *directer.WorkflowContext {
 	return wf.Context
 }
 
+// SaveContext 持久化当前上下文, 由任务协程调用, 仅更新参数字段, 租约丢失时返回ErrWorkflowLeaseLost
+func (wf *Workflow) SaveContext() error {
+	w := &dao.Workflow{
+		Id:           wf.Id,
+		FencingToken: wf.dbInfo.FencingToken,
+		Parameter:    string(wf.Context.GetParameter()),
+		UpdateTime:   time.Now().UTC(),
+	}
+	return dao.UpdateWorkflow(w, "Parameter", "UpdateTime")
+}
+
 func (wf *Workflow) failed(err error) {
 	wf.err = err
 	wf.logger.WithFields(map[string]interface{}{
@@ -85,15 +103,17 @@ func (wf *Workflow) insertDB() error {
 		return err
 	}
 	w := &dao.Workflow{
-		Id:           wf.Id,
-		State:        dao.WorkflowStateCreate,
-		ResourceId:   wf.resourceId,
-		Parameter:    string(wf.Context.Parameter),
-		Meta:         string(metaStr),
-		ProjectId:    wf.projectId,
-		CreationTime: time.Now().UTC(),
-		UpdateTime:   time.Now().UTC(),
-		WorkNodeId:   wf.worknodeId,
+		Id:              wf.Id,
+		State:           dao.WorkflowStateCreate,
+		ResourceId:      wf.resourceId,
+		Parameter:       string(wf.Context.GetParameter()),
+		Meta:            string(metaStr),
+		ProjectId:       wf.projectId,
+		CreationTime:    time.Now().UTC(),
+		UpdateTime:      time.Now().UTC(),
+		WorkNodeId:      wf.worknodeId,
+		FencingToken:    1,
+		LeaseExpireTime: time.Now().UTC().Add(dao.WorkflowLease),
 	}
 	wf.dbInfo = w
 	if err := dao.InsertWorkflow(w); err != nil {
@@ -108,11 +128,16 @@ func (wf *Workflow) save(state string) error {
 	if err != nil {
 		return err
 	}
+	taskStates, err := wf.taskStates()
+	if err != nil {
+		return err
+	}
 	wf.dbInfo.Meta = string(metaStr)
-	wf.dbInfo.Parameter = string(wf.Context.Parameter)
+	wf.dbInfo.Parameter = string(wf.Context.GetParameter())
+	wf.dbInfo.TaskStates = taskStates
 	wf.dbInfo.State = state
 	wf.dbInfo.UpdateTime = time.Now().UTC()
-	return dao.UpdateWorkflow(wf.dbInfo, "State", "Meta", "Parameter", "UpdateTime")
+	return dao.UpdateWorkflow(wf.dbInfo, "State", "Meta", "Parameter", "TaskStates", "UpdateTime")
 }
 
 func (wf *Workflow) load(logger *logger.FMLogger) error {
@@ -125,6 +150,7 @@ func (wf *Workflow) load(logger *logger.FMLogger) error {
 	wf.Parameter = wf.dbInfo.Parameter
 	wf.Tasks = newTaskCollection()
 	wf.execCh = make(chan *directer.ExecuteContext, DefaultExecChLength)
+	wf.timeoutCh = make(chan taskTimeout, DefaultExecChLength)
 	wf.logger = logger
 	wf.Context = &directer.WorkflowContext{}
 	wf.worknodeId = wfdb.WorkNodeId
@@ -147,72 +173,183 @@ func (wf *Workflow) load(logger *logger.FMLogger) error {
 }
 
 func (wf *Workflow) processExecEvent(ctx *directer.ExecuteContext) (exitWorkflow bool) {
-	if ctx.Ended() {
-		if ctx.Err != nil {
-			wf.failed(ctx.Err)
-			if err := wf.save(dao.WorkflowStateError); err != nil {
-				return true
-			}
+	if ctx.Attempt != wf.attempts[ctx.From] {
+		wf.logger.Warn("ignore event from timed out task step %d, attempt %d", ctx.From, ctx.Attempt)
+		return false
+	}
+	wf.stopTimer(ctx.From)
+	wf.recordProgress(ctx)
+	switch wf.graph.state(ctx.From) {
+	case TaskStateExecuting:
+		if wf.onTaskExecuted(ctx) {
+			return false
+		}
+	case TaskStateRollbacking:
+		if wf.onTaskRollbacked(ctx) {
+			return false
 		}
+	default:
+		wf.logger.Warn("receive unexpected event from task step %d, state %s", ctx.From, wf.graph.state(ctx.From))
+		return false
+	}
 
-		if ctx.Direction == directer.PositiveDirection {
-			if err := wf.save(dao.WorkflowStateFinished); err != nil {
-				return true
-			}
-		} else {
-			if err := wf.save(dao.WorkflowStateRollbacked); err != nil {
-				return true
-			}
+	return wf.schedule(ctx)
+}
+
+// onTaskExecuted 处理任务正向执行结果, 返回true表示任务需要重试
+func (wf *Workflow) onTaskExecuted(ctx *directer.ExecuteContext) (retry bool) {
+	if ctx.Direction == directer.PositiveDirection && ctx.Err != nil && ctx.Next == ctx.From {
+		if !wf.rollback {
+			wf.dispatch(ctx.From, ctx, ctx.RetryDelay)
+			return true
 		}
-		return true
+		// 其他分支已经失败, 不再重试, 直接标记为失败等待回滚
+		wf.graph.setState(ctx.From, TaskStateFailed)
+		wf.progress[ctx.From-1].Failed = true
+		return false
 	}
 
-	t, err := wf.Tasks.getTask(ctx.Next)
-	if err != nil {
-		wf.failed(err)
+	if ctx.Direction == directer.NegativeDirection {
+		wf.graph.setState(ctx.From, TaskStateFailed)
+		wf.progress[ctx.From-1].Failed = true
+		wf.setRollbackFlag(ctx.Err)
+		return false
+	}
+
+	wf.graph.setState(ctx.From, TaskStateCompleted)
+	wf.progress[ctx.From-1].Failed = false
+	return false
+}
+
+// onTaskRollbacked 处理任务回滚结果, 返回true表示任务需要重试
+func (wf *Workflow) onTaskRollbacked(ctx *directer.ExecuteContext) (retry bool) {
+	if ctx.Err != nil && ctx.Next == ctx.From {
+		wf.dispatch(ctx.From, ctx, ctx.RetryDelay)
 		return true
 	}
 
-	if ctx.Direction == directer.PositiveDirection {
-		if output, e := t.Execute(ctx); e != nil {
-			wf.error(e, output, ctx.Direction)
-		}
-		if err := wf.save(dao.WorkflowStateRunning); err != nil {
+	if ctx.Err != nil && !wf.Meta.Tasks[ctx.From-1].RollbackFailure.Ignore {
+		// 回滚失败且不可忽略, 等待其他回滚分支结束后终止工作流
+		wf.aborted = true
+		wf.failed(ctx.Err)
+	}
+	wf.graph.setState(ctx.From, TaskStateRollbacked)
+	return false
+}
+
+// schedule 根据任务依赖关系调度可执行的任务, 返回true表示工作流结束
+func (wf *Workflow) schedule(ctx *directer.ExecuteContext) (exitWorkflow bool) {
+	if !wf.rollback {
+		if wf.graph.allCompleted() {
+			_ = wf.save(dao.WorkflowStateFinished)
 			return true
 		}
-	} else {
-		wf.setRollbackFlag(ctx.Err)
-		if output, e := t.Rollback(ctx); e != nil {
-			wf.error(e, output, ctx.Direction)
+		for _, step := range wf.graph.executable() {
+			wf.graph.setState(step, TaskStateExecuting)
+			wf.dispatch(step, ctx, 0)
 		}
-		if err := wf.save(dao.WorkflowStateRollbacking); err != nil {
-			return true
+		return wf.save(dao.WorkflowStateRunning) != nil
+	}
+
+	// 进入回滚流程后, 需要等待仍在执行的分支结束, 再按照依赖关系逆序回滚已经执行过的任务
+	if wf.graph.count(TaskStateExecuting) == 0 && !wf.aborted {
+		for _, step := range wf.graph.rollbackable() {
+			wf.graph.setState(step, TaskStateRollbacking)
+			wf.dispatch(step, ctx, 0)
 		}
 	}
 
-	return false
+	if wf.graph.count(TaskStateExecuting) > 0 || wf.graph.count(TaskStateRollbacking) > 0 {
+		return wf.save(dao.WorkflowStateRollbacking) != nil
+	}
+
+	if wf.aborted {
+		_ = wf.save(dao.WorkflowStateError)
+	} else {
+		_ = wf.save(dao.WorkflowStateRollbacked)
+	}
+	return true
+}
+
+// dispatch 等待delay秒后异步执行或回滚任务, 任务结束后通过Process通知工作流
+func (wf *Workflow) dispatch(step int, ctx *directer.ExecuteContext, delay int) {
+	t, err := wf.Tasks.getTask(step)
+	if err != nil {
+		wf.failed(err)
+		return
+	}
+
+	if ctx == nil {
+		ctx = &directer.ExecuteContext{}
+	}
+	execCtx := *ctx
+	execCtx.Next = step
+	execCtx.RetryDelay = 0
+	rollback := wf.graph.state(step) == TaskStateRollbacking
+	wf.startTimer(step, delay)
+
+	go func() {
+		if delay > 0 {
+			time.Sleep(time.Duration(delay) * time.Second)
+		}
+		if rollback {
+			if output, e := t.Rollback(&execCtx); e != nil {
+				wf.error(e, output, directer.NegativeDirection)
+			}
+			return
+		}
+		if output, e := t.Execute(&execCtx); e != nil {
+			wf.error(e, output, directer.PositiveDirection)
+		}
+	}()
 }
 
 func (wf *Workflow) run() {
 	wf.logger.WithField(logger.Stage, "workflow_begin").Info("workflow begin")
 
 	ticker := time.NewTicker(5 * time.Second)
-	wf.Process(&directer.ExecuteContext{
-		Next: wf.EntryTask.TaskStep(),
-	})
+	defer ticker.Stop()
+	defer wf.stopTimers()
+	if wf.schedule(nil) {
+		return
+	}
 
 	for {
 		select {
 		case <-ticker.C:
 			wf.logger.WithField(logger.Stage, "workflow_heartbeat").Info("workflow is running")
+			if wf.renewLease() {
+				return
+			}
+			if wf.checkAction() {
+				return
+			}
 		case execCtx := <-wf.execCh:
 			if wf.processExecEvent(execCtx) {
 				return
 			}
+		case event := <-wf.timeoutCh:
+			wf.onTaskTimeout(event)
 		}
 	}
 }
 
+// renewLease 续约工作流租约, 返回true表示工作流已被其他WorkNode接管, 当前WorkNode需要退出执行
+func (wf *Workflow) renewLease() (leaseLost bool) {
+	err := dao.RenewWorkflowLease(wf.Id, wf.dbInfo.FencingToken)
+	if err == nil {
+		return false
+	}
+	if err == dao.ErrWorkflowLeaseLost {
+		wf.failed(err)
+		return true
+	}
+
+	// 数据库异常时继续执行, 租约过期后的写操作会被fencing token拦截
+	wf.logger.Warn("renew workflow lease error: %v", err)
+	return false
+}
+
 // Run 执行工作流6
 func (wf *Workflow) Run() {
 	go func() {
@@ -275,29 +412,46 @@ func (wf *Workflow) parseTasks() error {
 	if len(wf.Meta.Tasks) < 1 {
 		return fmt.Errorf("must have more than one task")
 	}
-	entry, err := newComponent(wf.Meta.Tasks[0], wf, 1)
+	g, err := newTaskGraph(wf.Meta.Tasks)
 	if err != nil {
 		return err
 	}
-	curTask := entry
-	wf.EntryTask = entry
-	wf.Tasks.addTask(entry)
+	wf.graph = g
+	wf.initProgress()
+	wf.attempts = make(map[int]int, len(wf.Meta.Tasks))
+	wf.timers = make(map[int]*time.Timer, len(wf.Meta.Tasks))
+	if wf.builder == nil {
+		wf.builder = newComponent
+	}
 
-	for i := 1; i < len(wf.Meta.Tasks); i++ {
-		tm := wf.Meta.Tasks[i]
-		t, err := newComponent(tm, wf, i+1)
+	for i, tm := range wf.Meta.Tasks {
+		t, err := wf.builder(tm, wf, i+1)
 		if err != nil {
 			return err
 		}
-		t.LinkPrev(curTask.TaskStep())
-		curTask.LinkNext(t.TaskStep())
+		wf.linkTask(t, i+1)
 		wf.Tasks.addTask(t)
-		curTask = t
 	}
 
+	entry, err := wf.Tasks.getTask(g.roots()[0])
+	if err != nil {
+		return err
+	}
+	wf.EntryTask = entry
+
 	return nil
 }
 
+// linkTask Prev/Next仅用于日志记录, 实际的调度顺序由依赖关系图决定
+func (wf *Workflow) linkTask(t components.Task, step int) {
+	if deps := wf.graph.deps[step]; len(deps) > 0 {
+		t.LinkPrev(deps[0])
+	}
+	if dependents := wf.graph.dependents[step]; len(dependents) > 0 {
+		t.LinkNext(dependents[0])
+	}
+}
+
 // LoadWorkflow 加载工作流
 func LoadWorkflow(id string) (*Workflow, error) {
 	wf := &Workflow{
@@ -343,6 +497,7 @@ func CreateWorkflow(metaFile string,
 		Id:         u.String(),
 		Tasks:      newTaskCollection(),
 		execCh:     make(chan *directer.ExecuteContext, DefaultExecChLength),
+		timeoutCh:  make(chan taskTimeout, DefaultExecChLength),
 		logger:     log.WithField(logger.WorkflowId, u.String()),
 		Context:    &directer.WorkflowContext{},
 		projectId:  projectId,
@@ -0,0 +1,573 @@
+package workflow
+
+import (
+	"fleetmanager/db/dao"
+	"fleetmanager/db/dbm"
+	"fleetmanager/logger"
+	mockbeego "fleetmanager/mocks/beego"
+	"fleetmanager/workflow/components"
+	"fleetmanager/workflow/directer"
+	"fleetmanager/workflow/meta"
+	"fmt"
+	"github.com/beego/beego/v2/client/orm"
+	"github.com/golang/mock/gomock"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNewTaskGraph(t *testing.T) {
+	tests := []struct {
+		name          string
+		tasks         []meta.TaskMeta
+		expectedRoots []int
+		expectedOrder []int
+		expectedError bool
+	}{
+		{
+			name:          "no depends_on declared: linear chain",
+			tasks:         []meta.TaskMeta{{Name: "a"}, {Name: "b"}, {Name: "c"}},
+			expectedRoots: []int{1},
+			expectedOrder: []int{1, 2, 3},
+		},
+		{
+			name: "diamond",
+			tasks: []meta.TaskMeta{
+				{Name: "a"},
+				{Name: "b", DependsOn: []string{"a"}},
+				{Name: "c", DependsOn: []string{"a"}},
+				{Name: "d", DependsOn: []string{"b", "c"}},
+			},
+			expectedRoots: []int{1},
+			expectedOrder: []int{1, 2, 3, 4},
+		},
+		{
+			name: "multiple roots",
+			tasks: []meta.TaskMeta{
+				{Name: "a"},
+				{Name: "b"},
+				{Name: "c", DependsOn: []string{"b", "a"}},
+			},
+			expectedRoots: []int{1, 2},
+			expectedOrder: []int{1, 2, 3},
+		},
+		{
+			name: "unknown dependency",
+			tasks: []meta.TaskMeta{
+				{Name: "a"},
+				{Name: "b", DependsOn: []string{"x"}},
+			},
+			expectedError: true,
+		},
+		{
+			name: "duplicated name",
+			tasks: []meta.TaskMeta{
+				{Name: "a"},
+				{Name: "a", DependsOn: []string{"a"}},
+			},
+			expectedError: true,
+		},
+		{
+			name: "cycle",
+			tasks: []meta.TaskMeta{
+				{Name: "a", DependsOn: []string{"c"}},
+				{Name: "b", DependsOn: []string{"a"}},
+				{Name: "c", DependsOn: []string{"b"}},
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := newTaskGraph(tt.tasks)
+			if tt.expectedError {
+				if err == nil {
+					t.Errorf("newTaskGraph() expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("newTaskGraph() error = %v", err)
+			}
+			if !reflect.DeepEqual(g.roots(), tt.expectedRoots) {
+				t.Errorf("roots() = %v, want %v", g.roots(), tt.expectedRoots)
+			}
+			if !reflect.DeepEqual(g.order, tt.expectedOrder) {
+				t.Errorf("order = %v, want %v", g.order, tt.expectedOrder)
+			}
+		})
+	}
+}
+
+type fakeTask struct {
+	components.BaseTask
+	name     string
+	fail     bool
+	delay    time.Duration
+	recorder *taskRecorder
+}
+
+type taskRecorder struct {
+	lock       sync.Mutex
+	running    int
+	maxRunning int
+	executed   []string
+	rollbacked []string
+	// hangs 任务前若干次执行将被挂起直至超时
+	hangs map[string]int
+}
+
+// Execute 模拟执行任务
+func (t *fakeTask) Execute(*directer.ExecuteContext) (output interface{}, err error) {
+	defer func() { t.ExecNext(output, err) }()
+	name := t.name
+	t.recorder.lock.Lock()
+	if t.recorder.hangs[name] > 0 {
+		t.recorder.hangs[name]--
+		t.recorder.lock.Unlock()
+		time.Sleep(2 * time.Second)
+		return nil, nil
+	}
+	t.recorder.running++
+	if t.recorder.running > t.recorder.maxRunning {
+		t.recorder.maxRunning = t.recorder.running
+	}
+	t.recorder.lock.Unlock()
+
+	time.Sleep(t.delay)
+
+	t.recorder.lock.Lock()
+	t.recorder.running--
+	t.recorder.executed = append(t.recorder.executed, name)
+	t.recorder.lock.Unlock()
+	if t.fail {
+		return nil, fmt.Errorf("task %s failed", name)
+	}
+	return nil, nil
+}
+
+// Rollback 模拟回滚任务
+func (t *fakeTask) Rollback(*directer.ExecuteContext) (output interface{}, err error) {
+	defer func() { t.RollbackPrev(output, err) }()
+	t.recorder.lock.Lock()
+	t.recorder.rollbacked = append(t.recorder.rollbacked, t.name)
+	t.recorder.lock.Unlock()
+	return nil, nil
+}
+
+// mockWorkflowTable 模拟工作流表, updated为条件更新返回的行数, token为数据库中记录的fencing token
+func mockWorkflowTable(mockCtrl *gomock.Controller, state *string, updated int64, token int64) {
+	mockOrmer := mockbeego.NewMockOrmer(mockCtrl)
+	mockQuerySeter := mockbeego.NewMockQuerySeter(mockCtrl)
+	dbm.Ormer = mockOrmer
+	mockOrmer.EXPECT().QueryTable(gomock.Any()).Return(mockQuerySeter).AnyTimes()
+	mockQuerySeter.EXPECT().Filter(gomock.Any(), gomock.Any()).Return(mockQuerySeter).AnyTimes()
+	mockQuerySeter.EXPECT().Limit(gomock.Any()).Return(mockQuerySeter).AnyTimes()
+	mockQuerySeter.EXPECT().Update(gomock.Any()).DoAndReturn(
+		func(values orm.Params) (int64, error) {
+			if s, ok := values["State"]; ok && updated > 0 {
+				*state = s.(string)
+			}
+			return updated, nil
+		}).AnyTimes()
+	mockQuerySeter.EXPECT().One(gomock.Any()).DoAndReturn(
+		func(container interface{}, cols ...string) error {
+			container.(*dao.Workflow).FencingToken = token
+			return nil
+		}).AnyTimes()
+}
+
+func newFakeWorkflow(t *testing.T, tasks []meta.TaskMeta, failed map[string]bool,
+	delay time.Duration, recorder *taskRecorder) *Workflow {
+	wf := &Workflow{
+		Id:        "mock_workflow",
+		Tasks:     newTaskCollection(),
+		execCh:    make(chan *directer.ExecuteContext, DefaultExecChLength),
+		timeoutCh: make(chan taskTimeout, DefaultExecChLength),
+		logger:    logger.NewDebugLogger(),
+		Context:   &directer.WorkflowContext{},
+		Meta:      meta.WorkflowMeta{Tasks: tasks},
+		dbInfo:    &dao.Workflow{Id: "mock_workflow"},
+	}
+	wf.builder = func(tm meta.TaskMeta, d directer.Directer, step int) (components.Task, error) {
+		return &fakeTask{
+			BaseTask: components.NewBaseTask(tm, d, step),
+			name:     tm.Name,
+			fail:     failed[tm.Name],
+			delay:    delay,
+			recorder: recorder,
+		}, nil
+	}
+	if err := wf.parseTasks(); err != nil {
+		t.Fatalf("parseTasks() error = %v", err)
+	}
+	return wf
+}
+
+func TestWorkflowRun(t *testing.T) {
+	originOrmer := dbm.Ormer
+	defer func() { dbm.Ormer = originOrmer }()
+
+	// a -> (b, c, d) -> e
+	tasks := []meta.TaskMeta{
+		{Name: "a"},
+		{Name: "b", DependsOn: []string{"a"}},
+		{Name: "c", DependsOn: []string{"a"}},
+		{Name: "d", DependsOn: []string{"a"}},
+		{Name: "e", DependsOn: []string{"b", "c", "d"}},
+	}
+
+	tests := []struct {
+		name               string
+		failed             map[string]bool
+		expectedState      string
+		expectedExecuted   []string
+		expectedRollbacked []string
+		expectedParallel   bool
+	}{
+		{
+			name:             "independent branches run concurrently",
+			failed:           map[string]bool{},
+			expectedState:    dao.WorkflowStateFinished,
+			expectedExecuted: []string{"a", "b", "c", "d", "e"},
+			expectedParallel: true,
+		},
+		{
+			name:               "failed branch rolls back completed branches only",
+			failed:             map[string]bool{"c": true},
+			expectedState:      dao.WorkflowStateRollbacked,
+			expectedExecuted:   []string{"a", "b", "c", "d"},
+			expectedRollbacked: []string{"a", "b", "c", "d"},
+			expectedParallel:   true,
+		},
+		{
+			name:               "failed root rolls back itself",
+			failed:             map[string]bool{"a": true},
+			expectedState:      dao.WorkflowStateRollbacked,
+			expectedExecuted:   []string{"a"},
+			expectedRollbacked: []string{"a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			var state string
+			mockWorkflowTable(mockCtrl, &state, 1, 0)
+
+			recorder := &taskRecorder{}
+			wf := newFakeWorkflow(t, tasks, tt.failed, 50*time.Millisecond, recorder)
+			wf.run()
+
+			if state != tt.expectedState {
+				t.Errorf("workflow state = %s, want %s", state, tt.expectedState)
+			}
+			if !sameElements(recorder.executed, tt.expectedExecuted) {
+				t.Errorf("executed = %v, want %v", recorder.executed, tt.expectedExecuted)
+			}
+			if !sameElements(recorder.rollbacked, tt.expectedRollbacked) {
+				t.Errorf("rollbacked = %v, want %v", recorder.rollbacked, tt.expectedRollbacked)
+			}
+			if tt.expectedParallel && recorder.maxRunning < 2 {
+				t.Errorf("max running tasks = %d, want concurrent execution", recorder.maxRunning)
+			}
+			if len(recorder.rollbacked) > 0 && recorder.rollbacked[len(recorder.rollbacked)-1] != "a" {
+				t.Errorf("root task must be rolled back last, got %v", recorder.rollbacked)
+			}
+		})
+	}
+}
+
+func TestWorkflowLeaseLost(t *testing.T) {
+	originOrmer := dbm.Ormer
+	defer func() { dbm.Ormer = originOrmer }()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	// 数据库中的fencing token已被其他WorkNode递增, 当前WorkNode的更新全部被拒绝
+	var state string
+	mockWorkflowTable(mockCtrl, &state, 0, 1)
+	tasks := []meta.TaskMeta{{Name: "a"}, {Name: "b", DependsOn: []string{"a"}}}
+	recorder := &taskRecorder{}
+	wf := newFakeWorkflow(t, tasks, nil, 0, recorder)
+
+	done := make(chan struct{})
+	go func() {
+		wf.run()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatalf("workflow does not exit after lease lost")
+	}
+	if state != "" {
+		t.Errorf("workflow state = %s, want no update", state)
+	}
+	if err := wf.save(dao.WorkflowStateFinished); err != dao.ErrWorkflowLeaseLost {
+		t.Errorf("save() error = %v, want %v", err, dao.ErrWorkflowLeaseLost)
+	}
+}
+
+func TestTaskTimeout(t *testing.T) {
+	originOrmer := dbm.Ormer
+	defer func() { dbm.Ormer = originOrmer }()
+
+	tests := []struct {
+		name               string
+		onFailure          meta.OnFailure
+		hangs              int
+		expectedState      string
+		expectedExecuted   []string
+		expectedRollbacked []string
+		expectedRetryTimes int
+	}{
+		{
+			name:               "timed out task is retried",
+			onFailure:          meta.OnFailure{RetryPolicy: meta.RetryPolicy{Repeat: 1, Logic: meta.LogicFixed}},
+			hangs:              1,
+			expectedState:      dao.WorkflowStateFinished,
+			expectedExecuted:   []string{"a", "b", "c"},
+			expectedRetryTimes: 1,
+		},
+		{
+			name:               "timed out task without retry rolls back",
+			hangs:              1,
+			expectedState:      dao.WorkflowStateRollbacked,
+			expectedExecuted:   []string{"a"},
+			expectedRollbacked: []string{"a", "b"},
+		},
+		{
+			name:             "timed out task is ignored",
+			onFailure:        meta.OnFailure{Ignore: true},
+			hangs:            1,
+			expectedState:    dao.WorkflowStateFinished,
+			expectedExecuted: []string{"a", "c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			var state string
+			mockWorkflowTable(mockCtrl, &state, 1, 0)
+
+			tasks := []meta.TaskMeta{
+				{Name: "a"},
+				{Name: "b", TimeoutSeconds: 1, ExecuteFailure: tt.onFailure},
+				{Name: "c"},
+			}
+			recorder := &taskRecorder{hangs: map[string]int{"b": tt.hangs}}
+			wf := newFakeWorkflow(t, tasks, nil, 0, recorder)
+			wf.run()
+
+			recorder.lock.Lock()
+			defer recorder.lock.Unlock()
+			if state != tt.expectedState {
+				t.Errorf("workflow state = %s, want %s", state, tt.expectedState)
+			}
+			if !sameElements(recorder.executed, tt.expectedExecuted) {
+				t.Errorf("executed = %v, want %v", recorder.executed, tt.expectedExecuted)
+			}
+			if !sameElements(recorder.rollbacked, tt.expectedRollbacked) {
+				t.Errorf("rollbacked = %v, want %v", recorder.rollbacked, tt.expectedRollbacked)
+			}
+			if wf.progress[1].RetryTimes != tt.expectedRetryTimes {
+				t.Errorf("retry times = %d, want %d", wf.progress[1].RetryTimes, tt.expectedRetryTimes)
+			}
+			if wf.progress[1].LastError == "" {
+				t.Errorf("timeout reason is not recorded")
+			}
+		})
+	}
+}
+
+func sameElements(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	count := make(map[string]int, len(a))
+	for _, s := range a {
+		count[s]++
+	}
+	for _, s := range b {
+		count[s]--
+	}
+	for _, n := range count {
+		if n != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestRestoreProgress(t *testing.T) {
+	tasks := []meta.TaskMeta{
+		{Name: "a"},
+		{Name: "b", DependsOn: []string{"a"}, ExecuteFailure: meta.OnFailure{Ignore: true}},
+		{Name: "c", DependsOn: []string{"a"}},
+	}
+	taskStates := `[{"step":1,"name":"a","state":"COMPLETED"},` +
+		`{"step":2,"name":"b","state":"ROLLBACKED","retry_times":3,"last_error":"mock error","failed":true},` +
+		`{"step":3,"name":"c","state":"ROLLBACKED"}]`
+
+	tests := []struct {
+		name               string
+		taskStates         string
+		skipTask           string
+		expectedExecutable []int
+		expectedError      bool
+	}{
+		{
+			name:               "resume from failed task",
+			expectedExecutable: []int{2, 3},
+		},
+		{
+			name:               "resume and skip ignorable task",
+			skipTask:           "b",
+			expectedExecutable: []int{3},
+		},
+		{
+			name:          "task can not be skipped",
+			skipTask:      "c",
+			expectedError: true,
+		},
+		{
+			name:          "task not found",
+			skipTask:      "x",
+			expectedError: true,
+		},
+		{
+			name: "succeeded task can not be skipped after rollback",
+			taskStates: `[{"step":1,"name":"a","state":"COMPLETED"},` +
+				`{"step":2,"name":"b","state":"ROLLBACKED"},{"step":3,"name":"c","state":"FAILED"}]`,
+			skipTask:      "b",
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wf := newFakeWorkflow(t, tasks, nil, 0, &taskRecorder{})
+			wf.dbInfo.TaskStates = taskStates
+			if tt.taskStates != "" {
+				wf.dbInfo.TaskStates = tt.taskStates
+			}
+			err := wf.restoreProgress(tt.skipTask)
+			if tt.expectedError {
+				if err == nil {
+					t.Errorf("restoreProgress() expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("restoreProgress() error = %v", err)
+			}
+			if !reflect.DeepEqual(wf.graph.executable(), tt.expectedExecutable) {
+				t.Errorf("executable() = %v, want %v", wf.graph.executable(), tt.expectedExecutable)
+			}
+			if wf.progress[1].RetryTimes != 3 || wf.progress[1].LastError != "mock error" {
+				t.Errorf("progress of task b is not restored: %+v", wf.progress[1])
+			}
+		})
+	}
+}
+
+func TestSkipTask(t *testing.T) {
+	tasks := []meta.TaskMeta{
+		{Name: "a", ExecuteFailure: meta.OnFailure{Ignore: true}},
+		{Name: "b", DependsOn: []string{"a"}, ExecuteFailure: meta.OnFailure{Ignore: true}},
+		{Name: "c", DependsOn: []string{"a"}},
+	}
+	wf := newFakeWorkflow(t, tasks, nil, 0, &taskRecorder{})
+	wf.graph.setState(1, TaskStateExecuting)
+
+	wf.skip("a")
+	if wf.graph.state(1) != TaskStateExecuting || wf.progress[0].Skipped {
+		t.Errorf("executing task a should not be skipped: %+v", wf.progress[0])
+	}
+	wf.skip("c")
+	if wf.graph.state(3) != TaskStatePending || wf.progress[2].Skipped {
+		t.Errorf("task c can not be ignored and should not be skipped: %+v", wf.progress[2])
+	}
+	wf.skip("b")
+	if wf.graph.state(2) != TaskStateCompleted || !wf.progress[1].Skipped {
+		t.Errorf("pending task b should be skipped: %+v", wf.progress[1])
+	}
+}
+
+func TestClaimedWorkflowContinue(t *testing.T) {
+	originOrmer := dbm.Ormer
+	defer func() { dbm.Ormer = originOrmer }()
+
+	// a -> (b, c)
+	tasks := []meta.TaskMeta{
+		{Name: "a"},
+		{Name: "b", DependsOn: []string{"a"}},
+		{Name: "c", DependsOn: []string{"a"}},
+	}
+
+	tests := []struct {
+		name               string
+		state              string
+		taskStates         string
+		expectedState      string
+		expectedExecuted   []string
+		expectedRollbacked []string
+	}{
+		{
+			name:  "running workflow skips completed tasks",
+			state: dao.WorkflowStateRunning,
+			taskStates: `[{"step":1,"name":"a","state":"COMPLETED"},` +
+				`{"step":2,"name":"b","state":"COMPLETED"},{"step":3,"name":"c","state":"EXECUTING"}]`,
+			expectedState:    dao.WorkflowStateFinished,
+			expectedExecuted: []string{"c"},
+		},
+		{
+			name:  "rollbacking workflow continues rollback",
+			state: dao.WorkflowStateRollbacking,
+			taskStates: `[{"step":1,"name":"a","state":"COMPLETED"},` +
+				`{"step":2,"name":"b","state":"ROLLBACKED"},{"step":3,"name":"c","state":"ROLLBACKING"}]`,
+			expectedState:      dao.WorkflowStateRollbacked,
+			expectedRollbacked: []string{"c", "a"},
+		},
+		{
+			name:             "workflow without progress starts over",
+			state:            dao.WorkflowStateCreate,
+			expectedState:    dao.WorkflowStateFinished,
+			expectedExecuted: []string{"a", "b", "c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			var state string
+			mockWorkflowTable(mockCtrl, &state, 1, 0)
+
+			recorder := &taskRecorder{}
+			wf := newFakeWorkflow(t, tasks, nil, 0, recorder)
+			wf.dbInfo.State = tt.state
+			wf.dbInfo.TaskStates = tt.taskStates
+			if err := wf.restoreClaimedProgress(); err != nil {
+				t.Fatalf("restoreClaimedProgress() error = %v", err)
+			}
+			wf.run()
+
+			if state != tt.expectedState {
+				t.Errorf("workflow state = %s, want %s", state, tt.expectedState)
+			}
+			if !sameElements(recorder.executed, tt.expectedExecuted) {
+				t.Errorf("executed = %v, want %v", recorder.executed, tt.expectedExecuted)
+			}
+			if !reflect.DeepEqual(recorder.rollbacked, tt.expectedRollbacked) {
+				t.Errorf("rollbacked = %v, want %v", recorder.rollbacked, tt.expectedRollbacked)
+			}
+		})
+	}
+}
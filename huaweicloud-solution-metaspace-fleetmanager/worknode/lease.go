@@ -0,0 +1,113 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2022-2022. All rights reserved.
+
+// 工作流租约接管
+package worknode
+
+import (
+	"fleetmanager/db/dao"
+	"fleetmanager/workflow"
+	"time"
+)
+
+// workflowLeaseStore 工作流租约存储
+type workflowLeaseStore interface {
+	ListExpiredWorkflows(now time.Time) ([]dao.Workflow, error)
+	ClaimWorkflow(id string, token int64, workNodeId string, now time.Time) (int64, error)
+	WorkNodeAlive(workNodeId string) (bool, error)
+}
+
+type daoLeaseStore struct{}
+
+// ListExpiredWorkflows 获取租约已过期的未完成工作流
+func (s daoLeaseStore) ListExpiredWorkflows(now time.Time) ([]dao.Workflow, error) {
+	return dao.ListExpiredWorkflows(now)
+}
+
+// ClaimWorkflow 接管租约已过期的工作流
+func (s daoLeaseStore) ClaimWorkflow(id string, token int64, workNodeId string, now time.Time) (int64, error) {
+	return dao.ClaimWorkflow(id, token, workNodeId, now)
+}
+
+// WorkNodeAlive 判断节点是否仍在运行
+func (s daoLeaseStore) WorkNodeAlive(workNodeId string) (bool, error) {
+	return dao.WorkNodeAlive(workNodeId)
+}
+
+// workflowClaimer 接管租约已过期的工作流, 多个WorkNode并发接管时通过fencing token保证只有一个成功
+type workflowClaimer struct {
+	workNodeId string
+	store      workflowLeaseStore
+	start      func(wf *dao.Workflow)
+}
+
+func newWorkflowClaimer(workNodeId string) *workflowClaimer {
+	return &workflowClaimer{
+		workNodeId: workNodeId,
+		store:      daoLeaseStore{},
+		start:      startWorkflow,
+	}
+}
+
+// claimExpired 接管所有租约已过期的工作流, 返回接管成功的工作流个数
+func (c *workflowClaimer) claimExpired() (int, error) {
+	now := time.Now().UTC()
+	wfs, err := c.store.ListExpiredWorkflows(now)
+	if err != nil {
+		return 0, err
+	}
+
+	claimed := 0
+	for i := range wfs {
+		wf := &wfs[i]
+		if !c.claimable(wf) {
+			continue
+		}
+		updated, err := c.store.ClaimWorkflow(wf.Id, wf.FencingToken, c.workNodeId, now)
+		if err != nil {
+			TLogger.Warn("work node %s claim workflow %s error: %v", c.workNodeId, wf.Id, err)
+			continue
+		}
+		if updated != 1 {
+			// 已被其他节点接管或原节点已续约
+			continue
+		}
+
+		TLogger.Info("work node %s claimed workflow %s from work node %s, fencing token %d",
+			c.workNodeId, wf.Id, wf.WorkNodeId, wf.FencingToken+1)
+		wf.WorkNodeId = c.workNodeId
+		wf.FencingToken++
+		claimed++
+		c.start(wf)
+	}
+
+	return claimed, nil
+}
+
+// claimable 升级前创建的工作流没有租约, 由不续约的旧版本WorkNode执行, 仅在原节点失效后才能接管
+func (c *workflowClaimer) claimable(wf *dao.Workflow) bool {
+	if !wf.LeaseExpireTime.IsZero() || wf.WorkNodeId == "" {
+		return true
+	}
+	alive, err := c.store.WorkNodeAlive(wf.WorkNodeId)
+	if err != nil {
+		TLogger.Warn("work node %s check work node %s of workflow %s error: %v",
+			c.workNodeId, wf.WorkNodeId, wf.Id, err)
+		return false
+	}
+
+	return !alive
+}
+
+// startWorkflow 加载已接管的工作流, 从数据库记录的任务进度继续执行
+func startWorkflow(wf *dao.Workflow) {
+	tmp, err := workflow.LoadClaimedWorkflow(wf.Id)
+	if err != nil {
+		TLogger.Error("load workflow error: %v, try to update to error", err)
+		if err = workflow.StartWorkflowFailed(wf); err != nil {
+			TLogger.Error("change workflow to error failed error: %v, try to ignore", err)
+		}
+		return
+	}
+
+	tmp.Run()
+}
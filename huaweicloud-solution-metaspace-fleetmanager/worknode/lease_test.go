@@ -0,0 +1,263 @@
+package worknode
+
+import (
+	"fleetmanager/db/dao"
+	"fleetmanager/db/dbm"
+	"fleetmanager/db/dbm/dbmtest"
+	"fleetmanager/logger"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	TLogger = logger.NewDebugLogger()
+	dao.Init()
+	cleanup, err := dbmtest.InitSqlite()
+	if err != nil {
+		fmt.Printf("init sqlite error: %v\n", err)
+		os.Exit(1)
+	}
+	code := m.Run()
+	cleanup()
+	os.Exit(code)
+}
+
+type claimRecord struct {
+	workNodeId string
+	workflowId string
+	token      int64
+}
+
+// claimRecorder 记录各WorkNode接管工作流的结果
+type claimRecorder struct {
+	lock   sync.Mutex
+	claims []claimRecord
+}
+
+func (r *claimRecorder) add(workNodeId string, wf *dao.Workflow) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.claims = append(r.claims, claimRecord{workNodeId: workNodeId, workflowId: wf.Id, token: wf.FencingToken})
+}
+
+func (r *claimRecorder) claimsOf(id string) []claimRecord {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	var records []claimRecord
+	for _, c := range r.claims {
+		if c.workflowId == id {
+			records = append(records, c)
+		}
+	}
+	return records
+}
+
+func resetTables(t *testing.T) {
+	if err := dbmtest.ClearTables(dao.WorkflowTable, dao.WorkNodeTable); err != nil {
+		t.Fatalf("ClearTables() error = %v", err)
+	}
+}
+
+func insertWorkflow(t *testing.T, id string, workNodeId string) {
+	if err := dao.InsertWorkflow(&dao.Workflow{
+		Id:              id,
+		State:           dao.WorkflowStateRunning,
+		WorkNodeId:      workNodeId,
+		FencingToken:    1,
+		LeaseExpireTime: time.Now().UTC().Add(dao.WorkflowLease),
+	}); err != nil {
+		t.Fatalf("InsertWorkflow() error = %v", err)
+	}
+}
+
+func ownerOf(t *testing.T, id string) (string, int64) {
+	w, err := dao.GetWorkflow(dao.Filters{"Id": id})
+	if err != nil {
+		t.Fatalf("GetWorkflow() error = %v", err)
+	}
+	return w.WorkNodeId, w.FencingToken
+}
+
+// simulatedNode 模拟一个WorkNode: 周期性接管过期工作流, 并为自己执行的工作流续约, crash后停止所有动作
+type simulatedNode struct {
+	id       string
+	recorder *claimRecorder
+	crashCh  chan struct{}
+	wg       *sync.WaitGroup
+}
+
+func (n *simulatedNode) own(id string, token int64) {
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		ticker := time.NewTicker(dao.WorkflowLease / 4)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-n.crashCh:
+				return
+			case <-ticker.C:
+				if err := dao.RenewWorkflowLease(id, token); err != nil {
+					return
+				}
+			}
+		}
+	}()
+}
+
+func (n *simulatedNode) runClaimer(interval time.Duration) {
+	c := &workflowClaimer{
+		workNodeId: n.id,
+		store:      daoLeaseStore{},
+		start: func(wf *dao.Workflow) {
+			n.recorder.add(n.id, wf)
+			n.own(wf.Id, wf.FencingToken)
+		},
+	}
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-n.crashCh:
+				return
+			case <-ticker.C:
+				_, _ = c.claimExpired()
+			}
+		}
+	}()
+}
+
+func waitFor(timeout time.Duration, cond func() bool) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return cond()
+}
+
+func TestWorkflowTakeOverAfterNodeCrash(t *testing.T) {
+	resetTables(t)
+	originLease := dao.WorkflowLease
+	defer func() { dao.WorkflowLease = originLease }()
+	dao.WorkflowLease = 500 * time.Millisecond
+	lease := dao.WorkflowLease
+	workflows := []string{"wf-1", "wf-2", "wf-3"}
+	for _, id := range workflows {
+		insertWorkflow(t, id, "node-0")
+	}
+
+	recorder := &claimRecorder{}
+	wg := &sync.WaitGroup{}
+	nodes := make(map[string]*simulatedNode)
+	for i := 0; i < 5; i++ {
+		id := fmt.Sprintf("node-%d", i)
+		nodes[id] = &simulatedNode{id: id, recorder: recorder, crashCh: make(chan struct{}), wg: wg}
+		nodes[id].runClaimer(50 * time.Millisecond)
+	}
+	defer func() {
+		for _, n := range nodes {
+			select {
+			case <-n.crashCh:
+			default:
+				close(n.crashCh)
+			}
+		}
+		wg.Wait()
+	}()
+	for _, id := range workflows {
+		nodes["node-0"].own(id, 1)
+	}
+
+	// 原节点持续续约时, 其他节点不能接管
+	time.Sleep(3 * lease)
+	for _, id := range workflows {
+		if claims := recorder.claimsOf(id); len(claims) != 0 {
+			t.Fatalf("workflow %s is claimed while the owner is alive: %v", id, claims)
+		}
+	}
+
+	close(nodes["node-0"].crashCh)
+	for _, id := range workflows {
+		id := id
+		if !waitFor(10*lease, func() bool { return len(recorder.claimsOf(id)) > 0 }) {
+			t.Fatalf("workflow %s is not claimed after the owner crashed", id)
+		}
+	}
+	// 等待足够长时间, 确认没有重复接管
+	time.Sleep(3 * lease)
+	for _, id := range workflows {
+		claims := recorder.claimsOf(id)
+		if len(claims) != 1 {
+			t.Fatalf("workflow %s claims = %v, want exactly one", id, claims)
+		}
+		if claims[0].workNodeId == "node-0" || claims[0].token != 2 {
+			t.Errorf("workflow %s claim = %+v, want token 2 by a live node", id, claims[0])
+		}
+		stale := &dao.Workflow{Id: id, FencingToken: 1, State: dao.WorkflowStateFinished}
+		if err := dao.UpdateWorkflow(stale, "State"); err != dao.ErrWorkflowLeaseLost {
+			t.Errorf("update with stale token error = %v, want %v", err, dao.ErrWorkflowLeaseLost)
+		}
+		current := &dao.Workflow{Id: id, FencingToken: 2, State: dao.WorkflowStateRunning}
+		if err := dao.UpdateWorkflow(current, "State"); err != nil {
+			t.Errorf("update with current token error = %v", err)
+		}
+	}
+
+	// 新的执行节点再次故障, 工作流继续被其他存活节点接管
+	owner, _ := ownerOf(t, "wf-1")
+	close(nodes[owner].crashCh)
+	if !waitFor(10*lease, func() bool { return len(recorder.claimsOf("wf-1")) == 2 }) {
+		t.Fatalf("workflow wf-1 is not claimed after the second owner %s crashed", owner)
+	}
+	newOwner, token := ownerOf(t, "wf-1")
+	if newOwner == owner || newOwner == "node-0" || token != 3 {
+		t.Errorf("wf-1 owner = %s, token = %d, want a live node with token 3", newOwner, token)
+	}
+}
+
+func TestClaimLegacyWorkflow(t *testing.T) {
+	resetTables(t)
+	// 升级前创建的工作流没有fencing token和租约, 由旧版本WorkNode执行且不会续约
+	for _, id := range []string{"legacy-alive", "legacy-dead"} {
+		insertWorkflow(t, id, id)
+		if _, err := dbm.Ormer.Raw("UPDATE workflow SET fencing_token = 0, lease_expire_time = NULL WHERE id = ?",
+			id).Exec(); err != nil {
+			t.Fatalf("update legacy workflow error = %v", err)
+		}
+	}
+	if err := dao.InsertWorkNode(&dao.WorkNode{Id: "legacy-alive", State: dao.WorkNodeStateRunning}); err != nil {
+		t.Fatalf("InsertWorkNode() error = %v", err)
+	}
+	if err := dao.InsertWorkNode(&dao.WorkNode{Id: "legacy-dead", State: dao.WorkNodeStateError}); err != nil {
+		t.Fatalf("InsertWorkNode() error = %v", err)
+	}
+
+	var started []string
+	c := &workflowClaimer{
+		workNodeId: "node-new",
+		store:      daoLeaseStore{},
+		start:      func(wf *dao.Workflow) { started = append(started, wf.Id) },
+	}
+	if _, err := c.claimExpired(); err != nil {
+		t.Fatalf("claimExpired() error = %v", err)
+	}
+
+	if len(started) != 1 || started[0] != "legacy-dead" {
+		t.Errorf("started = %v, want only the workflow of the dead work node", started)
+	}
+	if owner, token := ownerOf(t, "legacy-alive"); owner != "legacy-alive" || token != 0 {
+		t.Errorf("legacy-alive owner = %s, token = %d, want not claimed", owner, token)
+	}
+	if owner, token := ownerOf(t, "legacy-dead"); owner != "node-new" || token != 1 {
+		t.Errorf("legacy-dead owner = %s, token = %d, want claimed by node-new with token 1", owner, token)
+	}
+}
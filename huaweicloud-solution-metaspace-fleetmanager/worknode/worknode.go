@@ -7,14 +7,13 @@ import (
 	"fleetmanager/db/dao"
 	"fleetmanager/logger"
 	"fleetmanager/utils/wait"
-	"fleetmanager/workflow"
 	"github.com/google/uuid"
 	"regexp"
 	"time"
 )
 
 const (
-	DefaultTakeOverTaskInterval          = 5 * 60
+	DefaultTakeOverTaskInterval          = 5
 	DefaultDeadWorkNodeCheckTaskInterval = 5 * 60
 	DefaultHeartBeatTaskInterval         = 1 * 60
 	MaxDeadMinites                       = 10
@@ -38,16 +37,21 @@ func Init() error {
 	return dao.InsertWorkNode(wn)
 }
 
-// StartWorkNodeTakeOverPeriodTask 周期性检测可执行的工作流任务并启动
+// StartWorkNodeTakeOverPeriodTask 周期性接管租约已过期的工作流并启动, 同时清理已失效的WorkNode
 func StartWorkNodeTakeOverPeriodTask(stopCh <-chan struct{}) {
+	claimer := newWorkflowClaimer(WorkNodeId)
 	go wait.Until(func() {
-		TLogger.Info("TakeOverWorkNodeTask start, WorkNode: %s", WorkNodeId)
+		TLogger.Debug("TakeOverWorkNodeTask start, WorkNode: %s", WorkNodeId)
+		claimed, err := claimer.claimExpired()
+		if err != nil {
+			logger.R.Warn("workflow take over error: %v", err)
+		}
 		if err := takeOverWorkNode(); err != nil {
 			logger.R.Warn("work node take over error: %v", err)
 			return
 		}
 
-		TLogger.Debug("TakeOverWorkNodeTask finished, WorkNode: %s", WorkNodeId)
+		TLogger.Debug("TakeOverWorkNodeTask finished, WorkNode: %s, %d workflows are claimed", WorkNodeId, claimed)
 	}, time.Duration(DefaultTakeOverTaskInterval)*time.Second, stopCh)
 }
 
@@ -107,7 +111,7 @@ func checkDeadTime(deadTime string) bool {
 	return isOk
 }
 
-// 任务接管
+// takeOverWorkNode 接管已失效的WorkNode, 其未完成的工作流在租约过期后由各节点通过租约接管
 func takeOverWorkNode() error {
 	// 获取所有状态是Error以及Terminated的WorkNode列表
 	f := dao.Filters{"State__in": []string{dao.WorkNodeStateTerminated, dao.WorkNodeStateError}}
@@ -115,91 +119,26 @@ func takeOverWorkNode() error {
 	if err != nil {
 		return err
 	}
+	if len(wns) == 0 {
+		return nil
+	}
 
 	TLogger.Warn("%d work nodes need to be take over, wns: %s", len(wns), wns)
-
-	// 尝试Take Over, 如果成功, 则启动workflow恢复, 如果失败则执行下一个WorkNode的Take Over, 直到成功一个
 	for _, wn := range wns {
 		updated, err := dao.TakeOverWorkNode(wn.Id, wn.State, WorkNodeId)
 		if err != nil {
 			TLogger.Warn("work node %s try to take over wn %s error: %v", WorkNodeId, wn, err)
-		} else {
-			if updated != 1 {
-				// 没有接管成功
-				TLogger.Warn("work node %s take over wn %s failed by other node", WorkNodeId, wn)
-				continue
-			}
-
-			// 接管成功，启动接管流程
-			TLogger.Debug("Try to take over wfs, WorkNode:%s", WorkNodeId)
-			go (func() {
-				if err := startTakeOverWorkFlow(wn.Id); err != nil {
-					logger.R.Warn("workflow take over error: %v", err)
-				}
-			})()
-
-			// 一个period仅接管一个WorkNode
-			break
+			continue
 		}
-	}
-
-	return nil
-}
-
-func updateWorkFlowNodeInfo(wfs []dao.Workflow, originWorkNodeId string) error {
-	wfIds := getWorkflowIds(wfs)
-	if err := dao.TakeOverWorkFlow(wfIds, WorkNodeId); err != nil {
-		// 更新失败
-		return err
-	}
-
-	// 更新成功, 更新worknode状态
-	if err := dao.UpdateWorkNodeState(originWorkNodeId, dao.WorkNodeStateFinished); err != nil {
-		// 打印日志, 不影响后续工作流任务执行
-		TLogger.Warn("update work node state to finished db error: %v", err)
-	}
-	return nil
-}
-
-func getWorkflowIds(wfs []dao.Workflow) []string {
-	wfIds := make([]string, 0)
-	for _, wf := range wfs {
-		wfIds = append(wfIds, wf.Id)
-	}
-	return wfIds
-}
-
-func startTakeOverWorkFlow(workNodeId string) error {
-	// 1. 查询出对应workNode所有的未完成工作流
-	filter := dao.Filters{
-		"State__in":  []string{dao.WorkflowStateRollbacking, dao.WorkflowStateRunning, dao.WorkflowStateCreate},
-		"WorkNodeId": workNodeId,
-	}
-	wfs, err := dao.GetAllWorkflows(filter)
-	if err != nil {
-		return err
-	}
-
-	TLogger.Info("Try to take over wfs:%+v, WorkNode:%s", wfs, WorkNodeId)
-
-	// 2. 开始接管
-	if err := updateWorkFlowNodeInfo(wfs, workNodeId); err != nil {
-		return err
-	}
-
-	// 3. 执行工作流, 理论上可以忽略错误
-	for _, wf := range wfs {
-		tmp, err := workflow.LoadWorkflow(wf.Id)
-		if err != nil {
-			TLogger.Error("load workflow error: %v, try to update to error", err)
-			if err = workflow.StartWorkflowFailed(&wf); err != nil {
-				TLogger.Error("change workflow to error failed error: %v, try to ignore", err)
-			}
+		if updated != 1 {
+			// 没有接管成功
+			TLogger.Warn("work node %s take over wn %s failed by other node", WorkNodeId, wn)
 			continue
 		}
 
-		// 启动工作流
-		tmp.Run()
+		if err := dao.UpdateWorkNodeState(wn.Id, dao.WorkNodeStateFinished); err != nil {
+			TLogger.Warn("update work node state to finished db error: %v", err)
+		}
 	}
 
 	return nil